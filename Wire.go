@@ -75,6 +75,9 @@ import (
 	"github.com/devtron-labs/devtron/pkg/app"
 	"github.com/devtron-labs/devtron/pkg/appClone"
 	"github.com/devtron-labs/devtron/pkg/appClone/batch"
+	appCloneRepository "github.com/devtron-labs/devtron/pkg/appClone/repository"
+	"github.com/devtron-labs/devtron/pkg/appGroup"
+	appGroupRepository "github.com/devtron-labs/devtron/pkg/appGroup/repository"
 	"github.com/devtron-labs/devtron/pkg/appStatus"
 	appStoreBean "github.com/devtron-labs/devtron/pkg/appStore/bean"
 	appStoreDeploymentFullMode "github.com/devtron-labs/devtron/pkg/appStore/deployment/fullMode"
@@ -86,12 +89,17 @@ import (
 	"github.com/devtron-labs/devtron/pkg/bulkAction"
 	"github.com/devtron-labs/devtron/pkg/chart"
 	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
+	"github.com/devtron-labs/devtron/pkg/clusterHealth"
 	"github.com/devtron-labs/devtron/pkg/commonService"
 	delete2 "github.com/devtron-labs/devtron/pkg/delete"
 	"github.com/devtron-labs/devtron/pkg/deploymentGroup"
 	"github.com/devtron-labs/devtron/pkg/dockerRegistry"
+	"github.com/devtron-labs/devtron/pkg/externalSecret"
+	externalSecretRepository "github.com/devtron-labs/devtron/pkg/externalSecret/repository"
 	"github.com/devtron-labs/devtron/pkg/git"
 	"github.com/devtron-labs/devtron/pkg/gitops"
+	"github.com/devtron-labs/devtron/pkg/hibernation"
+	hibernationRepository "github.com/devtron-labs/devtron/pkg/hibernation/repository"
 	jira2 "github.com/devtron-labs/devtron/pkg/jira"
 	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
 	repository7 "github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs/repository"
@@ -102,10 +110,16 @@ import (
 	repository5 "github.com/devtron-labs/devtron/pkg/pipeline/repository"
 	"github.com/devtron-labs/devtron/pkg/plugin"
 	repository6 "github.com/devtron-labs/devtron/pkg/plugin/repository"
+	"github.com/devtron-labs/devtron/pkg/previewEnv"
+	previewEnvRepository "github.com/devtron-labs/devtron/pkg/previewEnv/repository"
 	"github.com/devtron-labs/devtron/pkg/projectManagementService/jira"
 	"github.com/devtron-labs/devtron/pkg/security"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	util3 "github.com/devtron-labs/devtron/pkg/util"
+	"github.com/devtron-labs/devtron/pkg/variables"
+	variablesRepository "github.com/devtron-labs/devtron/pkg/variables/repository"
+	"github.com/devtron-labs/devtron/pkg/workloadAction"
+	workloadActionRepository "github.com/devtron-labs/devtron/pkg/workloadAction/repository"
 	util2 "github.com/devtron-labs/devtron/util"
 	"github.com/devtron-labs/devtron/util/argo"
 	"github.com/devtron-labs/devtron/util/k8s"
@@ -179,6 +193,8 @@ func InitializeApp() (*App, error) {
 
 		bulkUpdate.NewBulkUpdateRepository,
 		wire.Bind(new(bulkUpdate.BulkUpdateRepository), new(*bulkUpdate.BulkUpdateRepositoryImpl)),
+		bulkUpdate.NewBulkUpdateJobRepositoryImpl,
+		wire.Bind(new(bulkUpdate.BulkUpdateJobRepository), new(*bulkUpdate.BulkUpdateJobRepositoryImpl)),
 
 		chartConfig.NewEnvConfigOverrideRepository,
 		wire.Bind(new(chartConfig.EnvConfigOverrideRepository), new(*chartConfig.EnvConfigOverrideRepositoryImpl)),
@@ -435,6 +451,8 @@ func InitializeApp() (*App, error) {
 		wire.Bind(new(restHandler.ConfigMapRestHandler), new(*restHandler.ConfigMapRestHandlerImpl)),
 		pipeline.NewConfigMapServiceImpl,
 		wire.Bind(new(pipeline.ConfigMapService), new(*pipeline.ConfigMapServiceImpl)),
+		pipeline.NewConfigMapVersionServiceImpl,
+		wire.Bind(new(pipeline.ConfigMapVersionService), new(*pipeline.ConfigMapVersionServiceImpl)),
 		chartConfig.NewConfigMapRepositoryImpl,
 		wire.Bind(new(chartConfig.ConfigMapRepository), new(*chartConfig.ConfigMapRepositoryImpl)),
 
@@ -683,6 +701,8 @@ func InitializeApp() (*App, error) {
 
 		repository3.NewConfigMapHistoryRepositoryImpl,
 		wire.Bind(new(repository3.ConfigMapHistoryRepository), new(*repository3.ConfigMapHistoryRepositoryImpl)),
+		repository3.NewConfigMapHistoryRestoreAuditRepositoryImpl,
+		wire.Bind(new(repository3.ConfigMapHistoryRestoreAuditRepository), new(*repository3.ConfigMapHistoryRestoreAuditRepositoryImpl)),
 		repository3.NewDeploymentTemplateHistoryRepositoryImpl,
 		wire.Bind(new(repository3.DeploymentTemplateHistoryRepository), new(*repository3.DeploymentTemplateHistoryRepositoryImpl)),
 		repository3.NewPrePostCiScriptHistoryRepositoryImpl,
@@ -798,6 +818,8 @@ func InitializeApp() (*App, error) {
 		wire.Bind(new(pipeline.GlobalCMCSService), new(*pipeline.GlobalCMCSServiceImpl)),
 		repository.NewGlobalCMCSRepositoryImpl,
 		wire.Bind(new(repository.GlobalCMCSRepository), new(*repository.GlobalCMCSRepositoryImpl)),
+		repository.NewGlobalCMCSHistoryRepositoryImpl,
+		wire.Bind(new(repository.GlobalCMCSHistoryRepository), new(*repository.GlobalCMCSHistoryRepositoryImpl)),
 
 		chartRepoRepository.NewGlobalStrategyMetadataRepositoryImpl,
 		wire.Bind(new(chartRepoRepository.GlobalStrategyMetadataRepository), new(*chartRepoRepository.GlobalStrategyMetadataRepositoryImpl)),
@@ -819,6 +841,81 @@ func InitializeApp() (*App, error) {
 
 		kubernetesResourceAuditLogs.Newk8sResourceHistoryServiceImpl,
 		wire.Bind(new(kubernetesResourceAuditLogs.K8sResourceHistoryService), new(*kubernetesResourceAuditLogs.K8sResourceHistoryServiceImpl)),
+
+		repository7.NewK8sResourceAuditLogRepositoryImpl,
+		wire.Bind(new(repository7.K8sResourceAuditLogRepository), new(*repository7.K8sResourceAuditLogRepositoryImpl)),
+		kubernetesResourceAuditLogs.NewK8sResourceAuditLogServiceImpl,
+		wire.Bind(new(kubernetesResourceAuditLogs.K8sResourceAuditLogService), new(*kubernetesResourceAuditLogs.K8sResourceAuditLogServiceImpl)),
+		restHandler.NewK8sResourceAuditLogRestHandlerImpl,
+		wire.Bind(new(restHandler.K8sResourceAuditLogRestHandler), new(*restHandler.K8sResourceAuditLogRestHandlerImpl)),
+		router.NewK8sResourceAuditLogRouterImpl,
+		wire.Bind(new(router.K8sResourceAuditLogRouter), new(*router.K8sResourceAuditLogRouterImpl)),
+
+		externalSecretRepository.NewExternalSecretStoreRepositoryImpl,
+		wire.Bind(new(externalSecretRepository.ExternalSecretStoreRepository), new(*externalSecretRepository.ExternalSecretStoreRepositoryImpl)),
+		externalSecret.NewExternalSecretProviderServiceImpl,
+		wire.Bind(new(externalSecret.ExternalSecretProviderService), new(*externalSecret.ExternalSecretProviderServiceImpl)),
+
+		variablesRepository.NewScopedVariableRepositoryImpl,
+		wire.Bind(new(variablesRepository.ScopedVariableRepository), new(*variablesRepository.ScopedVariableRepositoryImpl)),
+		variables.NewScopedVariableServiceImpl,
+		wire.Bind(new(variables.ScopedVariableService), new(*variables.ScopedVariableServiceImpl)),
+		restHandler.NewScopedVariableRestHandlerImpl,
+		wire.Bind(new(restHandler.ScopedVariableRestHandler), new(*restHandler.ScopedVariableRestHandlerImpl)),
+		router.NewScopedVariableRouterImpl,
+		wire.Bind(new(router.ScopedVariableRouter), new(*router.ScopedVariableRouterImpl)),
+
+		appGroupRepository.NewAppGroupRepositoryImpl,
+		wire.Bind(new(appGroupRepository.AppGroupRepository), new(*appGroupRepository.AppGroupRepositoryImpl)),
+		appGroup.NewAppGroupServiceImpl,
+		wire.Bind(new(appGroup.AppGroupService), new(*appGroup.AppGroupServiceImpl)),
+		restHandler.NewAppGroupRestHandlerImpl,
+		wire.Bind(new(restHandler.AppGroupRestHandler), new(*restHandler.AppGroupRestHandlerImpl)),
+		router.NewAppGroupRouterImpl,
+		wire.Bind(new(router.AppGroupRouter), new(*router.AppGroupRouterImpl)),
+
+		appCloneRepository.NewAppTemplateRepositoryImpl,
+		wire.Bind(new(appCloneRepository.AppTemplateRepository), new(*appCloneRepository.AppTemplateRepositoryImpl)),
+		appClone.NewAppTemplateServiceImpl,
+		wire.Bind(new(appClone.AppTemplateService), new(*appClone.AppTemplateServiceImpl)),
+		restHandler.NewAppTemplateRestHandlerImpl,
+		wire.Bind(new(restHandler.AppTemplateRestHandler), new(*restHandler.AppTemplateRestHandlerImpl)),
+		router.NewAppTemplateRouterImpl,
+		wire.Bind(new(router.AppTemplateRouter), new(*router.AppTemplateRouterImpl)),
+
+		previewEnvRepository.NewPreviewEnvironmentRepositoryImpl,
+		wire.Bind(new(previewEnvRepository.PreviewEnvironmentRepository), new(*previewEnvRepository.PreviewEnvironmentRepositoryImpl)),
+		previewEnv.NewPreviewEnvironmentServiceImpl,
+		wire.Bind(new(previewEnv.PreviewEnvironmentService), new(*previewEnv.PreviewEnvironmentServiceImpl)),
+		restHandler.NewPreviewEnvironmentRestHandlerImpl,
+		wire.Bind(new(restHandler.PreviewEnvironmentRestHandler), new(*restHandler.PreviewEnvironmentRestHandlerImpl)),
+		router.NewPreviewEnvironmentRouterImpl,
+		wire.Bind(new(router.PreviewEnvironmentRouter), new(*router.PreviewEnvironmentRouterImpl)),
+
+		hibernationRepository.NewHibernationScheduleRepositoryImpl,
+		wire.Bind(new(hibernationRepository.HibernationScheduleRepository), new(*hibernationRepository.HibernationScheduleRepositoryImpl)),
+		hibernation.NewHibernationScheduleServiceImpl,
+		wire.Bind(new(hibernation.HibernationScheduleService), new(*hibernation.HibernationScheduleServiceImpl)),
+		restHandler.NewHibernationScheduleRestHandlerImpl,
+		wire.Bind(new(restHandler.HibernationScheduleRestHandler), new(*restHandler.HibernationScheduleRestHandlerImpl)),
+		router.NewHibernationScheduleRouterImpl,
+		wire.Bind(new(router.HibernationScheduleRouter), new(*router.HibernationScheduleRouterImpl)),
+
+		clusterHealth.NewClusterHealthServiceImpl,
+		wire.Bind(new(clusterHealth.ClusterHealthService), new(*clusterHealth.ClusterHealthServiceImpl)),
+		restHandler.NewClusterHealthRestHandlerImpl,
+		wire.Bind(new(restHandler.ClusterHealthRestHandler), new(*restHandler.ClusterHealthRestHandlerImpl)),
+		router.NewClusterHealthRouterImpl,
+		wire.Bind(new(router.ClusterHealthRouter), new(*router.ClusterHealthRouterImpl)),
+
+		workloadActionRepository.NewWorkloadActionRepositoryImpl,
+		wire.Bind(new(workloadActionRepository.WorkloadActionRepository), new(*workloadActionRepository.WorkloadActionRepositoryImpl)),
+		workloadAction.NewWorkloadActionServiceImpl,
+		wire.Bind(new(workloadAction.WorkloadActionService), new(*workloadAction.WorkloadActionServiceImpl)),
+		restHandler.NewWorkloadActionRestHandlerImpl,
+		wire.Bind(new(restHandler.WorkloadActionRestHandler), new(*restHandler.WorkloadActionRestHandlerImpl)),
+		router.NewWorkloadActionRouterImpl,
+		wire.Bind(new(router.WorkloadActionRouter), new(*router.WorkloadActionRouterImpl)),
 	)
 	return &App{}, nil
 }
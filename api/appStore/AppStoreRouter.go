@@ -66,6 +66,8 @@ func (router AppStoreRouterImpl) Init(configRouter *mux.Router) {
 		HandlerFunc(router.deployRestHandler.CheckAppExists).Methods("POST")
 	configRouter.Path("/group/install").
 		HandlerFunc(router.deployRestHandler.DeployBulk).Methods("POST")
+	configRouter.Path("/installed-app/update/dry-run").
+		HandlerFunc(router.deployRestHandler.UpdateInstalledAppDryRun).Methods("POST")
 	configRouter.Path("/installed-app/detail").Queries("installed-app-id", "{installed-app-id}").Queries("env-id", "{env-id}").
 		HandlerFunc(router.deployRestHandler.FetchAppDetailsForInstalledApp).
 		Methods("GET")
@@ -48,6 +48,7 @@ type InstalledAppRestHandler interface {
 	CheckAppExists(w http.ResponseWriter, r *http.Request)
 	DefaultComponentInstallation(w http.ResponseWriter, r *http.Request)
 	FetchAppDetailsForInstalledApp(w http.ResponseWriter, r *http.Request)
+	UpdateInstalledAppDryRun(w http.ResponseWriter, r *http.Request)
 }
 
 type InstalledAppRestHandlerImpl struct {
@@ -433,6 +434,50 @@ func (handler *InstalledAppRestHandlerImpl) FetchAppDetailsForInstalledApp(w htt
 	common.WriteJsonResp(w, err, appDetail, http.StatusOK)
 }
 
+func (handler *InstalledAppRestHandlerImpl) UpdateInstalledAppDryRun(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request appStoreBean.InstallAppVersionDTO
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.Logger.Errorw("request err, UpdateInstalledAppDryRun", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if request.InstalledAppId == 0 || request.AppStoreVersion == 0 {
+		common.WriteJsonResp(w, fmt.Errorf("installedAppId and appStoreVersion are required"), nil, http.StatusBadRequest)
+		return
+	}
+	handler.Logger.Infow("request payload, UpdateInstalledAppDryRun", "installedAppId", request.InstalledAppId, "appStoreVersion", request.AppStoreVersion)
+	installedApp, err := handler.appStoreDeploymentService.GetInstalledApp(request.InstalledAppId)
+	if err != nil {
+		handler.Logger.Errorw("service err, UpdateInstalledAppDryRun", "err", err, "installedAppId", request.InstalledAppId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	object, object2 := handler.enforcerUtil.GetHelmObject(installedApp.AppId, installedApp.EnvironmentId)
+	ok := handler.enforcer.Enforce(token, casbin.ResourceHelmApp, casbin.ActionUpdate, object) || handler.enforcer.Enforce(token, casbin.ResourceHelmApp, casbin.ActionUpdate, object2)
+	if !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	res, err := handler.installedAppService.DryRunUpdateInstalledApp(r.Context(), &request)
+	if err != nil {
+		handler.Logger.Errorw("service err, UpdateInstalledAppDryRun", "err", err, "installedAppId", request.InstalledAppId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
 func (handler *InstalledAppRestHandlerImpl) fetchResourceTree(w http.ResponseWriter, r *http.Request, appDetail *bean2.AppDetailContainer) {
 	ctx := r.Context()
 	cn, _ := w.(http.CloseNotifier)
@@ -53,16 +53,19 @@ type ClusterRestHandler interface {
 	GetClusterNamespaces(w http.ResponseWriter, r *http.Request)
 	GetAllClusterNamespaces(w http.ResponseWriter, r *http.Request)
 	FindAllForClusterPermission(w http.ResponseWriter, r *http.Request)
+	GetKubeconfigContexts(w http.ResponseWriter, r *http.Request)
+	ImportKubeconfig(w http.ResponseWriter, r *http.Request)
 }
 
 type ClusterRestHandlerImpl struct {
-	clusterService  cluster.ClusterService
-	logger          *zap.SugaredLogger
-	userService     user.UserService
-	validator       *validator.Validate
-	enforcer        casbin.Enforcer
-	deleteService   delete2.DeleteService
-	argoUserService argo.ArgoUserService
+	clusterService          cluster.ClusterService
+	logger                  *zap.SugaredLogger
+	userService             user.UserService
+	validator               *validator.Validate
+	enforcer                casbin.Enforcer
+	deleteService           delete2.DeleteService
+	argoUserService         argo.ArgoUserService
+	kubeconfigImportService cluster.KubeconfigImportService
 }
 
 func NewClusterRestHandlerImpl(clusterService cluster.ClusterService,
@@ -71,15 +74,17 @@ func NewClusterRestHandlerImpl(clusterService cluster.ClusterService,
 	validator *validator.Validate,
 	enforcer casbin.Enforcer,
 	deleteService delete2.DeleteService,
-	argoUserService argo.ArgoUserService) *ClusterRestHandlerImpl {
+	argoUserService argo.ArgoUserService,
+	kubeconfigImportService cluster.KubeconfigImportService) *ClusterRestHandlerImpl {
 	return &ClusterRestHandlerImpl{
-		clusterService:  clusterService,
-		logger:          logger,
-		userService:     userService,
-		validator:       validator,
-		enforcer:        enforcer,
-		deleteService:   deleteService,
-		argoUserService: argoUserService,
+		clusterService:          clusterService,
+		logger:                  logger,
+		userService:             userService,
+		validator:               validator,
+		enforcer:                enforcer,
+		deleteService:           deleteService,
+		argoUserService:         argoUserService,
+		kubeconfigImportService: kubeconfigImportService,
 	}
 }
 
@@ -422,3 +427,101 @@ func (impl ClusterRestHandlerImpl) FindAllForClusterPermission(w http.ResponseWr
 	}
 	common.WriteJsonResp(w, err, clusterList, http.StatusOK)
 }
+
+func (impl ClusterRestHandlerImpl) GetKubeconfigContexts(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	request := &cluster.KubeconfigRequest{}
+	err = decoder.Decode(request)
+	if err != nil {
+		impl.logger.Errorw("request err, GetKubeconfigContexts", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = impl.validator.Struct(request)
+	if err != nil {
+		impl.logger.Errorw("validation err, GetKubeconfigContexts", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceCluster, casbin.ActionCreate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	contexts, err := impl.kubeconfigImportService.GetKubeconfigContexts(request)
+	if err != nil {
+		impl.logger.Errorw("service err, GetKubeconfigContexts", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	common.WriteJsonResp(w, nil, contexts, http.StatusOK)
+}
+
+func (impl ClusterRestHandlerImpl) ImportKubeconfig(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	request := &cluster.KubeconfigImportRequest{}
+	err = decoder.Decode(request)
+	if err != nil {
+		impl.logger.Errorw("request err, ImportKubeconfig", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = impl.validator.Struct(request)
+	if err != nil {
+		impl.logger.Errorw("validation err, ImportKubeconfig", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := impl.enforcer.Enforce(token, casbin.ResourceCluster, casbin.ActionCreate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return
+	}
+	for _, contextImport := range request.Contexts {
+		if len(contextImport.Namespaces) == 0 {
+			continue
+		}
+		if ok := impl.enforcer.Enforce(token, casbin.ResourceGlobalEnvironment, casbin.ActionCreate, "*"); !ok {
+			common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+			return
+		}
+		break
+	}
+	//RBAC enforcer Ends
+
+	ctx := r.Context()
+	if util2.IsBaseStack() {
+		ctx = context.WithValue(ctx, "token", token)
+	} else {
+		acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+		if err != nil {
+			impl.logger.Errorw("error in getting acd token", "err", err)
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		ctx = context.WithValue(ctx, "token", acdToken)
+	}
+	res, err := impl.kubeconfigImportService.ImportKubeconfig(ctx, request, userId)
+	if err != nil {
+		impl.logger.Errorw("service err, ImportKubeconfig", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
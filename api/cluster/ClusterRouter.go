@@ -72,4 +72,12 @@ func (impl ClusterRouterImpl) InitClusterRouter(clusterRouter *mux.Router) {
 	clusterRouter.Path("/auth-list").
 		Methods("GET").
 		HandlerFunc(impl.clusterRestHandler.FindAllForClusterPermission)
+
+	clusterRouter.Path("/kubeconfig/contexts").
+		Methods("POST").
+		HandlerFunc(impl.clusterRestHandler.GetKubeconfigContexts)
+
+	clusterRouter.Path("/kubeconfig/import").
+		Methods("POST").
+		HandlerFunc(impl.clusterRestHandler.ImportKubeconfig)
 }
@@ -11,6 +11,8 @@ import (
 var ClusterWireSet = wire.NewSet(
 	repository.NewClusterRepositoryImpl,
 	wire.Bind(new(repository.ClusterRepository), new(*repository.ClusterRepositoryImpl)),
+	repository.NewClusterHealthRepositoryImpl,
+	wire.Bind(new(repository.ClusterHealthRepository), new(*repository.ClusterHealthRepositoryImpl)),
 	cluster.NewClusterServiceImplExtended,
 	wire.Bind(new(cluster.ClusterService), new(*cluster.ClusterServiceImplExtended)),
 	NewClusterRestHandlerImpl,
@@ -22,6 +24,8 @@ var ClusterWireSet = wire.NewSet(
 	wire.Bind(new(repository.EnvironmentRepository), new(*repository.EnvironmentRepositoryImpl)),
 	cluster.NewEnvironmentServiceImpl,
 	wire.Bind(new(cluster.EnvironmentService), new(*cluster.EnvironmentServiceImpl)),
+	cluster.NewKubeconfigImportServiceImpl,
+	wire.Bind(new(cluster.KubeconfigImportService), new(*cluster.KubeconfigImportServiceImpl)),
 	NewEnvironmentRestHandlerImpl,
 	wire.Bind(new(EnvironmentRestHandler), new(*EnvironmentRestHandlerImpl)),
 	NewEnvironmentRouterImpl,
@@ -42,6 +46,8 @@ var ClusterWireSetEa = wire.NewSet(
 	wire.Bind(new(repository.EnvironmentRepository), new(*repository.EnvironmentRepositoryImpl)),
 	cluster.NewEnvironmentServiceImpl,
 	wire.Bind(new(cluster.EnvironmentService), new(*cluster.EnvironmentServiceImpl)),
+	cluster.NewKubeconfigImportServiceImpl,
+	wire.Bind(new(cluster.KubeconfigImportService), new(*cluster.KubeconfigImportServiceImpl)),
 	NewEnvironmentRestHandlerImpl,
 	wire.Bind(new(EnvironmentRestHandler), new(*EnvironmentRestHandlerImpl)),
 	NewEnvironmentRouterImpl,
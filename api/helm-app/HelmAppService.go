@@ -60,6 +60,14 @@ type HelmAppService interface {
 	GetDevtronHelmAppIdentifier() *AppIdentifier
 	UpdateApplicationWithChartInfoWithExtraValues(ctx context.Context, appIdentifier *AppIdentifier, chartRepository *ChartRepository, extraValues map[string]interface{}, extraValuesYamlUrl string, useLatestChartVersion bool) (*openapi.UpdateReleaseResponse, error)
 	TemplateChart(ctx context.Context, templateChartRequest *openapi2.TemplateChartRequest) (*openapi2.TemplateChartResponse, error)
+	// GetReleaseManifest returns manifest of latest revision of release
+	GetReleaseManifest(ctx context.Context, app *AppIdentifier) (string, error)
+	// RenderChart renders chart with values of request through helm client without installing it
+	RenderChart(ctx context.Context, clusterId int, request *InstallReleaseRequest) (string, error)
+	// DryRunUpdateApplication diffs release against its chart rendered with new values, chart of release must be known
+	// to devtron as helm does not keep chart repository of a release
+	DryRunUpdateApplication(ctx context.Context, app *AppIdentifier, request *openapi.UpdateReleaseRequest) (*ReleaseDiffResponse, error)
+	DryRunUpdateApplicationWithChartInfo(ctx context.Context, clusterId int, updateReleaseRequest *InstallReleaseRequest) (*ReleaseDiffResponse, error)
 }
 
 type HelmAppServiceImpl struct {
@@ -728,6 +736,107 @@ func (impl *HelmAppServiceImpl) TemplateChart(ctx context.Context, templateChart
 	return response, nil
 }
 
+func (impl *HelmAppServiceImpl) GetReleaseManifest(ctx context.Context, app *AppIdentifier) (string, error) {
+	history, err := impl.GetDeploymentHistory(ctx, app)
+	if err != nil {
+		impl.logger.Errorw("error in getting deployment history", "err", err, "app", app)
+		return "", err
+	}
+	var latestVersion int32
+	for _, deployment := range history.GetDeploymentHistory() {
+		if deployment.GetVersion() > latestVersion {
+			latestVersion = deployment.GetVersion()
+		}
+	}
+	if latestVersion == 0 {
+		return "", fmt.Errorf("no revision found for release %s", app.ReleaseName)
+	}
+	deploymentDetail, err := impl.GetDeploymentDetail(ctx, app, latestVersion)
+	if err != nil {
+		return "", err
+	}
+	return deploymentDetail.GetManifest(), nil
+}
+
+func (impl *HelmAppServiceImpl) RenderChart(ctx context.Context, clusterId int, request *InstallReleaseRequest) (string, error) {
+	config, err := impl.GetClusterConf(clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cluster detail", "clusterId", clusterId, "err", err)
+		return "", err
+	}
+	request.ReleaseIdentifier.ClusterConfig = config
+	templateChartResponse, err := impl.helmAppClient.TemplateChart(ctx, request)
+	if err != nil {
+		impl.logger.Errorw("error in templating chart", "err", err, "chartName", request.ChartName, "chartVersion", request.ChartVersion)
+		return "", err
+	}
+	return templateChartResponse.GetGeneratedManifest(), nil
+}
+
+func (impl *HelmAppServiceImpl) DryRunUpdateApplication(ctx context.Context, app *AppIdentifier, request *openapi.UpdateReleaseRequest) (*ReleaseDiffResponse, error) {
+	installedApp, err := impl.installedAppRepository.GetInstalledApplicationByClusterIdAndNamespaceAndAppName(app.ClusterId, app.Namespace, app.ReleaseName)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting installed app", "err", err, "app", app)
+		return nil, err
+	}
+	if installedApp == nil || installedApp.Id == 0 {
+		return nil, &util.ApiError{
+			HttpStatusCode: http.StatusBadRequest,
+			Code:           "400",
+			UserMessage:    "dry run is not supported for this release as its chart repository is not known, link it to chart store to enable dry run",
+		}
+	}
+	installedAppVersion, err := impl.installedAppRepository.GetActiveInstalledAppVersionByInstalledAppId(installedApp.Id)
+	if err != nil {
+		impl.logger.Errorw("error in getting installed app version", "err", err, "installedAppId", installedApp.Id)
+		return nil, err
+	}
+	appStoreAppVersion := installedAppVersion.AppStoreApplicationVersion
+	chartRepo := appStoreAppVersion.AppStore.ChartRepo
+	updateReleaseRequest := &InstallReleaseRequest{
+		ReleaseIdentifier: &ReleaseIdentifier{
+			ReleaseName:      app.ReleaseName,
+			ReleaseNamespace: app.Namespace,
+		},
+		ChartName:    appStoreAppVersion.Name,
+		ChartVersion: appStoreAppVersion.Version,
+		ValuesYaml:   request.GetValuesYaml(),
+		ChartRepository: &ChartRepository{
+			Name:     chartRepo.Name,
+			Url:      chartRepo.Url,
+			Username: chartRepo.UserName,
+			Password: chartRepo.Password,
+		},
+	}
+	return impl.DryRunUpdateApplicationWithChartInfo(ctx, app.ClusterId, updateReleaseRequest)
+}
+
+func (impl *HelmAppServiceImpl) DryRunUpdateApplicationWithChartInfo(ctx context.Context, clusterId int, updateReleaseRequest *InstallReleaseRequest) (*ReleaseDiffResponse, error) {
+	app := &AppIdentifier{
+		ClusterId:   clusterId,
+		Namespace:   updateReleaseRequest.ReleaseIdentifier.ReleaseNamespace,
+		ReleaseName: updateReleaseRequest.ReleaseIdentifier.ReleaseName,
+	}
+	currentManifest, err := impl.GetReleaseManifest(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	desiredManifest, err := impl.RenderChart(ctx, clusterId, updateReleaseRequest)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := DiffReleaseManifests(currentManifest, desiredManifest)
+	if err != nil {
+		impl.logger.Errorw("error in diffing release manifests", "err", err, "app", app)
+		return nil, err
+	}
+	diff.ReleaseName = app.ReleaseName
+	diff.Namespace = app.Namespace
+	diff.ChartName = updateReleaseRequest.ChartName
+	diff.ChartVersion = updateReleaseRequest.ChartVersion
+	return diff, nil
+}
+
 type AppIdentifier struct {
 	ClusterId   int    `json:"clusterId"`
 	Namespace   string `json:"namespace"`
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/client/k8s/application"
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	RELEASE_RESOURCE_ACTION_CREATE    = "CREATE"
+	RELEASE_RESOURCE_ACTION_UPDATE    = "UPDATE"
+	RELEASE_RESOURCE_ACTION_DELETE    = "DELETE"
+	RELEASE_RESOURCE_ACTION_UNCHANGED = "UNCHANGED"
+)
+
+// values of secrets are never returned in diff, only the keys whose value is changed are marked
+const (
+	MASKED_SECRET_VALUE         = application.MASKED_SECRET_VALUE
+	MASKED_CHANGED_SECRET_VALUE = application.MASKED_CHANGED_SECRET_VALUE
+)
+
+var manifestDocumentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// ReleaseResourceDiff is change of one resource of release on upgrade, manifests are yaml and are not set for
+// unchanged resources. Diff is json merge patch from current to desired manifest.
+type ReleaseResourceDiff struct {
+	Group           string          `json:"group"`
+	Version         string          `json:"version"`
+	Kind            string          `json:"kind"`
+	Namespace       string          `json:"namespace,omitempty"`
+	Name            string          `json:"name"`
+	Action          string          `json:"action"`
+	CurrentManifest string          `json:"currentManifest,omitempty"`
+	DesiredManifest string          `json:"desiredManifest,omitempty"`
+	Diff            json.RawMessage `json:"diff,omitempty"`
+}
+
+type ReleaseDiffResponse struct {
+	ReleaseName    string                 `json:"releaseName"`
+	Namespace      string                 `json:"namespace"`
+	ChartName      string                 `json:"chartName,omitempty"`
+	ChartVersion   string                 `json:"chartVersion,omitempty"`
+	CreatedCount   int                    `json:"createdCount"`
+	UpdatedCount   int                    `json:"updatedCount"`
+	DeletedCount   int                    `json:"deletedCount"`
+	UnchangedCount int                    `json:"unchangedCount"`
+	Resources      []*ReleaseResourceDiff `json:"resources"`
+}
+
+// DiffReleaseManifests compares rendered manifests of current and desired release, current is empty for a release
+// which is not installed yet. Resources are matched on group, kind, namespace and name.
+func DiffReleaseManifests(currentManifest string, desiredManifest string) (*ReleaseDiffResponse, error) {
+	currentResources, err := parseReleaseManifest(currentManifest)
+	if err != nil {
+		return nil, fmt.Errorf("error in parsing current manifest: %v", err)
+	}
+	desiredResources, err := parseReleaseManifest(desiredManifest)
+	if err != nil {
+		return nil, fmt.Errorf("error in parsing desired manifest: %v", err)
+	}
+	response := &ReleaseDiffResponse{Resources: make([]*ReleaseResourceDiff, 0)}
+	for key, desired := range desiredResources {
+		current := currentResources[key]
+		resourceDiff, err := diffReleaseResource(current, desired)
+		if err != nil {
+			return nil, err
+		}
+		response.Resources = append(response.Resources, resourceDiff)
+	}
+	for key, current := range currentResources {
+		if _, ok := desiredResources[key]; ok {
+			continue
+		}
+		resourceDiff, err := diffReleaseResource(current, nil)
+		if err != nil {
+			return nil, err
+		}
+		response.Resources = append(response.Resources, resourceDiff)
+	}
+	for _, resourceDiff := range response.Resources {
+		switch resourceDiff.Action {
+		case RELEASE_RESOURCE_ACTION_CREATE:
+			response.CreatedCount++
+		case RELEASE_RESOURCE_ACTION_UPDATE:
+			response.UpdatedCount++
+		case RELEASE_RESOURCE_ACTION_DELETE:
+			response.DeletedCount++
+		default:
+			response.UnchangedCount++
+		}
+	}
+	sort.Slice(response.Resources, func(i, j int) bool {
+		a, b := response.Resources[i], response.Resources[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return response, nil
+}
+
+// parseReleaseManifest splits multi document manifest rendered by helm, items of lists are returned as separate resources
+func parseReleaseManifest(manifest string) (map[string]*unstructured.Unstructured, error) {
+	resources := make(map[string]*unstructured.Unstructured)
+	for _, document := range manifestDocumentSeparator.Split(manifest, -1) {
+		if len(strings.TrimSpace(document)) == 0 {
+			continue
+		}
+		jsonDocument, err := yaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			return nil, err
+		}
+		object := &unstructured.Unstructured{}
+		if string(jsonDocument) == "null" {
+			//document with comments only
+			continue
+		}
+		err = object.UnmarshalJSON(jsonDocument)
+		if err != nil {
+			return nil, err
+		}
+		objects := []*unstructured.Unstructured{object}
+		if object.IsList() {
+			list, err := object.ToList()
+			if err != nil {
+				return nil, err
+			}
+			objects = objects[:0]
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+		}
+		for _, item := range objects {
+			resources[releaseResourceKey(item)] = item
+		}
+	}
+	return resources, nil
+}
+
+func releaseResourceKey(object *unstructured.Unstructured) string {
+	gvk := object.GroupVersionKind()
+	return fmt.Sprintf("%s/%s/%s/%s", gvk.Group, gvk.Kind, object.GetNamespace(), object.GetName())
+}
+
+// diffReleaseResource either current or desired can be nil, for a resource being created or deleted respectively
+func diffReleaseResource(current *unstructured.Unstructured, desired *unstructured.Unstructured) (*ReleaseResourceDiff, error) {
+	object := desired
+	if object == nil {
+		object = current
+	}
+	gvk := object.GroupVersionKind()
+	resourceDiff := &ReleaseResourceDiff{
+		Group:     gvk.Group,
+		Version:   gvk.Version,
+		Kind:      gvk.Kind,
+		Namespace: object.GetNamespace(),
+		Name:      object.GetName(),
+	}
+	current, desired = application.MaskSecretData(current, desired)
+	diff, err := application.CreateManifestDiff(current, desired)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case current == nil:
+		resourceDiff.Action = RELEASE_RESOURCE_ACTION_CREATE
+	case desired == nil:
+		resourceDiff.Action = RELEASE_RESOURCE_ACTION_DELETE
+	case diff == nil:
+		resourceDiff.Action = RELEASE_RESOURCE_ACTION_UNCHANGED
+		return resourceDiff, nil
+	default:
+		resourceDiff.Action = RELEASE_RESOURCE_ACTION_UPDATE
+		resourceDiff.Diff = diff
+	}
+	if current != nil {
+		resourceDiff.CurrentManifest, err = toYamlManifest(current)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if desired != nil {
+		resourceDiff.DesiredManifest, err = toYamlManifest(desired)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resourceDiff, nil
+}
+
+func toYamlManifest(object *unstructured.Unstructured) (string, error) {
+	manifest, err := yaml.Marshal(application.NormalizeManifestForDiff(object).Object)
+	if err != nil {
+		return "", err
+	}
+	return string(manifest), nil
+}
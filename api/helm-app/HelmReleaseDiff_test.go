@@ -0,0 +1,156 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+const currentReleaseManifest = `
+---
+# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  namespace: demo
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: demo
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-old
+  namespace: demo
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: app
+  namespace: demo
+data:
+  password: b2xk
+  username: YWRtaW4=
+`
+
+const desiredReleaseManifest = `
+---
+# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  namespace: demo
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: demo
+spec:
+  replicas: 3
+---
+# Source: app/templates/empty.yaml
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: app-new
+    namespace: demo
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: app
+  namespace: demo
+data:
+  password: bmV3
+  username: YWRtaW4=
+`
+
+func findResourceDiff(response *ReleaseDiffResponse, kind string, name string) *ReleaseResourceDiff {
+	for _, resource := range response.Resources {
+		if resource.Kind == kind && resource.Name == name {
+			return resource
+		}
+	}
+	return nil
+}
+
+func TestDiffReleaseManifests(t *testing.T) {
+	response, err := DiffReleaseManifests(currentReleaseManifest, desiredReleaseManifest)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if response.CreatedCount != 1 || response.UpdatedCount != 2 || response.DeletedCount != 1 || response.UnchangedCount != 1 {
+		t.Errorf("unexpected counts %+v", response)
+	}
+	expectedActions := map[string]string{
+		"Service/app":       RELEASE_RESOURCE_ACTION_UNCHANGED,
+		"Deployment/app":    RELEASE_RESOURCE_ACTION_UPDATE,
+		"ConfigMap/app-new": RELEASE_RESOURCE_ACTION_CREATE,
+		"ConfigMap/app-old": RELEASE_RESOURCE_ACTION_DELETE,
+		"Secret/app":        RELEASE_RESOURCE_ACTION_UPDATE,
+	}
+	for key, action := range expectedActions {
+		parts := strings.Split(key, "/")
+		resource := findResourceDiff(response, parts[0], parts[1])
+		if resource == nil {
+			t.Errorf("resource %s not found in diff", key)
+			continue
+		}
+		if resource.Action != action {
+			t.Errorf("expected action %s for %s, got %s", action, key, resource.Action)
+		}
+	}
+	deployment := findResourceDiff(response, "Deployment", "app")
+	if deployment != nil && !strings.Contains(string(deployment.Diff), `"replicas":3`) {
+		t.Errorf("expected replicas in deployment diff, got %s", deployment.Diff)
+	}
+}
+
+func TestDiffReleaseManifestsMasksSecrets(t *testing.T) {
+	response, err := DiffReleaseManifests(currentReleaseManifest, desiredReleaseManifest)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	secret := findResourceDiff(response, "Secret", "app")
+	if secret == nil {
+		t.Fatalf("secret not found in diff")
+	}
+	for _, manifest := range []string{secret.CurrentManifest, secret.DesiredManifest, string(secret.Diff)} {
+		if strings.Contains(manifest, "bmV3") || strings.Contains(manifest, "b2xk") || strings.Contains(manifest, "YWRtaW4=") {
+			t.Errorf("secret value leaked in diff: %s", manifest)
+		}
+	}
+	if !strings.Contains(secret.DesiredManifest, "password: '"+MASKED_CHANGED_SECRET_VALUE+"'") {
+		t.Errorf("expected changed key to be marked, got %s", secret.DesiredManifest)
+	}
+	if !strings.Contains(secret.DesiredManifest, "username: '"+MASKED_SECRET_VALUE+"'") {
+		t.Errorf("expected unchanged key to be masked, got %s", secret.DesiredManifest)
+	}
+}
+
+func TestDiffReleaseManifestsForNewRelease(t *testing.T) {
+	response, err := DiffReleaseManifests("", desiredReleaseManifest)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if response.CreatedCount != 4 || len(response.Resources) != 4 {
+		t.Errorf("expected all resources to be created, got %+v", response)
+	}
+}
@@ -0,0 +1,325 @@
+package restHandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/appGroup"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util"
+	"github.com/devtron-labs/devtron/util/argo"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type AppGroupRestHandler interface {
+	CreateAppGroup(w http.ResponseWriter, r *http.Request)
+	UpdateAppGroup(w http.ResponseWriter, r *http.Request)
+	DeleteAppGroup(w http.ResponseWriter, r *http.Request)
+	GetAppGroup(w http.ResponseWriter, r *http.Request)
+	GetAllAppGroups(w http.ResponseWriter, r *http.Request)
+	GetAppGroupStatus(w http.ResponseWriter, r *http.Request)
+	PerformAppGroupAction(w http.ResponseWriter, r *http.Request)
+}
+
+type AppGroupRestHandlerImpl struct {
+	logger          *zap.SugaredLogger
+	userAuthService user.UserService
+	validator       *validator.Validate
+	enforcer        casbin.Enforcer
+	enforcerUtil    rbac.EnforcerUtil
+	appGroupService appGroup.AppGroupService
+	argoUserService argo.ArgoUserService
+}
+
+func NewAppGroupRestHandlerImpl(
+	logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	appGroupService appGroup.AppGroupService,
+	argoUserService argo.ArgoUserService) *AppGroupRestHandlerImpl {
+	return &AppGroupRestHandlerImpl{
+		logger:          logger,
+		userAuthService: userAuthService,
+		validator:       validator,
+		enforcer:        enforcer,
+		enforcerUtil:    enforcerUtil,
+		appGroupService: appGroupService,
+		argoUserService: argoUserService,
+	}
+}
+
+func (handler *AppGroupRestHandlerImpl) CreateAppGroup(w http.ResponseWriter, r *http.Request) {
+	handler.saveAppGroup(w, r, casbin.ActionCreate)
+}
+
+func (handler *AppGroupRestHandlerImpl) UpdateAppGroup(w http.ResponseWriter, r *http.Request) {
+	handler.saveAppGroup(w, r, casbin.ActionUpdate)
+}
+
+func (handler *AppGroupRestHandlerImpl) saveAppGroup(w http.ResponseWriter, r *http.Request, action string) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean appGroup.AppGroupDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveAppGroup", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SaveAppGroup", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, SaveAppGroup", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	//RBAC
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceAppGroup, action, strings.ToLower(bean.Name)); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	members, err := handler.appGroupService.FindAppGroupMembers(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, SaveAppGroup", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	//only apps on which user has update access can be grouped
+	if ok := handler.checkAuthForMembers(token, members, casbin.ActionUpdate); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC ends
+
+	var res *appGroup.AppGroupDto
+	if action == casbin.ActionCreate {
+		res, err = handler.appGroupService.CreateAppGroup(&bean)
+	} else {
+		res, err = handler.appGroupService.UpdateAppGroup(&bean)
+	}
+	if err != nil {
+		handler.logger.Errorw("service err, SaveAppGroup", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *AppGroupRestHandlerImpl) DeleteAppGroup(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	group, err := handler.appGroupService.GetAppGroup(id)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteAppGroup", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceAppGroup, casbin.ActionDelete, strings.ToLower(group.Name)); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	err = handler.appGroupService.DeleteAppGroup(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteAppGroup", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (handler *AppGroupRestHandlerImpl) GetAppGroup(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.appGroupService.GetAppGroup(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetAppGroup", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.checkAuthForAppGroupView(token, res); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *AppGroupRestHandlerImpl) GetAllAppGroups(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	res, err := handler.appGroupService.GetAllAppGroups()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllAppGroups", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	result := make([]*appGroup.AppGroupDto, 0)
+	for _, item := range res {
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceAppGroup, casbin.ActionGet, strings.ToLower(item.Name)); ok {
+			result = append(result, item)
+		}
+	}
+	//RBAC enforcer Ends
+	common.WriteJsonResp(w, nil, result, http.StatusOK)
+}
+
+func (handler *AppGroupRestHandlerImpl) GetAppGroupStatus(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	envId := 0
+	if envIdParam := r.URL.Query().Get("envId"); len(envIdParam) > 0 {
+		envId, err = strconv.Atoi(envIdParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	group, err := handler.appGroupService.GetAppGroup(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetAppGroupStatus", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.checkAuthForAppGroupView(token, group); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.appGroupService.GetAppGroupStatus(r.Context(), id, envId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetAppGroupStatus", "err", err, "id", id, "envId", envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *AppGroupRestHandlerImpl) PerformAppGroupAction(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var request appGroup.AppGroupActionRequest
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, PerformAppGroupAction", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.AppGroupId = id
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, PerformAppGroupAction", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	//RBAC, trigger access on group is checked by the service once the group is resolved, access on each app and env
+	//is checked along with it and the members without access are skipped by bulk actions
+	token := r.Header.Get("token")
+	//RBAC ends
+
+	acdToken, err := handler.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		handler.logger.Errorw("error in getting acd token", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	ctx := context.WithValue(kubernetesResourceAuditLogs.WithAuditContext(r.Context(), userId, util.GetClientIP(r)), "token", acdToken)
+	res, err := handler.appGroupService.PerformAppGroupAction(ctx, &request, token, handler.checkAuthForAppGroupTrigger, handler.checkAuthForAppGroupAction)
+	if err != nil {
+		handler.logger.Errorw("service err, PerformAppGroupAction", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *AppGroupRestHandlerImpl) checkAuthForAppGroupView(token string, group *appGroup.AppGroupDto) bool {
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceAppGroup, casbin.ActionGet, strings.ToLower(group.Name)); ok {
+		return true
+	}
+	return len(group.Apps) > 0 && handler.checkAuthForMembers(token, group.Apps, casbin.ActionGet)
+}
+
+func (handler *AppGroupRestHandlerImpl) checkAuthForMembers(token string, members []*appGroup.AppGroupApp, action string) bool {
+	if len(members) == 0 {
+		return false
+	}
+	rbacObjects := handler.enforcerUtil.GetRbacObjectsForAllApps()
+	for _, member := range members {
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, action, rbacObjects[member.AppId]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (handler *AppGroupRestHandlerImpl) checkAuthForAppGroupTrigger(token string, appGroupObject string) bool {
+	return handler.enforcer.Enforce(token, casbin.ResourceAppGroup, casbin.ActionTrigger, appGroupObject)
+}
+
+func (handler *AppGroupRestHandlerImpl) checkAuthForAppGroupAction(token string, appObject string, envObject string) bool {
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionUpdate, strings.ToLower(appObject)); !ok {
+		return false
+	}
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionUpdate, strings.ToLower(envObject)); !ok {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,324 @@
+package restHandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/appClone"
+	"github.com/devtron-labs/devtron/pkg/team"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/argo"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type AppTemplateRestHandler interface {
+	SaveAppTemplate(w http.ResponseWriter, r *http.Request)
+	DeleteAppTemplate(w http.ResponseWriter, r *http.Request)
+	GetAppTemplate(w http.ResponseWriter, r *http.Request)
+	GetAllAppTemplates(w http.ResponseWriter, r *http.Request)
+	GetAppTemplateVersions(w http.ResponseWriter, r *http.Request)
+	CreateAppFromTemplate(w http.ResponseWriter, r *http.Request)
+	GetTemplateApps(w http.ResponseWriter, r *http.Request)
+	GetTemplateForApp(w http.ResponseWriter, r *http.Request)
+}
+
+type AppTemplateRestHandlerImpl struct {
+	logger             *zap.SugaredLogger
+	userAuthService    user.UserService
+	validator          *validator.Validate
+	enforcer           casbin.Enforcer
+	enforcerUtil       rbac.EnforcerUtil
+	teamService        team.TeamService
+	appTemplateService appClone.AppTemplateService
+	argoUserService    argo.ArgoUserService
+}
+
+func NewAppTemplateRestHandlerImpl(
+	logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	teamService team.TeamService,
+	appTemplateService appClone.AppTemplateService,
+	argoUserService argo.ArgoUserService) *AppTemplateRestHandlerImpl {
+	return &AppTemplateRestHandlerImpl{
+		logger:             logger,
+		userAuthService:    userAuthService,
+		validator:          validator,
+		enforcer:           enforcer,
+		enforcerUtil:       enforcerUtil,
+		teamService:        teamService,
+		appTemplateService: appTemplateService,
+		argoUserService:    argoUserService,
+	}
+}
+
+func (handler *AppTemplateRestHandlerImpl) SaveAppTemplate(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean appClone.AppTemplateDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveAppTemplate", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SaveAppTemplate", "name", bean.Name, "refAppId", bean.RefAppId)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, SaveAppTemplate", "err", err, "name", bean.Name)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	action := casbin.ActionCreate
+	if bean.Id > 0 {
+		action = casbin.ActionUpdate
+	}
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, action, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	if bean.RefAppId > 0 {
+		object := handler.enforcerUtil.GetAppRBACNameByAppId(bean.RefAppId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
+			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
+		}
+	}
+	//RBAC enforcer Ends
+
+	res, err := handler.appTemplateService.SaveAppTemplate(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, SaveAppTemplate", "err", err, "name", bean.Name)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *AppTemplateRestHandlerImpl) DeleteAppTemplate(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionDelete, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	err = handler.appTemplateService.DeleteAppTemplate(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteAppTemplate", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (handler *AppTemplateRestHandlerImpl) GetAppTemplate(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	version := 0
+	if versionParam := r.URL.Query().Get("version"); len(versionParam) > 0 {
+		version, err = strconv.Atoi(versionParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	//template data carries captured config including secrets
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.appTemplateService.GetAppTemplate(id, version)
+	if err != nil {
+		handler.logger.Errorw("service err, GetAppTemplate", "err", err, "id", id, "version", version)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *AppTemplateRestHandlerImpl) GetAllAppTemplates(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	//auth free, only login required, templates are listed for creating apps
+	res, err := handler.appTemplateService.GetAllAppTemplates()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllAppTemplates", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *AppTemplateRestHandlerImpl) GetAppTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	//auth free, only login required, versions carry declared parameters without template data
+	res, err := handler.appTemplateService.GetAppTemplateVersions(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetAppTemplateVersions", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *AppTemplateRestHandlerImpl) CreateAppFromTemplate(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var request appClone.CreateAppFromTemplateRequest
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, CreateAppFromTemplate", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.AppTemplateId = id
+	request.UserId = userId
+	handler.logger.Infow("request payload, CreateAppFromTemplate", "appTemplateId", id, "appName", request.AppName)
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, CreateAppFromTemplate", "err", err, "appName", request.AppName)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	//RBAC same as creating app
+	project, err := handler.teamService.FetchOne(request.TeamId)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionCreate, fmt.Sprintf("%s/%s", strings.ToLower(project.Name), "*")); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC ends
+
+	acdToken, err := handler.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		handler.logger.Errorw("error in getting acd token", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	ctx := context.WithValue(r.Context(), "token", acdToken)
+	res, err := handler.appTemplateService.CreateAppFromTemplate(ctx, &request)
+	if err != nil {
+		handler.logger.Errorw("service err, CreateAppFromTemplate", "err", err, "appTemplateId", id, "appName", request.AppName)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *AppTemplateRestHandlerImpl) GetTemplateApps(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.appTemplateService.GetTemplateApps(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetTemplateApps", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	rbacObjects := handler.enforcerUtil.GetRbacObjectsForAllApps()
+	result := make([]*appClone.AppTemplateAppDto, 0)
+	for _, item := range res {
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, rbacObjects[item.AppId]); ok {
+			result = append(result, item)
+		}
+	}
+	//RBAC enforcer Ends
+	common.WriteJsonResp(w, nil, result, http.StatusOK)
+}
+
+func (handler *AppTemplateRestHandlerImpl) GetTemplateForApp(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	appId, err := strconv.Atoi(mux.Vars(r)["appId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.appTemplateService.GetTemplateForApp(appId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetTemplateForApp", "err", err, "appId", appId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
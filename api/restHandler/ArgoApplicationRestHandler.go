@@ -105,8 +105,13 @@ func NewArgoApplicationRestHandlerImpl(client application.ServiceClient,
 }
 
 func (impl ArgoApplicationRestHandlerImpl) GetTerminalSession(w http.ResponseWriter, r *http.Request) {
+	userId, err := impl.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
 	token := r.Header.Get("token")
-	request := &terminal.TerminalSessionRequest{}
+	request := &terminal.TerminalSessionRequest{UserId: userId}
 	vars := mux.Vars(r)
 	request.ContainerName = vars["container"]
 	request.Namespace = vars["namespace"]
@@ -33,6 +33,11 @@ type BulkUpdateRestHandler interface {
 	FindBulkUpdateReadme(w http.ResponseWriter, r *http.Request)
 	GetImpactedAppsName(w http.ResponseWriter, r *http.Request)
 	BulkUpdate(w http.ResponseWriter, r *http.Request)
+	GetBulkUpdateJobs(w http.ResponseWriter, r *http.Request)
+	GetBulkUpdateJob(w http.ResponseWriter, r *http.Request)
+	RevertBulkUpdateJob(w http.ResponseWriter, r *http.Request)
+	CiBulkUpdate(w http.ResponseWriter, r *http.Request)
+	CloneEnvironment(w http.ResponseWriter, r *http.Request)
 
 	BulkHibernate(w http.ResponseWriter, r *http.Request)
 	BulkUnHibernate(w http.ResponseWriter, r *http.Request)
@@ -209,9 +214,14 @@ func (handler BulkUpdateRestHandlerImpl) CheckAuthForBulkUpdate(AppId int, EnvId
 
 }
 func (handler BulkUpdateRestHandlerImpl) BulkUpdate(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
 	decoder := json.NewDecoder(r.Body)
 	var script bulkAction.BulkUpdateScript
-	err := decoder.Decode(&script)
+	err = decoder.Decode(&script)
 	if err != nil {
 		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
 		return
@@ -233,25 +243,186 @@ func (handler BulkUpdateRestHandlerImpl) BulkUpdate(w http.ResponseWriter, r *ht
 		ok := handler.CheckAuthForBulkUpdate(deploymentTemplateImpactedApp.AppId, deploymentTemplateImpactedApp.EnvId, deploymentTemplateImpactedApp.AppName, rbacObjects, token)
 		if !ok {
 			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
 		}
 	}
 	for _, configMapImpactedApp := range impactedApps.ConfigMap {
 		ok := handler.CheckAuthForBulkUpdate(configMapImpactedApp.AppId, configMapImpactedApp.EnvId, configMapImpactedApp.AppName, rbacObjects, token)
 		if !ok {
 			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
 		}
 	}
 	for _, secretImpactedApp := range impactedApps.Secret {
 		ok := handler.CheckAuthForBulkUpdate(secretImpactedApp.AppId, secretImpactedApp.EnvId, secretImpactedApp.AppName, rbacObjects, token)
 		if !ok {
 			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
 		}
 	}
 
+	script.Spec.UserId = userId
+	v := r.URL.Query()
+	if dryRun, _ := strconv.ParseBool(v.Get("dryRun")); dryRun {
+		response := handler.bulkUpdateService.BulkUpdateDryRun(script.Spec)
+		common.WriteJsonResp(w, nil, response, http.StatusOK)
+		return
+	}
+	if async, _ := strconv.ParseBool(v.Get("async")); async {
+		job, err := handler.bulkUpdateService.BulkUpdateAsync(script.Spec)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		common.WriteJsonResp(w, nil, job, http.StatusAccepted)
+		return
+	}
 	response := handler.bulkUpdateService.BulkUpdate(script.Spec)
 	common.WriteJsonResp(w, nil, response, http.StatusOK)
 }
 
+func (handler BulkUpdateRestHandlerImpl) CiBulkUpdate(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var script bulkAction.CiBulkUpdateScript
+	err = decoder.Decode(&script)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = handler.validator.Struct(script)
+	if err != nil {
+		handler.logger.Errorw("validation err, CiBulkUpdateScript", "err", err, "CiBulkUpdateScript", script)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	impactedCiPipelines, err := handler.bulkUpdateService.GetCiBulkUpdateImpactedPipelines(script.Spec)
+	if err != nil {
+		handler.logger.Errorw("error in getting impacted ci pipelines", "err", err, "payload", script.Spec)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	rbacObjects := handler.enforcerUtil.GetRbacObjectsForAllApps()
+	for _, ciPipeline := range impactedCiPipelines {
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionUpdate, rbacObjects[ciPipeline.AppId]); !ok {
+			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
+		}
+	}
+	script.Spec.UserId = userId
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	response, err := handler.bulkUpdateService.BulkUpdateCiPipelines(script.Spec, dryRun)
+	if err != nil {
+		handler.logger.Errorw("error in ci bulk update", "err", err, "payload", script.Spec)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
+// checkAuthForBulkUpdateJob checks the action for every app and env changed by the job, creator of a job can always see it
+func (handler BulkUpdateRestHandlerImpl) checkAuthForBulkUpdateJob(job *bulkAction.BulkUpdateJobDto, action string, userId int32, token string) bool {
+	if action == casbin.ActionGet && job.CreatedBy == userId {
+		return true
+	}
+	rbacObjects := handler.enforcerUtil.GetRbacObjectsForAllApps()
+	for _, item := range job.Items {
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, action, rbacObjects[item.AppId]); !ok {
+			return false
+		}
+		if item.EnvId > 0 {
+			resourceName := handler.enforcerUtil.GetAppRBACByAppNameAndEnvId(item.AppName, item.EnvId)
+			if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, action, resourceName); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (handler BulkUpdateRestHandlerImpl) GetBulkUpdateJobs(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	jobs, err := handler.bulkUpdateService.GetBulkUpdateJobs()
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	//super admin sees all jobs, others see the jobs created by them
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		filteredJobs := make([]*bulkAction.BulkUpdateJobDto, 0)
+		for _, job := range jobs {
+			if job.CreatedBy == userId {
+				filteredJobs = append(filteredJobs, job)
+			}
+		}
+		jobs = filteredJobs
+	}
+	common.WriteJsonResp(w, nil, jobs, http.StatusOK)
+}
+
+func (handler BulkUpdateRestHandlerImpl) GetBulkUpdateJob(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	jobId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	job, err := handler.bulkUpdateService.GetBulkUpdateJob(jobId)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.checkAuthForBulkUpdateJob(job, casbin.ActionGet, userId, token); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	common.WriteJsonResp(w, nil, job, http.StatusOK)
+}
+
+func (handler BulkUpdateRestHandlerImpl) RevertBulkUpdateJob(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	jobId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	job, err := handler.bulkUpdateService.GetBulkUpdateJob(jobId)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	if ok := handler.checkAuthForBulkUpdateJob(job, casbin.ActionUpdate, userId, token); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	response, err := handler.bulkUpdateService.RevertBulkUpdateJob(jobId, userId)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
 func (handler BulkUpdateRestHandlerImpl) BulkHibernate(w http.ResponseWriter, r *http.Request) {
 	userId, err := handler.userAuthService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
@@ -398,6 +569,58 @@ func (handler BulkUpdateRestHandlerImpl) BulkBuildTrigger(w http.ResponseWriter,
 	common.WriteJsonResp(w, nil, response, http.StatusOK)
 }
 
+func (handler BulkUpdateRestHandlerImpl) CloneEnvironment(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request appClone.EnvCloneRequest
+	err = decoder.Decode(&request)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err", "err", err, "request", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	acdToken, err := handler.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		handler.logger.Errorw("error in getting acd token", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	ctx := context.WithValue(r.Context(), "token", acdToken)
+	token := r.Header.Get("token")
+	//rbac is checked per app, apps without get access on source env and create access on app and target env are reported as failed
+	response, err := handler.appCloneService.CloneEnvironment(ctx, &request, token, handler.checkAuthForEnvClone)
+	if err != nil {
+		handler.logger.Errorw("service err, CloneEnvironment", "err", err, "request", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
+func (handler BulkUpdateRestHandlerImpl) checkAuthForEnvClone(token string, appObject string, sourceEnvObject string, targetEnvObject string) bool {
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionCreate, strings.ToLower(appObject)); !ok {
+		return false
+	}
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionGet, strings.ToLower(sourceEnvObject)); !ok {
+		return false
+	}
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionCreate, strings.ToLower(targetEnvObject)); !ok {
+		return false
+	}
+	return true
+}
+
 func (handler BulkUpdateRestHandlerImpl) checkAuthForBulkActions(token string, appObject string, envObject string) bool {
 	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionUpdate, strings.ToLower(appObject)); !ok {
 		return false
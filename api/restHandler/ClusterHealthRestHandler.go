@@ -0,0 +1,127 @@
+package restHandler
+
+import (
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/clusterHealth"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type ClusterHealthRestHandler interface {
+	GetClusterHealth(w http.ResponseWriter, r *http.Request)
+	GetClusterHealthHistory(w http.ResponseWriter, r *http.Request)
+	CheckClusterHealth(w http.ResponseWriter, r *http.Request)
+}
+
+type ClusterHealthRestHandlerImpl struct {
+	logger               *zap.SugaredLogger
+	userAuthService      user.UserService
+	enforcer             casbin.Enforcer
+	clusterService       cluster.ClusterService
+	clusterHealthService clusterHealth.ClusterHealthService
+}
+
+func NewClusterHealthRestHandlerImpl(logger *zap.SugaredLogger, userAuthService user.UserService, enforcer casbin.Enforcer,
+	clusterService cluster.ClusterService, clusterHealthService clusterHealth.ClusterHealthService) *ClusterHealthRestHandlerImpl {
+	return &ClusterHealthRestHandlerImpl{
+		logger:               logger,
+		userAuthService:      userAuthService,
+		enforcer:             enforcer,
+		clusterService:       clusterService,
+		clusterHealthService: clusterHealthService,
+	}
+}
+
+func (handler *ClusterHealthRestHandlerImpl) GetClusterHealth(w http.ResponseWriter, r *http.Request) {
+	clusterId, ok := handler.authorizeCluster(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	res, err := handler.clusterHealthService.GetClusterHealth(clusterId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetClusterHealth", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *ClusterHealthRestHandlerImpl) GetClusterHealthHistory(w http.ResponseWriter, r *http.Request) {
+	clusterId, ok := handler.authorizeCluster(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	offset, size := 0, 20
+	var err error
+	if offsetParam := r.URL.Query().Get("offset"); len(offsetParam) > 0 {
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	if sizeParam := r.URL.Query().Get("size"); len(sizeParam) > 0 {
+		size, err = strconv.Atoi(sizeParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	res, err := handler.clusterHealthService.GetClusterHealthHistory(clusterId, offset, size)
+	if err != nil {
+		handler.logger.Errorw("service err, GetClusterHealthHistory", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *ClusterHealthRestHandlerImpl) CheckClusterHealth(w http.ResponseWriter, r *http.Request) {
+	clusterId, ok := handler.authorizeCluster(w, r, casbin.ActionUpdate)
+	if !ok {
+		return
+	}
+	res, err := handler.clusterHealthService.CheckClusterHealth(clusterId)
+	if err != nil {
+		handler.logger.Errorw("service err, CheckClusterHealth", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+// authorizeCluster checks action on cluster of id in path, error response is written when it returns false
+func (handler *ClusterHealthRestHandlerImpl) authorizeCluster(w http.ResponseWriter, r *http.Request, action string) (int, bool) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return 0, false
+	}
+	clusterId, err := strconv.Atoi(mux.Vars(r)["clusterId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return 0, false
+	}
+	clusterBean, err := handler.clusterService.FindByIdWithoutConfig(clusterId)
+	if err != nil {
+		handler.logger.Errorw("service err, FindByIdWithoutConfig", "err", err, "clusterId", clusterId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return 0, false
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceCluster, action, strings.ToLower(clusterBean.ClusterName)); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return 0, false
+	}
+	//RBAC enforcer Ends
+	return clusterId, true
+}
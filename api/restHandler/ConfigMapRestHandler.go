@@ -18,19 +18,25 @@
 package restHandler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/devtron-labs/devtron/api/bean"
 	"github.com/devtron-labs/devtron/api/restHandler/common"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/chart"
+	"github.com/devtron-labs/devtron/pkg/externalSecret"
 	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
 	"github.com/devtron-labs/devtron/pkg/team"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/argo"
 	"github.com/devtron-labs/devtron/util/rbac"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
 	"net/http"
 	"strconv"
 )
@@ -54,6 +60,15 @@ type ConfigMapRestHandler interface {
 	CSGlobalFetchForEdit(w http.ResponseWriter, r *http.Request)
 	CSEnvironmentFetchForEdit(w http.ResponseWriter, r *http.Request)
 	ConfigSecretBulkPatch(w http.ResponseWriter, r *http.Request)
+
+	SaveDefaultSecretStore(w http.ResponseWriter, r *http.Request)
+	GetDefaultSecretStores(w http.ResponseWriter, r *http.Request)
+	DeleteDefaultSecretStore(w http.ResponseWriter, r *http.Request)
+	TestExternalSecretConnection(w http.ResponseWriter, r *http.Request)
+
+	GetConfigHistoryVersions(w http.ResponseWriter, r *http.Request)
+	GetConfigHistoryDiff(w http.ResponseWriter, r *http.Request)
+	RestoreConfigHistoryVersion(w http.ResponseWriter, r *http.Request)
 }
 
 type ConfigMapRestHandlerImpl struct {
@@ -66,12 +81,19 @@ type ConfigMapRestHandlerImpl struct {
 	pipelineRepository pipelineConfig.PipelineRepository
 	enforcerUtil       rbac.EnforcerUtil
 	configMapService   pipeline.ConfigMapService
+	validator          *validator.Validate
+
+	externalSecretProviderService externalSecret.ExternalSecretProviderService
+	configMapVersionService       pipeline.ConfigMapVersionService
+	argoUserService               argo.ArgoUserService
 }
 
 func NewConfigMapRestHandlerImpl(pipelineBuilder pipeline.PipelineBuilder, Logger *zap.SugaredLogger,
 	chartService chart.ChartService, userAuthService user.UserService, teamService team.TeamService,
 	enforcer casbin.Enforcer, pipelineRepository pipelineConfig.PipelineRepository,
-	enforcerUtil rbac.EnforcerUtil, configMapService pipeline.ConfigMapService) *ConfigMapRestHandlerImpl {
+	enforcerUtil rbac.EnforcerUtil, configMapService pipeline.ConfigMapService, validator *validator.Validate,
+	externalSecretProviderService externalSecret.ExternalSecretProviderService,
+	configMapVersionService pipeline.ConfigMapVersionService, argoUserService argo.ArgoUserService) *ConfigMapRestHandlerImpl {
 	return &ConfigMapRestHandlerImpl{
 		pipelineBuilder:    pipelineBuilder,
 		Logger:             Logger,
@@ -82,6 +104,11 @@ func NewConfigMapRestHandlerImpl(pipelineBuilder pipeline.PipelineBuilder, Logge
 		pipelineRepository: pipelineRepository,
 		enforcerUtil:       enforcerUtil,
 		configMapService:   configMapService,
+		validator:          validator,
+
+		externalSecretProviderService: externalSecretProviderService,
+		configMapVersionService:       configMapVersionService,
+		argoUserService:               argoUserService,
 	}
 }
 
@@ -703,3 +730,309 @@ func (handler ConfigMapRestHandlerImpl) ConfigSecretBulkPatch(w http.ResponseWri
 	}
 	common.WriteJsonResp(w, err, true, http.StatusOK)
 }
+
+func (handler ConfigMapRestHandlerImpl) SaveDefaultSecretStore(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request externalSecret.DefaultSecretStoreDto
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.Logger.Errorw("request err, SaveDefaultSecretStore", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.Logger.Errorw("validation err, SaveDefaultSecretStore", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.Logger.Infow("request payload, SaveDefaultSecretStore", "payload", request)
+
+	//RBAC START
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC END
+
+	res, err := handler.externalSecretProviderService.SaveDefaultSecretStore(&request)
+	if err != nil {
+		handler.Logger.Errorw("service err, SaveDefaultSecretStore", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler ConfigMapRestHandlerImpl) GetDefaultSecretStores(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	envId, err := strconv.Atoi(vars["envId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	//RBAC START
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionGet, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC END
+
+	res, err := handler.externalSecretProviderService.GetDefaultSecretStores(envId)
+	if err != nil {
+		handler.Logger.Errorw("service err, GetDefaultSecretStores", "err", err, "envId", envId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler ConfigMapRestHandlerImpl) DeleteDefaultSecretStore(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	envId, err := strconv.Atoi(vars["envId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	//RBAC START
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC END
+
+	err = handler.externalSecretProviderService.DeleteDefaultSecretStore(envId, id, userId)
+	if util.IsErrNoRows(err) {
+		common.WriteJsonResp(w, fmt.Errorf("default secret store not found for environment"), nil, http.StatusNotFound)
+		return
+	} else if err != nil {
+		handler.Logger.Errorw("service err, DeleteDefaultSecretStore", "err", err, "envId", envId, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, true, http.StatusOK)
+}
+
+func (handler ConfigMapRestHandlerImpl) TestExternalSecretConnection(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request externalSecret.ConnectionTestRequest
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.Logger.Errorw("request err, TestExternalSecretConnection", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.Logger.Errorw("validation err, TestExternalSecretConnection", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	//RBAC START
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC END
+
+	res, err := handler.externalSecretProviderService.TestConnection(&request)
+	if err != nil {
+		handler.Logger.Errorw("service err, TestExternalSecretConnection", "err", err, "externalType", request.ExternalType)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+// getConfigHistoryRequest parses the common params of history apis, envId is 0 for app level history
+func (handler ConfigMapRestHandlerImpl) getConfigHistoryRequest(r *http.Request) (appId int, envId int, configType repository.ConfigType, name string, err error) {
+	vars := mux.Vars(r)
+	appId, err = strconv.Atoi(vars["appId"])
+	if err != nil {
+		return appId, envId, configType, name, err
+	}
+	if len(vars["envId"]) > 0 {
+		envId, err = strconv.Atoi(vars["envId"])
+		if err != nil {
+			return appId, envId, configType, name, err
+		}
+	}
+	configType = repository.ConfigType(vars["type"])
+	if configType != repository.CONFIGMAP_TYPE && configType != repository.SECRET_TYPE {
+		return appId, envId, configType, name, fmt.Errorf("invalid type %s, supported types are %s and %s", configType, repository.CONFIGMAP_TYPE, repository.SECRET_TYPE)
+	}
+	return appId, envId, configType, vars["name"], nil
+}
+
+// checkConfigHistoryAccess enforces the action on app and, for env level history, on the environment
+func (handler ConfigMapRestHandlerImpl) checkConfigHistoryAccess(token string, appId int, envId int, action string) bool {
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, action, handler.enforcerUtil.GetAppRBACNameByAppId(appId)); !ok {
+		return false
+	}
+	if envId > 0 {
+		return handler.enforcer.Enforce(token, casbin.ResourceEnvironment, action, handler.enforcerUtil.GetEnvRBACNameByAppId(appId, envId))
+	}
+	return true
+}
+
+func (handler ConfigMapRestHandlerImpl) GetConfigHistoryVersions(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	appId, envId, configType, name, err := handler.getConfigHistoryRequest(r)
+	if err != nil {
+		handler.Logger.Errorw("request err, GetConfigHistoryVersions", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	//RBAC START
+	token := r.Header.Get("token")
+	if ok := handler.checkConfigHistoryAccess(token, appId, envId, casbin.ActionGet); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC END
+
+	res, err := handler.configMapVersionService.GetVersions(appId, envId, configType, name)
+	if err != nil {
+		handler.Logger.Errorw("service err, GetConfigHistoryVersions", "err", err, "appId", appId, "envId", envId, "name", name)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler ConfigMapRestHandlerImpl) GetConfigHistoryDiff(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	appId, envId, configType, name, err := handler.getConfigHistoryRequest(r)
+	if err != nil {
+		handler.Logger.Errorw("request err, GetConfigHistoryDiff", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	vars := mux.Vars(r)
+	fromId, err := strconv.Atoi(vars["from"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	toId, err := strconv.Atoi(vars["to"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	//RBAC START
+	token := r.Header.Get("token")
+	if ok := handler.checkConfigHistoryAccess(token, appId, envId, casbin.ActionGet); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC END
+	//secret values are shown only to users who can edit them
+	userHasAdminAccess := handler.checkConfigHistoryAccess(token, appId, envId, casbin.ActionUpdate)
+
+	res, err := handler.configMapVersionService.GetVersionDiff(appId, envId, configType, name, fromId, toId, userHasAdminAccess)
+	if err != nil {
+		handler.Logger.Errorw("service err, GetConfigHistoryDiff", "err", err, "appId", appId, "envId", envId, "name", name)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler ConfigMapRestHandlerImpl) RestoreConfigHistoryVersion(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request pipeline.ConfigMapRestoreRequest
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.Logger.Errorw("request err, RestoreConfigHistoryVersion", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.Logger.Errorw("validation err, RestoreConfigHistoryVersion", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	handler.Logger.Infow("request payload, RestoreConfigHistoryVersion", "payload", request)
+
+	//RBAC START
+	token := r.Header.Get("token")
+	if ok := handler.checkConfigHistoryAccess(token, request.AppId, request.EnvironmentId, casbin.ActionUpdate); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	if request.Redeploy {
+		if ok := handler.checkConfigHistoryAccess(token, request.AppId, request.EnvironmentId, casbin.ActionTrigger); !ok {
+			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
+		}
+	}
+	//RBAC END
+
+	ctx := r.Context()
+	if request.Redeploy {
+		acdToken, err := handler.argoUserService.GetLatestDevtronArgoCdUserToken()
+		if err != nil {
+			handler.Logger.Errorw("error in getting acd token", "err", err)
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		ctx = context.WithValue(r.Context(), "token", acdToken)
+	}
+	res, err := handler.configMapVersionService.RestoreVersion(ctx, &request)
+	if err != nil {
+		handler.Logger.Errorw("service err, RestoreConfigHistoryVersion", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, res, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
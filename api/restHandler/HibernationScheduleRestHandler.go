@@ -0,0 +1,282 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/hibernation"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type HibernationScheduleRestHandler interface {
+	SaveSchedule(w http.ResponseWriter, r *http.Request)
+	GetSchedule(w http.ResponseWriter, r *http.Request)
+	GetAllSchedules(w http.ResponseWriter, r *http.Request)
+	DeleteSchedule(w http.ResponseWriter, r *http.Request)
+	SkipNextHibernation(w http.ResponseWriter, r *http.Request)
+	ExtendSchedule(w http.ResponseWriter, r *http.Request)
+	GetScheduleHistory(w http.ResponseWriter, r *http.Request)
+}
+
+type HibernationScheduleRestHandlerImpl struct {
+	logger                     *zap.SugaredLogger
+	userAuthService            user.UserService
+	validator                  *validator.Validate
+	enforcer                   casbin.Enforcer
+	enforcerUtil               rbac.EnforcerUtil
+	hibernationScheduleService hibernation.HibernationScheduleService
+}
+
+func NewHibernationScheduleRestHandlerImpl(
+	logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	hibernationScheduleService hibernation.HibernationScheduleService) *HibernationScheduleRestHandlerImpl {
+	return &HibernationScheduleRestHandlerImpl{
+		logger:                     logger,
+		userAuthService:            userAuthService,
+		validator:                  validator,
+		enforcer:                   enforcer,
+		enforcerUtil:               enforcerUtil,
+		hibernationScheduleService: hibernationScheduleService,
+	}
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) SaveSchedule(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean hibernation.HibernationScheduleDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveSchedule", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SaveSchedule", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, SaveSchedule", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if !handler.checkScheduleAuth(token, userId, &bean, casbin.ActionUpdate) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	if bean.Id > 0 {
+		//access on the targets being replaced is needed as well
+		existing, err := handler.hibernationScheduleService.GetSchedule(bean.Id)
+		if err != nil {
+			handler.logger.Errorw("service err, SaveSchedule", "err", err, "id", bean.Id)
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		if !handler.checkScheduleAuth(token, userId, existing, casbin.ActionUpdate) {
+			common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+			return
+		}
+	}
+	//RBAC enforcer Ends
+
+	var res *hibernation.HibernationScheduleDto
+	if bean.Id > 0 {
+		res, err = handler.hibernationScheduleService.UpdateSchedule(&bean)
+	} else {
+		res, err = handler.hibernationScheduleService.CreateSchedule(&bean)
+	}
+	if err != nil {
+		handler.logger.Errorw("service err, SaveSchedule", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	userId, schedule, ok := handler.getAuthorizedSchedule(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	handler.logger.Debugw("request, GetSchedule", "id", schedule.Id, "userId", userId)
+	common.WriteJsonResp(w, nil, schedule, http.StatusOK)
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) GetAllSchedules(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	schedules, err := handler.hibernationScheduleService.GetAllSchedules()
+	if err != nil {
+		handler.logger.Errorw("service err, GetAllSchedules", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	res := make([]*hibernation.HibernationScheduleDto, 0, len(schedules))
+	for _, schedule := range schedules {
+		if handler.checkScheduleAuth(token, userId, schedule, casbin.ActionGet) {
+			res = append(res, schedule)
+		}
+	}
+	//RBAC enforcer Ends
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	userId, schedule, ok := handler.getAuthorizedSchedule(w, r, casbin.ActionUpdate)
+	if !ok {
+		return
+	}
+	err := handler.hibernationScheduleService.DeleteSchedule(schedule.Id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteSchedule", "err", err, "id", schedule.Id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, schedule.Id, http.StatusOK)
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) SkipNextHibernation(w http.ResponseWriter, r *http.Request) {
+	userId, schedule, ok := handler.getAuthorizedSchedule(w, r, casbin.ActionUpdate)
+	if !ok {
+		return
+	}
+	res, err := handler.hibernationScheduleService.SkipNextHibernation(schedule.Id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, SkipNextHibernation", "err", err, "id", schedule.Id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) ExtendSchedule(w http.ResponseWriter, r *http.Request) {
+	userId, schedule, ok := handler.getAuthorizedSchedule(w, r, casbin.ActionUpdate)
+	if !ok {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request hibernation.HibernationExtendRequest
+	err := decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, ExtendSchedule", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, ExtendSchedule", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.hibernationScheduleService.ExtendSchedule(schedule.Id, &request, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, ExtendSchedule", "err", err, "id", schedule.Id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *HibernationScheduleRestHandlerImpl) GetScheduleHistory(w http.ResponseWriter, r *http.Request) {
+	_, schedule, ok := handler.getAuthorizedSchedule(w, r, casbin.ActionGet)
+	if !ok {
+		return
+	}
+	offset, size := 0, 20
+	var err error
+	if offsetParam := r.URL.Query().Get("offset"); len(offsetParam) > 0 {
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	if sizeParam := r.URL.Query().Get("size"); len(sizeParam) > 0 {
+		size, err = strconv.Atoi(sizeParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	res, err := handler.hibernationScheduleService.GetScheduleHistory(schedule.Id, offset, size)
+	if err != nil {
+		handler.logger.Errorw("service err, GetScheduleHistory", "err", err, "id", schedule.Id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+// getAuthorizedSchedule loads the schedule of id in path and checks action on its targets, error response is written
+// when it returns false
+func (handler *HibernationScheduleRestHandlerImpl) getAuthorizedSchedule(w http.ResponseWriter, r *http.Request, action string) (int32, *hibernation.HibernationScheduleDto, bool) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return userId, nil, false
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return userId, nil, false
+	}
+	schedule, err := handler.hibernationScheduleService.GetSchedule(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetSchedule", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return userId, nil, false
+	}
+	token := r.Header.Get("token")
+	if !handler.checkScheduleAuth(token, userId, schedule, action) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return userId, nil, false
+	}
+	return userId, schedule, true
+}
+
+// checkScheduleAuth app scoped schedules need action on every app and env of the schedule, schedules of other scopes
+// can cover apps created later on so they are managed by super admins only
+func (handler *HibernationScheduleRestHandlerImpl) checkScheduleAuth(token string, userId int32, schedule *hibernation.HibernationScheduleDto, action string) bool {
+	if schedule.Scope != hibernation.HIBERNATION_SCOPE_APP || schedule.Target == nil {
+		isSuperAdmin, err := handler.userAuthService.IsSuperAdmin(int(userId))
+		if err != nil {
+			handler.logger.Errorw("error in checking super admin", "err", err, "userId", userId)
+			return false
+		}
+		return isSuperAdmin
+	}
+	for _, appId := range schedule.Target.AppIds {
+		appObject := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+		if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, action, appObject); !ok {
+			return false
+		}
+		for _, envId := range schedule.Target.EnvIds {
+			envObject := handler.enforcerUtil.GetEnvRBACNameByAppId(appId, envId)
+			if ok := handler.enforcer.Enforce(token, casbin.ResourceEnvironment, action, envObject); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
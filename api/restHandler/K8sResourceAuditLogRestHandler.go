@@ -0,0 +1,105 @@
+package restHandler
+
+import (
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs/repository"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type K8sResourceAuditLogRestHandler interface {
+	GetAuditLogs(w http.ResponseWriter, r *http.Request)
+}
+
+type K8sResourceAuditLogRestHandlerImpl struct {
+	logger          *zap.SugaredLogger
+	userAuthService user.UserService
+	auditLogService kubernetesResourceAuditLogs.K8sResourceAuditLogService
+}
+
+func NewK8sResourceAuditLogRestHandlerImpl(logger *zap.SugaredLogger, userAuthService user.UserService,
+	auditLogService kubernetesResourceAuditLogs.K8sResourceAuditLogService) *K8sResourceAuditLogRestHandlerImpl {
+	return &K8sResourceAuditLogRestHandlerImpl{
+		logger:          logger,
+		userAuthService: userAuthService,
+		auditLogService: auditLogService,
+	}
+}
+
+func (handler *K8sResourceAuditLogRestHandlerImpl) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	// RBAC enforcer applying
+	isSuperAdmin, err := handler.userAuthService.IsSuperAdmin(int(userId))
+	if err != nil {
+		handler.logger.Errorw("error in checking super admin", "err", err, "userId", userId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	if !isSuperAdmin {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+	filter, err := decodeAuditLogFilter(r.URL.Query())
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.auditLogService.GetAuditLogs(filter)
+	if err != nil {
+		handler.logger.Errorw("service err, GetAuditLogs", "err", err, "filter", filter)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+// decodeAuditLogFilter reads filters from query params, from and to are RFC3339 timestamps
+func decodeAuditLogFilter(query url.Values) (*repository.K8sResourceAuditLogFilter, error) {
+	filter := &repository.K8sResourceAuditLogFilter{
+		Kind:         query.Get("kind"),
+		Namespace:    query.Get("namespace"),
+		ResourceName: query.Get("name"),
+		Action:       query.Get("action"),
+	}
+	var err error
+	intParams := map[string]*int{"clusterId": &filter.ClusterId, "offset": &filter.Offset, "size": &filter.Size}
+	for param, value := range intParams {
+		if len(query.Get(param)) == 0 {
+			continue
+		}
+		if *value, err = strconv.Atoi(query.Get(param)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", param, query.Get(param))
+		}
+	}
+	if userIdParam := query.Get("userId"); len(userIdParam) > 0 {
+		userId, err := strconv.ParseInt(userIdParam, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid userId: %s", userIdParam)
+		}
+		filter.UserId = int32(userId)
+	}
+	timeParams := map[string]*time.Time{"from": &filter.From, "to": &filter.To}
+	for param, value := range timeParams {
+		if len(query.Get(param)) == 0 {
+			continue
+		}
+		if *value, err = time.Parse(time.RFC3339, query.Get(param)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", param, query.Get(param))
+		}
+	}
+	if filter.Offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d", filter.Offset)
+	}
+	return filter, nil
+}
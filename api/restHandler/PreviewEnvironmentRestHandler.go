@@ -0,0 +1,249 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/previewEnv"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type PreviewEnvironmentRestHandler interface {
+	SavePreviewConfig(w http.ResponseWriter, r *http.Request)
+	GetPreviewConfig(w http.ResponseWriter, r *http.Request)
+	DeletePreviewConfig(w http.ResponseWriter, r *http.Request)
+	GetActivePreviews(w http.ResponseWriter, r *http.Request)
+	GetPreview(w http.ResponseWriter, r *http.Request)
+	DeletePreview(w http.ResponseWriter, r *http.Request)
+}
+
+type PreviewEnvironmentRestHandlerImpl struct {
+	logger                    *zap.SugaredLogger
+	userAuthService           user.UserService
+	validator                 *validator.Validate
+	enforcer                  casbin.Enforcer
+	enforcerUtil              rbac.EnforcerUtil
+	previewEnvironmentService previewEnv.PreviewEnvironmentService
+}
+
+func NewPreviewEnvironmentRestHandlerImpl(
+	logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	previewEnvironmentService previewEnv.PreviewEnvironmentService) *PreviewEnvironmentRestHandlerImpl {
+	return &PreviewEnvironmentRestHandlerImpl{
+		logger:                    logger,
+		userAuthService:           userAuthService,
+		validator:                 validator,
+		enforcer:                  enforcer,
+		enforcerUtil:              enforcerUtil,
+		previewEnvironmentService: previewEnvironmentService,
+	}
+}
+
+func (handler *PreviewEnvironmentRestHandlerImpl) SavePreviewConfig(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var bean previewEnv.PreviewEnvConfigDto
+	err = decoder.Decode(&bean)
+	if err != nil {
+		handler.logger.Errorw("request err, SavePreviewConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	bean.UserId = userId
+	handler.logger.Infow("request payload, SavePreviewConfig", "payload", bean)
+	err = handler.validator.Struct(bean)
+	if err != nil {
+		handler.logger.Errorw("validation err, SavePreviewConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if !handler.checkPreviewAdminAuth(token, bean.AppId) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	res, err := handler.previewEnvironmentService.SaveConfig(&bean)
+	if err != nil {
+		handler.logger.Errorw("service err, SavePreviewConfig", "err", err, "payload", bean)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *PreviewEnvironmentRestHandlerImpl) GetPreviewConfig(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	appId, err := strconv.Atoi(mux.Vars(r)["appId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	res, err := handler.previewEnvironmentService.GetConfig(appId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetPreviewConfig", "err", err, "appId", appId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *PreviewEnvironmentRestHandlerImpl) DeletePreviewConfig(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	appId, err := strconv.Atoi(mux.Vars(r)["appId"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if !handler.checkPreviewAdminAuth(token, appId) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	err = handler.previewEnvironmentService.DeleteConfig(appId, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeletePreviewConfig", "err", err, "appId", appId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, appId, http.StatusOK)
+}
+
+func (handler *PreviewEnvironmentRestHandlerImpl) GetActivePreviews(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	appId := 0
+	if appIdParam := r.URL.Query().Get("appId"); len(appIdParam) > 0 {
+		appId, err = strconv.Atoi(appIdParam)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+	}
+	previews, err := handler.previewEnvironmentService.GetActivePreviews(appId)
+	if err != nil {
+		handler.logger.Errorw("service err, GetActivePreviews", "err", err, "appId", appId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	// RBAC enforcer applying, only previews of apps the user can view are listed
+	token := r.Header.Get("token")
+	authorizedApps := make(map[int]bool)
+	res := make([]*previewEnv.PreviewEnvironmentDto, 0, len(previews))
+	for _, preview := range previews {
+		authorized, ok := authorizedApps[preview.AppId]
+		if !ok {
+			object := handler.enforcerUtil.GetAppRBACNameByAppId(preview.AppId)
+			authorized = handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object)
+			authorizedApps[preview.AppId] = authorized
+		}
+		if authorized {
+			res = append(res, preview)
+		}
+	}
+	//RBAC enforcer Ends
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *PreviewEnvironmentRestHandlerImpl) GetPreview(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.previewEnvironmentService.GetPreview(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetPreview", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(res.AppId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *PreviewEnvironmentRestHandlerImpl) DeletePreview(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	preview, err := handler.previewEnvironmentService.GetPreview(id)
+	if err != nil {
+		handler.logger.Errorw("service err, DeletePreview", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	token := r.Header.Get("token")
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(preview.AppId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionDelete, object); !ok {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	err = handler.previewEnvironmentService.DeletePreview(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeletePreview", "err", err, "id", id)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
+
+// checkPreviewAdminAuth previews create environments on the configured cluster, so managing the config needs
+// environment create access along with app admin access
+func (handler *PreviewEnvironmentRestHandlerImpl) checkPreviewAdminAuth(token string, appId int) bool {
+	object := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionCreate, object); !ok {
+		return false
+	}
+	return handler.enforcer.Enforce(token, casbin.ResourceGlobalEnvironment, casbin.ActionCreate, "*")
+}
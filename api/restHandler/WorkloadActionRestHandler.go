@@ -0,0 +1,198 @@
+package restHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	client "github.com/devtron-labs/devtron/api/helm-app"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/pkg/workloadAction"
+	"github.com/devtron-labs/devtron/util"
+	"github.com/devtron-labs/devtron/util/rbac"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"strconv"
+)
+
+type WorkloadActionRestHandler interface {
+	RestartWorkloads(w http.ResponseWriter, r *http.Request)
+	ScaleWorkloads(w http.ResponseWriter, r *http.Request)
+	GetTimeline(w http.ResponseWriter, r *http.Request)
+}
+
+type WorkloadActionRestHandlerImpl struct {
+	logger                *zap.SugaredLogger
+	userAuthService       user.UserService
+	validator             *validator.Validate
+	enforcer              casbin.Enforcer
+	enforcerUtil          rbac.EnforcerUtil
+	enforcerUtilHelm      rbac.EnforcerUtilHelm
+	helmAppService        client.HelmAppService
+	workloadActionService workloadAction.WorkloadActionService
+}
+
+func NewWorkloadActionRestHandlerImpl(
+	logger *zap.SugaredLogger,
+	userAuthService user.UserService,
+	validator *validator.Validate,
+	enforcer casbin.Enforcer,
+	enforcerUtil rbac.EnforcerUtil,
+	enforcerUtilHelm rbac.EnforcerUtilHelm,
+	helmAppService client.HelmAppService,
+	workloadActionService workloadAction.WorkloadActionService) *WorkloadActionRestHandlerImpl {
+	return &WorkloadActionRestHandlerImpl{
+		logger:                logger,
+		userAuthService:       userAuthService,
+		validator:             validator,
+		enforcer:              enforcer,
+		enforcerUtil:          enforcerUtil,
+		enforcerUtilHelm:      enforcerUtilHelm,
+		helmAppService:        helmAppService,
+		workloadActionService: workloadActionService,
+	}
+}
+
+func (handler *WorkloadActionRestHandlerImpl) RestartWorkloads(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request workloadAction.WorkloadActionRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, RestartWorkloads", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	handler.logger.Infow("request payload, RestartWorkloads", "payload", request)
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, RestartWorkloads", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	// RBAC enforcer applying
+	if !handler.checkWorkloadActionAuth(r.Header.Get("token"), request.AppId, request.EnvId, request.HelmAppId, casbin.ActionUpdate) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	ctx := kubernetesResourceAuditLogs.WithAuditContext(r.Context(), userId, util.GetClientIP(r))
+	res, err := handler.workloadActionService.RestartWorkloads(ctx, &request)
+	if err != nil {
+		handler.logger.Errorw("service err, RestartWorkloads", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *WorkloadActionRestHandlerImpl) ScaleWorkloads(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	var request workloadAction.WorkloadScaleRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, ScaleWorkloads", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	request.UserId = userId
+	handler.logger.Infow("request payload, ScaleWorkloads", "payload", request)
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, ScaleWorkloads", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if !handler.checkWorkloadActionAuth(token, request.AppId, request.EnvId, request.HelmAppId, casbin.ActionUpdate) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	// persisting scale edits env override and redeploys, so trigger is needed too
+	if request.PersistInDeploymentTemplate && len(request.HelmAppId) == 0 && !handler.checkWorkloadActionAuth(token, request.AppId, request.EnvId, "", casbin.ActionTrigger) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	ctx := kubernetesResourceAuditLogs.WithAuditContext(r.Context(), userId, util.GetClientIP(r))
+	res, err := handler.workloadActionService.ScaleWorkloads(ctx, &request)
+	if err != nil {
+		handler.logger.Errorw("service err, ScaleWorkloads", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler *WorkloadActionRestHandlerImpl) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userAuthService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	query := r.URL.Query()
+	helmAppId := query.Get("helmAppId")
+	appId, envId, offset, size := 0, 0, 0, 20
+	for param, value := range map[string]*int{"appId": &appId, "envId": &envId, "offset": &offset, "size": &size} {
+		if paramValue := query.Get(param); len(paramValue) > 0 {
+			*value, err = strconv.Atoi(paramValue)
+			if err != nil {
+				common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if len(helmAppId) == 0 && (appId == 0 || envId == 0) {
+		common.WriteJsonResp(w, fmt.Errorf("either helmAppId or appId and envId are required"), nil, http.StatusBadRequest)
+		return
+	}
+
+	// RBAC enforcer applying
+	if !handler.checkWorkloadActionAuth(r.Header.Get("token"), appId, envId, helmAppId, casbin.ActionGet) {
+		common.WriteJsonResp(w, fmt.Errorf("unauthorized user"), "Unauthorized User", http.StatusForbidden)
+		return
+	}
+	//RBAC enforcer Ends
+
+	res, err := handler.workloadActionService.GetTimeline(appId, envId, helmAppId, offset, size)
+	if err != nil {
+		handler.logger.Errorw("service err, GetTimeline", "err", err, "appId", appId, "envId", envId, "helmAppId", helmAppId)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+// checkWorkloadActionAuth helm apps are checked on helm app object, devtron apps need action on both app and env
+func (handler *WorkloadActionRestHandlerImpl) checkWorkloadActionAuth(token string, appId int, envId int, helmAppId string, action string) bool {
+	if len(helmAppId) > 0 {
+		appIdentifier, err := handler.helmAppService.DecodeAppId(helmAppId)
+		if err != nil {
+			handler.logger.Errorw("error in decoding helm app id", "err", err, "helmAppId", helmAppId)
+			return false
+		}
+		rbacObject, rbacObject2 := handler.enforcerUtilHelm.GetHelmObjectByClusterIdNamespaceAndAppName(appIdentifier.ClusterId, appIdentifier.Namespace, appIdentifier.ReleaseName)
+		return handler.enforcer.Enforce(token, casbin.ResourceHelmApp, action, rbacObject) || handler.enforcer.Enforce(token, casbin.ResourceHelmApp, action, rbacObject2)
+	}
+	appObject := handler.enforcerUtil.GetAppRBACNameByAppId(appId)
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceApplications, action, appObject); !ok {
+		return false
+	}
+	envObject := handler.enforcerUtil.GetEnvRBACNameByAppId(appId, envId)
+	return handler.enforcer.Enforce(token, casbin.ResourceEnvironment, action, envObject)
+}
@@ -0,0 +1,35 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type AppGroupRouter interface {
+	initAppGroupRouter(appGroupRouter *mux.Router)
+}
+
+type AppGroupRouterImpl struct {
+	restHandler restHandler.AppGroupRestHandler
+}
+
+func NewAppGroupRouterImpl(restHandler restHandler.AppGroupRestHandler) *AppGroupRouterImpl {
+	return &AppGroupRouterImpl{restHandler: restHandler}
+}
+
+func (router AppGroupRouterImpl) initAppGroupRouter(appGroupRouter *mux.Router) {
+	appGroupRouter.Path("").
+		HandlerFunc(router.restHandler.CreateAppGroup).Methods("POST")
+	appGroupRouter.Path("").
+		HandlerFunc(router.restHandler.UpdateAppGroup).Methods("PUT")
+	appGroupRouter.Path("").
+		HandlerFunc(router.restHandler.GetAllAppGroups).Methods("GET")
+	appGroupRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.GetAppGroup).Methods("GET")
+	appGroupRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.DeleteAppGroup).Methods("DELETE")
+	appGroupRouter.Path("/{id}/status").
+		HandlerFunc(router.restHandler.GetAppGroupStatus).Methods("GET")
+	appGroupRouter.Path("/{id}/action").
+		HandlerFunc(router.restHandler.PerformAppGroupAction).Methods("POST")
+}
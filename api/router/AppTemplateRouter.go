@@ -0,0 +1,39 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type AppTemplateRouter interface {
+	initAppTemplateRouter(appTemplateRouter *mux.Router)
+}
+
+type AppTemplateRouterImpl struct {
+	restHandler restHandler.AppTemplateRestHandler
+}
+
+func NewAppTemplateRouterImpl(restHandler restHandler.AppTemplateRestHandler) *AppTemplateRouterImpl {
+	return &AppTemplateRouterImpl{restHandler: restHandler}
+}
+
+func (router AppTemplateRouterImpl) initAppTemplateRouter(appTemplateRouter *mux.Router) {
+	appTemplateRouter.Path("").
+		HandlerFunc(router.restHandler.SaveAppTemplate).Methods("POST")
+	appTemplateRouter.Path("").
+		HandlerFunc(router.restHandler.SaveAppTemplate).Methods("PUT")
+	appTemplateRouter.Path("").
+		HandlerFunc(router.restHandler.GetAllAppTemplates).Methods("GET")
+	appTemplateRouter.Path("/app/{appId}").
+		HandlerFunc(router.restHandler.GetTemplateForApp).Methods("GET")
+	appTemplateRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.GetAppTemplate).Methods("GET")
+	appTemplateRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.DeleteAppTemplate).Methods("DELETE")
+	appTemplateRouter.Path("/{id}/versions").
+		HandlerFunc(router.restHandler.GetAppTemplateVersions).Methods("GET")
+	appTemplateRouter.Path("/{id}/apps").
+		HandlerFunc(router.restHandler.GetTemplateApps).Methods("GET")
+	appTemplateRouter.Path("/{id}/app").
+		HandlerFunc(router.restHandler.CreateAppFromTemplate).Methods("POST")
+}
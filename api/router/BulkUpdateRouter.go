@@ -23,6 +23,11 @@ func (router BulkUpdateRouterImpl) initBulkUpdateRouter(bulkRouter *mux.Router)
 	bulkRouter.Path("/{apiVersion}/{kind}/readme").HandlerFunc(router.restHandler.FindBulkUpdateReadme).Methods("GET")
 	bulkRouter.Path("/v1beta1/application/dryrun").HandlerFunc(router.restHandler.GetImpactedAppsName).Methods("POST")
 	bulkRouter.Path("/v1beta1/application").HandlerFunc(router.restHandler.BulkUpdate).Methods("POST")
+	bulkRouter.Path("/v1beta1/application/job").HandlerFunc(router.restHandler.GetBulkUpdateJobs).Methods("GET")
+	bulkRouter.Path("/v1beta1/application/job/{id}").HandlerFunc(router.restHandler.GetBulkUpdateJob).Methods("GET")
+	bulkRouter.Path("/v1beta1/application/job/{id}/revert").HandlerFunc(router.restHandler.RevertBulkUpdateJob).Methods("POST")
+	bulkRouter.Path("/v1beta1/ci-pipeline").HandlerFunc(router.restHandler.CiBulkUpdate).Methods("POST")
+	bulkRouter.Path("/v1beta1/env-clone").HandlerFunc(router.restHandler.CloneEnvironment).Methods("POST")
 
 	bulkRouter.Path("/v1beta1/hibernate").HandlerFunc(router.restHandler.BulkHibernate).Methods("POST")
 	bulkRouter.Path("/v1beta1/unhibernate").HandlerFunc(router.restHandler.BulkUnHibernate).Methods("POST")
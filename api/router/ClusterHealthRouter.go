@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type ClusterHealthRouter interface {
+	initClusterHealthRouter(clusterHealthRouter *mux.Router)
+}
+
+type ClusterHealthRouterImpl struct {
+	restHandler restHandler.ClusterHealthRestHandler
+}
+
+func NewClusterHealthRouterImpl(restHandler restHandler.ClusterHealthRestHandler) *ClusterHealthRouterImpl {
+	return &ClusterHealthRouterImpl{restHandler: restHandler}
+}
+
+func (router ClusterHealthRouterImpl) initClusterHealthRouter(clusterHealthRouter *mux.Router) {
+	clusterHealthRouter.Path("/{clusterId}").
+		HandlerFunc(router.restHandler.GetClusterHealth).Methods("GET")
+	clusterHealthRouter.Path("/{clusterId}/history").
+		HandlerFunc(router.restHandler.GetClusterHealthHistory).Methods("GET")
+	clusterHealthRouter.Path("/{clusterId}/check").
+		HandlerFunc(router.restHandler.CheckClusterHealth).Methods("POST")
+}
@@ -77,4 +77,28 @@ func (router ConfigMapRouterImpl) initConfigMapRouter(configRouter *mux.Router)
 
 	configRouter.Path("/bulk/patch").HandlerFunc(router.restHandler.ConfigSecretBulkPatch).Methods("POST")
 
+	configRouter.Path("/external-secret/default-store").
+		HandlerFunc(router.restHandler.SaveDefaultSecretStore).Methods("POST")
+	configRouter.Path("/external-secret/default-store/{envId}").
+		HandlerFunc(router.restHandler.GetDefaultSecretStores).Methods("GET")
+	configRouter.Path("/external-secret/default-store/{envId}/{id}").
+		HandlerFunc(router.restHandler.DeleteDefaultSecretStore).Methods("DELETE")
+	configRouter.Path("/external-secret/test-connection").
+		HandlerFunc(router.restHandler.TestExternalSecretConnection).Methods("POST")
+
+	configRouter.Path("/global/history/{appId}").
+		Queries("type", "{type}", "name", "{name}").
+		HandlerFunc(router.restHandler.GetConfigHistoryVersions).Methods("GET")
+	configRouter.Path("/environment/history/{appId}/{envId}").
+		Queries("type", "{type}", "name", "{name}").
+		HandlerFunc(router.restHandler.GetConfigHistoryVersions).Methods("GET")
+	configRouter.Path("/global/history/{appId}/diff").
+		Queries("type", "{type}", "name", "{name}", "from", "{from}", "to", "{to}").
+		HandlerFunc(router.restHandler.GetConfigHistoryDiff).Methods("GET")
+	configRouter.Path("/environment/history/{appId}/{envId}/diff").
+		Queries("type", "{type}", "name", "{name}", "from", "{from}", "to", "{to}").
+		HandlerFunc(router.restHandler.GetConfigHistoryDiff).Methods("GET")
+	configRouter.Path("/history/restore").
+		HandlerFunc(router.restHandler.RestoreConfigHistoryVersion).Methods("POST")
+
 }
@@ -20,4 +20,16 @@ func NewGlobalCMCSRouterImpl(restHandler restHandler.GlobalCMCSRestHandler) *Glo
 func (router GlobalCMCSRouterImpl) initGlobalCMCSRouter(configRouter *mux.Router) {
 	configRouter.Path("").
 		HandlerFunc(router.restHandler.CreateGlobalCMCSConfig).Methods("POST")
+	configRouter.Path("").
+		HandlerFunc(router.restHandler.UpdateGlobalCMCSConfig).Methods("PUT")
+	configRouter.Path("").
+		HandlerFunc(router.restHandler.GetAllGlobalCMCSConfig).Methods("GET")
+	configRouter.Path("/preview").
+		HandlerFunc(router.restHandler.PreviewGlobalCMCSConfig).Methods("POST")
+	configRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.GetGlobalCMCSConfig).Methods("GET")
+	configRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.DeleteGlobalCMCSConfig).Methods("DELETE")
+	configRouter.Path("/{id}/history").
+		HandlerFunc(router.restHandler.GetGlobalCMCSConfigHistory).Methods("GET")
 }
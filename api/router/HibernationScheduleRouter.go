@@ -0,0 +1,37 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type HibernationScheduleRouter interface {
+	initHibernationScheduleRouter(hibernationScheduleRouter *mux.Router)
+}
+
+type HibernationScheduleRouterImpl struct {
+	restHandler restHandler.HibernationScheduleRestHandler
+}
+
+func NewHibernationScheduleRouterImpl(restHandler restHandler.HibernationScheduleRestHandler) *HibernationScheduleRouterImpl {
+	return &HibernationScheduleRouterImpl{restHandler: restHandler}
+}
+
+func (router HibernationScheduleRouterImpl) initHibernationScheduleRouter(hibernationScheduleRouter *mux.Router) {
+	hibernationScheduleRouter.Path("").
+		HandlerFunc(router.restHandler.SaveSchedule).Methods("POST")
+	hibernationScheduleRouter.Path("").
+		HandlerFunc(router.restHandler.SaveSchedule).Methods("PUT")
+	hibernationScheduleRouter.Path("").
+		HandlerFunc(router.restHandler.GetAllSchedules).Methods("GET")
+	hibernationScheduleRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.GetSchedule).Methods("GET")
+	hibernationScheduleRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.DeleteSchedule).Methods("DELETE")
+	hibernationScheduleRouter.Path("/{id}/skip").
+		HandlerFunc(router.restHandler.SkipNextHibernation).Methods("POST")
+	hibernationScheduleRouter.Path("/{id}/extend").
+		HandlerFunc(router.restHandler.ExtendSchedule).Methods("POST")
+	hibernationScheduleRouter.Path("/{id}/history").
+		HandlerFunc(router.restHandler.GetScheduleHistory).Methods("GET")
+}
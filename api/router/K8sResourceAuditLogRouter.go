@@ -0,0 +1,23 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type K8sResourceAuditLogRouter interface {
+	initK8sResourceAuditLogRouter(auditLogRouter *mux.Router)
+}
+
+type K8sResourceAuditLogRouterImpl struct {
+	restHandler restHandler.K8sResourceAuditLogRestHandler
+}
+
+func NewK8sResourceAuditLogRouterImpl(restHandler restHandler.K8sResourceAuditLogRestHandler) *K8sResourceAuditLogRouterImpl {
+	return &K8sResourceAuditLogRouterImpl{restHandler: restHandler}
+}
+
+func (router K8sResourceAuditLogRouterImpl) initK8sResourceAuditLogRouter(auditLogRouter *mux.Router) {
+	auditLogRouter.Path("").
+		HandlerFunc(router.restHandler.GetAuditLogs).Methods("GET")
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type PreviewEnvironmentRouter interface {
+	initPreviewEnvironmentRouter(previewEnvironmentRouter *mux.Router)
+}
+
+type PreviewEnvironmentRouterImpl struct {
+	restHandler restHandler.PreviewEnvironmentRestHandler
+}
+
+func NewPreviewEnvironmentRouterImpl(restHandler restHandler.PreviewEnvironmentRestHandler) *PreviewEnvironmentRouterImpl {
+	return &PreviewEnvironmentRouterImpl{restHandler: restHandler}
+}
+
+func (router PreviewEnvironmentRouterImpl) initPreviewEnvironmentRouter(previewEnvironmentRouter *mux.Router) {
+	previewEnvironmentRouter.Path("/config").
+		HandlerFunc(router.restHandler.SavePreviewConfig).Methods("POST")
+	previewEnvironmentRouter.Path("/config/{appId}").
+		HandlerFunc(router.restHandler.GetPreviewConfig).Methods("GET")
+	previewEnvironmentRouter.Path("/config/{appId}").
+		HandlerFunc(router.restHandler.DeletePreviewConfig).Methods("DELETE")
+	previewEnvironmentRouter.Path("").
+		HandlerFunc(router.restHandler.GetActivePreviews).Methods("GET")
+	previewEnvironmentRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.GetPreview).Methods("GET")
+	previewEnvironmentRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.DeletePreview).Methods("DELETE")
+}
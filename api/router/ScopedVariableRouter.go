@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type ScopedVariableRouter interface {
+	initScopedVariableRouter(variableRouter *mux.Router)
+}
+
+type ScopedVariableRouterImpl struct {
+	restHandler restHandler.ScopedVariableRestHandler
+}
+
+func NewScopedVariableRouterImpl(restHandler restHandler.ScopedVariableRestHandler) *ScopedVariableRouterImpl {
+	return &ScopedVariableRouterImpl{restHandler: restHandler}
+}
+
+func (router ScopedVariableRouterImpl) initScopedVariableRouter(variableRouter *mux.Router) {
+	variableRouter.Path("").
+		HandlerFunc(router.restHandler.CreateOrUpdateVariable).Methods("POST")
+	variableRouter.Path("").
+		HandlerFunc(router.restHandler.GetAllVariables).Methods("GET")
+	variableRouter.Path("/snapshot/{appId}/{wfrId}").
+		HandlerFunc(router.restHandler.GetVariableSnapshots).Methods("GET")
+	variableRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.GetVariable).Methods("GET")
+	variableRouter.Path("/{id}").
+		HandlerFunc(router.restHandler.DeleteVariable).Methods("DELETE")
+	variableRouter.Path("/{id}/usage").
+		HandlerFunc(router.restHandler.GetVariableUsage).Methods("GET")
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/devtron-labs/devtron/api/restHandler"
+	"github.com/gorilla/mux"
+)
+
+type WorkloadActionRouter interface {
+	initWorkloadActionRouter(workloadActionRouter *mux.Router)
+}
+
+type WorkloadActionRouterImpl struct {
+	restHandler restHandler.WorkloadActionRestHandler
+}
+
+func NewWorkloadActionRouterImpl(restHandler restHandler.WorkloadActionRestHandler) *WorkloadActionRouterImpl {
+	return &WorkloadActionRouterImpl{restHandler: restHandler}
+}
+
+func (router WorkloadActionRouterImpl) initWorkloadActionRouter(workloadActionRouter *mux.Router) {
+	workloadActionRouter.Path("/restart").
+		HandlerFunc(router.restHandler.RestartWorkloads).Methods("POST")
+	workloadActionRouter.Path("/scale").
+		HandlerFunc(router.restHandler.ScaleWorkloads).Methods("POST")
+	workloadActionRouter.Path("/timeline").
+		HandlerFunc(router.restHandler.GetTimeline).Methods("GET")
+}
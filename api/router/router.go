@@ -116,6 +116,15 @@ type MuxRouter struct {
 	globalCMCSRouter                   GlobalCMCSRouter
 	userTerminalAccessRouter           terminal2.UserTerminalAccessRouter
 	ciStatusUpdateCron                 cron.CiStatusUpdateCron
+	scopedVariableRouter               ScopedVariableRouter
+	appGroupRouter                     AppGroupRouter
+	appTemplateRouter                  AppTemplateRouter
+	previewEnvironmentRouter           PreviewEnvironmentRouter
+	hibernationScheduleRouter          HibernationScheduleRouter
+	clusterHealthRouter                ClusterHealthRouter
+	k8sResourceAuditLogRouter          K8sResourceAuditLogRouter
+	terminalSessionRecordingRouter     terminal2.TerminalSessionRecordingRouter
+	workloadActionRouter               WorkloadActionRouter
 }
 
 func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, PipelineConfigRouter PipelineConfigRouter,
@@ -143,7 +152,12 @@ func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, P
 	serverRouter server.ServerRouter, apiTokenRouter apiToken.ApiTokenRouter,
 	helmApplicationStatusUpdateHandler cron.CdApplicationStatusUpdateHandler, k8sCapacityRouter k8s.K8sCapacityRouter,
 	webhookHelmRouter webhookHelm.WebhookHelmRouter, globalCMCSRouter GlobalCMCSRouter,
-	userTerminalAccessRouter terminal2.UserTerminalAccessRouter, ciStatusUpdateCron cron.CiStatusUpdateCron) *MuxRouter {
+	userTerminalAccessRouter terminal2.UserTerminalAccessRouter, ciStatusUpdateCron cron.CiStatusUpdateCron,
+	scopedVariableRouter ScopedVariableRouter, appGroupRouter AppGroupRouter,
+	appTemplateRouter AppTemplateRouter, previewEnvironmentRouter PreviewEnvironmentRouter,
+	hibernationScheduleRouter HibernationScheduleRouter, clusterHealthRouter ClusterHealthRouter,
+	k8sResourceAuditLogRouter K8sResourceAuditLogRouter, terminalSessionRecordingRouter terminal2.TerminalSessionRecordingRouter,
+	workloadActionRouter WorkloadActionRouter) *MuxRouter {
 	r := &MuxRouter{
 		Router:                             mux.NewRouter(),
 		HelmRouter:                         HelmRouter,
@@ -210,6 +224,15 @@ func NewMuxRouter(logger *zap.SugaredLogger, HelmRouter PipelineTriggerRouter, P
 		globalCMCSRouter:                   globalCMCSRouter,
 		userTerminalAccessRouter:           userTerminalAccessRouter,
 		ciStatusUpdateCron:                 ciStatusUpdateCron,
+		scopedVariableRouter:               scopedVariableRouter,
+		appGroupRouter:                     appGroupRouter,
+		appTemplateRouter:                  appTemplateRouter,
+		previewEnvironmentRouter:           previewEnvironmentRouter,
+		hibernationScheduleRouter:          hibernationScheduleRouter,
+		clusterHealthRouter:                clusterHealthRouter,
+		k8sResourceAuditLogRouter:          k8sResourceAuditLogRouter,
+		terminalSessionRecordingRouter:     terminalSessionRecordingRouter,
+		workloadActionRouter:               workloadActionRouter,
 	}
 	return r
 }
@@ -411,6 +434,33 @@ func (r MuxRouter) Init() {
 	globalCMCSRouter := r.Router.PathPrefix("/orchestrator/global/cm-cs").Subrouter()
 	r.globalCMCSRouter.initGlobalCMCSRouter(globalCMCSRouter)
 
+	scopedVariableRouter := r.Router.PathPrefix("/orchestrator/global/variables").Subrouter()
+	r.scopedVariableRouter.initScopedVariableRouter(scopedVariableRouter)
+
+	appGroupRouter := r.Router.PathPrefix("/orchestrator/app-group").Subrouter()
+	r.appGroupRouter.initAppGroupRouter(appGroupRouter)
+
+	appTemplateRouter := r.Router.PathPrefix("/orchestrator/app-template").Subrouter()
+	r.appTemplateRouter.initAppTemplateRouter(appTemplateRouter)
+
+	previewEnvironmentRouter := r.Router.PathPrefix("/orchestrator/preview-env").Subrouter()
+	r.previewEnvironmentRouter.initPreviewEnvironmentRouter(previewEnvironmentRouter)
+
+	hibernationScheduleRouter := r.Router.PathPrefix("/orchestrator/hibernation-schedule").Subrouter()
+	r.hibernationScheduleRouter.initHibernationScheduleRouter(hibernationScheduleRouter)
+
+	clusterHealthRouter := r.Router.PathPrefix("/orchestrator/cluster-health").Subrouter()
+	r.clusterHealthRouter.initClusterHealthRouter(clusterHealthRouter)
+
+	k8sResourceAuditLogRouter := r.Router.PathPrefix("/orchestrator/k8s-resource-audit-log").Subrouter()
+	r.k8sResourceAuditLogRouter.initK8sResourceAuditLogRouter(k8sResourceAuditLogRouter)
+
 	userTerminalAccessRouter := r.Router.PathPrefix("/orchestrator/user/terminal").Subrouter()
 	r.userTerminalAccessRouter.InitTerminalAccessRouter(userTerminalAccessRouter)
+
+	terminalSessionRecordingRouter := r.Router.PathPrefix("/orchestrator/terminal-session-recording").Subrouter()
+	r.terminalSessionRecordingRouter.InitTerminalSessionRecordingRouter(terminalSessionRecordingRouter)
+
+	workloadActionRouter := r.Router.PathPrefix("/orchestrator/workload-action").Subrouter()
+	r.workloadActionRouter.initWorkloadActionRouter(workloadActionRouter)
 }
@@ -0,0 +1,237 @@
+package terminal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/restHandler/common"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/terminal"
+	"github.com/devtron-labs/devtron/pkg/terminal/repository"
+	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type TerminalSessionRecordingRestHandler interface {
+	GetRecordings(w http.ResponseWriter, r *http.Request)
+	GetPlayback(w http.ResponseWriter, r *http.Request)
+	DownloadRecording(w http.ResponseWriter, r *http.Request)
+	GetRecordingSettings(w http.ResponseWriter, r *http.Request)
+	SaveRecordingSetting(w http.ResponseWriter, r *http.Request)
+	DeleteRecordingSetting(w http.ResponseWriter, r *http.Request)
+}
+
+type TerminalSessionRecordingRestHandlerImpl struct {
+	logger                          *zap.SugaredLogger
+	terminalSessionRecordingService terminal.TerminalSessionRecordingService
+	enforcer                        casbin.Enforcer
+	userService                     user.UserService
+	validator                       *validator.Validate
+}
+
+func NewTerminalSessionRecordingRestHandlerImpl(logger *zap.SugaredLogger, terminalSessionRecordingService terminal.TerminalSessionRecordingService,
+	enforcer casbin.Enforcer, userService user.UserService, validator *validator.Validate) *TerminalSessionRecordingRestHandlerImpl {
+	return &TerminalSessionRecordingRestHandlerImpl{
+		logger:                          logger,
+		terminalSessionRecordingService: terminalSessionRecordingService,
+		enforcer:                        enforcer,
+		userService:                     userService,
+		validator:                       validator,
+	}
+}
+
+// authorize allows only super admin as recordings contain everything typed in and printed by terminals of all users,
+// error response is written when it returns false
+func (handler TerminalSessionRecordingRestHandlerImpl) authorize(w http.ResponseWriter, r *http.Request, casbinAction string) (int32, bool) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return 0, false
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbinAction, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return 0, false
+	}
+	//RBAC enforcer Ends
+	return userId, true
+}
+
+func (handler TerminalSessionRecordingRestHandlerImpl) GetRecordings(w http.ResponseWriter, r *http.Request) {
+	if _, ok := handler.authorize(w, r, casbin.ActionGet); !ok {
+		return
+	}
+	filter, err := decodeRecordingFilter(r.URL.Query())
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.terminalSessionRecordingService.GetRecordings(filter)
+	if err != nil {
+		handler.logger.Errorw("service err, GetRecordings", "err", err, "filter", filter)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+// decodeRecordingFilter reads filters from query params, from and to are RFC3339 timestamps
+func decodeRecordingFilter(query url.Values) (*repository.TerminalSessionRecordingFilter, error) {
+	filter := &repository.TerminalSessionRecordingFilter{}
+	var err error
+	intParams := map[string]*int{"clusterId": &filter.ClusterId, "offset": &filter.Offset, "size": &filter.Size}
+	for param, value := range intParams {
+		if len(query.Get(param)) == 0 {
+			continue
+		}
+		if *value, err = strconv.Atoi(query.Get(param)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", param, query.Get(param))
+		}
+	}
+	if userIdParam := query.Get("userId"); len(userIdParam) > 0 {
+		userId, err := strconv.ParseInt(userIdParam, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid userId: %s", userIdParam)
+		}
+		filter.UserId = int32(userId)
+	}
+	timeParams := map[string]*time.Time{"from": &filter.From, "to": &filter.To}
+	for param, value := range timeParams {
+		if len(query.Get(param)) == 0 {
+			continue
+		}
+		if *value, err = time.Parse(time.RFC3339, query.Get(param)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", param, query.Get(param))
+		}
+	}
+	if filter.Offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d", filter.Offset)
+	}
+	return filter, nil
+}
+
+func (handler TerminalSessionRecordingRestHandlerImpl) GetPlayback(w http.ResponseWriter, r *http.Request) {
+	if _, ok := handler.authorize(w, r, casbin.ActionGet); !ok {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.terminalSessionRecordingService.GetPlayback(id)
+	if err != nil {
+		handler.logger.Errorw("service err, GetPlayback", "err", err, "id", id)
+		if util.IsErrNoRows(err) {
+			err = &util.ApiError{Code: "404", HttpStatusCode: http.StatusNotFound, UserMessage: "recording not found"}
+			common.WriteJsonResp(w, err, nil, http.StatusNotFound)
+			return
+		}
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler TerminalSessionRecordingRestHandlerImpl) DownloadRecording(w http.ResponseWriter, r *http.Request) {
+	if _, ok := handler.authorize(w, r, casbin.ActionGet); !ok {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	recording, reader, err := handler.terminalSessionRecordingService.OpenRecording(id)
+	if err != nil {
+		handler.logger.Errorw("service err, DownloadRecording", "err", err, "id", id)
+		if util.IsErrNoRows(err) {
+			err = &util.ApiError{Code: "404", HttpStatusCode: http.StatusNotFound, UserMessage: "recording not found"}
+			common.WriteJsonResp(w, err, nil, http.StatusNotFound)
+			return
+		}
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	defer reader.Close()
+	w.Header().Set("Content-Disposition", "attachment; filename="+recording.SessionId+terminal.RECORDING_FILE_EXTENSION)
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	_, err = io.Copy(w, reader)
+	if err != nil {
+		handler.logger.Errorw("error in writing recording, DownloadRecording", "err", err, "id", id)
+	}
+}
+
+func (handler TerminalSessionRecordingRestHandlerImpl) GetRecordingSettings(w http.ResponseWriter, r *http.Request) {
+	if _, ok := handler.authorize(w, r, casbin.ActionGet); !ok {
+		return
+	}
+	res, err := handler.terminalSessionRecordingService.GetRecordingSettings()
+	if err != nil {
+		handler.logger.Errorw("service err, GetRecordingSettings", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler TerminalSessionRecordingRestHandlerImpl) SaveRecordingSetting(w http.ResponseWriter, r *http.Request) {
+	userId, ok := handler.authorize(w, r, casbin.ActionUpdate)
+	if !ok {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request terminal.TerminalSessionRecordingSettingBean
+	err := decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("request err, SaveRecordingSetting", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = handler.validator.Struct(request)
+	if err != nil {
+		handler.logger.Errorw("validation err, SaveRecordingSetting", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	res, err := handler.terminalSessionRecordingService.SaveRecordingSetting(&request, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, SaveRecordingSetting", "err", err, "payload", request)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, res, http.StatusOK)
+}
+
+func (handler TerminalSessionRecordingRestHandlerImpl) DeleteRecordingSetting(w http.ResponseWriter, r *http.Request) {
+	userId, ok := handler.authorize(w, r, casbin.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	err = handler.terminalSessionRecordingService.DeleteRecordingSetting(id, userId)
+	if err != nil {
+		handler.logger.Errorw("service err, DeleteRecordingSetting", "err", err, "id", id)
+		if util.IsErrNoRows(err) {
+			err = &util.ApiError{Code: "404", HttpStatusCode: http.StatusNotFound, UserMessage: "recording setting not found"}
+			common.WriteJsonResp(w, err, nil, http.StatusNotFound)
+			return
+		}
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, id, http.StatusOK)
+}
@@ -0,0 +1,34 @@
+package terminal
+
+import (
+	"github.com/gorilla/mux"
+)
+
+type TerminalSessionRecordingRouter interface {
+	InitTerminalSessionRecordingRouter(recordingRouter *mux.Router)
+}
+
+type TerminalSessionRecordingRouterImpl struct {
+	terminalSessionRecordingRestHandler TerminalSessionRecordingRestHandler
+}
+
+func NewTerminalSessionRecordingRouterImpl(terminalSessionRecordingRestHandler TerminalSessionRecordingRestHandler) *TerminalSessionRecordingRouterImpl {
+	return &TerminalSessionRecordingRouterImpl{
+		terminalSessionRecordingRestHandler: terminalSessionRecordingRestHandler,
+	}
+}
+
+func (router TerminalSessionRecordingRouterImpl) InitTerminalSessionRecordingRouter(recordingRouter *mux.Router) {
+	recordingRouter.Path("").
+		HandlerFunc(router.terminalSessionRecordingRestHandler.GetRecordings).Methods("GET")
+	recordingRouter.Path("/{id}/playback").
+		HandlerFunc(router.terminalSessionRecordingRestHandler.GetPlayback).Methods("GET")
+	recordingRouter.Path("/{id}/download").
+		HandlerFunc(router.terminalSessionRecordingRestHandler.DownloadRecording).Methods("GET")
+	recordingRouter.Path("/setting").
+		HandlerFunc(router.terminalSessionRecordingRestHandler.GetRecordingSettings).Methods("GET")
+	recordingRouter.Path("/setting").
+		HandlerFunc(router.terminalSessionRecordingRestHandler.SaveRecordingSetting).Methods("POST")
+	recordingRouter.Path("/setting/{id}").
+		HandlerFunc(router.terminalSessionRecordingRestHandler.DeleteRecordingSetting).Methods("DELETE")
+}
@@ -6,8 +6,10 @@ import (
 	"github.com/devtron-labs/devtron/api/restHandler/common"
 	"github.com/devtron-labs/devtron/internal/sql/models"
 	"github.com/devtron-labs/devtron/pkg/clusterTerminalAccess"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	"github.com/devtron-labs/devtron/util"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"gopkg.in/go-playground/validator.v9"
@@ -73,7 +75,7 @@ func (handler UserTerminalAccessRestHandlerImpl) StartTerminalSession(w http.Res
 		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
 		return
 	}
-	sessionResponse, err := handler.UserTerminalAccessService.StartTerminalSession(r.Context(), &request)
+	sessionResponse, err := handler.UserTerminalAccessService.StartTerminalSession(kubernetesResourceAuditLogs.WithAuditContext(r.Context(), userId, util.GetClientIP(r)), &request)
 	if err != nil {
 		handler.Logger.Errorw("service err, StartTerminalSession", "err", err)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -109,7 +111,7 @@ func (handler UserTerminalAccessRestHandlerImpl) UpdateTerminalSession(w http.Re
 		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
 		return
 	}
-	sessionResponse, err := handler.UserTerminalAccessService.UpdateTerminalSession(r.Context(), &request)
+	sessionResponse, err := handler.UserTerminalAccessService.UpdateTerminalSession(kubernetesResourceAuditLogs.WithAuditContext(r.Context(), userId, util.GetClientIP(r)), &request)
 	if err != nil {
 		handler.Logger.Errorw("service err, UpdateTerminalSession", "err", err)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -318,7 +320,7 @@ func (handler UserTerminalAccessRestHandlerImpl) DisconnectAllTerminalSessionAnd
 		return
 	}
 	handler.UserTerminalAccessService.DisconnectAllSessionsForUser(r.Context(), userId)
-	sessionResponse, err := handler.UserTerminalAccessService.StartTerminalSession(r.Context(), &request)
+	sessionResponse, err := handler.UserTerminalAccessService.StartTerminalSession(kubernetesResourceAuditLogs.WithAuditContext(r.Context(), userId, util.GetClientIP(r)), &request)
 	if err != nil {
 		handler.Logger.Errorw("service err, DisconnectAllTerminalSessionAndRetry", "err", err)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -11,6 +11,10 @@ var TerminalWireSet = wire.NewSet(
 	wire.Bind(new(UserTerminalAccessRouter), new(*UserTerminalAccessRouterImpl)),
 	NewUserTerminalAccessRestHandlerImpl,
 	wire.Bind(new(UserTerminalAccessRestHandler), new(*UserTerminalAccessRestHandlerImpl)),
+	NewTerminalSessionRecordingRouterImpl,
+	wire.Bind(new(TerminalSessionRecordingRouter), new(*TerminalSessionRecordingRouterImpl)),
+	NewTerminalSessionRecordingRestHandlerImpl,
+	wire.Bind(new(TerminalSessionRecordingRestHandler), new(*TerminalSessionRecordingRestHandlerImpl)),
 	clusterTerminalAccess.GetTerminalAccessConfig,
 	clusterTerminalAccess.NewUserTerminalAccessServiceImpl,
 	wire.Bind(new(clusterTerminalAccess.UserTerminalAccessService), new(*clusterTerminalAccess.UserTerminalAccessServiceImpl)),
@@ -89,6 +89,9 @@ type Payload struct {
 	DownloadLink          string               `json:"downloadLink"`
 	BuildHistoryLink      string               `json:"buildHistoryLink"`
 	MaterialTriggerInfo   *MaterialTriggerInfo `json:"material"`
+	ClusterName           string               `json:"clusterName,omitempty"`
+	ClusterHealthStatus   string               `json:"clusterHealthStatus,omitempty"`
+	ClusterHealthMessage  string               `json:"clusterHealthMessage,omitempty"`
 }
 
 type CiPipelineMaterialResponse struct {
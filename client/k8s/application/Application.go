@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -31,7 +32,10 @@ type K8sClientService interface {
 	GetPodLogs(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean) (io.ReadCloser, error)
 	GetApiResources(restConfig *rest.Config, includeOnlyVerb string) ([]*K8sApiResource, error)
 	GetResourceList(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean) (*ResourceListResponse, bool, error)
+	WatchResourceList(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, resourceVersion string) (watch.Interface, bool, error)
 	ApplyResource(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, manifest string) (*ManifestResponse, error)
+	DryRunApplyResource(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, manifest string) (*ManifestResponse, error)
+	DryRunUpdateResource(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean) (*ManifestResponse, error)
 }
 
 type K8sClientServiceImpl struct {
@@ -98,6 +102,10 @@ func (impl K8sClientServiceImpl) GetResource(ctx context.Context, restConfig *re
 }
 
 func (impl K8sClientServiceImpl) CreateResource(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, manifest string) (*ManifestResponse, error) {
+	return impl.createResource(ctx, restConfig, request, manifest, nil)
+}
+
+func (impl K8sClientServiceImpl) createResource(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, manifest string, dryRun []string) (*ManifestResponse, error) {
 	resourceIf, namespaced, err := impl.GetResourceIf(restConfig, request)
 	if err != nil {
 		impl.logger.Errorw("error in getting dynamic interface for resource", "err", err)
@@ -112,9 +120,9 @@ func (impl K8sClientServiceImpl) CreateResource(ctx context.Context, restConfig
 	resourceIdentifier := request.ResourceIdentifier
 	var resp *unstructured.Unstructured
 	if len(resourceIdentifier.Namespace) > 0 && namespaced {
-		resp, err = resourceIf.Namespace(resourceIdentifier.Namespace).Create(ctx, &unstructured.Unstructured{Object: createObj}, metav1.CreateOptions{})
+		resp, err = resourceIf.Namespace(resourceIdentifier.Namespace).Create(ctx, &unstructured.Unstructured{Object: createObj}, metav1.CreateOptions{DryRun: dryRun})
 	} else {
-		resp, err = resourceIf.Create(ctx, &unstructured.Unstructured{Object: createObj}, metav1.CreateOptions{})
+		resp, err = resourceIf.Create(ctx, &unstructured.Unstructured{Object: createObj}, metav1.CreateOptions{DryRun: dryRun})
 	}
 	if err != nil {
 		impl.logger.Errorw("error in creating resource", "err", err)
@@ -416,18 +424,97 @@ func (impl K8sClientServiceImpl) GetResourceList(ctx context.Context, restConfig
 	return &ResourceListResponse{*resp}, namespaced, nil
 }
 
+// WatchResourceList opens a watch returning events in table format, same as GetResourceList, starting after resourceVersion
+func (impl K8sClientServiceImpl) WatchResourceList(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, resourceVersion string) (watch.Interface, bool, error) {
+	resourceIf, namespaced, err := impl.GetResourceIfWithAcceptHeader(restConfig, request)
+	if err != nil {
+		impl.logger.Errorw("error in getting dynamic interface for resource", "err", err)
+		return nil, namespaced, err
+	}
+	resourceIdentifier := request.ResourceIdentifier
+	// server closes watch after timeout, caller is expected to resume from last seen resource version
+	timeoutSeconds := int64(5 * 60)
+	listOptions := metav1.ListOptions{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       resourceIdentifier.GroupVersionKind.Kind,
+			APIVersion: resourceIdentifier.GroupVersionKind.GroupVersion().String(),
+		},
+		ResourceVersion: resourceVersion,
+		TimeoutSeconds:  &timeoutSeconds,
+	}
+	var watchIf watch.Interface
+	if len(resourceIdentifier.Namespace) > 0 && namespaced {
+		watchIf, err = resourceIf.Namespace(resourceIdentifier.Namespace).Watch(ctx, listOptions)
+	} else {
+		watchIf, err = resourceIf.Watch(ctx, listOptions)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in watching resource", "err", err, "resource", resourceIdentifier, "resourceVersion", resourceVersion)
+		return nil, namespaced, err
+	}
+	return watchIf, namespaced, nil
+}
+
+// DryRunApplyResource does with dryRun=All what apply of manifest does, patch of the existing resource same as
+// ApplyResource or create of a new one, returned object is what api server would persist
+func (impl K8sClientServiceImpl) DryRunApplyResource(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, manifest string) (*ManifestResponse, error) {
+	dryRun := []string{metav1.DryRunAll}
+	resp, err := impl.applyResource(ctx, restConfig, request, manifest, dryRun)
+	if errors.IsNotFound(err) {
+		resp, err = impl.createResource(ctx, restConfig, request, manifest, dryRun)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in dry run apply of resource", "err", err, "resource", request.ResourceIdentifier.Name)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DryRunUpdateResource runs UpdateResource with dryRun=All
+func (impl K8sClientServiceImpl) DryRunUpdateResource(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean) (*ManifestResponse, error) {
+	resourceIf, namespaced, err := impl.GetResourceIf(restConfig, request)
+	if err != nil {
+		impl.logger.Errorw("error in getting dynamic interface for resource", "err", err)
+		return nil, err
+	}
+	var updateObj map[string]interface{}
+	err = json.Unmarshal([]byte(request.Patch), &updateObj)
+	if err != nil {
+		impl.logger.Errorw("error in json un-marshaling patch string for dry run update of resource", "err", err)
+		return nil, err
+	}
+	resourceIdentifier := request.ResourceIdentifier
+	updateOptions := metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}}
+	var resp *unstructured.Unstructured
+	if len(resourceIdentifier.Namespace) > 0 && namespaced {
+		resp, err = resourceIf.Namespace(resourceIdentifier.Namespace).Update(ctx, &unstructured.Unstructured{Object: updateObj}, updateOptions)
+	} else {
+		resp, err = resourceIf.Update(ctx, &unstructured.Unstructured{Object: updateObj}, updateOptions)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in dry run update of resource", "err", err, "resource", resourceIdentifier.Name)
+		return nil, err
+	}
+	return &ManifestResponse{*resp}, nil
+}
+
 func (impl K8sClientServiceImpl) ApplyResource(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, manifest string) (*ManifestResponse, error) {
+	return impl.applyResource(ctx, restConfig, request, manifest, nil)
+}
+
+func (impl K8sClientServiceImpl) applyResource(ctx context.Context, restConfig *rest.Config, request *K8sRequestBean, manifest string, dryRun []string) (*ManifestResponse, error) {
 	resourceIf, namespaced, err := impl.GetResourceIf(restConfig, request)
 	if err != nil {
 		impl.logger.Errorw("error in getting dynamic interface for resource", "err", err)
 		return nil, err
 	}
 	resourceIdentifier := request.ResourceIdentifier
+	patchOptions := metav1.PatchOptions{FieldManager: "patch", DryRun: dryRun}
 	var resp *unstructured.Unstructured
 	if len(resourceIdentifier.Namespace) > 0 && namespaced {
-		resp, err = resourceIf.Namespace(resourceIdentifier.Namespace).Patch(ctx, resourceIdentifier.Name, types.StrategicMergePatchType, []byte(manifest), metav1.PatchOptions{FieldManager: "patch"})
+		resp, err = resourceIf.Namespace(resourceIdentifier.Namespace).Patch(ctx, resourceIdentifier.Name, types.StrategicMergePatchType, []byte(manifest), patchOptions)
 	} else {
-		resp, err = resourceIf.Patch(ctx, resourceIdentifier.Name, types.StrategicMergePatchType, []byte(manifest), metav1.PatchOptions{FieldManager: "patch"})
+		resp, err = resourceIf.Patch(ctx, resourceIdentifier.Name, types.StrategicMergePatchType, []byte(manifest), patchOptions)
 	}
 	if err != nil {
 		impl.logger.Errorw("error in applying resource", "err", err)
@@ -1,6 +1,8 @@
 package application
 
 import (
+	"encoding/json"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -19,6 +21,26 @@ type ApplyResourcesRequest struct {
 	ClusterId int    `json:"clusterId"`
 }
 
+const (
+	DRY_RUN_ACTION_CREATE    = "CREATE"
+	DRY_RUN_ACTION_CHANGE    = "CHANGE"
+	DRY_RUN_ACTION_UNCHANGED = "UNCHANGED"
+)
+
+// DryRunResourceResponse describes what applying a manifest would do, Diff is a json merge patch from live to dry run object
+type DryRunResourceResponse struct {
+	Kind             string                     `json:"kind"`
+	Name             string                     `json:"name"`
+	Namespace        string                     `json:"namespace,omitempty"`
+	Action           string                     `json:"action,omitempty"`
+	Diff             json.RawMessage            `json:"diff,omitempty"`
+	LiveManifest     *unstructured.Unstructured `json:"liveManifest,omitempty"`
+	DryRunManifest   *unstructured.Unstructured `json:"dryRunManifest,omitempty"`
+	ValidationErrors []string                   `json:"validationErrors,omitempty"`
+	PermissionDenied bool                       `json:"permissionDenied"`
+	Error            string                     `json:"error,omitempty"`
+}
+
 type ApplyResourcesResponse struct {
 	Kind     string `json:"kind"`
 	Name     string `json:"name"`
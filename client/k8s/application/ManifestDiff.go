@@ -0,0 +1,108 @@
+package application
+
+import (
+	"encoding/json"
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const LastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// values of secrets are never kept in diffs, only the keys whose value is changed are marked
+const (
+	MASKED_SECRET_VALUE         = "********"
+	MASKED_CHANGED_SECRET_VALUE = "******** (changed)"
+)
+
+// fields set by api server on every write, these are not considered as changes while diffing manifests
+var serverManagedMetadataFields = []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink"}
+
+// NormalizeManifestForDiff returns a copy of manifest without status and server managed metadata
+func NormalizeManifestForDiff(manifest *unstructured.Unstructured) *unstructured.Unstructured {
+	normalized := manifest.DeepCopy()
+	for _, field := range serverManagedMetadataFields {
+		unstructured.RemoveNestedField(normalized.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(normalized.Object, "metadata", "annotations", LastAppliedConfigAnnotation)
+	if annotations, found, _ := unstructured.NestedMap(normalized.Object, "metadata", "annotations"); found && len(annotations) == 0 {
+		unstructured.RemoveNestedField(normalized.Object, "metadata", "annotations")
+	}
+	unstructured.RemoveNestedField(normalized.Object, "status")
+	return normalized
+}
+
+// CreateManifestDiff returns json merge patch from before to after of normalized manifests, nil when nothing has changed.
+// Normalized after manifest is returned when before is nil and normalized before manifest when after is nil.
+func CreateManifestDiff(before *unstructured.Unstructured, after *unstructured.Unstructured) (json.RawMessage, error) {
+	if before == nil && after == nil {
+		return nil, nil
+	}
+	if before == nil || after == nil {
+		manifest := after
+		if manifest == nil {
+			manifest = before
+		}
+		return json.Marshal(NormalizeManifestForDiff(manifest).Object)
+	}
+	beforeJson, err := json.Marshal(NormalizeManifestForDiff(before).Object)
+	if err != nil {
+		return nil, err
+	}
+	afterJson, err := json.Marshal(NormalizeManifestForDiff(after).Object)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := jsonpatch.CreateMergePatch(beforeJson, afterJson)
+	if err != nil {
+		return nil, err
+	}
+	if string(patch) == "{}" {
+		return nil, nil
+	}
+	return patch, nil
+}
+
+// MaskSecretData returns copies of secrets with values of data and stringData masked, a value is marked as changed
+// in desired secret when it differs from current one. Manifests of other kinds are returned as they are.
+func MaskSecretData(current *unstructured.Unstructured, desired *unstructured.Unstructured) (*unstructured.Unstructured, *unstructured.Unstructured) {
+	object := desired
+	if object == nil {
+		object = current
+	}
+	if object == nil || object.GroupVersionKind().Group != "" || object.GetKind() != "Secret" {
+		return current, desired
+	}
+	if current != nil {
+		current = current.DeepCopy()
+	}
+	if desired != nil {
+		desired = desired.DeepCopy()
+	}
+	for _, field := range []string{"data", "stringData"} {
+		var currentData, desiredData map[string]interface{}
+		if current != nil {
+			currentData, _, _ = unstructured.NestedMap(current.Object, field)
+		}
+		if desired != nil {
+			desiredData, _, _ = unstructured.NestedMap(desired.Object, field)
+		}
+		if currentData != nil {
+			masked := make(map[string]interface{}, len(currentData))
+			for key := range currentData {
+				masked[key] = MASKED_SECRET_VALUE
+			}
+			_ = unstructured.SetNestedMap(current.Object, masked, field)
+		}
+		if desiredData != nil {
+			masked := make(map[string]interface{}, len(desiredData))
+			for key, value := range desiredData {
+				masked[key] = MASKED_SECRET_VALUE
+				if currentValue, ok := currentData[key]; current != nil && (!ok || currentValue != value) {
+					masked[key] = MASKED_CHANGED_SECRET_VALUE
+				}
+			}
+			_ = unstructured.SetNestedMap(desired.Object, masked, field)
+		}
+	}
+	return current, desired
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2020 Devtron Labs
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Command encryption re-encrypts the secret data stored in postgres, it reads the same env as the orchestrator.
+//
+//	encryption -mode=migrate   encrypts legacy plaintext rows in place
+//	encryption -mode=rotate    re-encrypts all rows with the current key, run after changing the primary key
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/encryption"
+	"github.com/devtron-labs/devtron/pkg/sql"
+)
+
+func main() {
+	mode := flag.String("mode", string(encryption.ReEncryptModeMigrate), "migrate or rotate")
+	flag.Parse()
+
+	logger, err := util.NewSugardLogger()
+	if err != nil {
+		log.Fatal(err)
+	}
+	dbConfig, err := sql.GetConfig()
+	if err != nil {
+		logger.Fatalw("error in reading db config", "err", err)
+	}
+	dbConnection, err := sql.NewDbConnection(dbConfig, logger)
+	if err != nil {
+		logger.Fatalw("error in connecting db", "err", err)
+	}
+	defer dbConnection.Close()
+	encryptor, err := encryption.GetDefaultEncryptor()
+	if err != nil {
+		logger.Fatalw("error in initialising encryption", "err", err)
+	}
+	results, err := encryption.NewReEncryptionServiceImpl(logger, dbConnection, encryptor).ReEncrypt(encryption.ReEncryptMode(*mode))
+	for _, result := range results {
+		logger.Infow("re-encrypt result", "table", result.Table, "scanned", result.Scanned, "encrypted", result.Encrypted, "skipped", result.Skipped)
+	}
+	if err != nil {
+		logger.Fatalw("error in re-encrypting data", "mode", *mode, "err", err)
+	}
+}
@@ -57,6 +57,8 @@ type MuxRouter struct {
 	userTerminalAccessRouter terminal.UserTerminalAccessRouter
 	attributesRouter         router.AttributesRouter
 	appRouter                router.AppRouter
+
+	terminalSessionRecordingRouter terminal.TerminalSessionRecordingRouter
 }
 
 func NewMuxRouter(
@@ -86,6 +88,7 @@ func NewMuxRouter(
 	userTerminalAccessRouter terminal.UserTerminalAccessRouter,
 	attributesRouter router.AttributesRouter,
 	appRouter router.AppRouter,
+	terminalSessionRecordingRouter terminal.TerminalSessionRecordingRouter,
 ) *MuxRouter {
 	r := &MuxRouter{
 		Router:                   mux.NewRouter(),
@@ -116,6 +119,8 @@ func NewMuxRouter(
 		userTerminalAccessRouter: userTerminalAccessRouter,
 		attributesRouter:         attributesRouter,
 		appRouter:                appRouter,
+
+		terminalSessionRecordingRouter: terminalSessionRecordingRouter,
 	}
 	return r
 }
@@ -236,6 +241,9 @@ func (r *MuxRouter) Init() {
 	userTerminalAccessRouter := r.Router.PathPrefix("/orchestrator/user/terminal").Subrouter()
 	r.userTerminalAccessRouter.InitTerminalAccessRouter(userTerminalAccessRouter)
 
+	terminalSessionRecordingRouter := r.Router.PathPrefix("/orchestrator/terminal-session-recording").Subrouter()
+	r.terminalSessionRecordingRouter.InitTerminalSessionRecordingRouter(terminalSessionRecordingRouter)
+
 	attributeRouter := r.Router.PathPrefix("/orchestrator/attributes").Subrouter()
 	r.attributesRouter.InitAttributesRouter(attributeRouter)
 }
@@ -166,6 +166,11 @@ func InitializeApp() (*App, error) {
 
 		kubernetesResourceAuditLogs.Newk8sResourceHistoryServiceImpl,
 		wire.Bind(new(kubernetesResourceAuditLogs.K8sResourceHistoryService), new(*kubernetesResourceAuditLogs.K8sResourceHistoryServiceImpl)),
+
+		repository2.NewK8sResourceAuditLogRepositoryImpl,
+		wire.Bind(new(repository2.K8sResourceAuditLogRepository), new(*repository2.K8sResourceAuditLogRepositoryImpl)),
+		kubernetesResourceAuditLogs.NewK8sResourceAuditLogServiceImpl,
+		wire.Bind(new(kubernetesResourceAuditLogs.K8sResourceAuditLogService), new(*kubernetesResourceAuditLogs.K8sResourceAuditLogServiceImpl)),
 	)
 	return &App{}, nil
 }
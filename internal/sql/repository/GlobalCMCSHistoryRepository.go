@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+const (
+	GLOBAL_CM_CS_ACTION_CREATE = "CREATE"
+	GLOBAL_CM_CS_ACTION_UPDATE = "UPDATE"
+	GLOBAL_CM_CS_ACTION_DELETE = "DELETE"
+)
+
+type GlobalCMCSHistoryRepository interface {
+	Save(model *GlobalCMCSHistory) (*GlobalCMCSHistory, error)
+	FindByGlobalCMCSId(globalCMCSId int) ([]*GlobalCMCSHistory, error)
+}
+
+type GlobalCMCSHistoryRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewGlobalCMCSHistoryRepositoryImpl(logger *zap.SugaredLogger, dbConnection *pg.DB) *GlobalCMCSHistoryRepositoryImpl {
+	return &GlobalCMCSHistoryRepositoryImpl{dbConnection: dbConnection, logger: logger}
+}
+
+// GlobalCMCSHistory is an audit entry of a change in global cm/cs, config holds the json of the config after the change
+type GlobalCMCSHistory struct {
+	TableName    struct{} `sql:"global_cm_cs_history" pg:",discard_unknown_columns"`
+	Id           int      `sql:"id,pk"`
+	GlobalCMCSId int      `sql:"global_cm_cs_id,notnull"`
+	Version      int      `sql:"version,notnull"`
+	Action       string   `sql:"action,notnull"` // [CREATE, UPDATE, DELETE]
+	Config       string   `sql:"config"`
+	sql.AuditLog
+}
+
+func (impl *GlobalCMCSHistoryRepositoryImpl) Save(model *GlobalCMCSHistory) (*GlobalCMCSHistory, error) {
+	err := impl.dbConnection.Insert(model)
+	if err != nil {
+		impl.logger.Errorw("err on saving global cm/cs history", "err", err, "globalCMCSId", model.GlobalCMCSId)
+		return nil, err
+	}
+	return model, nil
+}
+
+func (impl *GlobalCMCSHistoryRepositoryImpl) FindByGlobalCMCSId(globalCMCSId int) ([]*GlobalCMCSHistory, error) {
+	var models []*GlobalCMCSHistory
+	err := impl.dbConnection.Model(&models).
+		Where("global_cm_cs_id = ?", globalCMCSId).
+		Order("version DESC").Select()
+	if err != nil {
+		impl.logger.Errorw("err on getting global cm/cs history", "err", err, "globalCMCSId", globalCMCSId)
+		return nil, err
+	}
+	return models, nil
+}
@@ -13,6 +13,9 @@ type GlobalCMCSRepository interface {
 	FindAllActive() ([]*GlobalCMCS, error)
 	FindByConfigTypeAndName(configType, name string) (*GlobalCMCS, error)
 	FindByMountPath(mountPath string) (*GlobalCMCS, error)
+	FindById(id int) (*GlobalCMCS, error)
+	FindActiveCiPipelineScopes() ([]*GlobalCMCSPipelineScope, error)
+	FindActiveCdPipelineScopes() ([]*GlobalCMCSPipelineScope, error)
 }
 
 const (
@@ -22,6 +25,13 @@ const (
 	VOLUME_CONFIG      = "volume"
 )
 
+// stages of workflows in which a global cm/cs can be used
+const (
+	GLOBAL_CM_CS_STAGE_CI      = "CI"
+	GLOBAL_CM_CS_STAGE_PRE_CD  = "PRE_CD"
+	GLOBAL_CM_CS_STAGE_POST_CD = "POST_CD"
+)
+
 type GlobalCMCSRepositoryImpl struct {
 	dbConnection *pg.DB
 	logger       *zap.SugaredLogger
@@ -41,9 +51,27 @@ type GlobalCMCS struct {
 	Data      json.RawMessage `sql:"data"`
 	MountPath string          `sql:"mount_path"`
 	Deleted   bool            `sql:"deleted,notnull"`
+	//json string of selectors restricting the pipelines this config is used in, empty means all pipelines
+	Selectors   string   `sql:"selectors"`
+	UseInStages []string `sql:"use_in_stages" pg:",array"`
+	Version     int      `sql:"version,notnull"`
 	sql.AuditLog
 }
 
+// GlobalCMCSPipelineScope holds the details of a pipeline against which selectors of global cm/cs are matched
+type GlobalCMCSPipelineScope struct {
+	PipelineId      int    `sql:"pipeline_id"`
+	PipelineName    string `sql:"pipeline_name"`
+	AppId           int    `sql:"app_id"`
+	AppName         string `sql:"app_name"`
+	TeamId          int    `sql:"team_id"`
+	EnvironmentId   int    `sql:"environment_id"`
+	EnvironmentName string `sql:"environment_name"`
+	ClusterId       int    `sql:"cluster_id"`
+	HasPreStage     bool   `sql:"has_pre_stage"`
+	HasPostStage    bool   `sql:"has_post_stage"`
+}
+
 func (impl *GlobalCMCSRepositoryImpl) Save(model *GlobalCMCS) (*GlobalCMCS, error) {
 	err := impl.dbConnection.Insert(model)
 	if err != nil {
@@ -97,3 +125,43 @@ func (impl *GlobalCMCSRepositoryImpl) FindByMountPath(mountPath string) (*Global
 	}
 	return model, nil
 }
+
+func (impl *GlobalCMCSRepositoryImpl) FindById(id int) (*GlobalCMCS, error) {
+	model := &GlobalCMCS{}
+	err := impl.dbConnection.Model(model).
+		Where("id = ?", id).
+		Where("deleted = ?", false).Select()
+	if err != nil {
+		impl.logger.Errorw("err on getting global cm/cs config by id", "err", err, "id", id)
+		return nil, err
+	}
+	return model, nil
+}
+
+func (impl *GlobalCMCSRepositoryImpl) FindActiveCiPipelineScopes() ([]*GlobalCMCSPipelineScope, error) {
+	var scopes []*GlobalCMCSPipelineScope
+	query := "SELECT cp.id as pipeline_id, cp.name as pipeline_name, a.id as app_id, a.app_name, a.team_id" +
+		" FROM ci_pipeline cp INNER JOIN app a ON a.id = cp.app_id" +
+		" WHERE cp.active = true AND cp.deleted = false AND a.active = true ORDER BY a.app_name, cp.id;"
+	_, err := impl.dbConnection.Query(&scopes, query)
+	if err != nil {
+		impl.logger.Errorw("err on getting ci pipelines for global cm/cs scope", "err", err)
+		return nil, err
+	}
+	return scopes, nil
+}
+
+func (impl *GlobalCMCSRepositoryImpl) FindActiveCdPipelineScopes() ([]*GlobalCMCSPipelineScope, error) {
+	var scopes []*GlobalCMCSPipelineScope
+	query := "SELECT p.id as pipeline_id, p.pipeline_name, a.id as app_id, a.app_name, a.team_id," +
+		" e.id as environment_id, e.environment_name, e.cluster_id," +
+		" COALESCE(p.pre_stage_config_yaml, '') <> '' as has_pre_stage, COALESCE(p.post_stage_config_yaml, '') <> '' as has_post_stage" +
+		" FROM pipeline p INNER JOIN app a ON a.id = p.app_id INNER JOIN environment e ON e.id = p.environment_id" +
+		" WHERE p.deleted = false AND a.active = true AND e.active = true ORDER BY a.app_name, p.id;"
+	_, err := impl.dbConnection.Query(&scopes, query)
+	if err != nil {
+		impl.logger.Errorw("err on getting cd pipelines for global cm/cs scope", "err", err)
+		return nil, err
+	}
+	return scopes, nil
+}
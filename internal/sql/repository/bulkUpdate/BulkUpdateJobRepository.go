@@ -0,0 +1,176 @@
+package bulkUpdate
+
+import (
+	"github.com/devtron-labs/devtron/pkg/encryption"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	BULK_UPDATE_JOB_RUNNING            = "RUNNING"
+	BULK_UPDATE_JOB_COMPLETED          = "COMPLETED"
+	BULK_UPDATE_JOB_FAILED             = "FAILED"
+	BULK_UPDATE_JOB_REVERTED           = "REVERTED"
+	BULK_UPDATE_JOB_PARTIALLY_REVERTED = "PARTIALLY_REVERTED"
+)
+
+// kind of a job tells the type of its payload and response
+const (
+	BULK_UPDATE_JOB_KIND_APPLICATION = "application"
+	BULK_UPDATE_JOB_KIND_CI_PIPELINE = "ci-pipeline"
+)
+
+// object types changed by a bulk update, object id of a job item refers to the row of the table of its type
+const (
+	BULK_UPDATE_OBJECT_CHART            = "CHART"            // charts
+	BULK_UPDATE_OBJECT_ENV_OVERRIDE     = "ENV_OVERRIDE"     // chart_env_config_override
+	BULK_UPDATE_OBJECT_CM_APP_LEVEL     = "CM_APP_LEVEL"     // config_map_app_level.config_map_data
+	BULK_UPDATE_OBJECT_CM_ENV_LEVEL     = "CM_ENV_LEVEL"     // config_map_env_level.config_map_data
+	BULK_UPDATE_OBJECT_SECRET_APP_LEVEL = "SECRET_APP_LEVEL" // config_map_app_level.secret_data
+	BULK_UPDATE_OBJECT_SECRET_ENV_LEVEL = "SECRET_ENV_LEVEL" // config_map_env_level.secret_data
+	BULK_UPDATE_OBJECT_CI_PIPELINE      = "CI_PIPELINE"      // ci_pipeline, states are the json of the whole ci pipeline
+)
+
+type BulkUpdateJob struct {
+	tableName  struct{}  `sql:"bulk_update_job" pg:",discard_unknown_columns"`
+	Id         int       `sql:"id,pk"`
+	Kind       string    `sql:"kind,notnull"`
+	Status     string    `sql:"status,notnull"`
+	Payload    string    `sql:"payload"`
+	Response   string    `sql:"response"`
+	StartedOn  time.Time `sql:"started_on"`
+	FinishedOn time.Time `sql:"finished_on"`
+	sql.AuditLog
+}
+
+// BulkUpdateJobItem holds the state of an object before and after a bulk update, reverting a job writes back the before state
+type BulkUpdateJobItem struct {
+	tableName   struct{} `sql:"bulk_update_job_item" pg:",discard_unknown_columns"`
+	Id          int      `sql:"id,pk"`
+	JobId       int      `sql:"job_id,notnull"`
+	ObjectType  string   `sql:"object_type,notnull"`
+	ObjectId    int      `sql:"object_id,notnull"`
+	AppId       int      `sql:"app_id,notnull"`
+	AppName     string   `sql:"app_name"`
+	EnvId       int      `sql:"env_id"`
+	BeforeState string   `sql:"before_state"`
+	AfterState  string   `sql:"after_state"`
+	Reverted    bool     `sql:"reverted,notnull"`
+	sql.AuditLog
+}
+
+func (item *BulkUpdateJobItem) IsSecret() bool {
+	return item.ObjectType == BULK_UPDATE_OBJECT_SECRET_APP_LEVEL || item.ObjectType == BULK_UPDATE_OBJECT_SECRET_ENV_LEVEL
+}
+
+// states of secrets are encrypted before they are written and decrypted after they are read, like secret data itself
+
+func (item *BulkUpdateJobItem) BeforeInsert(db orm.DB) error {
+	if !item.IsSecret() {
+		return nil
+	}
+	if err := encryption.EncryptField(&item.BeforeState); err != nil {
+		return err
+	}
+	return encryption.EncryptField(&item.AfterState)
+}
+
+func (item *BulkUpdateJobItem) AfterInsert(db orm.DB) error {
+	return item.decryptStates()
+}
+
+func (item *BulkUpdateJobItem) AfterQuery(db orm.DB) error {
+	return item.decryptStates()
+}
+
+func (item *BulkUpdateJobItem) decryptStates() error {
+	if !item.IsSecret() {
+		return nil
+	}
+	if err := encryption.DecryptField(&item.BeforeState); err != nil {
+		return err
+	}
+	return encryption.DecryptField(&item.AfterState)
+}
+
+type BulkUpdateJobRepository interface {
+	SaveJob(job *BulkUpdateJob) error
+	UpdateJob(job *BulkUpdateJob) error
+	FindJobById(id int) (*BulkUpdateJob, error)
+	FindRecentJobs(limit int) ([]*BulkUpdateJob, error)
+	SaveItem(item *BulkUpdateJobItem) error
+	// MarkItemReverted marks the item reverted in tx if given, so that it is marked along with the write back of its state
+	MarkItemReverted(id int, userId int32, tx *pg.Tx) error
+	FindItemsByJobId(jobId int) ([]*BulkUpdateJobItem, error)
+}
+
+type BulkUpdateJobRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewBulkUpdateJobRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *BulkUpdateJobRepositoryImpl {
+	return &BulkUpdateJobRepositoryImpl{dbConnection: dbConnection, logger: logger}
+}
+
+func (repositoryImpl BulkUpdateJobRepositoryImpl) SaveJob(job *BulkUpdateJob) error {
+	err := repositoryImpl.dbConnection.Insert(job)
+	if err != nil {
+		repositoryImpl.logger.Errorw("error in saving bulk update job", "err", err)
+	}
+	return err
+}
+
+func (repositoryImpl BulkUpdateJobRepositoryImpl) UpdateJob(job *BulkUpdateJob) error {
+	err := repositoryImpl.dbConnection.Update(job)
+	if err != nil {
+		repositoryImpl.logger.Errorw("error in updating bulk update job", "err", err, "jobId", job.Id)
+	}
+	return err
+}
+
+func (repositoryImpl BulkUpdateJobRepositoryImpl) FindJobById(id int) (*BulkUpdateJob, error) {
+	job := &BulkUpdateJob{}
+	err := repositoryImpl.dbConnection.Model(job).Where("id = ?", id).Select()
+	return job, err
+}
+
+func (repositoryImpl BulkUpdateJobRepositoryImpl) FindRecentJobs(limit int) ([]*BulkUpdateJob, error) {
+	var jobs []*BulkUpdateJob
+	err := repositoryImpl.dbConnection.Model(&jobs).Order("id DESC").Limit(limit).Select()
+	return jobs, err
+}
+
+func (repositoryImpl BulkUpdateJobRepositoryImpl) SaveItem(item *BulkUpdateJobItem) error {
+	err := repositoryImpl.dbConnection.Insert(item)
+	if err != nil {
+		repositoryImpl.logger.Errorw("error in saving bulk update job item", "err", err, "jobId", item.JobId)
+	}
+	return err
+}
+
+func (repositoryImpl BulkUpdateJobRepositoryImpl) MarkItemReverted(id int, userId int32, tx *pg.Tx) error {
+	var db orm.DB = repositoryImpl.dbConnection
+	if tx != nil {
+		db = tx
+	}
+	_, err := db.Model(&BulkUpdateJobItem{}).
+		Set("reverted = ?", true).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", userId).
+		Where("id = ?", id).
+		Update()
+	if err != nil {
+		repositoryImpl.logger.Errorw("error in marking bulk update job item reverted", "err", err, "id", id)
+	}
+	return err
+}
+
+func (repositoryImpl BulkUpdateJobRepositoryImpl) FindItemsByJobId(jobId int) ([]*BulkUpdateJobItem, error) {
+	var items []*BulkUpdateJobItem
+	err := repositoryImpl.dbConnection.Model(&items).Where("job_id = ?", jobId).Order("id ASC").Select()
+	return items, err
+}
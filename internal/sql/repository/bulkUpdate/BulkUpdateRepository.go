@@ -5,8 +5,10 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/app"
 	"github.com/devtron-labs/devtron/internal/sql/repository/chartConfig"
 	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
+	"github.com/devtron-labs/devtron/pkg/encryption"
 	"github.com/go-pg/pg"
 	"go.uber.org/zap"
+	"regexp"
 	"strings"
 )
 
@@ -21,6 +23,7 @@ type BulkUpdateReadme struct {
 type BulkUpdateRepository interface {
 	BuildAppNameQuery(appNameIncludes []string, appNameExcludes []string) string
 	FindBulkUpdateReadme(operation string) (*BulkUpdateReadme, error)
+	FindAppsByAppNameSubstring(appNameIncludes []string, appNameExcludes []string) ([]*app.App, error)
 
 	//For Deployment Template :
 	FindDeploymentTemplateBulkAppNameForGlobal(appNameIncludes []string, appNameExcludes []string) ([]*app.App, error)
@@ -43,6 +46,14 @@ type BulkUpdateRepository interface {
 	BulkUpdateSecretDataForGlobalById(id int, patch string) error
 	BulkUpdateConfigMapDataForEnvById(id int, patch string) error
 	BulkUpdateSecretDataForEnvById(id int, patch string) error
+
+	//For revert of bulk update jobs, rows are locked till the end of tx so that the state is checked and written back atomically :
+	GetConnection() *pg.DB
+	FindChartByIdForUpdate(id int, tx *pg.Tx) (*chartRepoRepository.Chart, error)
+	FindChartEnvByIdForUpdate(id int, tx *pg.Tx) (*chartConfig.EnvConfigOverride, error)
+	FindConfigMapAppModelByIdForUpdate(id int, tx *pg.Tx) (*chartConfig.ConfigMapAppModel, error)
+	FindConfigMapEnvModelByIdForUpdate(id int, tx *pg.Tx) (*chartConfig.ConfigMapEnvModel, error)
+	UpdateColumnsWithTxn(model interface{}, tx *pg.Tx, columns ...string) error
 }
 
 func NewBulkUpdateRepository(dbConnection *pg.DB,
@@ -79,14 +90,31 @@ func (repositoryImpl BulkUpdateRepositoryImpl) BuildCMNameQuery(configMapNames [
 
 	return configMapNameQuery
 }
+
+// BuildSecretNameQuery also selects encrypted rows as their names can not be matched in sql,
+// these are matched by secretDataMatchesNames once decrypted
 func (repositoryImpl BulkUpdateRepositoryImpl) BuildSecretNameQuery(secretNames []string) string {
 	secretNameQuery := "secret_data LIKE ANY (array["
 	secretNameQuery += "'%" + strings.Join(secretNames, "%', '%") + "%'"
 	secretNameQuery += "])"
+	secretNameQuery += fmt.Sprintf(" OR secret_data LIKE '%s%%'", encryption.EncryptedValuePrefix)
 	secretNameQuery = fmt.Sprintf("( %s ) ", secretNameQuery)
 
 	return secretNameQuery
 }
+
+// secretDataMatchesNames applies the LIKE '%name%' semantics of BuildSecretNameQuery on decrypted secret data
+func secretDataMatchesNames(secretData string, secretNames []string) bool {
+	for _, secretName := range secretNames {
+		pattern := regexp.QuoteMeta(secretName)
+		pattern = strings.ReplaceAll(pattern, "%", ".*")
+		pattern = strings.ReplaceAll(pattern, "_", ".")
+		if matched, err := regexp.MatchString("(?s)"+pattern, secretData); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
 func (repositoryImpl BulkUpdateRepositoryImpl) FindBulkUpdateReadme(resource string) (*BulkUpdateReadme, error) {
 	bulkUpdateReadme := &BulkUpdateReadme{}
 	err := repositoryImpl.dbConnection.
@@ -95,6 +123,19 @@ func (repositoryImpl BulkUpdateRepositoryImpl) FindBulkUpdateReadme(resource str
 	return bulkUpdateReadme, err
 }
 
+func (repositoryImpl BulkUpdateRepositoryImpl) FindAppsByAppNameSubstring(appNameIncludes []string, appNameExcludes []string) ([]*app.App, error) {
+	apps := []*app.App{}
+	appNameQuery := repositoryImpl.BuildAppNameQuery(appNameIncludes, appNameExcludes)
+	err := repositoryImpl.dbConnection.
+		Model(&apps).
+		Where(appNameQuery).
+		Where("app.active = ?", true).
+		Where("app.app_store = ?", false).
+		Order("app.id ASC").
+		Select()
+	return apps, err
+}
+
 func (repositoryImpl BulkUpdateRepositoryImpl) FindDeploymentTemplateBulkAppNameForGlobal(appNameIncludes []string, appNameExcludes []string) ([]*app.App, error) {
 	apps := []*app.App{}
 	appNameQuery := repositoryImpl.BuildAppNameQuery(appNameIncludes, appNameExcludes)
@@ -142,7 +183,16 @@ func (repositoryImpl BulkUpdateRepositoryImpl) FindSecretBulkAppModelForGlobal(a
 		Where(secretNameQuery).
 		Where("app.active = ?", true).
 		Select()
-	return CmAndSecretAppModel, err
+	if err != nil {
+		return CmAndSecretAppModel, err
+	}
+	filteredModels := []*chartConfig.ConfigMapAppModel{}
+	for _, model := range CmAndSecretAppModel {
+		if secretDataMatchesNames(model.SecretData, secretNames) {
+			filteredModels = append(filteredModels, model)
+		}
+	}
+	return filteredModels, nil
 }
 func (repositoryImpl BulkUpdateRepositoryImpl) FindCMBulkAppModelForEnv(appNameIncludes []string, appNameExcludes []string, envId int, configMapNames []string) ([]*chartConfig.ConfigMapEnvModel, error) {
 	CmAndSecretEnvModel := []*chartConfig.ConfigMapEnvModel{}
@@ -168,7 +218,16 @@ func (repositoryImpl BulkUpdateRepositoryImpl) FindSecretBulkAppModelForEnv(appN
 		Where("app.active = ?", true).
 		Where("config_map_env_model.environment_id = ? ", envId).
 		Select()
-	return CmAndSecretEnvModel, err
+	if err != nil {
+		return CmAndSecretEnvModel, err
+	}
+	filteredModels := []*chartConfig.ConfigMapEnvModel{}
+	for _, model := range CmAndSecretEnvModel {
+		if secretDataMatchesNames(model.SecretData, secretNames) {
+			filteredModels = append(filteredModels, model)
+		}
+	}
+	return filteredModels, nil
 }
 func (repositoryImpl BulkUpdateRepositoryImpl) FindAppByChartId(chartId int) (*app.App, error) {
 	app := &app.App{}
@@ -259,7 +318,12 @@ func (repositoryImpl BulkUpdateRepositoryImpl) BulkUpdateConfigMapDataForGlobalB
 }
 func (repositoryImpl BulkUpdateRepositoryImpl) BulkUpdateSecretDataForGlobalById(id int, patch string) error {
 	SecretAppModel := &chartConfig.ConfigMapAppModel{}
-	_, err := repositoryImpl.dbConnection.
+	err := encryption.EncryptField(&patch)
+	if err != nil {
+		repositoryImpl.logger.Errorw("error in encrypting secret_data", "err", err)
+		return err
+	}
+	_, err = repositoryImpl.dbConnection.
 		Model(SecretAppModel).
 		Set("secret_data = ?", patch).
 		Where("id = ?", id).
@@ -285,7 +349,12 @@ func (repositoryImpl BulkUpdateRepositoryImpl) BulkUpdateConfigMapDataForEnvById
 }
 func (repositoryImpl BulkUpdateRepositoryImpl) BulkUpdateSecretDataForEnvById(id int, patch string) error {
 	SecretEnvModel := &chartConfig.ConfigMapEnvModel{}
-	_, err := repositoryImpl.dbConnection.
+	err := encryption.EncryptField(&patch)
+	if err != nil {
+		repositoryImpl.logger.Errorw("error in encrypting secret_data", "err", err)
+		return err
+	}
+	_, err = repositoryImpl.dbConnection.
 		Model(SecretEnvModel).
 		Set("secret_data = ?", patch).
 		Where("id = ?", id).
@@ -296,3 +365,44 @@ func (repositoryImpl BulkUpdateRepositoryImpl) BulkUpdateSecretDataForEnvById(id
 	}
 	return nil
 }
+
+func (repositoryImpl BulkUpdateRepositoryImpl) GetConnection() *pg.DB {
+	return repositoryImpl.dbConnection
+}
+
+func (repositoryImpl BulkUpdateRepositoryImpl) FindChartByIdForUpdate(id int, tx *pg.Tx) (*chartRepoRepository.Chart, error) {
+	chart := &chartRepoRepository.Chart{}
+	err := tx.Model(chart).Where("id = ?", id).For("UPDATE").Select()
+	return chart, err
+}
+
+func (repositoryImpl BulkUpdateRepositoryImpl) FindChartEnvByIdForUpdate(id int, tx *pg.Tx) (*chartConfig.EnvConfigOverride, error) {
+	chartEnv := &chartConfig.EnvConfigOverride{}
+	err := tx.Model(chartEnv).
+		Column("env_config_override.*", "Chart").
+		Where("env_config_override.id = ?", id).
+		For("UPDATE OF env_config_override").
+		Select()
+	return chartEnv, err
+}
+
+func (repositoryImpl BulkUpdateRepositoryImpl) FindConfigMapAppModelByIdForUpdate(id int, tx *pg.Tx) (*chartConfig.ConfigMapAppModel, error) {
+	model := &chartConfig.ConfigMapAppModel{}
+	err := tx.Model(model).Where("id = ?", id).For("UPDATE").Select()
+	return model, err
+}
+
+func (repositoryImpl BulkUpdateRepositoryImpl) FindConfigMapEnvModelByIdForUpdate(id int, tx *pg.Tx) (*chartConfig.ConfigMapEnvModel, error) {
+	model := &chartConfig.ConfigMapEnvModel{}
+	err := tx.Model(model).Where("id = ?", id).For("UPDATE").Select()
+	return model, err
+}
+
+// UpdateColumnsWithTxn updates only given columns of model by its primary key, hooks of model (like encryption of secret data) are run
+func (repositoryImpl BulkUpdateRepositoryImpl) UpdateColumnsWithTxn(model interface{}, tx *pg.Tx, columns ...string) error {
+	_, err := tx.Model(model).Column(columns...).WherePK().Update()
+	if err != nil {
+		repositoryImpl.logger.Errorw("error in updating columns", "err", err, "columns", columns)
+	}
+	return err
+}
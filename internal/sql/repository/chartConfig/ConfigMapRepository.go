@@ -18,8 +18,10 @@
 package chartConfig
 
 import (
+	"github.com/devtron-labs/devtron/pkg/encryption"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
 	"go.uber.org/zap"
 )
 
@@ -57,6 +59,28 @@ type ConfigMapAppModel struct {
 	sql.AuditLog
 }
 
+// secret data is encrypted before it is written and decrypted after it is read, so callers always see plaintext
+
+func (model *ConfigMapAppModel) BeforeInsert(db orm.DB) error {
+	return encryption.EncryptField(&model.SecretData)
+}
+
+func (model *ConfigMapAppModel) BeforeUpdate(db orm.DB) error {
+	return encryption.EncryptField(&model.SecretData)
+}
+
+func (model *ConfigMapAppModel) AfterInsert(db orm.DB) error {
+	return encryption.DecryptField(&model.SecretData)
+}
+
+func (model *ConfigMapAppModel) AfterUpdate(db orm.DB) error {
+	return encryption.DecryptField(&model.SecretData)
+}
+
+func (model *ConfigMapAppModel) AfterQuery(db orm.DB) error {
+	return encryption.DecryptField(&model.SecretData)
+}
+
 func (impl ConfigMapRepositoryImpl) CreateAppLevel(model *ConfigMapAppModel) (*ConfigMapAppModel, error) {
 	err := impl.dbConnection.Insert(model)
 	if err != nil {
@@ -104,6 +128,26 @@ type ConfigMapEnvModel struct {
 	sql.AuditLog
 }
 
+func (model *ConfigMapEnvModel) BeforeInsert(db orm.DB) error {
+	return encryption.EncryptField(&model.SecretData)
+}
+
+func (model *ConfigMapEnvModel) BeforeUpdate(db orm.DB) error {
+	return encryption.EncryptField(&model.SecretData)
+}
+
+func (model *ConfigMapEnvModel) AfterInsert(db orm.DB) error {
+	return encryption.DecryptField(&model.SecretData)
+}
+
+func (model *ConfigMapEnvModel) AfterUpdate(db orm.DB) error {
+	return encryption.DecryptField(&model.SecretData)
+}
+
+func (model *ConfigMapEnvModel) AfterQuery(db orm.DB) error {
+	return encryption.DecryptField(&model.SecretData)
+}
+
 func (impl ConfigMapRepositoryImpl) CreateEnvLevel(model *ConfigMapEnvModel) (*ConfigMapEnvModel, error) {
 	err := impl.dbConnection.Insert(model)
 	if err != nil {
@@ -135,8 +135,16 @@ func (impl PipelineRepositoryImpl) FindByIdsIn(ids []int) ([]*Pipeline, error) {
 		Select()
 	if err != nil {
 		impl.logger.Errorw("error on fetching pipelines", "ids", ids)
+		return pipelines, err
 	}
-	return pipelines, err
+	for _, pipeline := range pipelines {
+		err = pipeline.Environment.DecryptClusterConfig()
+		if err != nil {
+			impl.logger.Errorw("error in decrypting cluster config", "err", err, "pipelineId", pipeline.Id)
+			return pipelines, err
+		}
+	}
+	return pipelines, nil
 }
 
 func (impl PipelineRepositoryImpl) FindByIdsInAndEnvironment(ids []int, environmentId int) ([]*Pipeline, error) {
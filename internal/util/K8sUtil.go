@@ -216,6 +216,33 @@ func (impl K8sUtil) CreateNsIfNotExists(namespace string, clusterConfig *Cluster
 	return err
 }
 
+func (impl K8sUtil) DeleteNsIfExists(namespace string, clusterConfig *ClusterConfig) (err error) {
+	client, err := impl.GetClient(clusterConfig)
+	if err != nil {
+		impl.logger.Errorw("error", "error", err, "clusterConfig", clusterConfig)
+		return err
+	}
+	exists, err := impl.checkIfNsExists(namespace, client)
+	if err != nil {
+		impl.logger.Errorw("error", "error", err, "clusterConfig", clusterConfig)
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	impl.logger.Infow("ns exists deleting", "ns", namespace)
+	return impl.deleteNs(namespace, client)
+}
+
+func (impl K8sUtil) ListPods(namespace string, clusterConfig *ClusterConfig) (*v1.PodList, error) {
+	client, err := impl.GetClient(clusterConfig)
+	if err != nil {
+		impl.logger.Errorw("error", "error", err, "clusterConfig", clusterConfig)
+		return nil, err
+	}
+	return client.Pods(namespace).List(context.Background(), metav1.ListOptions{})
+}
+
 func (impl K8sUtil) checkIfNsExists(namespace string, client *v12.CoreV1Client) (exists bool, err error) {
 	ns, err := client.Namespaces().Get(context.Background(), namespace, metav1.GetOptions{})
 	//ns, err := impl.k8sClient.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{})
@@ -19,6 +19,7 @@ package app
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"github.com/argoproj/gitops-engine/pkg/health"
@@ -33,6 +34,7 @@ import (
 	"go.opentelemetry.io/otel"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	chart2 "k8s.io/helm/pkg/proto/hapi/chart"
+	"net/http"
 	"net/url"
 	"path"
 	"path/filepath"
@@ -44,10 +46,12 @@ import (
 	"github.com/devtron-labs/devtron/pkg/appStatus"
 	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
 	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/externalSecret"
 	history2 "github.com/devtron-labs/devtron/pkg/pipeline/history"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
 	util3 "github.com/devtron-labs/devtron/pkg/util"
+	"github.com/devtron-labs/devtron/pkg/variables"
 
 	application2 "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
@@ -146,6 +150,8 @@ type AppServiceImpl struct {
 	appStatusConfig                        *AppStatusConfig
 	gitOpsConfigRepository                 repository.GitOpsConfigRepository
 	appStatusService                       appStatus.AppStatusService
+	externalSecretProviderService          externalSecret.ExternalSecretProviderService
+	scopedVariableService                  variables.ScopedVariableService
 }
 
 type AppService interface {
@@ -203,7 +209,9 @@ func NewAppService(
 	pipelineStatusTimelineService PipelineStatusTimelineService,
 	appStatusConfig *AppStatusConfig,
 	gitOpsConfigRepository repository.GitOpsConfigRepository,
-	appStatusService appStatus.AppStatusService) *AppServiceImpl {
+	appStatusService appStatus.AppStatusService,
+	externalSecretProviderService externalSecret.ExternalSecretProviderService,
+	scopedVariableService variables.ScopedVariableService) *AppServiceImpl {
 	appServiceImpl := &AppServiceImpl{
 		environmentConfigRepository:            environmentConfigRepository,
 		mergeUtil:                              mergeUtil,
@@ -256,6 +264,8 @@ func NewAppService(
 		appStatusConfig:                        appStatusConfig,
 		gitOpsConfigRepository:                 gitOpsConfigRepository,
 		appStatusService:                       appStatusService,
+		externalSecretProviderService:          externalSecretProviderService,
+		scopedVariableService:                  scopedVariableService,
 	}
 	return appServiceImpl
 }
@@ -776,6 +786,23 @@ func (impl *AppServiceImpl) getDbMigrationOverride(overrideRequest *bean.ValuesO
 	return confByte, nil
 }
 
+// validateClusterReachable fails the deployment right away when the cluster failed its last consecutive connection checks
+func (impl *AppServiceImpl) validateClusterReachable(envId int) error {
+	environment, err := impl.envRepository.FindById(envId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching environment", "err", err, "envId", envId)
+		return err
+	}
+	if environment.Cluster != nil && len(environment.Cluster.ErrorInConnecting) > 0 {
+		return &ApiError{
+			HttpStatusCode:  http.StatusPreconditionFailed,
+			UserMessage:     fmt.Sprintf("deployment failed as cluster %s of environment %s is unreachable: %s", environment.Cluster.ClusterName, environment.Name, environment.Cluster.ErrorInConnecting),
+			InternalMessage: environment.Cluster.ErrorInConnecting,
+		}
+	}
+	return nil
+}
+
 func (impl *AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideRequest, ctx context.Context, triggeredAt time.Time, deployedBy int32, wfrId int) (id int, err error) {
 	if overrideRequest.DeploymentType == models.DEPLOYMENTTYPE_UNKNOWN {
 		overrideRequest.DeploymentType = models.DEPLOYMENTTYPE_DEPLOY
@@ -790,6 +817,10 @@ func (impl *AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideR
 		impl.logger.Errorw("invalid req", "err", err, "req", overrideRequest)
 		return 0, err
 	}
+	err = impl.validateClusterReachable(pipeline.EnvironmentId)
+	if err != nil {
+		return 0, err
+	}
 	envOverride := &chartConfig.EnvConfigOverride{}
 	var appMetrics *bool
 	strategy := &chartConfig.PipelineStrategy{}
@@ -1081,7 +1112,7 @@ func (impl *AppServiceImpl) TriggerRelease(overrideRequest *bean.ValuesOverrideR
 		appLabelJsonByte = nil
 	}
 	_, span = otel.Tracer("orchestrator").Start(ctx, "mergeAndSave")
-	releaseId, pipelineOverrideId, mergeAndSave, saveErr := impl.mergeAndSave(envOverride, overrideRequest, dbMigrationOverride, artifact, pipeline, configMapJson, appLabelJsonByte, strategy, ctx, triggeredAt, deployedBy, appMetrics)
+	releaseId, pipelineOverrideId, mergeAndSave, saveErr := impl.mergeAndSave(envOverride, overrideRequest, dbMigrationOverride, artifact, pipeline, configMapJson, appLabelJsonByte, strategy, ctx, triggeredAt, deployedBy, appMetrics, wfrId)
 	span.End()
 	if releaseId != 0 {
 		//updating the acd app with updated values and sync operation
@@ -1295,6 +1326,11 @@ func (impl *AppServiceImpl) GetCmSecretNew(appId int, envId int) (*bean.ConfigMa
 	if err != nil {
 		return nil, nil, err
 	}
+	secretDataJson, err = impl.externalSecretProviderService.ResolveSecretStores(envId, secretDataJson)
+	if err != nil {
+		impl.logger.Errorw("error in resolving external secret stores", "err", err, "appId", appId, "envId", envId)
+		return nil, nil, err
+	}
 	configResponse := bean.ConfigMapJson{}
 	if configMapJson != "" {
 		err = json.Unmarshal([]byte(configMapJson), &configResponse)
@@ -1425,6 +1461,11 @@ func (impl *AppServiceImpl) getConfigMapAndSecretJsonV2(appId int, envId int, pi
 	if err != nil {
 		return []byte("{}"), err
 	}
+	secretDataJson, err = impl.externalSecretProviderService.ResolveSecretStores(envId, secretDataJson)
+	if err != nil {
+		impl.logger.Errorw("error in resolving external secret stores", "err", err, "appId", appId, "envId", envId)
+		return []byte("{}"), err
+	}
 	configResponseR := bean.ConfigMapRootJson{}
 	configResponse := bean.ConfigMapJson{}
 	if configMapJson != "" {
@@ -1612,7 +1653,7 @@ func (impl *AppServiceImpl) mergeAndSave(envOverride *chartConfig.EnvConfigOverr
 	dbMigrationOverride []byte,
 	artifact *repository.CiArtifact,
 	pipeline *pipelineConfig.Pipeline, configMapJson, appLabelJsonByte []byte, strategy *chartConfig.PipelineStrategy, ctx context.Context,
-	triggeredAt time.Time, deployedBy int32, appMetrics *bool) (releaseId int, overrideId int, mergedValues string, err error) {
+	triggeredAt time.Time, deployedBy int32, appMetrics *bool, wfrId int) (releaseId int, overrideId int, mergedValues string, err error) {
 
 	//register release , obtain release id TODO: populate releaseId to template
 	override, err := impl.savePipelineOverride(overrideRequest, envOverride.Id, triggeredAt)
@@ -1657,7 +1698,14 @@ func (impl *AppServiceImpl) mergeAndSave(envOverride *chartConfig.EnvConfigOverr
 		return 0, 0, "", err
 	}
 
+	usedVariables := make(map[string]string)
+	secretVariables := make(map[string]string)
 	if configMapJson != nil {
+		configMapJson, err = impl.substituteVariablesInSecrets(configMapJson, pipeline.AppId, envOverride.TargetEnvironment, secretVariables)
+		if err != nil {
+			impl.logger.Errorw("error in substituting variables in secrets", "err", err, "pipelineId", pipeline.Id)
+			return 0, 0, "", err
+		}
 		merged, err = impl.mergeUtil.JsonPatch(merged, configMapJson)
 		if err != nil {
 			return 0, 0, "", err
@@ -1671,6 +1719,30 @@ func (impl *AppServiceImpl) mergeAndSave(envOverride *chartConfig.EnvConfigOverr
 		}
 	}
 
+	//variables are substituted once in merged values, covering deployment template, strategy and configmaps
+	mergedWithVariables, used, err := impl.scopedVariableService.SubstituteVariables(string(merged), pipeline.AppId, envOverride.TargetEnvironment, true)
+	if err != nil {
+		impl.logger.Errorw("error in substituting variables in deployment values", "err", err, "pipelineId", pipeline.Id)
+		return 0, 0, "", err
+	}
+	merged = []byte(mergedWithVariables)
+	for name, value := range used {
+		usedVariables[name] = value
+	}
+	variables.MaskSecretVariables(usedVariables, secretVariables)
+	err = impl.scopedVariableService.SaveSnapshot(&variables.VariableSnapshotDto{
+		AppId:              pipeline.AppId,
+		EnvironmentId:      envOverride.TargetEnvironment,
+		PipelineId:         pipeline.Id,
+		CdWorkflowRunnerId: wfrId,
+		Stage:              variables.SNAPSHOT_STAGE_DEPLOY,
+		Variables:          usedVariables,
+		UserId:             deployedBy,
+	})
+	if err != nil {
+		return 0, 0, "", err
+	}
+
 	appName := fmt.Sprintf("%s-%s", pipeline.App.AppName, envOverride.Environment.Name)
 	merged = impl.hpaCheckBeforeTrigger(ctx, appName, envOverride.Namespace, merged, pipeline.AppId)
 
@@ -1741,6 +1813,62 @@ func (impl *AppServiceImpl) mergeAndSave(envOverride *chartConfig.EnvConfigOverr
 	return override.PipelineReleaseCounter, override.Id, mergedValues, nil
 }
 
+// substituteVariablesInSecrets substitutes variables in data of secrets, which is base64 encoded and can not be
+// substituted along with rest of the values
+func (impl *AppServiceImpl) substituteVariablesInSecrets(configMapJson []byte, appId, envId int, secretVariables map[string]string) ([]byte, error) {
+	if !strings.Contains(string(configMapJson), "ConfigSecrets") {
+		return configMapJson, nil
+	}
+	root := make(map[string]json.RawMessage)
+	err := json.Unmarshal(configMapJson, &root)
+	if err != nil {
+		return nil, err
+	}
+	secretsJson := bean.ConfigSecretJson{}
+	err = json.Unmarshal(root["ConfigSecrets"], &secretsJson)
+	if err != nil {
+		return nil, err
+	}
+	substituted := false
+	for _, secret := range secretsJson.Secrets {
+		if secret.External || len(secret.Data) == 0 {
+			continue
+		}
+		data := make(map[string]string)
+		err = json.Unmarshal(secret.Data, &data)
+		if err != nil {
+			return nil, err
+		}
+		for key, encodedValue := range data {
+			value, err := base64.StdEncoding.DecodeString(encodedValue)
+			if err != nil || !variables.ContainsVariables(string(value)) {
+				continue
+			}
+			substitutedValue, used, err := impl.scopedVariableService.SubstituteVariables(string(value), appId, envId, false)
+			if err != nil {
+				return nil, fmt.Errorf("error in secret %s : %w", secret.Name, err)
+			}
+			for name, usedValue := range used {
+				secretVariables[name] = usedValue
+			}
+			data[key] = base64.StdEncoding.EncodeToString([]byte(substitutedValue))
+			substituted = true
+		}
+		secret.Data, err = json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !substituted {
+		return configMapJson, nil
+	}
+	root["ConfigSecrets"], err = json.Marshal(secretsJson)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(root)
+}
+
 func (impl *AppServiceImpl) savePipelineOverride(overrideRequest *bean.ValuesOverrideRequest, envOverrideId int, triggeredAt time.Time) (override *chartConfig.PipelineOverride, err error) {
 	currentReleaseNo, err := impl.pipelineOverrideRepository.GetCurrentPipelineReleaseCounter(overrideRequest.PipelineId)
 	if err != nil {
@@ -2007,6 +2135,11 @@ func (impl *AppServiceImpl) createHelmAppForCdPipeline(overrideRequest *bean.Val
 		}
 
 		releaseName := pipeline.DeploymentAppName
+		err := envOverride.Environment.Cluster.DecryptConfig()
+		if err != nil {
+			impl.logger.Errorw("error in decrypting cluster config", "err", err, "clusterId", envOverride.Environment.ClusterId)
+			return false, err
+		}
 		bearerToken := envOverride.Environment.Cluster.Config["bearer_token"]
 		if pipeline.DeploymentAppCreated {
 			req := &client2.UpgradeReleaseRequest{
@@ -19,6 +19,7 @@ package appClone
 
 import (
 	"context"
+	"encoding/json"
 	bean2 "github.com/devtron-labs/devtron/api/bean"
 	"github.com/devtron-labs/devtron/pkg/chart"
 	"strings"
@@ -29,12 +30,18 @@ import (
 	"github.com/devtron-labs/devtron/pkg/app"
 	"github.com/devtron-labs/devtron/pkg/appWorkflow"
 	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
 	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/util/rbac"
 	"go.uber.org/zap"
 )
 
 type AppCloneService interface {
 	CloneApp(createReq *bean.CreateAppDTO, context context.Context) (*bean.CreateAppDTO, error)
+	// CloneEnvironment creates overrides and cd pipelines on target env for apps deployed on source env, apps failing
+	// checkAuthForEnvClone on source and target env are reported as failed
+	CloneEnvironment(ctx context.Context, request *EnvCloneRequest, token string,
+		checkAuthForEnvClone func(token string, appObject string, sourceEnvObject string, targetEnvObject string) bool) (*EnvCloneResponse, error)
 }
 type AppCloneServiceImpl struct {
 	logger                  *zap.SugaredLogger
@@ -47,6 +54,9 @@ type AppCloneServiceImpl struct {
 	propertiesConfigService pipeline.PropertiesConfigService
 	pipelineStageService    pipeline.PipelineStageService
 	ciTemplateService       pipeline.CiTemplateService
+	pipelineRepository      pipelineConfig.PipelineRepository
+	environmentRepository   repository.EnvironmentRepository
+	enforcerUtil            rbac.EnforcerUtil
 }
 
 func NewAppCloneServiceImpl(logger *zap.SugaredLogger,
@@ -58,7 +68,10 @@ func NewAppCloneServiceImpl(logger *zap.SugaredLogger,
 	appListingService app.AppListingService,
 	propertiesConfigService pipeline.PropertiesConfigService,
 	ciTemplateOverrideRepository pipelineConfig.CiTemplateOverrideRepository,
-	pipelineStageService pipeline.PipelineStageService, ciTemplateService pipeline.CiTemplateService) *AppCloneServiceImpl {
+	pipelineStageService pipeline.PipelineStageService, ciTemplateService pipeline.CiTemplateService,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	environmentRepository repository.EnvironmentRepository,
+	enforcerUtil rbac.EnforcerUtil) *AppCloneServiceImpl {
 	return &AppCloneServiceImpl{
 		logger:                  logger,
 		pipelineBuilder:         pipelineBuilder,
@@ -70,6 +83,9 @@ func NewAppCloneServiceImpl(logger *zap.SugaredLogger,
 		propertiesConfigService: propertiesConfigService,
 		pipelineStageService:    pipelineStageService,
 		ciTemplateService:       ciTemplateService,
+		pipelineRepository:      pipelineRepository,
+		environmentRepository:   environmentRepository,
+		enforcerUtil:            enforcerUtil,
 	}
 }
 
@@ -357,41 +373,60 @@ func (impl *AppCloneServiceImpl) CreateEnvCm(ctx context.Context, oldAppId, newA
 	}
 	for _, refEnv := range refEnvs {
 		impl.logger.Debugw("cloning cfg for env", "env", refEnv)
-		refCm, err := impl.configMapService.CMEnvironmentFetch(oldAppId, refEnv.EnvironmentId)
-		if err != nil {
-			return nil, err
-		}
-		thisCm, err := impl.configMapService.CMEnvironmentFetch(newAppId, refEnv.EnvironmentId)
+		_, err = impl.cloneEnvCm(oldAppId, refEnv.EnvironmentId, newAppId, refEnv.EnvironmentId, userId, nil, false)
 		if err != nil {
 			return nil, err
 		}
+	}
+	return nil, nil
+}
 
-		var refEnvCm []*pipeline.ConfigData
-		for _, refCmData := range refCm.ConfigData {
-			if !refCmData.Global || refCmData.Data != nil {
-				refEnvCm = append(refEnvCm, refCmData)
-			}
+// cloneEnvCm copies the env level config maps of ref app on ref env to the app on env, returns names of the copied
+// config maps. rewrite, when given, is applied on data of every config map
+func (impl *AppCloneServiceImpl) cloneEnvCm(refAppId, refEnvId, appId, envId int, userId int32, rewrite func(data json.RawMessage) json.RawMessage, dryRun bool) ([]string, error) {
+	refCm, err := impl.configMapService.CMEnvironmentFetch(refAppId, refEnvId)
+	if err != nil {
+		return nil, err
+	}
+	var refEnvCm []*pipeline.ConfigData
+	for _, refCmData := range refCm.ConfigData {
+		if !refCmData.Global || refCmData.Data != nil {
+			refEnvCm = append(refEnvCm, refCmData)
 		}
-		if len(refEnvCm) == 0 {
-			impl.logger.Debug("no env cm")
-			continue
-		}
-		cfgDatas := impl.configDataClone(refEnvCm)
-		for _, cfgData := range cfgDatas {
-			newCm := &pipeline.ConfigDataRequest{
-				AppId:         newAppId,
-				EnvironmentId: refEnv.EnvironmentId,
-				ConfigData:    []*pipeline.ConfigData{cfgData},
-				UserId:        userId,
-				Id:            thisCm.Id,
-			}
-			thisCm, err = impl.configMapService.CMEnvironmentAddUpdate(newCm)
-			if err != nil {
-				return nil, err
-			}
+	}
+	if len(refEnvCm) == 0 {
+		impl.logger.Debug("no env cm")
+		return nil, nil
+	}
+	var names []string
+	cfgDatas := impl.configDataClone(refEnvCm)
+	for _, cfgData := range cfgDatas {
+		names = append(names, cfgData.Name)
+		if rewrite != nil && cfgData.Data != nil {
+			cfgData.Data = rewrite(cfgData.Data)
 		}
 	}
-	return nil, nil
+	if dryRun {
+		return names, nil
+	}
+	thisCm, err := impl.configMapService.CMEnvironmentFetch(appId, envId)
+	if err != nil {
+		return nil, err
+	}
+	for _, cfgData := range cfgDatas {
+		newCm := &pipeline.ConfigDataRequest{
+			AppId:         appId,
+			EnvironmentId: envId,
+			ConfigData:    []*pipeline.ConfigData{cfgData},
+			UserId:        userId,
+			Id:            thisCm.Id,
+		}
+		thisCm, err = impl.configMapService.CMEnvironmentAddUpdate(newCm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
 }
 
 func (impl *AppCloneServiceImpl) CreateEnvSecret(ctx context.Context, oldAppId, newAppId int, userId int32) (interface{}, error) {
@@ -401,43 +436,75 @@ func (impl *AppCloneServiceImpl) CreateEnvSecret(ctx context.Context, oldAppId,
 	}
 	for _, refEnv := range refEnvs {
 		impl.logger.Debugw("cloning cfg for env", "env", refEnv)
-		refCm, err := impl.configMapService.CSEnvironmentFetch(oldAppId, refEnv.EnvironmentId)
-		if err != nil {
-			return nil, err
-		}
-		thisCm, err := impl.configMapService.CSEnvironmentFetch(newAppId, refEnv.EnvironmentId)
+		_, err = impl.cloneEnvSecret(oldAppId, refEnv.EnvironmentId, newAppId, refEnv.EnvironmentId, userId, nil, false)
 		if err != nil {
 			return nil, err
 		}
+	}
+	return nil, nil
+}
 
-		var refEnvCm []*pipeline.ConfigData
-		for _, refCmData := range refCm.ConfigData {
-			if !refCmData.Global || refCmData.Data != nil {
-				refEnvCm = append(refEnvCm, refCmData)
-			}
+// cloneEnvSecret copies the env level secrets of ref app on ref env to the app on env, returns names of the copied
+// secrets. rewrite, when given, is applied on data of every secret
+func (impl *AppCloneServiceImpl) cloneEnvSecret(refAppId, refEnvId, appId, envId int, userId int32, rewrite func(data json.RawMessage) json.RawMessage, dryRun bool) ([]string, error) {
+	refCm, err := impl.configMapService.CSEnvironmentFetch(refAppId, refEnvId)
+	if err != nil {
+		return nil, err
+	}
+	var refEnvCm []*pipeline.ConfigData
+	for _, refCmData := range refCm.ConfigData {
+		//external secrets overriding a global secret carry no data, only their external secret references
+		if !refCmData.Global || refCmData.Data != nil || refCmData.ExternalSecret != nil || refCmData.ESOSecretData.EsoData != nil {
+			refEnvCm = append(refEnvCm, refCmData)
 		}
-		if len(refEnvCm) == 0 {
-			impl.logger.Debug("no env cm")
-			continue
-		}
-		cfgDatas := impl.configDataClone(refEnvCm)
-		for _, cfgData := range cfgDatas {
-			var configData []*pipeline.ConfigData
-			configData = append(configData, cfgData)
-			newCm := &pipeline.ConfigDataRequest{
-				AppId:         newAppId,
-				EnvironmentId: refEnv.EnvironmentId,
-				ConfigData:    configData,
-				UserId:        userId,
-				Id:            thisCm.Id,
-			}
-			thisCm, err = impl.configMapService.CSEnvironmentAddUpdate(newCm)
+	}
+	if len(refEnvCm) == 0 {
+		impl.logger.Debug("no env cm")
+		return nil, nil
+	}
+	var names []string
+	cfgDatas := impl.configDataClone(refEnvCm)
+	for _, cfgData := range cfgDatas {
+		names = append(names, cfgData.Name)
+		if cfgData.Data != nil && !dryRun {
+			//values are removed by fetch, taking them from the ref env override
+			refCs, err := impl.configMapService.CSEnvironmentFetchForEdit(cfgData.Name, refCm.Id, refAppId, refEnvId)
 			if err != nil {
+				impl.logger.Errorw("error in fetching secret for edit", "appId", refAppId, "envId", refEnvId, "name", cfgData.Name, "err", err)
 				return nil, err
 			}
+			if len(refCs.ConfigData) == 0 {
+				return nil, fmt.Errorf("secret %s not found on ref env", cfgData.Name)
+			}
+			cfgData.Data = refCs.ConfigData[0].Data
+		}
+		if rewrite != nil && cfgData.Data != nil {
+			cfgData.Data = rewrite(cfgData.Data)
 		}
 	}
-	return nil, nil
+	if dryRun {
+		return names, nil
+	}
+	thisCm, err := impl.configMapService.CSEnvironmentFetch(appId, envId)
+	if err != nil {
+		return nil, err
+	}
+	for _, cfgData := range cfgDatas {
+		var configData []*pipeline.ConfigData
+		configData = append(configData, cfgData)
+		newCm := &pipeline.ConfigDataRequest{
+			AppId:         appId,
+			EnvironmentId: envId,
+			ConfigData:    configData,
+			UserId:        userId,
+			Id:            thisCm.Id,
+		}
+		thisCm, err = impl.configMapService.CSEnvironmentAddUpdate(newCm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
 }
 
 func (impl *AppCloneServiceImpl) createEnvOverride(oldAppId, newAppId int, userId int32, ctx context.Context) (interface{}, error) {
@@ -446,69 +513,97 @@ func (impl *AppCloneServiceImpl) createEnvOverride(oldAppId, newAppId int, userI
 		return nil, err
 	}
 	for _, refEnv := range refEnvs {
-
-		chartRefRes, err := impl.chartService.ChartRefAutocompleteForAppOrEnv(oldAppId, refEnv.EnvironmentId)
-		if err != nil {
+		isOverride, err := impl.cloneEnvOverride(ctx, oldAppId, refEnv.EnvironmentId, newAppId, refEnv.EnvironmentId, userId, nil, false)
+		if err != nil && !isOverride {
 			return nil, err
+		} else if err != nil {
+			//failure in creating the override does not fail the app clone, the app is cloned without it
+			impl.logger.Errorw("error in cloning env override, skipping", "oldAppId", oldAppId, "newAppId", newAppId, "envId", refEnv.EnvironmentId, "err", err)
 		}
-		refEnvProperties, err := impl.propertiesConfigService.GetEnvironmentProperties(oldAppId, refEnv.EnvironmentId, chartRefRes.LatestEnvChartRef)
-		if err != nil {
-			return nil, err
-		}
-		if !refEnvProperties.IsOverride {
-			impl.logger.Debugw("no env override", "env", refEnv.EnvironmentId)
-			continue
-		}
-		thisEnvProperties, err := impl.propertiesConfigService.GetEnvironmentProperties(newAppId, refEnv.EnvironmentId, chartRefRes.LatestEnvChartRef)
-		if err != nil {
-			return nil, err
-		}
-		envPropertiesReq := &pipeline.EnvironmentProperties{
-			Id:                thisEnvProperties.EnvironmentConfig.Id,
-			EnvOverrideValues: refEnvProperties.EnvironmentConfig.EnvOverrideValues,
-			Status:            refEnvProperties.EnvironmentConfig.Status,
-			ManualReviewed:    refEnvProperties.EnvironmentConfig.ManualReviewed,
-			Active:            refEnvProperties.EnvironmentConfig.Active,
-			Namespace:         refEnvProperties.EnvironmentConfig.Namespace,
-			EnvironmentId:     refEnvProperties.EnvironmentConfig.EnvironmentId,
-			EnvironmentName:   refEnvProperties.EnvironmentConfig.EnvironmentName,
-			Latest:            refEnvProperties.EnvironmentConfig.Latest,
+	}
+	return nil, nil
+}
+
+// cloneEnvOverride copies the deployment template override of ref app on ref env to the app on env, returns whether ref
+// env has an override, errors returned along with true are of creating the override. rewrite, when given, is applied on
+// the override values
+func (impl *AppCloneServiceImpl) cloneEnvOverride(ctx context.Context, refAppId, refEnvId, appId, envId int, userId int32, rewrite func(data json.RawMessage) json.RawMessage, dryRun bool) (bool, error) {
+	chartRefRes, err := impl.chartService.ChartRefAutocompleteForAppOrEnv(refAppId, refEnvId)
+	if err != nil {
+		return false, err
+	}
+	refEnvProperties, err := impl.propertiesConfigService.GetEnvironmentProperties(refAppId, refEnvId, chartRefRes.LatestEnvChartRef)
+	if err != nil {
+		return false, err
+	}
+	if !refEnvProperties.IsOverride {
+		impl.logger.Debugw("no env override", "env", refEnvId)
+		return false, nil
+	}
+	if dryRun {
+		return true, nil
+	}
+	thisEnvProperties, err := impl.propertiesConfigService.GetEnvironmentProperties(appId, envId, chartRefRes.LatestEnvChartRef)
+	if err != nil {
+		return false, err
+	}
+	envOverrideValues := refEnvProperties.EnvironmentConfig.EnvOverrideValues
+	if rewrite != nil {
+		envOverrideValues = rewrite(envOverrideValues)
+	}
+	namespace := refEnvProperties.EnvironmentConfig.Namespace
+	environmentName := refEnvProperties.EnvironmentConfig.EnvironmentName
+	if refEnvId != envId {
+		namespace = thisEnvProperties.Namespace
+		environmentName = thisEnvProperties.EnvironmentConfig.EnvironmentName
+	}
+	envPropertiesReq := &pipeline.EnvironmentProperties{
+		Id:                thisEnvProperties.EnvironmentConfig.Id,
+		EnvOverrideValues: envOverrideValues,
+		Status:            refEnvProperties.EnvironmentConfig.Status,
+		ManualReviewed:    refEnvProperties.EnvironmentConfig.ManualReviewed,
+		Active:            refEnvProperties.EnvironmentConfig.Active,
+		Namespace:         namespace,
+		EnvironmentId:     envId,
+		EnvironmentName:   environmentName,
+		Latest:            refEnvProperties.EnvironmentConfig.Latest,
+		UserId:            userId,
+		AppMetrics:        refEnvProperties.EnvironmentConfig.AppMetrics,
+		ChartRefId:        refEnvProperties.EnvironmentConfig.ChartRefId,
+		IsOverride:        refEnvProperties.EnvironmentConfig.IsOverride,
+		IsBasicViewLocked: refEnvProperties.EnvironmentConfig.IsBasicViewLocked,
+		CurrentViewEditor: refEnvProperties.EnvironmentConfig.CurrentViewEditor,
+	}
+	createResp, err := impl.propertiesConfigService.CreateEnvironmentProperties(appId, envPropertiesReq)
+	if err != nil && err.Error() == bean2.NOCHARTEXIST {
+		templateRequest := chart.TemplateRequest{
+			AppId:             appId,
+			ChartRefId:        envPropertiesReq.ChartRefId,
+			ValuesOverride:    []byte("{}"),
 			UserId:            userId,
-			AppMetrics:        refEnvProperties.EnvironmentConfig.AppMetrics,
-			ChartRefId:        refEnvProperties.EnvironmentConfig.ChartRefId,
-			IsOverride:        refEnvProperties.EnvironmentConfig.IsOverride,
-			IsBasicViewLocked: refEnvProperties.EnvironmentConfig.IsBasicViewLocked,
-			CurrentViewEditor: refEnvProperties.EnvironmentConfig.CurrentViewEditor,
+			IsBasicViewLocked: envPropertiesReq.IsBasicViewLocked,
+			CurrentViewEditor: envPropertiesReq.CurrentViewEditor,
 		}
-		createResp, err := impl.propertiesConfigService.CreateEnvironmentProperties(newAppId, envPropertiesReq)
+		_, err = impl.chartService.CreateChartFromEnvOverride(templateRequest, ctx)
 		if err != nil {
-			if err.Error() == bean2.NOCHARTEXIST {
-				templateRequest := chart.TemplateRequest{
-					AppId:             newAppId,
-					ChartRefId:        envPropertiesReq.ChartRefId,
-					ValuesOverride:    []byte("{}"),
-					UserId:            userId,
-					IsBasicViewLocked: envPropertiesReq.IsBasicViewLocked,
-					CurrentViewEditor: envPropertiesReq.CurrentViewEditor,
-				}
-				_, err = impl.chartService.CreateChartFromEnvOverride(templateRequest, ctx)
-				if err != nil {
-					impl.logger.Error(err)
-					return nil, nil
-				}
-				createResp, err = impl.propertiesConfigService.CreateEnvironmentProperties(newAppId, envPropertiesReq)
-
-			}
+			impl.logger.Errorw("error in creating chart from env override", "appId", appId, "envId", envId, "err", err)
+			return true, err
 		}
-		impl.logger.Debugw("env override create res", "createRes", createResp)
-		//create object
-		//save object
-
+		createResp, err = impl.propertiesConfigService.CreateEnvironmentProperties(appId, envPropertiesReq)
 	}
-	return nil, nil
+	if err != nil {
+		impl.logger.Errorw("error in creating env override", "appId", appId, "envId", envId, "err", err)
+		return true, err
+	}
+	impl.logger.Debugw("env override create res", "createRes", createResp)
+	return true, nil
 }
 
 func (impl *AppCloneServiceImpl) configDataClone(cfData []*pipeline.ConfigData) []*pipeline.ConfigData {
+	return cloneConfigData(cfData)
+}
+
+func cloneConfigData(cfData []*pipeline.ConfigData) []*pipeline.ConfigData {
 	var copiedData []*pipeline.ConfigData
 	for _, refdata := range cfData {
 		data := &pipeline.ConfigData{
@@ -521,6 +616,11 @@ func (impl *AppCloneServiceImpl) configDataClone(cfData []*pipeline.ConfigData)
 			DefaultMountPath:   refdata.DefaultMountPath,
 			Global:             refdata.Global,
 			ExternalSecretType: refdata.ExternalSecretType,
+			ExternalSecret:     refdata.ExternalSecret,
+			ESOSecretData:      refdata.ESOSecretData,
+			RoleARN:            refdata.RoleARN,
+			SubPath:            refdata.SubPath,
+			FilePermission:     refdata.FilePermission,
 		}
 		copiedData = append(copiedData, data)
 	}
@@ -0,0 +1,997 @@
+package appClone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	appWorkflow2 "github.com/devtron-labs/devtron/internal/sql/repository/appWorkflow"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/app"
+	"github.com/devtron-labs/devtron/pkg/appClone/repository"
+	"github.com/devtron-labs/devtron/pkg/appWorkflow"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/devtron-labs/devtron/pkg/chart"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	bean3 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	APP_TEMPLATE_PARAMETER_STRING  = "string"
+	APP_TEMPLATE_PARAMETER_NUMBER  = "number"
+	APP_TEMPLATE_PARAMETER_BOOLEAN = "boolean"
+)
+
+const templateParameterNamePattern = "[A-Za-z][A-Za-z0-9_]*"
+
+// template parameters are referenced as #{{NAME}}, distinct from @{{NAME}} of scoped variables so that templates can
+// carry variable references which are resolved on deployment of the created app
+var templateParameterReferenceRegex = regexp.MustCompile(`#\{\{\s*(` + templateParameterNamePattern + `)\s*\}\}`)
+var templateParameterNameRegex = regexp.MustCompile(`^` + templateParameterNamePattern + `$`)
+
+type AppTemplateParameter struct {
+	Name         string `json:"name" validate:"required"`
+	Description  string `json:"description,omitempty"`
+	Type         string `json:"type,omitempty" validate:"omitempty,oneof=string number boolean"`
+	DefaultValue string `json:"defaultValue,omitempty"`
+	Required     bool   `json:"required"`
+	// CaptureValue is used when a template is captured from an app, occurrences of it in string values of the
+	// captured config are replaced with a reference to the parameter
+	CaptureValue string `json:"captureValue,omitempty"`
+}
+
+// AppTemplateData is the config captured in a template version. Materials keep the ids of the app they were captured
+// from, git material ids in ci config and ci pipelines refer to them and are mapped to the materials of the created app
+type AppTemplateData struct {
+	Materials          []*bean.GitMaterial            `json:"materials"`
+	CiConfig           *AppTemplateCiConfig           `json:"ciConfig,omitempty"`
+	DeploymentTemplate *AppTemplateDeploymentTemplate `json:"deploymentTemplate,omitempty"`
+	ConfigMaps         []*pipeline.ConfigData         `json:"configMaps,omitempty"`
+	Secrets            []*pipeline.ConfigData         `json:"secrets,omitempty"`
+	Workflows          []*AppTemplateWorkflow         `json:"workflows,omitempty"`
+}
+
+type AppTemplateCiConfig struct {
+	DockerRegistry    string                   `json:"dockerRegistry"`
+	DockerRepository  string                   `json:"dockerRepository"`
+	CiBuildConfig     *bean3.CiBuildConfigBean `json:"ciBuildConfig"`
+	BeforeDockerBuild []*bean.Task             `json:"beforeDockerBuild,omitempty"`
+	AfterDockerBuild  []*bean.Task             `json:"afterDockerBuild,omitempty"`
+}
+
+type AppTemplateDeploymentTemplate struct {
+	ChartRefId        int                         `json:"chartRefId"`
+	ValuesOverride    json.RawMessage             `json:"valuesOverride"`
+	IsBasicViewLocked bool                        `json:"isBasicViewLocked"`
+	CurrentViewEditor models.ChartsViewEditorType `json:"currentViewEditor"`
+}
+
+type AppTemplateWorkflow struct {
+	Name        string                         `json:"name"`
+	CiPipeline  *bean.CiPipeline               `json:"ciPipeline"`
+	CdPipelines []*bean.CDPipelineConfigObject `json:"cdPipelines,omitempty"`
+}
+
+// AppTemplateDto creates a new version of the template on save, either captured from RefAppId or from TemplateData
+type AppTemplateDto struct {
+	Id            int                     `json:"id"`
+	Name          string                  `json:"name" validate:"required,max=250"`
+	Description   string                  `json:"description,omitempty"`
+	Version       int                     `json:"version"`
+	LatestVersion int                     `json:"latestVersion"`
+	RefAppId      int                     `json:"refAppId,omitempty"`
+	Parameters    []*AppTemplateParameter `json:"parameters,omitempty" validate:"dive"`
+	TemplateData  *AppTemplateData        `json:"templateData,omitempty"`
+	CreatedOn     time.Time               `json:"createdOn"`
+	UserId        int32                   `json:"-"`
+}
+
+type CreateAppFromTemplateRequest struct {
+	AppTemplateId   int               `json:"-"`
+	AppName         string            `json:"appName" validate:"name-component,max=100"`
+	TeamId          int               `json:"teamId" validate:"number,required"`
+	AppLabels       []*bean.Label     `json:"labels,omitempty" validate:"dive"`
+	Version         int               `json:"version,omitempty"` //latest version of template when not given
+	ParameterValues map[string]string `json:"parameterValues,omitempty"`
+	UserId          int32             `json:"-"`
+}
+
+type AppTemplateAppDto struct {
+	AppId           int               `json:"appId"`
+	AppTemplateId   int               `json:"appTemplateId"`
+	AppTemplateName string            `json:"appTemplateName,omitempty"`
+	Version         int               `json:"version"`
+	LatestVersion   int               `json:"latestVersion"`
+	Outdated        bool              `json:"outdated"`
+	ParameterValues map[string]string `json:"parameterValues,omitempty"`
+}
+
+type AppTemplateService interface {
+	SaveAppTemplate(request *AppTemplateDto) (*AppTemplateDto, error)
+	DeleteAppTemplate(id int, userId int32) error
+	// GetAppTemplate returns the given version of template with its data, latest version when version is 0
+	GetAppTemplate(id int, version int) (*AppTemplateDto, error)
+	GetAllAppTemplates() ([]*AppTemplateDto, error)
+	GetAppTemplateVersions(id int) ([]*AppTemplateDto, error)
+	CreateAppFromTemplate(ctx context.Context, request *CreateAppFromTemplateRequest) (*bean.CreateAppDTO, error)
+	// GetTemplateApps returns the apps created from template with the version they were created from
+	GetTemplateApps(id int) ([]*AppTemplateAppDto, error)
+	GetTemplateForApp(appId int) (*AppTemplateAppDto, error)
+}
+
+type AppTemplateServiceImpl struct {
+	logger                *zap.SugaredLogger
+	appTemplateRepository repository.AppTemplateRepository
+	pipelineBuilder       pipeline.PipelineBuilder
+	chartService          chart.ChartService
+	configMapService      pipeline.ConfigMapService
+	appWorkflowService    appWorkflow.AppWorkflowService
+	appListingService     app.AppListingService
+	pipelineStageService  pipeline.PipelineStageService
+	ciTemplateService     pipeline.CiTemplateService
+}
+
+func NewAppTemplateServiceImpl(logger *zap.SugaredLogger,
+	appTemplateRepository repository.AppTemplateRepository,
+	pipelineBuilder pipeline.PipelineBuilder,
+	chartService chart.ChartService,
+	configMapService pipeline.ConfigMapService,
+	appWorkflowService appWorkflow.AppWorkflowService,
+	appListingService app.AppListingService,
+	pipelineStageService pipeline.PipelineStageService,
+	ciTemplateService pipeline.CiTemplateService) *AppTemplateServiceImpl {
+	return &AppTemplateServiceImpl{
+		logger:                logger,
+		appTemplateRepository: appTemplateRepository,
+		pipelineBuilder:       pipelineBuilder,
+		chartService:          chartService,
+		configMapService:      configMapService,
+		appWorkflowService:    appWorkflowService,
+		appListingService:     appListingService,
+		pipelineStageService:  pipelineStageService,
+		ciTemplateService:     ciTemplateService,
+	}
+}
+
+func (impl AppTemplateServiceImpl) SaveAppTemplate(request *AppTemplateDto) (*AppTemplateDto, error) {
+	err := validateTemplateParameters(request.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	var templateData string
+	if request.RefAppId > 0 {
+		templateData, err = impl.captureTemplateData(request.RefAppId, request.Parameters)
+	} else if request.TemplateData != nil {
+		var data []byte
+		data, err = json.Marshal(request.TemplateData)
+		templateData = string(data)
+	} else {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: "either refAppId or templateData is required"}
+	}
+	if err != nil {
+		impl.logger.Errorw("error in building app template data", "err", err, "refAppId", request.RefAppId)
+		return nil, err
+	}
+	err = validateTemplateReferences(templateData, request.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	parameters, err := json.Marshal(request.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := impl.appTemplateRepository.FindByName(request.Name)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app template by name", "err", err, "name", request.Name)
+		return nil, err
+	}
+	if request.Id == 0 && model.Id > 0 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("app template %s already exists", request.Name)}
+	}
+	if request.Id > 0 {
+		model, err = impl.appTemplateRepository.FindById(request.Id)
+		if err == pg.ErrNoRows {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app template %d not found", request.Id)}
+		} else if err != nil {
+			impl.logger.Errorw("error in getting app template", "err", err, "id", request.Id)
+			return nil, err
+		}
+		if model.Name != request.Name {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: "name of app template can not be changed"}
+		}
+	}
+
+	tx, err := impl.appTemplateRepository.GetConnection().Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	if request.Id == 0 {
+		model = &repository.AppTemplate{
+			Name:          request.Name,
+			Description:   request.Description,
+			LatestVersion: 1,
+			Active:        true,
+			AuditLog:      sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.UserId, UpdatedOn: time.Now(), UpdatedBy: request.UserId},
+		}
+		err = impl.appTemplateRepository.Save(model, tx)
+	} else {
+		model.Description = request.Description
+		model.LatestVersion = model.LatestVersion + 1
+		model.UpdatedOn = time.Now()
+		model.UpdatedBy = request.UserId
+		err = impl.appTemplateRepository.Update(model, tx)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving app template", "err", err, "name", request.Name)
+		return nil, err
+	}
+	version := &repository.AppTemplateVersion{
+		AppTemplateId: model.Id,
+		Version:       model.LatestVersion,
+		Parameters:    string(parameters),
+		TemplateData:  templateData,
+		RefAppId:      request.RefAppId,
+		AuditLog:      sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.UserId, UpdatedOn: time.Now(), UpdatedBy: request.UserId},
+	}
+	err = impl.appTemplateRepository.SaveVersion(version, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving app template version", "err", err, "appTemplateId", model.Id)
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return impl.GetAppTemplate(model.Id, model.LatestVersion)
+}
+
+func validateTemplateParameters(parameters []*AppTemplateParameter) error {
+	names := make(map[string]bool)
+	for _, parameter := range parameters {
+		if !templateParameterNameRegex.MatchString(parameter.Name) {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("invalid parameter name %s", parameter.Name)}
+		}
+		if names[parameter.Name] {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("parameter %s is declared more than once", parameter.Name)}
+		}
+		names[parameter.Name] = true
+		if len(parameter.DefaultValue) > 0 {
+			if err := validateParameterValue(parameter, parameter.DefaultValue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateParameterValue(parameter *AppTemplateParameter, value string) error {
+	var err error
+	switch parameter.Type {
+	case APP_TEMPLATE_PARAMETER_NUMBER:
+		_, err = strconv.ParseFloat(value, 64)
+	case APP_TEMPLATE_PARAMETER_BOOLEAN:
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("value %q of parameter %s is not a %s", value, parameter.Name, parameter.Type)}
+	}
+	return nil
+}
+
+// validateTemplateReferences fails on references to parameters which are not declared
+func validateTemplateReferences(templateData string, parameters []*AppTemplateParameter) error {
+	declared := make(map[string]bool)
+	for _, parameter := range parameters {
+		declared[parameter.Name] = true
+	}
+	for _, match := range templateParameterReferenceRegex.FindAllStringSubmatch(templateData, -1) {
+		if !declared[match[1]] {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("parameter %s is referenced but not declared", match[1])}
+		}
+	}
+	return nil
+}
+
+// captureTemplateData reads the config of ref app the same way app clone does. Capture values of parameters found in
+// string values are replaced with references to the parameters
+func (impl AppTemplateServiceImpl) captureTemplateData(refAppId int, parameters []*AppTemplateParameter) (string, error) {
+	appStatus, err := impl.appListingService.FetchAppStageStatus(refAppId)
+	if err != nil {
+		return "", err
+	}
+	refAppStatus := make(map[string]bool)
+	for _, as := range appStatus {
+		refAppStatus[as.StageName] = as.Status
+	}
+	if !refAppStatus["APP"] {
+		return "", &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app %d not found", refAppId)}
+	}
+	refApp, err := impl.pipelineBuilder.GetApp(refAppId)
+	if err != nil {
+		return "", err
+	}
+	templateData := &AppTemplateData{}
+	for _, material := range refApp.Material {
+		templateData.Materials = append(templateData.Materials, &bean.GitMaterial{
+			Name:            material.Name,
+			Url:             material.Url,
+			Id:              material.Id,
+			GitProviderId:   material.GitProviderId,
+			CheckoutPath:    material.CheckoutPath,
+			FetchSubmodules: material.FetchSubmodules,
+		})
+	}
+	if refAppStatus["MATERIAL"] && refAppStatus["TEMPLATE"] {
+		refCiConf, err := impl.pipelineBuilder.GetCiPipeline(refAppId)
+		if err != nil {
+			return "", err
+		}
+		templateData.CiConfig = &AppTemplateCiConfig{
+			DockerRegistry:    refCiConf.DockerRegistry,
+			DockerRepository:  refCiConf.DockerRepository,
+			CiBuildConfig:     refCiConf.CiBuildConfig,
+			BeforeDockerBuild: refCiConf.BeforeDockerBuild,
+			AfterDockerBuild:  refCiConf.AfterDockerBuild,
+		}
+		if templateData.CiConfig.CiBuildConfig != nil {
+			templateData.CiConfig.CiBuildConfig.Id = 0
+		}
+	}
+	if refAppStatus["CHART"] {
+		refTemplate, err := impl.chartService.FindLatestChartForAppByAppId(refAppId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching ref app chart ", "app", refAppId, "err", err)
+			return "", err
+		}
+		templateData.DeploymentTemplate = &AppTemplateDeploymentTemplate{
+			ChartRefId:        refTemplate.ChartRefId,
+			ValuesOverride:    refTemplate.DefaultAppOverride,
+			IsBasicViewLocked: refTemplate.IsBasicViewLocked,
+			CurrentViewEditor: refTemplate.CurrentViewEditor,
+		}
+		refCm, err := impl.configMapService.CMGlobalFetch(refAppId)
+		if err != nil {
+			return "", err
+		}
+		templateData.ConfigMaps = cloneConfigData(refCm.ConfigData)
+		refCs, err := impl.configMapService.CSGlobalFetch(refAppId)
+		if err != nil {
+			return "", err
+		}
+		templateData.Secrets = cloneConfigData(refCs.ConfigData)
+	}
+	if refAppStatus["CI_PIPELINE"] {
+		templateData.Workflows, err = impl.captureWorkflows(refAppId, refApp.AppName)
+		if err != nil {
+			return "", err
+		}
+	}
+	data, err := json.Marshal(templateData)
+	if err != nil {
+		return "", err
+	}
+	return replaceCaptureValues(string(data), parameters), nil
+}
+
+func replaceCaptureValues(templateData string, parameters []*AppTemplateParameter) string {
+	// longer values first, so that a value contained in another is not replaced inside it
+	var capturing []*AppTemplateParameter
+	for _, parameter := range parameters {
+		if len(parameter.CaptureValue) > 0 {
+			capturing = append(capturing, parameter)
+		}
+	}
+	sort.SliceStable(capturing, func(i, j int) bool {
+		return len(capturing[i].CaptureValue) > len(capturing[j].CaptureValue)
+	})
+	for _, parameter := range capturing {
+		templateData = strings.ReplaceAll(templateData, escapeJsonString(parameter.CaptureValue), "#{{"+parameter.Name+"}}")
+	}
+	return templateData
+}
+
+func escapeJsonString(value string) string {
+	escaped, _ := json.Marshal(value)
+	return string(escaped[1 : len(escaped)-1])
+}
+
+func (impl AppTemplateServiceImpl) captureWorkflows(refAppId int, refAppName string) ([]*AppTemplateWorkflow, error) {
+	refAppWFs, err := impl.appWorkflowService.FindAppWorkflows(refAppId)
+	if err != nil {
+		return nil, err
+	}
+	refCiConfig, err := impl.pipelineBuilder.GetCiPipeline(refAppId)
+	if err != nil {
+		return nil, err
+	}
+	refCdPipelines, err := impl.pipelineBuilder.GetCdPipelinesForApp(refAppId)
+	if err != nil && !util.IsErrNoRows(err) {
+		return nil, err
+	}
+	var workflows []*AppTemplateWorkflow
+	for _, refAppWF := range refAppWFs {
+		workflow := &AppTemplateWorkflow{Name: refAppWF.Name}
+		supported := true
+		for _, mapping := range refAppWF.AppWorkflowMappingDto {
+			switch mapping.Type {
+			case appWorkflow2.CIPIPELINE:
+				if workflow.CiPipeline != nil {
+					supported = false
+					break
+				}
+				workflow.CiPipeline, err = impl.captureCiPipeline(refCiConfig, mapping.ComponentId, refAppName)
+				if err != nil {
+					return nil, err
+				}
+				if workflow.CiPipeline == nil {
+					supported = false
+				}
+			case appWorkflow2.CDPIPELINE:
+				if refCdPipelines == nil {
+					continue
+				}
+				for _, refCdPipeline := range refCdPipelines.Pipelines {
+					if refCdPipeline.Id == mapping.ComponentId {
+						workflow.CdPipelines = append(workflow.CdPipelines, captureCdPipeline(refCdPipeline, refAppName))
+					}
+				}
+			default:
+				supported = false
+			}
+		}
+		if !supported || workflow.CiPipeline == nil {
+			//same as app clone, external ci, linked ci and multiple ci in a workflow are not captured
+			impl.logger.Warnw("skipping unsupported workflow in app template", "appId", refAppId, "workflow", refAppWF.Name)
+			continue
+		}
+		workflows = append(workflows, workflow)
+	}
+	return workflows, nil
+}
+
+func (impl AppTemplateServiceImpl) captureCiPipeline(refCiConfig *bean.CiConfigRequest, ciPipelineId int, refAppName string) (*bean.CiPipeline, error) {
+	for _, refCiPipeline := range refCiConfig.CiPipelines {
+		if refCiPipeline.Id != ciPipelineId {
+			continue
+		}
+		if refCiPipeline.IsExternal || refCiPipeline.ParentCiPipeline != 0 {
+			return nil, nil
+		}
+		pipelineName := refCiPipeline.Name
+		if strings.HasPrefix(pipelineName, refAppName) {
+			pipelineName = strings.Replace(pipelineName, refAppName+"-ci-", "", 1)
+		}
+		var ciMaterials []*bean.CiMaterial
+		for _, refCiMaterial := range refCiPipeline.CiMaterial {
+			ciMaterials = append(ciMaterials, &bean.CiMaterial{
+				GitMaterialId: refCiMaterial.GitMaterialId,
+				Source: &bean.SourceTypeConfig{
+					Type:  refCiMaterial.Source.Type,
+					Value: refCiMaterial.Source.Value,
+					Regex: refCiMaterial.Source.Regex,
+				},
+			})
+		}
+		preStageDetail, postStageDetail, err := impl.pipelineStageService.GetCiPipelineStageDataDeepCopy(refCiPipeline.Id)
+		if err != nil {
+			impl.logger.Errorw("error in getting pre & post stage detail by ciPipelineId", "err", err, "ciPipelineId", refCiPipeline.Id)
+			return nil, err
+		}
+		ciPipeline := &bean.CiPipeline{
+			IsManual:                 refCiPipeline.IsManual,
+			DockerArgs:               refCiPipeline.DockerArgs,
+			CiMaterial:               ciMaterials,
+			Name:                     pipelineName,
+			Active:                   refCiPipeline.Active,
+			BeforeDockerBuild:        refCiPipeline.BeforeDockerBuild,
+			AfterDockerBuild:         refCiPipeline.AfterDockerBuild,
+			IsDockerConfigOverridden: refCiPipeline.IsDockerConfigOverridden,
+			PreBuildStage:            preStageDetail,
+			PostBuildStage:           postStageDetail,
+		}
+		for _, script := range refCiPipeline.BeforeDockerBuildScripts {
+			ciPipeline.BeforeDockerBuildScripts = append(ciPipeline.BeforeDockerBuildScripts, &bean.CiScript{Index: script.Index, Name: script.Name, Script: script.Script, OutputLocation: script.OutputLocation})
+		}
+		for _, script := range refCiPipeline.AfterDockerBuildScripts {
+			ciPipeline.AfterDockerBuildScripts = append(ciPipeline.AfterDockerBuildScripts, &bean.CiScript{Index: script.Index, Name: script.Name, Script: script.Script, OutputLocation: script.OutputLocation})
+		}
+		if refCiPipeline.IsDockerConfigOverridden {
+			templateOverrideBean, err := impl.ciTemplateService.FindTemplateOverrideByCiPipelineId(refCiPipeline.Id)
+			if err != nil {
+				return nil, err
+			}
+			ciBuildConfig := templateOverrideBean.CiBuildConfig
+			ciBuildConfig.Id = 0
+			ciBuildConfig.GitMaterialId = templateOverrideBean.CiTemplateOverride.GitMaterialId
+			ciPipeline.DockerConfigOverride = bean.DockerConfigOverride{
+				DockerRegistry:   templateOverrideBean.CiTemplateOverride.DockerRegistryId,
+				DockerRepository: templateOverrideBean.CiTemplateOverride.DockerRepository,
+				CiBuildConfig:    ciBuildConfig,
+			}
+		}
+		return ciPipeline, nil
+	}
+	return nil, fmt.Errorf("ci pipeline %d not found", ciPipelineId)
+}
+
+func captureCdPipeline(refCdPipeline *bean.CDPipelineConfigObject, refAppName string) *bean.CDPipelineConfigObject {
+	pipelineName := refCdPipeline.Name
+	if strings.HasPrefix(pipelineName, refAppName) {
+		pipelineName = strings.Replace(pipelineName, refAppName+"-", "", 1)
+	}
+	return &bean.CDPipelineConfigObject{
+		EnvironmentId:                 refCdPipeline.EnvironmentId,
+		EnvironmentName:               refCdPipeline.EnvironmentName,
+		TriggerType:                   refCdPipeline.TriggerType,
+		Name:                          pipelineName,
+		Strategies:                    refCdPipeline.Strategies,
+		Namespace:                     refCdPipeline.Namespace,
+		DeploymentTemplate:            refCdPipeline.DeploymentTemplate,
+		PreStage:                      refCdPipeline.PreStage,
+		PostStage:                     refCdPipeline.PostStage,
+		PreStageConfigMapSecretNames:  refCdPipeline.PreStageConfigMapSecretNames,
+		PostStageConfigMapSecretNames: refCdPipeline.PostStageConfigMapSecretNames,
+		RunPreStageInEnv:              refCdPipeline.RunPreStageInEnv,
+		RunPostStageInEnv:             refCdPipeline.RunPostStageInEnv,
+		DeploymentAppType:             refCdPipeline.DeploymentAppType,
+	}
+}
+
+func (impl AppTemplateServiceImpl) DeleteAppTemplate(id int, userId int32) error {
+	model, err := impl.appTemplateRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app template %d not found", id)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app template", "err", err, "id", id)
+		return err
+	}
+	tx, err := impl.appTemplateRepository.GetConnection().Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	model.Active = false
+	model.UpdatedOn = time.Now()
+	model.UpdatedBy = userId
+	err = impl.appTemplateRepository.Update(model, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deleting app template", "err", err, "id", id)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (impl AppTemplateServiceImpl) GetAppTemplate(id int, version int) (*AppTemplateDto, error) {
+	model, err := impl.appTemplateRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app template %d not found", id)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app template", "err", err, "id", id)
+		return nil, err
+	}
+	if version == 0 {
+		version = model.LatestVersion
+	}
+	templateVersion, err := impl.appTemplateRepository.FindVersion(id, version)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("version %d of app template %d not found", version, id)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app template version", "err", err, "id", id, "version", version)
+		return nil, err
+	}
+	dto, err := toAppTemplateDto(model, templateVersion)
+	if err != nil {
+		return nil, err
+	}
+	dto.TemplateData = &AppTemplateData{}
+	err = json.Unmarshal([]byte(templateVersion.TemplateData), dto.TemplateData)
+	if err != nil {
+		impl.logger.Errorw("error in unmarshalling app template data", "err", err, "id", id, "version", version)
+		return nil, err
+	}
+	return dto, nil
+}
+
+func toAppTemplateDto(model *repository.AppTemplate, version *repository.AppTemplateVersion) (*AppTemplateDto, error) {
+	dto := &AppTemplateDto{
+		Id:            model.Id,
+		Name:          model.Name,
+		Description:   model.Description,
+		LatestVersion: model.LatestVersion,
+		Version:       model.LatestVersion,
+		CreatedOn:     model.CreatedOn,
+	}
+	if version != nil {
+		dto.Version = version.Version
+		dto.RefAppId = version.RefAppId
+		dto.CreatedOn = version.CreatedOn
+		if len(version.Parameters) > 0 {
+			err := json.Unmarshal([]byte(version.Parameters), &dto.Parameters)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return dto, nil
+}
+
+func (impl AppTemplateServiceImpl) GetAllAppTemplates() ([]*AppTemplateDto, error) {
+	models, err := impl.appTemplateRepository.FindAllActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app templates", "err", err)
+		return nil, err
+	}
+	dtos := make([]*AppTemplateDto, 0, len(models))
+	for _, model := range models {
+		dto, _ := toAppTemplateDto(model, nil)
+		dtos = append(dtos, dto)
+	}
+	return dtos, nil
+}
+
+func (impl AppTemplateServiceImpl) GetAppTemplateVersions(id int) ([]*AppTemplateDto, error) {
+	model, err := impl.appTemplateRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app template %d not found", id)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app template", "err", err, "id", id)
+		return nil, err
+	}
+	versions, err := impl.appTemplateRepository.FindVersions(id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app template versions", "err", err, "id", id)
+		return nil, err
+	}
+	dtos := make([]*AppTemplateDto, 0, len(versions))
+	for _, version := range versions {
+		dto, err := toAppTemplateDto(model, version)
+		if err != nil {
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos, nil
+}
+
+// substituteTemplateParameters replaces parameter references with the given values or defaults. A reference making
+// up a whole json string is replaced with the raw value for number and boolean parameters
+func substituteTemplateParameters(templateData string, parameters []*AppTemplateParameter, values map[string]string) (string, map[string]string, error) {
+	declared := make(map[string]*AppTemplateParameter)
+	for _, parameter := range parameters {
+		declared[parameter.Name] = parameter
+	}
+	for name := range values {
+		if _, ok := declared[name]; !ok {
+			return "", nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("unknown parameter %s", name)}
+		}
+	}
+	resolved := make(map[string]string)
+	for _, parameter := range parameters {
+		value, ok := values[parameter.Name]
+		if !ok || len(value) == 0 {
+			value = parameter.DefaultValue
+		}
+		if len(value) == 0 && parameter.Required {
+			return "", nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("value of parameter %s is required", parameter.Name)}
+		}
+		if len(value) > 0 {
+			if err := validateParameterValue(parameter, value); err != nil {
+				return "", nil, err
+			}
+		}
+		resolved[parameter.Name] = value
+		if parameter.Type == APP_TEMPLATE_PARAMETER_NUMBER || parameter.Type == APP_TEMPLATE_PARAMETER_BOOLEAN {
+			if len(value) == 0 {
+				value = "null"
+			}
+			wholeValueRegex := regexp.MustCompile(`"#\{\{\s*` + parameter.Name + `\s*\}\}"`)
+			templateData = wholeValueRegex.ReplaceAllLiteralString(templateData, value)
+		}
+	}
+	var err error
+	templateData = templateParameterReferenceRegex.ReplaceAllStringFunc(templateData, func(reference string) string {
+		name := templateParameterReferenceRegex.FindStringSubmatch(reference)[1]
+		value, ok := resolved[name]
+		if !ok {
+			err = &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("parameter %s is referenced but not declared", name)}
+			return reference
+		}
+		return escapeJsonString(value)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return templateData, resolved, nil
+}
+
+func (impl AppTemplateServiceImpl) CreateAppFromTemplate(ctx context.Context, request *CreateAppFromTemplateRequest) (*bean.CreateAppDTO, error) {
+	model, err := impl.appTemplateRepository.FindById(request.AppTemplateId)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app template %d not found", request.AppTemplateId)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app template", "err", err, "id", request.AppTemplateId)
+		return nil, err
+	}
+	version := request.Version
+	if version == 0 {
+		version = model.LatestVersion
+	}
+	templateVersion, err := impl.appTemplateRepository.FindVersion(model.Id, version)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("version %d of app template %d not found", version, model.Id)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app template version", "err", err, "id", model.Id, "version", version)
+		return nil, err
+	}
+	var parameters []*AppTemplateParameter
+	if len(templateVersion.Parameters) > 0 {
+		err = json.Unmarshal([]byte(templateVersion.Parameters), &parameters)
+		if err != nil {
+			return nil, err
+		}
+	}
+	data, parameterValues, err := substituteTemplateParameters(templateVersion.TemplateData, parameters, request.ParameterValues)
+	if err != nil {
+		return nil, err
+	}
+	templateData := &AppTemplateData{}
+	err = json.Unmarshal([]byte(data), templateData)
+	if err != nil {
+		impl.logger.Errorw("error in unmarshalling app template data", "err", err, "id", model.Id, "version", version)
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: "invalid app template data after substituting parameters", InternalMessage: err.Error()}
+	}
+
+	createRes, err := impl.pipelineBuilder.CreateApp(&bean.CreateAppDTO{
+		AppName:   request.AppName,
+		UserId:    request.UserId,
+		TeamId:    request.TeamId,
+		AppLabels: request.AppLabels,
+	})
+	if err != nil {
+		impl.logger.Errorw("error in creating app from template", "err", err, "request", request)
+		return nil, err
+	}
+	err = impl.applyTemplateData(ctx, createRes.Id, templateData, request.UserId)
+	if err != nil {
+		impl.logger.Errorw("error in applying app template", "err", err, "appId", createRes.Id, "appTemplateId", model.Id)
+		return nil, err
+	}
+	values, err := json.Marshal(parameterValues)
+	if err != nil {
+		return nil, err
+	}
+	err = impl.appTemplateRepository.SaveTemplateApp(&repository.AppTemplateApp{
+		AppTemplateId:   model.Id,
+		AppId:           createRes.Id,
+		Version:         version,
+		ParameterValues: string(values),
+		AuditLog:        sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.UserId, UpdatedOn: time.Now(), UpdatedBy: request.UserId},
+	})
+	if err != nil {
+		impl.logger.Errorw("error in saving app template version of app", "err", err, "appId", createRes.Id)
+		return nil, err
+	}
+	return createRes, nil
+}
+
+// applyTemplateData creates config of the app in the same order as app clone
+func (impl AppTemplateServiceImpl) applyTemplateData(ctx context.Context, appId int, templateData *AppTemplateData, userId int32) error {
+	gitMaterialMapping := make(map[int]int)
+	for _, material := range templateData.Materials {
+		createMaterial := &bean.CreateMaterialDTO{
+			AppId:  appId,
+			UserId: userId,
+			Material: []*bean.GitMaterial{{
+				Name:            material.Name,
+				Url:             material.Url,
+				GitProviderId:   material.GitProviderId,
+				CheckoutPath:    material.CheckoutPath,
+				FetchSubmodules: material.FetchSubmodules,
+			}},
+		}
+		createMaterialRes, err := impl.pipelineBuilder.CreateMaterialsForApp(createMaterial)
+		if err != nil {
+			return err
+		}
+		gitMaterialMapping[material.Id] = createMaterialRes.Material[0].Id
+	}
+	if templateData.CiConfig != nil && len(templateData.Materials) > 0 {
+		ciBuildConfig := templateData.CiConfig.CiBuildConfig
+		if ciBuildConfig != nil {
+			ciBuildConfig.GitMaterialId = mapGitMaterialId(gitMaterialMapping, ciBuildConfig.GitMaterialId)
+		}
+		_, err := impl.pipelineBuilder.CreateCiPipeline(&bean.CiConfigRequest{
+			AppId:             appId,
+			DockerRegistry:    templateData.CiConfig.DockerRegistry,
+			DockerRepository:  templateData.CiConfig.DockerRepository,
+			CiBuildConfig:     ciBuildConfig,
+			DockerRegistryUrl: templateData.CiConfig.DockerRegistry,
+			UserId:            userId,
+			BeforeDockerBuild: templateData.CiConfig.BeforeDockerBuild,
+			AfterDockerBuild:  templateData.CiConfig.AfterDockerBuild,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if templateData.DeploymentTemplate == nil {
+		return nil
+	}
+	_, err := impl.chartService.Create(chart.TemplateRequest{
+		AppId:             appId,
+		Latest:            true,
+		ValuesOverride:    templateData.DeploymentTemplate.ValuesOverride,
+		ChartRefId:        templateData.DeploymentTemplate.ChartRefId,
+		UserId:            userId,
+		IsBasicViewLocked: templateData.DeploymentTemplate.IsBasicViewLocked,
+		CurrentViewEditor: templateData.DeploymentTemplate.CurrentViewEditor,
+	}, ctx)
+	if err != nil {
+		return err
+	}
+	thisCm, err := impl.configMapService.CMGlobalFetch(appId)
+	if err != nil {
+		return err
+	}
+	for _, cfgData := range templateData.ConfigMaps {
+		thisCm, err = impl.configMapService.CMGlobalAddUpdate(&pipeline.ConfigDataRequest{
+			AppId:      appId,
+			ConfigData: []*pipeline.ConfigData{cfgData},
+			UserId:     userId,
+			Id:         thisCm.Id,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, cfgData := range templateData.Secrets {
+		thisCm, err = impl.configMapService.CSGlobalAddUpdate(&pipeline.ConfigDataRequest{
+			AppId:      appId,
+			ConfigData: []*pipeline.ConfigData{cfgData},
+			UserId:     userId,
+			Id:         thisCm.Id,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, workflow := range templateData.Workflows {
+		err = impl.createTemplateWorkflow(ctx, appId, workflow, gitMaterialMapping, userId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (impl AppTemplateServiceImpl) createTemplateWorkflow(ctx context.Context, appId int, workflow *AppTemplateWorkflow, gitMaterialMapping map[int]int, userId int32) error {
+	thisWf, err := impl.appWorkflowService.CreateAppWorkflow(appWorkflow.AppWorkflowDto{
+		Name:   workflow.Name,
+		AppId:  appId,
+		UserId: userId,
+	})
+	if err != nil {
+		return err
+	}
+	ciPipeline := workflow.CiPipeline
+	for _, ciMaterial := range ciPipeline.CiMaterial {
+		ciMaterial.GitMaterialId = mapGitMaterialId(gitMaterialMapping, ciMaterial.GitMaterialId)
+	}
+	if ciPipeline.IsDockerConfigOverridden && ciPipeline.DockerConfigOverride.CiBuildConfig != nil {
+		ciBuildConfig := ciPipeline.DockerConfigOverride.CiBuildConfig
+		ciBuildConfig.GitMaterialId = mapGitMaterialId(gitMaterialMapping, ciBuildConfig.GitMaterialId)
+	}
+	ciConfig, err := impl.pipelineBuilder.PatchCiPipeline(&bean.CiPatchRequest{
+		CiPipeline:    ciPipeline,
+		AppId:         appId,
+		Action:        bean.CREATE,
+		AppWorkflowId: thisWf.Id,
+		UserId:        userId,
+	})
+	if err != nil {
+		return err
+	}
+	if len(workflow.CdPipelines) == 0 {
+		return nil
+	}
+	for _, cdPipeline := range workflow.CdPipelines {
+		cdPipeline.CiPipelineId = ciConfig.CiPipelines[0].Id
+		cdPipeline.AppWorkflowId = thisWf.Id
+		_, err = impl.pipelineBuilder.CreateCdPipelines(&bean.CdPipelines{
+			Pipelines: []*bean.CDPipelineConfigObject{cdPipeline},
+			AppId:     appId,
+			UserId:    userId,
+		}, ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapGitMaterialId maps a material id of template to the material created for the app, apps with single material
+// fall back to it when the id is not of a template material
+func mapGitMaterialId(gitMaterialMapping map[int]int, templateMaterialId int) int {
+	if id, ok := gitMaterialMapping[templateMaterialId]; ok {
+		return id
+	}
+	if len(gitMaterialMapping) == 1 {
+		for _, id := range gitMaterialMapping {
+			return id
+		}
+	}
+	return templateMaterialId
+}
+
+func (impl AppTemplateServiceImpl) GetTemplateApps(id int) ([]*AppTemplateAppDto, error) {
+	model, err := impl.appTemplateRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app template %d not found", id)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app template", "err", err, "id", id)
+		return nil, err
+	}
+	templateApps, err := impl.appTemplateRepository.FindTemplateAppsByTemplateId(id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting apps of app template", "err", err, "id", id)
+		return nil, err
+	}
+	dtos := make([]*AppTemplateAppDto, 0, len(templateApps))
+	for _, templateApp := range templateApps {
+		dto, err := toAppTemplateAppDto(model, templateApp)
+		if err != nil {
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos, nil
+}
+
+func (impl AppTemplateServiceImpl) GetTemplateForApp(appId int) (*AppTemplateAppDto, error) {
+	templateApp, err := impl.appTemplateRepository.FindTemplateAppByAppId(appId)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app %d is not created from a template", appId)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app template of app", "err", err, "appId", appId)
+		return nil, err
+	}
+	model, err := impl.appTemplateRepository.FindById(templateApp.AppTemplateId)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app template %d not found", templateApp.AppTemplateId)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app template", "err", err, "id", templateApp.AppTemplateId)
+		return nil, err
+	}
+	return toAppTemplateAppDto(model, templateApp)
+}
+
+func toAppTemplateAppDto(model *repository.AppTemplate, templateApp *repository.AppTemplateApp) (*AppTemplateAppDto, error) {
+	dto := &AppTemplateAppDto{
+		AppId:           templateApp.AppId,
+		AppTemplateId:   model.Id,
+		AppTemplateName: model.Name,
+		Version:         templateApp.Version,
+		LatestVersion:   model.LatestVersion,
+		Outdated:        templateApp.Version < model.LatestVersion,
+	}
+	if len(templateApp.ParameterValues) > 0 {
+		err := json.Unmarshal([]byte(templateApp.ParameterValues), &dto.ParameterValues)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dto, nil
+}
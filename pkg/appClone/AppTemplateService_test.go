@@ -0,0 +1,68 @@
+package appClone
+
+import (
+	"testing"
+)
+
+func TestReplaceCaptureValues(t *testing.T) {
+	parameters := []*AppTemplateParameter{
+		{Name: "REPO", CaptureValue: "https://github.com/org/payments"},
+		{Name: "SERVICE", CaptureValue: "payments"},
+	}
+	templateData := `{"materials":[{"url":"https://github.com/org/payments"}],"configMaps":[{"data":{"SERVICE_NAME":"payments"}}]}`
+	expected := `{"materials":[{"url":"#{{REPO}}"}],"configMaps":[{"data":{"SERVICE_NAME":"#{{SERVICE}}"}}]}`
+	if replaced := replaceCaptureValues(templateData, parameters); replaced != expected {
+		t.Errorf("unexpected template data %s", replaced)
+	}
+}
+
+func TestSubstituteTemplateParameters(t *testing.T) {
+	parameters := []*AppTemplateParameter{
+		{Name: "REPO", Required: true},
+		{Name: "PORT", Type: APP_TEMPLATE_PARAMETER_NUMBER, DefaultValue: "8080"},
+		{Name: "TEAM", DefaultValue: "core"},
+	}
+	templateData := `{"url":"#{{REPO}}","port":"#{{ PORT }}","name":"#{{TEAM}}-#{{PORT}}","vars":"@{{ENV}}"}`
+	_, _, err := substituteTemplateParameters(templateData, parameters, nil)
+	if err == nil {
+		t.Errorf("expected error for missing required parameter")
+	}
+	_, _, err = substituteTemplateParameters(templateData, parameters, map[string]string{"REPO": "x", "UNKNOWN": "y"})
+	if err == nil {
+		t.Errorf("expected error for unknown parameter")
+	}
+	_, _, err = substituteTemplateParameters(templateData, parameters, map[string]string{"REPO": "x", "PORT": "http"})
+	if err == nil {
+		t.Errorf("expected error for invalid number")
+	}
+	substituted, values, err := substituteTemplateParameters(templateData, parameters, map[string]string{"REPO": `https://github.com/org/"cart"`})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := `{"url":"https://github.com/org/\"cart\"","port":8080,"name":"core-8080","vars":"@{{ENV}}"}`
+	if substituted != expected {
+		t.Errorf("unexpected template data %s", substituted)
+	}
+	if values["PORT"] != "8080" || values["TEAM"] != "core" {
+		t.Errorf("unexpected resolved values %v", values)
+	}
+}
+
+func TestValidateTemplateReferences(t *testing.T) {
+	parameters := []*AppTemplateParameter{{Name: "REPO"}}
+	if err := validateTemplateReferences(`{"url":"#{{REPO}}"}`, parameters); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := validateTemplateReferences(`{"url":"#{{REPO}}","port":"#{{PORT}}"}`, parameters); err == nil {
+		t.Errorf("expected error for undeclared parameter")
+	}
+}
+
+func TestMapGitMaterialId(t *testing.T) {
+	if id := mapGitMaterialId(map[int]int{3: 10, 4: 11}, 4); id != 11 {
+		t.Errorf("unexpected id %d", id)
+	}
+	if id := mapGitMaterialId(map[int]int{3: 10}, 7); id != 10 {
+		t.Errorf("unexpected id %d", id)
+	}
+}
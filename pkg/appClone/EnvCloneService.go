@@ -0,0 +1,241 @@
+package appClone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"net/http"
+	"strings"
+)
+
+const (
+	ENV_CLONE_STATUS_PREVIEW = "PREVIEW"
+	ENV_CLONE_STATUS_SUCCESS = "SUCCESS"
+	ENV_CLONE_STATUS_FAILED  = "FAILED"
+	ENV_CLONE_STATUS_SKIPPED = "SKIPPED"
+)
+
+// EnvCloneRequest copies env overrides, env CM/CS and the cd pipeline of every app deployed on source env to target
+// env. AppIds limits the apps, all apps with a pipeline on source env are cloned when empty
+type EnvCloneRequest struct {
+	SourceEnvId int                        `json:"sourceEnvId" validate:"required"`
+	TargetEnvId int                        `json:"targetEnvId" validate:"required"`
+	AppIds      []int                      `json:"appIds,omitempty"`
+	Rewrites    []*EnvCloneRewrite         `json:"rewrites,omitempty" validate:"dive"`
+	DryRun      bool                       `json:"dryRun"`
+	TriggerType pipelineConfig.TriggerType `json:"triggerType,omitempty"` //source pipeline trigger type is kept when empty
+	UserId      int32                      `json:"-"`
+}
+
+// EnvCloneRewrite replaces every occurrence of Find in copied override values, CM/CS data and stage configs
+type EnvCloneRewrite struct {
+	Find    string `json:"find" validate:"required"`
+	Replace string `json:"replace"`
+}
+
+type EnvCloneResponse struct {
+	SourceEnvId int                  `json:"sourceEnvId"`
+	TargetEnvId int                  `json:"targetEnvId"`
+	DryRun      bool                 `json:"dryRun"`
+	Apps        []*EnvCloneAppResult `json:"apps"`
+}
+
+type EnvCloneAppResult struct {
+	AppId        int      `json:"appId"`
+	AppName      string   `json:"appName"`
+	Status       string   `json:"status"`
+	Message      string   `json:"message,omitempty"`
+	EnvOverride  bool     `json:"envOverride"`
+	ConfigMaps   []string `json:"configMaps,omitempty"`
+	Secrets      []string `json:"secrets,omitempty"`
+	PipelineId   int      `json:"pipelineId,omitempty"`
+	PipelineName string   `json:"pipelineName,omitempty"`
+	Namespace    string   `json:"namespace,omitempty"`
+}
+
+func (impl *AppCloneServiceImpl) CloneEnvironment(ctx context.Context, request *EnvCloneRequest, token string,
+	checkAuthForEnvClone func(token string, appObject string, sourceEnvObject string, targetEnvObject string) bool) (*EnvCloneResponse, error) {
+	if request.SourceEnvId == request.TargetEnvId {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: "source and target environment must be different"}
+	}
+	sourceEnv, err := impl.environmentRepository.FindById(request.SourceEnvId)
+	if err != nil {
+		impl.logger.Errorw("error in getting source env", "envId", request.SourceEnvId, "err", err)
+		return nil, err
+	}
+	targetEnv, err := impl.environmentRepository.FindById(request.TargetEnvId)
+	if err != nil {
+		impl.logger.Errorw("error in getting target env", "envId", request.TargetEnvId, "err", err)
+		return nil, err
+	}
+	var pipelines []*pipelineConfig.Pipeline
+	if len(request.AppIds) > 0 {
+		pipelines, err = impl.pipelineRepository.FindActiveByInFilter(request.SourceEnvId, request.AppIds)
+	} else {
+		pipelines, err = impl.pipelineRepository.FindActiveByEnvId(request.SourceEnvId)
+	}
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting pipelines of source env", "envId", request.SourceEnvId, "err", err)
+		return nil, err
+	}
+	rewrite := newEnvCloneRewrite(request.Rewrites)
+	response := &EnvCloneResponse{SourceEnvId: request.SourceEnvId, TargetEnvId: request.TargetEnvId, DryRun: request.DryRun, Apps: make([]*EnvCloneAppResult, 0)}
+	for _, sourcePipeline := range pipelines {
+		if !sourcePipeline.App.Active {
+			continue
+		}
+		result := &EnvCloneAppResult{
+			AppId:        sourcePipeline.AppId,
+			AppName:      sourcePipeline.App.AppName,
+			PipelineName: envClonePipelineName(sourcePipeline.Name, sourceEnv.Name, targetEnv.Name),
+			Namespace:    targetEnv.Namespace,
+		}
+		response.Apps = append(response.Apps, result)
+		appObject := impl.enforcerUtil.GetAppRBACNameByAppId(sourcePipeline.AppId)
+		sourceEnvObject := impl.enforcerUtil.GetEnvRBACNameByAppId(sourcePipeline.AppId, request.SourceEnvId)
+		targetEnvObject := impl.enforcerUtil.GetEnvRBACNameByAppId(sourcePipeline.AppId, request.TargetEnvId)
+		if !checkAuthForEnvClone(token, appObject, sourceEnvObject, targetEnvObject) {
+			result.Status = ENV_CLONE_STATUS_FAILED
+			result.Message = "unauthorized for app, source or target environment"
+			continue
+		}
+		existing, err := impl.pipelineRepository.FindActiveByAppIdAndEnvironmentId(sourcePipeline.AppId, request.TargetEnvId)
+		if err != nil && !util.IsErrNoRows(err) {
+			result.Status = ENV_CLONE_STATUS_FAILED
+			result.Message = err.Error()
+			continue
+		}
+		if len(existing) > 0 {
+			result.Status = ENV_CLONE_STATUS_SKIPPED
+			result.Message = "pipeline already exists on target environment"
+			continue
+		}
+		err = impl.cloneAppEnvironment(ctx, sourcePipeline, request, rewrite, result)
+		if err != nil {
+			impl.logger.Errorw("error in cloning environment of app", "appId", sourcePipeline.AppId, "sourceEnvId", request.SourceEnvId, "targetEnvId", request.TargetEnvId, "err", err)
+			result.Status = ENV_CLONE_STATUS_FAILED
+			result.Message = err.Error()
+			continue
+		}
+		if request.DryRun {
+			result.Status = ENV_CLONE_STATUS_PREVIEW
+		} else {
+			result.Status = ENV_CLONE_STATUS_SUCCESS
+		}
+	}
+	return response, nil
+}
+
+func (impl *AppCloneServiceImpl) cloneAppEnvironment(ctx context.Context, sourcePipeline *pipelineConfig.Pipeline, request *EnvCloneRequest,
+	rewrite func(data json.RawMessage) json.RawMessage, result *EnvCloneAppResult) error {
+	appId := sourcePipeline.AppId
+	var err error
+	result.EnvOverride, err = impl.cloneEnvOverride(ctx, appId, request.SourceEnvId, appId, request.TargetEnvId, request.UserId, rewrite, request.DryRun)
+	if err != nil {
+		return err
+	}
+	result.ConfigMaps, err = impl.cloneEnvCm(appId, request.SourceEnvId, appId, request.TargetEnvId, request.UserId, rewrite, request.DryRun)
+	if err != nil {
+		return err
+	}
+	result.Secrets, err = impl.cloneEnvSecret(appId, request.SourceEnvId, appId, request.TargetEnvId, request.UserId, rewrite, request.DryRun)
+	if err != nil {
+		return err
+	}
+	if request.DryRun {
+		return nil
+	}
+	refPipelines, err := impl.pipelineBuilder.GetCdPipelinesForApp(appId)
+	if err != nil {
+		return err
+	}
+	var refCdPipeline *bean.CDPipelineConfigObject
+	for _, refPipeline := range refPipelines.Pipelines {
+		if refPipeline.Id == sourcePipeline.Id {
+			refCdPipeline = refPipeline
+			break
+		}
+	}
+	if refCdPipeline == nil {
+		return fmt.Errorf("no cd pipeline found")
+	}
+	triggerType := refCdPipeline.TriggerType
+	if len(request.TriggerType) > 0 {
+		triggerType = request.TriggerType
+	}
+	preStage := refCdPipeline.PreStage
+	preStage.Config = rewriteString(preStage.Config, rewrite)
+	postStage := refCdPipeline.PostStage
+	postStage.Config = rewriteString(postStage.Config, rewrite)
+	//new pipeline is a sibling of source pipeline in its workflow
+	cdPipeline := &bean.CDPipelineConfigObject{
+		EnvironmentId:                 request.TargetEnvId,
+		CiPipelineId:                  refCdPipeline.CiPipelineId,
+		TriggerType:                   triggerType,
+		Name:                          result.PipelineName,
+		Strategies:                    refCdPipeline.Strategies,
+		Namespace:                     result.Namespace,
+		AppWorkflowId:                 refCdPipeline.AppWorkflowId,
+		DeploymentTemplate:            refCdPipeline.DeploymentTemplate,
+		PreStage:                      preStage,
+		PostStage:                     postStage,
+		PreStageConfigMapSecretNames:  refCdPipeline.PreStageConfigMapSecretNames,
+		PostStageConfigMapSecretNames: refCdPipeline.PostStageConfigMapSecretNames,
+		RunPostStageInEnv:             refCdPipeline.RunPostStageInEnv,
+		RunPreStageInEnv:              refCdPipeline.RunPreStageInEnv,
+		DeploymentAppType:             refCdPipeline.DeploymentAppType,
+		ParentPipelineId:              refCdPipeline.ParentPipelineId,
+		ParentPipelineType:            refCdPipeline.ParentPipelineType,
+	}
+	cdPipelineRes, err := impl.pipelineBuilder.CreateCdPipelines(&bean.CdPipelines{
+		Pipelines: []*bean.CDPipelineConfigObject{cdPipeline},
+		AppId:     appId,
+		UserId:    request.UserId,
+	}, ctx)
+	if err != nil {
+		return err
+	}
+	if len(cdPipelineRes.Pipelines) > 0 {
+		result.PipelineId = cdPipelineRes.Pipelines[0].Id
+	}
+	return nil
+}
+
+// envClonePipelineName replaces source env name in pipeline name with target env name, suffixes target env name
+// when pipeline name does not carry the source env name
+func envClonePipelineName(sourcePipelineName, sourceEnvName, targetEnvName string) string {
+	if strings.Contains(sourcePipelineName, sourceEnvName) {
+		return strings.Replace(sourcePipelineName, sourceEnvName, targetEnvName, 1)
+	}
+	return fmt.Sprintf("%s-%s", sourcePipelineName, targetEnvName)
+}
+
+// newEnvCloneRewrite returns a rewrite of json values applying the rewrites in order, nil when there are none
+func newEnvCloneRewrite(rewrites []*EnvCloneRewrite) func(data json.RawMessage) json.RawMessage {
+	if len(rewrites) == 0 {
+		return nil
+	}
+	return func(data json.RawMessage) json.RawMessage {
+		value := string(data)
+		for _, rewrite := range rewrites {
+			value = strings.ReplaceAll(value, escapeJsonString(rewrite.Find), escapeJsonString(rewrite.Replace))
+		}
+		return json.RawMessage(value)
+	}
+}
+
+func rewriteString(value string, rewrite func(data json.RawMessage) json.RawMessage) string {
+	if rewrite == nil || len(value) == 0 {
+		return value
+	}
+	//stage configs are plain yaml, rewriting them as a json string keeps the escaping consistent
+	quoted, _ := json.Marshal(value)
+	var rewritten string
+	if err := json.Unmarshal(rewrite(quoted), &rewritten); err != nil {
+		return value
+	}
+	return rewritten
+}
@@ -0,0 +1,98 @@
+package appClone
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"go.uber.org/zap"
+)
+
+func TestEnvClonePipelineName(t *testing.T) {
+	if name := envClonePipelineName("cd-payments-staging", "staging", "qa"); name != "cd-payments-qa" {
+		t.Errorf("unexpected pipeline name %s", name)
+	}
+	if name := envClonePipelineName("cd-payments", "staging", "qa"); name != "cd-payments-qa" {
+		t.Errorf("unexpected pipeline name %s", name)
+	}
+}
+
+func TestNewEnvCloneRewrite(t *testing.T) {
+	if rewrite := newEnvCloneRewrite(nil); rewrite != nil {
+		t.Errorf("expected nil rewrite")
+	}
+	rewrite := newEnvCloneRewrite([]*EnvCloneRewrite{
+		{Find: "staging.example.com", Replace: "qa.example.com"},
+		{Find: "db-staging", Replace: `db-"qa"`},
+	})
+	rewritten := rewrite(json.RawMessage(`{"HOST":"api.staging.example.com","DB":"db-staging"}`))
+	expected := `{"HOST":"api.qa.example.com","DB":"db-\"qa\""}`
+	if string(rewritten) != expected {
+		t.Errorf("unexpected rewritten data %s", rewritten)
+	}
+	if !json.Valid(rewritten) {
+		t.Errorf("rewritten data is not valid json")
+	}
+}
+
+func TestRewriteString(t *testing.T) {
+	rewrite := newEnvCloneRewrite([]*EnvCloneRewrite{{Find: "staging", Replace: "qa"}})
+	config := "version: 0.0.1\nenv: \"staging\"\n"
+	if rewritten := rewriteString(config, rewrite); rewritten != "version: 0.0.1\nenv: \"qa\"\n" {
+		t.Errorf("unexpected rewritten config %q", rewritten)
+	}
+	if rewritten := rewriteString(config, nil); rewritten != config {
+		t.Errorf("expected config unchanged without rewrite")
+	}
+}
+
+// fakeConfigMapService serves secrets of env 1 with values removed on fetch, like the config map service does
+type fakeConfigMapService struct {
+	pipeline.ConfigMapService
+	saved []*pipeline.ConfigDataRequest
+}
+
+func (impl *fakeConfigMapService) CSEnvironmentFetch(appId int, envId int) (*pipeline.ConfigDataRequest, error) {
+	if envId != 1 {
+		return &pipeline.ConfigDataRequest{AppId: appId, EnvironmentId: envId}, nil
+	}
+	return &pipeline.ConfigDataRequest{Id: 7, AppId: appId, EnvironmentId: envId, ConfigData: []*pipeline.ConfigData{
+		{Name: "db-creds", Type: "environment", Data: json.RawMessage(`{"password":""}`)},
+	}}, nil
+}
+
+func (impl *fakeConfigMapService) CSEnvironmentFetchForEdit(name string, id int, appId int, envId int) (*pipeline.ConfigDataRequest, error) {
+	if id != 7 || name != "db-creds" {
+		return &pipeline.ConfigDataRequest{}, nil
+	}
+	return &pipeline.ConfigDataRequest{Id: id, ConfigData: []*pipeline.ConfigData{
+		{Name: "db-creds", Type: "environment", Data: json.RawMessage(`{"password":"c2VjcmV0LWRldg=="}`)},
+	}}, nil
+}
+
+func (impl *fakeConfigMapService) CSEnvironmentAddUpdate(configMapRequest *pipeline.ConfigDataRequest) (*pipeline.ConfigDataRequest, error) {
+	impl.saved = append(impl.saved, configMapRequest)
+	return configMapRequest, nil
+}
+
+func TestCloneEnvSecretCopiesValues(t *testing.T) {
+	configMapService := &fakeConfigMapService{}
+	impl := &AppCloneServiceImpl{logger: zap.NewNop().Sugar(), configMapService: configMapService}
+	names, err := impl.cloneEnvSecret(1, 1, 1, 2, 1, nil, false)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if len(names) != 1 || len(configMapService.saved) != 1 {
+		t.Fatalf("expected one secret cloned, got %v", names)
+	}
+	saved := configMapService.saved[0]
+	if saved.EnvironmentId != 2 || string(saved.ConfigData[0].Data) != `{"password":"c2VjcmV0LWRldg=="}` {
+		t.Errorf("expected values of ref env secret on target env, got %s", saved.ConfigData[0].Data)
+	}
+
+	configMapService.saved = nil
+	names, err = impl.cloneEnvSecret(1, 1, 1, 2, 1, nil, true)
+	if err != nil || len(names) != 1 || len(configMapService.saved) != 0 {
+		t.Errorf("expected preview without saving, got %v %v", names, err)
+	}
+}
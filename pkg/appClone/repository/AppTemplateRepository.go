@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type AppTemplate struct {
+	tableName     struct{} `sql:"app_template" pg:",discard_unknown_columns"`
+	Id            int      `sql:"id,pk"`
+	Name          string   `sql:"name,notnull"`
+	Description   string   `sql:"description"`
+	LatestVersion int      `sql:"latest_version,notnull"`
+	Active        bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type AppTemplateVersion struct {
+	tableName     struct{} `sql:"app_template_version" pg:",discard_unknown_columns"`
+	Id            int      `sql:"id,pk"`
+	AppTemplateId int      `sql:"app_template_id,notnull"`
+	Version       int      `sql:"version,notnull"`
+	Parameters    string   `sql:"parameters"`
+	TemplateData  string   `sql:"template_data,notnull"`
+	RefAppId      int      `sql:"ref_app_id"`
+	sql.AuditLog
+}
+
+type AppTemplateApp struct {
+	tableName       struct{} `sql:"app_template_app" pg:",discard_unknown_columns"`
+	Id              int      `sql:"id,pk"`
+	AppTemplateId   int      `sql:"app_template_id,notnull"`
+	AppId           int      `sql:"app_id,notnull"`
+	Version         int      `sql:"version,notnull"`
+	ParameterValues string   `sql:"parameter_values"`
+	sql.AuditLog
+}
+
+type AppTemplateRepository interface {
+	GetConnection() *pg.DB
+	Save(model *AppTemplate, tx *pg.Tx) error
+	Update(model *AppTemplate, tx *pg.Tx) error
+	FindById(id int) (*AppTemplate, error)
+	FindByName(name string) (*AppTemplate, error)
+	FindAllActive() ([]*AppTemplate, error)
+	SaveVersion(model *AppTemplateVersion, tx *pg.Tx) error
+	FindVersion(appTemplateId int, version int) (*AppTemplateVersion, error)
+	FindVersions(appTemplateId int) ([]*AppTemplateVersion, error)
+	SaveTemplateApp(model *AppTemplateApp) error
+	FindTemplateAppByAppId(appId int) (*AppTemplateApp, error)
+	FindTemplateAppsByTemplateId(appTemplateId int) ([]*AppTemplateApp, error)
+}
+
+type AppTemplateRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewAppTemplateRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *AppTemplateRepositoryImpl {
+	return &AppTemplateRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (repo AppTemplateRepositoryImpl) GetConnection() *pg.DB {
+	return repo.dbConnection
+}
+
+func (repo AppTemplateRepositoryImpl) Save(model *AppTemplate, tx *pg.Tx) error {
+	return tx.Insert(model)
+}
+
+func (repo AppTemplateRepositoryImpl) Update(model *AppTemplate, tx *pg.Tx) error {
+	return tx.Update(model)
+}
+
+func (repo AppTemplateRepositoryImpl) FindById(id int) (*AppTemplate, error) {
+	model := &AppTemplate{}
+	err := repo.dbConnection.Model(model).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo AppTemplateRepositoryImpl) FindByName(name string) (*AppTemplate, error) {
+	model := &AppTemplate{}
+	err := repo.dbConnection.Model(model).
+		Where("name = ?", name).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo AppTemplateRepositoryImpl) FindAllActive() ([]*AppTemplate, error) {
+	var models []*AppTemplate
+	err := repo.dbConnection.Model(&models).
+		Where("active = ?", true).
+		Order("name").
+		Select()
+	return models, err
+}
+
+func (repo AppTemplateRepositoryImpl) SaveVersion(model *AppTemplateVersion, tx *pg.Tx) error {
+	return tx.Insert(model)
+}
+
+func (repo AppTemplateRepositoryImpl) FindVersion(appTemplateId int, version int) (*AppTemplateVersion, error) {
+	model := &AppTemplateVersion{}
+	err := repo.dbConnection.Model(model).
+		Where("app_template_id = ?", appTemplateId).
+		Where("version = ?", version).
+		Select()
+	return model, err
+}
+
+func (repo AppTemplateRepositoryImpl) FindVersions(appTemplateId int) ([]*AppTemplateVersion, error) {
+	var models []*AppTemplateVersion
+	err := repo.dbConnection.Model(&models).
+		Column("id", "app_template_id", "version", "parameters", "ref_app_id", "created_on", "created_by", "updated_on", "updated_by").
+		Where("app_template_id = ?", appTemplateId).
+		Order("version DESC").
+		Select()
+	return models, err
+}
+
+func (repo AppTemplateRepositoryImpl) SaveTemplateApp(model *AppTemplateApp) error {
+	return repo.dbConnection.Insert(model)
+}
+
+func (repo AppTemplateRepositoryImpl) FindTemplateAppByAppId(appId int) (*AppTemplateApp, error) {
+	model := &AppTemplateApp{}
+	err := repo.dbConnection.Model(model).
+		Where("app_id = ?", appId).
+		Select()
+	return model, err
+}
+
+func (repo AppTemplateRepositoryImpl) FindTemplateAppsByTemplateId(appTemplateId int) ([]*AppTemplateApp, error) {
+	var models []*AppTemplateApp
+	err := repo.dbConnection.Model(&models).
+		Join("INNER JOIN app a ON a.id = app_template_app.app_id").
+		Where("app_template_app.app_template_id = ?", appTemplateId).
+		Where("a.active = ?", true).
+		Order("app_template_app.app_id").
+		Select()
+	return models, err
+}
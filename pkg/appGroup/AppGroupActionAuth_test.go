@@ -0,0 +1,109 @@
+package appGroup
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/appGroup/repository"
+	"github.com/devtron-labs/devtron/pkg/bulkAction"
+	"go.uber.org/zap"
+)
+
+type fakeAppGroupRepository struct {
+	repository.AppGroupRepository
+	group    *repository.AppGroup
+	mappings []*repository.AppGroupMapping
+}
+
+func (repo *fakeAppGroupRepository) FindById(id int) (*repository.AppGroup, error) {
+	return repo.group, nil
+}
+
+func (repo *fakeAppGroupRepository) FindActiveMappingsByAppGroupId(appGroupId int) ([]*repository.AppGroupMapping, error) {
+	return repo.mappings, nil
+}
+
+type fakeAppRepository struct {
+	app.AppRepository
+	apps []*app.App
+}
+
+func (repo *fakeAppRepository) FindAllActiveAppsWithTeam() ([]*app.App, error) {
+	return repo.apps, nil
+}
+
+// fakeBulkUpdateService deploys the apps which pass the check like bulk actions do, skipping the others
+type fakeBulkUpdateService struct {
+	bulkAction.BulkUpdateService
+	appNames map[int]string
+	called   bool
+}
+
+func (impl *fakeBulkUpdateService) BulkDeploy(request *bulkAction.BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string,
+	checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*bulkAction.BulkApplicationForEnvironmentResponse, error) {
+	impl.called = true
+	response := &bulkAction.BulkApplicationForEnvironmentResponse{Response: make(map[string]map[string]bool)}
+	for _, appId := range request.AppIdIncludes {
+		appName := impl.appNames[appId]
+		if checkAuthForBulkActions(token, "team/"+appName, "prod") {
+			response.Response[appName] = map[string]bool{"prod": true}
+		}
+	}
+	return response, nil
+}
+
+func newTestAppGroupService() (*AppGroupServiceImpl, *fakeBulkUpdateService) {
+	bulkUpdateService := &fakeBulkUpdateService{appNames: map[int]string{1: "payments", 2: "billing"}}
+	impl := NewAppGroupServiceImpl(zap.NewNop().Sugar(),
+		&fakeAppGroupRepository{
+			group:    &repository.AppGroup{Id: 5, Name: "Checkout", Active: true},
+			mappings: []*repository.AppGroupMapping{{AppGroupId: 5, AppId: 1}, {AppGroupId: 5, AppId: 2}},
+		},
+		&fakeAppRepository{apps: []*app.App{{Id: 1, AppName: "payments"}, {Id: 2, AppName: "billing"}}},
+		nil, nil, nil, nil, bulkUpdateService, nil)
+	return impl, bulkUpdateService
+}
+
+func TestPerformAppGroupActionChecksAccessOnMembers(t *testing.T) {
+	impl, bulkUpdateService := newTestAppGroupService()
+	request := &AppGroupActionRequest{AppGroupId: 5, Action: APP_GROUP_ACTION_DEPLOY, EnvId: 3}
+	var checkedGroupObject string
+	//global app group admin, having trigger on every group but access on payments only
+	checkAuthForAppGroup := func(token string, appGroupObject string) bool {
+		checkedGroupObject = appGroupObject
+		return true
+	}
+	checkAuthForMember := func(token string, appObject string, envObject string) bool {
+		return appObject == "team/payments"
+	}
+	response, err := impl.PerformAppGroupAction(context.Background(), request, "token", checkAuthForAppGroup, checkAuthForMember)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if checkedGroupObject != "checkout" {
+		t.Errorf("expected access checked on lower cased group name, got %s", checkedGroupObject)
+	}
+	if _, ok := response.Response["billing"]; ok || len(response.Response) != 1 {
+		t.Errorf("access on group must not authorize apps without access, got %v", response.Response)
+	}
+	if !bulkUpdateService.called {
+		t.Errorf("expected bulk deploy of members")
+	}
+}
+
+func TestPerformAppGroupActionWithoutAccessOnGroup(t *testing.T) {
+	impl, bulkUpdateService := newTestAppGroupService()
+	request := &AppGroupActionRequest{AppGroupId: 5, Action: APP_GROUP_ACTION_DEPLOY, EnvId: 3}
+	checkAuthForAppGroup := func(token string, appGroupObject string) bool { return false }
+	checkAuthForMember := func(token string, appObject string, envObject string) bool { return true }
+	_, err := impl.PerformAppGroupAction(context.Background(), request, "token", checkAuthForAppGroup, checkAuthForMember)
+	if apiErr, ok := err.(*util.ApiError); !ok || apiErr.HttpStatusCode != http.StatusForbidden {
+		t.Errorf("expected forbidden error, got %v", err)
+	}
+	if bulkUpdateService.called {
+		t.Errorf("no action is to be performed without access on group")
+	}
+}
@@ -0,0 +1,458 @@
+package appGroup
+
+import (
+	"context"
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	app2 "github.com/devtron-labs/devtron/pkg/app"
+	"github.com/devtron-labs/devtron/pkg/appGroup/repository"
+	"github.com/devtron-labs/devtron/pkg/bulkAction"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/user"
+	repository2 "github.com/devtron-labs/devtron/pkg/user/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+type AppGroupService interface {
+	CreateAppGroup(request *AppGroupDto) (*AppGroupDto, error)
+	UpdateAppGroup(request *AppGroupDto) (*AppGroupDto, error)
+	DeleteAppGroup(id int, userId int32) error
+	// GetAppGroup returns the group with its members resolved
+	GetAppGroup(id int) (*AppGroupDto, error)
+	GetAllAppGroups() ([]*AppGroupDto, error)
+	// FindAppGroupMembers resolves the members of a group definition, it is used for checking access on members before
+	// a group is saved
+	FindAppGroupMembers(request *AppGroupDto) ([]*AppGroupApp, error)
+	// GetAppGroupStatus aggregates ci status, deployment status and app status of every member per environment, envId
+	// filters the environments when given
+	GetAppGroupStatus(ctx context.Context, id int, envId int) (*AppGroupStatus, error)
+	// PerformAppGroupAction runs the action for the members deployed in the env through bulk actions. Access on the group
+	// is needed for the action and every app and env acted upon is checked as well, access on a group never extends to
+	// apps on which the user has no access
+	PerformAppGroupAction(ctx context.Context, request *AppGroupActionRequest, token string,
+		checkAuthForAppGroup func(token string, appGroupObject string) bool,
+		checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*AppGroupActionResponse, error)
+}
+
+type AppGroupServiceImpl struct {
+	logger             *zap.SugaredLogger
+	appGroupRepository repository.AppGroupRepository
+	appRepository      app.AppRepository
+	pipelineRepository pipelineConfig.PipelineRepository
+	appListingService  app2.AppListingService
+	ciHandler          pipeline.CiHandler
+	cdHandler          pipeline.CdHandler
+	bulkUpdateService  bulkAction.BulkUpdateService
+	userAuthService    user.UserAuthService
+}
+
+func NewAppGroupServiceImpl(logger *zap.SugaredLogger,
+	appGroupRepository repository.AppGroupRepository,
+	appRepository app.AppRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	appListingService app2.AppListingService,
+	ciHandler pipeline.CiHandler,
+	cdHandler pipeline.CdHandler,
+	bulkUpdateService bulkAction.BulkUpdateService,
+	userAuthService user.UserAuthService) *AppGroupServiceImpl {
+	return &AppGroupServiceImpl{
+		logger:             logger,
+		appGroupRepository: appGroupRepository,
+		appRepository:      appRepository,
+		pipelineRepository: pipelineRepository,
+		appListingService:  appListingService,
+		ciHandler:          ciHandler,
+		cdHandler:          cdHandler,
+		bulkUpdateService:  bulkUpdateService,
+		userAuthService:    userAuthService,
+	}
+}
+
+func (impl AppGroupServiceImpl) CreateAppGroup(request *AppGroupDto) (*AppGroupDto, error) {
+	//names are unique irrespective of case as rbac of group is on its lower cased name
+	existing, err := impl.appGroupRepository.FindByName(request.Name)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app group by name", "err", err, "name", request.Name)
+		return nil, err
+	}
+	if existing != nil && existing.Id > 0 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("app group %s already exists", request.Name)}
+	}
+	_, err = impl.FindAppGroupMembers(request)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := impl.appGroupRepository.GetConnection().Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	model := &repository.AppGroup{
+		Name:        request.Name,
+		Description: request.Description,
+		Selector:    request.Selector,
+		Active:      true,
+		AuditLog:    sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.UserId, UpdatedOn: time.Now(), UpdatedBy: request.UserId},
+	}
+	err = impl.appGroupRepository.Save(model, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving app group", "err", err, "request", request)
+		return nil, err
+	}
+	err = impl.saveAppGroupMappings(model.Id, request, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return impl.GetAppGroup(model.Id)
+}
+
+func (impl AppGroupServiceImpl) UpdateAppGroup(request *AppGroupDto) (*AppGroupDto, error) {
+	model, err := impl.appGroupRepository.FindById(request.Id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app group %d not found", request.Id)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app group", "err", err, "id", request.Id)
+		return nil, err
+	}
+	if model.Name != request.Name {
+		//rbac of group is on its name, renaming would move the access given on group
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: "name of app group can not be changed"}
+	}
+	_, err = impl.FindAppGroupMembers(request)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := impl.appGroupRepository.GetConnection().Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	model.Description = request.Description
+	model.Selector = request.Selector
+	model.UpdatedOn = time.Now()
+	model.UpdatedBy = request.UserId
+	err = impl.appGroupRepository.Update(model, tx)
+	if err != nil {
+		impl.logger.Errorw("error in updating app group", "err", err, "request", request)
+		return nil, err
+	}
+	err = impl.appGroupRepository.DeactivateMappingsByAppGroupId(model.Id, request.UserId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deactivating app group mappings", "err", err, "appGroupId", model.Id)
+		return nil, err
+	}
+	err = impl.saveAppGroupMappings(model.Id, request, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return impl.GetAppGroup(model.Id)
+}
+
+func (impl AppGroupServiceImpl) saveAppGroupMappings(appGroupId int, request *AppGroupDto, tx *pg.Tx) error {
+	var mappings []*repository.AppGroupMapping
+	for _, appId := range uniqueAppIds(request.AppIds) {
+		mappings = append(mappings, &repository.AppGroupMapping{
+			AppGroupId: appGroupId,
+			AppId:      appId,
+			Active:     true,
+			AuditLog:   sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.UserId, UpdatedOn: time.Now(), UpdatedBy: request.UserId},
+		})
+	}
+	err := impl.appGroupRepository.SaveMappings(mappings, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving app group mappings", "err", err, "appGroupId", appGroupId)
+	}
+	return err
+}
+
+func (impl AppGroupServiceImpl) DeleteAppGroup(id int, userId int32) error {
+	model, err := impl.appGroupRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app group %d not found", id)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app group", "err", err, "id", id)
+		return err
+	}
+	tx, err := impl.appGroupRepository.GetConnection().Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	err = impl.appGroupRepository.DeactivateMappingsByAppGroupId(model.Id, userId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deactivating app group mappings", "err", err, "appGroupId", model.Id)
+		return err
+	}
+	model.Active = false
+	model.UpdatedOn = time.Now()
+	model.UpdatedBy = userId
+	err = impl.appGroupRepository.Update(model, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deleting app group", "err", err, "id", id)
+		return err
+	}
+	//deleting auth roles entries for this app group
+	err = impl.userAuthService.DeleteRoles(repository2.APP_GROUP_TYPE, model.Name, tx, "")
+	if err != nil {
+		impl.logger.Errorw("error in deleting auth roles", "err", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (impl AppGroupServiceImpl) GetAppGroup(id int) (*AppGroupDto, error) {
+	model, err := impl.appGroupRepository.FindById(id)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("app group %d not found", id)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting app group", "err", err, "id", id)
+		return nil, err
+	}
+	mappings, err := impl.appGroupRepository.FindActiveMappingsByAppGroupId(id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app group mappings", "err", err, "appGroupId", id)
+		return nil, err
+	}
+	dto := toAppGroupDto(model)
+	for _, mapping := range mappings {
+		dto.AppIds = append(dto.AppIds, mapping.AppId)
+	}
+	dto.Apps, err = impl.FindAppGroupMembers(dto)
+	if err != nil {
+		return nil, err
+	}
+	return dto, nil
+}
+
+func (impl AppGroupServiceImpl) GetAllAppGroups() ([]*AppGroupDto, error) {
+	models, err := impl.appGroupRepository.FindAllActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app groups", "err", err)
+		return nil, err
+	}
+	dtos := make([]*AppGroupDto, 0, len(models))
+	for _, model := range models {
+		dtos = append(dtos, toAppGroupDto(model))
+	}
+	return dtos, nil
+}
+
+func toAppGroupDto(model *repository.AppGroup) *AppGroupDto {
+	return &AppGroupDto{
+		Id:          model.Id,
+		Name:        model.Name,
+		Description: model.Description,
+		Selector:    model.Selector,
+	}
+}
+
+func (impl AppGroupServiceImpl) FindAppGroupMembers(request *AppGroupDto) ([]*AppGroupApp, error) {
+	if len(request.Selector) > 0 {
+		err := validateAppGroupSelector(request.Selector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	members := make(map[int]*app.App)
+	if appIds := uniqueAppIds(request.AppIds); len(appIds) > 0 {
+		apps, err := impl.appRepository.FindAllActiveAppsWithTeam()
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting apps for app group", "err", err)
+			return nil, err
+		}
+		appsById := make(map[int]*app.App, len(apps))
+		for _, app := range apps {
+			appsById[app.Id] = app
+		}
+		for _, appId := range appIds {
+			app, ok := appsById[appId]
+			if !ok {
+				return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("app %d not found", appId)}
+			}
+			members[appId] = app
+		}
+	}
+	if len(request.Selector) > 0 {
+		apps, err := impl.bulkUpdateService.FindAppsBySelector(request.Selector)
+		if err != nil {
+			impl.logger.Errorw("error in resolving app group selector", "err", err, "selector", request.Selector)
+			return nil, err
+		}
+		for _, app := range apps {
+			members[app.Id] = app
+		}
+	}
+	apps := make([]*AppGroupApp, 0, len(members))
+	for _, app := range members {
+		apps = append(apps, &AppGroupApp{AppId: app.Id, AppName: app.AppName, TeamId: app.TeamId, TeamName: app.Team.Name})
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].AppName < apps[j].AppName
+	})
+	return apps, nil
+}
+
+// validateAppGroupSelector allows project and label keys only, members of a group do not depend on environments or charts
+func validateAppGroupSelector(selector string) error {
+	requirements, err := bulkAction.ParseAppSelector(selector)
+	if err != nil {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: err.Error(), InternalMessage: err.Error()}
+	}
+	for _, requirement := range requirements {
+		if requirement.Key == bulkAction.SELECTOR_KEY_CLUSTER || requirement.Key == bulkAction.SELECTOR_KEY_CHART_VERSION {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("%s can not be used in app group selector", requirement.Key)}
+		}
+	}
+	return nil
+}
+
+func uniqueAppIds(appIds []int) []int {
+	var unique []int
+	seen := make(map[int]bool)
+	for _, appId := range appIds {
+		if !seen[appId] {
+			seen[appId] = true
+			unique = append(unique, appId)
+		}
+	}
+	return unique
+}
+
+func (impl AppGroupServiceImpl) GetAppGroupStatus(ctx context.Context, id int, envId int) (*AppGroupStatus, error) {
+	appGroup, err := impl.GetAppGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	status := &AppGroupStatus{Id: appGroup.Id, Name: appGroup.Name, Apps: make([]*AppGroupAppStatus, 0, len(appGroup.Apps))}
+	for _, member := range appGroup.Apps {
+		appStatus, err := impl.getAppStatus(ctx, member, envId)
+		if err != nil {
+			return nil, err
+		}
+		status.Apps = append(status.Apps, appStatus)
+	}
+	return status, nil
+}
+
+func (impl AppGroupServiceImpl) getAppStatus(ctx context.Context, member *AppGroupApp, envId int) (*AppGroupAppStatus, error) {
+	appStatus := &AppGroupAppStatus{AppId: member.AppId, AppName: member.AppName, Environments: make([]*AppGroupEnvStatus, 0)}
+	ciStatus, err := impl.ciHandler.FetchCiStatusForTriggerView(member.AppId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting ci status of app", "err", err, "appId", member.AppId)
+		return nil, err
+	}
+	appStatus.CiStatus = ciStatus
+	pipelines, err := impl.pipelineRepository.FindActiveByAppId(member.AppId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting cd pipelines of app", "err", err, "appId", member.AppId)
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return appStatus, nil
+	}
+	cdStatus, err := impl.cdHandler.FetchAppWorkflowStatusForTriggerView(member.AppId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting cd status of app", "err", err, "appId", member.AppId)
+		return nil, err
+	}
+	cdStatusByPipelineId := make(map[int]*pipelineConfig.CdWorkflowStatus)
+	for _, item := range cdStatus {
+		cdStatusByPipelineId[item.PipelineId] = item
+	}
+	environments, err := impl.appListingService.FetchOtherEnvironment(ctx, member.AppId)
+	if err != nil {
+		impl.logger.Errorw("error in getting environments of app", "err", err, "appId", member.AppId)
+		return nil, err
+	}
+	for _, pipeline := range pipelines {
+		if envId > 0 && pipeline.EnvironmentId != envId {
+			continue
+		}
+		envStatus := &AppGroupEnvStatus{
+			EnvironmentId:   pipeline.EnvironmentId,
+			EnvironmentName: pipeline.Environment.Name,
+			PipelineId:      pipeline.Id,
+		}
+		if item, ok := cdStatusByPipelineId[pipeline.Id]; ok {
+			envStatus.DeploymentStatus = item.DeployStatus
+			envStatus.PreStatus = item.PreStatus
+			envStatus.PostStatus = item.PostStatus
+		}
+		for _, environment := range environments {
+			if environment.EnvironmentId == pipeline.EnvironmentId {
+				envStatus.AppStatus = environment.AppStatus
+				envStatus.LastDeployed = environment.LastDeployed
+				break
+			}
+		}
+		appStatus.Environments = append(appStatus.Environments, envStatus)
+	}
+	return appStatus, nil
+}
+
+func (impl AppGroupServiceImpl) PerformAppGroupAction(ctx context.Context, request *AppGroupActionRequest, token string,
+	checkAuthForAppGroup func(token string, appGroupObject string) bool,
+	checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*AppGroupActionResponse, error) {
+	appGroup, err := impl.GetAppGroup(request.AppGroupId)
+	if err != nil {
+		return nil, err
+	}
+	if !checkAuthForAppGroup(token, strings.ToLower(appGroup.Name)) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusForbidden, UserMessage: "unauthorized user"}
+	}
+	response := &AppGroupActionResponse{
+		AppGroupId: appGroup.Id,
+		Action:     request.Action,
+		EnvId:      request.EnvId,
+		Response:   make(map[string]map[string]bool),
+		StartedOn:  time.Now(),
+	}
+	if len(appGroup.Apps) == 0 {
+		//empty includes would select every app in env for bulk actions
+		return response, nil
+	}
+	bulkRequest := &bulkAction.BulkApplicationForEnvironmentPayload{EnvId: request.EnvId, UserId: request.UserId}
+	for _, member := range appGroup.Apps {
+		bulkRequest.AppIdIncludes = append(bulkRequest.AppIdIncludes, member.AppId)
+	}
+	var bulkResponse *bulkAction.BulkApplicationForEnvironmentResponse
+	switch request.Action {
+	case APP_GROUP_ACTION_BUILD:
+		bulkResponse, err = impl.bulkUpdateService.BulkBuildTrigger(bulkRequest, ctx, nil, token, checkAuthForBulkActions)
+	case APP_GROUP_ACTION_DEPLOY:
+		bulkResponse, err = impl.bulkUpdateService.BulkDeploy(bulkRequest, ctx, nil, token, checkAuthForBulkActions)
+	case APP_GROUP_ACTION_HIBERNATE:
+		bulkResponse, err = impl.bulkUpdateService.BulkHibernate(bulkRequest, ctx, nil, token, checkAuthForBulkActions)
+	case APP_GROUP_ACTION_UNHIBERNATE:
+		bulkResponse, err = impl.bulkUpdateService.BulkUnHibernate(bulkRequest, ctx, nil, token, checkAuthForBulkActions)
+	case APP_GROUP_ACTION_RESTART:
+		bulkResponse, err = impl.bulkUpdateService.BulkRestart(bulkRequest, ctx, token, checkAuthForBulkActions)
+	default:
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("unknown app group action %s", request.Action)}
+	}
+	if err != nil {
+		impl.logger.Errorw("error in performing app group action", "err", err, "request", request)
+		return nil, err
+	}
+	response.Response = bulkResponse.Response
+	return response, nil
+}
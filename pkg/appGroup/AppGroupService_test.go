@@ -0,0 +1,28 @@
+package appGroup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUniqueAppIds(t *testing.T) {
+	appIds := uniqueAppIds([]int{3, 1, 3, 2, 1})
+	if !reflect.DeepEqual(appIds, []int{3, 1, 2}) {
+		t.Errorf("unexpected app ids %v", appIds)
+	}
+	if appIds = uniqueAppIds(nil); len(appIds) != 0 {
+		t.Errorf("unexpected app ids %v", appIds)
+	}
+}
+
+func TestValidateAppGroupSelector(t *testing.T) {
+	if err := validateAppGroupSelector("project=payments,team=checkout"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := validateAppGroupSelector("project=payments,cluster=prod"); err == nil {
+		t.Errorf("expected error for cluster key in app group selector")
+	}
+	if err := validateAppGroupSelector("chartVersion<4.11"); err == nil {
+		t.Errorf("expected error for chart version key in app group selector")
+	}
+}
@@ -0,0 +1,72 @@
+package appGroup
+
+import (
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"time"
+)
+
+const (
+	APP_GROUP_ACTION_BUILD       = "build"
+	APP_GROUP_ACTION_DEPLOY      = "deploy"
+	APP_GROUP_ACTION_HIBERNATE   = "hibernate"
+	APP_GROUP_ACTION_UNHIBERNATE = "unhibernate"
+	APP_GROUP_ACTION_RESTART     = "restart"
+)
+
+// AppGroupDto is a named collection of apps, members are the apps given in AppIds and the apps matching Selector.
+// Selector is in the format of bulk action selectors, only project and label keys are allowed
+type AppGroupDto struct {
+	Id          int            `json:"id"`
+	Name        string         `json:"name" validate:"required,max=250"`
+	Description string         `json:"description,omitempty"`
+	AppIds      []int          `json:"appIds,omitempty"`
+	Selector    string         `json:"selector,omitempty"`
+	Apps        []*AppGroupApp `json:"apps,omitempty"`
+	UserId      int32          `json:"-"`
+}
+
+type AppGroupApp struct {
+	AppId    int    `json:"appId"`
+	AppName  string `json:"appName"`
+	TeamId   int    `json:"teamId"`
+	TeamName string `json:"teamName,omitempty"`
+}
+
+type AppGroupStatus struct {
+	Id   int                  `json:"id"`
+	Name string               `json:"name"`
+	Apps []*AppGroupAppStatus `json:"apps"`
+}
+
+type AppGroupAppStatus struct {
+	AppId        int                                `json:"appId"`
+	AppName      string                             `json:"appName"`
+	CiStatus     []*pipelineConfig.CiWorkflowStatus `json:"ciStatus"`
+	Environments []*AppGroupEnvStatus               `json:"environments"`
+}
+
+type AppGroupEnvStatus struct {
+	EnvironmentId    int    `json:"environmentId"`
+	EnvironmentName  string `json:"environmentName"`
+	PipelineId       int    `json:"pipelineId"`
+	AppStatus        string `json:"appStatus"`
+	DeploymentStatus string `json:"deploymentStatus"`
+	PreStatus        string `json:"preStatus,omitempty"`
+	PostStatus       string `json:"postStatus,omitempty"`
+	LastDeployed     string `json:"lastDeployed,omitempty"`
+}
+
+type AppGroupActionRequest struct {
+	AppGroupId int    `json:"-"`
+	Action     string `json:"action" validate:"oneof=build deploy hibernate unhibernate restart"`
+	EnvId      int    `json:"envId" validate:"required"`
+	UserId     int32  `json:"-"`
+}
+
+type AppGroupActionResponse struct {
+	AppGroupId int                        `json:"appGroupId"`
+	Action     string                     `json:"action"`
+	EnvId      int                        `json:"envId"`
+	Response   map[string]map[string]bool `json:"response"`
+	StartedOn  time.Time                  `json:"startedOn"`
+}
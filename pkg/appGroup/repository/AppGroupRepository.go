@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type AppGroup struct {
+	tableName   struct{} `sql:"app_group" pg:",discard_unknown_columns"`
+	Id          int      `sql:"id,pk"`
+	Name        string   `sql:"name,notnull"`
+	Description string   `sql:"description"`
+	Selector    string   `sql:"selector"`
+	Active      bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type AppGroupMapping struct {
+	tableName  struct{} `sql:"app_group_mapping" pg:",discard_unknown_columns"`
+	Id         int      `sql:"id,pk"`
+	AppGroupId int      `sql:"app_group_id,notnull"`
+	AppId      int      `sql:"app_id,notnull"`
+	Active     bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type AppGroupRepository interface {
+	GetConnection() *pg.DB
+	Save(model *AppGroup, tx *pg.Tx) error
+	Update(model *AppGroup, tx *pg.Tx) error
+	FindById(id int) (*AppGroup, error)
+	FindByName(name string) (*AppGroup, error)
+	FindAllActive() ([]*AppGroup, error)
+	SaveMappings(models []*AppGroupMapping, tx *pg.Tx) error
+	DeactivateMappingsByAppGroupId(appGroupId int, userId int32, tx *pg.Tx) error
+	FindActiveMappingsByAppGroupId(appGroupId int) ([]*AppGroupMapping, error)
+}
+
+type AppGroupRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewAppGroupRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *AppGroupRepositoryImpl {
+	return &AppGroupRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (repo AppGroupRepositoryImpl) GetConnection() *pg.DB {
+	return repo.dbConnection
+}
+
+func (repo AppGroupRepositoryImpl) Save(model *AppGroup, tx *pg.Tx) error {
+	return tx.Insert(model)
+}
+
+func (repo AppGroupRepositoryImpl) Update(model *AppGroup, tx *pg.Tx) error {
+	return tx.Update(model)
+}
+
+func (repo AppGroupRepositoryImpl) FindById(id int) (*AppGroup, error) {
+	model := &AppGroup{}
+	err := repo.dbConnection.Model(model).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo AppGroupRepositoryImpl) FindByName(name string) (*AppGroup, error) {
+	model := &AppGroup{}
+	err := repo.dbConnection.Model(model).
+		Where("LOWER(name) = ?", strings.ToLower(name)).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo AppGroupRepositoryImpl) FindAllActive() ([]*AppGroup, error) {
+	var models []*AppGroup
+	err := repo.dbConnection.Model(&models).
+		Where("active = ?", true).
+		Order("name").
+		Select()
+	return models, err
+}
+
+func (repo AppGroupRepositoryImpl) SaveMappings(models []*AppGroupMapping, tx *pg.Tx) error {
+	if len(models) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&models).Insert()
+	return err
+}
+
+func (repo AppGroupRepositoryImpl) DeactivateMappingsByAppGroupId(appGroupId int, userId int32, tx *pg.Tx) error {
+	_, err := tx.Model((*AppGroupMapping)(nil)).
+		Set("active = ?", false).
+		Set("updated_on = ?", time.Now()).
+		Set("updated_by = ?", userId).
+		Where("app_group_id = ?", appGroupId).
+		Where("active = ?", true).
+		Update()
+	return err
+}
+
+func (repo AppGroupRepositoryImpl) FindActiveMappingsByAppGroupId(appGroupId int) ([]*AppGroupMapping, error) {
+	var models []*AppGroupMapping
+	err := repo.dbConnection.Model(&models).
+		Where("app_group_id = ?", appGroupId).
+		Where("active = ?", true).
+		Select()
+	return models, err
+}
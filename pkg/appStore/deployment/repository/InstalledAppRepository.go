@@ -180,6 +180,10 @@ func (impl InstalledAppRepositoryImpl) GetInstalledApp(id int) (*InstalledApps,
 	err := impl.dbConnection.Model(model).
 		Column("installed_apps.*", "App", "Environment", "App.Team", "Environment.Cluster").
 		Where("installed_apps.id = ?", id).Where("installed_apps.active = true").Select()
+	if err != nil {
+		return model, err
+	}
+	err = model.Environment.DecryptClusterConfig()
 	return model, err
 }
 
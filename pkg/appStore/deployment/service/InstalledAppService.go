@@ -79,6 +79,8 @@ type InstalledAppService interface {
 	FindAppDetailsForAppstoreApplication(installedAppId, envId int) (bean2.AppDetailContainer, error)
 	UpdateInstalledAppVersionStatus(application *v1alpha1.Application) (bool, error)
 	FetchResourceTree(rctx context.Context, cn http.CloseNotifier, appDetail *bean2.AppDetailContainer) bean2.AppDetailContainer
+	// DryRunUpdateInstalledApp diffs installed app against chart version and values of request without updating it
+	DryRunUpdateInstalledApp(ctx context.Context, request *appStoreBean.InstallAppVersionDTO) (*client.ReleaseDiffResponse, error)
 }
 
 type InstalledAppServiceImpl struct {
@@ -955,3 +957,64 @@ func (impl InstalledAppServiceImpl) FetchResourceTree(rctx context.Context, cn h
 	}
 	return *appDetail
 }
+
+func (impl InstalledAppServiceImpl) DryRunUpdateInstalledApp(ctx context.Context, request *appStoreBean.InstallAppVersionDTO) (*client.ReleaseDiffResponse, error) {
+	installedAppVersion, err := impl.installedAppRepository.GetActiveInstalledAppVersionByInstalledAppId(request.InstalledAppId)
+	if err != nil {
+		impl.logger.Errorw("error in getting installed app version", "err", err, "installedAppId", request.InstalledAppId)
+		return nil, err
+	}
+	appStoreAppVersion, err := impl.appStoreApplicationVersionRepository.FindById(request.AppStoreVersion)
+	if err != nil {
+		impl.logger.Errorw("error in fetching app store application version", "err", err, "appStoreApplicationVersionId", request.AppStoreVersion)
+		return nil, err
+	}
+	installedApp := installedAppVersion.InstalledApp
+	clusterId := installedApp.Environment.ClusterId
+	if util.IsHelmApp(installedApp.DeploymentAppType) {
+		desiredRequest := buildChartInstallRequest(installedApp.App.AppName, installedApp.Environment.Namespace, appStoreAppVersion, request.ValuesOverrideYaml)
+		return impl.helmAppService.DryRunUpdateApplicationWithChartInfo(ctx, clusterId, desiredRequest)
+	}
+	// argo cd deployments have no helm release, current state is the active version rendered with its values. argo cd
+	// renders chart with name of argo cd app as release name.
+	releaseName := fmt.Sprintf("%s-%s", installedApp.App.AppName, installedApp.Environment.Name)
+	desiredRequest := buildChartInstallRequest(releaseName, installedApp.Environment.Namespace, appStoreAppVersion, request.ValuesOverrideYaml)
+	currentRequest := buildChartInstallRequest(releaseName, installedApp.Environment.Namespace, &installedAppVersion.AppStoreApplicationVersion, installedAppVersion.ValuesYaml)
+	currentManifest, err := impl.helmAppService.RenderChart(ctx, clusterId, currentRequest)
+	if err != nil {
+		return nil, err
+	}
+	desiredManifest, err := impl.helmAppService.RenderChart(ctx, clusterId, desiredRequest)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := client.DiffReleaseManifests(currentManifest, desiredManifest)
+	if err != nil {
+		impl.logger.Errorw("error in diffing installed app manifests", "err", err, "installedAppId", request.InstalledAppId)
+		return nil, err
+	}
+	diff.ReleaseName = desiredRequest.ReleaseIdentifier.ReleaseName
+	diff.Namespace = desiredRequest.ReleaseIdentifier.ReleaseNamespace
+	diff.ChartName = desiredRequest.ChartName
+	diff.ChartVersion = desiredRequest.ChartVersion
+	return diff, nil
+}
+
+func buildChartInstallRequest(releaseName string, namespace string, appStoreAppVersion *appStoreDiscoverRepository.AppStoreApplicationVersion, valuesYaml string) *client.InstallReleaseRequest {
+	chartRepo := appStoreAppVersion.AppStore.ChartRepo
+	return &client.InstallReleaseRequest{
+		ReleaseIdentifier: &client.ReleaseIdentifier{
+			ReleaseName:      releaseName,
+			ReleaseNamespace: namespace,
+		},
+		ChartName:    appStoreAppVersion.Name,
+		ChartVersion: appStoreAppVersion.Version,
+		ValuesYaml:   valuesYaml,
+		ChartRepository: &client.ChartRepository{
+			Name:     chartRepo.Name,
+			Url:      chartRepo.Url,
+			Username: chartRepo.UserName,
+			Password: chartRepo.Password,
+		},
+	}
+}
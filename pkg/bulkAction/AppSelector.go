@@ -0,0 +1,242 @@
+package bulkAction
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// keys of a selector other than these are matched against app labels
+const (
+	SELECTOR_KEY_PROJECT       = "project"
+	SELECTOR_KEY_CLUSTER       = "cluster"
+	SELECTOR_KEY_CHART_VERSION = "chartVersion"
+)
+
+const (
+	SELECTOR_OP_EQUALS         = "="
+	SELECTOR_OP_NOT_EQUALS     = "!="
+	SELECTOR_OP_IN             = "in"
+	SELECTOR_OP_NOT_IN         = "notin"
+	SELECTOR_OP_EXISTS         = "exists"
+	SELECTOR_OP_DOES_NOT_EXIST = "!"
+	SELECTOR_OP_LESS           = "<"
+	SELECTOR_OP_LESS_EQUALS    = "<="
+	SELECTOR_OP_GREATER        = ">"
+	SELECTOR_OP_GREATER_EQUALS = ">="
+)
+
+// AppSelectorRequirement is one comma separated term of a selector, e.g. team=payments, tier in (critical) or chartVersion<4.11
+type AppSelectorRequirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+var (
+	selectorSetRegex    = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./-]*)\s+(in|notin)\s*\(([^()]*)\)$`)
+	selectorCompRegex   = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./-]*)\s*(==|!=|<=|>=|=|<|>)\s*([A-Za-z0-9_./-]+)$`)
+	selectorExistsRegex = regexp.MustCompile(`^(!?)\s*([A-Za-z0-9][A-Za-z0-9_./-]*)$`)
+)
+
+// ParseAppSelector parses selector expressions in the format of kubernetes label selectors, extended with ordering
+// operators for chartVersion
+func ParseAppSelector(selector string) ([]*AppSelectorRequirement, error) {
+	var requirements []*AppSelectorRequirement
+	for _, term := range splitSelectorTerms(selector) {
+		term = strings.TrimSpace(term)
+		if len(term) == 0 {
+			continue
+		}
+		requirement := &AppSelectorRequirement{}
+		if match := selectorSetRegex.FindStringSubmatch(term); match != nil {
+			requirement.Key, requirement.Operator = match[1], match[2]
+			for _, value := range strings.Split(match[3], ",") {
+				if value = strings.TrimSpace(value); len(value) > 0 {
+					requirement.Values = append(requirement.Values, value)
+				}
+			}
+			if len(requirement.Values) == 0 {
+				return nil, fmt.Errorf("invalid selector term %q, no values given for %s", term, requirement.Operator)
+			}
+		} else if match := selectorCompRegex.FindStringSubmatch(term); match != nil {
+			requirement.Key, requirement.Operator, requirement.Values = match[1], match[2], []string{match[3]}
+			if requirement.Operator == "==" {
+				requirement.Operator = SELECTOR_OP_EQUALS
+			}
+		} else if match := selectorExistsRegex.FindStringSubmatch(term); match != nil {
+			requirement.Key, requirement.Operator = match[2], SELECTOR_OP_EXISTS
+			if match[1] == "!" {
+				requirement.Operator = SELECTOR_OP_DOES_NOT_EXIST
+			}
+		} else {
+			return nil, fmt.Errorf("invalid selector term %q", term)
+		}
+		if requirement.isOrdering() {
+			if requirement.Key != SELECTOR_KEY_CHART_VERSION {
+				return nil, fmt.Errorf("invalid selector term %q, operator %s is supported only for %s", term, requirement.Operator, SELECTOR_KEY_CHART_VERSION)
+			}
+			if _, err := parseVersion(requirement.Values[0]); err != nil {
+				return nil, fmt.Errorf("invalid selector term %q, %s", term, err.Error())
+			}
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
+
+// splitSelectorTerms splits on the commas which are not inside the value list of in and notin
+func splitSelectorTerms(selector string) []string {
+	var terms []string
+	depth, start := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, selector[start:])
+}
+
+func (requirement *AppSelectorRequirement) isOrdering() bool {
+	switch requirement.Operator {
+	case SELECTOR_OP_LESS, SELECTOR_OP_LESS_EQUALS, SELECTOR_OP_GREATER, SELECTOR_OP_GREATER_EQUALS:
+		return true
+	}
+	return false
+}
+
+// Matches evaluates the requirement for the values of its key, an object can have more than one value for a label key.
+// Negative operators match objects not having the key, as in kubernetes
+func (requirement *AppSelectorRequirement) Matches(values []string) bool {
+	switch requirement.Operator {
+	case SELECTOR_OP_EXISTS:
+		return len(values) > 0
+	case SELECTOR_OP_DOES_NOT_EXIST:
+		return len(values) == 0
+	case SELECTOR_OP_EQUALS, SELECTOR_OP_IN:
+		return containsAny(values, requirement.Values)
+	case SELECTOR_OP_NOT_EQUALS, SELECTOR_OP_NOT_IN:
+		return !containsAny(values, requirement.Values)
+	}
+	//ordering operators
+	expected, _ := parseVersion(requirement.Values[0])
+	for _, value := range values {
+		actual, err := parseVersion(value)
+		if err != nil {
+			continue
+		}
+		comparison := compareVersions(actual, expected)
+		switch requirement.Operator {
+		case SELECTOR_OP_LESS:
+			if comparison < 0 {
+				return true
+			}
+		case SELECTOR_OP_LESS_EQUALS:
+			if comparison <= 0 {
+				return true
+			}
+		case SELECTOR_OP_GREATER:
+			if comparison > 0 {
+				return true
+			}
+		case SELECTOR_OP_GREATER_EQUALS:
+			if comparison >= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsAny(values []string, expected []string) bool {
+	for _, value := range values {
+		for _, expectedValue := range expected {
+			if value == expectedValue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseVersion parses the numeric segments of versions like 4.11, v4.11.0 or 4.11.0-beta, pre release is ignored
+func parseVersion(version string) ([]int, error) {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	var segments []int
+	for _, segment := range strings.Split(version, ".") {
+		number, err := strconv.Atoi(segment)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a version", version)
+		}
+		segments = append(segments, number)
+	}
+	return segments, nil
+}
+
+func compareVersions(a []int, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// likeMatches applies sql LIKE semantics of app name includes and excludes on a name, backslash escapes the next character
+func likeMatches(pattern string, name string) bool {
+	var regex strings.Builder
+	escaped := false
+	for _, c := range pattern {
+		switch {
+		case escaped:
+			regex.WriteString(regexp.QuoteMeta(string(c)))
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '%':
+			regex.WriteString(".*")
+		case c == '_':
+			regex.WriteString(".")
+		default:
+			regex.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	matched, err := regexp.MatchString("(?s)^"+regex.String()+"$", name)
+	return err == nil && matched
+}
+
+func likeMatchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if likeMatches(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeLike makes a name match only itself when used as a LIKE pattern
+func escapeLike(name string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(name)
+}
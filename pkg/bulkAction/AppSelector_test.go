@@ -0,0 +1,64 @@
+package bulkAction
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAppSelector(t *testing.T) {
+	requirements, err := ParseAppSelector("team=payments, tier in (critical, high),chartVersion<4.11,!deprecated,cluster!=prod")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []*AppSelectorRequirement{
+		{Key: "team", Operator: SELECTOR_OP_EQUALS, Values: []string{"payments"}},
+		{Key: "tier", Operator: SELECTOR_OP_IN, Values: []string{"critical", "high"}},
+		{Key: SELECTOR_KEY_CHART_VERSION, Operator: SELECTOR_OP_LESS, Values: []string{"4.11"}},
+		{Key: "deprecated", Operator: SELECTOR_OP_DOES_NOT_EXIST},
+		{Key: SELECTOR_KEY_CLUSTER, Operator: SELECTOR_OP_NOT_EQUALS, Values: []string{"prod"}},
+	}
+	if !reflect.DeepEqual(requirements, expected) {
+		t.Errorf("unexpected requirements %+v", requirements)
+	}
+}
+
+func TestParseAppSelectorInvalid(t *testing.T) {
+	for _, selector := range []string{"tier in ()", "team<payments", "chartVersion>latest", "team=a=b"} {
+		if _, err := ParseAppSelector(selector); err == nil {
+			t.Errorf("expected error for selector %q", selector)
+		}
+	}
+}
+
+func TestAppSelectorRequirementMatches(t *testing.T) {
+	tests := []struct {
+		selector string
+		values   []string
+		want     bool
+	}{
+		{"team=payments", []string{"payments"}, true},
+		{"team=payments", nil, false},
+		{"team!=payments", nil, true},
+		{"tier notin (critical)", []string{"low"}, true},
+		{"tier", []string{"low"}, true},
+		{"chartVersion<4.11", []string{"4.10.2"}, true},
+		{"chartVersion<4.11", []string{"4.11.0"}, false},
+		{"chartVersion>=4.9", []string{"v4.11.1-beta"}, true},
+		{"chartVersion>4.9", nil, false},
+	}
+	for _, tt := range tests {
+		requirements, err := ParseAppSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("unexpected error %v for %q", err, tt.selector)
+		}
+		if got := requirements[0].Matches(tt.values); got != tt.want {
+			t.Errorf("%q on %v = %v, want %v", tt.selector, tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestLikeMatches(t *testing.T) {
+	if !likeMatches("pay%", "payments") || likeMatches("pay_", "payments") || !likeMatches(escapeLike("my_app"), "my_app") || likeMatches(escapeLike("my_app"), "myxapp") {
+		t.Errorf("unexpected like matching")
+	}
+}
@@ -25,6 +25,9 @@ import (
 	pipeline1 "github.com/devtron-labs/devtron/pkg/pipeline"
 	"github.com/devtron-labs/devtron/pkg/pipeline/history"
 	repository4 "github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
+	"github.com/devtron-labs/devtron/pkg/plugin"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/workloadAction"
 	"github.com/devtron-labs/devtron/util/rbac"
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/go-pg/pg"
@@ -33,6 +36,8 @@ import (
 	"go.uber.org/zap"
 	"net/http"
 	"sort"
+	"strings"
+	"time"
 )
 
 type BulkUpdateService interface {
@@ -43,11 +48,28 @@ type BulkUpdateService interface {
 	BulkUpdateConfigMap(bulkUpdatePayload *BulkUpdatePayload) *CmAndSecretBulkUpdateResponse
 	BulkUpdateSecret(bulkUpdatePayload *BulkUpdatePayload) *CmAndSecretBulkUpdateResponse
 	BulkUpdate(bulkUpdateRequest *BulkUpdatePayload) (bulkUpdateResponse *BulkUpdateResponse)
+	// BulkUpdateDryRun applies the patches in memory only and returns the diff of every object which would change
+	BulkUpdateDryRun(bulkUpdateRequest *BulkUpdatePayload) (bulkUpdateResponse *BulkUpdateResponse)
+	// BulkUpdateAsync saves the job and runs the bulk update in background, job status is to be polled with GetBulkUpdateJob
+	BulkUpdateAsync(bulkUpdateRequest *BulkUpdatePayload) (*BulkUpdateJobDto, error)
+	GetBulkUpdateJob(jobId int) (*BulkUpdateJobDto, error)
+	GetBulkUpdateJobs() ([]*BulkUpdateJobDto, error)
+	// RevertBulkUpdateJob writes back the state before the job for every object changed by it, objects modified after
+	// the job are not reverted and reported as failure
+	RevertBulkUpdateJob(jobId int, userId int32) (*BulkUpdateRevertResponse, error)
+	GetCiBulkUpdateImpactedPipelines(payload *CiBulkUpdatePayload) ([]*bean2.CiPipeline, error)
+	// BulkUpdateCiPipelines edits the selected ci pipelines through ci pipeline patch, changes are recorded as a job
+	// which can be reverted like other bulk updates
+	BulkUpdateCiPipelines(payload *CiBulkUpdatePayload, dryRun bool) (*CiBulkUpdateResponse, error)
 
 	BulkHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
 	BulkUnHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
 	BulkDeploy(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
 	BulkBuildTrigger(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
+	// BulkRestart restarts the pods of deployment or rollout of the apps in env, like kubectl rollout restart
+	BulkRestart(request *BulkApplicationForEnvironmentPayload, ctx context.Context, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error)
+	// FindAppsBySelector returns the apps matching the project, label and chart version requirements of selector
+	FindAppsBySelector(selector string) ([]*app.App, error)
 
 	GetBulkActionImpactedPipelinesAndWfs(dto *CdBulkActionRequestDto) ([]*pipelineConfig.Pipeline, []int, []int, error)
 	PerformBulkActionOnCdPipelines(dto *CdBulkActionRequestDto, impactedPipelines []*pipelineConfig.Pipeline, ctx context.Context, dryRun bool, impactedAppWfIds []int, impactedCiPipelineIds []int) (*PipelineAndWfBulkActionResponseDto, error)
@@ -84,6 +106,10 @@ type BulkUpdateServiceImpl struct {
 	ciPipelineRepository             pipelineConfig.CiPipelineRepository
 	appWorkflowRepository            appWorkflow.AppWorkflowRepository
 	appWorkflowService               appWorkflow2.AppWorkflowService
+	bulkUpdateJobRepository          bulkUpdate.BulkUpdateJobRepository
+	appLabelRepository               pipelineConfig.AppLabelRepository
+	globalPluginService              plugin.GlobalPluginService
+	workloadActionService            workloadAction.WorkloadActionService
 }
 
 func NewBulkUpdateServiceImpl(bulkUpdateRepository bulkUpdate.BulkUpdateRepository,
@@ -111,7 +137,11 @@ func NewBulkUpdateServiceImpl(bulkUpdateRepository bulkUpdate.BulkUpdateReposito
 	enforcerUtilHelm rbac.EnforcerUtilHelm, ciHandler pipeline.CiHandler,
 	ciPipelineRepository pipelineConfig.CiPipelineRepository,
 	appWorkflowRepository appWorkflow.AppWorkflowRepository,
-	appWorkflowService appWorkflow2.AppWorkflowService) *BulkUpdateServiceImpl {
+	appWorkflowService appWorkflow2.AppWorkflowService,
+	bulkUpdateJobRepository bulkUpdate.BulkUpdateJobRepository,
+	appLabelRepository pipelineConfig.AppLabelRepository,
+	globalPluginService plugin.GlobalPluginService,
+	workloadActionService workloadAction.WorkloadActionService) *BulkUpdateServiceImpl {
 	return &BulkUpdateServiceImpl{
 		bulkUpdateRepository:             bulkUpdateRepository,
 		chartRepository:                  chartRepository,
@@ -143,6 +173,10 @@ func NewBulkUpdateServiceImpl(bulkUpdateRepository bulkUpdate.BulkUpdateReposito
 		ciPipelineRepository:             ciPipelineRepository,
 		appWorkflowRepository:            appWorkflowRepository,
 		appWorkflowService:               appWorkflowService,
+		bulkUpdateJobRepository:          bulkUpdateJobRepository,
+		appLabelRepository:               appLabelRepository,
+		globalPluginService:              globalPluginService,
+		workloadActionService:            workloadActionService,
 	}
 }
 
@@ -172,6 +206,12 @@ func (impl BulkUpdateServiceImpl) GetBulkAppName(bulkUpdatePayload *BulkUpdatePa
 	deploymentTemplateImpactedObjects := []*DeploymentTemplateImpactedObjectsResponseForOneApp{}
 	configMapImpactedObjects := []*CmAndSecretImpactedObjectsResponseForOneApp{}
 	secretImpactedObjects := []*CmAndSecretImpactedObjectsResponseForOneApp{}
+	bulkUpdatePayload, err := impl.applyAppSelectorOnBulkUpdatePayload(bulkUpdatePayload)
+	if err == errNoAppMatchesSelector {
+		return impactedObjectsResponse, nil
+	} else if err != nil {
+		return nil, err
+	}
 	var appNameIncludes []string
 	var appNameExcludes []string
 	if bulkUpdatePayload.Includes == nil || len(bulkUpdatePayload.Includes.Names) == 0 {
@@ -371,6 +411,14 @@ func (impl BulkUpdateServiceImpl) ApplyJsonPatch(patch jsonpatch.Patch, target s
 	return string(modified), err
 }
 func (impl BulkUpdateServiceImpl) BulkUpdateDeploymentTemplate(bulkUpdatePayload *BulkUpdatePayload) *DeploymentTemplateBulkUpdateResponse {
+	bulkUpdatePayload, err := impl.applyAppSelectorOnBulkUpdatePayload(bulkUpdatePayload)
+	if err != nil {
+		return &DeploymentTemplateBulkUpdateResponse{Message: []string{selectorErrorMessage(err)}}
+	}
+	return impl.bulkUpdateDeploymentTemplate(bulkUpdatePayload, &bulkUpdateExecution{})
+}
+
+func (impl BulkUpdateServiceImpl) bulkUpdateDeploymentTemplate(bulkUpdatePayload *BulkUpdatePayload, execution *bulkUpdateExecution) *DeploymentTemplateBulkUpdateResponse {
 	deploymentTemplateBulkUpdateResponse := &DeploymentTemplateBulkUpdateResponse{}
 	var appNameIncludes []string
 	var appNameExcludes []string
@@ -410,6 +458,14 @@ func (impl BulkUpdateServiceImpl) BulkUpdateDeploymentTemplate(bulkUpdatePayload
 							Message: fmt.Sprintf("Error in applying JSON patch : %s", err.Error()),
 						}
 						deploymentTemplateBulkUpdateResponse.Failure = append(deploymentTemplateBulkUpdateResponse.Failure, bulkUpdateFailedResponse)
+					} else if execution.dryRun {
+						impl.addBulkUpdateDiff(execution, appDetailsByChart, 0, BULK_UPDATE_DIFF_DEPLOYMENT_TEMPLATE, chart.Values, modified)
+						bulkUpdateSuccessResponse := &DeploymentTemplateBulkUpdateResponseForOneApp{
+							AppId:   appDetailsByChart.Id,
+							AppName: appDetailsByChart.AppName,
+							Message: "Updated Successfully",
+						}
+						deploymentTemplateBulkUpdateResponse.Successful = append(deploymentTemplateBulkUpdateResponse.Successful, bulkUpdateSuccessResponse)
 					} else {
 						err = impl.bulkUpdateRepository.BulkUpdateChartsValuesYamlAndGlobalOverrideById(chart.Id, modified)
 						if err != nil {
@@ -427,21 +483,11 @@ func (impl BulkUpdateServiceImpl) BulkUpdateDeploymentTemplate(bulkUpdatePayload
 								Message: "Updated Successfully",
 							}
 							deploymentTemplateBulkUpdateResponse.Successful = append(deploymentTemplateBulkUpdateResponse.Successful, bulkUpdateSuccessResponse)
+							impl.recordBulkUpdateChange(execution, bulkUpdate.BULK_UPDATE_OBJECT_CHART, chart.Id, appDetailsByChart, 0, chart.Values, modified)
 
-							//creating history entry for deployment template
-							appLevelAppMetricsEnabled := false
-							appLevelMetrics, err := impl.appLevelMetricsRepository.FindByAppId(chart.AppId)
-							if err != nil && err != pg.ErrNoRows {
-								impl.logger.Errorw("error in getting app level metrics app level", "error", err)
-							} else if err == nil {
-								appLevelAppMetricsEnabled = appLevelMetrics.AppMetrics
-							}
 							chart.GlobalOverride = modified
 							chart.Values = modified
-							err = impl.deploymentTemplateHistoryService.CreateDeploymentTemplateHistoryFromGlobalTemplate(chart, nil, appLevelAppMetricsEnabled)
-							if err != nil {
-								impl.logger.Errorw("error in creating entry for deployment template history", "err", err, "chart", chart)
-							}
+							impl.createChartHistory(chart)
 						}
 					}
 				}
@@ -470,6 +516,15 @@ func (impl BulkUpdateServiceImpl) BulkUpdateDeploymentTemplate(bulkUpdatePayload
 							Message: fmt.Sprintf("Error in applying JSON patch : %s", err.Error()),
 						}
 						deploymentTemplateBulkUpdateResponse.Failure = append(deploymentTemplateBulkUpdateResponse.Failure, bulkUpdateFailedResponse)
+					} else if execution.dryRun {
+						impl.addBulkUpdateDiff(execution, appDetailsByChart, envId, BULK_UPDATE_DIFF_DEPLOYMENT_TEMPLATE, chartEnv.EnvOverrideValues, modified)
+						bulkUpdateSuccessResponse := &DeploymentTemplateBulkUpdateResponseForOneApp{
+							AppId:   appDetailsByChart.Id,
+							AppName: appDetailsByChart.AppName,
+							EnvId:   envId,
+							Message: "Updated Successfully",
+						}
+						deploymentTemplateBulkUpdateResponse.Successful = append(deploymentTemplateBulkUpdateResponse.Successful, bulkUpdateSuccessResponse)
 					} else {
 						err = impl.bulkUpdateRepository.BulkUpdateChartsEnvYamlOverrideById(chartEnv.Id, modified)
 						if err != nil {
@@ -489,27 +544,10 @@ func (impl BulkUpdateServiceImpl) BulkUpdateDeploymentTemplate(bulkUpdatePayload
 								Message: "Updated Successfully",
 							}
 							deploymentTemplateBulkUpdateResponse.Successful = append(deploymentTemplateBulkUpdateResponse.Successful, bulkUpdateSuccessResponse)
+							impl.recordBulkUpdateChange(execution, bulkUpdate.BULK_UPDATE_OBJECT_ENV_OVERRIDE, chartEnv.Id, appDetailsByChart, envId, chartEnv.EnvOverrideValues, modified)
 
-							//creating history entry for deployment template
-							envLevelAppMetricsEnabled := false
-							envLevelAppMetrics, err := impl.envLevelAppMetricsRepository.FindByAppIdAndEnvId(chartEnv.Chart.AppId, chartEnv.TargetEnvironment)
-							if err != nil && err != pg.ErrNoRows {
-								impl.logger.Errorw("error in getting env level app metrics", "err", err, "appId", chartEnv.Chart.AppId, "envId", chartEnv.TargetEnvironment)
-							} else if err == pg.ErrNoRows {
-								appLevelAppMetrics, err := impl.appLevelMetricsRepository.FindByAppId(chartEnv.Chart.AppId)
-								if err != nil && err != pg.ErrNoRows {
-									impl.logger.Errorw("error in getting app level app metrics", "err", err, "appId", chartEnv.Chart.AppId)
-								} else if err == nil {
-									envLevelAppMetricsEnabled = appLevelAppMetrics.AppMetrics
-								}
-							} else {
-								envLevelAppMetricsEnabled = *envLevelAppMetrics.AppMetrics
-							}
 							chartEnv.EnvOverrideValues = modified
-							err = impl.deploymentTemplateHistoryService.CreateDeploymentTemplateHistoryFromEnvOverrideTemplate(chartEnv, nil, envLevelAppMetricsEnabled, 0)
-							if err != nil {
-								impl.logger.Errorw("error in creating entry for env deployment template history", "err", err, "envOverride", chartEnv)
-							}
+							impl.createEnvOverrideHistory(chartEnv)
 						}
 					}
 				}
@@ -523,6 +561,14 @@ func (impl BulkUpdateServiceImpl) BulkUpdateDeploymentTemplate(bulkUpdatePayload
 }
 
 func (impl BulkUpdateServiceImpl) BulkUpdateConfigMap(bulkUpdatePayload *BulkUpdatePayload) *CmAndSecretBulkUpdateResponse {
+	bulkUpdatePayload, err := impl.applyAppSelectorOnBulkUpdatePayload(bulkUpdatePayload)
+	if err != nil {
+		return &CmAndSecretBulkUpdateResponse{Message: []string{selectorErrorMessage(err)}}
+	}
+	return impl.bulkUpdateConfigMap(bulkUpdatePayload, &bulkUpdateExecution{})
+}
+
+func (impl BulkUpdateServiceImpl) bulkUpdateConfigMap(bulkUpdatePayload *BulkUpdatePayload, execution *bulkUpdateExecution) *CmAndSecretBulkUpdateResponse {
 	configMapBulkUpdateResponse := &CmAndSecretBulkUpdateResponse{}
 	var appNameIncludes []string
 	var appNameExcludes []string
@@ -550,6 +596,7 @@ func (impl BulkUpdateServiceImpl) BulkUpdateConfigMap(bulkUpdatePayload *BulkUpd
 				configMapBulkUpdateResponse.Message = append(configMapBulkUpdateResponse.Message, "No matching apps to update globally")
 			} else {
 				for _, configMapAppModel := range configMapAppModels {
+					configMapDataBefore := configMapAppModel.ConfigMapData
 					configMapNames := gjson.Get(configMapAppModel.ConfigMapData, "maps.#.name")
 					messageCmNamesMap := make(map[string][]string)
 					for i, configMapName := range configMapNames.Array() {
@@ -589,8 +636,15 @@ func (impl BulkUpdateServiceImpl) BulkUpdateConfigMap(bulkUpdatePayload *BulkUpd
 							}
 						}
 					}
-					if _, ok := messageCmNamesMap["Updated Successfully"]; ok {
+					if _, ok := messageCmNamesMap["Updated Successfully"]; ok && execution.dryRun {
+						appDetailsById, _ := impl.appRepository.FindById(configMapAppModel.AppId)
+						impl.addBulkUpdateDiff(execution, appDetailsById, 0, BULK_UPDATE_DIFF_CONFIGMAP, configMapDataBefore, configMapAppModel.ConfigMapData)
+					} else if ok {
 						err := impl.bulkUpdateRepository.BulkUpdateConfigMapDataForGlobalById(configMapAppModel.Id, configMapAppModel.ConfigMapData)
+						if err == nil {
+							appDetailsById, _ := impl.appRepository.FindById(configMapAppModel.AppId)
+							impl.recordBulkUpdateChange(execution, bulkUpdate.BULK_UPDATE_OBJECT_CM_APP_LEVEL, configMapAppModel.Id, appDetailsById, 0, configMapDataBefore, configMapAppModel.ConfigMapData)
+						}
 						if err != nil {
 							impl.logger.Errorw("error in bulk updating charts", "err", err)
 							messageCmNamesMap[fmt.Sprintf("Error in updating in db : %s", err.Error())] = messageCmNamesMap["Updated Successfully"]
@@ -642,6 +696,7 @@ func (impl BulkUpdateServiceImpl) BulkUpdateConfigMap(bulkUpdatePayload *BulkUpd
 				configMapBulkUpdateResponse.Message = append(configMapBulkUpdateResponse.Message, fmt.Sprintf("No matching apps to update for envId : %d", envId))
 			} else {
 				for _, configMapEnvModel := range configMapEnvModels {
+					configMapDataBefore := configMapEnvModel.ConfigMapData
 					configMapNames := gjson.Get(configMapEnvModel.ConfigMapData, "maps.#.name")
 					messageCmNamesMap := make(map[string][]string)
 					for i, configMapName := range configMapNames.Array() {
@@ -681,8 +736,15 @@ func (impl BulkUpdateServiceImpl) BulkUpdateConfigMap(bulkUpdatePayload *BulkUpd
 							}
 						}
 					}
-					if _, ok := messageCmNamesMap["Updated Successfully"]; ok {
+					if _, ok := messageCmNamesMap["Updated Successfully"]; ok && execution.dryRun {
+						appDetailsById, _ := impl.appRepository.FindById(configMapEnvModel.AppId)
+						impl.addBulkUpdateDiff(execution, appDetailsById, envId, BULK_UPDATE_DIFF_CONFIGMAP, configMapDataBefore, configMapEnvModel.ConfigMapData)
+					} else if ok {
 						err := impl.bulkUpdateRepository.BulkUpdateConfigMapDataForEnvById(configMapEnvModel.Id, configMapEnvModel.ConfigMapData)
+						if err == nil {
+							appDetailsById, _ := impl.appRepository.FindById(configMapEnvModel.AppId)
+							impl.recordBulkUpdateChange(execution, bulkUpdate.BULK_UPDATE_OBJECT_CM_ENV_LEVEL, configMapEnvModel.Id, appDetailsById, envId, configMapDataBefore, configMapEnvModel.ConfigMapData)
+						}
 						if err != nil {
 							impl.logger.Errorw("error in bulk updating charts", "err", err)
 							messageCmNamesMap[fmt.Sprintf("Error in updating in db : %s", err.Error())] = messageCmNamesMap["Updated Successfully"]
@@ -728,6 +790,14 @@ func (impl BulkUpdateServiceImpl) BulkUpdateConfigMap(bulkUpdatePayload *BulkUpd
 	return configMapBulkUpdateResponse
 }
 func (impl BulkUpdateServiceImpl) BulkUpdateSecret(bulkUpdatePayload *BulkUpdatePayload) *CmAndSecretBulkUpdateResponse {
+	bulkUpdatePayload, err := impl.applyAppSelectorOnBulkUpdatePayload(bulkUpdatePayload)
+	if err != nil {
+		return &CmAndSecretBulkUpdateResponse{Message: []string{selectorErrorMessage(err)}}
+	}
+	return impl.bulkUpdateSecret(bulkUpdatePayload, &bulkUpdateExecution{})
+}
+
+func (impl BulkUpdateServiceImpl) bulkUpdateSecret(bulkUpdatePayload *BulkUpdatePayload, execution *bulkUpdateExecution) *CmAndSecretBulkUpdateResponse {
 	secretBulkUpdateResponse := &CmAndSecretBulkUpdateResponse{}
 	var appNameIncludes []string
 	var appNameExcludes []string
@@ -755,6 +825,7 @@ func (impl BulkUpdateServiceImpl) BulkUpdateSecret(bulkUpdatePayload *BulkUpdate
 				secretBulkUpdateResponse.Message = append(secretBulkUpdateResponse.Message, "No matching apps to update globally")
 			} else {
 				for _, secretAppModel := range secretAppModels {
+					secretDataBefore := secretAppModel.SecretData
 					secretNames := gjson.Get(secretAppModel.SecretData, "secrets.#.name")
 					messageSecretNamesMap := make(map[string][]string)
 					for i, secretName := range secretNames.Array() {
@@ -800,8 +871,15 @@ func (impl BulkUpdateServiceImpl) BulkUpdateSecret(bulkUpdatePayload *BulkUpdate
 							}
 						}
 					}
-					if _, ok := messageSecretNamesMap["Updated Successfully"]; ok {
+					if _, ok := messageSecretNamesMap["Updated Successfully"]; ok && execution.dryRun {
+						appDetailsById, _ := impl.appRepository.FindById(secretAppModel.AppId)
+						impl.addBulkUpdateDiff(execution, appDetailsById, 0, BULK_UPDATE_DIFF_SECRET, secretDataBefore, secretAppModel.SecretData)
+					} else if ok {
 						err := impl.bulkUpdateRepository.BulkUpdateSecretDataForGlobalById(secretAppModel.Id, secretAppModel.SecretData)
+						if err == nil {
+							appDetailsById, _ := impl.appRepository.FindById(secretAppModel.AppId)
+							impl.recordBulkUpdateChange(execution, bulkUpdate.BULK_UPDATE_OBJECT_SECRET_APP_LEVEL, secretAppModel.Id, appDetailsById, 0, secretDataBefore, secretAppModel.SecretData)
+						}
 						if err != nil {
 							impl.logger.Errorw("error in bulk updating secrets", "err", err)
 							messageSecretNamesMap[fmt.Sprintf("Error in updating in db : %s", err.Error())] = messageSecretNamesMap["Updated Successfully"]
@@ -853,6 +931,7 @@ func (impl BulkUpdateServiceImpl) BulkUpdateSecret(bulkUpdatePayload *BulkUpdate
 				secretBulkUpdateResponse.Message = append(secretBulkUpdateResponse.Message, fmt.Sprintf("No matching apps to update for envId : %d", envId))
 			} else {
 				for _, secretEnvModel := range secretEnvModels {
+					secretDataBefore := secretEnvModel.SecretData
 					secretNames := gjson.Get(secretEnvModel.SecretData, "secrets.#.name")
 					messageSecretNamesMap := make(map[string][]string)
 					for i, secretName := range secretNames.Array() {
@@ -898,8 +977,15 @@ func (impl BulkUpdateServiceImpl) BulkUpdateSecret(bulkUpdatePayload *BulkUpdate
 							}
 						}
 					}
-					if _, ok := messageSecretNamesMap["Updated Successfully"]; ok {
+					if _, ok := messageSecretNamesMap["Updated Successfully"]; ok && execution.dryRun {
+						appDetailsById, _ := impl.appRepository.FindById(secretEnvModel.AppId)
+						impl.addBulkUpdateDiff(execution, appDetailsById, envId, BULK_UPDATE_DIFF_SECRET, secretDataBefore, secretEnvModel.SecretData)
+					} else if ok {
 						err := impl.bulkUpdateRepository.BulkUpdateSecretDataForEnvById(secretEnvModel.Id, secretEnvModel.SecretData)
+						if err == nil {
+							appDetailsById, _ := impl.appRepository.FindById(secretEnvModel.AppId)
+							impl.recordBulkUpdateChange(execution, bulkUpdate.BULK_UPDATE_OBJECT_SECRET_ENV_LEVEL, secretEnvModel.Id, appDetailsById, envId, secretDataBefore, secretEnvModel.SecretData)
+						}
 						if err != nil {
 							impl.logger.Errorw("error in bulk updating charts", "err", err)
 							messageSecretNamesMap[fmt.Sprintf("Error in updating in db : %s", err.Error())] = messageSecretNamesMap["Updated Successfully"]
@@ -944,39 +1030,609 @@ func (impl BulkUpdateServiceImpl) BulkUpdateSecret(bulkUpdatePayload *BulkUpdate
 	}
 	return secretBulkUpdateResponse
 }
-func (impl BulkUpdateServiceImpl) BulkUpdate(bulkUpdatePayload *BulkUpdatePayload) *BulkUpdateResponse {
+func (impl BulkUpdateServiceImpl) runBulkUpdate(bulkUpdatePayload *BulkUpdatePayload, execution *bulkUpdateExecution) *BulkUpdateResponse {
 	bulkUpdateResponse := &BulkUpdateResponse{}
 	var deploymentTemplateBulkUpdateResponse *DeploymentTemplateBulkUpdateResponse
 	var configMapBulkUpdateResponse *CmAndSecretBulkUpdateResponse
 	var secretBulkUpdateResponse *CmAndSecretBulkUpdateResponse
+	bulkUpdatePayload, err := impl.applyAppSelectorOnBulkUpdatePayload(bulkUpdatePayload)
+	if err != nil {
+		return bulkUpdateErrorResponse(selectorErrorMessage(err))
+	}
 	if bulkUpdatePayload.DeploymentTemplate != nil && bulkUpdatePayload.DeploymentTemplate.Spec != nil && bulkUpdatePayload.DeploymentTemplate.Spec.PatchJson != "" {
-		deploymentTemplateBulkUpdateResponse = impl.BulkUpdateDeploymentTemplate(bulkUpdatePayload)
+		deploymentTemplateBulkUpdateResponse = impl.bulkUpdateDeploymentTemplate(bulkUpdatePayload, execution)
 	}
 	if bulkUpdatePayload.ConfigMap != nil && bulkUpdatePayload.ConfigMap.Spec != nil && len(bulkUpdatePayload.ConfigMap.Spec.Names) != 0 && bulkUpdatePayload.ConfigMap.Spec.PatchJson != "" {
-		configMapBulkUpdateResponse = impl.BulkUpdateConfigMap(bulkUpdatePayload)
+		configMapBulkUpdateResponse = impl.bulkUpdateConfigMap(bulkUpdatePayload, execution)
 	}
 	if bulkUpdatePayload.Secret != nil && bulkUpdatePayload.Secret.Spec != nil && len(bulkUpdatePayload.Secret.Spec.Names) != 0 && bulkUpdatePayload.Secret.Spec.PatchJson != "" {
-		secretBulkUpdateResponse = impl.BulkUpdateSecret(bulkUpdatePayload)
+		secretBulkUpdateResponse = impl.bulkUpdateSecret(bulkUpdatePayload, execution)
 	}
 
 	bulkUpdateResponse.DeploymentTemplate = deploymentTemplateBulkUpdateResponse
 	bulkUpdateResponse.ConfigMap = configMapBulkUpdateResponse
 	bulkUpdateResponse.Secret = secretBulkUpdateResponse
+	bulkUpdateResponse.DryRun = execution.dryRun
+	bulkUpdateResponse.Diff = execution.diffs
 	return bulkUpdateResponse
 }
 
-func (impl BulkUpdateServiceImpl) BulkHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
-	var pipelines []*pipelineConfig.Pipeline
-	var err error
-	if len(request.AppIdIncludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByInFilter(request.EnvId, request.AppIdIncludes)
-	} else if len(request.AppIdExcludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByNotFilter(request.EnvId, request.AppIdExcludes)
+// bulkUpdateExecution carries the mode of a bulk update run through the resource wise updates. In dry run nothing is
+// written and diffs are collected, otherwise every written object is recorded against the job so that it can be reverted
+type bulkUpdateExecution struct {
+	dryRun bool
+	jobId  int
+	userId int32
+	diffs  []*BulkUpdateDiffForOneApp
+}
+
+const bulkUpdateMaskedValue = "*****"
+
+func (impl BulkUpdateServiceImpl) addBulkUpdateDiff(execution *bulkUpdateExecution, app *app.App, envId int, diffType string, before string, after string) {
+	changes, err := diffJson(before, after, diffType == BULK_UPDATE_DIFF_SECRET)
+	if err != nil {
+		impl.logger.Errorw("error in computing bulk update diff", "err", err, "app", app, "envId", envId)
+		return
+	}
+	diff := &BulkUpdateDiffForOneApp{EnvId: envId, Type: diffType, Changes: changes}
+	if app != nil {
+		diff.AppId = app.Id
+		diff.AppName = app.AppName
+	}
+	execution.diffs = append(execution.diffs, diff)
+}
+
+func (impl BulkUpdateServiceImpl) recordBulkUpdateChange(execution *bulkUpdateExecution, objectType string, objectId int, app *app.App, envId int, before string, after string) {
+	if execution.jobId == 0 {
+		return
+	}
+	item := &bulkUpdate.BulkUpdateJobItem{
+		JobId:       execution.jobId,
+		ObjectType:  objectType,
+		ObjectId:    objectId,
+		EnvId:       envId,
+		BeforeState: before,
+		AfterState:  after,
+		AuditLog:    sql.AuditLog{CreatedOn: time.Now(), CreatedBy: execution.userId, UpdatedOn: time.Now(), UpdatedBy: execution.userId},
+	}
+	if app != nil {
+		item.AppId = app.Id
+		item.AppName = app.AppName
+	}
+	//update is already written, a failure here only makes the object not revertable
+	err := impl.bulkUpdateJobRepository.SaveItem(item)
+	if err != nil {
+		impl.logger.Errorw("error in recording bulk update change", "err", err, "jobId", execution.jobId, "objectType", objectType, "objectId", objectId)
+	}
+}
+
+func (impl BulkUpdateServiceImpl) createChartHistory(chart *chartRepoRepository.Chart) {
+	appLevelAppMetricsEnabled := false
+	appLevelMetrics, err := impl.appLevelMetricsRepository.FindByAppId(chart.AppId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app level metrics app level", "error", err)
+	} else if err == nil {
+		appLevelAppMetricsEnabled = appLevelMetrics.AppMetrics
+	}
+	err = impl.deploymentTemplateHistoryService.CreateDeploymentTemplateHistoryFromGlobalTemplate(chart, nil, appLevelAppMetricsEnabled)
+	if err != nil {
+		impl.logger.Errorw("error in creating entry for deployment template history", "err", err, "chart", chart)
+	}
+}
+
+func (impl BulkUpdateServiceImpl) createEnvOverrideHistory(chartEnv *chartConfig.EnvConfigOverride) {
+	envLevelAppMetricsEnabled := false
+	envLevelAppMetrics, err := impl.envLevelAppMetricsRepository.FindByAppIdAndEnvId(chartEnv.Chart.AppId, chartEnv.TargetEnvironment)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting env level app metrics", "err", err, "appId", chartEnv.Chart.AppId, "envId", chartEnv.TargetEnvironment)
+	} else if err == pg.ErrNoRows {
+		appLevelAppMetrics, err := impl.appLevelMetricsRepository.FindByAppId(chartEnv.Chart.AppId)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting app level app metrics", "err", err, "appId", chartEnv.Chart.AppId)
+		} else if err == nil {
+			envLevelAppMetricsEnabled = appLevelAppMetrics.AppMetrics
+		}
 	} else {
-		pipelines, err = impl.pipelineRepository.FindActiveByEnvId(request.EnvId)
+		envLevelAppMetricsEnabled = *envLevelAppMetrics.AppMetrics
 	}
+	err = impl.deploymentTemplateHistoryService.CreateDeploymentTemplateHistoryFromEnvOverrideTemplate(chartEnv, nil, envLevelAppMetricsEnabled, 0)
+	if err != nil {
+		impl.logger.Errorw("error in creating entry for env deployment template history", "err", err, "envOverride", chartEnv)
+	}
+}
+
+func (impl BulkUpdateServiceImpl) BulkUpdate(bulkUpdatePayload *BulkUpdatePayload) *BulkUpdateResponse {
+	job, err := impl.createBulkUpdateJob(bulkUpdatePayload)
+	if err != nil {
+		//nothing is updated when the job can not be saved, as changes could not be reverted
+		return bulkUpdateErrorResponse(fmt.Sprintf("Unable to save bulk update job : %s", err.Error()))
+	}
+	return impl.executeBulkUpdateJob(job, bulkUpdatePayload)
+}
+
+func bulkUpdateErrorResponse(message string) *BulkUpdateResponse {
+	return &BulkUpdateResponse{
+		DeploymentTemplate: &DeploymentTemplateBulkUpdateResponse{Message: []string{message}},
+		ConfigMap:          &CmAndSecretBulkUpdateResponse{Message: []string{message}},
+		Secret:             &CmAndSecretBulkUpdateResponse{Message: []string{message}},
+	}
+}
+
+func (impl BulkUpdateServiceImpl) BulkUpdateDryRun(bulkUpdatePayload *BulkUpdatePayload) *BulkUpdateResponse {
+	response := impl.runBulkUpdate(bulkUpdatePayload, &bulkUpdateExecution{dryRun: true})
+	if response.DeploymentTemplate != nil {
+		for _, successful := range response.DeploymentTemplate.Successful {
+			successful.Message = "Will be updated"
+		}
+		response.DeploymentTemplate.Message = toDryRunMessages(response.DeploymentTemplate.Message)
+	}
+	for _, cmAndSecretResponse := range []*CmAndSecretBulkUpdateResponse{response.ConfigMap, response.Secret} {
+		if cmAndSecretResponse == nil {
+			continue
+		}
+		for _, successful := range cmAndSecretResponse.Successful {
+			successful.Message = "Will be updated"
+		}
+		cmAndSecretResponse.Message = toDryRunMessages(cmAndSecretResponse.Message)
+	}
+	return response
+}
+
+func toDryRunMessages(messages []string) []string {
+	for i, message := range messages {
+		if message == "All matching apps are updated successfully" {
+			messages[i] = "All matching apps will be updated"
+		}
+	}
+	return messages
+}
+
+func (impl BulkUpdateServiceImpl) BulkUpdateAsync(bulkUpdatePayload *BulkUpdatePayload) (*BulkUpdateJobDto, error) {
+	job, err := impl.createBulkUpdateJob(bulkUpdatePayload)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				impl.logger.Errorw("panic in bulk update job", "jobId", job.Id, "err", r)
+				job.Status = bulkUpdate.BULK_UPDATE_JOB_FAILED
+				job.FinishedOn = time.Now()
+				job.UpdatedOn = time.Now()
+				_ = impl.bulkUpdateJobRepository.UpdateJob(job)
+			}
+		}()
+		impl.executeBulkUpdateJob(job, bulkUpdatePayload)
+	}()
+	return impl.toBulkUpdateJobDto(job, nil)
+}
+
+func (impl BulkUpdateServiceImpl) createBulkUpdateJob(bulkUpdatePayload *BulkUpdatePayload) (*bulkUpdate.BulkUpdateJob, error) {
+	return impl.saveBulkUpdateJob(bulkUpdate.BULK_UPDATE_JOB_KIND_APPLICATION, maskSecretPatchValues(bulkUpdatePayload), bulkUpdatePayload.UserId)
+}
+
+func (impl BulkUpdateServiceImpl) saveBulkUpdateJob(kind string, payload interface{}, userId int32) (*bulkUpdate.BulkUpdateJob, error) {
+	payloadJson, err := json.Marshal(payload)
+	if err != nil {
+		impl.logger.Errorw("error in marshaling bulk update payload", "err", err, "kind", kind)
+		return nil, err
+	}
+	job := &bulkUpdate.BulkUpdateJob{
+		Kind:      kind,
+		Status:    bulkUpdate.BULK_UPDATE_JOB_RUNNING,
+		Payload:   string(payloadJson),
+		StartedOn: time.Now(),
+		AuditLog:  sql.AuditLog{CreatedOn: time.Now(), CreatedBy: userId, UpdatedOn: time.Now(), UpdatedBy: userId},
+	}
+	err = impl.bulkUpdateJobRepository.SaveJob(job)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// maskSecretPatchValues returns a copy of payload without the values of secret patch, payload of a job is persisted as is
+func maskSecretPatchValues(bulkUpdatePayload *BulkUpdatePayload) *BulkUpdatePayload {
+	if bulkUpdatePayload.Secret == nil || bulkUpdatePayload.Secret.Spec == nil {
+		return bulkUpdatePayload
+	}
+	patchJson := bulkUpdatePayload.Secret.Spec.PatchJson
+	values := gjson.Get(patchJson, "#.value")
+	for j := range values.Array() {
+		patchJson, _ = sjson.Set(patchJson, fmt.Sprintf("%d.value", j), bulkUpdateMaskedValue)
+	}
+	masked := *bulkUpdatePayload
+	masked.Secret = &CmAndSecretTask{Spec: &CmAndSecretSpec{Names: bulkUpdatePayload.Secret.Spec.Names, PatchJson: patchJson}}
+	return &masked
+}
+
+func (impl BulkUpdateServiceImpl) executeBulkUpdateJob(job *bulkUpdate.BulkUpdateJob, bulkUpdatePayload *BulkUpdatePayload) *BulkUpdateResponse {
+	response := impl.runBulkUpdate(bulkUpdatePayload, &bulkUpdateExecution{jobId: job.Id, userId: bulkUpdatePayload.UserId})
+	response.JobId = job.Id
+	impl.completeBulkUpdateJob(job, response)
+	return response
+}
+
+func (impl BulkUpdateServiceImpl) completeBulkUpdateJob(job *bulkUpdate.BulkUpdateJob, response interface{}) {
+	responseJson, err := json.Marshal(response)
+	if err != nil {
+		impl.logger.Errorw("error in marshaling bulk update response", "err", err, "jobId", job.Id)
+	}
+	job.Status = bulkUpdate.BULK_UPDATE_JOB_COMPLETED
+	job.Response = string(responseJson)
+	job.FinishedOn = time.Now()
+	job.UpdatedOn = time.Now()
+	err = impl.bulkUpdateJobRepository.UpdateJob(job)
+	if err != nil {
+		impl.logger.Errorw("error in updating bulk update job status", "err", err, "jobId", job.Id)
+	}
+}
+
+func (impl BulkUpdateServiceImpl) GetBulkUpdateJob(jobId int) (*BulkUpdateJobDto, error) {
+	job, err := impl.bulkUpdateJobRepository.FindJobById(jobId)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("bulk update job %d not found", jobId)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting bulk update job", "err", err, "jobId", jobId)
+		return nil, err
+	}
+	items, err := impl.bulkUpdateJobRepository.FindItemsByJobId(jobId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting bulk update job items", "err", err, "jobId", jobId)
+		return nil, err
+	}
+	return impl.toBulkUpdateJobDto(job, items)
+}
+
+func (impl BulkUpdateServiceImpl) GetBulkUpdateJobs() ([]*BulkUpdateJobDto, error) {
+	jobs, err := impl.bulkUpdateJobRepository.FindRecentJobs(100)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting bulk update jobs", "err", err)
+		return nil, err
+	}
+	jobDtos := make([]*BulkUpdateJobDto, 0, len(jobs))
+	for _, job := range jobs {
+		jobDto, err := impl.toBulkUpdateJobDto(job, nil)
+		if err != nil {
+			return nil, err
+		}
+		//response of every job can be large, it is returned only by GetBulkUpdateJob
+		jobDto.Response = nil
+		jobDto.CiResponse = nil
+		jobDtos = append(jobDtos, jobDto)
+	}
+	return jobDtos, nil
+}
+
+func (impl BulkUpdateServiceImpl) toBulkUpdateJobDto(job *bulkUpdate.BulkUpdateJob, items []*bulkUpdate.BulkUpdateJobItem) (*BulkUpdateJobDto, error) {
+	jobDto := &BulkUpdateJobDto{
+		Id:        job.Id,
+		Kind:      job.Kind,
+		Status:    job.Status,
+		StartedOn: job.StartedOn,
+		CreatedBy: job.CreatedBy,
+		Items:     make([]*BulkUpdateJobItemDto, 0, len(items)),
+	}
+	if !job.FinishedOn.IsZero() {
+		jobDto.FinishedOn = &job.FinishedOn
+	}
+	var payload, response interface{}
+	if job.Kind == bulkUpdate.BULK_UPDATE_JOB_KIND_CI_PIPELINE {
+		jobDto.CiPayload, jobDto.CiResponse = &CiBulkUpdatePayload{}, &CiBulkUpdateResponse{}
+		payload, response = jobDto.CiPayload, jobDto.CiResponse
+	} else {
+		jobDto.Payload, jobDto.Response = &BulkUpdatePayload{}, &BulkUpdateResponse{}
+		payload, response = jobDto.Payload, jobDto.Response
+	}
+	if len(job.Payload) > 0 {
+		if err := json.Unmarshal([]byte(job.Payload), payload); err != nil {
+			impl.logger.Errorw("error in unmarshaling bulk update job payload", "err", err, "jobId", job.Id)
+			return nil, err
+		}
+	}
+	if len(job.Response) > 0 {
+		if err := json.Unmarshal([]byte(job.Response), response); err != nil {
+			impl.logger.Errorw("error in unmarshaling bulk update job response", "err", err, "jobId", job.Id)
+			return nil, err
+		}
+	} else {
+		jobDto.Response, jobDto.CiResponse = nil, nil
+	}
+	for _, item := range items {
+		jobDto.Items = append(jobDto.Items, toBulkUpdateJobItemDto(item))
+	}
+	return jobDto, nil
+}
+
+func toBulkUpdateJobItemDto(item *bulkUpdate.BulkUpdateJobItem) *BulkUpdateJobItemDto {
+	return &BulkUpdateJobItemDto{
+		Id:         item.Id,
+		ObjectType: item.ObjectType,
+		AppId:      item.AppId,
+		AppName:    item.AppName,
+		EnvId:      item.EnvId,
+		Reverted:   item.Reverted,
+	}
+}
+
+func (impl BulkUpdateServiceImpl) RevertBulkUpdateJob(jobId int, userId int32) (*BulkUpdateRevertResponse, error) {
+	job, err := impl.bulkUpdateJobRepository.FindJobById(jobId)
+	if err == pg.ErrNoRows {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: fmt.Sprintf("bulk update job %d not found", jobId)}
+	} else if err != nil {
+		impl.logger.Errorw("error in getting bulk update job", "err", err, "jobId", jobId)
+		return nil, err
+	}
+	if job.Status == bulkUpdate.BULK_UPDATE_JOB_RUNNING || job.Status == bulkUpdate.BULK_UPDATE_JOB_REVERTED {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("bulk update job %d can not be reverted in status %s", jobId, job.Status)}
+	}
+	items, err := impl.bulkUpdateJobRepository.FindItemsByJobId(jobId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting bulk update job items", "err", err, "jobId", jobId)
+		return nil, err
+	}
+	response := &BulkUpdateRevertResponse{JobId: jobId}
+	//reverting latest change first, so that the state of an object changed twice ends as it was before the job
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		if item.Reverted {
+			continue
+		}
+		err = impl.revertBulkUpdateJobItem(item, userId)
+		if err != nil {
+			impl.logger.Errorw("error in reverting bulk update job item", "err", err, "jobId", jobId, "itemId", item.Id)
+			response.Failure = append(response.Failure, &BulkUpdateRevertItem{BulkUpdateJobItemDto: toBulkUpdateJobItemDto(item), Message: err.Error()})
+			continue
+		}
+		item.Reverted = true
+		response.Successful = append(response.Successful, &BulkUpdateRevertItem{BulkUpdateJobItemDto: toBulkUpdateJobItemDto(item), Message: "Reverted Successfully"})
+	}
+	job.Status = bulkUpdate.BULK_UPDATE_JOB_REVERTED
+	if len(response.Failure) > 0 {
+		job.Status = bulkUpdate.BULK_UPDATE_JOB_PARTIALLY_REVERTED
+	}
+	job.UpdatedOn = time.Now()
+	job.UpdatedBy = userId
+	err = impl.bulkUpdateJobRepository.UpdateJob(job)
+	if err != nil {
+		return nil, err
+	}
+	response.Status = job.Status
+	return response, nil
+}
+
+// revertBulkUpdateJobItem writes back the before state only if the object is still as the job left it, the object is
+// locked while checking its state so that an edit made meanwhile is not overwritten
+func (impl BulkUpdateServiceImpl) revertBulkUpdateJobItem(item *bulkUpdate.BulkUpdateJobItem, userId int32) error {
+	if item.ObjectType == bulkUpdate.BULK_UPDATE_OBJECT_CI_PIPELINE {
+		err := impl.revertCiPipeline(item, userId)
+		if err != nil {
+			return err
+		}
+		return impl.bulkUpdateJobRepository.MarkItemReverted(item.Id, userId, nil)
+	}
+	dbConnection := impl.bulkUpdateRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	createHistory, err := impl.revertBulkUpdateJobItemState(item, userId, tx)
+	if err != nil {
+		return err
+	}
+	err = impl.bulkUpdateJobRepository.MarkItemReverted(item.Id, userId, tx)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	createHistory()
+	return nil
+}
+
+// revertBulkUpdateJobItemState writes back the before state in tx, history of the object is to be created once tx is committed
+func (impl BulkUpdateServiceImpl) revertBulkUpdateJobItemState(item *bulkUpdate.BulkUpdateJobItem, userId int32, tx *pg.Tx) (createHistory func(), err error) {
+	modifiedErr := fmt.Errorf("%s %d is modified after the bulk update, not reverting it", item.ObjectType, item.ObjectId)
+	switch item.ObjectType {
+	case bulkUpdate.BULK_UPDATE_OBJECT_CHART:
+		chart, err := impl.bulkUpdateRepository.FindChartByIdForUpdate(item.ObjectId, tx)
+		if err != nil {
+			return nil, err
+		}
+		if chart.Values != item.AfterState {
+			return nil, modifiedErr
+		}
+		chart.Values = item.BeforeState
+		chart.GlobalOverride = item.BeforeState
+		err = impl.bulkUpdateRepository.UpdateColumnsWithTxn(chart, tx, "values_yaml", "global_override")
+		if err != nil {
+			return nil, err
+		}
+		return func() { impl.createChartHistory(chart) }, nil
+	case bulkUpdate.BULK_UPDATE_OBJECT_ENV_OVERRIDE:
+		chartEnv, err := impl.bulkUpdateRepository.FindChartEnvByIdForUpdate(item.ObjectId, tx)
+		if err != nil {
+			return nil, err
+		}
+		if chartEnv.EnvOverrideValues != item.AfterState {
+			return nil, modifiedErr
+		}
+		chartEnv.EnvOverrideValues = item.BeforeState
+		err = impl.bulkUpdateRepository.UpdateColumnsWithTxn(chartEnv, tx, "env_override_yaml")
+		if err != nil {
+			return nil, err
+		}
+		return func() { impl.createEnvOverrideHistory(chartEnv) }, nil
+	case bulkUpdate.BULK_UPDATE_OBJECT_CM_APP_LEVEL, bulkUpdate.BULK_UPDATE_OBJECT_SECRET_APP_LEVEL:
+		model, err := impl.bulkUpdateRepository.FindConfigMapAppModelByIdForUpdate(item.ObjectId, tx)
+		if err != nil {
+			return nil, err
+		}
+		model.UpdatedOn = time.Now()
+		model.UpdatedBy = userId
+		if item.IsSecret() {
+			if model.SecretData != item.AfterState {
+				return nil, modifiedErr
+			}
+			model.SecretData = item.BeforeState
+			err = impl.bulkUpdateRepository.UpdateColumnsWithTxn(model, tx, "secret_data", "updated_on", "updated_by")
+		} else {
+			if model.ConfigMapData != item.AfterState {
+				return nil, modifiedErr
+			}
+			model.ConfigMapData = item.BeforeState
+			err = impl.bulkUpdateRepository.UpdateColumnsWithTxn(model, tx, "config_map_data", "updated_on", "updated_by")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func() {
+			err := impl.configMapHistoryService.CreateHistoryFromAppLevelConfig(model, configTypeOfJobItem(item))
+			if err != nil {
+				impl.logger.Errorw("error in creating entry for configmap history", "err", err)
+			}
+		}, nil
+	case bulkUpdate.BULK_UPDATE_OBJECT_CM_ENV_LEVEL, bulkUpdate.BULK_UPDATE_OBJECT_SECRET_ENV_LEVEL:
+		model, err := impl.bulkUpdateRepository.FindConfigMapEnvModelByIdForUpdate(item.ObjectId, tx)
+		if err != nil {
+			return nil, err
+		}
+		model.UpdatedOn = time.Now()
+		model.UpdatedBy = userId
+		if item.IsSecret() {
+			if model.SecretData != item.AfterState {
+				return nil, modifiedErr
+			}
+			model.SecretData = item.BeforeState
+			err = impl.bulkUpdateRepository.UpdateColumnsWithTxn(model, tx, "secret_data", "updated_on", "updated_by")
+		} else {
+			if model.ConfigMapData != item.AfterState {
+				return nil, modifiedErr
+			}
+			model.ConfigMapData = item.BeforeState
+			err = impl.bulkUpdateRepository.UpdateColumnsWithTxn(model, tx, "config_map_data", "updated_on", "updated_by")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func() {
+			err := impl.configMapHistoryService.CreateHistoryFromEnvLevelConfig(model, configTypeOfJobItem(item))
+			if err != nil {
+				impl.logger.Errorw("error in creating entry for configmap history", "err", err)
+			}
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown bulk update object type %s", item.ObjectType)
+	}
+}
+
+func configTypeOfJobItem(item *bulkUpdate.BulkUpdateJobItem) repository4.ConfigType {
+	if item.IsSecret() {
+		return repository4.SECRET_TYPE
+	}
+	return repository4.CONFIGMAP_TYPE
+}
+
+// diffJson returns the changed leaves of two json documents as json pointers, arrays are compared index wise
+func diffJson(before string, after string, maskValues bool) ([]*BulkUpdateFieldChange, error) {
+	beforeLeaves, err := flattenJson(before)
+	if err != nil {
+		return nil, err
+	}
+	afterLeaves, err := flattenJson(after)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for path := range beforeLeaves {
+		paths = append(paths, path)
+	}
+	for path := range afterLeaves {
+		if _, ok := beforeLeaves[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	masked, _ := json.Marshal(bulkUpdateMaskedValue)
+	changes := make([]*BulkUpdateFieldChange, 0)
+	for _, path := range paths {
+		beforeValue, inBefore := beforeLeaves[path]
+		afterValue, inAfter := afterLeaves[path]
+		change := &BulkUpdateFieldChange{Path: path, Before: beforeValue, After: afterValue}
+		switch {
+		case !inBefore:
+			change.Operation = BULK_UPDATE_CHANGE_ADDED
+		case !inAfter:
+			change.Operation = BULK_UPDATE_CHANGE_REMOVED
+		case string(beforeValue) != string(afterValue):
+			change.Operation = BULK_UPDATE_CHANGE_MODIFIED
+		default:
+			continue
+		}
+		if maskValues {
+			if inBefore {
+				change.Before = masked
+			}
+			if inAfter {
+				change.After = masked
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func flattenJson(document string) (map[string]json.RawMessage, error) {
+	leaves := make(map[string]json.RawMessage)
+	if len(document) == 0 {
+		return leaves, nil
+	}
+	var value interface{}
+	err := json.Unmarshal([]byte(document), &value)
+	if err != nil {
+		return nil, err
+	}
+	err = flattenJsonValue("", value, leaves)
+	return leaves, err
+}
+
+func flattenJsonValue(path string, value interface{}, leaves map[string]json.RawMessage) error {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		if len(typedValue) > 0 {
+			for key, child := range typedValue {
+				key = strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+				if err := flattenJsonValue(path+"/"+key, child, leaves); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case []interface{}:
+		if len(typedValue) > 0 {
+			for i, child := range typedValue {
+				if err := flattenJsonValue(fmt.Sprintf("%s/%d", path, i), child, leaves); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	leaf, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	leaves[path] = leaf
+	return nil
+}
+
+func (impl BulkUpdateServiceImpl) BulkHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
+	pipelines, err := impl.findPipelinesForBulkAction(request)
 	if err != nil {
-		impl.logger.Errorw("error in fetching pipelines", "envId", request.EnvId, "err", err)
 		return nil, err
 	}
 	response := make(map[string]map[string]bool)
@@ -1035,6 +1691,45 @@ func (impl BulkUpdateServiceImpl) BulkHibernate(request *BulkApplicationForEnvir
 	return bulkOperationResponse, nil
 }
 
+// findPipelinesForBulkAction returns the pipelines of the env of request for the apps included, not excluded and
+// matching the selector of request
+func (impl BulkUpdateServiceImpl) findPipelinesForBulkAction(request *BulkApplicationForEnvironmentPayload) ([]*pipelineConfig.Pipeline, error) {
+	var pipelines []*pipelineConfig.Pipeline
+	var err error
+	if len(request.AppIdIncludes) > 0 {
+		pipelines, err = impl.pipelineRepository.FindActiveByInFilter(request.EnvId, request.AppIdIncludes)
+	} else if len(request.AppIdExcludes) > 0 {
+		pipelines, err = impl.pipelineRepository.FindActiveByNotFilter(request.EnvId, request.AppIdExcludes)
+	} else {
+		pipelines, err = impl.pipelineRepository.FindActiveByEnvId(request.EnvId)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in fetching pipelines", "envId", request.EnvId, "err", err)
+		return nil, err
+	}
+	if len(request.Selector) == 0 {
+		return pipelines, nil
+	}
+	selection, err := impl.resolveAppSelector(request.Selector)
+	if err != nil {
+		return nil, err
+	}
+	envIds, err := impl.filterEnvIdsBySelection(selection, []int{request.EnvId})
+	if err != nil {
+		return nil, err
+	}
+	var selectedPipelines []*pipelineConfig.Pipeline
+	if len(envIds) == 0 {
+		return selectedPipelines, nil
+	}
+	for _, pipeline := range pipelines {
+		if _, ok := selection.apps[pipeline.AppId]; ok {
+			selectedPipelines = append(selectedPipelines, pipeline)
+		}
+	}
+	return selectedPipelines, nil
+}
+
 func (impl BulkUpdateServiceImpl) buildHibernateUnHibernateRequestForHelmPipelines(pipeline *pipelineConfig.Pipeline) (*client.AppIdentifier, *openapi.HibernateRequest, error) {
 	appIdentifier := &client.AppIdentifier{
 		ClusterId:   pipeline.Environment.ClusterId,
@@ -1090,17 +1785,8 @@ func (impl BulkUpdateServiceImpl) buildHibernateUnHibernateRequestForHelmPipelin
 	return appIdentifier, hibernateRequest, nil
 }
 func (impl BulkUpdateServiceImpl) BulkUnHibernate(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
-	var pipelines []*pipelineConfig.Pipeline
-	var err error
-	if len(request.AppIdIncludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByInFilter(request.EnvId, request.AppIdIncludes)
-	} else if len(request.AppIdExcludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByNotFilter(request.EnvId, request.AppIdExcludes)
-	} else {
-		pipelines, err = impl.pipelineRepository.FindActiveByEnvId(request.EnvId)
-	}
+	pipelines, err := impl.findPipelinesForBulkAction(request)
 	if err != nil {
-		impl.logger.Errorw("error in fetching pipelines", "envId", request.EnvId, "err", err)
 		return nil, err
 	}
 	response := make(map[string]map[string]bool)
@@ -1160,17 +1846,8 @@ func (impl BulkUpdateServiceImpl) BulkUnHibernate(request *BulkApplicationForEnv
 	return bulkOperationResponse, nil
 }
 func (impl BulkUpdateServiceImpl) BulkDeploy(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
-	var pipelines []*pipelineConfig.Pipeline
-	var err error
-	if len(request.AppIdIncludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByInFilter(request.EnvId, request.AppIdIncludes)
-	} else if len(request.AppIdExcludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByNotFilter(request.EnvId, request.AppIdExcludes)
-	} else {
-		pipelines, err = impl.pipelineRepository.FindActiveByEnvId(request.EnvId)
-	}
+	pipelines, err := impl.findPipelinesForBulkAction(request)
 	if err != nil {
-		impl.logger.Errorw("error in fetching pipelines", "envId", request.EnvId, "err", err)
 		return nil, err
 	}
 	response := make(map[string]map[string]bool)
@@ -1243,17 +1920,8 @@ func (impl BulkUpdateServiceImpl) BulkDeploy(request *BulkApplicationForEnvironm
 }
 
 func (impl BulkUpdateServiceImpl) BulkBuildTrigger(request *BulkApplicationForEnvironmentPayload, ctx context.Context, w http.ResponseWriter, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
-	var pipelines []*pipelineConfig.Pipeline
-	var err error
-	if len(request.AppIdIncludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByInFilter(request.EnvId, request.AppIdIncludes)
-	} else if len(request.AppIdExcludes) > 0 {
-		pipelines, err = impl.pipelineRepository.FindActiveByNotFilter(request.EnvId, request.AppIdExcludes)
-	} else {
-		pipelines, err = impl.pipelineRepository.FindActiveByEnvId(request.EnvId)
-	}
+	pipelines, err := impl.findPipelinesForBulkAction(request)
 	if err != nil {
-		impl.logger.Errorw("error in fetching pipelines", "envId", request.EnvId, "err", err)
 		return nil, err
 	}
 
@@ -1349,10 +2017,74 @@ func (impl BulkUpdateServiceImpl) BulkBuildTrigger(request *BulkApplicationForEn
 	return bulkOperationResponse, nil
 }
 
+func (impl BulkUpdateServiceImpl) BulkRestart(request *BulkApplicationForEnvironmentPayload, ctx context.Context, token string, checkAuthForBulkActions func(token string, appObject string, envObject string) bool) (*BulkApplicationForEnvironmentResponse, error) {
+	pipelines, err := impl.findPipelinesForBulkAction(request)
+	if err != nil {
+		return nil, err
+	}
+	response := make(map[string]map[string]bool)
+	for _, pipeline := range pipelines {
+		appKey := fmt.Sprintf("%d_%s", pipeline.AppId, pipeline.App.AppName)
+		pipelineKey := fmt.Sprintf("%d_%s", pipeline.Id, pipeline.Name)
+		if _, ok := response[appKey]; !ok {
+			response[appKey] = make(map[string]bool)
+		}
+		response[appKey][pipelineKey] = false
+		appObject := impl.enforcerUtil.GetAppRBACNameByAppId(pipeline.AppId)
+		envObject := impl.enforcerUtil.GetEnvRBACNameByAppId(pipeline.AppId, pipeline.EnvironmentId)
+		if !checkAuthForBulkActions(token, appObject, envObject) {
+			//skip restart for the app if user does not have access on that
+			continue
+		}
+		err = impl.restartPipelineWorkloads(ctx, pipeline, request.UserId)
+		if err != nil {
+			impl.logger.Errorw("error in restarting application", "err", err, "pipelineId", pipeline.Id)
+			continue
+		}
+		response[appKey][pipelineKey] = true
+	}
+	bulkOperationResponse := &BulkApplicationForEnvironmentResponse{}
+	bulkOperationResponse.BulkApplicationForEnvironmentPayload = *request
+	bulkOperationResponse.Response = response
+	return bulkOperationResponse, nil
+}
+
+// restartPipelineWorkloads restarts the workloads which are hibernated for the pipeline through workload action service,
+// so that each restart is audited and shows in timeline of the app. Workloads are same for argo cd and helm
+// deployments as the workload name is derived from app and env name.
+func (impl BulkUpdateServiceImpl) restartPipelineWorkloads(ctx context.Context, pipeline *pipelineConfig.Pipeline, userId int32) error {
+	appIdentifier, workloadRequest, err := impl.buildHibernateUnHibernateRequestForHelmPipelines(pipeline)
+	if err != nil {
+		return err
+	}
+	if appIdentifier == nil || workloadRequest == nil || workloadRequest.Resources == nil {
+		return fmt.Errorf("restart is not supported for chart of app %s", pipeline.App.AppName)
+	}
+	restartRequest := &workloadAction.WorkloadActionRequest{AppId: pipeline.AppId, EnvId: pipeline.EnvironmentId, UserId: userId}
+	for _, workload := range *workloadRequest.Resources {
+		restartRequest.Resources = append(restartRequest.Resources, &workloadAction.WorkloadResource{
+			Group:   workload.GetGroup(),
+			Version: workload.GetVersion(),
+			Kind:    workload.GetKind(),
+			Name:    workload.GetName(),
+		})
+	}
+	response, err := impl.workloadActionService.RestartWorkloads(ctx, restartRequest)
+	if err != nil {
+		return err
+	}
+	for _, result := range response.Results {
+		if result.Status != workloadAction.WORKLOAD_ACTION_STATUS_SUCCEEDED {
+			return fmt.Errorf("error in restarting %s %s: %s", result.Kind, result.Name, result.Message)
+		}
+	}
+	return nil
+}
+
 func (impl BulkUpdateServiceImpl) GetBulkActionImpactedPipelinesAndWfs(dto *CdBulkActionRequestDto) ([]*pipelineConfig.Pipeline, []int, []int, error) {
 	var err error
-	if (len(dto.EnvIds) == 0 && len(dto.EnvNames) == 0) || ((len(dto.AppIds) == 0 && len(dto.AppNames) == 0) && (len(dto.ProjectIds) == 0 && len(dto.ProjectNames) == 0)) {
-		//invalid payload, envIds or envNames are must and at least one of appIds, appNames, projectIds, projectNames is must
+	if (len(dto.EnvIds) == 0 && len(dto.EnvNames) == 0) || ((len(dto.AppIds) == 0 && len(dto.AppNames) == 0) && (len(dto.ProjectIds) == 0 && len(dto.ProjectNames) == 0) && len(dto.Selector) == 0) {
+		//invalid payload, envIds or envNames are must and at least one of appIds, appNames, projectIds, projectNames, selector is must
 		return nil, nil, nil, &util.ApiError{Code: "400", HttpStatusCode: 400, UserMessage: "invalid payload, can not get pipelines for this filter"}
 	}
 	if len(dto.ProjectIds) > 0 || len(dto.ProjectNames) > 0 {
@@ -1366,7 +2098,7 @@ func (impl BulkUpdateServiceImpl) GetBulkActionImpactedPipelinesAndWfs(dto *CdBu
 	var impactedWfIds []int
 	var impactedPipelineIds []int
 	var impactedCiPipelineIds []int
-	if (len(dto.AppIds) > 0 || len(dto.AppNames) > 0) && (len(dto.EnvIds) > 0 || len(dto.EnvNames) > 0) {
+	if (len(dto.AppIds) > 0 || len(dto.AppNames) > 0 || len(dto.Selector) > 0) && (len(dto.EnvIds) > 0 || len(dto.EnvNames) > 0) {
 		if len(dto.AppNames) > 0 {
 			appIdsByNames, err := impl.appRepository.FindIdsByNames(dto.AppNames)
 			if err != nil {
@@ -1383,6 +2115,16 @@ func (impl BulkUpdateServiceImpl) GetBulkActionImpactedPipelinesAndWfs(dto *CdBu
 			}
 			dto.EnvIds = append(dto.EnvIds, envIdsByNames...)
 		}
+		if len(dto.Selector) > 0 {
+			restrictToAppIds := len(dto.AppIds) > 0 || len(dto.AppNames) > 0 || len(dto.ProjectIds) > 0 || len(dto.ProjectNames) > 0
+			dto.AppIds, dto.EnvIds, err = impl.applyAppSelectorOnAppAndEnvIds(dto.Selector, dto.AppIds, dto.EnvIds, restrictToAppIds)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if len(dto.AppIds) == 0 || len(dto.EnvIds) == 0 {
+				return nil, nil, nil, nil
+			}
+		}
 		if !dto.DeleteWfAndCiPipeline {
 			//getting pipeline IDs for app level deletion request
 			impactedPipelineIds, err = impl.pipelineRepository.FindIdsByAppIdsAndEnvironmentIds(dto.AppIds, dto.EnvIds)
@@ -1525,3 +2267,214 @@ func (impl BulkUpdateServiceImpl) PerformBulkDeleteActionOnCdPipelines(impactedP
 	return respDto, nil
 
 }
+
+// appSelection holds the apps matching the app level requirements of a selector, cluster requirements restrict the
+// environments of an operation instead
+type appSelection struct {
+	apps                map[int]*app.App
+	clusterRequirements []*AppSelectorRequirement
+}
+
+var errNoAppMatchesSelector = &util.ApiError{HttpStatusCode: http.StatusNotFound, UserMessage: "no app matches the selector"}
+
+func selectorErrorMessage(err error) string {
+	if apiErr, ok := err.(*util.ApiError); ok {
+		return fmt.Sprint(apiErr.UserMessage)
+	}
+	return fmt.Sprintf("Unable to resolve selector : %s", err.Error())
+}
+
+func (impl BulkUpdateServiceImpl) resolveAppSelector(selector string) (*appSelection, error) {
+	requirements, err := ParseAppSelector(selector)
+	if err != nil {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: err.Error(), InternalMessage: err.Error()}
+	}
+	selection := &appSelection{apps: make(map[int]*app.App)}
+	var appRequirements []*AppSelectorRequirement
+	hasLabelRequirement, hasChartVersionRequirement := false, false
+	for _, requirement := range requirements {
+		switch requirement.Key {
+		case SELECTOR_KEY_CLUSTER:
+			selection.clusterRequirements = append(selection.clusterRequirements, requirement)
+			continue
+		case SELECTOR_KEY_CHART_VERSION:
+			hasChartVersionRequirement = true
+		case SELECTOR_KEY_PROJECT:
+		default:
+			hasLabelRequirement = true
+		}
+		appRequirements = append(appRequirements, requirement)
+	}
+	apps, err := impl.appRepository.FindAllActiveAppsWithTeam()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting apps for selector", "err", err, "selector", selector)
+		return nil, err
+	}
+	labelsByAppId := make(map[int]map[string][]string)
+	if hasLabelRequirement {
+		labels, err := impl.appLabelRepository.FindAll()
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting app labels for selector", "err", err, "selector", selector)
+			return nil, err
+		}
+		for _, label := range labels {
+			if _, ok := labelsByAppId[label.AppId]; !ok {
+				labelsByAppId[label.AppId] = make(map[string][]string)
+			}
+			labelsByAppId[label.AppId][label.Key] = append(labelsByAppId[label.AppId][label.Key], label.Value)
+		}
+	}
+	chartVersionByAppId := make(map[int]string)
+	if hasChartVersionRequirement {
+		appIds := make([]int, 0, len(apps))
+		for _, app := range apps {
+			appIds = append(appIds, app.Id)
+		}
+		charts, err := impl.chartRepository.FindLatestChartsByAppIds(appIds)
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting latest charts for selector", "err", err, "selector", selector)
+			return nil, err
+		}
+		for _, chart := range charts {
+			chartVersionByAppId[chart.AppId] = chart.ChartVersion
+		}
+	}
+	for _, app := range apps {
+		var chartVersions []string
+		if chartVersion, ok := chartVersionByAppId[app.Id]; ok {
+			chartVersions = []string{chartVersion}
+		}
+		matches := true
+		for _, requirement := range appRequirements {
+			var values []string
+			switch requirement.Key {
+			case SELECTOR_KEY_PROJECT:
+				if app.Team.Id > 0 {
+					values = []string{app.Team.Name}
+				}
+			case SELECTOR_KEY_CHART_VERSION:
+				values = chartVersions
+			default:
+				values = labelsByAppId[app.Id][requirement.Key]
+			}
+			if !requirement.Matches(values) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			selection.apps[app.Id] = app
+		}
+	}
+	return selection, nil
+}
+
+func (impl BulkUpdateServiceImpl) FindAppsBySelector(selector string) ([]*app.App, error) {
+	selection, err := impl.resolveAppSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	apps := make([]*app.App, 0, len(selection.apps))
+	for _, app := range selection.apps {
+		apps = append(apps, app)
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].Id < apps[j].Id
+	})
+	return apps, nil
+}
+
+// filterEnvIdsBySelection returns the envs of envIds in the clusters matching the cluster requirements of selection
+func (impl BulkUpdateServiceImpl) filterEnvIdsBySelection(selection *appSelection, envIds []int) ([]int, error) {
+	if len(selection.clusterRequirements) == 0 {
+		return envIds, nil
+	}
+	var filteredEnvIds []int
+	for _, envId := range envIds {
+		env, err := impl.environmentRepository.FindById(envId)
+		if err == pg.ErrNoRows {
+			continue
+		} else if err != nil {
+			impl.logger.Errorw("error in getting env for selector", "err", err, "envId", envId)
+			return nil, err
+		}
+		var clusterNames []string
+		if env.Cluster != nil {
+			clusterNames = []string{env.Cluster.ClusterName}
+		}
+		matches := true
+		for _, requirement := range selection.clusterRequirements {
+			if !requirement.Matches(clusterNames) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			filteredEnvIds = append(filteredEnvIds, envId)
+		}
+	}
+	return filteredEnvIds, nil
+}
+
+// applyAppSelectorOnBulkUpdatePayload returns the payload with includes set to the names of the apps matching both the
+// selector and name patterns, and env ids restricted to the clusters of the selector
+func (impl BulkUpdateServiceImpl) applyAppSelectorOnBulkUpdatePayload(bulkUpdatePayload *BulkUpdatePayload) (*BulkUpdatePayload, error) {
+	if len(bulkUpdatePayload.Selector) == 0 {
+		return bulkUpdatePayload, nil
+	}
+	selection, err := impl.resolveAppSelector(bulkUpdatePayload.Selector)
+	if err != nil {
+		return nil, err
+	}
+	var appNames []string
+	for _, app := range selection.apps {
+		if bulkUpdatePayload.Includes != nil && len(bulkUpdatePayload.Includes.Names) > 0 && !likeMatchesAny(bulkUpdatePayload.Includes.Names, app.AppName) {
+			continue
+		}
+		if bulkUpdatePayload.Excludes != nil && likeMatchesAny(bulkUpdatePayload.Excludes.Names, app.AppName) {
+			continue
+		}
+		appNames = append(appNames, escapeLike(app.AppName))
+	}
+	if len(appNames) == 0 {
+		return nil, errNoAppMatchesSelector
+	}
+	sort.Strings(appNames)
+	envIds, err := impl.filterEnvIdsBySelection(selection, bulkUpdatePayload.EnvIds)
+	if err != nil {
+		return nil, err
+	}
+	selectedPayload := *bulkUpdatePayload
+	selectedPayload.Selector = ""
+	selectedPayload.Includes = &NameIncludesExcludes{Names: appNames}
+	selectedPayload.Excludes = nil
+	selectedPayload.EnvIds = envIds
+	return &selectedPayload, nil
+}
+
+// applyAppSelectorOnAppAndEnvIds restricts app ids and env ids to the selector, all apps matching the selector are
+// taken when app ids are not to be restricted to
+func (impl BulkUpdateServiceImpl) applyAppSelectorOnAppAndEnvIds(selector string, appIds []int, envIds []int, restrictToAppIds bool) ([]int, []int, error) {
+	selection, err := impl.resolveAppSelector(selector)
+	if err != nil {
+		return nil, nil, err
+	}
+	var selectedAppIds []int
+	if restrictToAppIds {
+		for _, appId := range appIds {
+			if _, ok := selection.apps[appId]; ok {
+				selectedAppIds = append(selectedAppIds, appId)
+			}
+		}
+	} else {
+		for appId := range selection.apps {
+			selectedAppIds = append(selectedAppIds, appId)
+		}
+		sort.Ints(selectedAppIds)
+	}
+	selectedEnvIds, err := impl.filterEnvIdsBySelection(selection, envIds)
+	if err != nil {
+		return nil, nil, err
+	}
+	return selectedAppIds, selectedEnvIds, nil
+}
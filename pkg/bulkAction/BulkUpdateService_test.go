@@ -0,0 +1,57 @@
+package bulkAction
+
+import (
+	"testing"
+)
+
+func TestDiffJson(t *testing.T) {
+	before := `{"replicaCount":1,"image":{"tag":"v1"},"env":[{"name":"A"}],"a/b":1,"removed":true}`
+	after := `{"replicaCount":2,"image":{"tag":"v1"},"env":[{"name":"A"},{"name":"B"}],"a/b":1,"resources":{}}`
+	changes, err := diffJson(before, after, false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []struct {
+		path      string
+		operation string
+		before    string
+		after     string
+	}{
+		{"/env/1/name", BULK_UPDATE_CHANGE_ADDED, "", `"B"`},
+		{"/removed", BULK_UPDATE_CHANGE_REMOVED, "true", ""},
+		{"/replicaCount", BULK_UPDATE_CHANGE_MODIFIED, "1", "2"},
+		{"/resources", BULK_UPDATE_CHANGE_ADDED, "", "{}"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d", len(expected), len(changes))
+	}
+	for i, change := range changes {
+		if change.Path != expected[i].path || change.Operation != expected[i].operation ||
+			string(change.Before) != expected[i].before || string(change.After) != expected[i].after {
+			t.Errorf("unexpected change %d: %s %s %s %s", i, change.Path, change.Operation, change.Before, change.After)
+		}
+	}
+}
+
+func TestDiffJsonMasksValues(t *testing.T) {
+	changes, err := diffJson(`{"data":{"password":"b2xk"}}`, `{"data":{"password":"bmV3"}}`, true)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != "/data/password" {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+	if string(changes[0].Before) != `"*****"` || string(changes[0].After) != `"*****"` {
+		t.Errorf("values are not masked: %s %s", changes[0].Before, changes[0].After)
+	}
+}
+
+func TestDiffJsonEscapesKeys(t *testing.T) {
+	changes, err := diffJson(`{"a/b":{"c~d":1}}`, `{"a/b":{"c~d":2}}`, false)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != "/a~1b/c~0d" {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+}
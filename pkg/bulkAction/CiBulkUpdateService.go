@@ -0,0 +1,488 @@
+package bulkAction
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/sql/repository/bulkUpdate"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	bean2 "github.com/devtron-labs/devtron/pkg/bean"
+	bean3 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	"github.com/devtron-labs/devtron/pkg/plugin"
+	"net/http"
+	"sort"
+)
+
+// ciBulkUpdateTarget is a ci pipeline selected by a ci bulk update along with its app
+type ciBulkUpdateTarget struct {
+	app        *app.App
+	ciPipeline *bean2.CiPipeline
+}
+
+// ciPipelineEditableState is the part of a ci pipeline which can be changed by a ci bulk update, diffs and revert checks
+// are computed on it so that unrelated fields like webhook urls do not show up as changes
+type ciPipelineEditableState struct {
+	CiMaterial               []*bean2.CiMaterial        `json:"ciMaterial"`
+	IsDockerConfigOverridden bool                       `json:"isDockerConfigOverridden"`
+	DockerConfigOverride     bean2.DockerConfigOverride `json:"dockerConfigOverride"`
+	PreBuildStage            *bean3.PipelineStageDto    `json:"preBuildStage"`
+	PostBuildStage           *bean3.PipelineStageDto    `json:"postBuildStage"`
+}
+
+func ciPipelineEditableStateJson(ciPipeline *bean2.CiPipeline) (string, error) {
+	state := &ciPipelineEditableState{
+		CiMaterial:               ciPipeline.CiMaterial,
+		IsDockerConfigOverridden: ciPipeline.IsDockerConfigOverridden,
+		DockerConfigOverride:     ciPipeline.DockerConfigOverride,
+		PreBuildStage:            ciPipeline.PreBuildStage,
+		PostBuildStage:           ciPipeline.PostBuildStage,
+	}
+	stateJson, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	return string(stateJson), nil
+}
+
+func validateCiBulkUpdatePayload(payload *CiBulkUpdatePayload) error {
+	if payload.Includes == nil || len(payload.Includes.Names) == 0 {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: "includes are required for ci bulk update"}
+	}
+	if payload.Material == nil && payload.BuildConfig == nil && payload.AddPluginStep == nil && payload.RemovePluginStep == nil {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: "no operation given for ci bulk update"}
+	}
+	for _, step := range []*CiPluginStepBulkUpdate{payload.AddPluginStep, payload.RemovePluginStep} {
+		if step != nil && step.StageType != repository.PIPELINE_STAGE_TYPE_PRE_CI && step.StageType != repository.PIPELINE_STAGE_TYPE_POST_CI {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("invalid stage type %s of plugin step", step.StageType)}
+		}
+	}
+	return nil
+}
+
+func (impl BulkUpdateServiceImpl) findCiBulkUpdateTargets(payload *CiBulkUpdatePayload) ([]*ciBulkUpdateTarget, error) {
+	err := validateCiBulkUpdatePayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	selection, err := impl.applyAppSelectorOnBulkUpdatePayload(&BulkUpdatePayload{Includes: payload.Includes, Excludes: payload.Excludes, Selector: payload.Selector})
+	if err == errNoAppMatchesSelector {
+		return nil, nil
+	} else if err != nil {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: selectorErrorMessage(err)}
+	}
+	var excludes []string
+	if selection.Excludes != nil {
+		excludes = selection.Excludes.Names
+	}
+	apps, err := impl.bulkUpdateRepository.FindAppsByAppNameSubstring(selection.Includes.Names, excludes)
+	if err != nil {
+		impl.logger.Errorw("error in fetching apps for ci bulk update", "err", err, "includes", selection.Includes)
+		return nil, err
+	}
+	var targets []*ciBulkUpdateTarget
+	for _, app := range apps {
+		ciConfig, err := impl.pipelineBuilder.GetCiPipeline(app.Id)
+		if apiErr, ok := err.(*util.ApiError); ok && apiErr.Code == "404" {
+			//app without ci template has no ci pipeline
+			continue
+		} else if err != nil {
+			impl.logger.Errorw("error in fetching ci pipelines of app", "err", err, "appId", app.Id)
+			return nil, err
+		}
+		for _, ciPipeline := range ciConfig.CiPipelines {
+			if ciPipeline.IsExternal || ciPipeline.ParentCiPipeline > 0 {
+				continue
+			}
+			fullCiPipeline, err := impl.pipelineBuilder.GetCiPipelineById(ciPipeline.Id)
+			if err != nil {
+				impl.logger.Errorw("error in fetching ci pipeline", "err", err, "ciPipelineId", ciPipeline.Id)
+				return nil, err
+			}
+			fullCiPipeline.AppId = app.Id
+			targets = append(targets, &ciBulkUpdateTarget{app: app, ciPipeline: fullCiPipeline})
+		}
+	}
+	return targets, nil
+}
+
+func (impl BulkUpdateServiceImpl) GetCiBulkUpdateImpactedPipelines(payload *CiBulkUpdatePayload) ([]*bean2.CiPipeline, error) {
+	targets, err := impl.findCiBulkUpdateTargets(payload)
+	if err != nil {
+		return nil, err
+	}
+	ciPipelines := make([]*bean2.CiPipeline, 0, len(targets))
+	for _, target := range targets {
+		ciPipelines = append(ciPipelines, target.ciPipeline)
+	}
+	return ciPipelines, nil
+}
+
+func (impl BulkUpdateServiceImpl) BulkUpdateCiPipelines(payload *CiBulkUpdatePayload, dryRun bool) (*CiBulkUpdateResponse, error) {
+	targets, err := impl.findCiBulkUpdateTargets(payload)
+	if err != nil {
+		return nil, err
+	}
+	response := &CiBulkUpdateResponse{DryRun: dryRun}
+	if len(targets) == 0 {
+		response.Message = append(response.Message, "No ci pipeline matches the given includes, excludes and selector")
+		return response, nil
+	}
+	var pluginDetail *plugin.PluginDetailDto
+	if payload.AddPluginStep != nil {
+		pluginDetail, err = impl.globalPluginService.GetPluginDetailById(payload.AddPluginStep.PluginId)
+		if err != nil {
+			impl.logger.Errorw("error in getting plugin detail", "err", err, "pluginId", payload.AddPluginStep.PluginId)
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("plugin %d not found", payload.AddPluginStep.PluginId)}
+		}
+	}
+	var job *bulkUpdate.BulkUpdateJob
+	if !dryRun {
+		job, err = impl.saveBulkUpdateJob(bulkUpdate.BULK_UPDATE_JOB_KIND_CI_PIPELINE, payload, payload.UserId)
+		if err != nil {
+			return nil, err
+		}
+		response.JobId = job.Id
+	}
+	for _, target := range targets {
+		result := &CiBulkUpdateResponseForOnePipeline{
+			AppId:          target.app.Id,
+			AppName:        target.app.AppName,
+			CiPipelineId:   target.ciPipeline.Id,
+			CiPipelineName: target.ciPipeline.Name,
+		}
+		err = impl.bulkUpdateCiPipeline(target, payload, pluginDetail, result, job)
+		if err != nil {
+			impl.logger.Errorw("error in ci bulk update", "err", err, "appId", target.app.Id, "ciPipelineId", target.ciPipeline.Id)
+			result.Message = err.Error()
+			response.Failure = append(response.Failure, result)
+			continue
+		}
+		response.Successful = append(response.Successful, result)
+	}
+	if len(response.Failure) > 0 {
+		response.Message = append(response.Message, fmt.Sprintf("Ci bulk update failed for %d pipelines", len(response.Failure)))
+	}
+	if job != nil {
+		impl.completeBulkUpdateJob(job, response)
+	}
+	return response, nil
+}
+
+func (impl BulkUpdateServiceImpl) bulkUpdateCiPipeline(target *ciBulkUpdateTarget, payload *CiBulkUpdatePayload, pluginDetail *plugin.PluginDetailDto,
+	result *CiBulkUpdateResponseForOnePipeline, job *bulkUpdate.BulkUpdateJob) error {
+	beforeJson, err := json.Marshal(target.ciPipeline)
+	if err != nil {
+		return err
+	}
+	beforeState, err := ciPipelineEditableStateJson(target.ciPipeline)
+	if err != nil {
+		return err
+	}
+	//working on a copy, target keeps the state before the update
+	modified := &bean2.CiPipeline{}
+	err = json.Unmarshal(beforeJson, modified)
+	if err != nil {
+		return err
+	}
+	if payload.Material != nil {
+		err = applyCiMaterialBulkUpdate(modified, payload.Material)
+		if err != nil {
+			return err
+		}
+	}
+	if payload.BuildConfig != nil {
+		err = impl.applyCiBuildConfigBulkUpdate(modified, payload.BuildConfig)
+		if err != nil {
+			return err
+		}
+	}
+	if payload.RemovePluginStep != nil {
+		removeCiPluginStep(modified, payload.RemovePluginStep)
+	}
+	if payload.AddPluginStep != nil {
+		err = addCiPluginStep(modified, payload.AddPluginStep, pluginDetail)
+		if err != nil {
+			return err
+		}
+	}
+	afterState, err := ciPipelineEditableStateJson(modified)
+	if err != nil {
+		return err
+	}
+	result.Changes, err = diffJson(beforeState, afterState, false)
+	if err != nil {
+		return err
+	}
+	if len(result.Changes) == 0 {
+		result.Message = "No change required"
+		return nil
+	}
+	if job == nil {
+		result.Message = "Will be updated"
+		return nil
+	}
+	_, err = impl.pipelineBuilder.PatchCiPipeline(&bean2.CiPatchRequest{
+		CiPipeline:    modified,
+		AppId:         target.app.Id,
+		Action:        bean2.UPDATE_SOURCE,
+		AppWorkflowId: modified.AppWorkflowId,
+		UserId:        payload.UserId,
+	})
+	if err != nil {
+		return err
+	}
+	result.Message = "Updated Successfully"
+	//reading back the pipeline, ids of created steps and overrides are needed for revert
+	updated, err := impl.pipelineBuilder.GetCiPipelineById(modified.Id)
+	if err != nil {
+		impl.logger.Errorw("error in fetching updated ci pipeline", "err", err, "ciPipelineId", modified.Id)
+		return nil
+	}
+	afterJson, err := json.Marshal(updated)
+	if err != nil {
+		return nil
+	}
+	execution := &bulkUpdateExecution{jobId: job.Id, userId: payload.UserId}
+	impl.recordBulkUpdateChange(execution, bulkUpdate.BULK_UPDATE_OBJECT_CI_PIPELINE, modified.Id, target.app, 0, string(beforeJson), string(afterJson))
+	return nil
+}
+
+func applyCiMaterialBulkUpdate(ciPipeline *bean2.CiPipeline, update *CiMaterialBulkUpdate) error {
+	if update.Source == nil {
+		return fmt.Errorf("source is required for material update")
+	}
+	matched := false
+	for _, material := range ciPipeline.CiMaterial {
+		if len(update.GitMaterialName) > 0 && material.GitMaterialName != update.GitMaterialName {
+			continue
+		}
+		matched = true
+		source := *update.Source
+		material.Source = &source
+		material.IsRegex = source.Type == pipelineConfig.SOURCE_TYPE_BRANCH_REGEX
+	}
+	if !matched {
+		return fmt.Errorf("git material %s not found in ci pipeline", update.GitMaterialName)
+	}
+	return nil
+}
+
+func (impl BulkUpdateServiceImpl) applyCiBuildConfigBulkUpdate(ciPipeline *bean2.CiPipeline, update *CiBuildConfigBulkUpdate) error {
+	if !ciPipeline.IsDockerConfigOverridden || ciPipeline.DockerConfigOverride.CiBuildConfig == nil {
+		template, err := impl.pipelineBuilder.GetCiPipeline(ciPipeline.AppId)
+		if err != nil {
+			impl.logger.Errorw("error in fetching ci template", "err", err, "appId", ciPipeline.AppId)
+			return err
+		}
+		ciPipeline.DockerConfigOverride = bean2.DockerConfigOverride{
+			DockerRegistry:   template.DockerRegistry,
+			DockerRepository: template.DockerRepository,
+			CiBuildConfig:    template.CiBuildConfig,
+		}
+		if ciPipeline.DockerConfigOverride.CiBuildConfig == nil {
+			return fmt.Errorf("build config of app is not found")
+		}
+		//override is a new entity for the pipeline
+		ciPipeline.DockerConfigOverride.CiBuildConfig.Id = 0
+	}
+	ciPipeline.IsDockerConfigOverridden = true
+	return applyCiBuildConfigOverride(&ciPipeline.DockerConfigOverride, update)
+}
+
+func applyCiBuildConfigOverride(override *bean2.DockerConfigOverride, update *CiBuildConfigBulkUpdate) error {
+	if len(update.DockerRegistry) > 0 {
+		override.DockerRegistry = update.DockerRegistry
+	}
+	if len(update.DockerRepository) > 0 {
+		override.DockerRepository = update.DockerRepository
+	}
+	buildConfig := override.CiBuildConfig
+	if len(update.CiBuildType) > 0 && update.CiBuildType != buildConfig.CiBuildType {
+		switch update.CiBuildType {
+		case bean3.BUILDPACK_BUILD_TYPE:
+			if update.BuildPackConfig == nil {
+				return fmt.Errorf("buildPackConfig is required for build type %s", update.CiBuildType)
+			}
+		case bean3.SELF_DOCKERFILE_BUILD_TYPE, bean3.MANAGED_DOCKERFILE_BUILD_TYPE:
+			if update.DockerBuildConfig == nil {
+				return fmt.Errorf("dockerBuildConfig is required for build type %s", update.CiBuildType)
+			}
+		case bean3.SKIP_BUILD_BUILD_TYPE:
+		default:
+			return fmt.Errorf("unknown build type %s", update.CiBuildType)
+		}
+		buildConfig.CiBuildType = update.CiBuildType
+		buildConfig.DockerBuildConfig = update.DockerBuildConfig
+		buildConfig.BuildPackConfig = update.BuildPackConfig
+	}
+	if update.Args != nil || update.DockerBuildOptions != nil {
+		if buildConfig.DockerBuildConfig == nil {
+			return fmt.Errorf("docker build args and options can not be changed for build type %s", buildConfig.CiBuildType)
+		}
+		buildConfig.DockerBuildConfig.Args = applyKeyValueBulkUpdate(buildConfig.DockerBuildConfig.Args, update.Args)
+		buildConfig.DockerBuildConfig.DockerBuildOptions = applyKeyValueBulkUpdate(buildConfig.DockerBuildConfig.DockerBuildOptions, update.DockerBuildOptions)
+	}
+	return nil
+}
+
+func applyKeyValueBulkUpdate(values map[string]string, update *KeyValueBulkUpdate) map[string]string {
+	if update == nil {
+		return values
+	}
+	if values == nil && len(update.Set) > 0 {
+		values = make(map[string]string)
+	}
+	for key, value := range update.Set {
+		values[key] = value
+	}
+	for _, key := range update.Remove {
+		delete(values, key)
+	}
+	return values
+}
+
+func ciStageOfType(ciPipeline *bean2.CiPipeline, stageType repository.PipelineStageType, create bool) *bean3.PipelineStageDto {
+	stage := &ciPipeline.PreBuildStage
+	if stageType == repository.PIPELINE_STAGE_TYPE_POST_CI {
+		stage = &ciPipeline.PostBuildStage
+	}
+	if *stage == nil && create {
+		*stage = &bean3.PipelineStageDto{Type: stageType}
+	}
+	return *stage
+}
+
+func findPluginStep(stage *bean3.PipelineStageDto, pluginId int) int {
+	for i, step := range stage.Steps {
+		if step.StepType == repository.PIPELINE_STEP_TYPE_REF_PLUGIN && step.RefPluginStepDetail != nil && step.RefPluginStepDetail.PluginId == pluginId {
+			return i
+		}
+	}
+	return -1
+}
+
+// addCiPluginStep appends the plugin as the last step of the stage, pipelines already having the plugin in stage are left as they are
+func addCiPluginStep(ciPipeline *bean2.CiPipeline, update *CiPluginStepBulkUpdate, pluginDetail *plugin.PluginDetailDto) error {
+	stage := ciStageOfType(ciPipeline, update.StageType, true)
+	if findPluginStep(stage, update.PluginId) >= 0 {
+		return nil
+	}
+	inputValues := make(map[string]string, len(update.InputVariables))
+	for name, value := range update.InputVariables {
+		inputValues[name] = value
+	}
+	var inputVariables []*bean3.StepVariableDto
+	for _, variable := range pluginDetail.InputVariables {
+		value, ok := inputValues[variable.Name]
+		if !ok {
+			value = variable.DefaultValue
+		}
+		delete(inputValues, variable.Name)
+		if len(value) == 0 && !variable.AllowEmptyValue {
+			return fmt.Errorf("value of input variable %s of plugin is required", variable.Name)
+		}
+		inputVariables = append(inputVariables, &bean3.StepVariableDto{
+			Name:                      variable.Name,
+			Format:                    repository.PipelineStageStepVariableFormatType(variable.Format),
+			Description:               variable.Description,
+			IsExposed:                 variable.IsExposed,
+			AllowEmptyValue:           variable.AllowEmptyValue,
+			DefaultValue:              variable.DefaultValue,
+			Value:                     value,
+			ValueType:                 repository.PIPELINE_STAGE_STEP_VARIABLE_VALUE_TYPE_NEW,
+			VariableStepIndexInPlugin: variable.VariableStepIndex,
+		})
+	}
+	if len(inputValues) > 0 {
+		var unknown []string
+		for name := range inputValues {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		return fmt.Errorf("plugin %s has no input variables %v", pluginDetail.Metadata.Name, unknown)
+	}
+	name := update.Name
+	if len(name) == 0 {
+		name = pluginDetail.Metadata.Name
+	}
+	stage.Steps = append(stage.Steps, &bean3.PipelineStageStepDto{
+		Name:     name,
+		Index:    len(stage.Steps) + 1,
+		StepType: repository.PIPELINE_STEP_TYPE_REF_PLUGIN,
+		RefPluginStepDetail: &bean3.RefPluginStepDetailDto{
+			PluginId:       update.PluginId,
+			InputVariables: inputVariables,
+		},
+	})
+	return nil
+}
+
+// removeCiPluginStep removes the steps of the plugin from stage and reindexes the steps after them
+func removeCiPluginStep(ciPipeline *bean2.CiPipeline, update *CiPluginStepBulkUpdate) {
+	stage := ciStageOfType(ciPipeline, update.StageType, false)
+	if stage == nil {
+		return
+	}
+	for i := findPluginStep(stage, update.PluginId); i >= 0; i = findPluginStep(stage, update.PluginId) {
+		stage.Steps = append(stage.Steps[:i], stage.Steps[i+1:]...)
+	}
+	for i, step := range stage.Steps {
+		step.Index = i + 1
+	}
+}
+
+// revertCiPipeline patches the ci pipeline back to its state before the job, if it is not changed after the job
+// stageForCiPipelineRevert returns the stage to be sent for reverting a ci pipeline, a stage which did not exist before
+// the job is left untouched by a nil stage on update, so it is sent without steps to remove the steps added by the job
+func stageForCiPipelineRevert(before *bean3.PipelineStageDto, current *bean3.PipelineStageDto) *bean3.PipelineStageDto {
+	if before != nil || current == nil {
+		return before
+	}
+	return &bean3.PipelineStageDto{
+		Id:          current.Id,
+		Name:        current.Name,
+		Description: current.Description,
+		Type:        current.Type,
+		Steps:       []*bean3.PipelineStageStepDto{},
+	}
+}
+
+func (impl BulkUpdateServiceImpl) revertCiPipeline(item *bulkUpdate.BulkUpdateJobItem, userId int32) error {
+	current, err := impl.pipelineBuilder.GetCiPipelineById(item.ObjectId)
+	if err != nil {
+		return err
+	}
+	before, after := &bean2.CiPipeline{}, &bean2.CiPipeline{}
+	err = json.Unmarshal([]byte(item.BeforeState), before)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal([]byte(item.AfterState), after)
+	if err != nil {
+		return err
+	}
+	currentState, err := ciPipelineEditableStateJson(current)
+	if err != nil {
+		return err
+	}
+	afterState, err := ciPipelineEditableStateJson(after)
+	if err != nil {
+		return err
+	}
+	if currentState != afterState {
+		return fmt.Errorf("%s %d is modified after the bulk update, not reverting it", item.ObjectType, item.ObjectId)
+	}
+	before.AppWorkflowId = current.AppWorkflowId
+	before.PreBuildStage = stageForCiPipelineRevert(before.PreBuildStage, current.PreBuildStage)
+	before.PostBuildStage = stageForCiPipelineRevert(before.PostBuildStage, current.PostBuildStage)
+	_, err = impl.pipelineBuilder.PatchCiPipeline(&bean2.CiPatchRequest{
+		CiPipeline:    before,
+		AppId:         item.AppId,
+		Action:        bean2.UPDATE_SOURCE,
+		AppWorkflowId: current.AppWorkflowId,
+		UserId:        userId,
+	})
+	return err
+}
@@ -0,0 +1,96 @@
+package bulkAction
+
+import (
+	bean2 "github.com/devtron-labs/devtron/pkg/bean"
+	bean3 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	"github.com/devtron-labs/devtron/pkg/plugin"
+	"reflect"
+	"testing"
+)
+
+func TestApplyKeyValueBulkUpdate(t *testing.T) {
+	values := applyKeyValueBulkUpdate(map[string]string{"A": "1", "B": "2"}, &KeyValueBulkUpdate{Set: map[string]string{"A": "3", "C": "4"}, Remove: []string{"B"}})
+	if !reflect.DeepEqual(values, map[string]string{"A": "3", "C": "4"}) {
+		t.Errorf("unexpected values %v", values)
+	}
+	values = applyKeyValueBulkUpdate(nil, &KeyValueBulkUpdate{Set: map[string]string{"A": "1"}})
+	if !reflect.DeepEqual(values, map[string]string{"A": "1"}) {
+		t.Errorf("unexpected values %v", values)
+	}
+}
+
+func TestApplyCiBuildConfigOverride(t *testing.T) {
+	override := &bean2.DockerConfigOverride{CiBuildConfig: &bean3.CiBuildConfigBean{CiBuildType: bean3.BUILDPACK_BUILD_TYPE}}
+	err := applyCiBuildConfigOverride(override, &CiBuildConfigBulkUpdate{CiBuildType: bean3.SELF_DOCKERFILE_BUILD_TYPE})
+	if err == nil {
+		t.Errorf("expected error on switching to dockerfile build without dockerBuildConfig")
+	}
+	err = applyCiBuildConfigOverride(override, &CiBuildConfigBulkUpdate{
+		DockerRepository:  "apps/payments",
+		CiBuildType:       bean3.SELF_DOCKERFILE_BUILD_TYPE,
+		DockerBuildConfig: &bean3.DockerBuildConfig{DockerfilePath: "./Dockerfile"},
+		Args:              &KeyValueBulkUpdate{Set: map[string]string{"GO_VERSION": "1.19"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if override.DockerRepository != "apps/payments" || override.CiBuildConfig.CiBuildType != bean3.SELF_DOCKERFILE_BUILD_TYPE ||
+		override.CiBuildConfig.DockerBuildConfig.Args["GO_VERSION"] != "1.19" {
+		t.Errorf("unexpected override %+v", override.CiBuildConfig)
+	}
+}
+
+func TestAddAndRemoveCiPluginStep(t *testing.T) {
+	pluginDetail := &plugin.PluginDetailDto{
+		Metadata: &plugin.PluginMetadataDto{Id: 5, Name: "Sonarqube"},
+		InputVariables: []*plugin.PluginVariableDto{
+			{Name: "SONAR_URL"},
+			{Name: "SONAR_SCANNER", DefaultValue: "default"},
+		},
+	}
+	ciPipeline := &bean2.CiPipeline{PreBuildStage: &bean3.PipelineStageDto{Steps: []*bean3.PipelineStageStepDto{{Name: "script", Index: 1}}}}
+	update := &CiPluginStepBulkUpdate{StageType: repository.PIPELINE_STAGE_TYPE_PRE_CI, PluginId: 5}
+	if err := addCiPluginStep(ciPipeline, update, pluginDetail); err == nil {
+		t.Errorf("expected error for missing required input variable")
+	}
+	update.InputVariables = map[string]string{"SONAR_URL": "http://sonar", "UNKNOWN": "x"}
+	if err := addCiPluginStep(ciPipeline, update, pluginDetail); err == nil {
+		t.Errorf("expected error for unknown input variable")
+	}
+	delete(update.InputVariables, "UNKNOWN")
+	if err := addCiPluginStep(ciPipeline, update, pluginDetail); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	//adding again is a no op
+	if err := addCiPluginStep(ciPipeline, update, pluginDetail); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	steps := ciPipeline.PreBuildStage.Steps
+	if len(steps) != 2 || steps[1].Index != 2 || steps[1].Name != "Sonarqube" || steps[1].RefPluginStepDetail.InputVariables[1].Value != "default" {
+		t.Fatalf("unexpected steps %+v", steps)
+	}
+
+	ciPipeline.PreBuildStage.Steps = append([]*bean3.PipelineStageStepDto{steps[1]}, steps[0])
+	removeCiPluginStep(ciPipeline, update)
+	steps = ciPipeline.PreBuildStage.Steps
+	if len(steps) != 1 || steps[0].Name != "script" || steps[0].Index != 1 {
+		t.Errorf("unexpected steps after removal %+v", steps)
+	}
+}
+
+func TestStageForCiPipelineRevert(t *testing.T) {
+	current := &bean3.PipelineStageDto{Id: 7, Name: "pre-ci", Type: repository.PIPELINE_STAGE_TYPE_PRE_CI, Steps: []*bean3.PipelineStageStepDto{{Name: "Sonarqube", Index: 1}}}
+	//stage created by the job for a pipeline without pre build stage is emptied
+	stage := stageForCiPipelineRevert(nil, current)
+	if stage == nil || stage.Id != 7 || stage.Name != "pre-ci" || stage.Steps == nil || len(stage.Steps) != 0 {
+		t.Errorf("unexpected stage for revert %+v", stage)
+	}
+	before := &bean3.PipelineStageDto{Id: 7, Steps: []*bean3.PipelineStageStepDto{{Name: "script", Index: 1}}}
+	if stage = stageForCiPipelineRevert(before, current); stage != before {
+		t.Errorf("expected stage before the job to be reverted to, got %+v", stage)
+	}
+	if stage = stageForCiPipelineRevert(nil, nil); stage != nil {
+		t.Errorf("expected no stage when none exists, got %+v", stage)
+	}
+}
@@ -1,5 +1,13 @@
 package bulkAction
 
+import (
+	"encoding/json"
+	bean2 "github.com/devtron-labs/devtron/pkg/bean"
+	bean3 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/pkg/pipeline/repository"
+	"time"
+)
+
 type NameIncludesExcludes struct {
 	Names []string `json:"names"`
 }
@@ -21,10 +29,12 @@ type BulkUpdatePayload struct {
 	Includes           *NameIncludesExcludes   `json:"includes"`
 	Excludes           *NameIncludesExcludes   `json:"excludes"`
 	EnvIds             []int                   `json:"envIds"`
+	Selector           string                  `json:"selector,omitempty"`
 	Global             bool                    `json:"global"`
 	DeploymentTemplate *DeploymentTemplateTask `json:"deploymentTemplate"`
 	ConfigMap          *CmAndSecretTask        `json:"configMap"`
 	Secret             *CmAndSecretTask        `json:"secret"`
+	UserId             int32                   `json:"-"`
 }
 type BulkUpdateScript struct {
 	ApiVersion string             `json:"apiVersion" validate:"required"`
@@ -69,6 +79,138 @@ type BulkUpdateResponse struct {
 	DeploymentTemplate *DeploymentTemplateBulkUpdateResponse `json:"deploymentTemplate"`
 	ConfigMap          *CmAndSecretBulkUpdateResponse        `json:"configMap"`
 	Secret             *CmAndSecretBulkUpdateResponse        `json:"secret"`
+	JobId              int                                   `json:"jobId,omitempty"`
+	DryRun             bool                                  `json:"dryRun,omitempty"`
+	Diff               []*BulkUpdateDiffForOneApp            `json:"diff,omitempty"`
+}
+
+const (
+	BULK_UPDATE_DIFF_DEPLOYMENT_TEMPLATE = "DEPLOYMENT_TEMPLATE"
+	BULK_UPDATE_DIFF_CONFIGMAP           = "CONFIGMAP"
+	BULK_UPDATE_DIFF_SECRET              = "SECRET"
+)
+
+const (
+	BULK_UPDATE_CHANGE_ADDED    = "ADDED"
+	BULK_UPDATE_CHANGE_REMOVED  = "REMOVED"
+	BULK_UPDATE_CHANGE_MODIFIED = "MODIFIED"
+)
+
+// BulkUpdateDiffForOneApp is the change a bulk update makes to one deployment template, configmap or secret json of an app
+type BulkUpdateDiffForOneApp struct {
+	AppId   int                      `json:"appId"`
+	AppName string                   `json:"appName"`
+	EnvId   int                      `json:"envId"`
+	Type    string                   `json:"type"`
+	Changes []*BulkUpdateFieldChange `json:"changes"`
+}
+
+// BulkUpdateFieldChange is a changed leaf of the json, path is a json pointer. Values of secrets are masked
+type BulkUpdateFieldChange struct {
+	Path      string          `json:"path"`
+	Operation string          `json:"operation"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+}
+
+type BulkUpdateJobDto struct {
+	Id         int                     `json:"id"`
+	Kind       string                  `json:"kind"`
+	Status     string                  `json:"status"`
+	Payload    *BulkUpdatePayload      `json:"payload,omitempty"`
+	Response   *BulkUpdateResponse     `json:"response,omitempty"`
+	CiPayload  *CiBulkUpdatePayload    `json:"ciPayload,omitempty"`
+	CiResponse *CiBulkUpdateResponse   `json:"ciResponse,omitempty"`
+	Items      []*BulkUpdateJobItemDto `json:"items"`
+	StartedOn  time.Time               `json:"startedOn"`
+	FinishedOn *time.Time              `json:"finishedOn,omitempty"`
+	CreatedBy  int32                   `json:"createdBy"`
+}
+
+type BulkUpdateJobItemDto struct {
+	Id         int    `json:"id"`
+	ObjectType string `json:"objectType"`
+	AppId      int    `json:"appId"`
+	AppName    string `json:"appName"`
+	EnvId      int    `json:"envId"`
+	Reverted   bool   `json:"reverted"`
+}
+
+type BulkUpdateRevertResponse struct {
+	JobId      int                     `json:"jobId"`
+	Status     string                  `json:"status"`
+	Successful []*BulkUpdateRevertItem `json:"successful"`
+	Failure    []*BulkUpdateRevertItem `json:"failure"`
+}
+
+type BulkUpdateRevertItem struct {
+	*BulkUpdateJobItemDto
+	Message string `json:"message"`
+}
+
+type CiBulkUpdateScript struct {
+	ApiVersion string               `json:"apiVersion" validate:"required"`
+	Kind       string               `json:"kind" validate:"required"`
+	Spec       *CiBulkUpdatePayload `json:"spec" validate:"required"`
+}
+
+// CiBulkUpdatePayload selects ci pipelines by the app name patterns and selector, every operation given is applied on each
+// of them. Linked and external ci pipelines are not selected
+type CiBulkUpdatePayload struct {
+	Includes         *NameIncludesExcludes    `json:"includes"`
+	Excludes         *NameIncludesExcludes    `json:"excludes"`
+	Selector         string                   `json:"selector,omitempty"`
+	Material         *CiMaterialBulkUpdate    `json:"material,omitempty"`
+	BuildConfig      *CiBuildConfigBulkUpdate `json:"buildConfig,omitempty"`
+	AddPluginStep    *CiPluginStepBulkUpdate  `json:"addPluginStep,omitempty"`
+	RemovePluginStep *CiPluginStepBulkUpdate  `json:"removePluginStep,omitempty"`
+	UserId           int32                    `json:"-"`
+}
+
+type CiMaterialBulkUpdate struct {
+	GitMaterialName string                  `json:"gitMaterialName,omitempty"` //all materials of pipeline are changed when empty
+	Source          *bean2.SourceTypeConfig `json:"source" validate:"required"`
+}
+
+// CiBuildConfigBulkUpdate changes the docker config override of pipeline, override is created from the app ci template
+// for pipelines not having one
+type CiBuildConfigBulkUpdate struct {
+	DockerRegistry     string                   `json:"dockerRegistry,omitempty"`
+	DockerRepository   string                   `json:"dockerRepository,omitempty"`
+	CiBuildType        bean3.CiBuildType        `json:"ciBuildType,omitempty"`
+	DockerBuildConfig  *bean3.DockerBuildConfig `json:"dockerBuildConfig,omitempty"` //used on switching to a dockerfile build type
+	BuildPackConfig    *bean3.BuildPackConfig   `json:"buildPackConfig,omitempty"`   //used on switching to buildpack build type
+	Args               *KeyValueBulkUpdate      `json:"args,omitempty"`
+	DockerBuildOptions *KeyValueBulkUpdate      `json:"dockerBuildOptions,omitempty"`
+}
+
+type KeyValueBulkUpdate struct {
+	Set    map[string]string `json:"set,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+type CiPluginStepBulkUpdate struct {
+	StageType      repository.PipelineStageType `json:"stageType" validate:"oneof=PRE_CI POST_CI"`
+	PluginId       int                          `json:"pluginId" validate:"required"`
+	Name           string                       `json:"name,omitempty"`
+	InputVariables map[string]string            `json:"inputVariables,omitempty"`
+}
+
+type CiBulkUpdateResponse struct {
+	JobId      int                                   `json:"jobId,omitempty"`
+	DryRun     bool                                  `json:"dryRun,omitempty"`
+	Message    []string                              `json:"message"`
+	Failure    []*CiBulkUpdateResponseForOnePipeline `json:"failure"`
+	Successful []*CiBulkUpdateResponseForOnePipeline `json:"successful"`
+}
+
+type CiBulkUpdateResponseForOnePipeline struct {
+	AppId          int                      `json:"appId"`
+	AppName        string                   `json:"appName"`
+	CiPipelineId   int                      `json:"ciPipelineId"`
+	CiPipelineName string                   `json:"ciPipelineName"`
+	Message        string                   `json:"message"`
+	Changes        []*BulkUpdateFieldChange `json:"changes,omitempty"`
 }
 type DeploymentTemplateBulkUpdateResponse struct {
 	Message    []string                                         `json:"message"`
@@ -82,10 +224,11 @@ type CmAndSecretBulkUpdateResponse struct {
 }
 
 type BulkApplicationForEnvironmentPayload struct {
-	AppIdIncludes []int `json:"appIdIncludes,omitempty"`
-	AppIdExcludes []int `json:"appIdExcludes,omitempty"`
-	EnvId         int   `json:"envId"`
-	UserId        int32 `json:"-"`
+	AppIdIncludes []int  `json:"appIdIncludes,omitempty"`
+	AppIdExcludes []int  `json:"appIdExcludes,omitempty"`
+	Selector      string `json:"selector,omitempty"`
+	EnvId         int    `json:"envId"`
+	UserId        int32  `json:"-"`
 }
 
 type BulkApplicationForEnvironmentResponse struct {
@@ -107,6 +250,7 @@ type CdBulkActionRequestDto struct {
 	AppNames              []string     `json:"appNames"`
 	ProjectIds            []int        `json:"projectIds"`
 	ProjectNames          []string     `json:"projectNames"`
+	Selector              string       `json:"selector,omitempty"`
 	DeleteWfAndCiPipeline bool         `json:"deleteWfAndCiPipeline"`
 	ForceDelete           bool         `json:"forceDelete"`
 	UserId                int32        `json:"-"`
@@ -46,6 +46,7 @@ type ChartRepository interface {
 
 	FindActiveChartsByAppId(appId int) (charts []*Chart, err error)
 	FindLatestChartForAppByAppId(appId int) (chart *Chart, err error)
+	FindLatestChartsByAppIds(appIds []int) (charts []*Chart, err error)
 	FindChartByAppIdAndRefId(appId int, chartRefId int) (chart *Chart, err error)
 	FindNoLatestChartForAppByAppId(appId int) ([]*Chart, error)
 	FindPreviousChartByAppId(appId int) (chart *Chart, err error)
@@ -125,6 +126,18 @@ func (repositoryImpl ChartRepositoryImpl) FindLatestChartForAppByAppId(appId int
 	return chart, err
 }
 
+func (repositoryImpl ChartRepositoryImpl) FindLatestChartsByAppIds(appIds []int) (charts []*Chart, err error) {
+	if len(appIds) == 0 {
+		return charts, nil
+	}
+	err = repositoryImpl.dbConnection.
+		Model(&charts).
+		Where("app_id in (?)", pg.In(appIds)).
+		Where("latest= ?", true).
+		Select()
+	return charts, err
+}
+
 func (repositoryImpl ChartRepositoryImpl) FindChartByAppIdAndRefId(appId int, chartRefId int) (chart *Chart, err error) {
 	chart = &Chart{}
 	err = repositoryImpl.dbConnection.
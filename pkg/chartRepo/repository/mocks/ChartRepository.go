@@ -194,6 +194,29 @@ func (_m *ChartRepository) FindLatestChartForAppByAppId(appId int) (*chartRepoRe
 	return r0, r1
 }
 
+// FindLatestChartsByAppIds provides a mock function with given fields: appIds
+func (_m *ChartRepository) FindLatestChartsByAppIds(appIds []int) ([]*chartRepoRepository.Chart, error) {
+	ret := _m.Called(appIds)
+
+	var r0 []*chartRepoRepository.Chart
+	if rf, ok := ret.Get(0).(func([]int) []*chartRepoRepository.Chart); ok {
+		r0 = rf(appIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*chartRepoRepository.Chart)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]int) error); ok {
+		r1 = rf(appIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindNoLatestChartForAppByAppId provides a mock function with given fields: appId
 func (_m *ChartRepository) FindNoLatestChartForAppByAppId(appId int) ([]*chartRepoRepository.Chart, error) {
 	ret := _m.Called(appId)
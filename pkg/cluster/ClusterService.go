@@ -57,6 +57,51 @@ type ClusterBean struct {
 	K8sVersion              string                     `json:"k8sVersion"`
 	HasConfigOrUrlChanged   bool                       `json:"-"`
 	ErrorInConnecting       string                     `json:"errorInConnecting,omitempty"`
+	Health                  *ClusterHealthBean         `json:"health,omitempty"`
+}
+
+// ClusterHealthBean is the result of a periodic health check of the cluster
+type ClusterHealthBean struct {
+	Status          string     `json:"status"`
+	Reachable       bool       `json:"reachable"`
+	LatencyMs       int        `json:"latencyMs"`
+	ServerVersion   string     `json:"serverVersion,omitempty"`
+	CertExpiry      *time.Time `json:"certExpiry,omitempty"`
+	TokenExpiry     *time.Time `json:"tokenExpiry,omitempty"`
+	NodesTotal      int        `json:"nodesTotal"`
+	NodesReady      int        `json:"nodesReady"`
+	ArgoCdReachable bool       `json:"argoCdReachable"`
+	ArgoCdSkipped   bool       `json:"argoCdSkipped"`
+	ArgoCdMessage   string     `json:"argoCdMessage,omitempty"`
+	Message         string     `json:"message,omitempty"`
+	StatusChanged   bool       `json:"statusChanged"`
+	CheckedOn       time.Time  `json:"checkedOn"`
+}
+
+func NewClusterHealthBean(model *repository.ClusterHealthCheck) *ClusterHealthBean {
+	bean := &ClusterHealthBean{
+		Status:          model.Status,
+		Reachable:       model.Reachable,
+		LatencyMs:       model.LatencyMs,
+		ServerVersion:   model.ServerVersion,
+		NodesTotal:      model.NodesTotal,
+		NodesReady:      model.NodesReady,
+		ArgoCdReachable: model.ArgoCdReachable,
+		ArgoCdSkipped:   model.ArgoCdSkipped,
+		ArgoCdMessage:   model.ArgoCdMessage,
+		Message:         model.Message,
+		StatusChanged:   model.StatusChanged,
+		CheckedOn:       model.CheckedOn,
+	}
+	if !model.CertExpiry.IsZero() {
+		certExpiry := model.CertExpiry
+		bean.CertExpiry = &certExpiry
+	}
+	if !model.TokenExpiry.IsZero() {
+		tokenExpiry := model.TokenExpiry
+		bean.TokenExpiry = &tokenExpiry
+	}
+	return bean
 }
 
 type PrometheusAuth struct {
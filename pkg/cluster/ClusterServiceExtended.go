@@ -24,12 +24,13 @@ import (
 
 // extends ClusterServiceImpl and enhances method of ClusterService with full mode specific errors
 type ClusterServiceImplExtended struct {
-	environmentRepository  repository.EnvironmentRepository
-	grafanaClient          grafana.GrafanaClient
-	installedAppRepository repository2.InstalledAppRepository
-	clusterServiceCD       cluster2.ServiceClient
-	K8sInformerFactory     informer.K8sInformerFactory
-	gitOpsRepository       repository3.GitOpsConfigRepository
+	environmentRepository   repository.EnvironmentRepository
+	grafanaClient           grafana.GrafanaClient
+	installedAppRepository  repository2.InstalledAppRepository
+	clusterServiceCD        cluster2.ServiceClient
+	K8sInformerFactory      informer.K8sInformerFactory
+	gitOpsRepository        repository3.GitOpsConfigRepository
+	clusterHealthRepository repository.ClusterHealthRepository
 	*ClusterServiceImpl
 }
 
@@ -38,13 +39,15 @@ func NewClusterServiceImplExtended(repository repository.ClusterRepository, envi
 	K8sUtil *util.K8sUtil,
 	clusterServiceCD cluster2.ServiceClient, K8sInformerFactory informer.K8sInformerFactory,
 	gitOpsRepository repository3.GitOpsConfigRepository, userAuthRepository repository4.UserAuthRepository,
-	userRepository repository4.UserRepository, roleGroupRepository repository4.RoleGroupRepository) *ClusterServiceImplExtended {
+	userRepository repository4.UserRepository, roleGroupRepository repository4.RoleGroupRepository,
+	clusterHealthRepository repository.ClusterHealthRepository) *ClusterServiceImplExtended {
 	clusterServiceExt := &ClusterServiceImplExtended{
-		environmentRepository:  environmentRepository,
-		grafanaClient:          grafanaClient,
-		installedAppRepository: installedAppRepository,
-		clusterServiceCD:       clusterServiceCD,
-		gitOpsRepository:       gitOpsRepository,
+		environmentRepository:   environmentRepository,
+		grafanaClient:           grafanaClient,
+		installedAppRepository:  installedAppRepository,
+		clusterServiceCD:        clusterServiceCD,
+		gitOpsRepository:        gitOpsRepository,
+		clusterHealthRepository: clusterHealthRepository,
 		ClusterServiceImpl: &ClusterServiceImpl{
 			clusterRepository:   repository,
 			logger:              logger,
@@ -139,6 +142,20 @@ func (impl *ClusterServiceImplExtended) FindAll() ([]*ClusterBean, error) {
 		}
 		item.DefaultClusterComponent = defaultClusterComponents
 	}
+
+	healthChecks, err := impl.clusterHealthRepository.FindLatestByClusterIds(clusterIds)
+	if err != nil {
+		//health is informational, cluster list is returned without it
+		impl.logger.Errorw("error on fetching cluster health for cluster ids", "err", err, "clusterIds", clusterIds)
+		return beans, nil
+	}
+	healthMap := make(map[int]*ClusterHealthBean)
+	for _, healthCheck := range healthChecks {
+		healthMap[healthCheck.ClusterId] = NewClusterHealthBean(healthCheck)
+	}
+	for _, item := range beans {
+		item.Health = healthMap[item.Id]
+	}
 	return beans, nil
 }
 
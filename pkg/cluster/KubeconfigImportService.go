@@ -0,0 +1,404 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// auth type of the user of a kubeconfig context, only static tokens are stored as they are, every other type is used once
+// to create a service account whose token is stored instead
+const (
+	KUBECONFIG_AUTH_TOKEN         = "TOKEN"
+	KUBECONFIG_AUTH_CLIENT_CERT   = "CLIENT_CERT"
+	KUBECONFIG_AUTH_BASIC         = "BASIC"
+	KUBECONFIG_AUTH_AUTH_PROVIDER = "AUTH_PROVIDER"
+	KUBECONFIG_AUTH_EXEC          = "EXEC"
+)
+
+type KubeconfigImportConfig struct {
+	ServiceAccountNamespace string `env:"KUBECONFIG_IMPORT_SA_NAMESPACE" envDefault:"devtroncd"`
+	ServiceAccountName      string `env:"KUBECONFIG_IMPORT_SA_NAME" envDefault:"devtron-cluster-admin"`
+	TimeoutSecs             int    `env:"KUBECONFIG_IMPORT_TIMEOUT_SECS" envDefault:"10"`
+}
+
+type KubeconfigRequest struct {
+	Kubeconfig string `json:"kubeconfig" validate:"required"`
+}
+
+type KubeconfigContext struct {
+	ContextName            string   `json:"contextName"`
+	ClusterName            string   `json:"clusterName"` // suggested name of the cluster in devtron
+	ServerUrl              string   `json:"serverUrl"`
+	AuthType               string   `json:"authType"`
+	RequiresServiceAccount bool     `json:"requiresServiceAccount"`
+	Reachable              bool     `json:"reachable"`
+	K8sVersion             string   `json:"k8sVersion,omitempty"`
+	Namespaces             []string `json:"namespaces,omitempty"`
+	ExistingClusterName    string   `json:"existingClusterName,omitempty"` // devtron cluster already registered with this server
+	Error                  string   `json:"error,omitempty"`
+}
+
+type KubeconfigImportRequest struct {
+	Kubeconfig string                     `json:"kubeconfig" validate:"required"`
+	Contexts   []*KubeconfigContextImport `json:"contexts" validate:"required,min=1,dive"`
+}
+
+type KubeconfigContextImport struct {
+	ContextName    string   `json:"contextName" validate:"required"`
+	ClusterName    string   `json:"clusterName" validate:"required"`
+	PrometheusUrl  string   `json:"prometheusUrl,omitempty"`
+	BootstrapToken string   `json:"bootstrapToken,omitempty"` // used once in place of exec plugins, which are never run by devtron
+	Namespaces     []string `json:"namespaces,omitempty"`     // an environment is created for each namespace
+}
+
+type KubeconfigImportResponse struct {
+	ContextName           string                           `json:"contextName"`
+	ClusterName           string                           `json:"clusterName"`
+	ClusterId             int                              `json:"clusterId,omitempty"`
+	ServiceAccountCreated bool                             `json:"serviceAccountCreated"`
+	Environments          []*KubeconfigEnvironmentResponse `json:"environments,omitempty"`
+	Error                 string                           `json:"error,omitempty"`
+}
+
+type KubeconfigEnvironmentResponse struct {
+	EnvironmentName string `json:"environmentName"`
+	Namespace       string `json:"namespace"`
+	EnvironmentId   int    `json:"environmentId,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+type KubeconfigImportService interface {
+	GetKubeconfigContexts(request *KubeconfigRequest) ([]*KubeconfigContext, error)
+	ImportKubeconfig(ctx context.Context, request *KubeconfigImportRequest, userId int32) ([]*KubeconfigImportResponse, error)
+}
+
+type KubeconfigImportServiceImpl struct {
+	logger             *zap.SugaredLogger
+	clusterService     ClusterService
+	clusterRepository  repository.ClusterRepository
+	environmentService EnvironmentService
+	config             *KubeconfigImportConfig
+}
+
+func NewKubeconfigImportServiceImpl(logger *zap.SugaredLogger, clusterService ClusterService,
+	clusterRepository repository.ClusterRepository, environmentService EnvironmentService) (*KubeconfigImportServiceImpl, error) {
+	config := &KubeconfigImportConfig{}
+	err := env.Parse(config)
+	if err != nil {
+		logger.Errorw("error in parsing kubeconfig import config", "err", err)
+		return nil, err
+	}
+	return &KubeconfigImportServiceImpl{
+		logger:             logger,
+		clusterService:     clusterService,
+		clusterRepository:  clusterRepository,
+		environmentService: environmentService,
+		config:             config,
+	}, nil
+}
+
+func (impl *KubeconfigImportServiceImpl) GetKubeconfigContexts(request *KubeconfigRequest) ([]*KubeconfigContext, error) {
+	config, err := clientcmd.Load([]byte(request.Kubeconfig))
+	if err != nil {
+		impl.logger.Errorw("error in parsing kubeconfig", "err", err)
+		return nil, fmt.Errorf("invalid kubeconfig: %s", err.Error())
+	}
+	existingClusters, err := impl.getExistingClustersByServer()
+	if err != nil {
+		return nil, err
+	}
+	contexts := make([]*KubeconfigContext, 0, len(config.Contexts))
+	for _, contextName := range sortedContextNames(config) {
+		kubeContext := &KubeconfigContext{
+			ContextName: contextName,
+			ClusterName: kubeconfigClusterName(contextName),
+		}
+		contexts = append(contexts, kubeContext)
+		restConfig, authType, err := buildContextRestConfig(config, contextName, "")
+		kubeContext.AuthType = authType
+		kubeContext.RequiresServiceAccount = len(authType) > 0 && authType != KUBECONFIG_AUTH_TOKEN
+		if restConfig != nil {
+			kubeContext.ServerUrl = restConfig.Host
+			kubeContext.ExistingClusterName = existingClusters[normalizeServerUrl(restConfig.Host)]
+		}
+		if err != nil {
+			kubeContext.Error = err.Error()
+			continue
+		}
+		clientSet, err := impl.newClientSet(restConfig)
+		if err != nil {
+			kubeContext.Error = err.Error()
+			continue
+		}
+		serverVersion, err := clientSet.Discovery().ServerVersion()
+		if err != nil {
+			kubeContext.Error = err.Error()
+			continue
+		}
+		kubeContext.Reachable = true
+		kubeContext.K8sVersion = serverVersion.String()
+		namespaces, err := clientSet.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			//listing namespaces is only a convenience for picking environments
+			impl.logger.Warnw("error in listing namespaces of kubeconfig context", "err", err, "context", contextName)
+			continue
+		}
+		for _, namespace := range namespaces.Items {
+			kubeContext.Namespaces = append(kubeContext.Namespaces, namespace.Name)
+		}
+	}
+	return contexts, nil
+}
+
+// ImportKubeconfig onboards each requested context independently, failure of a context is reported in its response
+func (impl *KubeconfigImportServiceImpl) ImportKubeconfig(ctx context.Context, request *KubeconfigImportRequest, userId int32) ([]*KubeconfigImportResponse, error) {
+	config, err := clientcmd.Load([]byte(request.Kubeconfig))
+	if err != nil {
+		impl.logger.Errorw("error in parsing kubeconfig", "err", err)
+		return nil, fmt.Errorf("invalid kubeconfig: %s", err.Error())
+	}
+	responses := make([]*KubeconfigImportResponse, 0, len(request.Contexts))
+	for _, contextImport := range request.Contexts {
+		response := &KubeconfigImportResponse{ContextName: contextImport.ContextName, ClusterName: contextImport.ClusterName}
+		responses = append(responses, response)
+		err = impl.importContext(ctx, config, contextImport, response, userId)
+		if err != nil {
+			impl.logger.Errorw("error in importing kubeconfig context", "err", err, "context", contextImport.ContextName)
+			response.Error = err.Error()
+		}
+	}
+	return responses, nil
+}
+
+func (impl *KubeconfigImportServiceImpl) importContext(ctx context.Context, config *clientcmdapi.Config, contextImport *KubeconfigContextImport,
+	response *KubeconfigImportResponse, userId int32) error {
+	restConfig, authType, err := buildContextRestConfig(config, contextImport.ContextName, contextImport.BootstrapToken)
+	if err != nil {
+		return err
+	}
+	bearerToken := restConfig.BearerToken
+	if authType != KUBECONFIG_AUTH_TOKEN {
+		bearerToken, err = impl.createServiceAccountToken(restConfig)
+		if err != nil {
+			return fmt.Errorf("error in creating service account: %s", err.Error())
+		}
+		response.ServiceAccountCreated = true
+	}
+	clusterBean := &ClusterBean{
+		ClusterName:   contextImport.ClusterName,
+		ServerUrl:     restConfig.Host,
+		PrometheusUrl: contextImport.PrometheusUrl,
+		Active:        true,
+		Config:        map[string]string{"bearer_token": bearerToken},
+	}
+	clusterBean, err = impl.clusterService.Save(ctx, clusterBean, userId)
+	if err != nil {
+		return err
+	}
+	response.ClusterId = clusterBean.Id
+
+	for _, namespace := range contextImport.Namespaces {
+		environment := &EnvironmentBean{
+			Environment: kubeconfigEnvironmentName(contextImport.ClusterName, namespace),
+			ClusterId:   clusterBean.Id,
+			Namespace:   namespace,
+			Active:      true,
+		}
+		envResponse := &KubeconfigEnvironmentResponse{EnvironmentName: environment.Environment, Namespace: namespace}
+		response.Environments = append(response.Environments, envResponse)
+		environment, err = impl.environmentService.Create(environment, userId)
+		if err != nil {
+			impl.logger.Errorw("error in creating environment for namespace", "err", err, "clusterId", clusterBean.Id, "namespace", namespace)
+			envResponse.Error = err.Error()
+			continue
+		}
+		envResponse.EnvironmentId = environment.Id
+	}
+	return nil
+}
+
+// createServiceAccountToken creates a cluster admin service account with the kubeconfig credentials and returns its
+// long lived token, resources which already exist are reused
+func (impl *KubeconfigImportServiceImpl) createServiceAccountToken(restConfig *rest.Config) (string, error) {
+	clientSet, err := impl.newClientSet(restConfig)
+	if err != nil {
+		return "", err
+	}
+	ctx := context.Background()
+	namespace, name := impl.config.ServiceAccountNamespace, impl.config.ServiceAccountName
+
+	_, err = clientSet.CoreV1().Namespaces().Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return "", err
+	}
+	serviceAccount := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	_, err = clientSet.CoreV1().ServiceAccounts(namespace).Create(ctx, serviceAccount, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return "", err
+	}
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace}},
+	}
+	_, err = clientSet.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return "", err
+	}
+	//token secrets are not created for service accounts since k8s 1.24, so it is created explicitly
+	secretName := name + "-token"
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secretName,
+			Namespace:   namespace,
+			Annotations: map[string]string{v1.ServiceAccountNameKey: name},
+		},
+		Type: v1.SecretTypeServiceAccountToken,
+	}
+	_, err = clientSet.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return "", err
+	}
+	for i := 0; i < impl.config.TimeoutSecs; i++ {
+		secret, err = clientSet.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		if token := secret.Data[v1.ServiceAccountTokenKey]; len(token) > 0 {
+			return string(token), nil
+		}
+		time.Sleep(time.Second)
+	}
+	return "", fmt.Errorf("token of service account %s/%s was not populated", namespace, name)
+}
+
+func (impl *KubeconfigImportServiceImpl) newClientSet(restConfig *rest.Config) (*kubernetes.Clientset, error) {
+	restConfig.Timeout = time.Duration(impl.config.TimeoutSecs) * time.Second
+	return kubernetes.NewForConfig(restConfig)
+}
+
+func (impl *KubeconfigImportServiceImpl) getExistingClustersByServer() (map[string]string, error) {
+	clusters, err := impl.clusterRepository.FindAllActive()
+	if err != nil {
+		impl.logger.Errorw("error in fetching clusters", "err", err)
+		return nil, err
+	}
+	existingClusters := make(map[string]string)
+	for _, cluster := range clusters {
+		existingClusters[normalizeServerUrl(cluster.ServerUrl)] = cluster.ClusterName
+	}
+	return existingClusters, nil
+}
+
+// buildContextRestConfig builds rest config of a context from inline credentials only, file references and exec plugins
+// are rejected as they would be read or run on the devtron host
+func buildContextRestConfig(config *clientcmdapi.Config, contextName string, bootstrapToken string) (*rest.Config, string, error) {
+	kubeContext, ok := config.Contexts[contextName]
+	if !ok {
+		return nil, "", fmt.Errorf("context %s not found in kubeconfig", contextName)
+	}
+	cluster, ok := config.Clusters[kubeContext.Cluster]
+	if !ok {
+		return nil, "", fmt.Errorf("cluster %s of context %s not found in kubeconfig", kubeContext.Cluster, contextName)
+	}
+	restConfig := &rest.Config{
+		Host: cluster.Server,
+		TLSClientConfig: rest.TLSClientConfig{
+			Insecure:   cluster.InsecureSkipTLSVerify,
+			ServerName: cluster.TLSServerName,
+			CAData:     cluster.CertificateAuthorityData,
+		},
+	}
+	if len(cluster.CertificateAuthority) > 0 {
+		return restConfig, "", fmt.Errorf("certificate-authority file of cluster %s is not supported, use certificate-authority-data", kubeContext.Cluster)
+	}
+	user, ok := config.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return restConfig, "", fmt.Errorf("user %s of context %s not found in kubeconfig", kubeContext.AuthInfo, contextName)
+	}
+	if len(user.TokenFile) > 0 || len(user.ClientCertificate) > 0 || len(user.ClientKey) > 0 {
+		return restConfig, "", fmt.Errorf("file references of user %s are not supported, use inline data", kubeContext.AuthInfo)
+	}
+	switch {
+	case len(user.Token) > 0:
+		restConfig.BearerToken = user.Token
+		return restConfig, KUBECONFIG_AUTH_TOKEN, nil
+	case len(user.ClientCertificateData) > 0 && len(user.ClientKeyData) > 0:
+		restConfig.CertData = user.ClientCertificateData
+		restConfig.KeyData = user.ClientKeyData
+		return restConfig, KUBECONFIG_AUTH_CLIENT_CERT, nil
+	case len(user.Username) > 0:
+		restConfig.Username = user.Username
+		restConfig.Password = user.Password
+		return restConfig, KUBECONFIG_AUTH_BASIC, nil
+	case user.AuthProvider != nil:
+		idToken := user.AuthProvider.Config["id-token"]
+		if len(idToken) == 0 {
+			return restConfig, KUBECONFIG_AUTH_AUTH_PROVIDER, fmt.Errorf("auth provider %s of user %s has no id-token", user.AuthProvider.Name, kubeContext.AuthInfo)
+		}
+		restConfig.BearerToken = idToken
+		return restConfig, KUBECONFIG_AUTH_AUTH_PROVIDER, nil
+	case user.Exec != nil:
+		if len(bootstrapToken) == 0 {
+			return restConfig, KUBECONFIG_AUTH_EXEC, fmt.Errorf("exec plugin %s is not run by devtron, a bootstrap token is required", user.Exec.Command)
+		}
+		restConfig.BearerToken = bootstrapToken
+		return restConfig, KUBECONFIG_AUTH_EXEC, nil
+	}
+	return restConfig, "", fmt.Errorf("user %s of context %s has no supported credentials", kubeContext.AuthInfo, contextName)
+}
+
+func sortedContextNames(config *clientcmdapi.Config) []string {
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	//current context first, rest in name order
+	sort.Strings(names)
+	for i, name := range names {
+		if name == config.CurrentContext {
+			copy(names[1:i+1], names[:i])
+			names[0] = name
+			break
+		}
+	}
+	return names
+}
+
+var invalidNameChars = regexp.MustCompile("[^a-z0-9-]+")
+
+// kubeconfigClusterName turns a context name like arn:aws:eks:us-east-1:123:cluster/prod into a valid cluster name
+func kubeconfigClusterName(contextName string) string {
+	if index := strings.LastIndex(contextName, "/"); index >= 0 && index < len(contextName)-1 {
+		contextName = contextName[index+1:]
+	}
+	return strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(contextName), "-"), "-")
+}
+
+func kubeconfigEnvironmentName(clusterName string, namespace string) string {
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(clusterName+"-"+namespace), "-"), "-")
+	if len(name) > 50 {
+		name = strings.Trim(name[:50], "-")
+	}
+	return name
+}
+
+func normalizeServerUrl(serverUrl string) string {
+	return strings.TrimSuffix(strings.ToLower(serverUrl), "/")
+}
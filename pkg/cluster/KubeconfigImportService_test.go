@@ -0,0 +1,105 @@
+package cluster
+
+import (
+	"k8s.io/client-go/tools/clientcmd"
+	"testing"
+)
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+    certificate-authority-data: Y2EtZGF0YQ==
+- name: local
+  cluster:
+    server: https://127.0.0.1:6443
+    certificate-authority: /etc/kubernetes/pki/ca.crt
+contexts:
+- name: prod
+  context: {cluster: prod, user: token-user}
+- name: arn:aws:eks:us-east-1:123456789012:cluster/Payments_Cluster
+  context: {cluster: prod, user: eks-user}
+- name: cert
+  context: {cluster: prod, user: cert-user}
+- name: local
+  context: {cluster: local, user: token-user}
+- name: token-file
+  context: {cluster: prod, user: file-user}
+users:
+- name: token-user
+  user: {token: static-token}
+- name: cert-user
+  user: {client-certificate-data: Y2VydA==, client-key-data: a2V5}
+- name: file-user
+  user: {tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token}
+- name: eks-user
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws
+      args: [eks, get-token, --cluster-name, payments]
+`
+
+func TestBuildContextRestConfig(t *testing.T) {
+	config, err := clientcmd.Load([]byte(testKubeconfig))
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	restConfig, authType, err := buildContextRestConfig(config, "prod", "")
+	if err != nil || authType != KUBECONFIG_AUTH_TOKEN || restConfig.BearerToken != "static-token" || restConfig.Host != "https://prod.example.com:6443" {
+		t.Errorf("unexpected token context config %v %s %v", restConfig, authType, err)
+	}
+	restConfig, authType, err = buildContextRestConfig(config, "cert", "")
+	if err != nil || authType != KUBECONFIG_AUTH_CLIENT_CERT || string(restConfig.CertData) != "cert" {
+		t.Errorf("unexpected client cert context config %s %v", authType, err)
+	}
+
+	eksContext := "arn:aws:eks:us-east-1:123456789012:cluster/Payments_Cluster"
+	if _, authType, err = buildContextRestConfig(config, eksContext, ""); err == nil || authType != KUBECONFIG_AUTH_EXEC {
+		t.Errorf("expected exec plugin to require bootstrap token, got %s %v", authType, err)
+	}
+	restConfig, _, err = buildContextRestConfig(config, eksContext, "bootstrap-token")
+	if err != nil || restConfig.BearerToken != "bootstrap-token" {
+		t.Errorf("unexpected exec context config %v", err)
+	}
+
+	if _, _, err = buildContextRestConfig(config, "local", ""); err == nil {
+		t.Errorf("expected err for certificate authority file")
+	}
+	if _, _, err = buildContextRestConfig(config, "token-file", ""); err == nil {
+		t.Errorf("expected err for token file")
+	}
+	if _, _, err = buildContextRestConfig(config, "missing", ""); err == nil {
+		t.Errorf("expected err for missing context")
+	}
+}
+
+func TestSortedContextNames(t *testing.T) {
+	config, err := clientcmd.Load([]byte(testKubeconfig))
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	names := sortedContextNames(config)
+	if len(names) != 5 || names[0] != "prod" || names[1] != "arn:aws:eks:us-east-1:123456789012:cluster/Payments_Cluster" || names[4] != "token-file" {
+		t.Errorf("unexpected order %v", names)
+	}
+}
+
+func TestKubeconfigNames(t *testing.T) {
+	if name := kubeconfigClusterName("arn:aws:eks:us-east-1:123456789012:cluster/Payments_Cluster"); name != "payments-cluster" {
+		t.Errorf("unexpected cluster name %s", name)
+	}
+	if name := kubeconfigClusterName("gke_project_us-central1_staging"); name != "gke-project-us-central1-staging" {
+		t.Errorf("unexpected cluster name %s", name)
+	}
+	if name := kubeconfigEnvironmentName("payments-cluster", "kube-system"); name != "payments-cluster-kube-system" {
+		t.Errorf("unexpected environment name %s", name)
+	}
+	if name := kubeconfigEnvironmentName("a-very-long-cluster-name-for-the-payments-team", "production-namespace"); len(name) > 50 {
+		t.Errorf("environment name longer than 50 characters %s", name)
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"time"
+)
+
+type ClusterHealthCheck struct {
+	tableName       struct{}  `sql:"cluster_health_check" pg:",discard_unknown_columns"`
+	Id              int       `sql:"id,pk"`
+	ClusterId       int       `sql:"cluster_id,notnull"`
+	Status          string    `sql:"status,notnull"`
+	Reachable       bool      `sql:"reachable,notnull"`
+	LatencyMs       int       `sql:"latency_ms"`
+	ServerVersion   string    `sql:"server_version"`
+	CertExpiry      time.Time `sql:"cert_expiry"`
+	TokenExpiry     time.Time `sql:"token_expiry"`
+	NodesTotal      int       `sql:"nodes_total"`
+	NodesReady      int       `sql:"nodes_ready"`
+	ArgoCdReachable bool      `sql:"argo_cd_reachable"`
+	ArgoCdSkipped   bool      `sql:"argo_cd_skipped,notnull"`
+	ArgoCdMessage   string    `sql:"argo_cd_message"`
+	Message         string    `sql:"message"`
+	StatusChanged   bool      `sql:"status_changed,notnull"`
+	CheckedOn       time.Time `sql:"checked_on,notnull"`
+}
+
+type ClusterHealthRepository interface {
+	Save(model *ClusterHealthCheck) error
+	FindLatestByClusterId(clusterId int) (*ClusterHealthCheck, error)
+	FindLatestByClusterIds(clusterIds []int) ([]*ClusterHealthCheck, error)
+	FindByClusterId(clusterId int, offset int, size int) ([]*ClusterHealthCheck, error)
+	DeleteCheckedBefore(checkedOn time.Time) error
+}
+
+type ClusterHealthRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewClusterHealthRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *ClusterHealthRepositoryImpl {
+	return &ClusterHealthRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (impl ClusterHealthRepositoryImpl) Save(model *ClusterHealthCheck) error {
+	return impl.dbConnection.Insert(model)
+}
+
+func (impl ClusterHealthRepositoryImpl) FindLatestByClusterId(clusterId int) (*ClusterHealthCheck, error) {
+	model := &ClusterHealthCheck{}
+	err := impl.dbConnection.
+		Model(model).
+		Where("cluster_id = ?", clusterId).
+		Order("checked_on DESC").
+		Limit(1).
+		Select()
+	return model, err
+}
+
+func (impl ClusterHealthRepositoryImpl) FindLatestByClusterIds(clusterIds []int) ([]*ClusterHealthCheck, error) {
+	var models []*ClusterHealthCheck
+	if len(clusterIds) == 0 {
+		return models, nil
+	}
+	query := "SELECT DISTINCT ON (cluster_id) * FROM cluster_health_check WHERE cluster_id in (?) ORDER BY cluster_id, checked_on DESC;"
+	_, err := impl.dbConnection.Query(&models, query, pg.In(clusterIds))
+	return models, err
+}
+
+func (impl ClusterHealthRepositoryImpl) FindByClusterId(clusterId int, offset int, size int) ([]*ClusterHealthCheck, error) {
+	var models []*ClusterHealthCheck
+	err := impl.dbConnection.
+		Model(&models).
+		Where("cluster_id = ?", clusterId).
+		Order("checked_on DESC").
+		Offset(offset).
+		Limit(size).
+		Select()
+	return models, err
+}
+
+func (impl ClusterHealthRepositoryImpl) DeleteCheckedBefore(checkedOn time.Time) error {
+	_, err := impl.dbConnection.
+		Model((*ClusterHealthCheck)(nil)).
+		Where("checked_on < ?", checkedOn).
+		Where("status_changed = ?", false).
+		Delete()
+	return err
+}
@@ -18,8 +18,10 @@
 package repository
 
 import (
+	"github.com/devtron-labs/devtron/pkg/encryption"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
 	"go.uber.org/zap"
 )
 
@@ -42,6 +44,49 @@ type Cluster struct {
 	sql.AuditLog
 }
 
+// the bearer token in config is encrypted at rest, a copy of the config map is encrypted so that
+// maps shared with the caller are never modified
+
+func (cluster *Cluster) BeforeInsert(db orm.DB) error {
+	return cluster.encryptConfig()
+}
+
+func (cluster *Cluster) BeforeUpdate(db orm.DB) error {
+	return cluster.encryptConfig()
+}
+
+func (cluster *Cluster) AfterInsert(db orm.DB) error {
+	return cluster.DecryptConfig()
+}
+
+func (cluster *Cluster) AfterUpdate(db orm.DB) error {
+	return cluster.DecryptConfig()
+}
+
+func (cluster *Cluster) AfterQuery(db orm.DB) error {
+	return cluster.DecryptConfig()
+}
+
+func (cluster *Cluster) encryptConfig() error {
+	config, err := encryption.EncryptMapField(cluster.Config, encryption.BearerTokenKey)
+	if err != nil {
+		return err
+	}
+	cluster.Config = config
+	return nil
+}
+
+// DecryptConfig decrypts the bearer token, go-pg does not run hooks of joined models so it has to be called
+// explicitly when the cluster is loaded as a relation of another model
+func (cluster *Cluster) DecryptConfig() error {
+	config, err := encryption.DecryptMapField(cluster.Config, encryption.BearerTokenKey)
+	if err != nil {
+		return err
+	}
+	cluster.Config = config
+	return nil
+}
+
 type ClusterRepository interface {
 	Save(model *Cluster) error
 	FindOne(clusterName string) (*Cluster, error)
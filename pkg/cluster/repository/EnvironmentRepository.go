@@ -39,6 +39,19 @@ type Environment struct {
 	sql.AuditLog
 }
 
+func (environment *Environment) AfterQuery(db orm.DB) error {
+	return environment.DecryptClusterConfig()
+}
+
+// DecryptClusterConfig decrypts config of the joined cluster, to be called when environment itself is loaded as
+// a relation of another model (e.g. "Environment.Cluster") as its AfterQuery hook is not run then
+func (environment *Environment) DecryptClusterConfig() error {
+	if environment.Cluster == nil {
+		return nil
+	}
+	return environment.Cluster.DecryptConfig()
+}
+
 type EnvironmentRepository interface {
 	FindOne(environment string) (*Environment, error)
 	Create(mappings *Environment) error
@@ -0,0 +1,422 @@
+package clusterHealth
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	cluster3 "github.com/argoproj/argo-cd/v2/pkg/apiclient/cluster"
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/caarlos0/env/v6"
+	cluster2 "github.com/devtron-labs/devtron/client/argocdServer/cluster"
+	client "github.com/devtron-labs/devtron/client/events"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/util/argo"
+	util2 "github.com/devtron-labs/devtron/util/event"
+	"github.com/devtron-labs/devtron/util/k8s"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type ClusterHealthService interface {
+	GetClusterHealth(clusterId int) (*cluster.ClusterHealthBean, error)
+	GetClusterHealthHistory(clusterId int, offset int, size int) ([]*cluster.ClusterHealthBean, error)
+	CheckClusterHealth(clusterId int) (*cluster.ClusterHealthBean, error)
+}
+
+type ClusterHealthServiceImpl struct {
+	logger                  *zap.SugaredLogger
+	clusterService          cluster.ClusterService
+	clusterRepository       repository.ClusterRepository
+	clusterHealthRepository repository.ClusterHealthRepository
+	k8sApplicationService   k8s.K8sApplicationService
+	acdClusterService       cluster2.ServiceClient
+	argoUserService         argo.ArgoUserService
+	eventClient             client.EventClient
+	eventFactory            client.EventFactory
+	config                  *ClusterHealthConfig
+}
+
+func NewClusterHealthServiceImpl(logger *zap.SugaredLogger, clusterService cluster.ClusterService,
+	clusterRepository repository.ClusterRepository, clusterHealthRepository repository.ClusterHealthRepository,
+	k8sApplicationService k8s.K8sApplicationService, acdClusterService cluster2.ServiceClient,
+	argoUserService argo.ArgoUserService, eventClient client.EventClient, eventFactory client.EventFactory) (*ClusterHealthServiceImpl, error) {
+	config := &ClusterHealthConfig{}
+	err := env.Parse(config)
+	if err != nil {
+		logger.Errorw("error in parsing cluster health config", "err", err)
+		return nil, err
+	}
+	impl := &ClusterHealthServiceImpl{
+		logger:                  logger,
+		clusterService:          clusterService,
+		clusterRepository:       clusterRepository,
+		clusterHealthRepository: clusterHealthRepository,
+		k8sApplicationService:   k8sApplicationService,
+		acdClusterService:       acdClusterService,
+		argoUserService:         argoUserService,
+		eventClient:             eventClient,
+		eventFactory:            eventFactory,
+		config:                  config,
+	}
+	healthCron := cron.New(cron.WithChain())
+	healthCron.Start()
+	_, err = healthCron.AddFunc(fmt.Sprintf("@every %dm", config.ClusterHealthCronTime), impl.syncClusterHealth)
+	if err != nil {
+		logger.Errorw("error in adding cluster health cron", "err", err)
+		return nil, err
+	}
+	return impl, nil
+}
+
+func (impl *ClusterHealthServiceImpl) GetClusterHealth(clusterId int) (*cluster.ClusterHealthBean, error) {
+	model, err := impl.clusterHealthRepository.FindLatestByClusterId(clusterId)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in fetching cluster health", "err", err, "clusterId", clusterId)
+		return nil, err
+	} else if util.IsErrNoRows(err) {
+		//not checked yet, checking it right away
+		return impl.CheckClusterHealth(clusterId)
+	}
+	return cluster.NewClusterHealthBean(model), nil
+}
+
+func (impl *ClusterHealthServiceImpl) GetClusterHealthHistory(clusterId int, offset int, size int) ([]*cluster.ClusterHealthBean, error) {
+	models, err := impl.clusterHealthRepository.FindByClusterId(clusterId, offset, size)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cluster health history", "err", err, "clusterId", clusterId)
+		return nil, err
+	}
+	history := make([]*cluster.ClusterHealthBean, 0, len(models))
+	for _, model := range models {
+		history = append(history, cluster.NewClusterHealthBean(model))
+	}
+	return history, nil
+}
+
+func (impl *ClusterHealthServiceImpl) CheckClusterHealth(clusterId int) (*cluster.ClusterHealthBean, error) {
+	clusterBean, err := impl.clusterService.FindById(clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in fetching cluster", "err", err, "clusterId", clusterId)
+		return nil, err
+	}
+	ctx, argoCdSkipMessage := impl.buildAcdContext()
+	healthCheck := impl.checkCluster(ctx, argoCdSkipMessage, clusterBean)
+	err = impl.recordHealth(clusterBean, healthCheck)
+	if err != nil {
+		return nil, err
+	}
+	return cluster.NewClusterHealthBean(healthCheck), nil
+}
+
+func (impl *ClusterHealthServiceImpl) syncClusterHealth() {
+	impl.logger.Debug("starting cluster health sync")
+	defer impl.logger.Debug("stopped cluster health sync")
+	clusters, err := impl.clusterService.FindAll()
+	if err != nil {
+		impl.logger.Errorw("error in getting all clusters", "err", err)
+		return
+	}
+	ctx, argoCdSkipMessage := impl.buildAcdContext()
+	healthChecks := make([]*repository.ClusterHealthCheck, len(clusters))
+	wg := &sync.WaitGroup{}
+	wg.Add(len(clusters))
+	for i, clusterBean := range clusters {
+		go func(i int, clusterBean *cluster.ClusterBean) {
+			defer wg.Done()
+			healthChecks[i] = impl.checkCluster(ctx, argoCdSkipMessage, clusterBean)
+		}(i, clusterBean)
+	}
+	wg.Wait()
+	for i, clusterBean := range clusters {
+		err = impl.recordHealth(clusterBean, healthChecks[i])
+		if err != nil {
+			impl.logger.Errorw("error in recording cluster health", "err", err, "clusterId", clusterBean.Id)
+		}
+	}
+	retention := time.Duration(impl.config.ClusterHealthHistoryRetentionDays) * 24 * time.Hour
+	err = impl.clusterHealthRepository.DeleteCheckedBefore(time.Now().Add(-retention))
+	if err != nil {
+		impl.logger.Errorw("error in deleting old cluster health checks", "err", err)
+	}
+}
+
+// buildAcdContext returns context for argo cd calls along with the reason to skip the argo cd check, which is skipped
+// when gitops is not configured or the token could not be fetched, other checks of clusters run either way
+func (impl *ClusterHealthServiceImpl) buildAcdContext() (context.Context, string) {
+	acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		impl.logger.Errorw("error in getting acd token", "err", err)
+		return context.Background(), fmt.Sprintf("could not get argo cd token: %s", err.Error())
+	}
+	if len(acdToken) == 0 {
+		return context.Background(), "gitops is not configured"
+	}
+	return context.WithValue(context.Background(), "token", acdToken), ""
+}
+
+// checkCluster runs every check against the cluster, a failed check is recorded in the result instead of being returned
+func (impl *ClusterHealthServiceImpl) checkCluster(ctx context.Context, argoCdSkipMessage string, clusterBean *cluster.ClusterBean) *repository.ClusterHealthCheck {
+	healthCheck := &repository.ClusterHealthCheck{
+		ClusterId: clusterBean.Id,
+		CheckedOn: time.Now(),
+	}
+	timeout := time.Duration(impl.config.ClusterHealthTimeoutSecs) * time.Second
+	if len(argoCdSkipMessage) > 0 {
+		healthCheck.ArgoCdSkipped = true
+		healthCheck.ArgoCdMessage = argoCdSkipMessage
+	} else {
+		impl.checkArgoCdConnection(ctx, clusterBean, healthCheck)
+	}
+
+	restConfig, err := impl.k8sApplicationService.GetRestConfigByCluster(ctx, clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config by cluster", "err", err, "clusterId", clusterBean.Id)
+		healthCheck.Message = err.Error()
+		return impl.withStatus(healthCheck)
+	}
+	restConfig.Timeout = timeout
+	if expiry, ok := bearerTokenExpiry(restConfig.BearerToken); ok {
+		healthCheck.TokenExpiry = expiry
+	}
+	if expiry, err := serverCertExpiry(restConfig.Host, timeout); err == nil {
+		healthCheck.CertExpiry = expiry
+	} else {
+		impl.logger.Debugw("could not read server certificate of cluster", "err", err, "clusterId", clusterBean.Id)
+	}
+	k8sHttpClient, err := util.OverrideK8sHttpClientWithTracer(restConfig)
+	if err != nil {
+		healthCheck.Message = err.Error()
+		return impl.withStatus(healthCheck)
+	}
+	k8sClientSet, err := kubernetes.NewForConfigAndClient(restConfig, k8sHttpClient)
+	if err != nil {
+		impl.logger.Errorw("error in getting client set by rest config", "err", err, "clusterId", clusterBean.Id)
+		healthCheck.Message = err.Error()
+		return impl.withStatus(healthCheck)
+	}
+
+	start := time.Now()
+	serverVersion, err := k8sClientSet.Discovery().ServerVersion()
+	healthCheck.LatencyMs = int(time.Since(start).Milliseconds())
+	if err != nil {
+		healthCheck.Message = err.Error()
+		return impl.withStatus(healthCheck)
+	}
+	healthCheck.Reachable = true
+	healthCheck.ServerVersion = serverVersion.GitVersion
+
+	nodeCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	nodes, err := k8sClientSet.CoreV1().Nodes().List(nodeCtx, metav1.ListOptions{})
+	if err != nil {
+		impl.logger.Errorw("error in listing nodes of cluster", "err", err, "clusterId", clusterBean.Id)
+		healthCheck.Message = fmt.Sprintf("could not list nodes: %s", err.Error())
+	} else {
+		healthCheck.NodesTotal, healthCheck.NodesReady = nodeReadiness(nodes.Items)
+	}
+	return impl.withStatus(healthCheck)
+}
+
+func (impl *ClusterHealthServiceImpl) checkArgoCdConnection(ctx context.Context, clusterBean *cluster.ClusterBean, healthCheck *repository.ClusterHealthCheck) {
+	acdCluster, err := impl.acdClusterService.Get(ctx, &cluster3.ClusterQuery{Server: clusterBean.ServerUrl})
+	if err != nil {
+		healthCheck.ArgoCdMessage = err.Error()
+		return
+	}
+	connectionState := acdCluster.Info.ConnectionState
+	//argo cd reports unknown until it has synced an application on the cluster
+	healthCheck.ArgoCdReachable = connectionState.Status != v1alpha1.ConnectionStatusFailed
+	healthCheck.ArgoCdMessage = connectionState.Message
+}
+
+func (impl *ClusterHealthServiceImpl) withStatus(healthCheck *repository.ClusterHealthCheck) *repository.ClusterHealthCheck {
+	status, message := clusterHealthStatus(healthCheck, impl.config.ClusterHealthExpiryWarningDays, time.Now())
+	healthCheck.Status = status
+	if len(healthCheck.Message) == 0 {
+		healthCheck.Message = message
+	}
+	return healthCheck
+}
+
+func (impl *ClusterHealthServiceImpl) recordHealth(clusterBean *cluster.ClusterBean, healthCheck *repository.ClusterHealthCheck) error {
+	previous, err := impl.clusterHealthRepository.FindLatestByClusterId(clusterBean.Id)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in fetching latest cluster health", "err", err, "clusterId", clusterBean.Id)
+		return err
+	}
+	isTransition := err == nil && previous.Status != healthCheck.Status
+	//first check is kept as a transition so that history always has the starting status
+	healthCheck.StatusChanged = isTransition || util.IsErrNoRows(err)
+	unreachable := false
+	if !healthCheck.Reachable {
+		unreachable, err = impl.isConsecutivelyUnreachable(clusterBean.Id)
+		if err != nil {
+			return err
+		}
+	}
+	err = impl.clusterHealthRepository.Save(healthCheck)
+	if err != nil {
+		impl.logger.Errorw("error in saving cluster health", "err", err, "clusterId", clusterBean.Id)
+		return err
+	}
+
+	//deployments fail fast on clusters with error in connecting, which is set only once the cluster stays unreachable so
+	//that a single failed check does not block deployments
+	errorInConnecting := clusterBean.ErrorInConnecting
+	if healthCheck.Reachable {
+		errorInConnecting = ""
+	} else if unreachable {
+		errorInConnecting = healthCheck.Message
+	}
+	if errorInConnecting != clusterBean.ErrorInConnecting {
+		err = impl.clusterRepository.UpdateClusterConnectionStatus(clusterBean.Id, errorInConnecting)
+		if err != nil {
+			impl.logger.Errorw("error in updating cluster connection status", "err", err, "clusterId", clusterBean.Id)
+		}
+	}
+	if isTransition {
+		impl.notifyTransition(clusterBean, healthCheck)
+	}
+	return nil
+}
+
+// isConsecutivelyUnreachable tells whether the checks of cluster before the current unreachable one found it unreachable
+// as well, making up the configured threshold
+func (impl *ClusterHealthServiceImpl) isConsecutivelyUnreachable(clusterId int) (bool, error) {
+	threshold := impl.config.ClusterHealthUnreachableThreshold
+	if threshold <= 1 {
+		return true, nil
+	}
+	previousChecks, err := impl.clusterHealthRepository.FindByClusterId(clusterId, 0, threshold-1)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in fetching cluster health history", "err", err, "clusterId", clusterId)
+		return false, err
+	}
+	return unreachableInAll(previousChecks, threshold-1), nil
+}
+
+func (impl *ClusterHealthServiceImpl) notifyTransition(clusterBean *cluster.ClusterBean, healthCheck *repository.ClusterHealthCheck) {
+	eventType := util2.Fail
+	if healthCheck.Status == CLUSTER_HEALTH_STATUS_HEALTHY {
+		eventType = util2.Success
+	}
+	event := impl.eventFactory.Build(eventType, nil, 0, nil, util2.Cluster)
+	event.Payload = &client.Payload{
+		ClusterName:          clusterBean.ClusterName,
+		ClusterHealthStatus:  healthCheck.Status,
+		ClusterHealthMessage: healthCheck.Message,
+	}
+	_, err := impl.eventClient.WriteNotificationEvent(event)
+	if err != nil {
+		impl.logger.Errorw("error in sending cluster health notification", "err", err, "clusterId", clusterBean.Id)
+	}
+}
+
+// clusterHealthStatus derives status of a check, message lists every reason for a degraded cluster
+func clusterHealthStatus(healthCheck *repository.ClusterHealthCheck, expiryWarningDays int, now time.Time) (string, string) {
+	if !healthCheck.Reachable {
+		return CLUSTER_HEALTH_STATUS_UNREACHABLE, "api server is unreachable"
+	}
+	var reasons []string
+	if healthCheck.NodesReady < healthCheck.NodesTotal {
+		reasons = append(reasons, fmt.Sprintf("%d of %d nodes are ready", healthCheck.NodesReady, healthCheck.NodesTotal))
+	}
+	if !healthCheck.ArgoCdSkipped && !healthCheck.ArgoCdReachable {
+		reasons = append(reasons, fmt.Sprintf("argo cd cannot reach cluster: %s", healthCheck.ArgoCdMessage))
+	}
+	warnAfter := now.Add(time.Duration(expiryWarningDays) * 24 * time.Hour)
+	if !healthCheck.CertExpiry.IsZero() && healthCheck.CertExpiry.Before(warnAfter) {
+		reasons = append(reasons, fmt.Sprintf("server certificate expires on %s", healthCheck.CertExpiry.Format(time.RFC3339)))
+	}
+	if !healthCheck.TokenExpiry.IsZero() && healthCheck.TokenExpiry.Before(warnAfter) {
+		reasons = append(reasons, fmt.Sprintf("bearer token expires on %s", healthCheck.TokenExpiry.Format(time.RFC3339)))
+	}
+	if len(reasons) > 0 {
+		return CLUSTER_HEALTH_STATUS_DEGRADED, strings.Join(reasons, "; ")
+	}
+	return CLUSTER_HEALTH_STATUS_HEALTHY, ""
+}
+
+// unreachableInAll tells whether there are count checks and all of them found the cluster unreachable
+func unreachableInAll(healthChecks []*repository.ClusterHealthCheck, count int) bool {
+	if len(healthChecks) < count {
+		return false
+	}
+	for _, healthCheck := range healthChecks {
+		if healthCheck.Reachable {
+			return false
+		}
+	}
+	return true
+}
+
+func nodeReadiness(nodes []v1.Node) (int, int) {
+	ready := 0
+	for _, node := range nodes {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+				ready++
+				break
+			}
+		}
+	}
+	return len(nodes), ready
+}
+
+// bearerTokenExpiry reads exp claim of a jwt token without verifying it, legacy service account tokens have no expiry
+func bearerTokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if err = json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
+
+// serverCertExpiry reads expiry of the certificate served by api server, certificate is not verified as clusters
+// are connected with insecure tls
+func serverCertExpiry(host string, timeout time.Duration) (time.Time, error) {
+	serverUrl, err := url.Parse(host)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if serverUrl.Scheme != "https" {
+		return time.Time{}, fmt.Errorf("server %s is not served over https", host)
+	}
+	address := serverUrl.Host
+	if len(serverUrl.Port()) == 0 {
+		address = net.JoinHostPort(serverUrl.Hostname(), "443")
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", address, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return time.Time{}, fmt.Errorf("no certificate served by %s", host)
+	}
+	return certs[0].NotAfter, nil
+}
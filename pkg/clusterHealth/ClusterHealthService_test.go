@@ -0,0 +1,93 @@
+package clusterHealth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/devtron-labs/devtron/pkg/cluster/repository"
+	v1 "k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClusterHealthStatus(t *testing.T) {
+	now := time.Now()
+	healthCheck := &repository.ClusterHealthCheck{Reachable: true, NodesTotal: 3, NodesReady: 3, ArgoCdReachable: true}
+	if status, _ := clusterHealthStatus(healthCheck, 14, now); status != CLUSTER_HEALTH_STATUS_HEALTHY {
+		t.Errorf("expected healthy, got %s", status)
+	}
+
+	healthCheck.NodesReady = 2
+	healthCheck.TokenExpiry = now.Add(48 * time.Hour)
+	status, message := clusterHealthStatus(healthCheck, 14, now)
+	if status != CLUSTER_HEALTH_STATUS_DEGRADED || !strings.Contains(message, "2 of 3 nodes") || !strings.Contains(message, "bearer token expires") {
+		t.Errorf("unexpected status %s, message %s", status, message)
+	}
+
+	healthCheck.Reachable = false
+	if status, _ = clusterHealthStatus(healthCheck, 14, now); status != CLUSTER_HEALTH_STATUS_UNREACHABLE {
+		t.Errorf("expected unreachable, got %s", status)
+	}
+
+	//argo cd check skipped when gitops is not configured does not degrade the cluster
+	healthCheck = &repository.ClusterHealthCheck{Reachable: true, NodesTotal: 3, NodesReady: 3, ArgoCdSkipped: true}
+	if status, _ = clusterHealthStatus(healthCheck, 14, now); status != CLUSTER_HEALTH_STATUS_HEALTHY {
+		t.Errorf("expected healthy with argo cd check skipped, got %s", status)
+	}
+}
+
+func TestUnreachableInAll(t *testing.T) {
+	unreachable := &repository.ClusterHealthCheck{Reachable: false}
+	reachable := &repository.ClusterHealthCheck{Reachable: true}
+	if !unreachableInAll([]*repository.ClusterHealthCheck{unreachable, unreachable}, 2) {
+		t.Errorf("expected unreachable in all checks")
+	}
+	if unreachableInAll([]*repository.ClusterHealthCheck{unreachable, reachable}, 2) {
+		t.Errorf("expected a reachable check to break the run")
+	}
+	if unreachableInAll([]*repository.ClusterHealthCheck{unreachable}, 2) {
+		t.Errorf("expected fewer checks than threshold to not count")
+	}
+}
+
+func TestNodeReadiness(t *testing.T) {
+	readyNode := v1.Node{Status: v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}}}
+	notReadyNode := v1.Node{Status: v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionUnknown}}}}
+	total, ready := nodeReadiness([]v1.Node{readyNode, notReadyNode, readyNode})
+	if total != 3 || ready != 2 {
+		t.Errorf("unexpected readiness %d/%d", ready, total)
+	}
+}
+
+func TestBearerTokenExpiry(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"system:serviceaccount:devtroncd:devtron","exp":%d}`, exp)))
+	expiry, ok := bearerTokenExpiry("eyJhbGciOiJSUzI1NiJ9." + payload + ".signature")
+	if !ok || expiry.Unix() != exp {
+		t.Errorf("unexpected expiry %v", expiry)
+	}
+	legacyPayload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"system:serviceaccount:devtroncd:devtron"}`))
+	if _, ok = bearerTokenExpiry("eyJhbGciOiJSUzI1NiJ9." + legacyPayload + ".signature"); ok {
+		t.Errorf("expected no expiry for token without exp claim")
+	}
+	if _, ok = bearerTokenExpiry("opaque-token"); ok {
+		t.Errorf("expected no expiry for opaque token")
+	}
+}
+
+func TestServerCertExpiry(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	expiry, err := serverCertExpiry(server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if !expiry.Equal(server.Certificate().NotAfter) {
+		t.Errorf("unexpected expiry %v", expiry)
+	}
+	if _, err = serverCertExpiry("http://localhost:8080", time.Second); err == nil {
+		t.Errorf("expected err for plain http server")
+	}
+}
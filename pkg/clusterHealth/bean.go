@@ -0,0 +1,16 @@
+package clusterHealth
+
+const (
+	CLUSTER_HEALTH_STATUS_HEALTHY     = "HEALTHY"
+	CLUSTER_HEALTH_STATUS_DEGRADED    = "DEGRADED"    // reachable but nodes, argo cd or credentials need attention
+	CLUSTER_HEALTH_STATUS_UNREACHABLE = "UNREACHABLE" // api server could not be reached
+)
+
+type ClusterHealthConfig struct {
+	ClusterHealthCronTime             int `env:"CLUSTER_HEALTH_CRON_TIME" envDefault:"5"` // in minutes
+	ClusterHealthTimeoutSecs          int `env:"CLUSTER_HEALTH_TIMEOUT_SECS" envDefault:"10"`
+	ClusterHealthExpiryWarningDays    int `env:"CLUSTER_HEALTH_EXPIRY_WARNING_DAYS" envDefault:"14"`
+	ClusterHealthHistoryRetentionDays int `env:"CLUSTER_HEALTH_HISTORY_RETENTION_DAYS" envDefault:"7"`
+	// deployments are failed fast only after these many consecutive checks found the cluster unreachable
+	ClusterHealthUnreachableThreshold int `env:"CLUSTER_HEALTH_UNREACHABLE_THRESHOLD" envDefault:"3"`
+}
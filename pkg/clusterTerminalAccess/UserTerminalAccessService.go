@@ -10,6 +10,7 @@ import (
 	"github.com/devtron-labs/devtron/client/k8s/application"
 	"github.com/devtron-labs/devtron/internal/sql/models"
 	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
 	"github.com/devtron-labs/devtron/pkg/terminal"
 	"github.com/devtron-labs/devtron/util/k8s"
 	"github.com/robfig/cron/v3"
@@ -46,6 +47,7 @@ type UserTerminalAccessServiceImpl struct {
 	k8sApplicationService        k8s.K8sApplicationService
 	k8sClientService             application.K8sClientService
 	terminalSessionHandler       terminal.TerminalSessionHandler
+	auditLogService              kubernetesResourceAuditLogs.K8sResourceAuditLogService
 }
 
 type UserTerminalAccessSessionData struct {
@@ -65,7 +67,8 @@ func GetTerminalAccessConfig() (*models.UserTerminalSessionConfig, error) {
 }
 
 func NewUserTerminalAccessServiceImpl(logger *zap.SugaredLogger, terminalAccessRepository repository.TerminalAccessRepository, config *models.UserTerminalSessionConfig,
-	k8sApplicationService k8s.K8sApplicationService, k8sClientService application.K8sClientService, terminalSessionHandler terminal.TerminalSessionHandler) (*UserTerminalAccessServiceImpl, error) {
+	k8sApplicationService k8s.K8sApplicationService, k8sClientService application.K8sClientService, terminalSessionHandler terminal.TerminalSessionHandler,
+	auditLogService kubernetesResourceAuditLogs.K8sResourceAuditLogService) (*UserTerminalAccessServiceImpl, error) {
 	//fetches all running and starting entities from db and start SyncStatus
 	podStatusSyncCron := cron.New(cron.WithChain())
 	terminalAccessDataArrayMutex := &sync.RWMutex{}
@@ -80,6 +83,7 @@ func NewUserTerminalAccessServiceImpl(logger *zap.SugaredLogger, terminalAccessR
 		k8sClientService:             k8sClientService,
 		TerminalAccessSessionDataMap: &map1,
 		terminalSessionHandler:       terminalSessionHandler,
+		auditLogService:              auditLogService,
 	}
 	podStatusSyncCron.Start()
 	_, err := podStatusSyncCron.AddFunc(fmt.Sprintf("@every %ds", config.TerminalPodStatusSyncTimeInSecs), accessServiceImpl.SyncPodStatus)
@@ -106,6 +110,15 @@ func (impl *UserTerminalAccessServiceImpl) StartTerminalSession(ctx context.Cont
 		return nil, err
 	}
 	err = impl.startTerminalPod(ctx, podNameVar, request)
+	impl.auditLogService.SaveAuditLog(ctx, &kubernetesResourceAuditLogs.K8sResourceAuditLogRequest{
+		ClusterId:        request.ClusterId,
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Namespace:        request.Namespace,
+		Name:             podNameVar,
+		Action:           kubernetesResourceAuditLogs.AUDIT_ACTION_TERMINAL_START,
+		Message:          fmt.Sprintf("node: %s, image: %s, shell: %s", request.NodeName, request.BaseImage, request.ShellName),
+		Err:              err,
+	})
 	return terminalEntity, err
 }
 
@@ -450,6 +463,7 @@ func (impl *UserTerminalAccessServiceImpl) checkAndStartSession(ctx context.Cont
 			Namespace: namespace,
 			PodName:   terminalAccessPodName,
 			ClusterId: clusterId,
+			UserId:    terminalAccessData.UserId,
 		}
 		_, terminalMessage, err := impl.terminalSessionHandler.GetTerminalSession(request)
 		if err != nil {
@@ -17,6 +17,7 @@ import (
 	repository10 "github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs/repository"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/devtron-labs/devtron/pkg/terminal"
+	repository4 "github.com/devtron-labs/devtron/pkg/terminal/repository"
 	repository3 "github.com/devtron-labs/devtron/pkg/user/repository"
 	"github.com/devtron-labs/devtron/util/k8s"
 	"github.com/stretchr/testify/assert"
@@ -142,13 +143,19 @@ func initTerminalAccessService(t *testing.T) *UserTerminalAccessServiceImpl {
 	appRepositoryImpl := app.NewAppRepositoryImpl(db, sugaredLogger)
 	environmentRepositoryImpl := repository2.NewEnvironmentRepositoryImpl(db)
 	k8sResourceHistoryServiceImpl := kubernetesResourceAuditLogs.Newk8sResourceHistoryServiceImpl(k8sResourceHistoryRepositoryImpl, sugaredLogger, appRepositoryImpl, environmentRepositoryImpl)
-	k8sApplicationService := k8s.NewK8sApplicationServiceImpl(sugaredLogger, clusterServiceImpl, nil, k8sClientServiceImpl, nil, nil, nil, k8sResourceHistoryServiceImpl)
-	terminalSessionHandlerImpl := terminal.NewTerminalSessionHandlerImpl(nil, clusterServiceImpl, sugaredLogger)
+	k8sResourceAuditLogRepositoryImpl := repository10.NewK8sResourceAuditLogRepositoryImpl(db, sugaredLogger)
+	k8sResourceAuditLogServiceImpl, err := kubernetesResourceAuditLogs.NewK8sResourceAuditLogServiceImpl(sugaredLogger, k8sResourceAuditLogRepositoryImpl, clusterRepositoryImpl, userRepositoryImpl)
+	assert.Nil(t, err)
+	k8sApplicationService := k8s.NewK8sApplicationServiceImpl(sugaredLogger, clusterServiceImpl, nil, k8sClientServiceImpl, nil, nil, nil, k8sResourceHistoryServiceImpl, k8sResourceAuditLogServiceImpl, nil)
+	terminalSessionRecordingRepositoryImpl := repository4.NewTerminalSessionRecordingRepositoryImpl(db, sugaredLogger)
+	terminalSessionRecordingServiceImpl, err := terminal.NewTerminalSessionRecordingServiceImpl(sugaredLogger, terminalSessionRecordingRepositoryImpl)
+	assert.Nil(t, err)
+	terminalSessionHandlerImpl := terminal.NewTerminalSessionHandlerImpl(nil, clusterServiceImpl, sugaredLogger, terminalSessionRecordingServiceImpl)
 	userTerminalSessionConfig, err := GetTerminalAccessConfig()
 	assert.Nil(t, err)
 	userTerminalSessionConfig.TerminalPodStatusSyncTimeInSecs = 30
 	userTerminalSessionConfig.TerminalPodInActiveDurationInMins = 1
-	terminalAccessServiceImpl, err := NewUserTerminalAccessServiceImpl(sugaredLogger, terminalAccessRepositoryImpl, userTerminalSessionConfig, k8sApplicationService, k8sClientServiceImpl, terminalSessionHandlerImpl)
+	terminalAccessServiceImpl, err := NewUserTerminalAccessServiceImpl(sugaredLogger, terminalAccessRepositoryImpl, userTerminalSessionConfig, k8sApplicationService, k8sClientServiceImpl, terminalSessionHandlerImpl, k8sResourceAuditLogServiceImpl)
 	assert.Nil(t, err)
 	return terminalAccessServiceImpl
 }
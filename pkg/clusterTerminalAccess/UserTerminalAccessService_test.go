@@ -7,6 +7,7 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/models"
 	"github.com/devtron-labs/devtron/internal/sql/repository/mocks"
 	"github.com/devtron-labs/devtron/internal/util"
+	mocks5 "github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs/mocks"
 	"github.com/devtron-labs/devtron/pkg/terminal"
 	mocks2 "github.com/devtron-labs/devtron/pkg/terminal/mocks"
 	mocks3 "github.com/devtron-labs/devtron/util/k8s/mocks"
@@ -148,8 +149,10 @@ func loadUserTerminalAccessService(t *testing.T) (*mocks.TerminalAccessRepositor
 	terminalSessionHandler := mocks2.NewTerminalSessionHandler(t)
 	k8sApplicationService := mocks3.NewK8sApplicationService(t)
 	k8sClientService := mocks4.NewK8sClientService(t)
+	auditLogService := mocks5.NewK8sResourceAuditLogService(t)
+	auditLogService.On("SaveAuditLog", mock.Anything, mock.AnythingOfType("*kubernetesResourceAuditLogs.K8sResourceAuditLogRequest")).Maybe()
 	terminalAccessRepository.On("GetAllRunningUserTerminalData").Return(nil, nil)
-	terminalAccessServiceImpl, err := NewUserTerminalAccessServiceImpl(logger, terminalAccessRepository, userTerminalSessionConfig, k8sApplicationService, k8sClientService, terminalSessionHandler)
+	terminalAccessServiceImpl, err := NewUserTerminalAccessServiceImpl(logger, terminalAccessRepository, userTerminalSessionConfig, k8sApplicationService, k8sClientService, terminalSessionHandler, auditLogService)
 	assert.Nil(t, err)
 	return terminalAccessRepository, terminalSessionHandler, k8sApplicationService, terminalAccessServiceImpl
 }
@@ -0,0 +1,94 @@
+package encryption
+
+import (
+	"sync"
+)
+
+// the model hooks of go-pg can not receive dependencies, so the encryptor used by them is built once from env
+var (
+	defaultEncryptor    EnvelopeEncryptor
+	defaultEncryptorErr error
+	defaultEncryptorMu  sync.Mutex
+)
+
+// GetDefaultEncryptor returns the process wide encryptor configured through env
+func GetDefaultEncryptor() (EnvelopeEncryptor, error) {
+	defaultEncryptorMu.Lock()
+	defer defaultEncryptorMu.Unlock()
+	if defaultEncryptor == nil && defaultEncryptorErr == nil {
+		cfg, err := GetConfig()
+		if err != nil {
+			defaultEncryptorErr = err
+		} else {
+			defaultEncryptor, defaultEncryptorErr = NewEnvelopeEncryptorFromConfig(cfg)
+		}
+	}
+	return defaultEncryptor, defaultEncryptorErr
+}
+
+// SetDefaultEncryptor overrides the encryptor built from env, used by tests and tooling
+func SetDefaultEncryptor(encryptor EnvelopeEncryptor) {
+	defaultEncryptorMu.Lock()
+	defer defaultEncryptorMu.Unlock()
+	defaultEncryptor, defaultEncryptorErr = encryptor, nil
+}
+
+// EncryptField encrypts the value in place with the default encryptor
+func EncryptField(value *string) error {
+	if len(*value) == 0 || IsEncrypted(*value) {
+		return nil
+	}
+	encryptor, err := GetDefaultEncryptor()
+	if err != nil {
+		return err
+	}
+	encrypted, err := encryptor.Encrypt(*value)
+	if err != nil {
+		return err
+	}
+	*value = encrypted
+	return nil
+}
+
+// DecryptField decrypts the value in place with the default encryptor, plaintext values are left as they are
+func DecryptField(value *string) error {
+	if !IsEncrypted(*value) {
+		return nil
+	}
+	encryptor, err := GetDefaultEncryptor()
+	if err != nil {
+		return err
+	}
+	decrypted, err := encryptor.Decrypt(*value)
+	if err != nil {
+		return err
+	}
+	*value = decrypted
+	return nil
+}
+
+// EncryptMapField returns a copy of the map with the value of key encrypted, the map of the caller is not modified
+func EncryptMapField(values map[string]string, key string) (map[string]string, error) {
+	return transformMapField(values, key, EncryptField)
+}
+
+// DecryptMapField returns a copy of the map with the value of key decrypted
+func DecryptMapField(values map[string]string, key string) (map[string]string, error) {
+	return transformMapField(values, key, DecryptField)
+}
+
+func transformMapField(values map[string]string, key string, transform func(value *string) error) (map[string]string, error) {
+	value, ok := values[key]
+	if !ok {
+		return values, nil
+	}
+	if err := transform(&value); err != nil {
+		return values, err
+	}
+	copied := make(map[string]string, len(values))
+	for k, v := range values {
+		copied[k] = v
+	}
+	copied[key] = value
+	return copied, nil
+}
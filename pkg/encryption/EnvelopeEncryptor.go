@@ -0,0 +1,178 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type EnvelopeEncryptor interface {
+	Enabled() bool
+	Encrypt(plaintext string) (string, error)
+	Decrypt(value string) (string, error)
+	ReEncrypt(value string) (string, error)
+}
+
+type EnvelopeEncryptorImpl struct {
+	enabled     bool
+	keyProvider KeyProvider
+	// unwrapping is a remote call for kms providers, data keys are cached by their wrapped form
+	dataKeyCache map[string][]byte
+	cacheLock    sync.RWMutex
+}
+
+func NewEnvelopeEncryptorImpl(enabled bool, keyProvider KeyProvider) *EnvelopeEncryptorImpl {
+	return &EnvelopeEncryptorImpl{
+		enabled:      enabled,
+		keyProvider:  keyProvider,
+		dataKeyCache: make(map[string][]byte),
+	}
+}
+
+// NewEnvelopeEncryptorFromConfig builds the key provider configured through env, a key provider is built even
+// when encryption is disabled if one is configured so that already encrypted rows stay readable
+func NewEnvelopeEncryptorFromConfig(cfg *Config) (*EnvelopeEncryptorImpl, error) {
+	var keyProvider KeyProvider
+	var err error
+	switch cfg.KeyProvider {
+	case KeyProviderLocal:
+		if !cfg.Enabled {
+			if _, statErr := os.Stat(cfg.LocalKeyFile); statErr != nil {
+				return NewEnvelopeEncryptorImpl(false, nil), nil
+			}
+		}
+		keyProvider, err = NewLocalKeyProviderFromFile(cfg.LocalKeyFile)
+	case KeyProviderVaultTransit:
+		if !cfg.Enabled && len(cfg.VaultAddr) == 0 {
+			return NewEnvelopeEncryptorImpl(false, nil), nil
+		}
+		var client *VaultTransitClient
+		client, err = NewVaultTransitClient(cfg.VaultAddr, cfg.VaultToken, cfg.VaultTransitMount, time.Duration(cfg.VaultRequestTimeoutInSec)*time.Second)
+		if err == nil {
+			keyProvider, err = NewKMSKeyProvider(client, cfg.VaultTransitKey)
+		}
+	default:
+		err = fmt.Errorf("unsupported encryption key provider %s", cfg.KeyProvider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewEnvelopeEncryptorImpl(cfg.Enabled, keyProvider), nil
+}
+
+func (impl *EnvelopeEncryptorImpl) Enabled() bool {
+	return impl.enabled
+}
+
+// Encrypt returns the value unchanged if encryption is disabled, the value is empty or already encrypted
+func (impl *EnvelopeEncryptorImpl) Encrypt(plaintext string) (string, error) {
+	if !impl.enabled || len(plaintext) == 0 || IsEncrypted(plaintext) {
+		return plaintext, nil
+	}
+	return impl.encrypt(plaintext)
+}
+
+func (impl *EnvelopeEncryptorImpl) encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+	keyId, wrappedKey, err := impl.keyProvider.WrapKey(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("error in wrapping data key : %w", err)
+	}
+	nonce, sealed, err := sealAESGCM(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	serialised, err := json.Marshal(&envelope{KeyId: keyId, WrappedKey: wrappedKey, Nonce: nonce, Data: sealed})
+	if err != nil {
+		return "", err
+	}
+	return EncryptedValuePrefix + base64.StdEncoding.EncodeToString(serialised), nil
+}
+
+// Decrypt returns legacy plaintext values unchanged
+func (impl *EnvelopeEncryptorImpl) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if impl.keyProvider == nil {
+		return "", fmt.Errorf("found encrypted value but no encryption key provider is configured")
+	}
+	env, err := parseEnvelope(value)
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := impl.unwrapKey(env)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newAESGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := gcm.Open(nil, env.Nonce, env.Data, nil)
+	if err != nil {
+		return "", fmt.Errorf("error in decrypting value : %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// ReEncrypt decrypts the value and encrypts it again with a new data key wrapped by the current key
+func (impl *EnvelopeEncryptorImpl) ReEncrypt(value string) (string, error) {
+	if !impl.enabled {
+		return "", fmt.Errorf("encryption is not enabled")
+	}
+	if len(value) == 0 {
+		return value, nil
+	}
+	plaintext, err := impl.Decrypt(value)
+	if err != nil {
+		return "", err
+	}
+	return impl.encrypt(plaintext)
+}
+
+func (impl *EnvelopeEncryptorImpl) unwrapKey(env *envelope) ([]byte, error) {
+	cacheKey := env.KeyId + "/" + string(env.WrappedKey)
+	impl.cacheLock.RLock()
+	dataKey, ok := impl.dataKeyCache[cacheKey]
+	impl.cacheLock.RUnlock()
+	if ok {
+		return dataKey, nil
+	}
+	dataKey, err := impl.keyProvider.UnwrapKey(env.KeyId, env.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("error in unwrapping data key with key %s : %w", env.KeyId, err)
+	}
+	impl.cacheLock.Lock()
+	if len(impl.dataKeyCache) >= maxCachedDataKeys {
+		impl.dataKeyCache = make(map[string][]byte)
+	}
+	impl.dataKeyCache[cacheKey] = dataKey
+	impl.cacheLock.Unlock()
+	return dataKey, nil
+}
+
+func parseEnvelope(value string) (*envelope, error) {
+	serialised, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedValuePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted value : %w", err)
+	}
+	env := &envelope{}
+	if err = json.Unmarshal(serialised, env); err != nil {
+		return nil, fmt.Errorf("invalid encrypted value : %w", err)
+	}
+	return env, nil
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedValuePrefix)
+}
@@ -0,0 +1,81 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKeyProvider(t *testing.T, primaryKeyId string, keyIds ...string) *LocalKeyProvider {
+	keys := make(map[string]string)
+	for _, keyId := range keyIds {
+		keys[keyId] = base64.StdEncoding.EncodeToString([]byte(strings.Repeat(keyId[:1], dataKeySize)))
+	}
+	provider, err := NewLocalKeyProvider(&LocalKeyFile{PrimaryKeyId: primaryKeyId, Keys: keys})
+	assert.Nil(t, err)
+	return provider
+}
+
+func TestEnvelopeEncryptor(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		encryptor := NewEnvelopeEncryptorImpl(true, newTestKeyProvider(t, "a", "a"))
+		encrypted, err := encryptor.Encrypt(`{"secrets":[{"name":"db"}]}`)
+		assert.Nil(t, err)
+		assert.True(t, IsEncrypted(encrypted))
+		assert.NotContains(t, encrypted, "secrets")
+		decrypted, err := encryptor.Decrypt(encrypted)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"secrets":[{"name":"db"}]}`, decrypted)
+	})
+	t.Run("legacy plaintext passes through", func(t *testing.T) {
+		encryptor := NewEnvelopeEncryptorImpl(true, newTestKeyProvider(t, "a", "a"))
+		decrypted, err := encryptor.Decrypt(`{"secrets":[]}`)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"secrets":[]}`, decrypted)
+	})
+	t.Run("disabled encryptor does not encrypt", func(t *testing.T) {
+		encryptor := NewEnvelopeEncryptorImpl(false, nil)
+		value, err := encryptor.Encrypt("token")
+		assert.Nil(t, err)
+		assert.Equal(t, "token", value)
+		_, err = encryptor.Decrypt(EncryptedValuePrefix + "e30=")
+		assert.NotNil(t, err)
+	})
+	t.Run("rotation keeps old keys readable", func(t *testing.T) {
+		oldEncryptor := NewEnvelopeEncryptorImpl(true, newTestKeyProvider(t, "a", "a"))
+		encrypted, err := oldEncryptor.Encrypt("token")
+		assert.Nil(t, err)
+
+		rotatedEncryptor := NewEnvelopeEncryptorImpl(true, newTestKeyProvider(t, "b", "a", "b"))
+		reEncrypted, err := rotatedEncryptor.ReEncrypt(encrypted)
+		assert.Nil(t, err)
+		env, err := parseEnvelope(reEncrypted)
+		assert.Nil(t, err)
+		assert.Equal(t, "b", env.KeyId)
+
+		newEncryptor := NewEnvelopeEncryptorImpl(true, newTestKeyProvider(t, "b", "b"))
+		decrypted, err := newEncryptor.Decrypt(reEncrypted)
+		assert.Nil(t, err)
+		assert.Equal(t, "token", decrypted)
+		_, err = newEncryptor.Decrypt(encrypted)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestEncryptMapField(t *testing.T) {
+	SetDefaultEncryptor(NewEnvelopeEncryptorImpl(true, newTestKeyProvider(t, "a", "a")))
+	defer SetDefaultEncryptor(NewEnvelopeEncryptorImpl(false, nil))
+
+	config := map[string]string{BearerTokenKey: "token", "other": "value"}
+	encrypted, err := EncryptMapField(config, BearerTokenKey)
+	assert.Nil(t, err)
+	assert.Equal(t, "token", config[BearerTokenKey])
+	assert.True(t, IsEncrypted(encrypted[BearerTokenKey]))
+	assert.Equal(t, "value", encrypted["other"])
+
+	decrypted, err := DecryptMapField(encrypted, BearerTokenKey)
+	assert.Nil(t, err)
+	assert.Equal(t, config, decrypted)
+}
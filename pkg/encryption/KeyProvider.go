@@ -0,0 +1,147 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// KeyProvider wraps and unwraps the per value data keys with a key encryption key which never leaves the provider
+type KeyProvider interface {
+	// CurrentKeyId is the key used for wrapping new data keys
+	CurrentKeyId() string
+	WrapKey(dataKey []byte) (keyId string, wrappedKey []byte, err error)
+	// UnwrapKey must keep accepting retired key ids until every row has been rotated
+	UnwrapKey(keyId string, wrappedKey []byte) ([]byte, error)
+}
+
+// KMSClient is the minimal surface of a key management service, implementations never expose the key material
+type KMSClient interface {
+	Encrypt(keyId string, plaintext []byte) ([]byte, error)
+	Decrypt(keyId string, ciphertext []byte) ([]byte, error)
+}
+
+// LocalKeyFile is the format of ENCRYPTION_LOCAL_KEY_FILE, keys are base64 encoded 32 byte AES keys.
+// Rotation is done by adding a new key, pointing primaryKeyId to it and running the re-encrypt command,
+// old keys can be removed from the file once the command has completed
+type LocalKeyFile struct {
+	PrimaryKeyId string            `json:"primaryKeyId"`
+	Keys         map[string]string `json:"keys"`
+}
+
+type LocalKeyProvider struct {
+	primaryKeyId string
+	keys         map[string][]byte
+}
+
+func NewLocalKeyProviderFromFile(path string) (*LocalKeyProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error in reading encryption key file %s : %w", path, err)
+	}
+	keyFile := &LocalKeyFile{}
+	if err = json.Unmarshal(content, keyFile); err != nil {
+		return nil, fmt.Errorf("error in parsing encryption key file %s : %w", path, err)
+	}
+	return NewLocalKeyProvider(keyFile)
+}
+
+func NewLocalKeyProvider(keyFile *LocalKeyFile) (*LocalKeyProvider, error) {
+	keys := make(map[string][]byte, len(keyFile.Keys))
+	for keyId, encodedKey := range keyFile.Keys {
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("key %s is not valid base64 : %w", keyId, err)
+		}
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("key %s must be %d bytes, found %d", keyId, dataKeySize, len(key))
+		}
+		keys[keyId] = key
+	}
+	if _, ok := keys[keyFile.PrimaryKeyId]; !ok {
+		return nil, fmt.Errorf("primary key %q not found in key file", keyFile.PrimaryKeyId)
+	}
+	return &LocalKeyProvider{primaryKeyId: keyFile.PrimaryKeyId, keys: keys}, nil
+}
+
+func (impl *LocalKeyProvider) CurrentKeyId() string {
+	return impl.primaryKeyId
+}
+
+func (impl *LocalKeyProvider) WrapKey(dataKey []byte) (string, []byte, error) {
+	nonce, sealed, err := sealAESGCM(impl.keys[impl.primaryKeyId], dataKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return impl.primaryKeyId, append(nonce, sealed...), nil
+}
+
+func (impl *LocalKeyProvider) UnwrapKey(keyId string, wrappedKey []byte) ([]byte, error) {
+	key, ok := impl.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %q not found in key file", keyId)
+	}
+	gcm, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) < gcm.NonceSize() {
+		return nil, fmt.Errorf("wrapped data key is too short")
+	}
+	nonce, sealed := wrappedKey[:gcm.NonceSize()], wrappedKey[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+// KMSKeyProvider delegates wrapping of data keys to a KMSClient
+type KMSKeyProvider struct {
+	client KMSClient
+	keyId  string
+}
+
+func NewKMSKeyProvider(client KMSClient, keyId string) (*KMSKeyProvider, error) {
+	if client == nil || len(keyId) == 0 {
+		return nil, fmt.Errorf("kms client and key id are required")
+	}
+	return &KMSKeyProvider{client: client, keyId: keyId}, nil
+}
+
+func (impl *KMSKeyProvider) CurrentKeyId() string {
+	return impl.keyId
+}
+
+func (impl *KMSKeyProvider) WrapKey(dataKey []byte) (string, []byte, error) {
+	wrappedKey, err := impl.client.Encrypt(impl.keyId, dataKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return impl.keyId, wrappedKey, nil
+}
+
+func (impl *KMSKeyProvider) UnwrapKey(keyId string, wrappedKey []byte) ([]byte, error) {
+	return impl.client.Decrypt(keyId, wrappedKey)
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func sealAESGCM(key []byte, plaintext []byte) (nonce []byte, sealed []byte, err error) {
+	gcm, err := newAESGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce = make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, gcm.Seal(nil, nonce, plaintext, nil), nil
+}
@@ -0,0 +1,155 @@
+package encryption
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+// encryptedColumn is a column holding data encrypted by the model hooks, the service works on raw rows so
+// that it does not depend on the repositories and reads the stored (not decrypted) value
+type encryptedColumn struct {
+	table  string
+	column string
+	filter string
+	isJson bool
+}
+
+var encryptedColumns = []encryptedColumn{
+	{table: "config_map_app_level", column: "secret_data"},
+	{table: "config_map_env_level", column: "secret_data"},
+	{table: "config_map_history", column: "data", filter: "data_type = 'SECRET'"},
+	{table: "pre_post_cd_script_history", column: "secret_data"},
+	{table: "bulk_update_job_item", column: "before_state", filter: "object_type IN ('SECRET_APP_LEVEL', 'SECRET_ENV_LEVEL')"},
+	{table: "bulk_update_job_item", column: "after_state", filter: "object_type IN ('SECRET_APP_LEVEL', 'SECRET_ENV_LEVEL')"},
+	{table: "cluster", column: "config", isJson: true},
+}
+
+type ReEncryptionService interface {
+	// ReEncrypt walks all encrypted columns, in migrate mode only plaintext values are encrypted while in
+	// rotate mode every value is re-encrypted with a data key wrapped by the current key of the provider
+	ReEncrypt(mode ReEncryptMode) ([]*ReEncryptResult, error)
+}
+
+type ReEncryptionServiceImpl struct {
+	logger       *zap.SugaredLogger
+	dbConnection *pg.DB
+	encryptor    EnvelopeEncryptor
+}
+
+func NewReEncryptionServiceImpl(logger *zap.SugaredLogger, dbConnection *pg.DB, encryptor EnvelopeEncryptor) *ReEncryptionServiceImpl {
+	return &ReEncryptionServiceImpl{
+		logger:       logger,
+		dbConnection: dbConnection,
+		encryptor:    encryptor,
+	}
+}
+
+type encryptedRow struct {
+	Id    int    `sql:"id"`
+	Value string `sql:"value"`
+}
+
+func (impl *ReEncryptionServiceImpl) ReEncrypt(mode ReEncryptMode) ([]*ReEncryptResult, error) {
+	if !impl.encryptor.Enabled() {
+		return nil, fmt.Errorf("encryption is not enabled, set ENCRYPTION_ENABLED to true")
+	}
+	if mode != ReEncryptModeMigrate && mode != ReEncryptModeRotate {
+		return nil, fmt.Errorf("unsupported re-encrypt mode %s", mode)
+	}
+	var results []*ReEncryptResult
+	for _, column := range encryptedColumns {
+		result, err := impl.reEncryptColumn(column, mode)
+		if err != nil {
+			impl.logger.Errorw("error in re-encrypting column", "table", column.table, "column", column.column, "err", err)
+			return results, err
+		}
+		impl.logger.Infow("re-encrypted column", "table", column.table, "column", column.column, "result", result)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (impl *ReEncryptionServiceImpl) reEncryptColumn(column encryptedColumn, mode ReEncryptMode) (*ReEncryptResult, error) {
+	result := &ReEncryptResult{Table: column.table}
+	lastId := 0
+	for {
+		rows, err := impl.fetchBatch(column, lastId)
+		if err != nil {
+			return result, err
+		}
+		if len(rows) == 0 {
+			return result, nil
+		}
+		for _, row := range rows {
+			lastId = row.Id
+			result.Scanned++
+			updated, changed, err := impl.reEncryptValue(column, row.Value, mode)
+			if err != nil {
+				return result, fmt.Errorf("%s id %d : %w", column.table, row.Id, err)
+			}
+			if !changed {
+				result.Skipped++
+				continue
+			}
+			if err = impl.updateRow(column, row.Id, row.Value, updated); err != nil {
+				return result, err
+			}
+			result.Encrypted++
+		}
+	}
+}
+
+func (impl *ReEncryptionServiceImpl) fetchBatch(column encryptedColumn, lastId int) ([]*encryptedRow, error) {
+	var rows []*encryptedRow
+	query := fmt.Sprintf("SELECT id, %s::text AS value FROM %s WHERE id > ? AND %s IS NOT NULL", column.column, column.table, column.column)
+	if len(column.filter) > 0 {
+		query += " AND " + column.filter
+	}
+	query += " ORDER BY id LIMIT ?"
+	_, err := impl.dbConnection.Query(&rows, query, lastId, ReEncryptBatchSize)
+	return rows, err
+}
+
+// updateRow only writes if the row still holds the value which was read, rows changed concurrently
+// through the application are already encrypted by the model hooks
+func (impl *ReEncryptionServiceImpl) updateRow(column encryptedColumn, id int, oldValue, newValue string) error {
+	cast := ""
+	if column.isJson {
+		cast = "::json"
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s = ?%s WHERE id = ? AND %s::text = ?", column.table, column.column, cast, column.column)
+	_, err := impl.dbConnection.Exec(query, newValue, id, oldValue)
+	return err
+}
+
+func (impl *ReEncryptionServiceImpl) reEncryptValue(column encryptedColumn, value string, mode ReEncryptMode) (string, bool, error) {
+	if !column.isJson {
+		return impl.reEncryptString(value, mode)
+	}
+	config := make(map[string]string)
+	if err := json.Unmarshal([]byte(value), &config); err != nil {
+		return "", false, err
+	}
+	token, ok := config[BearerTokenKey]
+	if !ok {
+		return value, false, nil
+	}
+	updatedToken, changed, err := impl.reEncryptString(token, mode)
+	if err != nil || !changed {
+		return value, false, err
+	}
+	config[BearerTokenKey] = updatedToken
+	updated, err := json.Marshal(config)
+	return string(updated), true, err
+}
+
+func (impl *ReEncryptionServiceImpl) reEncryptString(value string, mode ReEncryptMode) (string, bool, error) {
+	if len(value) == 0 || (mode == ReEncryptModeMigrate && IsEncrypted(value)) {
+		return value, false, nil
+	}
+	updated, err := impl.encryptor.ReEncrypt(value)
+	return updated, err == nil, err
+}
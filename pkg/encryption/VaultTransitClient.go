@@ -0,0 +1,81 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultTransitClient is a KMSClient backed by the transit secrets engine of HashiCorp Vault
+type VaultTransitClient struct {
+	addr       string
+	token      string
+	mount      string
+	httpClient *http.Client
+}
+
+func NewVaultTransitClient(addr, token, mount string, timeout time.Duration) (*VaultTransitClient, error) {
+	if len(addr) == 0 || len(token) == 0 {
+		return nil, fmt.Errorf("vault address and token are required for transit key provider")
+	}
+	return &VaultTransitClient{
+		addr:       strings.TrimSuffix(addr, "/"),
+		token:      token,
+		mount:      strings.Trim(mount, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (impl *VaultTransitClient) Encrypt(keyId string, plaintext []byte) ([]byte, error) {
+	data, err := impl.post("encrypt", keyId, map[string]string{"plaintext": base64.StdEncoding.EncodeToString(plaintext)})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data["ciphertext"]), nil
+}
+
+func (impl *VaultTransitClient) Decrypt(keyId string, ciphertext []byte) ([]byte, error) {
+	data, err := impl.post("decrypt", keyId, map[string]string{"ciphertext": string(ciphertext)})
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(data["plaintext"])
+}
+
+func (impl *VaultTransitClient) post(operation, keyId string, body map[string]string) (map[string]string, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", impl.addr, impl.mount, operation, keyId)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", impl.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := impl.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault transit %s failed with status %d : %s", operation, resp.StatusCode, string(respBody))
+	}
+	transitResp := &struct {
+		Data map[string]string `json:"data"`
+	}{}
+	if err = json.Unmarshal(respBody, transitResp); err != nil {
+		return nil, err
+	}
+	return transitResp.Data, nil
+}
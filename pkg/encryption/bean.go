@@ -0,0 +1,59 @@
+package encryption
+
+import "github.com/caarlos0/env"
+
+const (
+	KeyProviderLocal        = "LOCAL"
+	KeyProviderVaultTransit = "VAULT_TRANSIT"
+
+	// EncryptedValuePrefix marks a value as an envelope written by this package, anything else is treated as
+	// legacy plaintext so that existing rows keep working until they are migrated
+	EncryptedValuePrefix = "enc:v1:"
+
+	dataKeySize        = 32
+	maxCachedDataKeys  = 1024
+	BearerTokenKey     = "bearer_token"
+	ReEncryptBatchSize = 500
+)
+
+type Config struct {
+	Enabled                  bool   `env:"ENCRYPTION_ENABLED" envDefault:"false"`
+	KeyProvider              string `env:"ENCRYPTION_KEY_PROVIDER" envDefault:"LOCAL"`
+	LocalKeyFile             string `env:"ENCRYPTION_LOCAL_KEY_FILE" envDefault:"/etc/devtron/encryption/keys.json"`
+	VaultAddr                string `env:"ENCRYPTION_VAULT_ADDR" envDefault:""`
+	VaultToken               string `env:"ENCRYPTION_VAULT_TOKEN" envDefault:"" secretData:"-"`
+	VaultTransitMount        string `env:"ENCRYPTION_VAULT_TRANSIT_MOUNT" envDefault:"transit"`
+	VaultTransitKey          string `env:"ENCRYPTION_VAULT_TRANSIT_KEY" envDefault:""`
+	VaultRequestTimeoutInSec int    `env:"ENCRYPTION_VAULT_TIMEOUT" envDefault:"10"`
+}
+
+func GetConfig() (*Config, error) {
+	cfg := &Config{}
+	err := env.Parse(cfg)
+	return cfg, err
+}
+
+// envelope is the serialised form of an encrypted value, the data key is stored wrapped by the key
+// encryption key identified by KeyId, byte slices are base64 encoded by encoding/json
+type envelope struct {
+	KeyId      string `json:"kid"`
+	WrappedKey []byte `json:"wk"`
+	Nonce      []byte `json:"n"`
+	Data       []byte `json:"d"`
+}
+
+type ReEncryptMode string
+
+const (
+	// ReEncryptModeMigrate only encrypts legacy plaintext values and leaves existing envelopes untouched
+	ReEncryptModeMigrate ReEncryptMode = "migrate"
+	// ReEncryptModeRotate re-encrypts every value with a fresh data key wrapped by the current key
+	ReEncryptModeRotate ReEncryptMode = "rotate"
+)
+
+type ReEncryptResult struct {
+	Table     string `json:"table"`
+	Scanned   int    `json:"scanned"`
+	Encrypted int    `json:"encrypted"`
+	Skipped   int    `json:"skipped"`
+}
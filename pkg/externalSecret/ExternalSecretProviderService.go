@@ -0,0 +1,445 @@
+package externalSecret
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/pkg/externalSecret/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/oauth2/google"
+)
+
+type ExternalSecretProviderService interface {
+	ValidateSecret(request *SecretValidationRequest) error
+	SaveDefaultSecretStore(dto *DefaultSecretStoreDto) (*DefaultSecretStoreDto, error)
+	GetDefaultSecretStores(envId int) ([]*DefaultSecretStoreDto, error)
+	// DeleteDefaultSecretStore store not belonging to envId is treated as not found
+	DeleteDefaultSecretStore(envId int, id int, userId int32) error
+	TestConnection(request *ConnectionTestRequest) (*ConnectionTestResponse, error)
+	// ResolveSecretStores renders secret stores of first-class provider secrets in merged secret json of an environment
+	ResolveSecretStores(envId int, secretDataJson string) (string, error)
+}
+
+type ExternalSecretProviderServiceImpl struct {
+	logger                        *zap.SugaredLogger
+	externalSecretStoreRepository repository.ExternalSecretStoreRepository
+}
+
+func NewExternalSecretProviderServiceImpl(logger *zap.SugaredLogger,
+	externalSecretStoreRepository repository.ExternalSecretStoreRepository) *ExternalSecretProviderServiceImpl {
+	return &ExternalSecretProviderServiceImpl{
+		logger:                        logger,
+		externalSecretStoreRepository: externalSecretStoreRepository,
+	}
+}
+
+func (impl *ExternalSecretProviderServiceImpl) ValidateSecret(request *SecretValidationRequest) error {
+	if !util.IsESOProviderType(request.ExternalType) {
+		return nil
+	}
+	if len(request.RemoteKeys) == 0 {
+		return fmt.Errorf("at least one remote key is required for external type %s", request.ExternalType)
+	}
+	err := ValidateRemoteKeys(request.ExternalType, request.RemoteKeys)
+	if err != nil {
+		return err
+	}
+	if request.Provider != nil {
+		return ValidateProviderConfig(request.ExternalType, request.Provider)
+	}
+	if request.HasSecretStore || request.EnvId == 0 {
+		//app level secrets can rely on the default store of every environment they are deployed on
+		return nil
+	}
+	_, err = impl.externalSecretStoreRepository.FindActiveByEnvIdAndType(request.EnvId, request.ExternalType)
+	if err == pg.ErrNoRows {
+		return fmt.Errorf("no provider config given and no default %s secret store configured for this environment", request.ExternalType)
+	} else if err != nil {
+		impl.logger.Errorw("error in getting default secret store", "err", err, "envId", request.EnvId, "externalType", request.ExternalType)
+		return err
+	}
+	return nil
+}
+
+func (impl *ExternalSecretProviderServiceImpl) SaveDefaultSecretStore(dto *DefaultSecretStoreDto) (*DefaultSecretStoreDto, error) {
+	if len(dto.SecretStoreRef) == 0 {
+		err := ValidateProviderConfig(dto.ExternalType, dto.Provider)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var providerJson string
+	if dto.Provider != nil {
+		providerByte, err := json.Marshal(dto.Provider)
+		if err != nil {
+			impl.logger.Errorw("error in marshaling provider config", "err", err)
+			return nil, err
+		}
+		providerJson = string(providerByte)
+	}
+	model, err := impl.externalSecretStoreRepository.FindActiveByEnvIdAndType(dto.EnvironmentId, dto.ExternalType)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting default secret store", "err", err, "envId", dto.EnvironmentId)
+		return nil, err
+	}
+	if err == pg.ErrNoRows {
+		model = &repository.ExternalSecretStore{
+			EnvironmentId: dto.EnvironmentId,
+			ExternalType:  dto.ExternalType,
+			Active:        true,
+			AuditLog:      sql.AuditLog{CreatedBy: dto.UserId, CreatedOn: time.Now(), UpdatedBy: dto.UserId, UpdatedOn: time.Now()},
+		}
+	}
+	model.Provider = providerJson
+	model.SecretStoreRef = string(dto.SecretStoreRef)
+	model.UpdatedBy = dto.UserId
+	model.UpdatedOn = time.Now()
+	if model.Id > 0 {
+		err = impl.externalSecretStoreRepository.Update(model)
+	} else {
+		err = impl.externalSecretStoreRepository.Save(model)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving default secret store", "err", err, "envId", dto.EnvironmentId)
+		return nil, err
+	}
+	dto.Id = model.Id
+	return dto, nil
+}
+
+func (impl *ExternalSecretProviderServiceImpl) GetDefaultSecretStores(envId int) ([]*DefaultSecretStoreDto, error) {
+	models, err := impl.externalSecretStoreRepository.FindActiveByEnvId(envId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting default secret stores", "err", err, "envId", envId)
+		return nil, err
+	}
+	dtos := make([]*DefaultSecretStoreDto, 0, len(models))
+	for _, model := range models {
+		dto, err := impl.toDto(model)
+		if err != nil {
+			return nil, err
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos, nil
+}
+
+func (impl *ExternalSecretProviderServiceImpl) DeleteDefaultSecretStore(envId int, id int, userId int32) error {
+	model, err := impl.externalSecretStoreRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting default secret store", "err", err, "id", id)
+		return err
+	}
+	if model.EnvironmentId != envId {
+		impl.logger.Errorw("default secret store does not belong to environment", "id", id, "envId", envId, "storeEnvId", model.EnvironmentId)
+		return pg.ErrNoRows
+	}
+	model.Active = false
+	model.UpdatedBy = userId
+	model.UpdatedOn = time.Now()
+	return impl.externalSecretStoreRepository.Update(model)
+}
+
+func (impl *ExternalSecretProviderServiceImpl) toDto(model *repository.ExternalSecretStore) (*DefaultSecretStoreDto, error) {
+	dto := &DefaultSecretStoreDto{
+		Id:            model.Id,
+		EnvironmentId: model.EnvironmentId,
+		ExternalType:  model.ExternalType,
+	}
+	if len(model.Provider) > 0 {
+		provider := &ProviderConfig{}
+		err := json.Unmarshal([]byte(model.Provider), provider)
+		if err != nil {
+			impl.logger.Errorw("error in un-marshaling provider config", "err", err, "id", model.Id)
+			return nil, err
+		}
+		dto.Provider = provider
+	}
+	if len(model.SecretStoreRef) > 0 {
+		dto.SecretStoreRef = json.RawMessage(model.SecretStoreRef)
+	}
+	return dto, nil
+}
+
+func (impl *ExternalSecretProviderServiceImpl) ResolveSecretStores(envId int, secretDataJson string) (string, error) {
+	if len(secretDataJson) == 0 {
+		return secretDataJson, nil
+	}
+	secrets := bean.ConfigSecretJson{}
+	err := json.Unmarshal([]byte(secretDataJson), &secrets)
+	if err != nil {
+		impl.logger.Errorw("error in un-marshaling secret data", "err", err)
+		return secretDataJson, err
+	}
+	resolved := false
+	defaultStores := make(map[string]*DefaultSecretStoreDto)
+	for _, secret := range secrets.Secrets {
+		if !util.IsESOProviderType(secret.ExternalType) {
+			continue
+		}
+		esoSecretData := make(map[string]json.RawMessage)
+		if len(secret.ESOSecretData) > 0 {
+			err = json.Unmarshal(secret.ESOSecretData, &esoSecretData)
+			if err != nil {
+				impl.logger.Errorw("error in un-marshaling eso secret data", "err", err, "name", secret.Name)
+				return secretDataJson, err
+			}
+		}
+		_, hasSecretStore := esoSecretData["secretStore"]
+		_, hasSecretStoreRef := esoSecretData["secretStoreRef"]
+		var provider *ProviderConfig
+		if providerJson, ok := esoSecretData["provider"]; ok {
+			provider = &ProviderConfig{}
+			err = json.Unmarshal(providerJson, provider)
+			if err != nil {
+				impl.logger.Errorw("error in un-marshaling provider config", "err", err, "name", secret.Name)
+				return secretDataJson, err
+			}
+			delete(esoSecretData, "provider")
+		}
+		if provider == nil && !hasSecretStore && !hasSecretStoreRef {
+			defaultStore, ok := defaultStores[secret.ExternalType]
+			if !ok {
+				defaultStore, err = impl.getDefaultSecretStore(envId, secret.ExternalType)
+				if err != nil {
+					return secretDataJson, err
+				}
+				defaultStores[secret.ExternalType] = defaultStore
+			}
+			if defaultStore == nil {
+				return secretDataJson, fmt.Errorf("no secret store found for secret %s of type %s, please configure a default secret store for the environment", secret.Name, secret.ExternalType)
+			}
+			if len(defaultStore.SecretStoreRef) > 0 {
+				esoSecretData["secretStoreRef"] = defaultStore.SecretStoreRef
+			} else {
+				provider = defaultStore.Provider
+			}
+		}
+		if provider != nil && !hasSecretStore && !hasSecretStoreRef {
+			secretStore, err := BuildSecretStore(secret.ExternalType, provider)
+			if err != nil {
+				impl.logger.Errorw("error in building secret store", "err", err, "name", secret.Name)
+				return secretDataJson, err
+			}
+			secretStoreByte, err := json.Marshal(secretStore)
+			if err != nil {
+				return secretDataJson, err
+			}
+			esoSecretData["secretStore"] = secretStoreByte
+		}
+		secret.ESOSecretData, err = json.Marshal(esoSecretData)
+		if err != nil {
+			return secretDataJson, err
+		}
+		secret.ExternalType = GetChartExternalType(secret.ExternalType)
+		resolved = true
+	}
+	if !resolved {
+		return secretDataJson, nil
+	}
+	resolvedByte, err := json.Marshal(secrets)
+	if err != nil {
+		impl.logger.Errorw("error in marshaling resolved secret data", "err", err)
+		return secretDataJson, err
+	}
+	return string(resolvedByte), nil
+}
+
+func (impl *ExternalSecretProviderServiceImpl) getDefaultSecretStore(envId int, externalType string) (*DefaultSecretStoreDto, error) {
+	model, err := impl.externalSecretStoreRepository.FindActiveByEnvIdAndType(envId, externalType)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		impl.logger.Errorw("error in getting default secret store", "err", err, "envId", envId, "externalType", externalType)
+		return nil, err
+	}
+	return impl.toDto(model)
+}
+
+func (impl *ExternalSecretProviderServiceImpl) TestConnection(request *ConnectionTestRequest) (*ConnectionTestResponse, error) {
+	err := ValidateProviderConfig(request.ExternalType, request.Provider)
+	if err != nil {
+		return nil, err
+	}
+	credentials := request.Credentials
+	if credentials == nil {
+		credentials = &TestCredentials{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTestTimeout)
+	defer cancel()
+	switch request.ExternalType {
+	case util.HashiCorpVaultKV2:
+		return impl.testVaultConnection(ctx, request.Provider.Vault, credentials)
+	case util.GCPSecretManager:
+		return impl.testGCPConnection(ctx, request.Provider.GCPSecretManager, credentials)
+	default:
+		return impl.testAzureConnection(ctx, request.Provider.AzureKeyVault, credentials)
+	}
+}
+
+func (impl *ExternalSecretProviderServiceImpl) testVaultConnection(ctx context.Context, vault *VaultProviderConfig, credentials *TestCredentials) (*ConnectionTestResponse, error) {
+	client, err := vaultHttpClient(vault.CABundle)
+	if err != nil {
+		return nil, err
+	}
+	server := strings.TrimSuffix(vault.Server, "/")
+	response := &ConnectionTestResponse{}
+	start := time.Now()
+	statusCode, err := doRequest(ctx, client, http.MethodGet, server+"/v1/sys/health?standbyok=true", vault.Namespace, "", nil)
+	response.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		impl.logger.Infow("vault not reachable", "server", vault.Server, "err", err)
+		response.Message = fmt.Sprintf("vault server not reachable: %s", err.Error())
+		return response, nil
+	}
+	response.Reachable = true
+	switch statusCode {
+	case http.StatusNotImplemented:
+		response.Message = "vault server is not initialized"
+		return response, nil
+	case http.StatusServiceUnavailable:
+		response.Message = "vault server is sealed"
+		return response, nil
+	}
+	if len(credentials.VaultToken) > 0 {
+		statusCode, err = doRequest(ctx, client, http.MethodGet, server+"/v1/auth/token/lookup-self", vault.Namespace, credentials.VaultToken, nil)
+	} else if vault.AuthType == VaultAuthTypeAppRole && len(credentials.VaultAppRoleSecretId) > 0 {
+		login := map[string]string{"role_id": vault.AppRoleAuth.RoleId, "secret_id": credentials.VaultAppRoleSecretId}
+		statusCode, err = doRequest(ctx, client, http.MethodPost, fmt.Sprintf("%s/v1/auth/%s/login", server, strings.Trim(vault.AppRoleAuth.Path, "/")), vault.Namespace, "", login)
+	} else {
+		response.Message = "vault server reachable, no credentials given to verify authentication"
+		return response, nil
+	}
+	return authResponse(response, statusCode, err), nil
+}
+
+func (impl *ExternalSecretProviderServiceImpl) testGCPConnection(ctx context.Context, gcp *GCPSecretManagerProviderConfig, credentials *TestCredentials) (*ConnectionTestResponse, error) {
+	response := &ConnectionTestResponse{}
+	secretsUrl := fmt.Sprintf("%s/projects/%s/secrets?pageSize=1", gcpSecretManagerBaseUrl, url.PathEscape(gcp.ProjectId))
+	start := time.Now()
+	_, err := doRequest(ctx, http.DefaultClient, http.MethodGet, secretsUrl, "", "", nil)
+	response.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		response.Message = fmt.Sprintf("gcp secret manager not reachable: %s", err.Error())
+		return response, nil
+	}
+	response.Reachable = true
+	if len(credentials.GCPServiceAccountKey) == 0 {
+		response.Message = "gcp secret manager reachable, no service account key given to verify authentication"
+		return response, nil
+	}
+	googleCredentials, err := google.CredentialsFromJSON(ctx, []byte(credentials.GCPServiceAccountKey), gcpCloudPlatformScope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gcp service account key: %s", err.Error())
+	}
+	token, err := googleCredentials.TokenSource.Token()
+	if err != nil {
+		return authResponse(response, 0, err), nil
+	}
+	statusCode, err := doRequest(ctx, http.DefaultClient, http.MethodGet, secretsUrl, "", "Bearer "+token.AccessToken, nil)
+	return authResponse(response, statusCode, err), nil
+}
+
+func (impl *ExternalSecretProviderServiceImpl) testAzureConnection(ctx context.Context, azure *AzureKeyVaultProviderConfig, credentials *TestCredentials) (*ConnectionTestResponse, error) {
+	response := &ConnectionTestResponse{}
+	secretsUrl := fmt.Sprintf("%s/secrets?api-version=%s&maxresults=1", strings.TrimSuffix(azure.VaultUrl, "/"), azureKeyVaultApiVersion)
+	start := time.Now()
+	_, err := doRequest(ctx, http.DefaultClient, http.MethodGet, secretsUrl, "", "", nil)
+	response.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		response.Message = fmt.Sprintf("azure key vault not reachable: %s", err.Error())
+		return response, nil
+	}
+	response.Reachable = true
+	if len(credentials.AzureClientId) == 0 || len(credentials.AzureClientSecret) == 0 || len(azure.TenantId) == 0 {
+		response.Message = "azure key vault reachable, no service principal given to verify authentication"
+		return response, nil
+	}
+	config := clientcredentials.Config{
+		ClientID:     credentials.AzureClientId,
+		ClientSecret: credentials.AzureClientSecret,
+		TokenURL:     fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", url.PathEscape(azure.TenantId)),
+		Scopes:       []string{azureKeyVaultScope},
+	}
+	token, err := config.Token(ctx)
+	if err != nil {
+		return authResponse(response, 0, err), nil
+	}
+	statusCode, err := doRequest(ctx, http.DefaultClient, http.MethodGet, secretsUrl, "", "Bearer "+token.AccessToken, nil)
+	return authResponse(response, statusCode, err), nil
+}
+
+func authResponse(response *ConnectionTestResponse, statusCode int, err error) *ConnectionTestResponse {
+	if err != nil {
+		response.Message = fmt.Sprintf("authentication failed: %s", err.Error())
+	} else if statusCode >= 200 && statusCode < 300 {
+		response.Authenticated = true
+		response.Message = "connection successful"
+	} else {
+		response.Message = fmt.Sprintf("authentication failed with status code %d", statusCode)
+	}
+	return response
+}
+
+func vaultHttpClient(caBundle string) (*http.Client, error) {
+	if len(caBundle) == 0 {
+		return &http.Client{}, nil
+	}
+	caPem, err := base64.StdEncoding.DecodeString(caBundle)
+	if err != nil {
+		//ca bundle given as plain pem
+		caPem = []byte(caBundle)
+	}
+	pool := x509.NewCertPool()
+	if ok := pool.AppendCertsFromPEM(caPem); !ok {
+		return nil, fmt.Errorf("invalid vault ca bundle")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return &http.Client{Transport: transport}, nil
+}
+
+func doRequest(ctx context.Context, client *http.Client, method string, requestUrl string, vaultNamespace string, authorization string, body interface{}) (int, error) {
+	var reqBody *bytes.Reader
+	if body != nil {
+		bodyByte, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reqBody = bytes.NewReader(bodyByte)
+	} else {
+		reqBody = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestUrl, reqBody)
+	if err != nil {
+		return 0, err
+	}
+	if len(vaultNamespace) > 0 {
+		req.Header.Set("X-Vault-Namespace", vaultNamespace)
+	}
+	if strings.HasPrefix(authorization, "Bearer ") {
+		req.Header.Set("Authorization", authorization)
+	} else if len(authorization) > 0 {
+		req.Header.Set("X-Vault-Token", authorization)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
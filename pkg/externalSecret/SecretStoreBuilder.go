@@ -0,0 +1,264 @@
+package externalSecret
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/devtron-labs/devtron/util"
+)
+
+var (
+	gcpProjectIdRegex    = regexp.MustCompile("^[a-z][a-z0-9-]{4,28}[a-z0-9]$")
+	gcpSecretNameRegex   = regexp.MustCompile("^[a-zA-Z0-9_-]{1,255}$")
+	azureObjectNameRegex = regexp.MustCompile("^(secret/|key/|cert/)?[0-9a-zA-Z-]{1,127}$")
+)
+
+// GetChartExternalType maps a first-class provider type to the ESO type understood by the reference charts,
+// the secret store for these types is always rendered by devtron before the values reach the chart
+func GetChartExternalType(externalType string) string {
+	switch externalType {
+	case util.HashiCorpVaultKV2:
+		return util.ESOHashiCorpVault
+	case util.GCPSecretManager:
+		return util.ESOGoogleSecretsManager
+	case util.AzureKeyVault:
+		return util.ESOAzureSecretsManager
+	}
+	return externalType
+}
+
+// ValidateProviderConfig checks that the provider block matching the external type is present and complete
+func ValidateProviderConfig(externalType string, provider *ProviderConfig) error {
+	if provider == nil {
+		return fmt.Errorf("provider config is required for external type %s", externalType)
+	}
+	switch externalType {
+	case util.HashiCorpVaultKV2:
+		return validateVaultProvider(provider.Vault)
+	case util.GCPSecretManager:
+		return validateGCPProvider(provider.GCPSecretManager)
+	case util.AzureKeyVault:
+		return validateAzureProvider(provider.AzureKeyVault)
+	}
+	return fmt.Errorf("unsupported external secret provider type %s", externalType)
+}
+
+// ValidateRemoteKeys checks the remote references against the naming rules of the provider
+func ValidateRemoteKeys(externalType string, keys []string) error {
+	for _, key := range keys {
+		if len(strings.TrimSpace(key)) == 0 {
+			return fmt.Errorf("remote key can not be empty")
+		}
+		switch externalType {
+		case util.HashiCorpVaultKV2:
+			if strings.HasPrefix(key, "/") || strings.Contains(key, "//") {
+				return fmt.Errorf("invalid vault secret path : %s", key)
+			}
+		case util.GCPSecretManager:
+			if !gcpSecretNameRegex.MatchString(key) {
+				return fmt.Errorf("invalid gcp secret name : %s", key)
+			}
+		case util.AzureKeyVault:
+			if !azureObjectNameRegex.MatchString(key) {
+				return fmt.Errorf("invalid azure key vault object name : %s", key)
+			}
+		}
+	}
+	return nil
+}
+
+func validateVaultProvider(vault *VaultProviderConfig) error {
+	if vault == nil {
+		return fmt.Errorf("vault provider config is required")
+	}
+	if err := validateServerUrl(vault.Server); err != nil {
+		return err
+	}
+	if len(vault.Path) == 0 {
+		return fmt.Errorf("vault kv v2 mount path is required")
+	}
+	switch vault.AuthType {
+	case VaultAuthTypeKubernetes:
+		if vault.KubernetesAuth == nil || len(vault.KubernetesAuth.MountPath) == 0 || len(vault.KubernetesAuth.Role) == 0 {
+			return fmt.Errorf("vault kubernetes auth requires mountPath and role")
+		}
+	case VaultAuthTypeAppRole:
+		if vault.AppRoleAuth == nil || len(vault.AppRoleAuth.Path) == 0 || len(vault.AppRoleAuth.RoleId) == 0 {
+			return fmt.Errorf("vault appRole auth requires path and roleId")
+		}
+		if err := validateSecretKeyRef("vault appRole secretRef", vault.AppRoleAuth.SecretRef); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported vault auth type %s", vault.AuthType)
+	}
+	return nil
+}
+
+func validateGCPProvider(gcp *GCPSecretManagerProviderConfig) error {
+	if gcp == nil {
+		return fmt.Errorf("gcp secret manager provider config is required")
+	}
+	if !gcpProjectIdRegex.MatchString(gcp.ProjectId) {
+		return fmt.Errorf("invalid gcp project id : %s", gcp.ProjectId)
+	}
+	switch gcp.AuthType {
+	case GCPAuthTypeWorkloadIdentity:
+		wi := gcp.WorkloadIdentity
+		if wi == nil || len(wi.ClusterLocation) == 0 || len(wi.ClusterName) == 0 || len(wi.ServiceAccountName) == 0 {
+			return fmt.Errorf("gcp workload identity requires clusterLocation, clusterName and serviceAccountName")
+		}
+	case GCPAuthTypeServiceAccountKey:
+		if err := validateSecretKeyRef("gcp secretAccessKeyRef", gcp.SecretAccessKeyRef); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported gcp auth type %s", gcp.AuthType)
+	}
+	return nil
+}
+
+func validateAzureProvider(azure *AzureKeyVaultProviderConfig) error {
+	if azure == nil {
+		return fmt.Errorf("azure key vault provider config is required")
+	}
+	if err := validateServerUrl(azure.VaultUrl); err != nil {
+		return err
+	}
+	switch azure.AuthType {
+	case AzureAuthTypeServicePrincipal:
+		if len(azure.TenantId) == 0 {
+			return fmt.Errorf("azure service principal auth requires tenantId")
+		}
+		if err := validateSecretKeyRef("azure clientIdRef", azure.ClientIdRef); err != nil {
+			return err
+		}
+		if err := validateSecretKeyRef("azure clientSecretRef", azure.ClientSecretRef); err != nil {
+			return err
+		}
+	case AzureAuthTypeManagedIdentity:
+	case AzureAuthTypeWorkloadIdentity:
+		if len(azure.ServiceAccountName) == 0 {
+			return fmt.Errorf("azure workload identity requires serviceAccountName")
+		}
+	default:
+		return fmt.Errorf("unsupported azure auth type %s", azure.AuthType)
+	}
+	return nil
+}
+
+func validateServerUrl(server string) error {
+	u, err := url.Parse(server)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return fmt.Errorf("invalid server url : %s", server)
+	}
+	return nil
+}
+
+func validateSecretKeyRef(name string, ref *SecretKeyRef) error {
+	if ref == nil || len(ref.Name) == 0 || len(ref.Key) == 0 {
+		return fmt.Errorf("%s requires name and key", name)
+	}
+	return nil
+}
+
+// BuildSecretStore renders the ESO SecretStore provider spec for a validated provider config
+func BuildSecretStore(externalType string, provider *ProviderConfig) (map[string]interface{}, error) {
+	if err := ValidateProviderConfig(externalType, provider); err != nil {
+		return nil, err
+	}
+	switch externalType {
+	case util.HashiCorpVaultKV2:
+		return map[string]interface{}{"vault": buildVaultSpec(provider.Vault)}, nil
+	case util.GCPSecretManager:
+		return map[string]interface{}{"gcpsm": buildGCPSpec(provider.GCPSecretManager)}, nil
+	default:
+		return map[string]interface{}{"azurekv": buildAzureSpec(provider.AzureKeyVault)}, nil
+	}
+}
+
+func buildVaultSpec(vault *VaultProviderConfig) map[string]interface{} {
+	auth := make(map[string]interface{})
+	if vault.AuthType == VaultAuthTypeKubernetes {
+		kubernetesAuth := map[string]interface{}{
+			"mountPath": vault.KubernetesAuth.MountPath,
+			"role":      vault.KubernetesAuth.Role,
+		}
+		if len(vault.KubernetesAuth.ServiceAccountName) > 0 {
+			kubernetesAuth["serviceAccountRef"] = map[string]interface{}{"name": vault.KubernetesAuth.ServiceAccountName}
+		}
+		auth["kubernetes"] = kubernetesAuth
+	} else {
+		auth["appRole"] = map[string]interface{}{
+			"path":      vault.AppRoleAuth.Path,
+			"roleId":    vault.AppRoleAuth.RoleId,
+			"secretRef": secretKeySelector(vault.AppRoleAuth.SecretRef),
+		}
+	}
+	spec := map[string]interface{}{
+		"server":  vault.Server,
+		"path":    vault.Path,
+		"version": vaultKVVersion,
+		"auth":    auth,
+	}
+	if len(vault.Namespace) > 0 {
+		spec["namespace"] = vault.Namespace
+	}
+	if len(vault.CABundle) > 0 {
+		spec["caBundle"] = vault.CABundle
+	}
+	return spec
+}
+
+func buildGCPSpec(gcp *GCPSecretManagerProviderConfig) map[string]interface{} {
+	auth := make(map[string]interface{})
+	if gcp.AuthType == GCPAuthTypeWorkloadIdentity {
+		workloadIdentity := map[string]interface{}{
+			"clusterLocation":   gcp.WorkloadIdentity.ClusterLocation,
+			"clusterName":       gcp.WorkloadIdentity.ClusterName,
+			"serviceAccountRef": map[string]interface{}{"name": gcp.WorkloadIdentity.ServiceAccountName},
+		}
+		if len(gcp.WorkloadIdentity.ClusterProjectId) > 0 {
+			workloadIdentity["clusterProjectID"] = gcp.WorkloadIdentity.ClusterProjectId
+		}
+		auth["workloadIdentity"] = workloadIdentity
+	} else {
+		auth["secretRef"] = map[string]interface{}{
+			"secretAccessKeySecretRef": secretKeySelector(gcp.SecretAccessKeyRef),
+		}
+	}
+	return map[string]interface{}{
+		"projectID": gcp.ProjectId,
+		"auth":      auth,
+	}
+}
+
+func buildAzureSpec(azure *AzureKeyVaultProviderConfig) map[string]interface{} {
+	spec := map[string]interface{}{
+		"vaultUrl": azure.VaultUrl,
+		"authType": azure.AuthType,
+	}
+	if len(azure.TenantId) > 0 {
+		spec["tenantId"] = azure.TenantId
+	}
+	switch azure.AuthType {
+	case AzureAuthTypeServicePrincipal:
+		spec["authSecretRef"] = map[string]interface{}{
+			"clientId":     secretKeySelector(azure.ClientIdRef),
+			"clientSecret": secretKeySelector(azure.ClientSecretRef),
+		}
+	case AzureAuthTypeManagedIdentity:
+		if len(azure.IdentityId) > 0 {
+			spec["identityId"] = azure.IdentityId
+		}
+	case AzureAuthTypeWorkloadIdentity:
+		spec["serviceAccountRef"] = map[string]interface{}{"name": azure.ServiceAccountName}
+	}
+	return spec
+}
+
+func secretKeySelector(ref *SecretKeyRef) map[string]interface{} {
+	return map[string]interface{}{"name": ref.Name, "key": ref.Key}
+}
@@ -0,0 +1,74 @@
+package externalSecret
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSecretStore(t *testing.T) {
+	t.Run("vault kubernetes auth", func(t *testing.T) {
+		provider := &ProviderConfig{Vault: &VaultProviderConfig{
+			Server:         "https://vault.example.com",
+			Path:           "secret",
+			AuthType:       VaultAuthTypeKubernetes,
+			KubernetesAuth: &VaultKubernetesAuth{MountPath: "kubernetes", Role: "devtron"},
+		}}
+		store, err := BuildSecretStore(util.HashiCorpVaultKV2, provider)
+		assert.Nil(t, err)
+		vault := store["vault"].(map[string]interface{})
+		assert.Equal(t, "v2", vault["version"])
+		assert.Equal(t, "secret", vault["path"])
+		auth := vault["auth"].(map[string]interface{})
+		assert.Contains(t, auth, "kubernetes")
+	})
+	t.Run("gcp workload identity", func(t *testing.T) {
+		provider := &ProviderConfig{GCPSecretManager: &GCPSecretManagerProviderConfig{
+			ProjectId:        "devtron-project",
+			AuthType:         GCPAuthTypeWorkloadIdentity,
+			WorkloadIdentity: &GCPWorkloadIdentity{ClusterLocation: "us-central1", ClusterName: "prod", ServiceAccountName: "eso"},
+		}}
+		store, err := BuildSecretStore(util.GCPSecretManager, provider)
+		assert.Nil(t, err)
+		gcpsm := store["gcpsm"].(map[string]interface{})
+		assert.Equal(t, "devtron-project", gcpsm["projectID"])
+	})
+	t.Run("azure service principal without secret refs", func(t *testing.T) {
+		provider := &ProviderConfig{AzureKeyVault: &AzureKeyVaultProviderConfig{
+			VaultUrl: "https://devtron.vault.azure.net",
+			TenantId: "tenant",
+			AuthType: AzureAuthTypeServicePrincipal,
+		}}
+		_, err := BuildSecretStore(util.AzureKeyVault, provider)
+		assert.NotNil(t, err)
+	})
+	t.Run("provider block not matching type", func(t *testing.T) {
+		provider := &ProviderConfig{AzureKeyVault: &AzureKeyVaultProviderConfig{VaultUrl: "https://devtron.vault.azure.net", AuthType: AzureAuthTypeManagedIdentity}}
+		_, err := BuildSecretStore(util.HashiCorpVaultKV2, provider)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestValidateRemoteKeys(t *testing.T) {
+	tests := []struct {
+		name         string
+		externalType string
+		keys         []string
+		wantErr      bool
+	}{
+		{name: "vault path", externalType: util.HashiCorpVaultKV2, keys: []string{"team/app/db"}, wantErr: false},
+		{name: "vault absolute path", externalType: util.HashiCorpVaultKV2, keys: []string{"/team/app"}, wantErr: true},
+		{name: "gcp name", externalType: util.GCPSecretManager, keys: []string{"db-password_1"}, wantErr: false},
+		{name: "gcp name with path", externalType: util.GCPSecretManager, keys: []string{"team/db"}, wantErr: true},
+		{name: "azure prefixed name", externalType: util.AzureKeyVault, keys: []string{"secret/db-password"}, wantErr: false},
+		{name: "azure underscore", externalType: util.AzureKeyVault, keys: []string{"db_password"}, wantErr: true},
+		{name: "empty key", externalType: util.AzureKeyVault, keys: []string{" "}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRemoteKeys(tt.externalType, tt.keys)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package externalSecret
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	VaultAuthTypeKubernetes = "kubernetes"
+	VaultAuthTypeAppRole    = "appRole"
+
+	GCPAuthTypeWorkloadIdentity  = "workloadIdentity"
+	GCPAuthTypeServiceAccountKey = "serviceAccountKey"
+
+	AzureAuthTypeServicePrincipal = "ServicePrincipal"
+	AzureAuthTypeManagedIdentity  = "ManagedIdentity"
+	AzureAuthTypeWorkloadIdentity = "WorkloadIdentity"
+
+	vaultKVVersion          = "v2"
+	connectionTestTimeout   = 10 * time.Second
+	gcpSecretManagerBaseUrl = "https://secretmanager.googleapis.com/v1"
+	gcpCloudPlatformScope   = "https://www.googleapis.com/auth/cloud-platform"
+	azureKeyVaultScope      = "https://vault.azure.net/.default"
+	azureKeyVaultApiVersion = "7.3"
+)
+
+// ProviderConfig is the typed secret store definition of a first-class external secret provider,
+// exactly one of the provider blocks is expected to be set, matching the external type of the secret
+type ProviderConfig struct {
+	Vault            *VaultProviderConfig            `json:"vault,omitempty"`
+	GCPSecretManager *GCPSecretManagerProviderConfig `json:"gcpSecretManager,omitempty"`
+	AzureKeyVault    *AzureKeyVaultProviderConfig    `json:"azureKeyVault,omitempty"`
+}
+
+type SecretKeyRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// VaultProviderConfig describes a HashiCorp Vault KV v2 secrets engine
+type VaultProviderConfig struct {
+	Server         string               `json:"server"`
+	Path           string               `json:"path"`
+	Namespace      string               `json:"namespace,omitempty"`
+	CABundle       string               `json:"caBundle,omitempty"`
+	AuthType       string               `json:"authType"`
+	KubernetesAuth *VaultKubernetesAuth `json:"kubernetesAuth,omitempty"`
+	AppRoleAuth    *VaultAppRoleAuth    `json:"appRoleAuth,omitempty"`
+}
+
+type VaultKubernetesAuth struct {
+	MountPath          string `json:"mountPath"`
+	Role               string `json:"role"`
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+type VaultAppRoleAuth struct {
+	Path      string        `json:"path"`
+	RoleId    string        `json:"roleId"`
+	SecretRef *SecretKeyRef `json:"secretRef"`
+}
+
+type GCPSecretManagerProviderConfig struct {
+	ProjectId          string               `json:"projectId"`
+	AuthType           string               `json:"authType"`
+	WorkloadIdentity   *GCPWorkloadIdentity `json:"workloadIdentity,omitempty"`
+	SecretAccessKeyRef *SecretKeyRef        `json:"secretAccessKeyRef,omitempty"`
+}
+
+type GCPWorkloadIdentity struct {
+	ClusterLocation    string `json:"clusterLocation"`
+	ClusterName        string `json:"clusterName"`
+	ClusterProjectId   string `json:"clusterProjectId,omitempty"`
+	ServiceAccountName string `json:"serviceAccountName"`
+}
+
+type AzureKeyVaultProviderConfig struct {
+	VaultUrl           string        `json:"vaultUrl"`
+	TenantId           string        `json:"tenantId,omitempty"`
+	AuthType           string        `json:"authType"`
+	ClientIdRef        *SecretKeyRef `json:"clientIdRef,omitempty"`
+	ClientSecretRef    *SecretKeyRef `json:"clientSecretRef,omitempty"`
+	IdentityId         string        `json:"identityId,omitempty"`
+	ServiceAccountName string        `json:"serviceAccountName,omitempty"`
+}
+
+type DefaultSecretStoreDto struct {
+	Id             int             `json:"id"`
+	EnvironmentId  int             `json:"environmentId" validate:"number,gt=0"`
+	ExternalType   string          `json:"externalType" validate:"oneof=HashiCorpVaultKV2 GCPSecretManager AzureKeyVault"`
+	Provider       *ProviderConfig `json:"provider,omitempty"`
+	SecretStoreRef json.RawMessage `json:"secretStoreRef,omitempty"`
+	UserId         int32           `json:"-"`
+}
+
+// ConnectionTestRequest carries the provider config along with credentials which are only used for the
+// test and never persisted, as the real credentials live in kubernetes secrets of the target cluster
+type ConnectionTestRequest struct {
+	ExternalType string           `json:"externalType" validate:"oneof=HashiCorpVaultKV2 GCPSecretManager AzureKeyVault"`
+	Provider     *ProviderConfig  `json:"provider" validate:"required"`
+	Credentials  *TestCredentials `json:"credentials,omitempty"`
+}
+
+type TestCredentials struct {
+	VaultAppRoleSecretId string `json:"vaultAppRoleSecretId,omitempty"`
+	VaultToken           string `json:"vaultToken,omitempty"`
+	GCPServiceAccountKey string `json:"gcpServiceAccountKey,omitempty"`
+	AzureClientId        string `json:"azureClientId,omitempty"`
+	AzureClientSecret    string `json:"azureClientSecret,omitempty"`
+}
+
+// SecretValidationRequest is the provider relevant part of a secret being saved,
+// EnvId is zero for app level secrets where the environment default store is not known yet
+type SecretValidationRequest struct {
+	EnvId          int
+	ExternalType   string
+	Provider       *ProviderConfig
+	HasSecretStore bool
+	RemoteKeys     []string
+}
+
+type ConnectionTestResponse struct {
+	Reachable     bool   `json:"reachable"`
+	Authenticated bool   `json:"authenticated"`
+	Message       string `json:"message"`
+	LatencyMs     int64  `json:"latencyMs"`
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+// ExternalSecretStore is the default secret store of an environment for one external secret provider type,
+// used for secrets which do not carry their own provider config or secret store
+type ExternalSecretStore struct {
+	tableName      struct{} `sql:"external_secret_store" pg:",discard_unknown_columns"`
+	Id             int      `sql:"id,pk"`
+	EnvironmentId  int      `sql:"environment_id,notnull"`
+	ExternalType   string   `sql:"external_type,notnull"`
+	Provider       string   `sql:"provider"`
+	SecretStoreRef string   `sql:"secret_store_ref"`
+	Active         bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type ExternalSecretStoreRepository interface {
+	Save(model *ExternalSecretStore) error
+	Update(model *ExternalSecretStore) error
+	FindById(id int) (*ExternalSecretStore, error)
+	FindActiveByEnvId(envId int) ([]*ExternalSecretStore, error)
+	FindActiveByEnvIdAndType(envId int, externalType string) (*ExternalSecretStore, error)
+}
+
+type ExternalSecretStoreRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewExternalSecretStoreRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *ExternalSecretStoreRepositoryImpl {
+	return &ExternalSecretStoreRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (repo ExternalSecretStoreRepositoryImpl) Save(model *ExternalSecretStore) error {
+	return repo.dbConnection.Insert(model)
+}
+
+func (repo ExternalSecretStoreRepositoryImpl) Update(model *ExternalSecretStore) error {
+	return repo.dbConnection.Update(model)
+}
+
+func (repo ExternalSecretStoreRepositoryImpl) FindById(id int) (*ExternalSecretStore, error) {
+	model := &ExternalSecretStore{}
+	err := repo.dbConnection.Model(model).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo ExternalSecretStoreRepositoryImpl) FindActiveByEnvId(envId int) ([]*ExternalSecretStore, error) {
+	var models []*ExternalSecretStore
+	err := repo.dbConnection.Model(&models).
+		Where("environment_id = ?", envId).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return models, err
+}
+
+func (repo ExternalSecretStoreRepositoryImpl) FindActiveByEnvIdAndType(envId int, externalType string) (*ExternalSecretStore, error) {
+	model := &ExternalSecretStore{}
+	err := repo.dbConnection.Model(model).
+		Where("environment_id = ?", envId).
+		Where("external_type = ?", externalType).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
 	"github.com/devtron-labs/devtron/pkg/bean"
 	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/previewEnv"
 	"go.uber.org/zap"
 )
 
@@ -31,16 +32,19 @@ type GitWebhookService interface {
 }
 
 type GitWebhookServiceImpl struct {
-	logger               *zap.SugaredLogger
-	ciHandler            pipeline.CiHandler
-	gitWebhookRepository repository.GitWebhookRepository
+	logger                    *zap.SugaredLogger
+	ciHandler                 pipeline.CiHandler
+	gitWebhookRepository      repository.GitWebhookRepository
+	previewEnvironmentService previewEnv.PreviewEnvironmentService
 }
 
-func NewGitWebhookServiceImpl(Logger *zap.SugaredLogger, ciHandler pipeline.CiHandler, gitWebhookRepository repository.GitWebhookRepository) *GitWebhookServiceImpl {
+func NewGitWebhookServiceImpl(Logger *zap.SugaredLogger, ciHandler pipeline.CiHandler, gitWebhookRepository repository.GitWebhookRepository,
+	previewEnvironmentService previewEnv.PreviewEnvironmentService) *GitWebhookServiceImpl {
 	return &GitWebhookServiceImpl{
-		logger:               Logger,
-		ciHandler:            ciHandler,
-		gitWebhookRepository: gitWebhookRepository,
+		logger:                    Logger,
+		ciHandler:                 ciHandler,
+		gitWebhookRepository:      gitWebhookRepository,
+		previewEnvironmentService: previewEnvironmentService,
 	}
 }
 
@@ -67,6 +71,9 @@ func (impl *GitWebhookServiceImpl) HandleGitWebhook(gitWebhookRequest gitSensor.
 			EventActionType: webhookData.EventActionType,
 			Data:            webhookData.Data,
 		}
+		//previews of pull requests are created, refreshed or torn down irrespective of ci trigger result, async to not
+		//hold the ci trigger of the event while previews are cloned
+		go impl.previewEnvironmentService.HandleWebhookEvent(gitWebhookRequest.Id, webhookData)
 	}
 
 	resp, err := impl.ciHandler.HandleCIWebhook(bean.GitCiTriggerRequest{
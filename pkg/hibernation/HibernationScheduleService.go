@@ -0,0 +1,665 @@
+package hibernation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	client "github.com/devtron-labs/devtron/api/helm-app"
+	openapi "github.com/devtron-labs/devtron/api/helm-app/openapiClient"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/appGroup"
+	"github.com/devtron-labs/devtron/pkg/bulkAction"
+	"github.com/devtron-labs/devtron/pkg/hibernation/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/argo"
+	"github.com/go-pg/pg"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type HibernationScheduleService interface {
+	CreateSchedule(request *HibernationScheduleDto) (*HibernationScheduleDto, error)
+	UpdateSchedule(request *HibernationScheduleDto) (*HibernationScheduleDto, error)
+	DeleteSchedule(id int, userId int32) error
+	GetSchedule(id int) (*HibernationScheduleDto, error)
+	GetAllSchedules() ([]*HibernationScheduleDto, error)
+	// SkipNextHibernation skips the next scheduled hibernation of schedule, the wakeup after it runs as usual
+	SkipNextHibernation(id int, userId int32) (*HibernationScheduleDto, error)
+	// ExtendSchedule keeps target awake till the extension ends, hibernation missed during extension runs at its end
+	ExtendSchedule(id int, request *HibernationExtendRequest, userId int32) (*HibernationScheduleDto, error)
+	GetScheduleHistory(id int, offset int, size int) ([]*HibernationScheduleHistoryDto, error)
+}
+
+type HibernationScheduleServiceImpl struct {
+	logger                        *zap.SugaredLogger
+	hibernationScheduleRepository repository.HibernationScheduleRepository
+	bulkUpdateService             bulkAction.BulkUpdateService
+	appGroupService               appGroup.AppGroupService
+	helmAppService                client.HelmAppService
+	argoUserService               argo.ArgoUserService
+	cron                          *cron.Cron
+	cronEntries                   map[int][]cron.EntryID
+	cronSpecs                     map[int]string
+	lock                          *sync.Mutex
+}
+
+func NewHibernationScheduleServiceImpl(logger *zap.SugaredLogger, hibernationScheduleRepository repository.HibernationScheduleRepository,
+	bulkUpdateService bulkAction.BulkUpdateService, appGroupService appGroup.AppGroupService, helmAppService client.HelmAppService,
+	argoUserService argo.ArgoUserService) (*HibernationScheduleServiceImpl, error) {
+	impl := &HibernationScheduleServiceImpl{
+		logger:                        logger,
+		hibernationScheduleRepository: hibernationScheduleRepository,
+		bulkUpdateService:             bulkUpdateService,
+		appGroupService:               appGroupService,
+		helmAppService:                helmAppService,
+		argoUserService:               argoUserService,
+		cron:                          cron.New(cron.WithChain()),
+		cronEntries:                   make(map[int][]cron.EntryID),
+		cronSpecs:                     make(map[int]string),
+		lock:                          &sync.Mutex{},
+	}
+	impl.cron.Start()
+	// extensions are checked every minute, schedules themselves get one cron entry per expression
+	_, err := impl.cron.AddFunc("@every 1m", impl.handleExtensionEnds)
+	if err != nil {
+		logger.Errorw("error in adding hibernation extension cron", "err", err)
+		return nil, err
+	}
+	err = impl.syncSchedules()
+	if err != nil {
+		return nil, err
+	}
+	// schedules changed through other instances are picked up by re-syncing from db
+	_, err = impl.cron.AddFunc("@every 1m", func() { _ = impl.syncSchedules() })
+	if err != nil {
+		logger.Errorw("error in adding hibernation schedule sync cron", "err", err)
+		return nil, err
+	}
+	return impl, nil
+}
+
+func (impl *HibernationScheduleServiceImpl) CreateSchedule(request *HibernationScheduleDto) (*HibernationScheduleDto, error) {
+	err := validateHibernationSchedule(request)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := impl.hibernationScheduleRepository.FindByName(request.Name)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting hibernation schedule by name", "name", request.Name, "err", err)
+		return nil, err
+	}
+	if existing != nil && existing.Id > 0 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("hibernation schedule %s already exists", request.Name)}
+	}
+	target, err := json.Marshal(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	model := &repository.HibernationSchedule{
+		Name:          request.Name,
+		Scope:         request.Scope,
+		Target:        string(target),
+		HibernateCron: request.HibernateCron,
+		WakeupCron:    request.WakeupCron,
+		Timezone:      request.Timezone,
+		Active:        true,
+		AuditLog:      sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.UserId, UpdatedOn: time.Now(), UpdatedBy: request.UserId},
+	}
+	err = impl.hibernationScheduleRepository.Save(model)
+	if err != nil {
+		impl.logger.Errorw("error in saving hibernation schedule", "request", request, "err", err)
+		return nil, err
+	}
+	err = impl.registerSchedule(model)
+	if err != nil {
+		return nil, err
+	}
+	return impl.buildScheduleDto(model)
+}
+
+func (impl *HibernationScheduleServiceImpl) UpdateSchedule(request *HibernationScheduleDto) (*HibernationScheduleDto, error) {
+	err := validateHibernationSchedule(request)
+	if err != nil {
+		return nil, err
+	}
+	model, err := impl.hibernationScheduleRepository.FindById(request.Id)
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedule", "id", request.Id, "err", err)
+		return nil, err
+	}
+	if model.Name != request.Name {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: "name of a hibernation schedule cannot be changed"}
+	}
+	target, err := json.Marshal(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	model.Scope = request.Scope
+	model.Target = string(target)
+	model.HibernateCron = request.HibernateCron
+	model.WakeupCron = request.WakeupCron
+	model.Timezone = request.Timezone
+	model.UpdatedBy = request.UserId
+	model.UpdatedOn = time.Now()
+	err = impl.hibernationScheduleRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("error in updating hibernation schedule", "request", request, "err", err)
+		return nil, err
+	}
+	err = impl.registerSchedule(model)
+	if err != nil {
+		return nil, err
+	}
+	return impl.buildScheduleDto(model)
+}
+
+func (impl *HibernationScheduleServiceImpl) DeleteSchedule(id int, userId int32) error {
+	model, err := impl.hibernationScheduleRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedule", "id", id, "err", err)
+		return err
+	}
+	model.Active = false
+	model.UpdatedBy = userId
+	model.UpdatedOn = time.Now()
+	err = impl.hibernationScheduleRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("error in deleting hibernation schedule", "id", id, "err", err)
+		return err
+	}
+	impl.unregisterSchedule(id)
+	return nil
+}
+
+func (impl *HibernationScheduleServiceImpl) GetSchedule(id int) (*HibernationScheduleDto, error) {
+	model, err := impl.hibernationScheduleRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedule", "id", id, "err", err)
+		return nil, err
+	}
+	return impl.buildScheduleDto(model)
+}
+
+func (impl *HibernationScheduleServiceImpl) GetAllSchedules() ([]*HibernationScheduleDto, error) {
+	models, err := impl.hibernationScheduleRepository.FindAllActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting hibernation schedules", "err", err)
+		return nil, err
+	}
+	schedules := make([]*HibernationScheduleDto, 0, len(models))
+	for _, model := range models {
+		schedule, err := impl.buildScheduleDto(model)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+func (impl *HibernationScheduleServiceImpl) SkipNextHibernation(id int, userId int32) (*HibernationScheduleDto, error) {
+	model, err := impl.hibernationScheduleRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedule", "id", id, "err", err)
+		return nil, err
+	}
+	model.SkipNext = true
+	model.UpdatedBy = userId
+	model.UpdatedOn = time.Now()
+	err = impl.hibernationScheduleRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("error in updating hibernation schedule", "id", id, "err", err)
+		return nil, err
+	}
+	return impl.buildScheduleDto(model)
+}
+
+func (impl *HibernationScheduleServiceImpl) ExtendSchedule(id int, request *HibernationExtendRequest, userId int32) (*HibernationScheduleDto, error) {
+	model, err := impl.hibernationScheduleRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedule", "id", id, "err", err)
+		return nil, err
+	}
+	model.ExtendedUntil = time.Now().Add(time.Duration(request.Hours) * time.Hour)
+	model.UpdatedBy = userId
+	model.UpdatedOn = time.Now()
+	if model.State == HIBERNATION_STATE_HIBERNATED {
+		//target is woken up for the extension and hibernated again once it ends
+		impl.executeAction(model, HIBERNATION_ACTION_UNHIBERNATE, HIBERNATION_TRIGGER_EXTENSION_START, userId)
+		model.HibernationPending = true
+	}
+	err = impl.hibernationScheduleRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("error in updating hibernation schedule", "id", id, "err", err)
+		return nil, err
+	}
+	return impl.buildScheduleDto(model)
+}
+
+func (impl *HibernationScheduleServiceImpl) GetScheduleHistory(id int, offset int, size int) ([]*HibernationScheduleHistoryDto, error) {
+	models, err := impl.hibernationScheduleRepository.FindHistory(id, offset, size)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting hibernation schedule history", "id", id, "err", err)
+		return nil, err
+	}
+	history := make([]*HibernationScheduleHistoryDto, 0, len(models))
+	for _, model := range models {
+		dto := &HibernationScheduleHistoryDto{
+			Id:         model.Id,
+			Action:     model.Action,
+			Trigger:    model.Trigger,
+			Status:     model.Status,
+			Message:    model.Message,
+			ExecutedOn: model.ExecutedOn,
+			ExecutedBy: model.ExecutedBy,
+		}
+		if len(model.Response) > 0 {
+			err = json.Unmarshal([]byte(model.Response), &dto.Response)
+			if err != nil {
+				return nil, err
+			}
+		}
+		history = append(history, dto)
+	}
+	return history, nil
+}
+
+// syncSchedules registers active schedules whose expressions differ from the registered ones and unregisters the
+// schedules which are not active anymore
+func (impl *HibernationScheduleServiceImpl) syncSchedules() error {
+	schedules, err := impl.hibernationScheduleRepository.FindAllActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting hibernation schedules", "err", err)
+		return err
+	}
+	active := make(map[int]bool, len(schedules))
+	for _, schedule := range schedules {
+		active[schedule.Id] = true
+		impl.lock.Lock()
+		registeredSpec, ok := impl.cronSpecs[schedule.Id]
+		impl.lock.Unlock()
+		if ok && registeredSpec == scheduleCronSpec(schedule) {
+			continue
+		}
+		err = impl.registerSchedule(schedule)
+		if err != nil {
+			//an invalid stored schedule should not stop the orchestrator from starting
+			impl.logger.Errorw("error in registering hibernation schedule", "id", schedule.Id, "err", err)
+		}
+	}
+	impl.lock.Lock()
+	var inactiveIds []int
+	for id := range impl.cronEntries {
+		if !active[id] {
+			inactiveIds = append(inactiveIds, id)
+		}
+	}
+	impl.lock.Unlock()
+	for _, id := range inactiveIds {
+		impl.unregisterSchedule(id)
+	}
+	return nil
+}
+
+// registerSchedule replaces the cron entries of schedule with entries for its current expressions
+func (impl *HibernationScheduleServiceImpl) registerSchedule(schedule *repository.HibernationSchedule) error {
+	impl.unregisterSchedule(schedule.Id)
+	id := schedule.Id
+	hibernateEntry, err := impl.cron.AddFunc(cronSpecWithTimezone(schedule.HibernateCron, schedule.Timezone), func() { impl.onScheduledHibernate(id) })
+	if err != nil {
+		return err
+	}
+	wakeupEntry, err := impl.cron.AddFunc(cronSpecWithTimezone(schedule.WakeupCron, schedule.Timezone), func() { impl.onScheduledWakeup(id) })
+	if err != nil {
+		impl.cron.Remove(hibernateEntry)
+		return err
+	}
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	impl.cronEntries[id] = []cron.EntryID{hibernateEntry, wakeupEntry}
+	impl.cronSpecs[id] = scheduleCronSpec(schedule)
+	return nil
+}
+
+func (impl *HibernationScheduleServiceImpl) unregisterSchedule(id int) {
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+	for _, entry := range impl.cronEntries[id] {
+		impl.cron.Remove(entry)
+	}
+	delete(impl.cronEntries, id)
+	delete(impl.cronSpecs, id)
+}
+
+func (impl *HibernationScheduleServiceImpl) onScheduledHibernate(id int) {
+	schedule, err := impl.hibernationScheduleRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedule", "id", id, "err", err)
+		return
+	}
+	if schedule.SkipNext {
+		schedule.SkipNext = false
+		impl.saveHistory(schedule, HIBERNATION_ACTION_HIBERNATE, HIBERNATION_TRIGGER_SCHEDULED, HIBERNATION_STATUS_SKIPPED, "skipped once", nil, 1)
+	} else if schedule.ExtendedUntil.After(time.Now()) {
+		schedule.HibernationPending = true
+		impl.saveHistory(schedule, HIBERNATION_ACTION_HIBERNATE, HIBERNATION_TRIGGER_SCHEDULED, HIBERNATION_STATUS_SKIPPED,
+			fmt.Sprintf("extended until %s", schedule.ExtendedUntil.Format(time.RFC3339)), nil, 1)
+	} else {
+		impl.executeAction(schedule, HIBERNATION_ACTION_HIBERNATE, HIBERNATION_TRIGGER_SCHEDULED, 1)
+	}
+	impl.updateScheduleState(schedule, "skip_next", "hibernation_pending")
+}
+
+func (impl *HibernationScheduleServiceImpl) onScheduledWakeup(id int) {
+	schedule, err := impl.hibernationScheduleRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting hibernation schedule", "id", id, "err", err)
+		return
+	}
+	//a hibernation deferred by an extension is not needed anymore once wakeup time is reached
+	schedule.HibernationPending = false
+	impl.executeAction(schedule, HIBERNATION_ACTION_UNHIBERNATE, HIBERNATION_TRIGGER_SCHEDULED, 1)
+	impl.updateScheduleState(schedule, "hibernation_pending")
+}
+
+// handleExtensionEnds hibernates targets whose hibernation got deferred by an extension which has now ended
+func (impl *HibernationScheduleServiceImpl) handleExtensionEnds() {
+	schedules, err := impl.hibernationScheduleRepository.FindAllActive()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting hibernation schedules", "err", err)
+		return
+	}
+	for _, schedule := range schedules {
+		if !schedule.HibernationPending || schedule.ExtendedUntil.After(time.Now()) {
+			continue
+		}
+		schedule.HibernationPending = false
+		schedule.ExtendedUntil = time.Time{}
+		impl.executeAction(schedule, HIBERNATION_ACTION_HIBERNATE, HIBERNATION_TRIGGER_EXTENSION_END, 1)
+		impl.updateScheduleState(schedule, "hibernation_pending", "extended_until")
+	}
+}
+
+// executeAction runs action on every target of schedule, updates state of schedule and records the result in history
+func (impl *HibernationScheduleServiceImpl) executeAction(schedule *repository.HibernationSchedule, action string, trigger string, userId int32) {
+	impl.logger.Infow("executing hibernation schedule", "id", schedule.Id, "action", action, "trigger", trigger)
+	response, err := impl.performAction(schedule, action)
+	status, message := HIBERNATION_STATUS_SUCCESS, ""
+	if err != nil {
+		impl.logger.Errorw("error in executing hibernation schedule", "id", schedule.Id, "action", action, "err", err)
+		status, message = HIBERNATION_STATUS_FAILED, err.Error()
+	} else {
+		status = hibernationResultStatus(response)
+	}
+	if status != HIBERNATION_STATUS_FAILED {
+		schedule.State = HIBERNATION_STATE_AWAKE
+		if action == HIBERNATION_ACTION_HIBERNATE {
+			schedule.State = HIBERNATION_STATE_HIBERNATED
+		}
+	}
+	impl.saveHistory(schedule, action, trigger, status, message, response, userId)
+}
+
+func (impl *HibernationScheduleServiceImpl) performAction(schedule *repository.HibernationSchedule, action string) (map[string]map[string]bool, error) {
+	target := &HibernationTarget{}
+	err := json.Unmarshal([]byte(schedule.Target), target)
+	if err != nil {
+		return nil, err
+	}
+	acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		impl.logger.Errorw("error in getting acd token", "err", err)
+		return nil, err
+	}
+	ctx := context.WithValue(context.Background(), "token", acdToken)
+	//access on targets is checked when schedule is saved
+	checkAuth := func(token string, appObject string, envObject string) bool { return true }
+	checkAuthForAppGroup := func(token string, appGroupObject string) bool { return true }
+	response := make(map[string]map[string]bool)
+	if schedule.Scope == HIBERNATION_SCOPE_HELM_APP {
+		for _, helmAppId := range target.HelmAppIds {
+			response[helmAppId] = impl.performHelmAppAction(ctx, helmAppId, action)
+		}
+		return response, nil
+	}
+	for _, envId := range target.EnvIds {
+		var envResponse map[string]map[string]bool
+		if schedule.Scope == HIBERNATION_SCOPE_APP_GROUP {
+			groupAction := appGroup.APP_GROUP_ACTION_HIBERNATE
+			if action == HIBERNATION_ACTION_UNHIBERNATE {
+				groupAction = appGroup.APP_GROUP_ACTION_UNHIBERNATE
+			}
+			groupResponse, err := impl.appGroupService.PerformAppGroupAction(ctx, &appGroup.AppGroupActionRequest{
+				AppGroupId: target.AppGroupId,
+				Action:     groupAction,
+				EnvId:      envId,
+				UserId:     1,
+			}, "", checkAuthForAppGroup, checkAuth)
+			if err != nil {
+				return response, err
+			}
+			envResponse = groupResponse.Response
+		} else {
+			bulkRequest := &bulkAction.BulkApplicationForEnvironmentPayload{EnvId: envId, UserId: 1}
+			if schedule.Scope == HIBERNATION_SCOPE_APP {
+				bulkRequest.AppIdIncludes = target.AppIds
+			} else if schedule.Scope == HIBERNATION_SCOPE_SELECTOR {
+				bulkRequest.Selector = target.Selector
+			}
+			var bulkResponse *bulkAction.BulkApplicationForEnvironmentResponse
+			if action == HIBERNATION_ACTION_HIBERNATE {
+				bulkResponse, err = impl.bulkUpdateService.BulkHibernate(bulkRequest, ctx, nil, "", checkAuth)
+			} else {
+				bulkResponse, err = impl.bulkUpdateService.BulkUnHibernate(bulkRequest, ctx, nil, "", checkAuth)
+			}
+			if err != nil {
+				return response, err
+			}
+			envResponse = bulkResponse.Response
+		}
+		for appKey, pipelines := range envResponse {
+			if _, ok := response[appKey]; !ok {
+				response[appKey] = make(map[string]bool)
+			}
+			for pipelineKey, success := range pipelines {
+				response[appKey][pipelineKey] = success
+			}
+		}
+	}
+	return response, nil
+}
+
+// performHelmAppAction hibernates every resource of helm app which can be hibernated, or wakes up every hibernated one
+func (impl *HibernationScheduleServiceImpl) performHelmAppAction(ctx context.Context, helmAppId string, action string) map[string]bool {
+	response := make(map[string]bool)
+	appIdentifier, err := impl.helmAppService.DecodeAppId(helmAppId)
+	if err != nil {
+		impl.logger.Errorw("error in decoding helm app id", "appId", helmAppId, "err", err)
+		response[helmAppId] = false
+		return response
+	}
+	appDetail, err := impl.helmAppService.GetApplicationDetail(ctx, appIdentifier)
+	if err != nil || appDetail.ResourceTreeResponse == nil {
+		impl.logger.Errorw("error in getting helm app detail", "appId", helmAppId, "err", err)
+		response[helmAppId] = false
+		return response
+	}
+	var resources []openapi.HibernateTargetObject
+	for _, node := range appDetail.ResourceTreeResponse.Nodes {
+		if (action == HIBERNATION_ACTION_HIBERNATE && node.CanBeHibernated && !node.IsHibernated) ||
+			(action == HIBERNATION_ACTION_UNHIBERNATE && node.IsHibernated) {
+			node := node
+			resources = append(resources, openapi.HibernateTargetObject{
+				Group:     &node.Group,
+				Kind:      &node.Kind,
+				Version:   &node.Version,
+				Name:      &node.Name,
+				Namespace: &node.Namespace,
+			})
+		}
+	}
+	if len(resources) == 0 {
+		return response
+	}
+	hibernateRequest := &openapi.HibernateRequest{Resources: &resources}
+	var statuses []*openapi.HibernateStatus
+	if action == HIBERNATION_ACTION_HIBERNATE {
+		statuses, err = impl.helmAppService.HibernateApplication(ctx, appIdentifier, hibernateRequest)
+	} else {
+		statuses, err = impl.helmAppService.UnHibernateApplication(ctx, appIdentifier, hibernateRequest)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in hibernating helm app", "appId", helmAppId, "action", action, "err", err)
+		response[helmAppId] = false
+		return response
+	}
+	for _, status := range statuses {
+		targetObject := status.GetTargetObject()
+		response[fmt.Sprintf("%s/%s", targetObject.GetKind(), targetObject.GetName())] = status.GetSuccess()
+	}
+	return response
+}
+
+func (impl *HibernationScheduleServiceImpl) saveHistory(schedule *repository.HibernationSchedule, action, trigger, status, message string,
+	response map[string]map[string]bool, userId int32) {
+	history := &repository.HibernationScheduleHistory{
+		HibernationScheduleId: schedule.Id,
+		Action:                action,
+		Trigger:               trigger,
+		Status:                status,
+		Message:               message,
+		ExecutedOn:            time.Now(),
+		ExecutedBy:            userId,
+	}
+	if len(response) > 0 {
+		responseJson, err := json.Marshal(response)
+		if err == nil {
+			history.Response = string(responseJson)
+		}
+	}
+	err := impl.hibernationScheduleRepository.SaveHistory(history)
+	if err != nil {
+		impl.logger.Errorw("error in saving hibernation schedule history", "id", schedule.Id, "action", action, "err", err)
+	}
+}
+
+// updateScheduleState saves state of schedule along with the given run columns, without overwriting the definition of
+// schedule which may have been updated while it ran
+func (impl *HibernationScheduleServiceImpl) updateScheduleState(schedule *repository.HibernationSchedule, columns ...string) {
+	schedule.UpdatedOn = time.Now()
+	columns = append(columns, "state", "updated_on")
+	err := impl.hibernationScheduleRepository.UpdateColumns(schedule, columns...)
+	if err != nil {
+		impl.logger.Errorw("error in updating hibernation schedule", "id", schedule.Id, "err", err)
+	}
+}
+
+func (impl *HibernationScheduleServiceImpl) buildScheduleDto(model *repository.HibernationSchedule) (*HibernationScheduleDto, error) {
+	dto := &HibernationScheduleDto{
+		Id:            model.Id,
+		Name:          model.Name,
+		Scope:         model.Scope,
+		Target:        &HibernationTarget{},
+		HibernateCron: model.HibernateCron,
+		WakeupCron:    model.WakeupCron,
+		Timezone:      model.Timezone,
+		State:         model.State,
+		SkipNext:      model.SkipNext,
+	}
+	err := json.Unmarshal([]byte(model.Target), dto.Target)
+	if err != nil {
+		return nil, err
+	}
+	if model.ExtendedUntil.After(time.Now()) {
+		extendedUntil := model.ExtendedUntil
+		dto.ExtendedUntil = &extendedUntil
+	}
+	dto.NextHibernation = nextCronTime(model.HibernateCron, model.Timezone)
+	dto.NextWakeup = nextCronTime(model.WakeupCron, model.Timezone)
+	return dto, nil
+}
+
+func validateHibernationSchedule(request *HibernationScheduleDto) error {
+	if len(request.Timezone) == 0 {
+		request.Timezone = HIBERNATION_DEFAULT_TIMEZONE
+	}
+	if _, err := time.LoadLocation(request.Timezone); err != nil {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("invalid timezone %s", request.Timezone)}
+	}
+	for _, expression := range []string{request.HibernateCron, request.WakeupCron} {
+		if _, err := cron.ParseStandard(cronSpecWithTimezone(expression, request.Timezone)); err != nil {
+			return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("invalid cron expression %s: %s", expression, err.Error())}
+		}
+	}
+	target := request.Target
+	var missing string
+	switch request.Scope {
+	case HIBERNATION_SCOPE_APP:
+		if len(target.AppIds) == 0 {
+			missing = "appIds"
+		}
+	case HIBERNATION_SCOPE_SELECTOR:
+		if len(target.Selector) == 0 {
+			missing = "selector"
+		}
+	case HIBERNATION_SCOPE_APP_GROUP:
+		if target.AppGroupId == 0 {
+			missing = "appGroupId"
+		}
+	case HIBERNATION_SCOPE_HELM_APP:
+		if len(target.HelmAppIds) == 0 {
+			missing = "helmAppIds"
+		}
+	}
+	if len(missing) == 0 && request.Scope != HIBERNATION_SCOPE_HELM_APP && len(target.EnvIds) == 0 {
+		missing = "envIds"
+	}
+	if len(missing) > 0 {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: fmt.Sprintf("%s are required for scope %s", missing, request.Scope)}
+	}
+	return nil
+}
+
+// hibernationResultStatus is success when every target succeeded, failed when none did and partial otherwise
+func hibernationResultStatus(response map[string]map[string]bool) string {
+	succeeded, failed := 0, 0
+	for _, results := range response {
+		for _, success := range results {
+			if success {
+				succeeded++
+			} else {
+				failed++
+			}
+		}
+	}
+	if failed == 0 {
+		return HIBERNATION_STATUS_SUCCESS
+	} else if succeeded == 0 {
+		return HIBERNATION_STATUS_FAILED
+	}
+	return HIBERNATION_STATUS_PARTIAL
+}
+
+// scheduleCronSpec identifies the cron entries registered for schedule
+func scheduleCronSpec(schedule *repository.HibernationSchedule) string {
+	return fmt.Sprintf("%s|%s|%s", schedule.HibernateCron, schedule.WakeupCron, schedule.Timezone)
+}
+
+func cronSpecWithTimezone(expression string, timezone string) string {
+	if len(timezone) == 0 {
+		timezone = HIBERNATION_DEFAULT_TIMEZONE
+	}
+	return fmt.Sprintf("CRON_TZ=%s %s", timezone, expression)
+}
+
+func nextCronTime(expression string, timezone string) *time.Time {
+	schedule, err := cron.ParseStandard(cronSpecWithTimezone(expression, timezone))
+	if err != nil {
+		return nil
+	}
+	next := schedule.Next(time.Now())
+	return &next
+}
@@ -0,0 +1,141 @@
+package hibernation
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devtron-labs/devtron/pkg/hibernation/repository"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+func TestValidateHibernationSchedule(t *testing.T) {
+	request := &HibernationScheduleDto{
+		Scope:         HIBERNATION_SCOPE_APP,
+		Target:        &HibernationTarget{AppIds: []int{1}, EnvIds: []int{2}},
+		HibernateCron: "0 20 * * 1-5",
+		WakeupCron:    "0 8 * * 1-5",
+	}
+	if err := validateHibernationSchedule(request); err != nil {
+		t.Errorf("unexpected err %v", err)
+	}
+	if request.Timezone != HIBERNATION_DEFAULT_TIMEZONE {
+		t.Errorf("expected default timezone, got %s", request.Timezone)
+	}
+
+	request.Timezone = "Mars/Olympus"
+	if err := validateHibernationSchedule(request); err == nil {
+		t.Errorf("expected err for invalid timezone")
+	}
+	request.Timezone = "Asia/Kolkata"
+	request.WakeupCron = "0 8 * *"
+	if err := validateHibernationSchedule(request); err == nil {
+		t.Errorf("expected err for invalid cron")
+	}
+	request.WakeupCron = "0 8 * * 1-5"
+	request.Target.EnvIds = nil
+	if err := validateHibernationSchedule(request); err == nil {
+		t.Errorf("expected err for missing envIds")
+	}
+
+	helmRequest := &HibernationScheduleDto{
+		Scope:         HIBERNATION_SCOPE_HELM_APP,
+		Target:        &HibernationTarget{HelmAppIds: []string{"1|default|nginx"}},
+		HibernateCron: "0 20 * * *",
+		WakeupCron:    "0 8 * * *",
+	}
+	if err := validateHibernationSchedule(helmRequest); err != nil {
+		t.Errorf("unexpected err %v", err)
+	}
+	helmRequest.Target.HelmAppIds = nil
+	if err := validateHibernationSchedule(helmRequest); err == nil {
+		t.Errorf("expected err for missing helmAppIds")
+	}
+}
+
+func TestHibernationResultStatus(t *testing.T) {
+	success := map[string]map[string]bool{"dev": {"app1": true, "app2": true}}
+	if status := hibernationResultStatus(success); status != HIBERNATION_STATUS_SUCCESS {
+		t.Errorf("expected success, got %s", status)
+	}
+	partial := map[string]map[string]bool{"dev": {"app1": true}, "qa": {"app1": false}}
+	if status := hibernationResultStatus(partial); status != HIBERNATION_STATUS_PARTIAL {
+		t.Errorf("expected partial, got %s", status)
+	}
+	failed := map[string]map[string]bool{"dev": {"app1": false}}
+	if status := hibernationResultStatus(failed); status != HIBERNATION_STATUS_FAILED {
+		t.Errorf("expected failed, got %s", status)
+	}
+}
+
+func TestNextCronTime(t *testing.T) {
+	if spec := cronSpecWithTimezone("0 8 * * *", ""); spec != "CRON_TZ=UTC 0 8 * * *" {
+		t.Errorf("unexpected spec %s", spec)
+	}
+	next := nextCronTime("30 9 * * *", "Asia/Kolkata")
+	if next == nil {
+		t.Fatalf("expected next time")
+	}
+	location, _ := time.LoadLocation("Asia/Kolkata")
+	local := next.In(location)
+	if local.Hour() != 9 || local.Minute() != 30 || !next.After(time.Now()) {
+		t.Errorf("unexpected next time %v", local)
+	}
+	if nextCronTime("invalid", "UTC") != nil {
+		t.Errorf("expected nil for invalid expression")
+	}
+}
+
+type fakeHibernationScheduleRepository struct {
+	repository.HibernationScheduleRepository
+	schedules []*repository.HibernationSchedule
+}
+
+func (repo *fakeHibernationScheduleRepository) FindAllActive() ([]*repository.HibernationSchedule, error) {
+	return repo.schedules, nil
+}
+
+func TestSyncSchedules(t *testing.T) {
+	repo := &fakeHibernationScheduleRepository{schedules: []*repository.HibernationSchedule{
+		{Id: 1, HibernateCron: "0 20 * * 1-5", WakeupCron: "0 8 * * 1-5", Timezone: "UTC"},
+		{Id: 2, HibernateCron: "0 22 * * *", WakeupCron: "0 6 * * *", Timezone: "UTC"},
+	}}
+	impl := &HibernationScheduleServiceImpl{
+		logger:                        zap.NewNop().Sugar(),
+		hibernationScheduleRepository: repo,
+		cron:                          cron.New(),
+		cronEntries:                   make(map[int][]cron.EntryID),
+		cronSpecs:                     make(map[int]string),
+		lock:                          &sync.Mutex{},
+	}
+	if err := impl.syncSchedules(); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if len(impl.cronEntries) != 2 || len(impl.cron.Entries()) != 4 {
+		t.Fatalf("expected both schedules registered, got %v", impl.cronEntries)
+	}
+	unchangedEntries := impl.cronEntries[1]
+
+	//schedule 1 updated and schedule 2 deleted through another instance
+	repo.schedules = []*repository.HibernationSchedule{
+		{Id: 1, HibernateCron: "0 19 * * 1-5", WakeupCron: "0 8 * * 1-5", Timezone: "UTC"},
+	}
+	if err := impl.syncSchedules(); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if _, ok := impl.cronEntries[2]; ok || len(impl.cron.Entries()) != 2 {
+		t.Errorf("expected deleted schedule unregistered, got %v", impl.cronEntries)
+	}
+	if impl.cronEntries[1][0] == unchangedEntries[0] || impl.cronSpecs[1] != scheduleCronSpec(repo.schedules[0]) {
+		t.Errorf("expected updated schedule registered again, got %v", impl.cronSpecs)
+	}
+
+	entries := impl.cronEntries[1]
+	if err := impl.syncSchedules(); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if impl.cronEntries[1][0] != entries[0] {
+		t.Errorf("expected unchanged schedule to keep its entries")
+	}
+}
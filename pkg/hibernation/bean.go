@@ -0,0 +1,81 @@
+package hibernation
+
+import (
+	"time"
+)
+
+// scope of a schedule tells which fields of its target are used
+const (
+	HIBERNATION_SCOPE_APP         = "APP"         // AppIds in EnvIds
+	HIBERNATION_SCOPE_ENVIRONMENT = "ENVIRONMENT" // every app in EnvIds
+	HIBERNATION_SCOPE_SELECTOR    = "SELECTOR"    // apps matching Selector in EnvIds
+	HIBERNATION_SCOPE_APP_GROUP   = "APP_GROUP"   // members of AppGroupId in EnvIds
+	HIBERNATION_SCOPE_HELM_APP    = "HELM_APP"    // HelmAppIds
+)
+
+const (
+	HIBERNATION_ACTION_HIBERNATE   = "HIBERNATE"
+	HIBERNATION_ACTION_UNHIBERNATE = "UNHIBERNATE"
+)
+
+const (
+	HIBERNATION_TRIGGER_SCHEDULED       = "SCHEDULED"
+	HIBERNATION_TRIGGER_EXTENSION_START = "EXTENSION_START"
+	HIBERNATION_TRIGGER_EXTENSION_END   = "EXTENSION_END"
+)
+
+const (
+	HIBERNATION_STATUS_SUCCESS = "SUCCESS"
+	HIBERNATION_STATUS_PARTIAL = "PARTIAL"
+	HIBERNATION_STATUS_FAILED  = "FAILED"
+	HIBERNATION_STATUS_SKIPPED = "SKIPPED"
+)
+
+const (
+	HIBERNATION_STATE_HIBERNATED = "HIBERNATED"
+	HIBERNATION_STATE_AWAKE      = "AWAKE"
+)
+
+const HIBERNATION_DEFAULT_TIMEZONE = "UTC"
+
+type HibernationTarget struct {
+	EnvIds     []int    `json:"envIds,omitempty"`
+	AppIds     []int    `json:"appIds,omitempty"`
+	Selector   string   `json:"selector,omitempty"`
+	AppGroupId int      `json:"appGroupId,omitempty"`
+	HelmAppIds []string `json:"helmAppIds,omitempty"` // app ids of helm apps as encoded by helm app service
+}
+
+// HibernationScheduleDto hibernates target on HibernateCron and wakes it up on WakeupCron, both are standard cron
+// expressions evaluated in Timezone
+type HibernationScheduleDto struct {
+	Id              int                `json:"id"`
+	Name            string             `json:"name" validate:"required,max=250"`
+	Scope           string             `json:"scope" validate:"oneof=APP ENVIRONMENT SELECTOR APP_GROUP HELM_APP"`
+	Target          *HibernationTarget `json:"target" validate:"required"`
+	HibernateCron   string             `json:"hibernateCron" validate:"required"`
+	WakeupCron      string             `json:"wakeupCron" validate:"required"`
+	Timezone        string             `json:"timezone,omitempty"`
+	State           string             `json:"state,omitempty"`
+	SkipNext        bool               `json:"skipNext"`
+	ExtendedUntil   *time.Time         `json:"extendedUntil,omitempty"`
+	NextHibernation *time.Time         `json:"nextHibernation,omitempty"`
+	NextWakeup      *time.Time         `json:"nextWakeup,omitempty"`
+	UserId          int32              `json:"-"`
+}
+
+// HibernationExtendRequest keeps target awake for Hours from now, target is woken up right away when hibernated
+type HibernationExtendRequest struct {
+	Hours int `json:"hours" validate:"min=1,max=168"`
+}
+
+type HibernationScheduleHistoryDto struct {
+	Id         int                        `json:"id"`
+	Action     string                     `json:"action"`
+	Trigger    string                     `json:"trigger"`
+	Status     string                     `json:"status"`
+	Message    string                     `json:"message,omitempty"`
+	Response   map[string]map[string]bool `json:"response,omitempty"`
+	ExecutedOn time.Time                  `json:"executedOn"`
+	ExecutedBy int32                      `json:"executedBy"`
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"time"
+)
+
+type HibernationSchedule struct {
+	tableName          struct{}  `sql:"hibernation_schedule" pg:",discard_unknown_columns"`
+	Id                 int       `sql:"id,pk"`
+	Name               string    `sql:"name,notnull"`
+	Scope              string    `sql:"scope,notnull"`
+	Target             string    `sql:"target,notnull"`
+	HibernateCron      string    `sql:"hibernate_cron,notnull"`
+	WakeupCron         string    `sql:"wakeup_cron,notnull"`
+	Timezone           string    `sql:"timezone,notnull"`
+	State              string    `sql:"state"`
+	SkipNext           bool      `sql:"skip_next,notnull"`
+	ExtendedUntil      time.Time `sql:"extended_until"`
+	HibernationPending bool      `sql:"hibernation_pending,notnull"`
+	Active             bool      `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type HibernationScheduleHistory struct {
+	tableName             struct{}  `sql:"hibernation_schedule_history" pg:",discard_unknown_columns"`
+	Id                    int       `sql:"id,pk"`
+	HibernationScheduleId int       `sql:"hibernation_schedule_id,notnull"`
+	Action                string    `sql:"action,notnull"`
+	Trigger               string    `sql:"trigger,notnull"`
+	Status                string    `sql:"status,notnull"`
+	Message               string    `sql:"message"`
+	Response              string    `sql:"response"`
+	ExecutedOn            time.Time `sql:"executed_on,notnull"`
+	ExecutedBy            int32     `sql:"executed_by"`
+}
+
+type HibernationScheduleRepository interface {
+	Save(model *HibernationSchedule) error
+	Update(model *HibernationSchedule) error
+	// UpdateColumns updates only the given columns of schedule, leaving changes made meanwhile to others intact
+	UpdateColumns(model *HibernationSchedule, columns ...string) error
+	FindById(id int) (*HibernationSchedule, error)
+	FindByName(name string) (*HibernationSchedule, error)
+	FindAllActive() ([]*HibernationSchedule, error)
+	SaveHistory(model *HibernationScheduleHistory) error
+	FindHistory(scheduleId int, offset int, size int) ([]*HibernationScheduleHistory, error)
+}
+
+type HibernationScheduleRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewHibernationScheduleRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *HibernationScheduleRepositoryImpl {
+	return &HibernationScheduleRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (repo HibernationScheduleRepositoryImpl) Save(model *HibernationSchedule) error {
+	return repo.dbConnection.Insert(model)
+}
+
+func (repo HibernationScheduleRepositoryImpl) Update(model *HibernationSchedule) error {
+	return repo.dbConnection.Update(model)
+}
+
+func (repo HibernationScheduleRepositoryImpl) UpdateColumns(model *HibernationSchedule, columns ...string) error {
+	_, err := repo.dbConnection.Model(model).Column(columns...).WherePK().Update()
+	return err
+}
+
+func (repo HibernationScheduleRepositoryImpl) FindById(id int) (*HibernationSchedule, error) {
+	model := &HibernationSchedule{}
+	err := repo.dbConnection.Model(model).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo HibernationScheduleRepositoryImpl) FindByName(name string) (*HibernationSchedule, error) {
+	model := &HibernationSchedule{}
+	err := repo.dbConnection.Model(model).
+		Where("name = ?", name).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo HibernationScheduleRepositoryImpl) FindAllActive() ([]*HibernationSchedule, error) {
+	var models []*HibernationSchedule
+	err := repo.dbConnection.Model(&models).
+		Where("active = ?", true).
+		Order("name").
+		Select()
+	return models, err
+}
+
+func (repo HibernationScheduleRepositoryImpl) SaveHistory(model *HibernationScheduleHistory) error {
+	return repo.dbConnection.Insert(model)
+}
+
+func (repo HibernationScheduleRepositoryImpl) FindHistory(scheduleId int, offset int, size int) ([]*HibernationScheduleHistory, error) {
+	var models []*HibernationScheduleHistory
+	err := repo.dbConnection.Model(&models).
+		Where("hibernation_schedule_id = ?", scheduleId).
+		Order("executed_on DESC").
+		Offset(offset).
+		Limit(size).
+		Select()
+	return models, err
+}
@@ -0,0 +1,73 @@
+package kubernetesResourceAuditLogs
+
+import (
+	"encoding/json"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"time"
+)
+
+const (
+	AUDIT_ACTION_CREATE          = "create"
+	AUDIT_ACTION_UPDATE          = "update"
+	AUDIT_ACTION_PATCH           = "patch"
+	AUDIT_ACTION_DELETE          = "delete"
+	AUDIT_ACTION_SCALE           = "scale"
+	AUDIT_ACTION_RESTART         = "restart"
+	AUDIT_ACTION_CORDON          = "cordon"
+	AUDIT_ACTION_UNCORDON        = "uncordon"
+	AUDIT_ACTION_DRAIN           = "drain"
+	AUDIT_ACTION_EDIT_TAINTS     = "edit_taints"
+	AUDIT_ACTION_TERMINAL_START  = "terminal_start"
+	AUDIT_ACTION_DEBUG_CONTAINER = "debug_container_create"
+	AUDIT_ACTION_PORT_FORWARD    = "port_forward"
+)
+
+const (
+	AUDIT_STATUS_SUCCEEDED = "Succeeded"
+	AUDIT_STATUS_FAILED    = "Failed"
+)
+
+// K8sResourceAuditLogRequest describes a mutation to be recorded, Before is nil for created and After is nil for
+// deleted resources. User and client ip are taken from context set by WithAuditContext.
+type K8sResourceAuditLogRequest struct {
+	ClusterId        int
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+	Action           string
+	Before           *unstructured.Unstructured
+	After            *unstructured.Unstructured
+	Message          string
+	Err              error
+}
+
+type K8sResourceAuditLogBean struct {
+	Id           int             `json:"id"`
+	ClusterId    int             `json:"clusterId"`
+	ClusterName  string          `json:"clusterName"`
+	Group        string          `json:"group"`
+	Version      string          `json:"version"`
+	Kind         string          `json:"kind"`
+	Namespace    string          `json:"namespace"`
+	ResourceName string          `json:"resourceName"`
+	Action       string          `json:"action"`
+	Status       string          `json:"status"`
+	Message      string          `json:"message,omitempty"`
+	Diff         json.RawMessage `json:"diff,omitempty"`
+	ClientIp     string          `json:"clientIp"`
+	UserId       int32           `json:"userId"`
+	UserEmail    string          `json:"userEmail"`
+	CreatedOn    time.Time       `json:"createdOn"`
+}
+
+type K8sResourceAuditLogListResponse struct {
+	TotalCount int                        `json:"totalCount"`
+	AuditLogs  []*K8sResourceAuditLogBean `json:"auditLogs"`
+}
+
+type K8sResourceAuditLogConfig struct {
+	// audit logs older than retention days are deleted by cleanup cron, non positive value keeps them forever
+	RetentionDays       int `env:"K8S_RESOURCE_AUDIT_LOG_RETENTION_DAYS" envDefault:"90"`
+	CleanupCronTimeMins int `env:"K8S_RESOURCE_AUDIT_LOG_CLEANUP_CRON_TIME" envDefault:"60"`
+}
@@ -0,0 +1,218 @@
+package kubernetesResourceAuditLogs
+
+import (
+	"context"
+	"fmt"
+	"github.com/caarlos0/env/v6"
+	"github.com/devtron-labs/devtron/client/k8s/application"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs/repository"
+	repository3 "github.com/devtron-labs/devtron/pkg/user/repository"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	defaultAuditLogPageSize = 20
+	maxAuditLogPageSize     = 500
+)
+
+type auditContextKey struct{}
+
+type auditContext struct {
+	userId   int32
+	clientIp string
+}
+
+// WithAuditContext returns ctx carrying user and client ip of the request, mutations done with this ctx are
+// recorded against them
+func WithAuditContext(ctx context.Context, userId int32, clientIp string) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, &auditContext{userId: userId, clientIp: clientIp})
+}
+
+func auditContextFrom(ctx context.Context) (int32, string) {
+	if ctx == nil {
+		return 0, ""
+	}
+	if auditCtx, ok := ctx.Value(auditContextKey{}).(*auditContext); ok {
+		return auditCtx.userId, auditCtx.clientIp
+	}
+	return 0, ""
+}
+
+type K8sResourceAuditLogService interface {
+	SaveAuditLog(ctx context.Context, request *K8sResourceAuditLogRequest)
+	GetAuditLogs(filter *repository.K8sResourceAuditLogFilter) (*K8sResourceAuditLogListResponse, error)
+}
+
+type K8sResourceAuditLogServiceImpl struct {
+	logger                        *zap.SugaredLogger
+	k8sResourceAuditLogRepository repository.K8sResourceAuditLogRepository
+	clusterRepository             repository2.ClusterRepository
+	userRepository                repository3.UserRepository
+	config                        *K8sResourceAuditLogConfig
+}
+
+func NewK8sResourceAuditLogServiceImpl(logger *zap.SugaredLogger, k8sResourceAuditLogRepository repository.K8sResourceAuditLogRepository,
+	clusterRepository repository2.ClusterRepository, userRepository repository3.UserRepository) (*K8sResourceAuditLogServiceImpl, error) {
+	config := &K8sResourceAuditLogConfig{}
+	err := env.Parse(config)
+	if err != nil {
+		logger.Errorw("error in parsing k8s resource audit log config", "err", err)
+		return nil, err
+	}
+	impl := &K8sResourceAuditLogServiceImpl{
+		logger:                        logger,
+		k8sResourceAuditLogRepository: k8sResourceAuditLogRepository,
+		clusterRepository:             clusterRepository,
+		userRepository:                userRepository,
+		config:                        config,
+	}
+	if config.RetentionDays > 0 {
+		cleanupCron := cron.New(cron.WithChain())
+		cleanupCron.Start()
+		_, err = cleanupCron.AddFunc(fmt.Sprintf("@every %dm", config.CleanupCronTimeMins), impl.deleteExpiredAuditLogs)
+		if err != nil {
+			logger.Errorw("error in adding k8s resource audit log cleanup cron", "err", err)
+			return nil, err
+		}
+	}
+	return impl, nil
+}
+
+// SaveAuditLog records the mutation, errors are only logged so that audit never fails the mutation itself
+func (impl *K8sResourceAuditLogServiceImpl) SaveAuditLog(ctx context.Context, request *K8sResourceAuditLogRequest) {
+	model, err := buildAuditLogModel(ctx, request)
+	if err != nil {
+		impl.logger.Errorw("error in building diff for k8s resource audit log", "err", err, "kind", request.GroupVersionKind.Kind, "name", request.Name)
+	}
+	err = impl.k8sResourceAuditLogRepository.Save(model)
+	if err != nil {
+		impl.logger.Errorw("error in saving k8s resource audit log", "err", err, "clusterId", request.ClusterId, "kind", request.GroupVersionKind.Kind, "name", request.Name, "action", request.Action)
+	}
+}
+
+// buildAuditLogModel always returns a model, diff is left empty when it can not be built
+func buildAuditLogModel(ctx context.Context, request *K8sResourceAuditLogRequest) (*repository.K8sResourceAuditLog, error) {
+	userId, clientIp := auditContextFrom(ctx)
+	model := &repository.K8sResourceAuditLog{
+		ClusterId:    request.ClusterId,
+		Group:        request.GroupVersionKind.Group,
+		Version:      request.GroupVersionKind.Version,
+		Kind:         request.GroupVersionKind.Kind,
+		Namespace:    request.Namespace,
+		ResourceName: request.Name,
+		Action:       request.Action,
+		Status:       AUDIT_STATUS_SUCCEEDED,
+		Message:      request.Message,
+		ClientIp:     clientIp,
+		CreatedBy:    userId,
+		CreatedOn:    time.Now(),
+	}
+	if request.Err != nil {
+		model.Status = AUDIT_STATUS_FAILED
+		if len(model.Message) > 0 {
+			model.Message = fmt.Sprintf("%s, err: %s", model.Message, request.Err.Error())
+		} else {
+			model.Message = request.Err.Error()
+		}
+	}
+	//only keys of secrets are kept, with the ones whose value is changed marked
+	before, after := application.MaskSecretData(request.Before, request.After)
+	diff, err := application.CreateManifestDiff(before, after)
+	if err != nil {
+		return model, err
+	}
+	model.Diff = string(diff)
+	return model, nil
+}
+
+func (impl *K8sResourceAuditLogServiceImpl) GetAuditLogs(filter *repository.K8sResourceAuditLogFilter) (*K8sResourceAuditLogListResponse, error) {
+	if filter.Size <= 0 {
+		filter.Size = defaultAuditLogPageSize
+	} else if filter.Size > maxAuditLogPageSize {
+		filter.Size = maxAuditLogPageSize
+	}
+	models, count, err := impl.k8sResourceAuditLogRepository.FindByFilter(filter)
+	if err != nil {
+		impl.logger.Errorw("error in getting k8s resource audit logs", "err", err, "filter", filter)
+		return nil, err
+	}
+	response := &K8sResourceAuditLogListResponse{TotalCount: count, AuditLogs: make([]*K8sResourceAuditLogBean, 0, len(models))}
+	if len(models) == 0 {
+		return response, nil
+	}
+	clusterNames, userEmails := impl.getClusterNamesAndUserEmails(models)
+	for _, model := range models {
+		bean := &K8sResourceAuditLogBean{
+			Id:           model.Id,
+			ClusterId:    model.ClusterId,
+			ClusterName:  clusterNames[model.ClusterId],
+			Group:        model.Group,
+			Version:      model.Version,
+			Kind:         model.Kind,
+			Namespace:    model.Namespace,
+			ResourceName: model.ResourceName,
+			Action:       model.Action,
+			Status:       model.Status,
+			Message:      model.Message,
+			ClientIp:     model.ClientIp,
+			UserId:       model.CreatedBy,
+			UserEmail:    userEmails[model.CreatedBy],
+			CreatedOn:    model.CreatedOn,
+		}
+		if len(model.Diff) > 0 {
+			bean.Diff = []byte(model.Diff)
+		}
+		response.AuditLogs = append(response.AuditLogs, bean)
+	}
+	return response, nil
+}
+
+// getClusterNamesAndUserEmails resolves names for display, logs of deleted clusters and users are still returned
+func (impl *K8sResourceAuditLogServiceImpl) getClusterNamesAndUserEmails(models []*repository.K8sResourceAuditLog) (map[int]string, map[int32]string) {
+	var clusterIds []int
+	var userIds []int32
+	clusterNames := make(map[int]string)
+	userEmails := make(map[int32]string)
+	for _, model := range models {
+		if _, ok := clusterNames[model.ClusterId]; !ok {
+			clusterNames[model.ClusterId] = ""
+			clusterIds = append(clusterIds, model.ClusterId)
+		}
+		if _, ok := userEmails[model.CreatedBy]; !ok && model.CreatedBy > 0 {
+			userEmails[model.CreatedBy] = ""
+			userIds = append(userIds, model.CreatedBy)
+		}
+	}
+	clusters, err := impl.clusterRepository.FindByIds(clusterIds)
+	if err != nil {
+		impl.logger.Errorw("error in getting clusters for audit logs", "err", err, "clusterIds", clusterIds)
+	}
+	for _, cluster := range clusters {
+		clusterNames[cluster.Id] = cluster.ClusterName
+	}
+	if len(userIds) > 0 {
+		users, err := impl.userRepository.GetByIds(userIds)
+		if err != nil {
+			impl.logger.Errorw("error in getting users for audit logs", "err", err, "userIds", userIds)
+		}
+		for _, user := range users {
+			userEmails[user.Id] = user.EmailId
+		}
+	}
+	return clusterNames, userEmails
+}
+
+func (impl *K8sResourceAuditLogServiceImpl) deleteExpiredAuditLogs() {
+	retainFrom := time.Now().AddDate(0, 0, -impl.config.RetentionDays)
+	deleted, err := impl.k8sResourceAuditLogRepository.DeleteCreatedBefore(retainFrom)
+	if err != nil {
+		impl.logger.Errorw("error in deleting expired k8s resource audit logs", "err", err, "retainFrom", retainFrom)
+		return
+	}
+	if deleted > 0 {
+		impl.logger.Infow("deleted expired k8s resource audit logs", "count", deleted, "retainFrom", retainFrom)
+	}
+}
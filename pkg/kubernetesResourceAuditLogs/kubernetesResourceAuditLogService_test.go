@@ -0,0 +1,109 @@
+package kubernetesResourceAuditLogs
+
+import (
+	"context"
+	"fmt"
+	"github.com/devtron-labs/devtron/client/k8s/application"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+	"testing"
+)
+
+func newTestDeployment(resourceVersion string, replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "api", "namespace": "prod", "resourceVersion": resourceVersion},
+		"spec":       map[string]interface{}{"replicas": replicas},
+		"status":     map[string]interface{}{"readyReplicas": replicas},
+	}}
+}
+
+func TestBuildAuditLogModel(t *testing.T) {
+	ctx := WithAuditContext(context.Background(), 7, "10.0.0.1")
+	request := &K8sResourceAuditLogRequest{
+		ClusterId:        1,
+		GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Namespace:        "prod",
+		Name:             "api",
+		Action:           AUDIT_ACTION_UPDATE,
+		Before:           newTestDeployment("10", 2),
+		After:            newTestDeployment("11", 3),
+	}
+	model, err := buildAuditLogModel(ctx, request)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if model.CreatedBy != 7 || model.ClientIp != "10.0.0.1" || model.Status != AUDIT_STATUS_SUCCEEDED || model.Group != "apps" || model.ResourceName != "api" {
+		t.Errorf("unexpected model %+v", model)
+	}
+	if model.Diff != `{"spec":{"replicas":3}}` {
+		t.Errorf("unexpected diff %s", model.Diff)
+	}
+
+	request.After = nil
+	request.Action = AUDIT_ACTION_DELETE
+	request.Message = "delete requested"
+	request.Err = fmt.Errorf("forbidden")
+	model, err = buildAuditLogModel(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if model.CreatedBy != 0 || model.Status != AUDIT_STATUS_FAILED || model.Message != "delete requested, err: forbidden" {
+		t.Errorf("unexpected failed model %+v", model)
+	}
+	if model.Diff != `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"api","namespace":"prod"},"spec":{"replicas":2}}` {
+		t.Errorf("expected normalized deleted manifest as diff, got %s", model.Diff)
+	}
+}
+
+func TestBuildAuditLogModelWithoutChange(t *testing.T) {
+	request := &K8sResourceAuditLogRequest{Action: AUDIT_ACTION_PATCH, Before: newTestDeployment("10", 2), After: newTestDeployment("12", 2)}
+	model, err := buildAuditLogModel(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if len(model.Diff) != 0 {
+		t.Errorf("expected empty diff when only server managed fields change, got %s", model.Diff)
+	}
+}
+
+func newTestSecret(data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "db-creds", "namespace": "prod"},
+		"data":       data,
+	}}
+}
+
+func TestBuildAuditLogModelOfSecret(t *testing.T) {
+	request := &K8sResourceAuditLogRequest{
+		Action: AUDIT_ACTION_PATCH,
+		Before: newTestSecret(map[string]interface{}{"username": "YWRtaW4=", "password": "b2xkLXBhc3N3b3Jk"}),
+		After:  newTestSecret(map[string]interface{}{"username": "YWRtaW4=", "password": "bmV3LXBhc3N3b3Jk"}),
+	}
+	model, err := buildAuditLogModel(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if strings.Contains(model.Diff, "bmV3LXBhc3N3b3Jk") || strings.Contains(model.Diff, "b2xkLXBhc3N3b3Jk") {
+		t.Errorf("expected values of secret not kept in diff, got %s", model.Diff)
+	}
+	if !strings.Contains(model.Diff, `"password":"`+application.MASKED_CHANGED_SECRET_VALUE+`"`) || strings.Contains(model.Diff, "username") {
+		t.Errorf("expected only changed key marked in diff, got %s", model.Diff)
+	}
+	if request.After.Object["data"].(map[string]interface{})["password"] != "bmV3LXBhc3N3b3Jk" {
+		t.Errorf("expected manifest of request left as it is")
+	}
+
+	request = &K8sResourceAuditLogRequest{Action: AUDIT_ACTION_CREATE, After: newTestSecret(map[string]interface{}{"password": "bmV3LXBhc3N3b3Jk"})}
+	model, err = buildAuditLogModel(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if strings.Contains(model.Diff, "bmV3LXBhc3N3b3Jk") || !strings.Contains(model.Diff, "password") {
+		t.Errorf("expected keys of created secret without values, got %s", model.Diff)
+	}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+	"go.uber.org/zap"
+	"time"
+)
+
+type K8sResourceAuditLog struct {
+	tableName    struct{}  `sql:"kubernetes_resource_audit_log" pg:",discard_unknown_columns"`
+	Id           int       `sql:"id,pk"`
+	ClusterId    int       `sql:"cluster_id,notnull"`
+	Group        string    `sql:"group"`
+	Version      string    `sql:"version"`
+	Kind         string    `sql:"kind,notnull"`
+	Namespace    string    `sql:"namespace"`
+	ResourceName string    `sql:"resource_name,notnull"`
+	Action       string    `sql:"action,notnull"`
+	Status       string    `sql:"status,notnull"`
+	Message      string    `sql:"message"`
+	Diff         string    `sql:"diff"`
+	ClientIp     string    `sql:"client_ip"`
+	CreatedBy    int32     `sql:"created_by"`
+	CreatedOn    time.Time `sql:"created_on,notnull"`
+}
+
+type K8sResourceAuditLogFilter struct {
+	ClusterId    int
+	UserId       int32
+	Kind         string
+	Namespace    string
+	ResourceName string
+	Action       string
+	From         time.Time
+	To           time.Time
+	Offset       int
+	Size         int
+}
+
+type K8sResourceAuditLogRepository interface {
+	Save(model *K8sResourceAuditLog) error
+	FindByFilter(filter *K8sResourceAuditLogFilter) ([]*K8sResourceAuditLog, int, error)
+	DeleteCreatedBefore(createdOn time.Time) (int, error)
+}
+
+type K8sResourceAuditLogRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewK8sResourceAuditLogRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *K8sResourceAuditLogRepositoryImpl {
+	return &K8sResourceAuditLogRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (repo K8sResourceAuditLogRepositoryImpl) Save(model *K8sResourceAuditLog) error {
+	return repo.dbConnection.Insert(model)
+}
+
+// FindByFilter returns a page of audit logs matching non empty filter fields, latest first, along with total matching count
+func (repo K8sResourceAuditLogRepositoryImpl) FindByFilter(filter *K8sResourceAuditLogFilter) ([]*K8sResourceAuditLog, int, error) {
+	var models []*K8sResourceAuditLog
+	query := repo.dbConnection.Model(&models)
+	query = applyAuditLogFilter(query, filter)
+	count, err := query.
+		Order("created_on DESC").
+		Order("id DESC").
+		Offset(filter.Offset).
+		Limit(filter.Size).
+		SelectAndCount()
+	return models, count, err
+}
+
+func applyAuditLogFilter(query *orm.Query, filter *K8sResourceAuditLogFilter) *orm.Query {
+	if filter.ClusterId > 0 {
+		query = query.Where("cluster_id = ?", filter.ClusterId)
+	}
+	if filter.UserId > 0 {
+		query = query.Where("created_by = ?", filter.UserId)
+	}
+	if len(filter.Kind) > 0 {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if len(filter.Namespace) > 0 {
+		query = query.Where("namespace = ?", filter.Namespace)
+	}
+	if len(filter.ResourceName) > 0 {
+		query = query.Where("resource_name = ?", filter.ResourceName)
+	}
+	if len(filter.Action) > 0 {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_on >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_on <= ?", filter.To)
+	}
+	return query
+}
+
+func (repo K8sResourceAuditLogRepositoryImpl) DeleteCreatedBefore(createdOn time.Time) (int, error) {
+	result, err := repo.dbConnection.
+		Model((*K8sResourceAuditLog)(nil)).
+		Where("created_on < ?", createdOn).
+		Delete()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
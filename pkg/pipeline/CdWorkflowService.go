@@ -32,9 +32,11 @@ import (
 	v1alpha12 "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/typed/workflow/v1alpha1"
 	"github.com/argoproj/argo-workflows/v3/workflow/util"
 	"github.com/devtron-labs/devtron/api/bean"
+	repository3 "github.com/devtron-labs/devtron/internal/sql/repository"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
 	"github.com/devtron-labs/devtron/pkg/app"
 	bean2 "github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/devtron-labs/devtron/pkg/variables"
 	"go.uber.org/zap"
 	v12 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -59,6 +61,9 @@ type CdWorkflowServiceImpl struct {
 	cdConfig      *CdConfig
 	appService    app.AppService
 	envRepository repository.EnvironmentRepository
+
+	globalCMCSService     GlobalCMCSService
+	scopedVariableService variables.ScopedVariableService
 }
 
 type CdWorkflowRequest struct {
@@ -110,9 +115,24 @@ type CdWorkflowRequest struct {
 const PRE = "PRE"
 const POST = "POST"
 
-func NewCdWorkflowServiceImpl(Logger *zap.SugaredLogger, envRepository repository.EnvironmentRepository, cdConfig *CdConfig, appService app.AppService) *CdWorkflowServiceImpl {
+func NewCdWorkflowServiceImpl(Logger *zap.SugaredLogger, envRepository repository.EnvironmentRepository, cdConfig *CdConfig, appService app.AppService,
+	globalCMCSService GlobalCMCSService, scopedVariableService variables.ScopedVariableService) *CdWorkflowServiceImpl {
 	return &CdWorkflowServiceImpl{Logger: Logger, config: cdConfig.ClusterConfig,
-		cdConfig: cdConfig, appService: appService, envRepository: envRepository}
+		cdConfig: cdConfig, appService: appService, envRepository: envRepository, globalCMCSService: globalCMCSService,
+		scopedVariableService: scopedVariableService}
+}
+
+// substituteVariables resolves scoped variable references for the app and env of the stage, collecting resolved values in usedVariables
+func (impl *CdWorkflowServiceImpl) substituteVariables(template string, workflowRequest *CdWorkflowRequest, usedVariables map[string]string) (string, error) {
+	substituted, used, err := impl.scopedVariableService.SubstituteVariables(template, workflowRequest.AppId, workflowRequest.EnvironmentId, false)
+	if err != nil {
+		impl.Logger.Errorw("error in substituting variables", "err", err, "appId", workflowRequest.AppId, "envId", workflowRequest.EnvironmentId)
+		return "", err
+	}
+	for name, value := range used {
+		usedVariables[name] = value
+	}
+	return substituted, nil
 }
 
 func (impl *CdWorkflowServiceImpl) SubmitWorkflow(workflowRequest *CdWorkflowRequest, pipeline *pipelineConfig.Pipeline, env *repository.Environment) (*v1alpha1.Workflow, error) {
@@ -124,6 +144,14 @@ func (impl *CdWorkflowServiceImpl) SubmitWorkflow(workflowRequest *CdWorkflowReq
 	if (workflowRequest.StageType == PRE && pipeline.RunPreStageInEnv) || (workflowRequest.StageType == POST && pipeline.RunPostStageInEnv) {
 		workflowRequest.IsExtRun = true
 	}
+	usedVariables := make(map[string]string)
+	//values resolved into secrets are not to be saved in the snapshot
+	secretVariables := make(map[string]string)
+	stageYaml, err := impl.substituteVariables(workflowRequest.StageYaml, workflowRequest, usedVariables)
+	if err != nil {
+		return nil, err
+	}
+	workflowRequest.StageYaml = stageYaml
 	ciCdTriggerEvent := CiCdTriggerEvent{
 		CdRequest: workflowRequest,
 	}
@@ -219,6 +247,45 @@ func (impl *CdWorkflowServiceImpl) SubmitWorkflow(workflowRequest *CdWorkflowReq
 		secrets.Secrets[i].Name = secrets.Secrets[i].Name + "-" + strconv.Itoa(workflowRequest.WorkflowId) + "-" + strconv.Itoa(workflowRequest.WorkflowRunnerId)
 	}
 
+	//global cm/cs scoped to this stage, app configs selected in the stage take precedence over global ones with same name
+	globalCmCsStage := repository3.GLOBAL_CM_CS_STAGE_PRE_CD
+	if workflowRequest.StageType == POST {
+		globalCmCsStage = repository3.GLOBAL_CM_CS_STAGE_POST_CD
+	}
+	globalCmCsConfigs, err := impl.globalCMCSService.FindAllActiveByScope(globalCmCsStage, workflowRequest.AppId, workflowRequest.EnvironmentId)
+	if err != nil {
+		impl.Logger.Errorw("error in getting global cm/cs config", "err", err, "stage", globalCmCsStage)
+		return nil, err
+	}
+	for _, config := range globalCmCsConfigs {
+		name := config.Name + "-" + strconv.Itoa(workflowRequest.WorkflowId) + "-" + strconv.Itoa(workflowRequest.WorkflowRunnerId)
+		if config.ConfigType == repository3.CM_TYPE_CONFIG {
+			if _, ok := cdPipelineLevelConfigMaps[config.Name]; ok {
+				continue
+			}
+			data, err := json.Marshal(config.Data)
+			if err != nil {
+				impl.Logger.Errorw("error in marshaling global cm data", "err", err, "name", config.Name)
+				return nil, err
+			}
+			configMaps.Maps = append(configMaps.Maps, bean.Map{Name: name, Type: config.Type, MountPath: config.MountPath, Data: data})
+		} else if config.ConfigType == repository3.CS_TYPE_CONFIG {
+			if _, ok := cdPipelineLevelSecrets[config.Name]; ok {
+				continue
+			}
+			secretDataMap := make(map[string][]byte)
+			for key, value := range config.Data {
+				secretDataMap[key] = []byte(value)
+			}
+			data, err := json.Marshal(secretDataMap)
+			if err != nil {
+				impl.Logger.Errorw("error in marshaling global secret data", "err", err, "name", config.Name)
+				return nil, err
+			}
+			secrets.Secrets = append(secrets.Secrets, &bean.Map{Name: name, Type: config.Type, MountPath: config.MountPath, Data: data})
+		}
+	}
+
 	configsMapping := make(map[string]string)
 	secretsMapping := make(map[string]string)
 
@@ -231,6 +298,12 @@ func (impl *CdWorkflowServiceImpl) SubmitWorkflow(workflowRequest *CdWorkflowReq
 				impl.Logger.Errorw("error while unmarshal data", "err", err)
 				return nil, err
 			}
+			for key, value := range datamap {
+				datamap[key], err = impl.substituteVariables(value, workflowRequest, usedVariables)
+				if err != nil {
+					return nil, err
+				}
+			}
 			ownerDelete := true
 			cmBody := v12.ConfigMap{
 				TypeMeta: v1.TypeMeta{
@@ -287,6 +360,13 @@ func (impl *CdWorkflowServiceImpl) SubmitWorkflow(workflowRequest *CdWorkflowReq
 				impl.Logger.Errorw("error while unmarshal data", "err", err)
 				return nil, err
 			}
+			for key, value := range datamap {
+				substituted, err := impl.substituteVariables(string(value), workflowRequest, secretVariables)
+				if err != nil {
+					return nil, err
+				}
+				datamap[key] = []byte(substituted)
+			}
 			ownerDelete := true
 			secretObject := v12.Secret{
 				TypeMeta: v1.TypeMeta{
@@ -333,6 +413,24 @@ func (impl *CdWorkflowServiceImpl) SubmitWorkflow(workflowRequest *CdWorkflowReq
 		}
 	}
 
+	snapshotStage := variables.SNAPSHOT_STAGE_PRE
+	if workflowRequest.StageType == POST {
+		snapshotStage = variables.SNAPSHOT_STAGE_POST
+	}
+	variables.MaskSecretVariables(usedVariables, secretVariables)
+	err = impl.scopedVariableService.SaveSnapshot(&variables.VariableSnapshotDto{
+		AppId:              workflowRequest.AppId,
+		EnvironmentId:      workflowRequest.EnvironmentId,
+		PipelineId:         pipeline.Id,
+		CdWorkflowRunnerId: workflowRequest.WorkflowRunnerId,
+		Stage:              snapshotStage,
+		Variables:          usedVariables,
+		UserId:             workflowRequest.TriggeredBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	var templates []v1alpha1.Template
 	if len(configsMapping) > 0 {
 		for i, cm := range configMaps.Maps {
@@ -475,6 +475,7 @@ func (impl *CiServiceImpl) buildWfRequestForCiPipeline(pipeline *pipelineConfig.
 		PreCiSteps:                 preCiSteps,
 		PostCiSteps:                postCiSteps,
 		RefPlugins:                 refPluginsData,
+		AppId:                      pipeline.AppId,
 		AppName:                    pipeline.App.AppName,
 		TriggerByAuthor:            user.EmailId,
 		CiBuildConfig:              ciBuildConfigBean,
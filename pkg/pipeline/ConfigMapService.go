@@ -25,6 +25,7 @@ import (
 	"github.com/devtron-labs/devtron/internal/util"
 	chartRepoRepository "github.com/devtron-labs/devtron/pkg/chartRepo/repository"
 	"github.com/devtron-labs/devtron/pkg/commonService"
+	"github.com/devtron-labs/devtron/pkg/externalSecret"
 	history2 "github.com/devtron-labs/devtron/pkg/pipeline/history"
 	"github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
 	util2 "github.com/devtron-labs/devtron/util"
@@ -88,6 +89,8 @@ type ESOSecretData struct {
 	SecretStoreRef  json.RawMessage `json:"secretStoreRef,omitempty"`
 	EsoData         []ESOData       `json:"esoData,omitempty"`
 	RefreshInterval string          `json:"refreshInterval,omitempty"`
+	// Provider is the typed secret store of first-class provider types, secret store is rendered from it at deploy time
+	Provider *externalSecret.ProviderConfig `json:"provider,omitempty"`
 }
 
 type ESOData struct {
@@ -156,16 +159,17 @@ type ConfigMapService interface {
 }
 
 type ConfigMapServiceImpl struct {
-	chartRepository             chartRepoRepository.ChartRepository
-	logger                      *zap.SugaredLogger
-	repoRepository              chartRepoRepository.ChartRepoRepository
-	mergeUtil                   util.MergeUtil
-	pipelineConfigRepository    chartConfig.PipelineConfigRepository
-	configMapRepository         chartConfig.ConfigMapRepository
-	environmentConfigRepository chartConfig.EnvConfigOverrideRepository
-	commonService               commonService.CommonService
-	appRepository               app.AppRepository
-	configMapHistoryService     history2.ConfigMapHistoryService
+	chartRepository               chartRepoRepository.ChartRepository
+	logger                        *zap.SugaredLogger
+	repoRepository                chartRepoRepository.ChartRepoRepository
+	mergeUtil                     util.MergeUtil
+	pipelineConfigRepository      chartConfig.PipelineConfigRepository
+	configMapRepository           chartConfig.ConfigMapRepository
+	environmentConfigRepository   chartConfig.EnvConfigOverrideRepository
+	commonService                 commonService.CommonService
+	appRepository                 app.AppRepository
+	configMapHistoryService       history2.ConfigMapHistoryService
+	externalSecretProviderService externalSecret.ExternalSecretProviderService
 }
 
 func NewConfigMapServiceImpl(chartRepository chartRepoRepository.ChartRepository,
@@ -175,18 +179,20 @@ func NewConfigMapServiceImpl(chartRepository chartRepoRepository.ChartRepository
 	pipelineConfigRepository chartConfig.PipelineConfigRepository,
 	configMapRepository chartConfig.ConfigMapRepository, environmentConfigRepository chartConfig.EnvConfigOverrideRepository,
 	commonService commonService.CommonService, appRepository app.AppRepository,
-	configMapHistoryService history2.ConfigMapHistoryService) *ConfigMapServiceImpl {
+	configMapHistoryService history2.ConfigMapHistoryService,
+	externalSecretProviderService externalSecret.ExternalSecretProviderService) *ConfigMapServiceImpl {
 	return &ConfigMapServiceImpl{
-		chartRepository:             chartRepository,
-		logger:                      logger,
-		repoRepository:              repoRepository,
-		mergeUtil:                   mergeUtil,
-		pipelineConfigRepository:    pipelineConfigRepository,
-		configMapRepository:         configMapRepository,
-		environmentConfigRepository: environmentConfigRepository,
-		commonService:               commonService,
-		appRepository:               appRepository,
-		configMapHistoryService:     configMapHistoryService,
+		chartRepository:               chartRepository,
+		logger:                        logger,
+		repoRepository:                repoRepository,
+		mergeUtil:                     mergeUtil,
+		pipelineConfigRepository:      pipelineConfigRepository,
+		configMapRepository:           configMapRepository,
+		environmentConfigRepository:   environmentConfigRepository,
+		commonService:                 commonService,
+		appRepository:                 appRepository,
+		configMapHistoryService:       configMapHistoryService,
+		externalSecretProviderService: externalSecretProviderService,
 	}
 }
 
@@ -537,6 +543,11 @@ func (impl ConfigMapServiceImpl) CSGlobalAddUpdate(configMapRequest *ConfigDataR
 		impl.logger.Errorw("error in validating", "error", err)
 		return configMapRequest, err
 	}
+	err = impl.validateExternalSecretProvider(configMapRequest.EnvironmentId, configData)
+	if err != nil {
+		impl.logger.Errorw("error in validating external secret provider", "error", err)
+		return configMapRequest, err
+	}
 	var model *chartConfig.ConfigMapAppModel
 	if configMapRequest.Id > 0 {
 		model, err = impl.configMapRepository.GetByIdAppLevel(configMapRequest.Id)
@@ -693,6 +704,7 @@ func (impl ConfigMapServiceImpl) CSGlobalFetch(appId int) (*ConfigDataRequest, e
 			SecretStoreRef:  item.ESOSecretData.SecretStoreRef,
 			EsoData:         esoData,
 			RefreshInterval: item.ESOSecretData.RefreshInterval,
+			Provider:        item.ESOSecretData.Provider,
 		}
 		item.ESOSecretData = esoSecretData
 		configs = append(configs, item)
@@ -726,6 +738,11 @@ func (impl ConfigMapServiceImpl) CSEnvironmentAddUpdate(configMapRequest *Config
 		impl.logger.Errorw("error in validating", "error", err)
 		return configMapRequest, err
 	}
+	err = impl.validateExternalSecretProvider(configMapRequest.EnvironmentId, configData)
+	if err != nil {
+		impl.logger.Errorw("error in validating external secret provider", "error", err)
+		return configMapRequest, err
+	}
 	var model *chartConfig.ConfigMapEnvModel
 	if configMapRequest.Id > 0 {
 		model, err = impl.configMapRepository.GetByIdEnvLevel(configMapRequest.Id)
@@ -895,6 +912,7 @@ func (impl ConfigMapServiceImpl) CSEnvironmentFetch(appId int, envId int) (*Conf
 			item.ESOSecretData.SecretStore = nil
 			item.ESOSecretData.SecretStoreRef = nil
 			item.ESOSecretData.RefreshInterval = ""
+			item.ESOSecretData.Provider = nil
 			item.DefaultMountPath = item.MountPath
 			item.Data = nil
 			item.ExternalSecret = nil
@@ -968,6 +986,7 @@ func (impl ConfigMapServiceImpl) CSEnvironmentFetch(appId int, envId int) (*Conf
 			SecretStoreRef:  item.ESOSecretData.SecretStoreRef,
 			EsoData:         esoData,
 			RefreshInterval: item.ESOSecretData.RefreshInterval,
+			Provider:        item.ESOSecretData.Provider,
 		}
 		item.ESOSecretData = esoSecretData
 
@@ -1512,6 +1531,9 @@ func (impl ConfigMapServiceImpl) validateConfigData(configData *ConfigData) (boo
 }
 
 func (impl ConfigMapServiceImpl) updateConfigData(configData *ConfigData, syncRequest *BulkPatchRequest) (*ConfigData, error) {
+	if configData.External && util2.IsESOType(configData.ExternalSecretType) {
+		return impl.updateESOSecretData(configData, syncRequest)
+	}
 	dataMap := make(map[string]string)
 	var updatedData json.RawMessage
 	if configData.Data != nil {
@@ -1554,6 +1576,34 @@ func (impl ConfigMapServiceImpl) updateConfigData(configData *ConfigData, syncRe
 	return configData, nil
 }
 
+// updateESOSecretData patches remote references of external secrets, key is the secret key and value is the remote key
+func (impl ConfigMapServiceImpl) updateESOSecretData(configData *ConfigData, syncRequest *BulkPatchRequest) (*ConfigData, error) {
+	if syncRequest.PatchAction == 1 || syncRequest.PatchAction == 2 {
+		err := externalSecret.ValidateRemoteKeys(configData.ExternalSecretType, []string{syncRequest.Value})
+		if err != nil {
+			return configData, err
+		}
+	}
+	var esoData []ESOData
+	found := false
+	for _, data := range configData.ESOSecretData.EsoData {
+		if data.SecretKey == syncRequest.Key {
+			found = true
+			if syncRequest.PatchAction == 3 {
+				continue
+			} else if syncRequest.PatchAction == 1 || syncRequest.PatchAction == 2 {
+				data.Key = syncRequest.Value
+			}
+		}
+		esoData = append(esoData, data)
+	}
+	if !found && syncRequest.PatchAction == 1 {
+		esoData = append(esoData, ESOData{SecretKey: syncRequest.Key, Key: syncRequest.Value})
+	}
+	configData.ESOSecretData.EsoData = esoData
+	return configData, nil
+}
+
 func (impl ConfigMapServiceImpl) ConfigSecretGlobalBulkPatch(bulkPatchRequest *BulkPatchRequest) (*BulkPatchRequest, error) {
 	_, err := impl.buildBulkPayload(bulkPatchRequest)
 	if err != nil {
@@ -1588,6 +1638,7 @@ func (impl ConfigMapServiceImpl) ConfigSecretGlobalBulkPatch(bulkPatchRequest *B
 						impl.logger.Warnw("error while updating data", "error", err)
 					}
 					item.Data = updatedConfigData.Data
+					item.ESOSecretData = updatedConfigData.ESOSecretData
 				}
 				configs = append(configs, item)
 			}
@@ -1613,6 +1664,7 @@ func (impl ConfigMapServiceImpl) ConfigSecretGlobalBulkPatch(bulkPatchRequest *B
 						impl.logger.Warnw("error while updating data", "error", err)
 					}
 					item.Data = updatedConfigData.Data
+					item.ESOSecretData = updatedConfigData.ESOSecretData
 				}
 				configs = append(configs, item)
 			}
@@ -1678,6 +1730,7 @@ func (impl ConfigMapServiceImpl) ConfigSecretEnvironmentBulkPatch(bulkPatchReque
 						impl.logger.Warnw("error while updating data", "error", err)
 					}
 					item.Data = updatedConfigData.Data
+					item.ESOSecretData = updatedConfigData.ESOSecretData
 				}
 				configs = append(configs, item)
 			}
@@ -1703,6 +1756,7 @@ func (impl ConfigMapServiceImpl) ConfigSecretEnvironmentBulkPatch(bulkPatchReque
 						impl.logger.Debugw("error while updating data", "error", err)
 					}
 					item.Data = updatedConfigData.Data
+					item.ESOSecretData = updatedConfigData.ESOSecretData
 				}
 				configs = append(configs, item)
 			}
@@ -1753,6 +1807,26 @@ func (impl ConfigMapServiceImpl) validateExternalSecretChartCompatibility(appId
 	return true, nil
 }
 
+func (impl ConfigMapServiceImpl) validateExternalSecretProvider(envId int, configData *ConfigData) error {
+	if !configData.External || !util2.IsESOProviderType(configData.ExternalSecretType) {
+		return nil
+	}
+	var remoteKeys []string
+	for _, data := range configData.ESOSecretData.EsoData {
+		if len(data.SecretKey) == 0 {
+			return fmt.Errorf("secret key can not be empty for secret : %s", configData.Name)
+		}
+		remoteKeys = append(remoteKeys, data.Key)
+	}
+	return impl.externalSecretProviderService.ValidateSecret(&externalSecret.SecretValidationRequest{
+		EnvId:          envId,
+		ExternalType:   configData.ExternalSecretType,
+		Provider:       configData.ESOSecretData.Provider,
+		HasSecretStore: len(configData.ESOSecretData.SecretStore) > 0 || len(configData.ESOSecretData.SecretStoreRef) > 0,
+		RemoteKeys:     remoteKeys,
+	})
+}
+
 func (impl ConfigMapServiceImpl) buildBulkPayload(bulkPatchRequest *BulkPatchRequest) (*BulkPatchRequest, error) {
 	var payload []*BulkPatchPayload
 	if bulkPatchRequest.Filter != nil {
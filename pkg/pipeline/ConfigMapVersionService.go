@@ -0,0 +1,252 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/internal/sql/repository/chartConfig"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/pipeline/history"
+	"github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ConfigMapRestoreRequest struct {
+	AppId         int                   `json:"appId" validate:"number,gt=0"`
+	EnvironmentId int                   `json:"environmentId"`
+	Type          repository.ConfigType `json:"type" validate:"oneof=CONFIGMAP SECRET"`
+	Name          string                `json:"name" validate:"required"`
+	HistoryId     int                   `json:"historyId" validate:"number,gt=0"`
+	Redeploy      bool                  `json:"redeploy"`
+	UserId        int32                 `json:"-"`
+}
+
+type ConfigMapRestoreResponse struct {
+	ConfigData *ConfigDataRequest `json:"configData"`
+	Redeployed bool               `json:"redeployed"`
+	ReleaseId  int                `json:"releaseId,omitempty"`
+}
+
+// ConfigMapVersionService exposes the CM/CS history of a single component, envId 0 refers to app level config
+type ConfigMapVersionService interface {
+	GetVersions(appId, envId int, configType repository.ConfigType, name string) ([]*history.ComponentVersionDto, error)
+	GetVersionDiff(appId, envId int, configType repository.ConfigType, name string, fromId, toId int, userHasAdminAccess bool) (*history.ComponentVersionDiffDto, error)
+	// RestoreVersion saves the component as it was in the history entry as a new edit, optionally redeploying
+	// the currently deployed artifact of the environment with it
+	RestoreVersion(ctx context.Context, request *ConfigMapRestoreRequest) (*ConfigMapRestoreResponse, error)
+}
+
+type ConfigMapVersionServiceImpl struct {
+	logger                                 *zap.SugaredLogger
+	configMapService                       ConfigMapService
+	configMapHistoryService                history.ConfigMapHistoryService
+	configMapRepository                    chartConfig.ConfigMapRepository
+	pipelineRepository                     pipelineConfig.PipelineRepository
+	cdWorkflowRepository                   pipelineConfig.CdWorkflowRepository
+	workflowDagExecutor                    WorkflowDagExecutor
+	configMapHistoryRestoreAuditRepository repository.ConfigMapHistoryRestoreAuditRepository
+}
+
+func NewConfigMapVersionServiceImpl(logger *zap.SugaredLogger,
+	configMapService ConfigMapService,
+	configMapHistoryService history.ConfigMapHistoryService,
+	configMapRepository chartConfig.ConfigMapRepository,
+	pipelineRepository pipelineConfig.PipelineRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository,
+	workflowDagExecutor WorkflowDagExecutor,
+	configMapHistoryRestoreAuditRepository repository.ConfigMapHistoryRestoreAuditRepository) *ConfigMapVersionServiceImpl {
+	return &ConfigMapVersionServiceImpl{
+		logger:                                 logger,
+		configMapService:                       configMapService,
+		configMapHistoryService:                configMapHistoryService,
+		configMapRepository:                    configMapRepository,
+		pipelineRepository:                     pipelineRepository,
+		cdWorkflowRepository:                   cdWorkflowRepository,
+		workflowDagExecutor:                    workflowDagExecutor,
+		configMapHistoryRestoreAuditRepository: configMapHistoryRestoreAuditRepository,
+	}
+}
+
+// getPipelineId returns the pipeline env level history is recorded against, app level history has no pipeline
+func (impl ConfigMapVersionServiceImpl) getPipelineId(appId, envId int) (int, error) {
+	if envId == 0 {
+		return 0, nil
+	}
+	pipelines, err := impl.pipelineRepository.FindActiveByAppIdAndEnvironmentId(appId, envId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting pipeline", "err", err, "appId", appId, "envId", envId)
+		return 0, err
+	}
+	if len(pipelines) == 0 {
+		return 0, fmt.Errorf("no cd pipeline found for app %d in environment %d, history is recorded per pipeline", appId, envId)
+	}
+	return pipelines[0].Id, nil
+}
+
+func (impl ConfigMapVersionServiceImpl) GetVersions(appId, envId int, configType repository.ConfigType, name string) ([]*history.ComponentVersionDto, error) {
+	pipelineId, err := impl.getPipelineId(appId, envId)
+	if err != nil {
+		return nil, err
+	}
+	return impl.configMapHistoryService.GetComponentVersionList(appId, pipelineId, configType, name)
+}
+
+func (impl ConfigMapVersionServiceImpl) GetVersionDiff(appId, envId int, configType repository.ConfigType, name string, fromId, toId int, userHasAdminAccess bool) (*history.ComponentVersionDiffDto, error) {
+	pipelineId, err := impl.getPipelineId(appId, envId)
+	if err != nil {
+		return nil, err
+	}
+	return impl.configMapHistoryService.GetComponentVersionDiff(appId, pipelineId, configType, name, fromId, toId, userHasAdminAccess)
+}
+
+func (impl ConfigMapVersionServiceImpl) RestoreVersion(ctx context.Context, request *ConfigMapRestoreRequest) (*ConfigMapRestoreResponse, error) {
+	pipelineId, err := impl.getPipelineId(request.AppId, request.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+	historyConfig, err := impl.configMapHistoryService.GetComponentFromHistory(request.HistoryId, request.AppId, pipelineId, request.Type, request.Name)
+	if err != nil {
+		impl.logger.Errorw("error in getting config from history", "err", err, "request", request)
+		return nil, err
+	}
+	//checking redeploy pre-conditions before saving so that a failed redeploy does not leave a half done restore
+	ciArtifactId := 0
+	if request.Redeploy {
+		if request.EnvironmentId == 0 {
+			return nil, fmt.Errorf("redeploy is supported only for environment level restore")
+		}
+		wfr, err := impl.cdWorkflowRepository.FindLastStatusByPipelineIdAndRunnerType(pipelineId, bean.CD_WORKFLOW_TYPE_DEPLOY)
+		if err != nil {
+			impl.logger.Errorw("error in getting last deployment of pipeline", "err", err, "pipelineId", pipelineId)
+			return nil, fmt.Errorf("no previous deployment found to redeploy for pipeline %d", pipelineId)
+		}
+		ciArtifactId = wfr.CdWorkflow.CiArtifactId
+	}
+
+	configData, err := impl.toConfigData(historyConfig, request.EnvironmentId == 0)
+	if err != nil {
+		return nil, err
+	}
+	configDataRequest, err := impl.saveConfigData(request, configData)
+	if err != nil {
+		impl.logger.Errorw("error in saving restored config", "err", err, "request", request)
+		return nil, err
+	}
+	response := &ConfigMapRestoreResponse{ConfigData: configDataRequest}
+	audit := &repository.ConfigMapHistoryRestoreAudit{
+		AppId:         request.AppId,
+		EnvironmentId: request.EnvironmentId,
+		DataType:      request.Type,
+		Name:          request.Name,
+		HistoryId:     request.HistoryId,
+		AuditLog:      sql.AuditLog{CreatedOn: time.Now(), CreatedBy: request.UserId, UpdatedOn: time.Now(), UpdatedBy: request.UserId},
+	}
+	var redeployErr error
+	if request.Redeploy {
+		overrideRequest := &bean.ValuesOverrideRequest{
+			PipelineId:     pipelineId,
+			AppId:          request.AppId,
+			CiArtifactId:   ciArtifactId,
+			UserId:         request.UserId,
+			CdWorkflowType: bean.CD_WORKFLOW_TYPE_DEPLOY,
+		}
+		response.ReleaseId, redeployErr = impl.workflowDagExecutor.ManualCdTrigger(overrideRequest, ctx)
+		if redeployErr != nil {
+			impl.logger.Errorw("error in redeploying restored config", "err", redeployErr, "overrideRequest", overrideRequest)
+		} else {
+			response.Redeployed = true
+			audit.Redeployed = true
+			audit.ReleaseId = response.ReleaseId
+		}
+	}
+	err = impl.configMapHistoryRestoreAuditRepository.Save(audit)
+	if err != nil {
+		impl.logger.Errorw("error in saving restore audit", "err", err, "audit", audit)
+		return nil, err
+	}
+	if redeployErr != nil {
+		return response, fmt.Errorf("config restored but redeploy failed : %w", redeployErr)
+	}
+	return response, nil
+}
+
+func (impl ConfigMapVersionServiceImpl) toConfigData(historyConfig *history.ConfigData, global bool) (*ConfigData, error) {
+	historyConfigJson, err := json.Marshal(historyConfig)
+	if err != nil {
+		return nil, err
+	}
+	configData := &ConfigData{}
+	err = json.Unmarshal(historyConfigJson, configData)
+	if err != nil {
+		return nil, err
+	}
+	//history holds the merged view of app and env level, defaults are recomputed on fetch. components which
+	//were inherited from app level in the env level history are kept inherited
+	configData.Global = global || historyConfig.Global
+	configData.DefaultData = nil
+	configData.DefaultMountPath = ""
+	configData.DefaultExternalSecret = nil
+	configData.DefaultESOSecretData = ESOSecretData{}
+	return configData, nil
+}
+
+func (impl ConfigMapVersionServiceImpl) saveConfigData(request *ConfigMapRestoreRequest, configData *ConfigData) (*ConfigDataRequest, error) {
+	configDataRequest := &ConfigDataRequest{
+		AppId:         request.AppId,
+		EnvironmentId: request.EnvironmentId,
+		ConfigData:    []*ConfigData{configData},
+		UserId:        request.UserId,
+	}
+	if request.EnvironmentId == 0 {
+		model, err := impl.configMapRepository.GetByAppIdAppLevel(request.AppId)
+		if err != nil && err != pg.ErrNoRows {
+			return nil, err
+		}
+		if err == nil {
+			configDataRequest.Id = model.Id
+		}
+		if request.Type == repository.CONFIGMAP_TYPE {
+			return impl.configMapService.CMGlobalAddUpdate(configDataRequest)
+		}
+		return impl.configMapService.CSGlobalAddUpdate(configDataRequest)
+	}
+	model, err := impl.configMapRepository.GetByAppIdAndEnvIdEnvLevel(request.AppId, request.EnvironmentId)
+	if err != nil && err != pg.ErrNoRows {
+		return nil, err
+	}
+	if configData.Global {
+		return impl.restoreInheritance(request, err == pg.ErrNoRows)
+	}
+	if err == nil {
+		configDataRequest.Id = model.Id
+	}
+	if request.Type == repository.CONFIGMAP_TYPE {
+		return impl.configMapService.CMEnvironmentAddUpdate(configDataRequest)
+	}
+	return impl.configMapService.CSEnvironmentAddUpdate(configDataRequest)
+}
+
+// restoreInheritance removes the env override of the component so that the app level config is used again
+func (impl ConfigMapVersionServiceImpl) restoreInheritance(request *ConfigMapRestoreRequest, noEnvOverride bool) (*ConfigDataRequest, error) {
+	if request.Type == repository.CONFIGMAP_TYPE {
+		if !noEnvOverride {
+			_, err := impl.configMapService.CMEnvironmentDeleteByAppIdAndEnvId(request.Name, request.AppId, request.EnvironmentId, request.UserId)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return impl.configMapService.CMEnvironmentFetch(request.AppId, request.EnvironmentId)
+	}
+	if !noEnvOverride {
+		_, err := impl.configMapService.CSEnvironmentDeleteByAppIdAndEnvId(request.Name, request.AppId, request.EnvironmentId, request.UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return impl.configMapService.CSEnvironmentFetch(request.AppId, request.EnvironmentId)
+}
@@ -0,0 +1,28 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/pkg/pipeline/history"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigMapVersionToConfigData(t *testing.T) {
+	impl := ConfigMapVersionServiceImpl{}
+	t.Run("inherited component of env level history stays inherited", func(t *testing.T) {
+		configData, err := impl.toConfigData(&history.ConfigData{Name: "app-config", Global: true, DefaultMountPath: "/etc"}, false)
+		assert.Nil(t, err)
+		assert.True(t, configData.Global)
+		assert.Empty(t, configData.DefaultMountPath)
+	})
+	t.Run("env override stays an override", func(t *testing.T) {
+		configData, err := impl.toConfigData(&history.ConfigData{Name: "app-config"}, false)
+		assert.Nil(t, err)
+		assert.False(t, configData.Global)
+	})
+	t.Run("app level component is global", func(t *testing.T) {
+		configData, err := impl.toConfigData(&history.ConfigData{Name: "app-config"}, true)
+		assert.Nil(t, err)
+		assert.True(t, configData.Global)
+	})
+}
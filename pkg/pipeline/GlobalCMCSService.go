@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/devtron-labs/devtron/internal/sql/repository"
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/go-pg/pg"
 	"go.uber.org/zap"
@@ -12,19 +15,39 @@ import (
 
 type GlobalCMCSService interface {
 	Create(model *GlobalCMCSDto) (*GlobalCMCSDto, error)
+	Update(model *GlobalCMCSDto) (*GlobalCMCSDto, error)
+	Delete(id int, userId int32) error
+	FindById(id int) (*GlobalCMCSDto, error)
 	FindAllActive() ([]*GlobalCMCSDto, error)
+	// FindAllActiveByScope returns configs to be used in the given stage of a workflow of the app, envId is 0 for ci
+	FindAllActiveByScope(stage string, appId, envId int) ([]*GlobalCMCSDto, error)
+	GetHistory(id int) ([]*GlobalCMCSHistoryDto, error)
+	// Preview returns the pipelines which would receive a config with given selectors and stages
+	Preview(request *GlobalCMCSPreviewRequest) ([]*GlobalCMCSPreviewDto, error)
 }
 
 type GlobalCMCSServiceImpl struct {
-	logger               *zap.SugaredLogger
-	globalCMCSRepository repository.GlobalCMCSRepository
+	logger                      *zap.SugaredLogger
+	globalCMCSRepository        repository.GlobalCMCSRepository
+	globalCMCSHistoryRepository repository.GlobalCMCSHistoryRepository
+	appRepository               app.AppRepository
+	appLabelRepository          pipelineConfig.AppLabelRepository
+	environmentRepository       repository2.EnvironmentRepository
 }
 
 func NewGlobalCMCSServiceImpl(logger *zap.SugaredLogger,
-	globalCMCSRepository repository.GlobalCMCSRepository) *GlobalCMCSServiceImpl {
+	globalCMCSRepository repository.GlobalCMCSRepository,
+	globalCMCSHistoryRepository repository.GlobalCMCSHistoryRepository,
+	appRepository app.AppRepository,
+	appLabelRepository pipelineConfig.AppLabelRepository,
+	environmentRepository repository2.EnvironmentRepository) *GlobalCMCSServiceImpl {
 	return &GlobalCMCSServiceImpl{
-		logger:               logger,
-		globalCMCSRepository: globalCMCSRepository,
+		logger:                      logger,
+		globalCMCSRepository:        globalCMCSRepository,
+		globalCMCSHistoryRepository: globalCMCSHistoryRepository,
+		appRepository:               appRepository,
+		appLabelRepository:          appLabelRepository,
+		environmentRepository:       environmentRepository,
 	}
 }
 
@@ -37,47 +60,147 @@ type GlobalCMCSDto struct {
 	Data      map[string]string `json:"data"  validate:"required"`
 	MountPath string            `json:"mountPath"`
 	Deleted   bool              `json:"deleted"`
-	UserId    int32             `json:"-"`
+	//selectors restricting the pipelines this config is used in, nil means all pipelines
+	Selectors *GlobalCMCSSelectors `json:"selectors,omitempty"`
+	//stages in which this config is used, defaults to CI
+	UseInStages []string `json:"useInStages" validate:"dive,oneof=CI PRE_CD POST_CD"`
+	Version     int      `json:"version"`
+	UserId      int32    `json:"-"`
 }
 
-func (impl *GlobalCMCSServiceImpl) Create(config *GlobalCMCSDto) (*GlobalCMCSDto, error) {
+// GlobalCMCSSelectors are ANDed with each other, values within a selector are ORed. Environment and cluster
+// selectors are matched only against cd stages as ci workflows do not belong to an environment
+type GlobalCMCSSelectors struct {
+	ClusterIds     []int                   `json:"clusterIds,omitempty"`
+	EnvironmentIds []int                   `json:"environmentIds,omitempty"`
+	ProjectIds     []int                   `json:"projectIds,omitempty"`
+	AppLabels      []*GlobalCMCSLabelMatch `json:"appLabels,omitempty" validate:"dive"`
+}
+
+// GlobalCMCSLabelMatch matches apps having the label, any value of the key is matched if value is empty
+type GlobalCMCSLabelMatch struct {
+	Key   string `json:"key" validate:"required"`
+	Value string `json:"value"`
+}
+
+type GlobalCMCSScope struct {
+	AppId         int
+	ProjectId     int
+	EnvironmentId int
+	ClusterId     int
+	AppLabels     map[string]string
+}
+
+type GlobalCMCSHistoryDto struct {
+	Id        int            `json:"id"`
+	Version   int            `json:"version"`
+	Action    string         `json:"action"`
+	Config    *GlobalCMCSDto `json:"config"`
+	CreatedBy int32          `json:"createdBy"`
+	CreatedOn time.Time      `json:"createdOn"`
+}
+
+type GlobalCMCSPreviewRequest struct {
+	Selectors   *GlobalCMCSSelectors `json:"selectors"`
+	UseInStages []string             `json:"useInStages" validate:"dive,oneof=CI PRE_CD POST_CD"`
+}
+
+type GlobalCMCSPreviewDto struct {
+	PipelineId      int    `json:"pipelineId"`
+	PipelineName    string `json:"pipelineName"`
+	Stage           string `json:"stage"`
+	AppId           int    `json:"appId"`
+	AppName         string `json:"appName"`
+	EnvironmentId   int    `json:"environmentId,omitempty"`
+	EnvironmentName string `json:"environmentName,omitempty"`
+}
+
+func (selectors *GlobalCMCSSelectors) isEmpty() bool {
+	return selectors == nil || (len(selectors.ClusterIds) == 0 && len(selectors.EnvironmentIds) == 0 &&
+		len(selectors.ProjectIds) == 0 && len(selectors.AppLabels) == 0)
+}
+
+func (selectors *GlobalCMCSSelectors) Matches(scope *GlobalCMCSScope) bool {
+	if selectors.isEmpty() {
+		return true
+	}
+	if len(selectors.ProjectIds) > 0 && !containsInt(selectors.ProjectIds, scope.ProjectId) {
+		return false
+	}
+	if len(selectors.EnvironmentIds) > 0 && (scope.EnvironmentId == 0 || !containsInt(selectors.EnvironmentIds, scope.EnvironmentId)) {
+		return false
+	}
+	if len(selectors.ClusterIds) > 0 && (scope.ClusterId == 0 || !containsInt(selectors.ClusterIds, scope.ClusterId)) {
+		return false
+	}
+	for _, label := range selectors.AppLabels {
+		value, ok := scope.AppLabels[label.Key]
+		if !ok || (len(label.Value) > 0 && label.Value != value) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (impl *GlobalCMCSServiceImpl) validateUniqueness(config *GlobalCMCSDto) error {
 	//checking if same name config is present for this type
 	sameNameConfig, err := impl.globalCMCSRepository.FindByConfigTypeAndName(config.ConfigType, config.Name)
 	if err != nil && err != pg.ErrNoRows {
 		impl.logger.Errorw("error in getting global cm/cs config by name and configType", "err", err, "configType", config.ConfigType, "name", config.Name)
-		return nil, err
+		return err
+	}
+	if sameNameConfig != nil && sameNameConfig.Id == config.Id {
+		sameNameConfig = nil
 	}
 	if config.Type == repository.VOLUME_CONFIG {
 		//checking if same mountPath config is present for any type
 		sameMountPathConfig, err := impl.globalCMCSRepository.FindByMountPath(config.MountPath)
 		if err != nil && err != pg.ErrNoRows {
 			impl.logger.Errorw("error in getting global cm/cs config by mountPath and configType", "err", err, "mountPath", config.MountPath)
-			return nil, err
+			return err
+		}
+		if sameMountPathConfig != nil && sameMountPathConfig.Id == config.Id {
+			sameMountPathConfig = nil
 		}
 		if (sameMountPathConfig != nil && sameMountPathConfig.Id > 0) && (sameNameConfig != nil && sameNameConfig.Id > 0) {
 			impl.logger.Errorw("found global cm/cs config with same name and same mountPath", "configName", config.Name)
-			return nil, fmt.Errorf("found configs with same name & mount path, please update the name & mountPath and try again")
+			return fmt.Errorf("found configs with same name & mount path, please update the name & mountPath and try again")
 		} else if sameMountPathConfig != nil && sameMountPathConfig.Id > 0 {
 			impl.logger.Errorw("found global cm/cs config with same mountPath", "configName", config.Name)
-			return nil, fmt.Errorf("found configs with same mount path, please update the mount path and try again")
+			return fmt.Errorf("found configs with same mount path, please update the mount path and try again")
 		}
 	}
 	if sameNameConfig != nil && sameNameConfig.Id > 0 {
 		impl.logger.Errorw("found global cm/cs config with same name", "configName", config.Name)
-		return nil, fmt.Errorf("found %s with same name, please update the name and try again", config.ConfigType)
+		return fmt.Errorf("found %s with same name, please update the name and try again", config.ConfigType)
 	}
-	dataByte, err := json.Marshal(config.Data)
+	return nil
+}
+
+func (impl *GlobalCMCSServiceImpl) Create(config *GlobalCMCSDto) (*GlobalCMCSDto, error) {
+	err := impl.validateUniqueness(config)
 	if err != nil {
-		impl.logger.Errorw("error in marshaling cm/cs data", "err", err)
 		return nil, err
 	}
+	if len(config.UseInStages) == 0 {
+		config.UseInStages = []string{repository.GLOBAL_CM_CS_STAGE_CI}
+	}
 	model := &repository.GlobalCMCS{
 		ConfigType: config.ConfigType,
-		Data:       json.RawMessage(dataByte),
 		Name:       config.Name,
 		MountPath:  config.MountPath,
 		Type:       config.Type,
 		Deleted:    false,
+		Version:    1,
 		AuditLog: sql.AuditLog{
 			CreatedBy: config.UserId,
 			CreatedOn: time.Now(),
@@ -85,15 +208,164 @@ func (impl *GlobalCMCSServiceImpl) Create(config *GlobalCMCSDto) (*GlobalCMCSDto
 			UpdatedOn: time.Now(),
 		},
 	}
+	err = impl.setModelData(model, config)
+	if err != nil {
+		return nil, err
+	}
 	model, err = impl.globalCMCSRepository.Save(model)
 	if err != nil {
 		impl.logger.Errorw("err on creating global cm/cs config ", "err", err)
 		return nil, err
 	}
 	config.Id = model.Id
+	config.Version = model.Version
+	err = impl.saveHistory(model, repository.GLOBAL_CM_CS_ACTION_CREATE, config.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (impl *GlobalCMCSServiceImpl) Update(config *GlobalCMCSDto) (*GlobalCMCSDto, error) {
+	model, err := impl.globalCMCSRepository.FindById(config.Id)
+	if err != nil {
+		impl.logger.Errorw("error in getting global cm/cs config", "err", err, "id", config.Id)
+		return nil, err
+	}
+	if model.ConfigType != config.ConfigType {
+		return nil, fmt.Errorf("config type of a global config can not be changed, please create a new %s", config.ConfigType)
+	}
+	err = impl.validateUniqueness(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.UseInStages) == 0 {
+		config.UseInStages = []string{repository.GLOBAL_CM_CS_STAGE_CI}
+	}
+	model.Name = config.Name
+	model.Type = config.Type
+	model.MountPath = config.MountPath
+	model.Version = model.Version + 1
+	model.UpdatedBy = config.UserId
+	model.UpdatedOn = time.Now()
+	err = impl.setModelData(model, config)
+	if err != nil {
+		return nil, err
+	}
+	model, err = impl.globalCMCSRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("err on updating global cm/cs config ", "err", err, "id", config.Id)
+		return nil, err
+	}
+	config.Version = model.Version
+	err = impl.saveHistory(model, repository.GLOBAL_CM_CS_ACTION_UPDATE, config.UserId)
+	if err != nil {
+		return nil, err
+	}
 	return config, nil
 }
 
+func (impl *GlobalCMCSServiceImpl) Delete(id int, userId int32) error {
+	model, err := impl.globalCMCSRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting global cm/cs config", "err", err, "id", id)
+		return err
+	}
+	model.Deleted = true
+	model.Version = model.Version + 1
+	model.UpdatedBy = userId
+	model.UpdatedOn = time.Now()
+	_, err = impl.globalCMCSRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("err on deleting global cm/cs config ", "err", err, "id", id)
+		return err
+	}
+	return impl.saveHistory(model, repository.GLOBAL_CM_CS_ACTION_DELETE, userId)
+}
+
+func (impl *GlobalCMCSServiceImpl) setModelData(model *repository.GlobalCMCS, config *GlobalCMCSDto) error {
+	dataByte, err := json.Marshal(config.Data)
+	if err != nil {
+		impl.logger.Errorw("error in marshaling cm/cs data", "err", err)
+		return err
+	}
+	model.Data = json.RawMessage(dataByte)
+	model.Selectors = ""
+	if !config.Selectors.isEmpty() {
+		selectors, err := json.Marshal(config.Selectors)
+		if err != nil {
+			impl.logger.Errorw("error in marshaling cm/cs selectors", "err", err)
+			return err
+		}
+		model.Selectors = string(selectors)
+	}
+	model.UseInStages = config.UseInStages
+	return nil
+}
+
+func (impl *GlobalCMCSServiceImpl) saveHistory(model *repository.GlobalCMCS, action string, userId int32) error {
+	config, err := json.Marshal(impl.toDto(model))
+	if err != nil {
+		impl.logger.Errorw("error in marshaling global cm/cs config for history", "err", err)
+		return err
+	}
+	history := &repository.GlobalCMCSHistory{
+		GlobalCMCSId: model.Id,
+		Version:      model.Version,
+		Action:       action,
+		Config:       string(config),
+		AuditLog: sql.AuditLog{
+			CreatedBy: userId,
+			CreatedOn: time.Now(),
+			UpdatedBy: userId,
+			UpdatedOn: time.Now(),
+		},
+	}
+	_, err = impl.globalCMCSHistoryRepository.Save(history)
+	if err != nil {
+		impl.logger.Errorw("error in saving global cm/cs history", "err", err, "id", model.Id)
+		return err
+	}
+	return nil
+}
+
+func (impl *GlobalCMCSServiceImpl) toDto(model *repository.GlobalCMCS) *GlobalCMCSDto {
+	data := make(map[string]string)
+	err := json.Unmarshal([]byte(model.Data), &data)
+	if err != nil {
+		impl.logger.Errorw("error in un-marshaling cm/cs data", "err", err)
+	}
+	configDto := &GlobalCMCSDto{
+		Id:          model.Id,
+		ConfigType:  model.ConfigType,
+		Type:        model.Type,
+		Data:        data,
+		Name:        model.Name,
+		MountPath:   model.MountPath,
+		Deleted:     model.Deleted,
+		UseInStages: model.UseInStages,
+		Version:     model.Version,
+	}
+	if len(model.Selectors) > 0 {
+		selectors := &GlobalCMCSSelectors{}
+		err = json.Unmarshal([]byte(model.Selectors), selectors)
+		if err != nil {
+			impl.logger.Errorw("error in un-marshaling cm/cs selectors", "err", err, "id", model.Id)
+		}
+		configDto.Selectors = selectors
+	}
+	return configDto
+}
+
+func (impl *GlobalCMCSServiceImpl) FindById(id int) (*GlobalCMCSDto, error) {
+	model, err := impl.globalCMCSRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting global cm/cs config", "err", err, "id", id)
+		return nil, err
+	}
+	return impl.toDto(model), nil
+}
+
 func (impl *GlobalCMCSServiceImpl) FindAllActive() ([]*GlobalCMCSDto, error) {
 	models, err := impl.globalCMCSRepository.FindAllActive()
 	if err != nil && err != pg.ErrNoRows {
@@ -102,21 +374,168 @@ func (impl *GlobalCMCSServiceImpl) FindAllActive() ([]*GlobalCMCSDto, error) {
 	}
 	var configDtos []*GlobalCMCSDto
 	for _, model := range models {
-		data := make(map[string]string)
-		err = json.Unmarshal([]byte(model.Data), &data)
+		configDtos = append(configDtos, impl.toDto(model))
+	}
+	return configDtos, nil
+}
+
+func (impl *GlobalCMCSServiceImpl) FindAllActiveByScope(stage string, appId, envId int) ([]*GlobalCMCSDto, error) {
+	configs, err := impl.FindAllActive()
+	if err != nil {
+		return nil, err
+	}
+	var scope *GlobalCMCSScope
+	var configDtos []*GlobalCMCSDto
+	for _, config := range configs {
+		if !containsString(config.UseInStages, stage) {
+			continue
+		}
+		//scope is built only if needed, configs without selectors are used in every pipeline
+		if scope == nil && !config.Selectors.isEmpty() {
+			scope, err = impl.getScope(appId, envId)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if config.Selectors.isEmpty() || config.Selectors.Matches(scope) {
+			configDtos = append(configDtos, config)
+		}
+	}
+	return configDtos, nil
+}
+
+func (impl *GlobalCMCSServiceImpl) getScope(appId, envId int) (*GlobalCMCSScope, error) {
+	scope := &GlobalCMCSScope{AppId: appId, EnvironmentId: envId, AppLabels: make(map[string]string)}
+	appModel, err := impl.appRepository.FindById(appId)
+	if err != nil {
+		impl.logger.Errorw("error in getting app for global cm/cs scope", "err", err, "appId", appId)
+		return nil, err
+	}
+	scope.ProjectId = appModel.TeamId
+	labels, err := impl.appLabelRepository.FindAllByAppId(appId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting app labels for global cm/cs scope", "err", err, "appId", appId)
+		return nil, err
+	}
+	for _, label := range labels {
+		scope.AppLabels[label.Key] = label.Value
+	}
+	if envId > 0 {
+		env, err := impl.environmentRepository.FindById(envId)
 		if err != nil {
-			impl.logger.Errorw("error in un-marshaling cm/cs data", "err", err)
+			impl.logger.Errorw("error in getting environment for global cm/cs scope", "err", err, "envId", envId)
+			return nil, err
 		}
-		configDto := &GlobalCMCSDto{
-			Id:         model.Id,
-			ConfigType: model.ConfigType,
-			Type:       model.Type,
-			Data:       data,
-			Name:       model.Name,
-			MountPath:  model.MountPath,
+		scope.ClusterId = env.ClusterId
+	}
+	return scope, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
 		}
-		configDtos = append(configDtos, configDto)
 	}
+	return false
+}
 
-	return configDtos, nil
+func (impl *GlobalCMCSServiceImpl) GetHistory(id int) ([]*GlobalCMCSHistoryDto, error) {
+	histories, err := impl.globalCMCSHistoryRepository.FindByGlobalCMCSId(id)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting global cm/cs history", "err", err, "id", id)
+		return nil, err
+	}
+	historyDtos := make([]*GlobalCMCSHistoryDto, 0, len(histories))
+	for _, history := range histories {
+		config := &GlobalCMCSDto{}
+		err = json.Unmarshal([]byte(history.Config), config)
+		if err != nil {
+			impl.logger.Errorw("error in un-marshaling global cm/cs history", "err", err, "historyId", history.Id)
+			return nil, err
+		}
+		historyDtos = append(historyDtos, &GlobalCMCSHistoryDto{
+			Id:        history.Id,
+			Version:   history.Version,
+			Action:    history.Action,
+			Config:    config,
+			CreatedBy: history.CreatedBy,
+			CreatedOn: history.CreatedOn,
+		})
+	}
+	return historyDtos, nil
+}
+
+func (impl *GlobalCMCSServiceImpl) Preview(request *GlobalCMCSPreviewRequest) ([]*GlobalCMCSPreviewDto, error) {
+	stages := request.UseInStages
+	if len(stages) == 0 {
+		stages = []string{repository.GLOBAL_CM_CS_STAGE_CI}
+	}
+	appLabels := make(map[int]map[string]string)
+	if request.Selectors != nil && len(request.Selectors.AppLabels) > 0 {
+		labels, err := impl.appLabelRepository.FindAll()
+		if err != nil && err != pg.ErrNoRows {
+			impl.logger.Errorw("error in getting app labels", "err", err)
+			return nil, err
+		}
+		for _, label := range labels {
+			if _, ok := appLabels[label.AppId]; !ok {
+				appLabels[label.AppId] = make(map[string]string)
+			}
+			appLabels[label.AppId][label.Key] = label.Value
+		}
+	}
+	matches := func(pipelineScope *repository.GlobalCMCSPipelineScope) bool {
+		return request.Selectors.Matches(&GlobalCMCSScope{
+			AppId:         pipelineScope.AppId,
+			ProjectId:     pipelineScope.TeamId,
+			EnvironmentId: pipelineScope.EnvironmentId,
+			ClusterId:     pipelineScope.ClusterId,
+			AppLabels:     appLabels[pipelineScope.AppId],
+		})
+	}
+	previewDtos := make([]*GlobalCMCSPreviewDto, 0)
+	if containsString(stages, repository.GLOBAL_CM_CS_STAGE_CI) {
+		ciScopes, err := impl.globalCMCSRepository.FindActiveCiPipelineScopes()
+		if err != nil && err != pg.ErrNoRows {
+			return nil, err
+		}
+		for _, ciScope := range ciScopes {
+			if matches(ciScope) {
+				previewDtos = append(previewDtos, toPreviewDto(ciScope, repository.GLOBAL_CM_CS_STAGE_CI))
+			}
+		}
+	}
+	usedInPreCd := containsString(stages, repository.GLOBAL_CM_CS_STAGE_PRE_CD)
+	usedInPostCd := containsString(stages, repository.GLOBAL_CM_CS_STAGE_POST_CD)
+	if usedInPreCd || usedInPostCd {
+		cdScopes, err := impl.globalCMCSRepository.FindActiveCdPipelineScopes()
+		if err != nil && err != pg.ErrNoRows {
+			return nil, err
+		}
+		for _, cdScope := range cdScopes {
+			if !matches(cdScope) {
+				continue
+			}
+			if usedInPreCd && cdScope.HasPreStage {
+				previewDtos = append(previewDtos, toPreviewDto(cdScope, repository.GLOBAL_CM_CS_STAGE_PRE_CD))
+			}
+			if usedInPostCd && cdScope.HasPostStage {
+				previewDtos = append(previewDtos, toPreviewDto(cdScope, repository.GLOBAL_CM_CS_STAGE_POST_CD))
+			}
+		}
+	}
+	return previewDtos, nil
+}
+
+func toPreviewDto(pipelineScope *repository.GlobalCMCSPipelineScope, stage string) *GlobalCMCSPreviewDto {
+	return &GlobalCMCSPreviewDto{
+		PipelineId:      pipelineScope.PipelineId,
+		PipelineName:    pipelineScope.PipelineName,
+		Stage:           stage,
+		AppId:           pipelineScope.AppId,
+		AppName:         pipelineScope.AppName,
+		EnvironmentId:   pipelineScope.EnvironmentId,
+		EnvironmentName: pipelineScope.EnvironmentName,
+	}
 }
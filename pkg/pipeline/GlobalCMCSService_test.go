@@ -0,0 +1,33 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobalCMCSSelectorsMatches(t *testing.T) {
+	ciScope := &GlobalCMCSScope{AppId: 1, ProjectId: 2, AppLabels: map[string]string{"team": "payments"}}
+	cdScope := &GlobalCMCSScope{AppId: 1, ProjectId: 2, EnvironmentId: 3, ClusterId: 4, AppLabels: map[string]string{"team": "payments"}}
+
+	t.Run("empty selectors match every pipeline", func(t *testing.T) {
+		var selectors *GlobalCMCSSelectors
+		assert.True(t, selectors.Matches(ciScope))
+		assert.True(t, (&GlobalCMCSSelectors{}).Matches(cdScope))
+	})
+	t.Run("project and labels", func(t *testing.T) {
+		selectors := &GlobalCMCSSelectors{ProjectIds: []int{2, 5}, AppLabels: []*GlobalCMCSLabelMatch{{Key: "team"}}}
+		assert.True(t, selectors.Matches(ciScope))
+		selectors.AppLabels[0].Value = "billing"
+		assert.False(t, selectors.Matches(ciScope))
+		selectors = &GlobalCMCSSelectors{ProjectIds: []int{5}}
+		assert.False(t, selectors.Matches(cdScope))
+	})
+	t.Run("environment and cluster selectors do not match ci", func(t *testing.T) {
+		selectors := &GlobalCMCSSelectors{EnvironmentIds: []int{3}}
+		assert.False(t, selectors.Matches(ciScope))
+		assert.True(t, selectors.Matches(cdScope))
+		selectors = &GlobalCMCSSelectors{ClusterIds: []int{7}}
+		assert.False(t, selectors.Matches(cdScope))
+	})
+}
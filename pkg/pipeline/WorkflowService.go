@@ -30,6 +30,7 @@ import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
 	"github.com/devtron-labs/devtron/pkg/bean"
 	bean2 "github.com/devtron-labs/devtron/pkg/pipeline/bean"
+	"github.com/devtron-labs/devtron/pkg/variables"
 	"go.uber.org/zap"
 	v12 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -57,10 +58,11 @@ type CiCdTriggerEvent struct {
 }
 
 type WorkflowServiceImpl struct {
-	Logger            *zap.SugaredLogger
-	config            *rest.Config
-	ciConfig          *CiConfig
-	globalCMCSService GlobalCMCSService
+	Logger                *zap.SugaredLogger
+	config                *rest.Config
+	ciConfig              *CiConfig
+	globalCMCSService     GlobalCMCSService
+	scopedVariableService variables.ScopedVariableService
 }
 
 type WorkflowRequest struct {
@@ -108,6 +110,7 @@ type WorkflowRequest struct {
 	PreCiSteps                 []*bean2.StepObject               `json:"preCiSteps"`
 	PostCiSteps                []*bean2.StepObject               `json:"postCiSteps"`
 	RefPlugins                 []*bean2.RefPluginObject          `json:"refPlugins"`
+	AppId                      int                               `json:"appId"`
 	AppName                    string                            `json:"appName"`
 	TriggerByAuthor            string                            `json:"triggerByAuthor"`
 	CiBuildConfig              *bean2.CiBuildConfigBean          `json:"ciBuildConfig"`
@@ -182,15 +185,33 @@ type GitOptions struct {
 }
 
 func NewWorkflowServiceImpl(Logger *zap.SugaredLogger, ciConfig *CiConfig,
-	globalCMCSService GlobalCMCSService) *WorkflowServiceImpl {
+	globalCMCSService GlobalCMCSService, scopedVariableService variables.ScopedVariableService) *WorkflowServiceImpl {
 	return &WorkflowServiceImpl{
-		Logger:            Logger,
-		config:            ciConfig.ClusterConfig,
-		ciConfig:          ciConfig,
-		globalCMCSService: globalCMCSService,
+		Logger:                Logger,
+		config:                ciConfig.ClusterConfig,
+		ciConfig:              ciConfig,
+		globalCMCSService:     globalCMCSService,
+		scopedVariableService: scopedVariableService,
 	}
 }
 
+// substituteVariablesInSteps resolves scoped variable references in inline step scripts, ci has no environment
+// so only app, project and global values apply
+func (impl *WorkflowServiceImpl) substituteVariablesInSteps(steps []*bean2.StepObject, appId int) error {
+	for _, step := range steps {
+		if step == nil || len(step.Script) == 0 {
+			continue
+		}
+		script, _, err := impl.scopedVariableService.SubstituteVariables(step.Script, appId, 0, false)
+		if err != nil {
+			impl.Logger.Errorw("error in substituting variables in step script", "err", err, "appId", appId, "step", step.Name)
+			return err
+		}
+		step.Script = script
+	}
+	return nil
+}
+
 const ciEvent = "CI"
 const cdStage = "CD"
 
@@ -209,6 +230,14 @@ func (impl *WorkflowServiceImpl) SubmitWorkflow(workflowRequest *WorkflowRequest
 		workflowRequest.IgnoreDockerCachePush = true
 		workflowRequest.IgnoreDockerCachePull = true
 	}
+	err := impl.substituteVariablesInSteps(workflowRequest.PreCiSteps, workflowRequest.AppId)
+	if err != nil {
+		return nil, err
+	}
+	err = impl.substituteVariablesInSteps(workflowRequest.PostCiSteps, workflowRequest.AppId)
+	if err != nil {
+		return nil, err
+	}
 	ciCdTriggerEvent := CiCdTriggerEvent{
 		Type:      ciEvent,
 		CiRequest: workflowRequest,
@@ -298,8 +327,8 @@ func (impl *WorkflowServiceImpl) SubmitWorkflow(workflowRequest *WorkflowRequest
 			},
 		}
 	}
-	//getting all cm/cs to be used by default
-	globalCmCsConfigs, err := impl.globalCMCSService.FindAllActive()
+	//getting all cm/cs to be used by default in ci of this app
+	globalCmCsConfigs, err := impl.globalCMCSService.FindAllActiveByScope(repository.GLOBAL_CM_CS_STAGE_CI, workflowRequest.AppId, 0)
 	if err != nil {
 		impl.Logger.Errorw("error in getting all global cm/cs config", "err", err)
 		return nil, err
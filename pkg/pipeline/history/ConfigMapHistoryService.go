@@ -2,13 +2,16 @@ package history
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/devtron-labs/devtron/internal/sql/repository/chartConfig"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
 	"github.com/devtron-labs/devtron/pkg/pipeline/history/repository"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/devtron-labs/devtron/pkg/user"
+	"github.com/devtron-labs/devtron/util"
 	"github.com/go-pg/pg"
 	"go.uber.org/zap"
+	"sort"
 	"strings"
 	"time"
 )
@@ -26,6 +29,11 @@ type ConfigMapHistoryService interface {
 
 	GetDeployedHistoryDetailForCMCSByPipelineIdAndWfrId(pipelineId, wfrId int, configType repository.ConfigType, userHasAdminAccess bool) ([]*ComponentLevelHistoryDetailDto, error)
 	ConvertConfigDataToComponentLevelDto(config *ConfigData, configType repository.ConfigType, userHasAdminAccess bool) (*ComponentLevelHistoryDetailDto, error)
+
+	// GetComponentVersionList lists the versions of a single CM/CS, pipelineId is 0 for app level history
+	GetComponentVersionList(appId, pipelineId int, configType repository.ConfigType, componentName string) ([]*ComponentVersionDto, error)
+	GetComponentVersionDiff(appId, pipelineId int, configType repository.ConfigType, componentName string, fromId, toId int, userHasAdminAccess bool) (*ComponentVersionDiffDto, error)
+	GetComponentFromHistory(id, appId, pipelineId int, configType repository.ConfigType, componentName string) (*ConfigData, error)
 }
 
 type ConfigMapHistoryServiceImpl struct {
@@ -563,7 +571,7 @@ func (impl ConfigMapHistoryServiceImpl) ConvertConfigDataToComponentLevelDto(con
 		historyDto.RoleARN = config.RoleARN
 		if config.External {
 			var externalSecretData []byte
-			if strings.HasPrefix(config.ExternalSecretType, "ESO") {
+			if util.IsESOType(config.ExternalSecretType) {
 				externalSecretData, err = json.Marshal(config.ESOSecretData)
 				if err != nil {
 					impl.logger.Errorw("error in marshaling external secret data", "err", err)
@@ -587,3 +595,242 @@ func (impl ConfigMapHistoryServiceImpl) ConvertConfigDataToComponentLevelDto(con
 	}
 	return componentLevelData, nil
 }
+
+func (impl ConfigMapHistoryServiceImpl) GetComponentVersionList(appId, pipelineId int, configType repository.ConfigType, componentName string) ([]*ComponentVersionDto, error) {
+	var histories []*repository.ConfigmapAndSecretHistory
+	var err error
+	if pipelineId > 0 {
+		histories, err = impl.configMapHistoryRepository.GetPipelineLevelHistoryList(pipelineId, configType)
+	} else {
+		histories, err = impl.configMapHistoryRepository.GetAppLevelHistoryList(appId, configType)
+	}
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting CM/CS history list", "err", err, "appId", appId, "pipelineId", pipelineId)
+		return nil, err
+	}
+	// every history entry holds all the CM/CS of the app, only entries where this component was changed
+	// or deployed are versions of it. histories are ordered latest first, so these are walked from the oldest
+	var versions []*ComponentVersionDto
+	var previousComponent []byte
+	emailIds := make(map[int32]string)
+	for i := len(histories) - 1; i >= 0; i-- {
+		history := histories[i]
+		if history.AppId != appId {
+			continue
+		}
+		config, err := impl.getComponentConfigData(history, configType, componentName)
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			previousComponent = nil
+			continue
+		}
+		component, err := json.Marshal(config)
+		if err != nil {
+			impl.logger.Errorw("error in marshaling config data", "err", err)
+			return nil, err
+		}
+		changed := string(component) != string(previousComponent)
+		previousComponent = component
+		if !changed && !history.Deployed {
+			continue
+		}
+		version := &ComponentVersionDto{
+			Id:        history.Id,
+			CreatedOn: history.CreatedOn,
+			CreatedBy: impl.getEmailId(history.CreatedBy, emailIds),
+			Deployed:  history.Deployed,
+		}
+		if history.Deployed {
+			version.DeployedOn = history.DeployedOn
+			version.DeployedBy = impl.getEmailId(history.DeployedBy, emailIds)
+		}
+		versions = append(versions, version)
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Id > versions[j].Id
+	})
+	return versions, nil
+}
+
+func (impl ConfigMapHistoryServiceImpl) getEmailId(userId int32, emailIds map[int32]string) string {
+	if emailId, ok := emailIds[userId]; ok {
+		return emailId
+	}
+	userInfo, err := impl.userService.GetById(userId)
+	if err != nil {
+		impl.logger.Warnw("unable to find user by id", "err", err, "userId", userId)
+		return ""
+	}
+	emailIds[userId] = userInfo.EmailId
+	return userInfo.EmailId
+}
+
+func (impl ConfigMapHistoryServiceImpl) GetComponentFromHistory(id, appId, pipelineId int, configType repository.ConfigType, componentName string) (*ConfigData, error) {
+	history, err := impl.configMapHistoryRepository.GetById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting CM/CS history", "err", err, "id", id)
+		return nil, err
+	}
+	//history must belong to the level it is requested for, app level history is not linked to a pipeline
+	if history.AppId != appId || history.DataType != configType || history.PipelineId != pipelineId {
+		return nil, fmt.Errorf("history %d not found for the requested %s", id, configType)
+	}
+	config, err := impl.getComponentConfigData(history, configType, componentName)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, fmt.Errorf("%s not found in history %d", componentName, id)
+	}
+	return config, nil
+}
+
+func (impl ConfigMapHistoryServiceImpl) GetComponentVersionDiff(appId, pipelineId int, configType repository.ConfigType, componentName string, fromId, toId int, userHasAdminAccess bool) (*ComponentVersionDiffDto, error) {
+	fromConfig, err := impl.GetComponentFromHistory(fromId, appId, pipelineId, configType, componentName)
+	if err != nil {
+		return nil, err
+	}
+	toConfig, err := impl.GetComponentFromHistory(toId, appId, pipelineId, configType, componentName)
+	if err != nil {
+		return nil, err
+	}
+	fromDto, err := impl.ConvertConfigDataToComponentLevelDto(fromConfig, configType, userHasAdminAccess)
+	if err != nil {
+		return nil, err
+	}
+	toDto, err := impl.ConvertConfigDataToComponentLevelDto(toConfig, configType, userHasAdminAccess)
+	if err != nil {
+		return nil, err
+	}
+	fromFields, err := flattenConfigData(fromConfig)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := flattenConfigData(toConfig)
+	if err != nil {
+		return nil, err
+	}
+	maskValues := configType == repository.SECRET_TYPE && !userHasAdminAccess
+	return &ComponentVersionDiffDto{
+		ComponentName: componentName,
+		FromId:        fromId,
+		ToId:          toId,
+		From:          fromDto.HistoryConfig,
+		To:            toDto.HistoryConfig,
+		Changes:       diffConfigFields(fromFields, toFields, maskValues),
+	}, nil
+}
+
+func (impl ConfigMapHistoryServiceImpl) getComponentConfigData(history *repository.ConfigmapAndSecretHistory, configType repository.ConfigType, componentName string) (*ConfigData, error) {
+	if len(history.Data) == 0 {
+		return nil, nil
+	}
+	var configData []*ConfigData
+	if configType == repository.CONFIGMAP_TYPE {
+		configList := ConfigList{}
+		err := json.Unmarshal([]byte(history.Data), &configList)
+		if err != nil {
+			impl.logger.Errorw("error while Unmarshal", "err", err, "historyId", history.Id)
+			return nil, err
+		}
+		configData = configList.ConfigData
+	} else {
+		secretList := SecretList{}
+		err := json.Unmarshal([]byte(history.Data), &secretList)
+		if err != nil {
+			impl.logger.Errorw("error while Unmarshal", "err", err, "historyId", history.Id)
+			return nil, err
+		}
+		configData = secretList.ConfigData
+	}
+	for _, config := range configData {
+		if config.Name == componentName {
+			return config, nil
+		}
+	}
+	return nil, nil
+}
+
+// flattenConfigData turns a CM/CS into field -> value pairs, data keys are prefixed with "data."
+func flattenConfigData(config *ConfigData) (map[string]string, error) {
+	fields := map[string]string{
+		"type":           config.Type,
+		"external":       fmt.Sprintf("%t", config.External),
+		"mountPath":      config.MountPath,
+		"subPath":        fmt.Sprintf("%t", config.SubPath),
+		"filePermission": config.FilePermission,
+		"externalType":   config.ExternalSecretType,
+		"roleARN":        config.RoleARN,
+	}
+	if len(config.Data) > 0 {
+		data := make(map[string]interface{})
+		if err := json.Unmarshal(config.Data, &data); err != nil {
+			return nil, err
+		}
+		for key, value := range data {
+			if str, ok := value.(string); ok {
+				fields["data."+key] = str
+			} else {
+				valueJson, _ := json.Marshal(value)
+				fields["data."+key] = string(valueJson)
+			}
+		}
+	}
+	if len(config.ExternalSecret) > 0 {
+		externalSecret, err := json.Marshal(config.ExternalSecret)
+		if err != nil {
+			return nil, err
+		}
+		fields["secretData"] = string(externalSecret)
+	}
+	if util.IsESOType(config.ExternalSecretType) {
+		esoSecretData, err := json.Marshal(config.ESOSecretData)
+		if err != nil {
+			return nil, err
+		}
+		fields["esoSecretData"] = string(esoSecretData)
+	}
+	return fields, nil
+}
+
+func diffConfigFields(from, to map[string]string, maskValues bool) []*ComponentFieldChange {
+	var fields []string
+	for field := range from {
+		fields = append(fields, field)
+	}
+	for field := range to {
+		if _, ok := from[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	changes := make([]*ComponentFieldChange, 0)
+	for _, field := range fields {
+		fromValue, inFrom := from[field]
+		toValue, inTo := to[field]
+		change := &ComponentFieldChange{Field: field, From: fromValue, To: toValue}
+		switch {
+		case inFrom && !inTo:
+			change.Change = COMPONENT_FIELD_REMOVED
+		case !inFrom && inTo:
+			change.Change = COMPONENT_FIELD_ADDED
+		case fromValue != toValue:
+			change.Change = COMPONENT_FIELD_MODIFIED
+		default:
+			continue
+		}
+		//external secret references are not secret values, only the data of the secret is masked
+		if maskValues && strings.HasPrefix(field, "data.") {
+			if inFrom {
+				change.From = "*****"
+			}
+			if inTo {
+				change.To = "*****"
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
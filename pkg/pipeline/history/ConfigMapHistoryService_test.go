@@ -0,0 +1,30 @@
+package history
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffConfigFields(t *testing.T) {
+	from, err := flattenConfigData(&ConfigData{Name: "db", Type: "environment", Data: json.RawMessage(`{"user":"admin","host":"old"}`)})
+	assert.Nil(t, err)
+	to, err := flattenConfigData(&ConfigData{Name: "db", Type: "environment", Data: json.RawMessage(`{"host":"new","port":5432}`)})
+	assert.Nil(t, err)
+
+	t.Run("field level changes", func(t *testing.T) {
+		changes := diffConfigFields(from, to, false)
+		assert.Equal(t, 3, len(changes))
+		assert.Equal(t, &ComponentFieldChange{Field: "data.host", Change: COMPONENT_FIELD_MODIFIED, From: "old", To: "new"}, changes[0])
+		assert.Equal(t, &ComponentFieldChange{Field: "data.port", Change: COMPONENT_FIELD_ADDED, To: "5432"}, changes[1])
+		assert.Equal(t, &ComponentFieldChange{Field: "data.user", Change: COMPONENT_FIELD_REMOVED, From: "admin"}, changes[2])
+	})
+	t.Run("secret values are masked", func(t *testing.T) {
+		changes := diffConfigFields(from, to, true)
+		assert.Equal(t, "*****", changes[0].From)
+		assert.Equal(t, "*****", changes[0].To)
+		assert.Equal(t, "", changes[1].From)
+		assert.Equal(t, "*****", changes[1].To)
+	})
+}
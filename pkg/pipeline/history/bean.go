@@ -3,6 +3,7 @@ package history
 import (
 	"encoding/json"
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/externalSecret"
 	"time"
 )
 
@@ -65,6 +66,37 @@ type HistoryDetailConfig struct {
 	Value       string `json:"value"`
 }
 
+type ComponentVersionDto struct {
+	Id         int       `json:"id"`
+	CreatedOn  time.Time `json:"createdOn"`
+	CreatedBy  string    `json:"createdBy"` //emailId of user
+	Deployed   bool      `json:"deployed"`
+	DeployedOn time.Time `json:"deployedOn,omitempty"`
+	DeployedBy string    `json:"deployedBy,omitempty"` //emailId of user
+}
+
+const (
+	COMPONENT_FIELD_ADDED    = "ADDED"
+	COMPONENT_FIELD_REMOVED  = "REMOVED"
+	COMPONENT_FIELD_MODIFIED = "MODIFIED"
+)
+
+type ComponentFieldChange struct {
+	Field  string `json:"field"`
+	Change string `json:"change"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+type ComponentVersionDiffDto struct {
+	ComponentName string                  `json:"componentName"`
+	FromId        int                     `json:"fromId"`
+	ToId          int                     `json:"toId"`
+	From          *HistoryDetailDto       `json:"from"`
+	To            *HistoryDetailDto       `json:"to"`
+	Changes       []*ComponentFieldChange `json:"changes"`
+}
+
 //history components(deployment template, configMaps, secrets, pipeline strategy) components below
 
 type ConfigMapAndSecretHistoryDto struct {
@@ -167,10 +199,11 @@ type ExternalSecret struct {
 }
 
 type ESOSecretData struct {
-	SecretStore     json.RawMessage `json:"secretStore,omitempty"`
-	SecretStoreRef  json.RawMessage `json:"secretStoreRef,omitempty"`
-	EsoData         []ESOData       `json:"esoData"`
-	RefreshInterval string          `json:"refreshInterval,omitempty"`
+	SecretStore     json.RawMessage                `json:"secretStore,omitempty"`
+	SecretStoreRef  json.RawMessage                `json:"secretStoreRef,omitempty"`
+	EsoData         []ESOData                      `json:"esoData"`
+	RefreshInterval string                         `json:"refreshInterval,omitempty"`
+	Provider        *externalSecret.ProviderConfig `json:"provider,omitempty"`
 }
 
 type ESOData struct {
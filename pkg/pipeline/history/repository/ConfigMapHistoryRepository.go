@@ -2,9 +2,12 @@ package repository
 
 import (
 	"fmt"
+	"github.com/devtron-labs/devtron/pkg/encryption"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
 	"go.uber.org/zap"
+	"strings"
 	"time"
 )
 
@@ -21,6 +24,9 @@ type ConfigMapHistoryRepository interface {
 	GetDeploymentDetailsForDeployedCMCSHistory(pipelineId int, configType ConfigType) ([]*ConfigmapAndSecretHistory, error)
 	GetHistoryByPipelineIdAndWfrId(pipelineId, wfrId int, configType ConfigType) (*ConfigmapAndSecretHistory, error)
 	GetDeployedHistoryList(pipelineId, baseConfigId int, configType ConfigType, componentName string) ([]*ConfigmapAndSecretHistory, error)
+	GetById(id int) (*ConfigmapAndSecretHistory, error)
+	GetAppLevelHistoryList(appId int, configType ConfigType) ([]*ConfigmapAndSecretHistory, error)
+	GetPipelineLevelHistoryList(pipelineId int, configType ConfigType) ([]*ConfigmapAndSecretHistory, error)
 }
 
 type ConfigMapHistoryRepositoryImpl struct {
@@ -48,6 +54,34 @@ type ConfigmapAndSecretHistory struct {
 	DeployedByEmailId string `sql:"-"`
 }
 
+// only secret history rows are encrypted, decryption is attempted for every row as data_type may not be selected
+
+func (model *ConfigmapAndSecretHistory) BeforeInsert(db orm.DB) error {
+	if model.DataType != SECRET_TYPE {
+		return nil
+	}
+	return encryption.EncryptField(&model.Data)
+}
+
+func (model *ConfigmapAndSecretHistory) BeforeUpdate(db orm.DB) error {
+	if model.DataType != SECRET_TYPE {
+		return nil
+	}
+	return encryption.EncryptField(&model.Data)
+}
+
+func (model *ConfigmapAndSecretHistory) AfterInsert(db orm.DB) error {
+	return encryption.DecryptField(&model.Data)
+}
+
+func (model *ConfigmapAndSecretHistory) AfterUpdate(db orm.DB) error {
+	return encryption.DecryptField(&model.Data)
+}
+
+func (model *ConfigmapAndSecretHistory) AfterQuery(db orm.DB) error {
+	return encryption.DecryptField(&model.Data)
+}
+
 func (impl ConfigMapHistoryRepositoryImpl) CreateHistory(model *ConfigmapAndSecretHistory) (*ConfigmapAndSecretHistory, error) {
 	err := impl.dbConnection.Insert(model)
 	if err != nil {
@@ -97,16 +131,65 @@ func (impl ConfigMapHistoryRepositoryImpl) GetHistoryByPipelineIdAndWfrId(pipeli
 
 func (impl ConfigMapHistoryRepositoryImpl) GetDeployedHistoryList(pipelineId, baseConfigId int, configType ConfigType, componentName string) ([]*ConfigmapAndSecretHistory, error) {
 	var histories []*ConfigmapAndSecretHistory
-	query := "SELECT cmh.id, cmh.deployed_on, cmh.deployed_by, cwr.status as deployment_status, users.email_id as deployed_by_email_id" +
+	// encrypted rows can not be matched on name in sql, they are selected along with data and matched after decryption
+	namePattern := fmt.Sprintf("\"name\":\"%s\"", componentName)
+	query := "SELECT cmh.id, cmh.data, cmh.deployed_on, cmh.deployed_by, cwr.status as deployment_status, users.email_id as deployed_by_email_id" +
 		" FROM config_map_history cmh" +
 		" INNER JOIN cd_workflow_runner cwr ON cwr.started_on = cmh.deployed_on" +
 		" INNER JOIN users ON users.id = cmh.deployed_by" +
-		" WHERE cmh.pipeline_id = ? AND cmh.deployed = true AND cmh.id <= ? AND cmh.data_type = ? AND cmh.data LIKE ?" +
+		" WHERE cmh.pipeline_id = ? AND cmh.deployed = true AND cmh.id <= ? AND cmh.data_type = ? AND (cmh.data LIKE ? OR cmh.data LIKE ?)" +
 		" ORDER BY cmh.id DESC;"
-	_, err := impl.dbConnection.Query(&histories, query, pipelineId, baseConfigId, configType, "%"+fmt.Sprintf("\"name\":\"%s\"", componentName)+"%")
+	_, err := impl.dbConnection.Query(&histories, query, pipelineId, baseConfigId, configType, "%"+namePattern+"%", encryption.EncryptedValuePrefix+"%")
 	if err != nil {
 		impl.logger.Errorw("error in getting configmap/secret history list by pipelineId", "err", err, "pipelineId", pipelineId)
 		return histories, err
 	}
+	filteredHistories := make([]*ConfigmapAndSecretHistory, 0, len(histories))
+	for _, history := range histories {
+		if strings.Contains(history.Data, namePattern) {
+			history.Data = ""
+			filteredHistories = append(filteredHistories, history)
+		}
+	}
+	return filteredHistories, nil
+}
+
+func (impl ConfigMapHistoryRepositoryImpl) GetById(id int) (*ConfigmapAndSecretHistory, error) {
+	var history ConfigmapAndSecretHistory
+	err := impl.dbConnection.Model(&history).Where("id = ?", id).Select()
+	if err != nil {
+		impl.logger.Errorw("error in getting CM/CS history by id", "err", err, "id", id)
+		return &history, err
+	}
+	return &history, nil
+}
+
+// GetAppLevelHistoryList returns history of app level edits, these entries are not linked to any pipeline
+func (impl ConfigMapHistoryRepositoryImpl) GetAppLevelHistoryList(appId int, configType ConfigType) ([]*ConfigmapAndSecretHistory, error) {
+	var histories []*ConfigmapAndSecretHistory
+	err := impl.dbConnection.Model(&histories).Where("app_id = ?", appId).
+		Where("data_type = ?", configType).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("pipeline_id IS NULL").WhereOr("pipeline_id = ?", 0)
+			return q, nil
+		}).
+		Order("id DESC").Select()
+	if err != nil {
+		impl.logger.Errorw("error in getting app level CM/CS history", "err", err, "appId", appId)
+		return histories, err
+	}
+	return histories, nil
+}
+
+// GetPipelineLevelHistoryList returns both edits and deployments recorded for the pipeline
+func (impl ConfigMapHistoryRepositoryImpl) GetPipelineLevelHistoryList(pipelineId int, configType ConfigType) ([]*ConfigmapAndSecretHistory, error) {
+	var histories []*ConfigmapAndSecretHistory
+	err := impl.dbConnection.Model(&histories).Where("pipeline_id = ?", pipelineId).
+		Where("data_type = ?", configType).
+		Order("id DESC").Select()
+	if err != nil {
+		impl.logger.Errorw("error in getting pipeline level CM/CS history", "err", err, "pipelineId", pipelineId)
+		return histories, err
+	}
 	return histories, nil
 }
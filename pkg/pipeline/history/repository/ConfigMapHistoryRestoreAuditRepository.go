@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ConfigMapHistoryRestoreAuditRepository interface {
+	Save(model *ConfigMapHistoryRestoreAudit) error
+	FindByAppId(appId int) ([]*ConfigMapHistoryRestoreAudit, error)
+}
+
+type ConfigMapHistoryRestoreAuditRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewConfigMapHistoryRestoreAuditRepositoryImpl(logger *zap.SugaredLogger, dbConnection *pg.DB) *ConfigMapHistoryRestoreAuditRepositoryImpl {
+	return &ConfigMapHistoryRestoreAuditRepositoryImpl{dbConnection: dbConnection, logger: logger}
+}
+
+type ConfigMapHistoryRestoreAudit struct {
+	TableName     struct{}   `sql:"config_map_history_restore_audit" pg:",discard_unknown_columns"`
+	Id            int        `sql:"id,pk"`
+	AppId         int        `sql:"app_id"`
+	EnvironmentId int        `sql:"environment_id"`
+	DataType      ConfigType `sql:"data_type"`
+	Name          string     `sql:"name"`
+	HistoryId     int        `sql:"history_id"`
+	Redeployed    bool       `sql:"redeployed,notnull"`
+	ReleaseId     int        `sql:"release_id"`
+	sql.AuditLog
+}
+
+func (impl ConfigMapHistoryRestoreAuditRepositoryImpl) Save(model *ConfigMapHistoryRestoreAudit) error {
+	err := impl.dbConnection.Insert(model)
+	if err != nil {
+		impl.logger.Errorw("error in saving CM/CS restore audit", "err", err, "model", model)
+		return err
+	}
+	return nil
+}
+
+func (impl ConfigMapHistoryRestoreAuditRepositoryImpl) FindByAppId(appId int) ([]*ConfigMapHistoryRestoreAudit, error) {
+	var audits []*ConfigMapHistoryRestoreAudit
+	err := impl.dbConnection.Model(&audits).Where("app_id = ?", appId).Order("id DESC").Select()
+	if err != nil {
+		impl.logger.Errorw("error in getting CM/CS restore audit", "err", err, "appId", appId)
+		return audits, err
+	}
+	return audits, nil
+}
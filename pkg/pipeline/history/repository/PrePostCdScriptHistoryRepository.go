@@ -2,8 +2,10 @@ package repository
 
 import (
 	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/pkg/encryption"
 	"github.com/devtron-labs/devtron/pkg/sql"
 	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
 	"go.uber.org/zap"
 	"time"
 )
@@ -32,6 +34,26 @@ type PrePostCdScriptHistory struct {
 	sql.AuditLog
 }
 
+func (model *PrePostCdScriptHistory) BeforeInsert(db orm.DB) error {
+	return encryption.EncryptField(&model.SecretData)
+}
+
+func (model *PrePostCdScriptHistory) BeforeUpdate(db orm.DB) error {
+	return encryption.EncryptField(&model.SecretData)
+}
+
+func (model *PrePostCdScriptHistory) AfterInsert(db orm.DB) error {
+	return encryption.DecryptField(&model.SecretData)
+}
+
+func (model *PrePostCdScriptHistory) AfterUpdate(db orm.DB) error {
+	return encryption.DecryptField(&model.SecretData)
+}
+
+func (model *PrePostCdScriptHistory) AfterQuery(db orm.DB) error {
+	return encryption.DecryptField(&model.SecretData)
+}
+
 type PrePostCdScriptHistoryRepository interface {
 	CreateHistoryWithTxn(history *PrePostCdScriptHistory, tx *pg.Tx) (*PrePostCdScriptHistory, error)
 	CreateHistory(history *PrePostCdScriptHistory) (*PrePostCdScriptHistory, error)
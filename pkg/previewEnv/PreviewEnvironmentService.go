@@ -0,0 +1,781 @@
+package previewEnv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/caarlos0/env"
+	bean2 "github.com/devtron-labs/devtron/api/bean"
+	"github.com/devtron-labs/devtron/client/gitSensor"
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/appClone"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"github.com/devtron-labs/devtron/pkg/chart"
+	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/previewEnv/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/util/argo"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type PreviewEnvironmentService interface {
+	SaveConfig(request *PreviewEnvConfigDto) (*PreviewEnvConfigDto, error)
+	GetConfig(appId int) (*PreviewEnvConfigDto, error)
+	DeleteConfig(appId int, userId int32) error
+	HandleWebhookEvent(ciPipelineMaterialId int, webhookData *gitSensor.WebhookData)
+	GetActivePreviews(appId int) ([]*PreviewEnvironmentDto, error)
+	GetPreview(id int) (*PreviewEnvironmentDto, error)
+	DeletePreview(id int, userId int32) error
+}
+
+type PreviewEnvironmentServiceImpl struct {
+	logger                       *zap.SugaredLogger
+	previewEnvConfig             *PreviewEnvConfig
+	previewEnvRepository         repository.PreviewEnvironmentRepository
+	ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository
+	ciPipelineRepository         pipelineConfig.CiPipelineRepository
+	ciWorkflowRepository         pipelineConfig.CiWorkflowRepository
+	pipelineRepository           pipelineConfig.PipelineRepository
+	appRepository                app.AppRepository
+	environmentService           cluster.EnvironmentService
+	clusterService               cluster.ClusterService
+	K8sUtil                      *util.K8sUtil
+	appCloneService              appClone.AppCloneService
+	chartService                 chart.ChartService
+	propertiesConfigService      pipeline.PropertiesConfigService
+	pipelineBuilder              pipeline.PipelineBuilder
+	workflowDagExecutor          pipeline.WorkflowDagExecutor
+	argoUserService              argo.ArgoUserService
+	httpClient                   *http.Client
+	cron                         *cron.Cron
+}
+
+func NewPreviewEnvironmentServiceImpl(logger *zap.SugaredLogger, previewEnvRepository repository.PreviewEnvironmentRepository,
+	ciPipelineMaterialRepository pipelineConfig.CiPipelineMaterialRepository, ciPipelineRepository pipelineConfig.CiPipelineRepository,
+	ciWorkflowRepository pipelineConfig.CiWorkflowRepository, pipelineRepository pipelineConfig.PipelineRepository, appRepository app.AppRepository,
+	environmentService cluster.EnvironmentService, clusterService cluster.ClusterService, K8sUtil *util.K8sUtil,
+	appCloneService appClone.AppCloneService, chartService chart.ChartService, propertiesConfigService pipeline.PropertiesConfigService,
+	pipelineBuilder pipeline.PipelineBuilder, workflowDagExecutor pipeline.WorkflowDagExecutor, argoUserService argo.ArgoUserService) (*PreviewEnvironmentServiceImpl, error) {
+	previewEnvConfig := &PreviewEnvConfig{}
+	err := env.Parse(previewEnvConfig)
+	if err != nil {
+		logger.Errorw("error in parsing preview env config", "err", err)
+		return nil, err
+	}
+	impl := &PreviewEnvironmentServiceImpl{
+		logger:                       logger,
+		previewEnvConfig:             previewEnvConfig,
+		previewEnvRepository:         previewEnvRepository,
+		ciPipelineMaterialRepository: ciPipelineMaterialRepository,
+		ciPipelineRepository:         ciPipelineRepository,
+		ciWorkflowRepository:         ciWorkflowRepository,
+		pipelineRepository:           pipelineRepository,
+		appRepository:                appRepository,
+		environmentService:           environmentService,
+		clusterService:               clusterService,
+		K8sUtil:                      K8sUtil,
+		appCloneService:              appCloneService,
+		chartService:                 chartService,
+		propertiesConfigService:      propertiesConfigService,
+		pipelineBuilder:              pipelineBuilder,
+		workflowDagExecutor:          workflowDagExecutor,
+		argoUserService:              argoUserService,
+		httpClient:                   &http.Client{Timeout: time.Duration(previewEnvConfig.CallbackTimeout) * time.Second},
+	}
+	// cron deploys previews once build of their head commit succeeds and tears down expired previews
+	impl.cron = cron.New(cron.WithChain())
+	impl.cron.Start()
+	_, err = impl.cron.AddFunc(fmt.Sprintf("@every %dm", previewEnvConfig.SyncIntervalMins), impl.syncPreviews)
+	if err != nil {
+		logger.Errorw("error in adding preview env sync cron", "err", err)
+		return nil, err
+	}
+	return impl, nil
+}
+
+func (impl *PreviewEnvironmentServiceImpl) SaveConfig(request *PreviewEnvConfigDto) (*PreviewEnvConfigDto, error) {
+	if len(request.OverrideValues) > 0 && !json.Valid(request.OverrideValues) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: "override values must be valid json"}
+	}
+	ciPipeline, err := impl.ciPipelineRepository.FindById(request.CiPipelineId)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting ci pipeline", "ciPipelineId", request.CiPipelineId, "err", err)
+		return nil, err
+	}
+	if util.IsErrNoRows(err) || ciPipeline.AppId != request.AppId {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: "ci pipeline not found in app"}
+	}
+	rewrites, err := json.Marshal(request.Rewrites)
+	if err != nil {
+		return nil, err
+	}
+	model, err := impl.previewEnvRepository.FindConfigByAppId(request.AppId)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting preview env config", "appId", request.AppId, "err", err)
+		return nil, err
+	}
+	model.AppId = request.AppId
+	model.CiPipelineId = request.CiPipelineId
+	model.SourceEnvId = request.SourceEnvId
+	model.ClusterId = request.ClusterId
+	model.EnvNamePrefix = request.EnvNamePrefix
+	model.UrlTemplate = request.UrlTemplate
+	model.CallbackUrl = request.CallbackUrl
+	model.TtlHours = request.TtlHours
+	model.OverrideValues = string(request.OverrideValues)
+	model.Rewrites = string(rewrites)
+	model.Active = true
+	model.UpdatedBy = request.UserId
+	model.UpdatedOn = time.Now()
+	if model.Id == 0 {
+		model.CreatedBy = request.UserId
+		model.CreatedOn = time.Now()
+		err = impl.previewEnvRepository.SaveConfig(model)
+	} else {
+		err = impl.previewEnvRepository.UpdateConfig(model)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving preview env config", "appId", request.AppId, "err", err)
+		return nil, err
+	}
+	request.Id = model.Id
+	return request, nil
+}
+
+func (impl *PreviewEnvironmentServiceImpl) GetConfig(appId int) (*PreviewEnvConfigDto, error) {
+	model, err := impl.previewEnvRepository.FindConfigByAppId(appId)
+	if err != nil {
+		impl.logger.Errorw("error in getting preview env config", "appId", appId, "err", err)
+		return nil, err
+	}
+	return impl.buildConfigDto(model)
+}
+
+// DeleteConfig deactivates the preview config of app and tears down all its active previews
+func (impl *PreviewEnvironmentServiceImpl) DeleteConfig(appId int, userId int32) error {
+	model, err := impl.previewEnvRepository.FindConfigByAppId(appId)
+	if err != nil {
+		impl.logger.Errorw("error in getting preview env config", "appId", appId, "err", err)
+		return err
+	}
+	previews, err := impl.previewEnvRepository.FindActiveByConfigId(model.Id)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting previews of config", "configId", model.Id, "err", err)
+		return err
+	}
+	for _, preview := range previews {
+		err = impl.teardownPreview(model, preview, "preview config deleted", userId)
+		if err != nil {
+			return err
+		}
+	}
+	model.Active = false
+	model.UpdatedBy = userId
+	model.UpdatedOn = time.Now()
+	return impl.previewEnvRepository.UpdateConfig(model)
+}
+
+// HandleWebhookEvent creates or refreshes the preview of a pull request when it is opened or updated and tears it
+// down when pull request is closed or merged. Build of the PR commit is triggered by the ci pipeline itself, the
+// preview is deployed by sync cron once that build succeeds
+func (impl *PreviewEnvironmentServiceImpl) HandleWebhookEvent(ciPipelineMaterialId int, webhookData *gitSensor.WebhookData) {
+	if webhookData == nil {
+		return
+	}
+	prUniqueId := webhookData.Data[bean.WEBHOOK_SELECTOR_UNIQUE_ID_NAME]
+	if len(prUniqueId) == 0 {
+		return
+	}
+	ciPipelineMaterial, err := impl.ciPipelineMaterialRepository.GetById(ciPipelineMaterialId)
+	if err != nil {
+		impl.logger.Errorw("error in getting ci pipeline material", "id", ciPipelineMaterialId, "err", err)
+		return
+	}
+	configs, err := impl.previewEnvRepository.FindConfigsByCiPipelineId(ciPipelineMaterial.CiPipelineId)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting preview env configs", "ciPipelineId", ciPipelineMaterial.CiPipelineId, "err", err)
+		return
+	}
+	closed := closedPrStates[strings.ToLower(webhookData.Data[WEBHOOK_SELECTOR_STATE_NAME])]
+	for _, config := range configs {
+		preview, err := impl.previewEnvRepository.FindActiveByConfigIdAndPr(config.Id, prUniqueId)
+		if err != nil && !util.IsErrNoRows(err) {
+			impl.logger.Errorw("error in getting preview of pr", "configId", config.Id, "prUniqueId", prUniqueId, "err", err)
+			continue
+		}
+		if closed {
+			if preview.Id > 0 {
+				err = impl.teardownPreview(config, preview, "pull request closed", 1)
+			}
+		} else if preview.Id > 0 {
+			err = impl.refreshPreview(config, preview, webhookData)
+		} else {
+			err = impl.createPreview(config, prUniqueId, webhookData)
+		}
+		if err != nil {
+			impl.logger.Errorw("error in handling preview of pr", "configId", config.Id, "prUniqueId", prUniqueId, "err", err)
+		}
+	}
+}
+
+func (impl *PreviewEnvironmentServiceImpl) GetActivePreviews(appId int) ([]*PreviewEnvironmentDto, error) {
+	var previews []*repository.PreviewEnvironment
+	var err error
+	if appId > 0 {
+		previews, err = impl.previewEnvRepository.FindActiveByAppId(appId)
+	} else {
+		previews, err = impl.previewEnvRepository.FindAllActive()
+	}
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting active previews", "appId", appId, "err", err)
+		return nil, err
+	}
+	clusterConfigs := make(map[int]*util.ClusterConfig)
+	result := make([]*PreviewEnvironmentDto, 0, len(previews))
+	for _, preview := range previews {
+		dto, err := impl.buildPreviewDto(preview, clusterConfigs)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dto)
+	}
+	return result, nil
+}
+
+func (impl *PreviewEnvironmentServiceImpl) GetPreview(id int) (*PreviewEnvironmentDto, error) {
+	preview, err := impl.previewEnvRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting preview", "id", id, "err", err)
+		return nil, err
+	}
+	return impl.buildPreviewDto(preview, make(map[int]*util.ClusterConfig))
+}
+
+func (impl *PreviewEnvironmentServiceImpl) DeletePreview(id int, userId int32) error {
+	preview, err := impl.previewEnvRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting preview", "id", id, "err", err)
+		return err
+	}
+	if !preview.Active {
+		return &util.ApiError{HttpStatusCode: http.StatusBadRequest, UserMessage: "preview is already deleted"}
+	}
+	config, err := impl.previewEnvRepository.FindConfigById(preview.PreviewEnvConfigId)
+	if err != nil {
+		impl.logger.Errorw("error in getting preview env config", "id", preview.PreviewEnvConfigId, "err", err)
+		return err
+	}
+	return impl.teardownPreview(config, preview, "deleted by user", userId)
+}
+
+func (impl *PreviewEnvironmentServiceImpl) createPreview(config *repository.PreviewEnvConfig, prUniqueId string, webhookData *gitSensor.WebhookData) error {
+	application, err := impl.appRepository.FindById(config.AppId)
+	if err != nil {
+		impl.logger.Errorw("error in getting app", "appId", config.AppId, "err", err)
+		return err
+	}
+	envName := previewEnvName(config.EnvNamePrefix, application.AppName, prUniqueId)
+	branch := webhookData.Data[bean.WEBHOOK_SELECTOR_SOURCE_BRANCH_NAME_NAME]
+	tokens := map[string]string{
+		PREVIEW_TOKEN_APP_NAME:  application.AppName,
+		PREVIEW_TOKEN_PR_ID:     prUniqueId,
+		PREVIEW_TOKEN_BRANCH:    branch,
+		PREVIEW_TOKEN_ENV_NAME:  envName,
+		PREVIEW_TOKEN_NAMESPACE: envName,
+	}
+	preview := &repository.PreviewEnvironment{
+		PreviewEnvConfigId: config.Id,
+		AppId:              config.AppId,
+		PrUniqueId:         prUniqueId,
+		PrTitle:            webhookData.Data[WEBHOOK_SELECTOR_TITLE_NAME],
+		SourceBranch:       branch,
+		HeadCommit:         webhookData.Data[bean.WEBHOOK_SELECTOR_SOURCE_CHECKOUT_NAME],
+		Namespace:          envName,
+		Url:                renderPreviewTemplate(config.UrlTemplate, tokens, false),
+		Status:             PREVIEW_ENV_STATUS_BUILDING,
+		ExpiresOn:          time.Now().Add(time.Duration(config.TtlHours) * time.Hour),
+		Active:             true,
+		AuditLog:           sql.AuditLog{CreatedOn: time.Now(), CreatedBy: 1, UpdatedOn: time.Now(), UpdatedBy: 1},
+	}
+	err = impl.previewEnvRepository.Save(preview)
+	if err != nil {
+		impl.logger.Errorw("error in saving preview", "configId", config.Id, "prUniqueId", prUniqueId, "err", err)
+		return err
+	}
+	err = impl.provisionPreview(config, preview, tokens)
+	if err != nil {
+		impl.markPreviewFailed(config, preview, application.AppName, err.Error())
+		return err
+	}
+	return impl.previewEnvRepository.Update(preview)
+}
+
+// provisionPreview creates env and namespace of the preview and clones source env config and pipeline of app into it
+func (impl *PreviewEnvironmentServiceImpl) provisionPreview(config *repository.PreviewEnvConfig, preview *repository.PreviewEnvironment, tokens map[string]string) error {
+	envBean, err := impl.environmentService.Create(&cluster.EnvironmentBean{
+		Environment: preview.Namespace,
+		ClusterId:   config.ClusterId,
+		Namespace:   preview.Namespace,
+		Active:      true,
+	}, 1)
+	if err != nil {
+		impl.logger.Errorw("error in creating preview env", "env", preview.Namespace, "err", err)
+		return err
+	}
+	preview.EnvironmentId = envBean.Id
+	var rewrites []*appClone.EnvCloneRewrite
+	if len(config.Rewrites) > 0 {
+		err = json.Unmarshal([]byte(config.Rewrites), &rewrites)
+		if err != nil {
+			return err
+		}
+	}
+	for _, rewrite := range rewrites {
+		rewrite.Replace = renderPreviewTemplate(rewrite.Replace, tokens, false)
+	}
+	ctx, err := impl.buildAcdContext()
+	if err != nil {
+		return err
+	}
+	//preview pipelines are manual, the shared ci pipeline builds every pr and each preview deploys only its own pr
+	cloneResponse, err := impl.appCloneService.CloneEnvironment(ctx, &appClone.EnvCloneRequest{
+		SourceEnvId: config.SourceEnvId,
+		TargetEnvId: envBean.Id,
+		AppIds:      []int{config.AppId},
+		Rewrites:    rewrites,
+		TriggerType: pipelineConfig.TRIGGER_TYPE_MANUAL,
+		UserId:      1,
+	}, "", func(token string, appObject string, sourceEnvObject string, targetEnvObject string) bool { return true })
+	if err != nil {
+		return err
+	}
+	if len(cloneResponse.Apps) == 0 {
+		return fmt.Errorf("app has no pipeline on source environment")
+	}
+	if result := cloneResponse.Apps[0]; result.Status != appClone.ENV_CLONE_STATUS_SUCCESS {
+		return fmt.Errorf("error in cloning source environment: %s", result.Message)
+	} else {
+		preview.PipelineId = result.PipelineId
+	}
+	if len(config.OverrideValues) > 0 {
+		overrideValues := renderPreviewTemplate(config.OverrideValues, tokens, true)
+		err = impl.applyOverrideValues(config.AppId, envBean.Id, preview.Namespace, json.RawMessage(overrideValues))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyOverrideValues merges pr specific values over env override of preview env, over app values when the source env
+// had no override
+func (impl *PreviewEnvironmentServiceImpl) applyOverrideValues(appId, envId int, namespace string, overrideValues json.RawMessage) error {
+	chartRefRes, err := impl.chartService.ChartRefAutocompleteForAppOrEnv(appId, envId)
+	if err != nil {
+		return err
+	}
+	envProperties, err := impl.propertiesConfigService.GetEnvironmentProperties(appId, envId, chartRefRes.LatestEnvChartRef)
+	if err != nil {
+		impl.logger.Errorw("error in getting env properties", "appId", appId, "envId", envId, "err", err)
+		return err
+	}
+	baseValues := envProperties.GlobalConfig
+	if envProperties.IsOverride {
+		baseValues = envProperties.EnvironmentConfig.EnvOverrideValues
+	}
+	merged, err := jsonpatch.MergePatch(baseValues, overrideValues)
+	if err != nil {
+		impl.logger.Errorw("error in merging preview override values", "appId", appId, "envId", envId, "err", err)
+		return err
+	}
+	envPropertiesReq := &pipeline.EnvironmentProperties{
+		Id:                envProperties.EnvironmentConfig.Id,
+		EnvOverrideValues: merged,
+		Status:            envProperties.EnvironmentConfig.Status,
+		ManualReviewed:    true,
+		Active:            true,
+		Namespace:         namespace,
+		EnvironmentId:     envId,
+		EnvironmentName:   envProperties.EnvironmentConfig.EnvironmentName,
+		Latest:            true,
+		UserId:            1,
+		AppMetrics:        envProperties.EnvironmentConfig.AppMetrics,
+		ChartRefId:        chartRefRes.LatestEnvChartRef,
+		IsOverride:        true,
+		IsBasicViewLocked: envProperties.EnvironmentConfig.IsBasicViewLocked,
+		CurrentViewEditor: envProperties.EnvironmentConfig.CurrentViewEditor,
+	}
+	if envProperties.IsOverride {
+		_, err = impl.propertiesConfigService.UpdateEnvironmentProperties(appId, envPropertiesReq, 1)
+	} else {
+		_, err = impl.propertiesConfigService.CreateEnvironmentProperties(appId, envPropertiesReq)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving preview override values", "appId", appId, "envId", envId, "err", err)
+	}
+	return err
+}
+
+// refreshPreview points an existing preview at the new head commit of pr and extends its ttl
+func (impl *PreviewEnvironmentServiceImpl) refreshPreview(config *repository.PreviewEnvConfig, preview *repository.PreviewEnvironment, webhookData *gitSensor.WebhookData) error {
+	headCommit := webhookData.Data[bean.WEBHOOK_SELECTOR_SOURCE_CHECKOUT_NAME]
+	if preview.Status == PREVIEW_ENV_STATUS_FAILED && preview.PipelineId == 0 {
+		//provisioning had failed, recreate the preview from scratch
+		err := impl.teardownPreview(config, preview, "recreating failed preview", 1)
+		if err != nil {
+			return err
+		}
+		return impl.createPreview(config, preview.PrUniqueId, webhookData)
+	}
+	if headCommit != preview.HeadCommit {
+		preview.HeadCommit = headCommit
+		preview.Status = PREVIEW_ENV_STATUS_BUILDING
+		preview.Message = ""
+	}
+	if title := webhookData.Data[WEBHOOK_SELECTOR_TITLE_NAME]; len(title) > 0 {
+		preview.PrTitle = title
+	}
+	preview.ExpiresOn = time.Now().Add(time.Duration(config.TtlHours) * time.Hour)
+	preview.UpdatedOn = time.Now()
+	return impl.previewEnvRepository.Update(preview)
+}
+
+func (impl *PreviewEnvironmentServiceImpl) teardownPreview(config *repository.PreviewEnvConfig, preview *repository.PreviewEnvironment, reason string, userId int32) error {
+	impl.logger.Infow("tearing down preview", "id", preview.Id, "appId", preview.AppId, "prUniqueId", preview.PrUniqueId, "reason", reason)
+	if preview.PipelineId > 0 {
+		cdPipeline, err := impl.pipelineRepository.FindById(preview.PipelineId)
+		if err != nil && !util.IsErrNoRows(err) {
+			impl.logger.Errorw("error in getting preview pipeline", "pipelineId", preview.PipelineId, "err", err)
+			return err
+		}
+		if cdPipeline != nil && cdPipeline.Id > 0 {
+			ctx, err := impl.buildAcdContext()
+			if err != nil {
+				return err
+			}
+			err = impl.pipelineBuilder.DeleteCdPipeline(cdPipeline, ctx, true, userId)
+			if err != nil {
+				impl.logger.Errorw("error in deleting preview pipeline", "pipelineId", preview.PipelineId, "err", err)
+				return err
+			}
+		}
+	}
+	if preview.EnvironmentId > 0 {
+		err := impl.environmentService.Delete(&cluster.EnvironmentBean{Id: preview.EnvironmentId}, userId)
+		if err != nil && !util.IsErrNoRows(err) {
+			impl.logger.Errorw("error in deleting preview env", "envId", preview.EnvironmentId, "err", err)
+			return err
+		}
+		clusterConfig, err := impl.getClusterConfig(config.ClusterId, make(map[int]*util.ClusterConfig))
+		if err != nil {
+			return err
+		}
+		err = impl.K8sUtil.DeleteNsIfExists(preview.Namespace, clusterConfig)
+		if err != nil {
+			impl.logger.Errorw("error in deleting preview namespace", "namespace", preview.Namespace, "err", err)
+			return err
+		}
+	}
+	preview.Active = false
+	preview.Status = PREVIEW_ENV_STATUS_DELETED
+	preview.Message = reason
+	preview.UpdatedBy = userId
+	preview.UpdatedOn = time.Now()
+	err := impl.previewEnvRepository.Update(preview)
+	if err != nil {
+		impl.logger.Errorw("error in updating preview", "id", preview.Id, "err", err)
+		return err
+	}
+	impl.notify(config, preview, "")
+	return nil
+}
+
+// syncPreviews is run by cron, it tears down expired previews and deploys previews whose head commit got built
+func (impl *PreviewEnvironmentServiceImpl) syncPreviews() {
+	previews, err := impl.previewEnvRepository.FindAllActive()
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting active previews", "err", err)
+		return
+	}
+	configs := make(map[int]*repository.PreviewEnvConfig)
+	for _, preview := range previews {
+		config, ok := configs[preview.PreviewEnvConfigId]
+		if !ok {
+			config, err = impl.previewEnvRepository.FindConfigById(preview.PreviewEnvConfigId)
+			if err != nil {
+				impl.logger.Errorw("error in getting preview env config", "id", preview.PreviewEnvConfigId, "err", err)
+				continue
+			}
+			configs[config.Id] = config
+		}
+		if time.Now().After(preview.ExpiresOn) {
+			err = impl.teardownPreview(config, preview, "ttl expired", 1)
+		} else if preview.Status == PREVIEW_ENV_STATUS_BUILDING && preview.PipelineId > 0 {
+			err = impl.deployIfBuilt(config, preview)
+		}
+		if err != nil {
+			impl.logger.Errorw("error in syncing preview", "id", preview.Id, "err", err)
+		}
+	}
+}
+
+func (impl *PreviewEnvironmentServiceImpl) deployIfBuilt(config *repository.PreviewEnvConfig, preview *repository.PreviewEnvironment) error {
+	workflows, err := impl.ciWorkflowRepository.FindByPipelineId(config.CiPipelineId, 0, 50)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting ci workflows", "ciPipelineId", config.CiPipelineId, "err", err)
+		return err
+	}
+	var workflow *pipelineConfig.WorkflowWithArtifact
+	for i := range workflows {
+		if isWorkflowOfCommit(&workflows[i], preview.PrUniqueId, preview.HeadCommit) {
+			workflow = &workflows[i]
+			break
+		}
+	}
+	if workflow == nil {
+		return nil
+	}
+	appName := impl.getAppName(preview.AppId)
+	switch workflow.Status {
+	case pipelineConfig.WorkflowSucceeded:
+		if workflow.CiArtifactId == 0 {
+			return nil
+		}
+	case pipelineConfig.WorkflowFailed, pipelineConfig.WorkflowAborted, "Error":
+		impl.markPreviewFailed(config, preview, appName, fmt.Sprintf("build %s of pr head commit %s", strings.ToLower(workflow.Status), preview.HeadCommit))
+		return nil
+	default:
+		return nil
+	}
+	ctx, err := impl.buildAcdContext()
+	if err != nil {
+		return err
+	}
+	_, err = impl.workflowDagExecutor.ManualCdTrigger(&bean2.ValuesOverrideRequest{
+		PipelineId:     preview.PipelineId,
+		AppId:          preview.AppId,
+		CiArtifactId:   workflow.CiArtifactId,
+		UserId:         1,
+		CdWorkflowType: bean2.CD_WORKFLOW_TYPE_DEPLOY,
+	}, ctx)
+	if err != nil {
+		impl.logger.Errorw("error in deploying preview", "id", preview.Id, "ciArtifactId", workflow.CiArtifactId, "err", err)
+		impl.markPreviewFailed(config, preview, appName, err.Error())
+		return err
+	}
+	preview.Status = PREVIEW_ENV_STATUS_DEPLOYED
+	preview.Message = ""
+	preview.CiArtifactId = workflow.CiArtifactId
+	preview.UpdatedOn = time.Now()
+	err = impl.previewEnvRepository.Update(preview)
+	if err != nil {
+		impl.logger.Errorw("error in updating preview", "id", preview.Id, "err", err)
+		return err
+	}
+	impl.notify(config, preview, appName)
+	return nil
+}
+
+func (impl *PreviewEnvironmentServiceImpl) markPreviewFailed(config *repository.PreviewEnvConfig, preview *repository.PreviewEnvironment, appName string, message string) {
+	preview.Status = PREVIEW_ENV_STATUS_FAILED
+	preview.Message = message
+	preview.UpdatedOn = time.Now()
+	err := impl.previewEnvRepository.Update(preview)
+	if err != nil {
+		impl.logger.Errorw("error in updating preview", "id", preview.Id, "err", err)
+		return
+	}
+	impl.notify(config, preview, appName)
+}
+
+// notify posts preview status to callback url of config, failures are only logged
+func (impl *PreviewEnvironmentServiceImpl) notify(config *repository.PreviewEnvConfig, preview *repository.PreviewEnvironment, appName string) {
+	if len(config.CallbackUrl) == 0 {
+		return
+	}
+	if len(appName) == 0 {
+		appName = impl.getAppName(preview.AppId)
+	}
+	payload, err := json.Marshal(&PreviewCallbackPayload{
+		AppId:       preview.AppId,
+		AppName:     appName,
+		PrUniqueId:  preview.PrUniqueId,
+		Branch:      preview.SourceBranch,
+		HeadCommit:  preview.HeadCommit,
+		Environment: preview.Namespace,
+		Url:         preview.Url,
+		Status:      preview.Status,
+		Message:     preview.Message,
+	})
+	if err != nil {
+		return
+	}
+	resp, err := impl.httpClient.Post(config.CallbackUrl, "application/json", bytes.NewBuffer(payload))
+	if err != nil {
+		impl.logger.Errorw("error in posting preview callback", "url", config.CallbackUrl, "previewId", preview.Id, "err", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		impl.logger.Errorw("preview callback failed", "url", config.CallbackUrl, "previewId", preview.Id, "status", resp.StatusCode)
+	}
+}
+
+func (impl *PreviewEnvironmentServiceImpl) buildPreviewDto(preview *repository.PreviewEnvironment, clusterConfigs map[int]*util.ClusterConfig) (*PreviewEnvironmentDto, error) {
+	dto := &PreviewEnvironmentDto{
+		Id:              preview.Id,
+		AppId:           preview.AppId,
+		AppName:         impl.getAppName(preview.AppId),
+		PrUniqueId:      preview.PrUniqueId,
+		PrTitle:         preview.PrTitle,
+		SourceBranch:    preview.SourceBranch,
+		HeadCommit:      preview.HeadCommit,
+		EnvironmentId:   preview.EnvironmentId,
+		EnvironmentName: preview.Namespace,
+		PipelineId:      preview.PipelineId,
+		Namespace:       preview.Namespace,
+		Url:             preview.Url,
+		Status:          preview.Status,
+		Message:         preview.Message,
+		CiArtifactId:    preview.CiArtifactId,
+		CreatedOn:       preview.CreatedOn,
+		ExpiresOn:       preview.ExpiresOn,
+	}
+	if !preview.Active || preview.EnvironmentId == 0 {
+		return dto, nil
+	}
+	config, err := impl.previewEnvRepository.FindConfigById(preview.PreviewEnvConfigId)
+	if err != nil {
+		impl.logger.Errorw("error in getting preview env config", "id", preview.PreviewEnvConfigId, "err", err)
+		return nil, err
+	}
+	clusterConfig, err := impl.getClusterConfig(config.ClusterId, clusterConfigs)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := impl.K8sUtil.ListPods(preview.Namespace, clusterConfig)
+	if err != nil {
+		//cluster may be unreachable, listing still returns the preview without pod counts
+		impl.logger.Errorw("error in listing pods of preview", "namespace", preview.Namespace, "err", err)
+		return dto, nil
+	}
+	dto.PodCount = len(pods.Items)
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == util.Running {
+			dto.RunningPodCount++
+		}
+	}
+	return dto, nil
+}
+
+func (impl *PreviewEnvironmentServiceImpl) buildConfigDto(model *repository.PreviewEnvConfig) (*PreviewEnvConfigDto, error) {
+	dto := &PreviewEnvConfigDto{
+		Id:            model.Id,
+		AppId:         model.AppId,
+		CiPipelineId:  model.CiPipelineId,
+		SourceEnvId:   model.SourceEnvId,
+		ClusterId:     model.ClusterId,
+		EnvNamePrefix: model.EnvNamePrefix,
+		UrlTemplate:   model.UrlTemplate,
+		CallbackUrl:   model.CallbackUrl,
+		TtlHours:      model.TtlHours,
+	}
+	if len(model.OverrideValues) > 0 {
+		dto.OverrideValues = json.RawMessage(model.OverrideValues)
+	}
+	if len(model.Rewrites) > 0 {
+		err := json.Unmarshal([]byte(model.Rewrites), &dto.Rewrites)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dto, nil
+}
+
+func (impl *PreviewEnvironmentServiceImpl) getClusterConfig(clusterId int, clusterConfigs map[int]*util.ClusterConfig) (*util.ClusterConfig, error) {
+	if clusterConfig, ok := clusterConfigs[clusterId]; ok {
+		return clusterConfig, nil
+	}
+	clusterBean, err := impl.clusterService.FindById(clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	clusterConfig, err := impl.clusterService.GetClusterConfig(clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster config", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	clusterConfigs[clusterId] = clusterConfig
+	return clusterConfig, nil
+}
+
+func (impl *PreviewEnvironmentServiceImpl) getAppName(appId int) string {
+	application, err := impl.appRepository.FindById(appId)
+	if err != nil {
+		impl.logger.Errorw("error in getting app", "appId", appId, "err", err)
+		return ""
+	}
+	return application.AppName
+}
+
+func (impl *PreviewEnvironmentServiceImpl) buildAcdContext() (context.Context, error) {
+	acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		impl.logger.Errorw("error in getting acd token", "err", err)
+		return nil, err
+	}
+	return context.WithValue(context.Background(), "token", acdToken), nil
+}
+
+// isWorkflowOfCommit tells if ci workflow was triggered for head commit of the pr
+func isWorkflowOfCommit(workflow *pipelineConfig.WorkflowWithArtifact, prUniqueId string, headCommit string) bool {
+	for _, gitTrigger := range workflow.GitTriggers {
+		data := gitTrigger.WebhookData.Data
+		if data == nil || data[bean.WEBHOOK_SELECTOR_UNIQUE_ID_NAME] != prUniqueId {
+			continue
+		}
+		if len(headCommit) == 0 || data[bean.WEBHOOK_SELECTOR_SOURCE_CHECKOUT_NAME] == headCommit {
+			return true
+		}
+	}
+	return false
+}
+
+var previewEnvNameInvalidChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// previewEnvName builds a dns compliant env and namespace name, app name is trimmed to keep pr id within the
+// env name length limit
+func previewEnvName(prefix string, appName string, prUniqueId string) string {
+	if len(prefix) == 0 {
+		prefix = "pr"
+	}
+	suffix := "-" + strings.Trim(previewEnvNameInvalidChars.ReplaceAllString(strings.ToLower(prUniqueId), "-"), "-")
+	name := previewEnvNameInvalidChars.ReplaceAllString(strings.ToLower(prefix+"-"+appName), "-")
+	if maxLen := 50 - len(suffix); len(name) > maxLen {
+		name = name[:maxLen]
+	}
+	return strings.Trim(name, "-") + suffix
+}
+
+// renderPreviewTemplate replaces preview tokens in template, values are json escaped when template is json
+func renderPreviewTemplate(template string, tokens map[string]string, isJson bool) string {
+	for token, value := range tokens {
+		if isJson {
+			escaped, _ := json.Marshal(value)
+			value = string(escaped[1 : len(escaped)-1])
+		}
+		template = strings.ReplaceAll(template, token, value)
+	}
+	return template
+}
@@ -0,0 +1,67 @@
+package previewEnv
+
+import (
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/bean"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPreviewEnvName(t *testing.T) {
+	if name := previewEnvName("", "Payments_API", "42"); name != "pr-payments-api-42" {
+		t.Errorf("unexpected env name %s", name)
+	}
+	name := previewEnvName("preview", strings.Repeat("checkout-service", 5), "1234567")
+	if len(name) > 50 || !strings.HasSuffix(name, "-1234567") {
+		t.Errorf("unexpected env name %s", name)
+	}
+}
+
+func TestRenderPreviewTemplate(t *testing.T) {
+	tokens := map[string]string{PREVIEW_TOKEN_APP_NAME: "payments", PREVIEW_TOKEN_PR_ID: "42", PREVIEW_TOKEN_BRANCH: `fix/"quotes"`}
+	if url := renderPreviewTemplate("https://{{APP_NAME}}-{{PR_ID}}.preview.example.com", tokens, false); url != "https://payments-42.preview.example.com" {
+		t.Errorf("unexpected url %s", url)
+	}
+	if values := renderPreviewTemplate(`{"branch":"{{BRANCH}}"}`, tokens, true); values != `{"branch":"fix/\"quotes\""}` {
+		t.Errorf("unexpected values %s", values)
+	}
+}
+
+func TestIsWorkflowOfCommit(t *testing.T) {
+	workflow := &pipelineConfig.WorkflowWithArtifact{
+		GitTriggers: map[int]pipelineConfig.GitCommit{
+			1: {WebhookData: pipelineConfig.WebhookData{Data: map[string]string{
+				bean.WEBHOOK_SELECTOR_UNIQUE_ID_NAME:       "42",
+				bean.WEBHOOK_SELECTOR_SOURCE_CHECKOUT_NAME: "abc123",
+			}}},
+		},
+	}
+	if !isWorkflowOfCommit(workflow, "42", "abc123") {
+		t.Errorf("expected workflow to match pr head commit")
+	}
+	if isWorkflowOfCommit(workflow, "42", "def456") {
+		t.Errorf("expected workflow of older commit to not match")
+	}
+	if isWorkflowOfCommit(workflow, "43", "abc123") {
+		t.Errorf("expected workflow of other pr to not match")
+	}
+}
+
+type fakeCiPipelineRepository struct {
+	pipelineConfig.CiPipelineRepository
+}
+
+func (impl *fakeCiPipelineRepository) FindById(id int) (*pipelineConfig.CiPipeline, error) {
+	return &pipelineConfig.CiPipeline{Id: id, AppId: 1}, nil
+}
+
+func TestSaveConfigOfCiPipelineOfOtherApp(t *testing.T) {
+	impl := &PreviewEnvironmentServiceImpl{logger: zap.NewNop().Sugar(), ciPipelineRepository: &fakeCiPipelineRepository{}}
+	_, err := impl.SaveConfig(&PreviewEnvConfigDto{AppId: 2, CiPipelineId: 10, SourceEnvId: 3})
+	if apiErr, ok := err.(*util.ApiError); !ok || apiErr.HttpStatusCode != http.StatusBadRequest {
+		t.Errorf("expected bad request for ci pipeline of other app, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package previewEnv
+
+import (
+	"encoding/json"
+	"github.com/devtron-labs/devtron/pkg/appClone"
+	"time"
+)
+
+const (
+	PREVIEW_ENV_STATUS_BUILDING = "BUILDING"
+	PREVIEW_ENV_STATUS_DEPLOYED = "DEPLOYED"
+	PREVIEW_ENV_STATUS_FAILED   = "FAILED"
+	PREVIEW_ENV_STATUS_DELETED  = "DELETED"
+)
+
+// webhook data keys and pull request states sent by git sensor for PR events
+const (
+	WEBHOOK_SELECTOR_TITLE_NAME = "title"
+	WEBHOOK_SELECTOR_STATE_NAME = "state"
+)
+
+var closedPrStates = map[string]bool{"closed": true, "merged": true, "declined": true}
+
+// tokens usable in url template, rewrites and override values of a preview config
+const (
+	PREVIEW_TOKEN_APP_NAME  = "{{APP_NAME}}"
+	PREVIEW_TOKEN_PR_ID     = "{{PR_ID}}"
+	PREVIEW_TOKEN_BRANCH    = "{{BRANCH}}"
+	PREVIEW_TOKEN_ENV_NAME  = "{{ENV_NAME}}"
+	PREVIEW_TOKEN_NAMESPACE = "{{NAMESPACE}}"
+)
+
+type PreviewEnvConfigDto struct {
+	Id             int                         `json:"id"`
+	AppId          int                         `json:"appId" validate:"required"`
+	CiPipelineId   int                         `json:"ciPipelineId" validate:"required"`
+	SourceEnvId    int                         `json:"sourceEnvId" validate:"required"`
+	ClusterId      int                         `json:"clusterId" validate:"required"`
+	EnvNamePrefix  string                      `json:"envNamePrefix,omitempty" validate:"max=20"`
+	UrlTemplate    string                      `json:"urlTemplate,omitempty"`
+	CallbackUrl    string                      `json:"callbackUrl,omitempty"`
+	TtlHours       int                         `json:"ttlHours" validate:"min=1"`
+	OverrideValues json.RawMessage             `json:"overrideValues,omitempty"`
+	Rewrites       []*appClone.EnvCloneRewrite `json:"rewrites,omitempty" validate:"dive"`
+	UserId         int32                       `json:"-"`
+}
+
+type PreviewEnvironmentDto struct {
+	Id              int       `json:"id"`
+	AppId           int       `json:"appId"`
+	AppName         string    `json:"appName,omitempty"`
+	PrUniqueId      string    `json:"prUniqueId"`
+	PrTitle         string    `json:"prTitle,omitempty"`
+	SourceBranch    string    `json:"sourceBranch,omitempty"`
+	HeadCommit      string    `json:"headCommit,omitempty"`
+	EnvironmentId   int       `json:"environmentId"`
+	EnvironmentName string    `json:"environmentName,omitempty"`
+	PipelineId      int       `json:"pipelineId"`
+	Namespace       string    `json:"namespace"`
+	Url             string    `json:"url,omitempty"`
+	Status          string    `json:"status"`
+	Message         string    `json:"message,omitempty"`
+	CiArtifactId    int       `json:"ciArtifactId,omitempty"`
+	PodCount        int       `json:"podCount"`
+	RunningPodCount int       `json:"runningPodCount"`
+	CreatedOn       time.Time `json:"createdOn"`
+	ExpiresOn       time.Time `json:"expiresOn"`
+}
+
+// PreviewCallbackPayload is posted to callback url of the config whenever a preview is deployed, fails or is deleted
+type PreviewCallbackPayload struct {
+	AppId       int    `json:"appId"`
+	AppName     string `json:"appName"`
+	PrUniqueId  string `json:"prUniqueId"`
+	Branch      string `json:"branch"`
+	HeadCommit  string `json:"headCommit"`
+	Environment string `json:"environment"`
+	Url         string `json:"url"`
+	Status      string `json:"status"`
+	Message     string `json:"message,omitempty"`
+}
+
+type PreviewEnvConfig struct {
+	SyncIntervalMins int `env:"PREVIEW_ENV_SYNC_INTERVAL_MINS" envDefault:"2"`
+	CallbackTimeout  int `env:"PREVIEW_ENV_CALLBACK_TIMEOUT_SECS" envDefault:"10"`
+}
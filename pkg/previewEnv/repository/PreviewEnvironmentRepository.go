@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"time"
+)
+
+type PreviewEnvConfig struct {
+	tableName      struct{} `sql:"preview_env_config" pg:",discard_unknown_columns"`
+	Id             int      `sql:"id,pk"`
+	AppId          int      `sql:"app_id,notnull"`
+	CiPipelineId   int      `sql:"ci_pipeline_id,notnull"`
+	SourceEnvId    int      `sql:"source_env_id,notnull"`
+	ClusterId      int      `sql:"cluster_id,notnull"`
+	EnvNamePrefix  string   `sql:"env_name_prefix"`
+	UrlTemplate    string   `sql:"url_template"`
+	CallbackUrl    string   `sql:"callback_url"`
+	TtlHours       int      `sql:"ttl_hours,notnull"`
+	OverrideValues string   `sql:"override_values"`
+	Rewrites       string   `sql:"rewrites"`
+	Active         bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type PreviewEnvironment struct {
+	tableName          struct{}  `sql:"preview_environment" pg:",discard_unknown_columns"`
+	Id                 int       `sql:"id,pk"`
+	PreviewEnvConfigId int       `sql:"preview_env_config_id,notnull"`
+	AppId              int       `sql:"app_id,notnull"`
+	PrUniqueId         string    `sql:"pr_unique_id,notnull"`
+	PrTitle            string    `sql:"pr_title"`
+	SourceBranch       string    `sql:"source_branch"`
+	HeadCommit         string    `sql:"head_commit"`
+	EnvironmentId      int       `sql:"environment_id"`
+	PipelineId         int       `sql:"pipeline_id"`
+	Namespace          string    `sql:"namespace"`
+	Url                string    `sql:"url"`
+	Status             string    `sql:"status,notnull"`
+	Message            string    `sql:"message"`
+	CiArtifactId       int       `sql:"ci_artifact_id"`
+	ExpiresOn          time.Time `sql:"expires_on,notnull"`
+	Active             bool      `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type PreviewEnvironmentRepository interface {
+	SaveConfig(model *PreviewEnvConfig) error
+	UpdateConfig(model *PreviewEnvConfig) error
+	FindConfigById(id int) (*PreviewEnvConfig, error)
+	FindConfigByAppId(appId int) (*PreviewEnvConfig, error)
+	FindConfigsByCiPipelineId(ciPipelineId int) ([]*PreviewEnvConfig, error)
+	Save(model *PreviewEnvironment) error
+	Update(model *PreviewEnvironment) error
+	FindById(id int) (*PreviewEnvironment, error)
+	FindActiveByConfigIdAndPr(configId int, prUniqueId string) (*PreviewEnvironment, error)
+	FindAllActive() ([]*PreviewEnvironment, error)
+	FindActiveByAppId(appId int) ([]*PreviewEnvironment, error)
+	FindActiveByConfigId(configId int) ([]*PreviewEnvironment, error)
+}
+
+type PreviewEnvironmentRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewPreviewEnvironmentRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *PreviewEnvironmentRepositoryImpl {
+	return &PreviewEnvironmentRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) SaveConfig(model *PreviewEnvConfig) error {
+	return repo.dbConnection.Insert(model)
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) UpdateConfig(model *PreviewEnvConfig) error {
+	return repo.dbConnection.Update(model)
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) FindConfigById(id int) (*PreviewEnvConfig, error) {
+	model := &PreviewEnvConfig{}
+	err := repo.dbConnection.Model(model).
+		Where("id = ?", id).
+		Select()
+	return model, err
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) FindConfigByAppId(appId int) (*PreviewEnvConfig, error) {
+	model := &PreviewEnvConfig{}
+	err := repo.dbConnection.Model(model).
+		Where("app_id = ?", appId).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) FindConfigsByCiPipelineId(ciPipelineId int) ([]*PreviewEnvConfig, error) {
+	var models []*PreviewEnvConfig
+	err := repo.dbConnection.Model(&models).
+		Where("ci_pipeline_id = ?", ciPipelineId).
+		Where("active = ?", true).
+		Select()
+	return models, err
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) Save(model *PreviewEnvironment) error {
+	return repo.dbConnection.Insert(model)
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) Update(model *PreviewEnvironment) error {
+	return repo.dbConnection.Update(model)
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) FindById(id int) (*PreviewEnvironment, error) {
+	model := &PreviewEnvironment{}
+	err := repo.dbConnection.Model(model).
+		Where("id = ?", id).
+		Select()
+	return model, err
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) FindActiveByConfigIdAndPr(configId int, prUniqueId string) (*PreviewEnvironment, error) {
+	model := &PreviewEnvironment{}
+	err := repo.dbConnection.Model(model).
+		Where("preview_env_config_id = ?", configId).
+		Where("pr_unique_id = ?", prUniqueId).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) FindAllActive() ([]*PreviewEnvironment, error) {
+	var models []*PreviewEnvironment
+	err := repo.dbConnection.Model(&models).
+		Where("active = ?", true).
+		Order("id").
+		Select()
+	return models, err
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) FindActiveByAppId(appId int) ([]*PreviewEnvironment, error) {
+	var models []*PreviewEnvironment
+	err := repo.dbConnection.Model(&models).
+		Where("app_id = ?", appId).
+		Where("active = ?", true).
+		Order("id").
+		Select()
+	return models, err
+}
+
+func (repo PreviewEnvironmentRepositoryImpl) FindActiveByConfigId(configId int) ([]*PreviewEnvironment, error) {
+	var models []*PreviewEnvironment
+	err := repo.dbConnection.Model(&models).
+		Where("preview_env_config_id = ?", configId).
+		Where("active = ?", true).
+		Select()
+	return models, err
+}
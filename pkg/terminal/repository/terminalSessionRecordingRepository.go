@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"time"
+)
+
+// TerminalSessionRecording is one recorded pod exec or cluster terminal session, FilePath is local path when
+// StorageType is LOCAL and blob key otherwise
+type TerminalSessionRecording struct {
+	tableName     struct{}  `sql:"terminal_session_recording" pg:",discard_unknown_columns"`
+	Id            int       `sql:"id,pk"`
+	SessionId     string    `sql:"session_id,notnull"`
+	ClusterId     int       `sql:"cluster_id"`
+	EnvironmentId int       `sql:"environment_id"`
+	AppId         int       `sql:"app_id"`
+	HelmAppId     string    `sql:"helm_app_id"`
+	Namespace     string    `sql:"namespace"`
+	PodName       string    `sql:"pod_name"`
+	ContainerName string    `sql:"container_name"`
+	UserId        int32     `sql:"user_id"`
+	StorageType   string    `sql:"storage_type,notnull"`
+	FilePath      string    `sql:"file_path,notnull"`
+	Status        string    `sql:"status,notnull"`
+	Size          int64     `sql:"size"`
+	StartedOn     time.Time `sql:"started_on,notnull"`
+	EndedOn       time.Time `sql:"ended_on"`
+}
+
+// TerminalSessionRecordingSetting forces recording of sessions in a cluster, EnvironmentId 0 applies to every
+// session of the cluster
+type TerminalSessionRecordingSetting struct {
+	tableName     struct{} `sql:"terminal_session_recording_setting" pg:",discard_unknown_columns"`
+	Id            int      `sql:"id,pk"`
+	ClusterId     int      `sql:"cluster_id,notnull"`
+	EnvironmentId int      `sql:"environment_id"`
+	Active        bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type TerminalSessionRecordingFilter struct {
+	ClusterId int
+	UserId    int32
+	From      time.Time
+	To        time.Time
+	Offset    int
+	Size      int
+}
+
+type TerminalSessionRecordingRepository interface {
+	Save(model *TerminalSessionRecording) error
+	Update(model *TerminalSessionRecording) error
+	FindById(id int) (*TerminalSessionRecording, error)
+	FindByFilter(filter *TerminalSessionRecordingFilter) ([]*TerminalSessionRecording, int, error)
+	FindStartedBefore(startedOn time.Time) ([]*TerminalSessionRecording, error)
+	DeleteByIds(ids []int) error
+	SaveSetting(model *TerminalSessionRecordingSetting) error
+	UpdateSetting(model *TerminalSessionRecordingSetting) error
+	FindActiveSettingById(id int) (*TerminalSessionRecordingSetting, error)
+	FindAllActiveSettings() ([]*TerminalSessionRecordingSetting, error)
+	FindActiveSettingsByClusterId(clusterId int) ([]*TerminalSessionRecordingSetting, error)
+}
+
+type TerminalSessionRecordingRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewTerminalSessionRecordingRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *TerminalSessionRecordingRepositoryImpl {
+	return &TerminalSessionRecordingRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (repo TerminalSessionRecordingRepositoryImpl) Save(model *TerminalSessionRecording) error {
+	return repo.dbConnection.Insert(model)
+}
+
+func (repo TerminalSessionRecordingRepositoryImpl) Update(model *TerminalSessionRecording) error {
+	return repo.dbConnection.Update(model)
+}
+
+func (repo TerminalSessionRecordingRepositoryImpl) FindById(id int) (*TerminalSessionRecording, error) {
+	model := &TerminalSessionRecording{}
+	err := repo.dbConnection.Model(model).
+		Where("id = ?", id).
+		Select()
+	return model, err
+}
+
+// FindByFilter returns a page of recordings matching non empty filter fields, latest first, along with total matching count
+func (repo TerminalSessionRecordingRepositoryImpl) FindByFilter(filter *TerminalSessionRecordingFilter) ([]*TerminalSessionRecording, int, error) {
+	var models []*TerminalSessionRecording
+	query := repo.dbConnection.Model(&models)
+	if filter.ClusterId > 0 {
+		query = query.Where("cluster_id = ?", filter.ClusterId)
+	}
+	if filter.UserId > 0 {
+		query = query.Where("user_id = ?", filter.UserId)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("started_on >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("started_on <= ?", filter.To)
+	}
+	count, err := query.
+		Order("started_on DESC").
+		Order("id DESC").
+		Offset(filter.Offset).
+		Limit(filter.Size).
+		SelectAndCount()
+	return models, count, err
+}
+
+func (repo TerminalSessionRecordingRepositoryImpl) FindStartedBefore(startedOn time.Time) ([]*TerminalSessionRecording, error) {
+	var models []*TerminalSessionRecording
+	err := repo.dbConnection.Model(&models).
+		Where("started_on < ?", startedOn).
+		Select()
+	return models, err
+}
+
+func (repo TerminalSessionRecordingRepositoryImpl) DeleteByIds(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := repo.dbConnection.Model((*TerminalSessionRecording)(nil)).
+		Where("id in (?)", pg.In(ids)).
+		Delete()
+	return err
+}
+
+func (repo TerminalSessionRecordingRepositoryImpl) SaveSetting(model *TerminalSessionRecordingSetting) error {
+	return repo.dbConnection.Insert(model)
+}
+
+func (repo TerminalSessionRecordingRepositoryImpl) UpdateSetting(model *TerminalSessionRecordingSetting) error {
+	return repo.dbConnection.Update(model)
+}
+
+func (repo TerminalSessionRecordingRepositoryImpl) FindActiveSettingById(id int) (*TerminalSessionRecordingSetting, error) {
+	model := &TerminalSessionRecordingSetting{}
+	err := repo.dbConnection.Model(model).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo TerminalSessionRecordingRepositoryImpl) FindAllActiveSettings() ([]*TerminalSessionRecordingSetting, error) {
+	var models []*TerminalSessionRecordingSetting
+	err := repo.dbConnection.Model(&models).
+		Where("active = ?", true).
+		Order("id ASC").
+		Select()
+	return models, err
+}
+
+func (repo TerminalSessionRecordingRepositoryImpl) FindActiveSettingsByClusterId(clusterId int) ([]*TerminalSessionRecordingSetting, error) {
+	var models []*TerminalSessionRecordingSetting
+	err := repo.dbConnection.Model(&models).
+		Where("cluster_id = ?", clusterId).
+		Where("active = ?", true).
+		Select()
+	return models, err
+}
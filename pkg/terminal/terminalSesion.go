@@ -26,6 +26,7 @@ import (
 	"log"
 	"net/http"
 	"sync"
+	"time"
 
 	"gopkg.in/igm/sockjs-go.v3/sockjs"
 	v1 "k8s.io/api/core/v1"
@@ -51,6 +52,8 @@ type TerminalSession struct {
 	sockJSSession sockjs.Session
 	sizeChan      chan remotecommand.TerminalSize
 	doneChan      chan struct{}
+	// recorder is nil when session is not recorded
+	recorder *TerminalSessionRecorder
 }
 
 // TerminalMessage is the messaging protocol between ShellController and TerminalSession.
@@ -94,8 +97,14 @@ func (t TerminalSession) Read(p []byte) (int, error) {
 
 	switch msg.Op {
 	case "stdin":
+		if t.recorder != nil {
+			t.recorder.RecordInput(msg.Data)
+		}
 		return copy(p, msg.Data), nil
 	case "resize":
+		if t.recorder != nil {
+			t.recorder.RecordResize(msg.Cols, msg.Rows)
+		}
 		t.sizeChan <- remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}
 		return 0, nil
 	default:
@@ -106,6 +115,9 @@ func (t TerminalSession) Read(p []byte) (int, error) {
 // Write handles process->pty stdout
 // Called from remotecommand whenever there is any output
 func (t TerminalSession) Write(p []byte) (int, error) {
+	if t.recorder != nil {
+		t.recorder.RecordOutput(string(p))
+	}
 	msg, err := json.Marshal(TerminalMessage{
 		Op:   "stdout",
 		Data: string(p),
@@ -175,6 +187,13 @@ func (sm *SessionMap) Close(sessionId string, status uint32, reason string) {
 
 }
 
+// Delete removes a session which was never bound to a SockJS connection
+func (sm *SessionMap) Delete(sessionId string) {
+	sm.Lock.Lock()
+	defer sm.Lock.Unlock()
+	delete(sm.Sessions, sessionId)
+}
+
 var terminalSessions = SessionMap{Sessions: make(map[string]TerminalSession)}
 
 // handleTerminalSession is Called by net/http for any new /api/sockjs connections
@@ -260,6 +279,37 @@ func startProcess(k8sClient kubernetes.Interface, cfg *rest.Config,
 	return nil
 }
 
+// startAttachProcess attaches ptyHandler to the running process of container specified in request, containers
+// without shell (e.g. ephemeral debug containers started with their own entrypoint) can only be reached this way
+func startAttachProcess(k8sClient kubernetes.Interface, cfg *rest.Config, ptyHandler PtyHandler, sessionRequest *TerminalSessionRequest) error {
+	req := k8sClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(sessionRequest.PodName).
+		Namespace(sessionRequest.Namespace).
+		SubResource("attach")
+
+	req.VersionedParams(&v1.PodAttachOptions{
+		Container: sessionRequest.ContainerName,
+		Stdin:     true,
+		Stdout:    true,
+		Stderr:    true,
+		TTY:       true,
+	}, scheme.ParameterCodec)
+
+	attach, err := remotecommand.NewSPDYExecutor(cfg, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	return attach.Stream(remotecommand.StreamOptions{
+		Stdin:             ptyHandler,
+		Stdout:            ptyHandler,
+		Stderr:            ptyHandler,
+		TerminalSizeQueue: ptyHandler,
+		Tty:               true,
+	})
+}
+
 // genTerminalSessionId generates a random session ID string. The format is not really interesting.
 // This ID is used to identify the session when the client opens the SockJS connection.
 // Not the same as the SockJS session id! We can't use that as that is generated
@@ -296,20 +346,42 @@ type TerminalSessionRequest struct {
 	AppId         int
 	//ClusterId is optional
 	ClusterId int
+	//UserId is user who opened the session, used for recording
+	UserId int32
+	//Attach connects session to the main process of container instead of exec-ing a shell, used for ephemeral containers
+	Attach bool
 }
 
+// terminalSessionBindTimeout is how long a session waits for the client to bind it
+var terminalSessionBindTimeout = 5 * time.Minute
+
 // WaitForTerminal is called from apihandler.handleAttach as a goroutine
 // Waits for the SockJS connection to be opened by the client the session to be bound in handleTerminalSession
 func WaitForTerminal(k8sClient kubernetes.Interface, cfg *rest.Config, request *TerminalSessionRequest) {
 
 	select {
+	case <-time.After(terminalSessionBindTimeout):
+		// client never bound the session, drop it and finish its recording
+		session := terminalSessions.Get(request.SessionId)
+		terminalSessions.Delete(request.SessionId)
+		if session.recorder != nil {
+			session.recorder.Close()
+		}
 	case <-terminalSessions.Get(request.SessionId).bound:
 		close(terminalSessions.Get(request.SessionId).bound)
 
+		session := terminalSessions.Get(request.SessionId)
+		if session.recorder != nil {
+			defer session.recorder.Close()
+			showRecordingBanner(session)
+		}
+
 		var err error
 		validShells := []string{"bash", "sh", "powershell", "cmd"}
 
-		if isValidShell(validShells, request.Shell) {
+		if request.Attach {
+			err = startAttachProcess(k8sClient, cfg, terminalSessions.Get(request.SessionId), request)
+		} else if isValidShell(validShells, request.Shell) {
 			cmd := []string{request.Shell}
 
 			err = startProcess(k8sClient, cfg, cmd, terminalSessions.Get(request.SessionId), request)
@@ -333,6 +405,16 @@ func WaitForTerminal(k8sClient kubernetes.Interface, cfg *rest.Config, request *
 	}
 }
 
+// showRecordingBanner lets user know that session is recorded before process is started
+func showRecordingBanner(session TerminalSession) {
+	if err := session.Toast("This terminal session is being recorded"); err != nil {
+		log.Println(err)
+	}
+	if _, err := session.Write([]byte(RECORDING_BANNER)); err != nil {
+		log.Println(err)
+	}
+}
+
 type TerminalSessionHandler interface {
 	GetTerminalSession(req *TerminalSessionRequest) (statusCode int, message *TerminalMessage, err error)
 	Close(sessionId string, statusCode uint32, msg string)
@@ -340,17 +422,19 @@ type TerminalSessionHandler interface {
 }
 
 type TerminalSessionHandlerImpl struct {
-	environmentService cluster.EnvironmentService
-	clusterService     cluster.ClusterService
-	logger             *zap.SugaredLogger
+	environmentService              cluster.EnvironmentService
+	clusterService                  cluster.ClusterService
+	logger                          *zap.SugaredLogger
+	terminalSessionRecordingService TerminalSessionRecordingService
 }
 
 func NewTerminalSessionHandlerImpl(environmentService cluster.EnvironmentService, clusterService cluster.ClusterService,
-	logger *zap.SugaredLogger) *TerminalSessionHandlerImpl {
+	logger *zap.SugaredLogger, terminalSessionRecordingService TerminalSessionRecordingService) *TerminalSessionHandlerImpl {
 	return &TerminalSessionHandlerImpl{
-		environmentService: environmentService,
-		clusterService:     clusterService,
-		logger:             logger,
+		environmentService:              environmentService,
+		clusterService:                  clusterService,
+		logger:                          logger,
+		terminalSessionRecordingService: terminalSessionRecordingService,
 	}
 }
 
@@ -382,16 +466,23 @@ func (impl *TerminalSessionHandlerImpl) GetTerminalSession(req *TerminalSessionR
 		return statusCode, nil, err
 	}
 	req.SessionId = sessionID
-	terminalSessions.Set(sessionID, TerminalSession{
-		id:       sessionID,
-		bound:    make(chan error),
-		sizeChan: make(chan remotecommand.TerminalSize),
-	})
 	config, client, err := impl.getClientConfig(req)
 	if err != nil {
 		impl.logger.Errorw("error in fetching config", "err", err)
 		return http.StatusInternalServerError, nil, err
 	}
+	// session is not started if it has to be recorded and recording can not be started
+	recorder, err := impl.terminalSessionRecordingService.StartRecording(req)
+	if err != nil {
+		impl.logger.Errorw("error in starting terminal session recording", "err", err, "clusterId", req.ClusterId, "podName", req.PodName)
+		return http.StatusInternalServerError, nil, err
+	}
+	terminalSessions.Set(sessionID, TerminalSession{
+		id:       sessionID,
+		bound:    make(chan error, 1),
+		sizeChan: make(chan remotecommand.TerminalSize),
+		recorder: recorder,
+	})
 	go WaitForTerminal(client, config, req)
 	return http.StatusOK, &TerminalMessage{SessionID: sessionID}, nil
 }
@@ -411,6 +502,7 @@ func (impl *TerminalSessionHandlerImpl) getClientConfig(req *TerminalSessionRequ
 			impl.logger.Errorw("error in fetching cluster detail", "envId", req.EnvironmentId, "err", err)
 			return nil, nil, err
 		}
+		req.ClusterId = clusterBean.Id
 	} else {
 		return nil, nil, fmt.Errorf("not able to find cluster-config")
 	}
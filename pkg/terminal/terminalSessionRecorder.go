@@ -0,0 +1,177 @@
+package terminal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	ASCIICAST_VERSION      = 2
+	ASCIICAST_EVENT_OUTPUT = "o"
+	ASCIICAST_EVENT_INPUT  = "i"
+	ASCIICAST_EVENT_RESIZE = "r"
+
+	defaultRecordingWidth  = 80
+	defaultRecordingHeight = 24
+)
+
+type AsciicastHeader struct {
+	Version   int    `json:"version"`
+	Width     uint16 `json:"width"`
+	Height    uint16 `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+	Title     string `json:"title,omitempty"`
+}
+
+// AsciicastEvent is one line of asciicast file, Time is seconds elapsed since start of session. Data of resize
+// event is "{cols}x{rows}".
+type AsciicastEvent struct {
+	Time float64 `json:"time"`
+	Type string  `json:"type"`
+	Data string  `json:"data"`
+}
+
+// TerminalSessionRecorder writes input and output of a terminal session with timing in asciicast v2 format.
+// Header is written lazily so that size of first resize sent by client is used as terminal size.
+type TerminalSessionRecorder struct {
+	lock          sync.Mutex
+	writer        io.WriteCloser
+	title         string
+	startedOn     time.Time
+	headerWritten bool
+	closed        bool
+	err           error
+	onClose       func(err error)
+}
+
+// NewTerminalSessionRecorder records in writer, onClose is called once writer is closed with first write error if any
+func NewTerminalSessionRecorder(writer io.WriteCloser, title string, onClose func(err error)) *TerminalSessionRecorder {
+	return &TerminalSessionRecorder{
+		writer:    writer,
+		title:     title,
+		startedOn: time.Now(),
+		onClose:   onClose,
+	}
+}
+
+func (r *TerminalSessionRecorder) RecordOutput(data string) {
+	r.record(ASCIICAST_EVENT_OUTPUT, data)
+}
+
+func (r *TerminalSessionRecorder) RecordInput(data string) {
+	r.record(ASCIICAST_EVENT_INPUT, data)
+}
+
+func (r *TerminalSessionRecorder) RecordResize(cols uint16, rows uint16) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.headerWritten {
+		r.writeHeader(cols, rows)
+		return
+	}
+	r.writeEvent(ASCIICAST_EVENT_RESIZE, fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// Close closes writer and calls onClose, recording is ignored after close
+func (r *TerminalSessionRecorder) Close() {
+	r.lock.Lock()
+	if r.closed {
+		r.lock.Unlock()
+		return
+	}
+	if !r.headerWritten {
+		r.writeHeader(defaultRecordingWidth, defaultRecordingHeight)
+	}
+	r.closed = true
+	if err := r.writer.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	err := r.err
+	r.lock.Unlock()
+	if r.onClose != nil {
+		r.onClose(err)
+	}
+}
+
+func (r *TerminalSessionRecorder) record(eventType string, data string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.headerWritten {
+		r.writeHeader(defaultRecordingWidth, defaultRecordingHeight)
+	}
+	r.writeEvent(eventType, data)
+}
+
+func (r *TerminalSessionRecorder) writeHeader(width uint16, height uint16) {
+	r.headerWritten = true
+	r.writeLine(AsciicastHeader{
+		Version:   ASCIICAST_VERSION,
+		Width:     width,
+		Height:    height,
+		Timestamp: r.startedOn.Unix(),
+		Title:     r.title,
+	})
+}
+
+func (r *TerminalSessionRecorder) writeEvent(eventType string, data string) {
+	r.writeLine([]interface{}{time.Since(r.startedOn).Seconds(), eventType, data})
+}
+
+// writeLine keeps first error and stops writing after it, a broken recording must not break the session
+func (r *TerminalSessionRecorder) writeLine(line interface{}) {
+	if r.closed || r.err != nil {
+		return
+	}
+	lineBytes, err := json.Marshal(line)
+	if err != nil {
+		r.err = err
+		return
+	}
+	if _, err = r.writer.Write(append(lineBytes, '\n')); err != nil {
+		r.err = err
+	}
+}
+
+// ParseAsciicast reads header and events of an asciicast v2 recording
+func ParseAsciicast(reader io.Reader) (*AsciicastHeader, []*AsciicastEvent, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("empty recording")
+	}
+	header := &AsciicastHeader{}
+	if err := json.Unmarshal(scanner.Bytes(), header); err != nil {
+		return nil, nil, fmt.Errorf("invalid recording header, err: %v", err)
+	}
+	events := make([]*AsciicastEvent, 0)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var fields []interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &fields); err != nil {
+			return nil, nil, fmt.Errorf("invalid recording event, err: %v", err)
+		}
+		if len(fields) != 3 {
+			return nil, nil, fmt.Errorf("invalid recording event %s", scanner.Text())
+		}
+		eventTime, okTime := fields[0].(float64)
+		eventType, okType := fields[1].(string)
+		data, okData := fields[2].(string)
+		if !okTime || !okType || !okData {
+			return nil, nil, fmt.Errorf("invalid recording event %s", scanner.Text())
+		}
+		events = append(events, &AsciicastEvent{Time: eventTime, Type: eventType, Data: data})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return header, events, nil
+}
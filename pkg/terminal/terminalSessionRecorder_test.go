@@ -0,0 +1,103 @@
+package terminal
+
+import (
+	"bytes"
+	"github.com/devtron-labs/devtron/pkg/terminal/repository"
+	"testing"
+	"time"
+)
+
+type nopWriteCloser struct {
+	bytes.Buffer
+}
+
+func (w *nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestTerminalSessionRecorder(t *testing.T) {
+	writer := &nopWriteCloser{}
+	closeCalled := false
+	recorder := NewTerminalSessionRecorder(writer, "prod/api-0/api", func(err error) {
+		closeCalled = true
+		if err != nil {
+			t.Errorf("unexpected err %v", err)
+		}
+	})
+	recorder.RecordResize(120, 40)
+	recorder.RecordInput("ls\r")
+	recorder.RecordOutput("bin  etc\r\n")
+	recorder.RecordResize(100, 30)
+	recorder.Close()
+	recorder.RecordOutput("after close")
+	recorder.Close()
+	if !closeCalled {
+		t.Errorf("expected onClose to be called")
+	}
+
+	header, events, err := ParseAsciicast(&writer.Buffer)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if header.Version != ASCIICAST_VERSION || header.Width != 120 || header.Height != 40 || header.Title != "prod/api-0/api" {
+		t.Errorf("unexpected header %+v", header)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if events[0].Type != ASCIICAST_EVENT_INPUT || events[0].Data != "ls\r" || events[1].Type != ASCIICAST_EVENT_OUTPUT || events[1].Data != "bin  etc\r\n" {
+		t.Errorf("unexpected events %+v %+v", events[0], events[1])
+	}
+	if events[2].Type != ASCIICAST_EVENT_RESIZE || events[2].Data != "100x30" {
+		t.Errorf("unexpected resize event %+v", events[2])
+	}
+}
+
+func TestTerminalSessionRecorderWithoutEvents(t *testing.T) {
+	writer := &nopWriteCloser{}
+	NewTerminalSessionRecorder(writer, "", nil).Close()
+	header, events, err := ParseAsciicast(&writer.Buffer)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if header.Width != defaultRecordingWidth || header.Height != defaultRecordingHeight || len(events) != 0 {
+		t.Errorf("unexpected recording %+v %v", header, events)
+	}
+}
+
+func TestIsRecordingForced(t *testing.T) {
+	envSettings := []*repository.TerminalSessionRecordingSetting{{ClusterId: 1, EnvironmentId: 5}}
+	if !isRecordingForced(envSettings, 5) || isRecordingForced(envSettings, 6) || isRecordingForced(envSettings, 0) {
+		t.Errorf("environment setting should only force sessions of that environment")
+	}
+	clusterSettings := append(envSettings, &repository.TerminalSessionRecordingSetting{ClusterId: 1})
+	if !isRecordingForced(clusterSettings, 6) || !isRecordingForced(clusterSettings, 0) {
+		t.Errorf("cluster setting should force every session of cluster")
+	}
+	if isRecordingForced(nil, 5) {
+		t.Errorf("recording should not be forced without settings")
+	}
+}
+
+func TestWaitForTerminalClosesRecorderOfUnboundSession(t *testing.T) {
+	bindTimeout := terminalSessionBindTimeout
+	terminalSessionBindTimeout = 10 * time.Millisecond
+	defer func() { terminalSessionBindTimeout = bindTimeout }()
+
+	closeCalled := false
+	recorder := NewTerminalSessionRecorder(&nopWriteCloser{}, "", func(err error) {
+		closeCalled = true
+	})
+	terminalSessions.Set("unbound", TerminalSession{
+		id:       "unbound",
+		bound:    make(chan error, 1),
+		recorder: recorder,
+	})
+	WaitForTerminal(nil, nil, &TerminalSessionRequest{SessionId: "unbound"})
+	if !closeCalled {
+		t.Errorf("expected recorder of unbound session to be closed")
+	}
+	if session := terminalSessions.Get("unbound"); session.id != "" {
+		t.Errorf("expected unbound session to be removed")
+	}
+}
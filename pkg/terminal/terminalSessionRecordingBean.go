@@ -0,0 +1,73 @@
+package terminal
+
+import (
+	blob_storage "github.com/devtron-labs/common-lib/blob-storage"
+	"time"
+)
+
+const (
+	RECORDING_STATUS_RECORDING = "Recording"
+	RECORDING_STATUS_COMPLETED = "Completed"
+	RECORDING_STATUS_FAILED    = "Failed"
+
+	RECORDING_STORAGE_LOCAL = "LOCAL"
+
+	RECORDING_FILE_EXTENSION = ".cast"
+	// RECORDING_BANNER is written to terminal before process is started so that user knows session is recorded
+	RECORDING_BANNER = "\r\n\x1b[1;33m*** This terminal session is being recorded ***\x1b[0m\r\n\r\n"
+)
+
+type TerminalSessionRecordingConfig struct {
+	// RecordAllSessions records every session, otherwise only sessions of clusters/environments with active setting
+	RecordAllSessions   bool   `env:"TERMINAL_SESSION_RECORDING_ENABLED" envDefault:"false"`
+	LocalDir            string `env:"TERMINAL_SESSION_RECORDING_DIR" envDefault:"/tmp/terminal-session-recordings"`
+	RetentionDays       int    `env:"TERMINAL_SESSION_RECORDING_RETENTION_DAYS" envDefault:"90"`
+	CleanupCronTimeMins int    `env:"TERMINAL_SESSION_RECORDING_CLEANUP_CRON_TIME" envDefault:"60"`
+	// recordings are uploaded to blob storage once session ends when enabled, local file is kept when upload fails
+	BlobStorageEnabled            bool                         `env:"TERMINAL_SESSION_RECORDING_BLOB_STORAGE_ENABLED" envDefault:"false"`
+	BlobStorageBucket             string                       `env:"TERMINAL_SESSION_RECORDING_BUCKET"`
+	BlobStorageRegion             string                       `env:"TERMINAL_SESSION_RECORDING_BUCKET_REGION"`
+	CloudProvider                 blob_storage.BlobStorageType `env:"BLOB_STORAGE_PROVIDER" envDefault:"S3"`
+	BlobStorageS3AccessKey        string                       `env:"BLOB_STORAGE_S3_ACCESS_KEY"`
+	BlobStorageS3SecretKey        string                       `env:"BLOB_STORAGE_S3_SECRET_KEY"`
+	BlobStorageS3Endpoint         string                       `env:"BLOB_STORAGE_S3_ENDPOINT"`
+	BlobStorageS3EndpointInsecure bool                         `env:"BLOB_STORAGE_S3_ENDPOINT_INSECURE" envDefault:"false"`
+	BlobStorageGcpCredentialJson  string                       `env:"BLOB_STORAGE_GCP_CREDENTIALS_JSON"`
+	AzureAccountName              string                       `env:"AZURE_ACCOUNT_NAME"`
+	AzureAccountKey               string                       `env:"AZURE_ACCOUNT_KEY"`
+}
+
+type TerminalSessionRecordingBean struct {
+	Id            int       `json:"id"`
+	SessionId     string    `json:"sessionId"`
+	ClusterId     int       `json:"clusterId"`
+	EnvironmentId int       `json:"environmentId,omitempty"`
+	AppId         int       `json:"appId,omitempty"`
+	HelmAppId     string    `json:"helmAppId,omitempty"`
+	Namespace     string    `json:"namespace"`
+	PodName       string    `json:"podName"`
+	ContainerName string    `json:"containerName"`
+	UserId        int32     `json:"userId"`
+	StorageType   string    `json:"storageType"`
+	Status        string    `json:"status"`
+	Size          int64     `json:"size"`
+	StartedOn     time.Time `json:"startedOn"`
+	EndedOn       time.Time `json:"endedOn,omitempty"`
+}
+
+type TerminalSessionRecordingListResponse struct {
+	TotalCount int                             `json:"totalCount"`
+	Recordings []*TerminalSessionRecordingBean `json:"recordings"`
+}
+
+type TerminalSessionPlayback struct {
+	Recording *TerminalSessionRecordingBean `json:"recording"`
+	Header    *AsciicastHeader              `json:"header"`
+	Events    []*AsciicastEvent             `json:"events"`
+}
+
+type TerminalSessionRecordingSettingBean struct {
+	Id            int `json:"id"`
+	ClusterId     int `json:"clusterId" validate:"required"`
+	EnvironmentId int `json:"environmentId"`
+}
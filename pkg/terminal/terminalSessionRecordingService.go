@@ -0,0 +1,380 @@
+package terminal
+
+import (
+	"fmt"
+	"github.com/caarlos0/env/v6"
+	blob_storage "github.com/devtron-labs/common-lib/blob-storage"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/terminal/repository"
+	"github.com/go-pg/pg"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRecordingPageSize = 20
+	maxRecordingPageSize     = 500
+	recordingBlobKeyPrefix   = "terminal-session-recordings"
+)
+
+type TerminalSessionRecordingService interface {
+	// StartRecording returns nil recorder when session is not required to be recorded
+	StartRecording(req *TerminalSessionRequest) (*TerminalSessionRecorder, error)
+	GetRecordings(filter *repository.TerminalSessionRecordingFilter) (*TerminalSessionRecordingListResponse, error)
+	GetPlayback(id int) (*TerminalSessionPlayback, error)
+	// OpenRecording returns reader of asciicast file, caller must close it
+	OpenRecording(id int) (*TerminalSessionRecordingBean, io.ReadCloser, error)
+	GetRecordingSettings() ([]*TerminalSessionRecordingSettingBean, error)
+	SaveRecordingSetting(setting *TerminalSessionRecordingSettingBean, userId int32) (*TerminalSessionRecordingSettingBean, error)
+	DeleteRecordingSetting(id int, userId int32) error
+}
+
+type TerminalSessionRecordingServiceImpl struct {
+	logger                             *zap.SugaredLogger
+	terminalSessionRecordingRepository repository.TerminalSessionRecordingRepository
+	blobStorageService                 *blob_storage.BlobStorageServiceImpl
+	config                             *TerminalSessionRecordingConfig
+}
+
+func NewTerminalSessionRecordingServiceImpl(logger *zap.SugaredLogger,
+	terminalSessionRecordingRepository repository.TerminalSessionRecordingRepository) (*TerminalSessionRecordingServiceImpl, error) {
+	config := &TerminalSessionRecordingConfig{}
+	err := env.Parse(config)
+	if err != nil {
+		logger.Errorw("error in parsing terminal session recording config", "err", err)
+		return nil, err
+	}
+	impl := &TerminalSessionRecordingServiceImpl{
+		logger:                             logger,
+		terminalSessionRecordingRepository: terminalSessionRecordingRepository,
+		blobStorageService:                 blob_storage.NewBlobStorageServiceImpl(logger),
+		config:                             config,
+	}
+	if config.RetentionDays > 0 {
+		cleanupCron := cron.New(cron.WithChain())
+		cleanupCron.Start()
+		_, err = cleanupCron.AddFunc(fmt.Sprintf("@every %dm", config.CleanupCronTimeMins), impl.deleteExpiredRecordings)
+		if err != nil {
+			logger.Errorw("error in adding terminal session recording cleanup cron", "err", err)
+			return nil, err
+		}
+	}
+	return impl, nil
+}
+
+func (impl *TerminalSessionRecordingServiceImpl) StartRecording(req *TerminalSessionRequest) (*TerminalSessionRecorder, error) {
+	required, err := impl.isRecordingRequired(req)
+	if err != nil || !required {
+		return nil, err
+	}
+	err = os.MkdirAll(impl.config.LocalDir, os.ModePerm)
+	if err != nil {
+		impl.logger.Errorw("error in creating terminal session recording dir", "err", err, "dir", impl.config.LocalDir)
+		return nil, err
+	}
+	localPath := filepath.Join(impl.config.LocalDir, req.SessionId+RECORDING_FILE_EXTENSION)
+	file, err := os.Create(localPath)
+	if err != nil {
+		impl.logger.Errorw("error in creating terminal session recording file", "err", err, "path", localPath)
+		return nil, err
+	}
+	model := &repository.TerminalSessionRecording{
+		SessionId:     req.SessionId,
+		ClusterId:     req.ClusterId,
+		EnvironmentId: req.EnvironmentId,
+		AppId:         req.AppId,
+		HelmAppId:     req.ApplicationId,
+		Namespace:     req.Namespace,
+		PodName:       req.PodName,
+		ContainerName: req.ContainerName,
+		UserId:        req.UserId,
+		StorageType:   RECORDING_STORAGE_LOCAL,
+		FilePath:      localPath,
+		Status:        RECORDING_STATUS_RECORDING,
+		StartedOn:     time.Now(),
+	}
+	err = impl.terminalSessionRecordingRepository.Save(model)
+	if err != nil {
+		impl.logger.Errorw("error in saving terminal session recording", "err", err, "sessionId", req.SessionId)
+		_ = file.Close()
+		_ = os.Remove(localPath)
+		return nil, err
+	}
+	title := fmt.Sprintf("%s/%s/%s", req.Namespace, req.PodName, req.ContainerName)
+	return NewTerminalSessionRecorder(file, title, func(err error) {
+		impl.finishRecording(model, err)
+	}), nil
+}
+
+func (impl *TerminalSessionRecordingServiceImpl) isRecordingRequired(req *TerminalSessionRequest) (bool, error) {
+	if impl.config.RecordAllSessions {
+		return true, nil
+	}
+	if req.ClusterId == 0 {
+		return false, nil
+	}
+	settings, err := impl.terminalSessionRecordingRepository.FindActiveSettingsByClusterId(req.ClusterId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting terminal session recording settings", "err", err, "clusterId", req.ClusterId)
+		return false, err
+	}
+	return isRecordingForced(settings, req.EnvironmentId), nil
+}
+
+// isRecordingForced checks settings of session's cluster, cluster wide setting applies to sessions without environment too
+func isRecordingForced(settings []*repository.TerminalSessionRecordingSetting, environmentId int) bool {
+	for _, setting := range settings {
+		if setting.EnvironmentId == 0 || setting.EnvironmentId == environmentId {
+			return true
+		}
+	}
+	return false
+}
+
+// finishRecording uploads recording to blob storage when enabled, local file is kept if upload fails
+func (impl *TerminalSessionRecordingServiceImpl) finishRecording(model *repository.TerminalSessionRecording, recordErr error) {
+	model.EndedOn = time.Now()
+	model.Status = RECORDING_STATUS_COMPLETED
+	if recordErr != nil {
+		impl.logger.Errorw("error in recording terminal session", "err", recordErr, "sessionId", model.SessionId)
+		model.Status = RECORDING_STATUS_FAILED
+	}
+	if fileInfo, err := os.Stat(model.FilePath); err == nil {
+		model.Size = fileInfo.Size()
+	}
+	if impl.config.BlobStorageEnabled {
+		blobKey := fmt.Sprintf("%s/%s%s", recordingBlobKeyPrefix, model.SessionId, RECORDING_FILE_EXTENSION)
+		err := impl.blobStorageService.PutWithCommand(impl.getBlobStorageRequest(model.FilePath, blobKey))
+		if err != nil {
+			impl.logger.Errorw("error in uploading terminal session recording, keeping it on local disk", "err", err, "sessionId", model.SessionId)
+		} else {
+			if err = os.Remove(model.FilePath); err != nil {
+				impl.logger.Errorw("error in removing uploaded terminal session recording", "err", err, "path", model.FilePath)
+			}
+			model.StorageType = string(impl.config.CloudProvider)
+			model.FilePath = blobKey
+		}
+	}
+	err := impl.terminalSessionRecordingRepository.Update(model)
+	if err != nil {
+		impl.logger.Errorw("error in updating terminal session recording", "err", err, "sessionId", model.SessionId)
+	}
+}
+
+func (impl *TerminalSessionRecordingServiceImpl) getBlobStorageRequest(sourceKey string, destinationKey string) *blob_storage.BlobStorageRequest {
+	return &blob_storage.BlobStorageRequest{
+		StorageType:    impl.config.CloudProvider,
+		SourceKey:      sourceKey,
+		DestinationKey: destinationKey,
+		AwsS3BaseConfig: &blob_storage.AwsS3BaseConfig{
+			AccessKey:   impl.config.BlobStorageS3AccessKey,
+			Passkey:     impl.config.BlobStorageS3SecretKey,
+			EndpointUrl: impl.config.BlobStorageS3Endpoint,
+			IsInSecure:  impl.config.BlobStorageS3EndpointInsecure,
+			BucketName:  impl.config.BlobStorageBucket,
+			Region:      impl.config.BlobStorageRegion,
+		},
+		AzureBlobBaseConfig: &blob_storage.AzureBlobBaseConfig{
+			Enabled:           impl.config.CloudProvider == blob_storage.BLOB_STORAGE_AZURE,
+			AccountName:       impl.config.AzureAccountName,
+			AccountKey:        impl.config.AzureAccountKey,
+			BlobContainerName: impl.config.BlobStorageBucket,
+		},
+		GcpBlobBaseConfig: &blob_storage.GcpBlobBaseConfig{
+			BucketName:             impl.config.BlobStorageBucket,
+			CredentialFileJsonData: impl.config.BlobStorageGcpCredentialJson,
+		},
+	}
+}
+
+func (impl *TerminalSessionRecordingServiceImpl) GetRecordings(filter *repository.TerminalSessionRecordingFilter) (*TerminalSessionRecordingListResponse, error) {
+	if filter.Size <= 0 {
+		filter.Size = defaultRecordingPageSize
+	} else if filter.Size > maxRecordingPageSize {
+		filter.Size = maxRecordingPageSize
+	}
+	models, count, err := impl.terminalSessionRecordingRepository.FindByFilter(filter)
+	if err != nil {
+		impl.logger.Errorw("error in getting terminal session recordings", "err", err, "filter", filter)
+		return nil, err
+	}
+	response := &TerminalSessionRecordingListResponse{TotalCount: count, Recordings: make([]*TerminalSessionRecordingBean, 0, len(models))}
+	for _, model := range models {
+		response.Recordings = append(response.Recordings, adaptRecording(model))
+	}
+	return response, nil
+}
+
+func (impl *TerminalSessionRecordingServiceImpl) GetPlayback(id int) (*TerminalSessionPlayback, error) {
+	recording, reader, err := impl.OpenRecording(id)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	header, events, err := ParseAsciicast(reader)
+	if err != nil {
+		impl.logger.Errorw("error in parsing terminal session recording", "err", err, "id", id)
+		return nil, err
+	}
+	return &TerminalSessionPlayback{Recording: recording, Header: header, Events: events}, nil
+}
+
+func (impl *TerminalSessionRecordingServiceImpl) OpenRecording(id int) (*TerminalSessionRecordingBean, io.ReadCloser, error) {
+	model, err := impl.terminalSessionRecordingRepository.FindById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting terminal session recording", "err", err, "id", id)
+		return nil, nil, err
+	}
+	if model.StorageType == RECORDING_STORAGE_LOCAL {
+		file, err := os.Open(model.FilePath)
+		if err != nil {
+			impl.logger.Errorw("error in opening terminal session recording", "err", err, "path", model.FilePath)
+			return nil, nil, err
+		}
+		return adaptRecording(model), file, nil
+	}
+	// blob storage client downloads relative to root
+	downloadKey := strings.TrimPrefix(filepath.Join(impl.config.LocalDir, fmt.Sprintf("download-%d-%d%s", model.Id, time.Now().UnixNano(), RECORDING_FILE_EXTENSION)), "/")
+	err = os.MkdirAll(impl.config.LocalDir, os.ModePerm)
+	if err != nil {
+		impl.logger.Errorw("error in creating terminal session recording dir", "err", err, "dir", impl.config.LocalDir)
+		return nil, nil, err
+	}
+	_, _, err = impl.blobStorageService.Get(impl.getBlobStorageRequest(model.FilePath, downloadKey))
+	if err != nil {
+		impl.logger.Errorw("error in downloading terminal session recording", "err", err, "key", model.FilePath)
+		_ = os.Remove("/" + downloadKey)
+		return nil, nil, err
+	}
+	file, err := os.Open("/" + downloadKey)
+	if err != nil {
+		impl.logger.Errorw("error in opening downloaded terminal session recording", "err", err, "path", downloadKey)
+		return nil, nil, err
+	}
+	return adaptRecording(model), &tempFileReadCloser{File: file}, nil
+}
+
+// tempFileReadCloser removes downloaded file once it is read
+type tempFileReadCloser struct {
+	*os.File
+}
+
+func (f *tempFileReadCloser) Close() error {
+	err := f.File.Close()
+	if removeErr := os.Remove(f.File.Name()); removeErr != nil && err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+func adaptRecording(model *repository.TerminalSessionRecording) *TerminalSessionRecordingBean {
+	return &TerminalSessionRecordingBean{
+		Id:            model.Id,
+		SessionId:     model.SessionId,
+		ClusterId:     model.ClusterId,
+		EnvironmentId: model.EnvironmentId,
+		AppId:         model.AppId,
+		HelmAppId:     model.HelmAppId,
+		Namespace:     model.Namespace,
+		PodName:       model.PodName,
+		ContainerName: model.ContainerName,
+		UserId:        model.UserId,
+		StorageType:   model.StorageType,
+		Status:        model.Status,
+		Size:          model.Size,
+		StartedOn:     model.StartedOn,
+		EndedOn:       model.EndedOn,
+	}
+}
+
+func (impl *TerminalSessionRecordingServiceImpl) GetRecordingSettings() ([]*TerminalSessionRecordingSettingBean, error) {
+	models, err := impl.terminalSessionRecordingRepository.FindAllActiveSettings()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting terminal session recording settings", "err", err)
+		return nil, err
+	}
+	settings := make([]*TerminalSessionRecordingSettingBean, 0, len(models))
+	for _, model := range models {
+		settings = append(settings, &TerminalSessionRecordingSettingBean{Id: model.Id, ClusterId: model.ClusterId, EnvironmentId: model.EnvironmentId})
+	}
+	return settings, nil
+}
+
+// SaveRecordingSetting returns existing setting when same cluster and environment is already forced
+func (impl *TerminalSessionRecordingServiceImpl) SaveRecordingSetting(setting *TerminalSessionRecordingSettingBean, userId int32) (*TerminalSessionRecordingSettingBean, error) {
+	existingSettings, err := impl.terminalSessionRecordingRepository.FindActiveSettingsByClusterId(setting.ClusterId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting terminal session recording settings", "err", err, "clusterId", setting.ClusterId)
+		return nil, err
+	}
+	for _, existingSetting := range existingSettings {
+		if existingSetting.EnvironmentId == setting.EnvironmentId {
+			setting.Id = existingSetting.Id
+			return setting, nil
+		}
+	}
+	model := &repository.TerminalSessionRecordingSetting{
+		ClusterId:     setting.ClusterId,
+		EnvironmentId: setting.EnvironmentId,
+		Active:        true,
+		AuditLog:      sql.AuditLog{CreatedOn: time.Now(), CreatedBy: userId, UpdatedOn: time.Now(), UpdatedBy: userId},
+	}
+	err = impl.terminalSessionRecordingRepository.SaveSetting(model)
+	if err != nil {
+		impl.logger.Errorw("error in saving terminal session recording setting", "err", err, "setting", setting)
+		return nil, err
+	}
+	setting.Id = model.Id
+	return setting, nil
+}
+
+func (impl *TerminalSessionRecordingServiceImpl) DeleteRecordingSetting(id int, userId int32) error {
+	model, err := impl.terminalSessionRecordingRepository.FindActiveSettingById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting terminal session recording setting", "err", err, "id", id)
+		return err
+	}
+	model.Active = false
+	model.UpdatedOn = time.Now()
+	model.UpdatedBy = userId
+	err = impl.terminalSessionRecordingRepository.UpdateSetting(model)
+	if err != nil {
+		impl.logger.Errorw("error in deleting terminal session recording setting", "err", err, "id", id)
+		return err
+	}
+	return nil
+}
+
+// deleteExpiredRecordings removes local files and metadata of recordings older than retention, objects in blob
+// storage are expected to be expired by lifecycle rule of bucket as blob storage client has no delete
+func (impl *TerminalSessionRecordingServiceImpl) deleteExpiredRecordings() {
+	retainFrom := time.Now().AddDate(0, 0, -impl.config.RetentionDays)
+	models, err := impl.terminalSessionRecordingRepository.FindStartedBefore(retainFrom)
+	if err != nil {
+		impl.logger.Errorw("error in getting expired terminal session recordings", "err", err, "retainFrom", retainFrom)
+		return
+	}
+	var ids []int
+	for _, model := range models {
+		if model.StorageType == RECORDING_STORAGE_LOCAL {
+			if err = os.Remove(model.FilePath); err != nil && !os.IsNotExist(err) {
+				impl.logger.Errorw("error in removing expired terminal session recording", "err", err, "path", model.FilePath)
+				continue
+			}
+		}
+		ids = append(ids, model.Id)
+	}
+	err = impl.terminalSessionRecordingRepository.DeleteByIds(ids)
+	if err != nil {
+		impl.logger.Errorw("error in deleting expired terminal session recordings", "err", err, "ids", ids)
+		return
+	}
+	if len(ids) > 0 {
+		impl.logger.Infow("deleted expired terminal session recordings", "count", len(ids), "retainFrom", retainFrom)
+	}
+}
@@ -500,6 +500,8 @@ func (impl UserAuthServiceImpl) DeleteRoles(entityType string, entityName string
 		roleModels, err = impl.userAuthRepository.GetRolesForApp(entityName)
 	case repository2.CHART_GROUP_TYPE:
 		roleModels, err = impl.userAuthRepository.GetRolesForChartGroup(entityName)
+	case repository2.APP_GROUP_TYPE:
+		roleModels, err = impl.userAuthRepository.GetRolesForAppGroup(entityName)
 	}
 	if err != nil {
 		impl.logger.Errorw(fmt.Sprintf("error in getting roles by %s", entityType), "err", err, "name", entityName)
@@ -377,7 +377,7 @@ func (impl UserServiceImpl) createUserIfNotExists(userInfo *bean.UserInfo, email
 									userInfo.Status = "role not found for any given filter: " + roleFilter.Team + "," + environment + "," + entityName + "," + roleFilter.Action
 									continue
 								}
-							} else if len(roleFilter.Entity) > 0 && roleFilter.Entity == "chart-group" {
+							} else if len(roleFilter.Entity) > 0 && (roleFilter.Entity == "chart-group" || roleFilter.Entity == repository2.APP_GROUP_TYPE) {
 								flag, err := impl.userAuthRepository.CreateDefaultPoliciesForGlobalEntity(roleFilter.Entity, entityName, roleFilter.Action, tx)
 								if err != nil || flag == false {
 									return nil, err
@@ -36,6 +36,7 @@ const (
 
 	ResourceAutocomplete = "autocomplete"
 	ResourceChartGroup   = "chart-group"
+	ResourceAppGroup     = "app-group"
 
 	ResourceTeam    = "team"
 	ResourceAdmin   = "admin"
@@ -49,6 +50,7 @@ const (
 	ActionTrigger   = "trigger"
 	ActionNotify    = "notify"
 	ActionExec      = "exec"
+	ActionDebug     = "debug"
 
 	ClusterResourceRegex         = "%s/%s"    // {cluster}/{namespace}
 	ClusterObjectRegex           = "%s/%s/%s" // {groupName}/{kindName}/{objectName}
@@ -375,6 +375,29 @@ func (_m *UserAuthRepository) GetRolesForApp(appName string) ([]*repository.Role
 	return r0, r1
 }
 
+// GetRolesForAppGroup provides a mock function with given fields: appGroupName
+func (_m *UserAuthRepository) GetRolesForAppGroup(appGroupName string) ([]*repository.RoleModel, error) {
+	ret := _m.Called(appGroupName)
+
+	var r0 []*repository.RoleModel
+	if rf, ok := ret.Get(0).(func(string) []*repository.RoleModel); ok {
+		r0 = rf(appGroupName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.RoleModel)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(appGroupName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRolesForChartGroup provides a mock function with given fields: chartGroupName
 func (_m *UserAuthRepository) GetRolesForChartGroup(chartGroupName string) ([]*repository.RoleModel, error) {
 	ret := _m.Called(chartGroupName)
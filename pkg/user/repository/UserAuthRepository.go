@@ -37,6 +37,7 @@ const (
 	ENV_TYPE         = "environment"
 	APP_TYPE         = "app"
 	CHART_GROUP_TYPE = "chart-group"
+	APP_GROUP_TYPE   = "app-group"
 )
 
 type UserAuthRepository interface {
@@ -64,6 +65,7 @@ type UserAuthRepository interface {
 	GetRolesForProject(teamName string) ([]*RoleModel, error)
 	GetRolesForApp(appName string) ([]*RoleModel, error)
 	GetRolesForChartGroup(chartGroupName string) ([]*RoleModel, error)
+	GetRolesForAppGroup(appGroupName string) ([]*RoleModel, error)
 	DeleteRole(role *RoleModel, tx *pg.Tx) error
 
 	GetRoleByFilterForClusterEntity(cluster, namespace, group, kind, resource, action string) (RoleModel, error)
@@ -1506,6 +1508,17 @@ func (impl UserAuthRepositoryImpl) GetRolesForChartGroup(chartGroupName string)
 	return roles, nil
 }
 
+func (impl UserAuthRepositoryImpl) GetRolesForAppGroup(appGroupName string) ([]*RoleModel, error) {
+	var roles []*RoleModel
+	err := impl.dbConnection.Model(&roles).Where("entity = ?", APP_GROUP_TYPE).
+		Where("entity_name = ?", appGroupName).Select()
+	if err != nil {
+		impl.Logger.Errorw("error in getting roles for app group", "err", err, "appGroupName", appGroupName)
+		return nil, err
+	}
+	return roles, nil
+}
+
 func (impl UserAuthRepositoryImpl) DeleteRole(role *RoleModel, tx *pg.Tx) error {
 	err := tx.Delete(role)
 	if err != nil {
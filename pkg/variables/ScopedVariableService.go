@@ -0,0 +1,362 @@
+package variables
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/devtron-labs/devtron/internal/sql/repository/app"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/devtron-labs/devtron/pkg/variables/repository"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ScopedVariableService interface {
+	// CreateOrUpdate saves the variable with the given values, existing values of the variable are replaced
+	CreateOrUpdate(dto *ScopedVariableDto) (*ScopedVariableDto, error)
+	Delete(id int, userId int32) error
+	GetAll() ([]*ScopedVariableDto, error)
+	GetById(id int) (*ScopedVariableDto, error)
+	GetUsage(id int) ([]*VariableUsageDto, error)
+	// ResolveValues returns values of the given variables resolved for the app, envId is 0 when not resolving for an environment
+	ResolveValues(names []string, appId, envId int) (map[string]string, error)
+	// SubstituteVariables replaces variables referenced in template with values resolved for the app and environment
+	SubstituteVariables(template string, appId, envId int, escapeJson bool) (string, map[string]string, error)
+	SaveSnapshot(snapshot *VariableSnapshotDto) error
+	GetSnapshots(appId, cdWorkflowRunnerId int) ([]*VariableSnapshotDto, error)
+}
+
+type ScopedVariableServiceImpl struct {
+	logger                   *zap.SugaredLogger
+	scopedVariableRepository repository.ScopedVariableRepository
+	appRepository            app.AppRepository
+	environmentRepository    repository2.EnvironmentRepository
+}
+
+func NewScopedVariableServiceImpl(logger *zap.SugaredLogger,
+	scopedVariableRepository repository.ScopedVariableRepository,
+	appRepository app.AppRepository,
+	environmentRepository repository2.EnvironmentRepository) *ScopedVariableServiceImpl {
+	return &ScopedVariableServiceImpl{
+		logger:                   logger,
+		scopedVariableRepository: scopedVariableRepository,
+		appRepository:            appRepository,
+		environmentRepository:    environmentRepository,
+	}
+}
+
+func (impl ScopedVariableServiceImpl) validate(dto *ScopedVariableDto) error {
+	err := ValidateVariableName(dto.Name)
+	if err != nil {
+		return err
+	}
+	scopes := make(map[string]bool)
+	for _, value := range dto.Values {
+		if value.ScopeType == SCOPE_GLOBAL {
+			value.ScopeId = 0
+		} else if value.ScopeId <= 0 {
+			return fmt.Errorf("scope id is required for %s scoped value of variable %s", value.ScopeType, dto.Name)
+		}
+		key := fmt.Sprintf("%s-%d", value.ScopeType, value.ScopeId)
+		if scopes[key] {
+			return fmt.Errorf("found multiple values of variable %s for scope %s %d", dto.Name, value.ScopeType, value.ScopeId)
+		}
+		scopes[key] = true
+	}
+	return nil
+}
+
+func (impl ScopedVariableServiceImpl) CreateOrUpdate(dto *ScopedVariableDto) (*ScopedVariableDto, error) {
+	err := impl.validate(dto)
+	if err != nil {
+		return nil, err
+	}
+	model, err := impl.scopedVariableRepository.FindVariableByName(dto.Name)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting scoped variable by name", "err", err, "name", dto.Name)
+		return nil, err
+	}
+	if dto.Id > 0 && model.Id > 0 && model.Id != dto.Id {
+		return nil, fmt.Errorf("variable with name %s already exists", dto.Name)
+	}
+	if dto.Id > 0 && model.Id == 0 {
+		//renaming an existing variable
+		model, err = impl.scopedVariableRepository.FindVariableById(dto.Id)
+		if err != nil {
+			impl.logger.Errorw("error in getting scoped variable", "err", err, "id", dto.Id)
+			return nil, err
+		}
+	}
+
+	dbConnection := impl.scopedVariableRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return nil, err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+
+	model.Name = dto.Name
+	model.Description = dto.Description
+	model.Active = true
+	model.UpdatedBy = dto.UserId
+	model.UpdatedOn = time.Now()
+	if model.Id == 0 {
+		model.CreatedBy = dto.UserId
+		model.CreatedOn = time.Now()
+		err = impl.scopedVariableRepository.SaveVariable(model, tx)
+	} else {
+		err = impl.scopedVariableRepository.UpdateVariable(model, tx)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in saving scoped variable", "err", err, "name", dto.Name)
+		return nil, err
+	}
+	err = impl.scopedVariableRepository.DeactivateValuesByVariableId(model.Id, dto.UserId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deactivating values of scoped variable", "err", err, "id", model.Id)
+		return nil, err
+	}
+	var values []*repository.ScopedVariableValue
+	for _, value := range dto.Values {
+		values = append(values, &repository.ScopedVariableValue{
+			VariableId: model.Id,
+			ScopeType:  string(value.ScopeType),
+			ScopeId:    value.ScopeId,
+			Value:      value.Value,
+			Active:     true,
+			AuditLog:   sql.AuditLog{CreatedBy: dto.UserId, CreatedOn: time.Now(), UpdatedBy: dto.UserId, UpdatedOn: time.Now()},
+		})
+	}
+	err = impl.scopedVariableRepository.SaveValues(values, tx)
+	if err != nil {
+		impl.logger.Errorw("error in saving values of scoped variable", "err", err, "id", model.Id)
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	dto.Id = model.Id
+	return dto, nil
+}
+
+func (impl ScopedVariableServiceImpl) Delete(id int, userId int32) error {
+	model, err := impl.scopedVariableRepository.FindVariableById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting scoped variable", "err", err, "id", id)
+		return err
+	}
+	dbConnection := impl.scopedVariableRepository.GetConnection()
+	tx, err := dbConnection.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback tx on error.
+	defer tx.Rollback()
+	model.Active = false
+	model.UpdatedBy = userId
+	model.UpdatedOn = time.Now()
+	err = impl.scopedVariableRepository.UpdateVariable(model, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deleting scoped variable", "err", err, "id", id)
+		return err
+	}
+	err = impl.scopedVariableRepository.DeactivateValuesByVariableId(id, userId, tx)
+	if err != nil {
+		impl.logger.Errorw("error in deactivating values of scoped variable", "err", err, "id", id)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (impl ScopedVariableServiceImpl) GetAll() ([]*ScopedVariableDto, error) {
+	models, err := impl.scopedVariableRepository.FindAllActiveVariables()
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting scoped variables", "err", err)
+		return nil, err
+	}
+	return impl.toDtos(models)
+}
+
+func (impl ScopedVariableServiceImpl) GetById(id int) (*ScopedVariableDto, error) {
+	model, err := impl.scopedVariableRepository.FindVariableById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting scoped variable", "err", err, "id", id)
+		return nil, err
+	}
+	dtos, err := impl.toDtos([]*repository.ScopedVariable{model})
+	if err != nil {
+		return nil, err
+	}
+	return dtos[0], nil
+}
+
+func (impl ScopedVariableServiceImpl) toDtos(models []*repository.ScopedVariable) ([]*ScopedVariableDto, error) {
+	var variableIds []int
+	dtos := make([]*ScopedVariableDto, 0, len(models))
+	dtoById := make(map[int]*ScopedVariableDto)
+	for _, model := range models {
+		variableIds = append(variableIds, model.Id)
+		dto := &ScopedVariableDto{Id: model.Id, Name: model.Name, Description: model.Description, Values: make([]*ScopedVariableValueDto, 0)}
+		dtoById[model.Id] = dto
+		dtos = append(dtos, dto)
+	}
+	values, err := impl.scopedVariableRepository.FindActiveValuesByVariableIds(variableIds)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting values of scoped variables", "err", err, "variableIds", variableIds)
+		return nil, err
+	}
+	for _, value := range values {
+		if dto, ok := dtoById[value.VariableId]; ok {
+			dto.Values = append(dto.Values, &ScopedVariableValueDto{ScopeType: VariableScopeType(value.ScopeType), ScopeId: value.ScopeId, Value: value.Value})
+		}
+	}
+	return dtos, nil
+}
+
+func (impl ScopedVariableServiceImpl) GetUsage(id int) ([]*VariableUsageDto, error) {
+	model, err := impl.scopedVariableRepository.FindVariableById(id)
+	if err != nil {
+		impl.logger.Errorw("error in getting scoped variable", "err", err, "id", id)
+		return nil, err
+	}
+	usages, err := impl.scopedVariableRepository.FindUsage(model.Name)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting usage of scoped variable", "err", err, "name", model.Name)
+		return nil, err
+	}
+	usageDtos := make([]*VariableUsageDto, 0, len(usages))
+	for _, usage := range usages {
+		usageDtos = append(usageDtos, &VariableUsageDto{AppId: usage.AppId, AppName: usage.AppName, UsedIn: usage.UsedIn})
+	}
+	return usageDtos, nil
+}
+
+func (impl ScopedVariableServiceImpl) getScope(appId, envId int) (*VariableScope, error) {
+	scope := &VariableScope{AppId: appId, EnvironmentId: envId}
+	appModel, err := impl.appRepository.FindById(appId)
+	if err != nil {
+		impl.logger.Errorw("error in getting app for variable scope", "err", err, "appId", appId)
+		return nil, err
+	}
+	scope.ProjectId = appModel.TeamId
+	if envId > 0 {
+		env, err := impl.environmentRepository.FindById(envId)
+		if err != nil {
+			impl.logger.Errorw("error in getting environment for variable scope", "err", err, "envId", envId)
+			return nil, err
+		}
+		scope.ClusterId = env.ClusterId
+	}
+	return scope, nil
+}
+
+func (impl ScopedVariableServiceImpl) ResolveValues(names []string, appId, envId int) (map[string]string, error) {
+	resolved := make(map[string]string)
+	if len(names) == 0 {
+		return resolved, nil
+	}
+	scope, err := impl.getScope(appId, envId)
+	if err != nil {
+		return nil, err
+	}
+	values, err := impl.scopedVariableRepository.FindActiveValuesByNames(names)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting values of scoped variables", "err", err, "names", names)
+		return nil, err
+	}
+	return resolveForScope(values, scope), nil
+}
+
+// resolveForScope picks for every variable the value of the most specific scope matching the given scope
+func resolveForScope(values []*repository.ScopedVariableValueWithName, scope *VariableScope) map[string]string {
+	resolved := make(map[string]string)
+	resolvedPriority := make(map[string]int)
+	for _, value := range values {
+		scopeType := VariableScopeType(value.ScopeType)
+		var matches bool
+		switch scopeType {
+		case SCOPE_APP:
+			matches = value.ScopeId == scope.AppId
+		case SCOPE_PROJECT:
+			matches = value.ScopeId == scope.ProjectId
+		case SCOPE_ENVIRONMENT:
+			matches = scope.EnvironmentId > 0 && value.ScopeId == scope.EnvironmentId
+		case SCOPE_CLUSTER:
+			matches = scope.ClusterId > 0 && value.ScopeId == scope.ClusterId
+		case SCOPE_GLOBAL:
+			matches = true
+		}
+		if matches && scopePriority[scopeType] > resolvedPriority[value.Name] {
+			resolved[value.Name] = value.Value
+			resolvedPriority[value.Name] = scopePriority[scopeType]
+		}
+	}
+	return resolved
+}
+
+func (impl ScopedVariableServiceImpl) SubstituteVariables(template string, appId, envId int, escapeJson bool) (string, map[string]string, error) {
+	if !ContainsVariables(template) {
+		return template, map[string]string{}, nil
+	}
+	values, err := impl.ResolveValues(ExtractVariables(template), appId, envId)
+	if err != nil {
+		return template, nil, err
+	}
+	return Substitute(template, values, escapeJson)
+}
+
+func (impl ScopedVariableServiceImpl) SaveSnapshot(snapshot *VariableSnapshotDto) error {
+	if len(snapshot.Variables) == 0 {
+		return nil
+	}
+	variables, err := json.Marshal(snapshot.Variables)
+	if err != nil {
+		return err
+	}
+	model := &repository.ScopedVariableSnapshot{
+		AppId:              snapshot.AppId,
+		EnvironmentId:      snapshot.EnvironmentId,
+		PipelineId:         snapshot.PipelineId,
+		CdWorkflowRunnerId: snapshot.CdWorkflowRunnerId,
+		Stage:              snapshot.Stage,
+		Variables:          string(variables),
+		AuditLog:           sql.AuditLog{CreatedBy: snapshot.UserId, CreatedOn: time.Now(), UpdatedBy: snapshot.UserId, UpdatedOn: time.Now()},
+	}
+	err = impl.scopedVariableRepository.SaveSnapshot(model)
+	if err != nil {
+		impl.logger.Errorw("error in saving scoped variable snapshot", "err", err, "appId", snapshot.AppId, "wfrId", snapshot.CdWorkflowRunnerId)
+		return err
+	}
+	return nil
+}
+
+func (impl ScopedVariableServiceImpl) GetSnapshots(appId, cdWorkflowRunnerId int) ([]*VariableSnapshotDto, error) {
+	models, err := impl.scopedVariableRepository.FindSnapshotsByAppIdAndWfrId(appId, cdWorkflowRunnerId)
+	if err != nil && err != pg.ErrNoRows {
+		impl.logger.Errorw("error in getting scoped variable snapshots", "err", err, "appId", appId, "wfrId", cdWorkflowRunnerId)
+		return nil, err
+	}
+	snapshots := make([]*VariableSnapshotDto, 0, len(models))
+	for _, model := range models {
+		variables := make(map[string]string)
+		err = json.Unmarshal([]byte(model.Variables), &variables)
+		if err != nil {
+			impl.logger.Errorw("error in un-marshaling scoped variable snapshot", "err", err, "id", model.Id)
+			return nil, err
+		}
+		snapshots = append(snapshots, &VariableSnapshotDto{
+			AppId:              model.AppId,
+			EnvironmentId:      model.EnvironmentId,
+			PipelineId:         model.PipelineId,
+			CdWorkflowRunnerId: model.CdWorkflowRunnerId,
+			Stage:              model.Stage,
+			Variables:          variables,
+			CreatedOn:          model.CreatedOn,
+		})
+	}
+	return snapshots, nil
+}
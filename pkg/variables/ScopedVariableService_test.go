@@ -0,0 +1,53 @@
+package variables
+
+import (
+	"testing"
+
+	"github.com/devtron-labs/devtron/pkg/variables/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubstitute(t *testing.T) {
+	t.Run("references are extracted sorted and unique", func(t *testing.T) {
+		names := ExtractVariables(`host: @{{DB_HOST}}, port: @{{ DB_PORT }}, url: @{{DB_HOST}}:@{{DB_PORT}}`)
+		assert.Equal(t, []string{"DB_HOST", "DB_PORT"}, names)
+	})
+	t.Run("values are json escaped when asked", func(t *testing.T) {
+		values := map[string]string{"MSG": `say "hi"`}
+		substituted, used, err := Substitute(`{"msg":"@{{MSG}}"}`, values, true)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"msg":"say \"hi\""}`, substituted)
+		assert.Equal(t, values, used)
+		substituted, _, err = Substitute(`echo @{{MSG}}`, values, false)
+		assert.Nil(t, err)
+		assert.Equal(t, `echo say "hi"`, substituted)
+	})
+	t.Run("missing variables fail substitution", func(t *testing.T) {
+		template := `@{{A}} @{{B}} @{{B}}`
+		substituted, _, err := Substitute(template, map[string]string{"A": "1"}, false)
+		assert.EqualError(t, err, "no value found for variables B")
+		assert.Equal(t, template, substituted)
+	})
+}
+
+func TestResolveForScope(t *testing.T) {
+	values := []*repository.ScopedVariableValueWithName{
+		{Name: "DB_HOST", ScopeType: string(SCOPE_GLOBAL), Value: "global"},
+		{Name: "DB_HOST", ScopeType: string(SCOPE_CLUSTER), ScopeId: 4, Value: "cluster"},
+		{Name: "DB_HOST", ScopeType: string(SCOPE_APP), ScopeId: 9, Value: "other-app"},
+		{Name: "DB_PORT", ScopeType: string(SCOPE_ENVIRONMENT), ScopeId: 3, Value: "5432"},
+		{Name: "DB_PORT", ScopeType: string(SCOPE_PROJECT), ScopeId: 2, Value: "6432"},
+	}
+	resolved := resolveForScope(values, &VariableScope{AppId: 1, ProjectId: 2, EnvironmentId: 3, ClusterId: 4})
+	assert.Equal(t, map[string]string{"DB_HOST": "cluster", "DB_PORT": "6432"}, resolved)
+
+	//ci has no environment, environment and cluster values do not apply
+	resolved = resolveForScope(values, &VariableScope{AppId: 1, ProjectId: 5})
+	assert.Equal(t, map[string]string{"DB_HOST": "global"}, resolved)
+}
+
+func TestMaskSecretVariables(t *testing.T) {
+	usedVariables := map[string]string{"DB_HOST": "db.prod", "DB_PASSWORD": "s3cret", "API_KEY": "k3y"}
+	MaskSecretVariables(usedVariables, map[string]string{"DB_PASSWORD": "s3cret", "API_KEY": "k3y"})
+	assert.Equal(t, map[string]string{"DB_HOST": "db.prod", "DB_PASSWORD": MASKED_VARIABLE_VALUE, "API_KEY": MASKED_VARIABLE_VALUE}, usedVariables)
+}
@@ -0,0 +1,163 @@
+package variables
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type VariableScopeType string
+
+// scope types of a variable value, ordered from most specific to least specific. A value of a more specific
+// scope wins over values of less specific scopes when resolving a variable for a deployment
+const (
+	SCOPE_APP         VariableScopeType = "APP"
+	SCOPE_PROJECT     VariableScopeType = "PROJECT"
+	SCOPE_ENVIRONMENT VariableScopeType = "ENVIRONMENT"
+	SCOPE_CLUSTER     VariableScopeType = "CLUSTER"
+	SCOPE_GLOBAL      VariableScopeType = "GLOBAL"
+)
+
+var scopePriority = map[VariableScopeType]int{
+	SCOPE_APP:         5,
+	SCOPE_PROJECT:     4,
+	SCOPE_ENVIRONMENT: 3,
+	SCOPE_CLUSTER:     2,
+	SCOPE_GLOBAL:      1,
+}
+
+// stages in which variables are resolved, snapshots of resolved values are saved against them
+const (
+	SNAPSHOT_STAGE_DEPLOY = "DEPLOY"
+	SNAPSHOT_STAGE_PRE    = "PRE"
+	SNAPSHOT_STAGE_POST   = "POST"
+)
+
+// value saved in snapshots for variables resolved into secrets, snapshots are returned to users having view access of the app
+const MASKED_VARIABLE_VALUE = "*****"
+
+// places in which variables can be referenced, returned by where used api
+const (
+	USED_IN_DEPLOYMENT_TEMPLATE = "DEPLOYMENT_TEMPLATE"
+	USED_IN_CONFIGMAP           = "CONFIGMAP"
+	USED_IN_CD_STAGE            = "CD_STAGE"
+	USED_IN_CI_SCRIPT           = "CI_SCRIPT"
+)
+
+const variableNamePattern = "[A-Za-z][A-Za-z0-9_]*"
+
+// variables are referenced as @{{NAME}}, spaces inside braces are allowed
+var variableReferenceRegex = regexp.MustCompile(`@\{\{\s*(` + variableNamePattern + `)\s*\}\}`)
+var variableNameRegex = regexp.MustCompile(`^` + variableNamePattern + `$`)
+
+type ScopedVariableDto struct {
+	Id          int                       `json:"id"`
+	Name        string                    `json:"name" validate:"required"`
+	Description string                    `json:"description"`
+	Values      []*ScopedVariableValueDto `json:"values" validate:"dive"`
+	UserId      int32                     `json:"-"`
+}
+
+type ScopedVariableValueDto struct {
+	ScopeType VariableScopeType `json:"scopeType" validate:"oneof=APP PROJECT ENVIRONMENT CLUSTER GLOBAL"`
+	//id of app, project, environment or cluster, 0 for global scope
+	ScopeId int    `json:"scopeId"`
+	Value   string `json:"value"`
+}
+
+type VariableScope struct {
+	AppId         int
+	ProjectId     int
+	EnvironmentId int
+	ClusterId     int
+}
+
+type VariableUsageDto struct {
+	AppId   int    `json:"appId"`
+	AppName string `json:"appName"`
+	UsedIn  string `json:"usedIn"`
+}
+
+type VariableSnapshotDto struct {
+	AppId              int               `json:"appId"`
+	EnvironmentId      int               `json:"environmentId"`
+	PipelineId         int               `json:"pipelineId"`
+	CdWorkflowRunnerId int               `json:"cdWorkflowRunnerId"`
+	Stage              string            `json:"stage"`
+	Variables          map[string]string `json:"variables"`
+	CreatedOn          time.Time         `json:"createdOn"`
+	UserId             int32             `json:"-"`
+}
+
+func ValidateVariableName(name string) error {
+	if !variableNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid variable name %s, name must start with a letter and contain only letters, digits and underscore", name)
+	}
+	return nil
+}
+
+// ContainsVariables is a cheap check to skip resolution of templates not referencing any variable
+func ContainsVariables(template string) bool {
+	return strings.Contains(template, "@{{")
+}
+
+// MaskSecretVariables masks values of variables which were substituted into secrets, a variable also used outside
+// of secrets is masked as well since the same value is resolved for it
+func MaskSecretVariables(usedVariables map[string]string, secretVariables map[string]string) {
+	for name := range secretVariables {
+		usedVariables[name] = MASKED_VARIABLE_VALUE
+	}
+}
+
+// ExtractVariables returns sorted unique names of variables referenced in template
+func ExtractVariables(template string) []string {
+	nameSet := make(map[string]bool)
+	for _, match := range variableReferenceRegex.FindAllStringSubmatch(template, -1) {
+		nameSet[match[1]] = true
+	}
+	names := make([]string, 0, len(nameSet))
+	for name := range nameSet {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Substitute replaces variable references in template with values, used holds the values of referenced variables.
+// escapeJson is to be set when template is a json document so that values are escaped as json string content
+func Substitute(template string, values map[string]string, escapeJson bool) (substituted string, used map[string]string, err error) {
+	used = make(map[string]string)
+	var missing []string
+	substituted = variableReferenceRegex.ReplaceAllStringFunc(template, func(reference string) string {
+		name := variableReferenceRegex.FindStringSubmatch(reference)[1]
+		value, ok := values[name]
+		if !ok {
+			if _, reported := used[name]; !reported && !containsString(missing, name) {
+				missing = append(missing, name)
+			}
+			return reference
+		}
+		used[name] = value
+		if escapeJson {
+			escaped, _ := json.Marshal(value)
+			return string(escaped[1 : len(escaped)-1])
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return template, used, fmt.Errorf("no value found for variables %s", strings.Join(missing, ", "))
+	}
+	return substituted, used, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"github.com/devtron-labs/devtron/pkg/sql"
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+)
+
+type ScopedVariable struct {
+	tableName   struct{} `sql:"scoped_variable" pg:",discard_unknown_columns"`
+	Id          int      `sql:"id,pk"`
+	Name        string   `sql:"name,notnull"`
+	Description string   `sql:"description"`
+	Active      bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+type ScopedVariableValue struct {
+	tableName  struct{} `sql:"scoped_variable_value" pg:",discard_unknown_columns"`
+	Id         int      `sql:"id,pk"`
+	VariableId int      `sql:"variable_id,notnull"`
+	ScopeType  string   `sql:"scope_type,notnull"`
+	ScopeId    int      `sql:"scope_id,notnull"`
+	Value      string   `sql:"value"`
+	Active     bool     `sql:"active,notnull"`
+	sql.AuditLog
+}
+
+// ScopedVariableValueWithName is used while resolving values of all variables at once
+type ScopedVariableValueWithName struct {
+	Name      string `sql:"name"`
+	ScopeType string `sql:"scope_type"`
+	ScopeId   int    `sql:"scope_id"`
+	Value     string `sql:"value"`
+}
+
+// ScopedVariableSnapshot holds the values of variables resolved for a deployment or a cd stage
+type ScopedVariableSnapshot struct {
+	tableName          struct{} `sql:"scoped_variable_snapshot" pg:",discard_unknown_columns"`
+	Id                 int      `sql:"id,pk"`
+	AppId              int      `sql:"app_id,notnull"`
+	EnvironmentId      int      `sql:"environment_id"`
+	PipelineId         int      `sql:"pipeline_id"`
+	CdWorkflowRunnerId int      `sql:"cd_workflow_runner_id"`
+	Stage              string   `sql:"stage,notnull"`
+	//json of map of variable name to resolved value
+	Variables string `sql:"variables"`
+	sql.AuditLog
+}
+
+type ScopedVariableUsage struct {
+	AppId   int    `sql:"app_id"`
+	AppName string `sql:"app_name"`
+	UsedIn  string `sql:"used_in"`
+}
+
+type ScopedVariableRepository interface {
+	GetConnection() *pg.DB
+	SaveVariable(model *ScopedVariable, tx *pg.Tx) error
+	UpdateVariable(model *ScopedVariable, tx *pg.Tx) error
+	FindVariableById(id int) (*ScopedVariable, error)
+	FindVariableByName(name string) (*ScopedVariable, error)
+	FindAllActiveVariables() ([]*ScopedVariable, error)
+	SaveValues(models []*ScopedVariableValue, tx *pg.Tx) error
+	DeactivateValuesByVariableId(variableId int, userId int32, tx *pg.Tx) error
+	FindActiveValuesByVariableIds(variableIds []int) ([]*ScopedVariableValue, error)
+	FindActiveValuesByNames(names []string) ([]*ScopedVariableValueWithName, error)
+	SaveSnapshot(model *ScopedVariableSnapshot) error
+	FindSnapshotsByAppIdAndWfrId(appId, cdWorkflowRunnerId int) ([]*ScopedVariableSnapshot, error)
+	FindUsage(name string) ([]*ScopedVariableUsage, error)
+}
+
+type ScopedVariableRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewScopedVariableRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *ScopedVariableRepositoryImpl {
+	return &ScopedVariableRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (repo ScopedVariableRepositoryImpl) GetConnection() *pg.DB {
+	return repo.dbConnection
+}
+
+func (repo ScopedVariableRepositoryImpl) SaveVariable(model *ScopedVariable, tx *pg.Tx) error {
+	return tx.Insert(model)
+}
+
+func (repo ScopedVariableRepositoryImpl) UpdateVariable(model *ScopedVariable, tx *pg.Tx) error {
+	return tx.Update(model)
+}
+
+func (repo ScopedVariableRepositoryImpl) FindVariableById(id int) (*ScopedVariable, error) {
+	model := &ScopedVariable{}
+	err := repo.dbConnection.Model(model).
+		Where("id = ?", id).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo ScopedVariableRepositoryImpl) FindVariableByName(name string) (*ScopedVariable, error) {
+	model := &ScopedVariable{}
+	err := repo.dbConnection.Model(model).
+		Where("name = ?", name).
+		Where("active = ?", true).
+		Select()
+	return model, err
+}
+
+func (repo ScopedVariableRepositoryImpl) FindAllActiveVariables() ([]*ScopedVariable, error) {
+	var models []*ScopedVariable
+	err := repo.dbConnection.Model(&models).
+		Where("active = ?", true).
+		Order("name").
+		Select()
+	return models, err
+}
+
+func (repo ScopedVariableRepositoryImpl) SaveValues(models []*ScopedVariableValue, tx *pg.Tx) error {
+	if len(models) == 0 {
+		return nil
+	}
+	return tx.Insert(&models)
+}
+
+func (repo ScopedVariableRepositoryImpl) DeactivateValuesByVariableId(variableId int, userId int32, tx *pg.Tx) error {
+	_, err := tx.Model((*ScopedVariableValue)(nil)).
+		Set("active = ?", false).
+		Set("updated_by = ?", userId).
+		Set("updated_on = now()").
+		Where("variable_id = ?", variableId).
+		Where("active = ?", true).
+		Update()
+	return err
+}
+
+func (repo ScopedVariableRepositoryImpl) FindActiveValuesByVariableIds(variableIds []int) ([]*ScopedVariableValue, error) {
+	var models []*ScopedVariableValue
+	if len(variableIds) == 0 {
+		return models, nil
+	}
+	err := repo.dbConnection.Model(&models).
+		Where("variable_id in (?)", pg.In(variableIds)).
+		Where("active = ?", true).
+		Select()
+	return models, err
+}
+
+func (repo ScopedVariableRepositoryImpl) FindActiveValuesByNames(names []string) ([]*ScopedVariableValueWithName, error) {
+	var models []*ScopedVariableValueWithName
+	if len(names) == 0 {
+		return models, nil
+	}
+	query := "SELECT sv.name, svv.scope_type, svv.scope_id, svv.value FROM scoped_variable_value svv" +
+		" INNER JOIN scoped_variable sv ON sv.id = svv.variable_id" +
+		" WHERE sv.active = true AND svv.active = true AND sv.name in (?);"
+	_, err := repo.dbConnection.Query(&models, query, pg.In(names))
+	return models, err
+}
+
+func (repo ScopedVariableRepositoryImpl) SaveSnapshot(model *ScopedVariableSnapshot) error {
+	return repo.dbConnection.Insert(model)
+}
+
+func (repo ScopedVariableRepositoryImpl) FindSnapshotsByAppIdAndWfrId(appId, cdWorkflowRunnerId int) ([]*ScopedVariableSnapshot, error) {
+	var models []*ScopedVariableSnapshot
+	err := repo.dbConnection.Model(&models).
+		Where("app_id = ?", appId).
+		Where("cd_workflow_runner_id = ?", cdWorkflowRunnerId).
+		Order("id").
+		Select()
+	return models, err
+}
+
+// FindUsage returns apps referencing the variable in deployment templates, configmaps, cd stages or ci scripts.
+// Secrets are not searched as their data is base64 encoded and may be encrypted at rest
+func (repo ScopedVariableRepositoryImpl) FindUsage(name string) ([]*ScopedVariableUsage, error) {
+	var models []*ScopedVariableUsage
+	pattern := `@\{\{\s*` + name + `\s*\}\}`
+	query := "SELECT DISTINCT a.id as app_id, a.app_name, 'DEPLOYMENT_TEMPLATE' as used_in FROM charts c" +
+		" INNER JOIN app a ON a.id = c.app_id WHERE a.active = true AND c.active = true AND c.global_override ~ ?0" +
+		" UNION SELECT DISTINCT a.id, a.app_name, 'DEPLOYMENT_TEMPLATE' FROM chart_env_config_override ceco" +
+		" INNER JOIN charts c ON c.id = ceco.chart_id INNER JOIN app a ON a.id = c.app_id" +
+		" WHERE a.active = true AND ceco.active = true AND ceco.env_override_yaml ~ ?0" +
+		" UNION SELECT DISTINCT a.id, a.app_name, 'CONFIGMAP' FROM config_map_app_level cm" +
+		" INNER JOIN app a ON a.id = cm.app_id WHERE a.active = true AND cm.config_map_data ~ ?0" +
+		" UNION SELECT DISTINCT a.id, a.app_name, 'CONFIGMAP' FROM config_map_env_level cm" +
+		" INNER JOIN app a ON a.id = cm.app_id WHERE a.active = true AND cm.config_map_data ~ ?0" +
+		" UNION SELECT DISTINCT a.id, a.app_name, 'CD_STAGE' FROM pipeline p" +
+		" INNER JOIN app a ON a.id = p.app_id WHERE a.active = true AND p.deleted = false" +
+		" AND (p.pre_stage_config_yaml ~ ?0 OR p.post_stage_config_yaml ~ ?0)" +
+		" UNION SELECT DISTINCT a.id, a.app_name, 'CI_SCRIPT' FROM plugin_pipeline_script pps" +
+		" INNER JOIN pipeline_stage_step pss ON pss.script_id = pps.id" +
+		" INNER JOIN pipeline_stage ps ON ps.id = pss.pipeline_stage_id" +
+		" INNER JOIN ci_pipeline cp ON cp.id = ps.ci_pipeline_id INNER JOIN app a ON a.id = cp.app_id" +
+		" WHERE a.active = true AND cp.deleted = false AND ps.deleted = false AND pss.deleted = false AND pps.script ~ ?0" +
+		" ORDER BY app_name;"
+	_, err := repo.dbConnection.Query(&models, query, pattern)
+	return models, err
+}
@@ -134,6 +134,14 @@ func (impl WebhookHelmServiceImpl) CreateOrUpdateHelmApplication(ctx context.Con
 			Password: chartRepo.Identifier.Password,
 		},
 	}
+	if request.DryRun {
+		res, err := impl.dryRunHelmApplication(ctx, clusterId, installReleaseRequest, isInstalled)
+		if err != nil {
+			impl.logger.Errorw("Error in dry run of helm release", "appIdentifier", appIdentifier, "err", err)
+			return nil, common.InternalServerError, err.Error(), http.StatusInternalServerError
+		}
+		return res, "", "", http.StatusOK
+	}
 	if isInstalled {
 		res, err := impl.helmAppService.UpdateApplicationWithChartInfo(ctx, clusterId, installReleaseRequest)
 		if err != nil {
@@ -163,3 +171,23 @@ func (impl WebhookHelmServiceImpl) CreateOrUpdateHelmApplication(ctx context.Con
 	appDetailUrl := fmt.Sprintf(HELM_APP_DETAIL_URL, hostUrlAttribute.Value, impl.helmAppService.EncodeAppId(appIdentifier))
 	return appDetailUrl, "", "", http.StatusOK
 }
+
+// dryRunHelmApplication diffs release against rendered chart, all resources are to be created if release is not installed
+func (impl WebhookHelmServiceImpl) dryRunHelmApplication(ctx context.Context, clusterId int, installReleaseRequest *client.InstallReleaseRequest, isInstalled bool) (*client.ReleaseDiffResponse, error) {
+	if isInstalled {
+		return impl.helmAppService.DryRunUpdateApplicationWithChartInfo(ctx, clusterId, installReleaseRequest)
+	}
+	manifest, err := impl.helmAppService.RenderChart(ctx, clusterId, installReleaseRequest)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := client.DiffReleaseManifests("", manifest)
+	if err != nil {
+		return nil, err
+	}
+	diff.ReleaseName = installReleaseRequest.ReleaseIdentifier.ReleaseName
+	diff.Namespace = installReleaseRequest.ReleaseIdentifier.ReleaseNamespace
+	diff.ChartName = installReleaseRequest.ChartName
+	diff.ChartVersion = installReleaseRequest.ChartVersion
+	return diff, nil
+}
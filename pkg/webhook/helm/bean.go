@@ -6,6 +6,7 @@ type HelmAppCreateUpdateRequest struct {
 	ReleaseName        string     `json:"releaseName,notnull" validate:"required"`
 	ValuesOverrideYaml string     `json:"valuesOverrideYaml,omitempty"`
 	Chart              *ChartSpec `json:"chart,notnull" validate:"required"`
+	DryRun             bool       `json:"dryRun,omitempty"`
 }
 
 type ChartSpec struct {
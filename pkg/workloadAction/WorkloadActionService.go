@@ -0,0 +1,487 @@
+package workloadAction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/api/bean"
+	client "github.com/devtron-labs/devtron/api/helm-app"
+	"github.com/devtron-labs/devtron/client/k8s/application"
+	"github.com/devtron-labs/devtron/internal/sql/models"
+	"github.com/devtron-labs/devtron/internal/sql/repository/pipelineConfig"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/chart"
+	repository2 "github.com/devtron-labs/devtron/pkg/cluster/repository"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"github.com/devtron-labs/devtron/pkg/workloadAction/repository"
+	"github.com/devtron-labs/devtron/util/argo"
+	"github.com/devtron-labs/devtron/util/k8s"
+	jsonpatch "github.com/evanphx/json-patch"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type WorkloadActionService interface {
+	// RestartWorkloads triggers rolling restart of each workload, a failure on one workload does not stop the others
+	RestartWorkloads(ctx context.Context, request *WorkloadActionRequest) (*WorkloadActionResponse, error)
+	// ScaleWorkloads sets replicas of each workload, see WorkloadScaleRequest for persisting the scale
+	ScaleWorkloads(ctx context.Context, request *WorkloadScaleRequest) (*WorkloadActionResponse, error)
+	GetTimeline(appId int, envId int, helmAppId string, offset int, size int) ([]*WorkloadActionTimelineBean, error)
+}
+
+type WorkloadActionServiceImpl struct {
+	logger                     *zap.SugaredLogger
+	workloadActionRepository   repository.WorkloadActionRepository
+	pipelineRepository         pipelineConfig.PipelineRepository
+	environmentRepository      repository2.EnvironmentRepository
+	cdWorkflowRepository       pipelineConfig.CdWorkflowRepository
+	helmAppService             client.HelmAppService
+	k8sApplicationService      k8s.K8sApplicationService
+	k8sClientService           application.K8sClientService
+	k8sResourceAuditLogService kubernetesResourceAuditLogs.K8sResourceAuditLogService
+	chartService               chart.ChartService
+	propertiesConfigService    pipeline.PropertiesConfigService
+	workflowDagExecutor        pipeline.WorkflowDagExecutor
+	argoUserService            argo.ArgoUserService
+}
+
+func NewWorkloadActionServiceImpl(logger *zap.SugaredLogger, workloadActionRepository repository.WorkloadActionRepository,
+	pipelineRepository pipelineConfig.PipelineRepository, environmentRepository repository2.EnvironmentRepository,
+	cdWorkflowRepository pipelineConfig.CdWorkflowRepository, helmAppService client.HelmAppService,
+	k8sApplicationService k8s.K8sApplicationService, k8sClientService application.K8sClientService,
+	k8sResourceAuditLogService kubernetesResourceAuditLogs.K8sResourceAuditLogService, chartService chart.ChartService,
+	propertiesConfigService pipeline.PropertiesConfigService, workflowDagExecutor pipeline.WorkflowDagExecutor,
+	argoUserService argo.ArgoUserService) *WorkloadActionServiceImpl {
+	return &WorkloadActionServiceImpl{
+		logger:                     logger,
+		workloadActionRepository:   workloadActionRepository,
+		pipelineRepository:         pipelineRepository,
+		environmentRepository:      environmentRepository,
+		cdWorkflowRepository:       cdWorkflowRepository,
+		helmAppService:             helmAppService,
+		k8sApplicationService:      k8sApplicationService,
+		k8sClientService:           k8sClientService,
+		k8sResourceAuditLogService: k8sResourceAuditLogService,
+		chartService:               chartService,
+		propertiesConfigService:    propertiesConfigService,
+		workflowDagExecutor:        workflowDagExecutor,
+		argoUserService:            argoUserService,
+	}
+}
+
+// workloadActionTarget is where workloads of request live, pipeline is set for devtron apps only
+type workloadActionTarget struct {
+	clusterId     int
+	namespace     string
+	pipeline      *pipelineConfig.Pipeline
+	appIdentifier *client.AppIdentifier
+}
+
+func (impl *WorkloadActionServiceImpl) RestartWorkloads(ctx context.Context, request *WorkloadActionRequest) (*WorkloadActionResponse, error) {
+	target, err := impl.getActionTarget(request)
+	if err != nil {
+		return nil, err
+	}
+	response := &WorkloadActionResponse{}
+	for _, resource := range request.Resources {
+		result := impl.applyAction(ctx, request, target, resource, WORKLOAD_ACTION_RESTART, nil)
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
+
+func (impl *WorkloadActionServiceImpl) ScaleWorkloads(ctx context.Context, request *WorkloadScaleRequest) (*WorkloadActionResponse, error) {
+	target, err := impl.getActionTarget(&request.WorkloadActionRequest)
+	if err != nil {
+		return nil, err
+	}
+	if request.PersistInDeploymentTemplate {
+		return impl.persistScale(ctx, request, target)
+	}
+	response := &WorkloadActionResponse{}
+	for _, resource := range request.Resources {
+		result := impl.applyAction(ctx, &request.WorkloadActionRequest, target, resource, WORKLOAD_ACTION_SCALE, request.Replicas)
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
+
+func (impl *WorkloadActionServiceImpl) GetTimeline(appId int, envId int, helmAppId string, offset int, size int) ([]*WorkloadActionTimelineBean, error) {
+	var models []*repository.WorkloadActionTimeline
+	var err error
+	if len(helmAppId) > 0 {
+		models, err = impl.workloadActionRepository.FindByHelmAppId(helmAppId, offset, size)
+	} else {
+		models, err = impl.workloadActionRepository.FindByAppIdAndEnvId(appId, envId, offset, size)
+	}
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting workload action timeline", "err", err, "appId", appId, "envId", envId, "helmAppId", helmAppId)
+		return nil, err
+	}
+	timeline := make([]*WorkloadActionTimelineBean, 0, len(models))
+	for _, model := range models {
+		timeline = append(timeline, &WorkloadActionTimelineBean{
+			Id:             model.Id,
+			Action:         model.Action,
+			Group:          model.Group,
+			Version:        model.Version,
+			Kind:           model.Kind,
+			Namespace:      model.Namespace,
+			ResourceName:   model.ResourceName,
+			ReplicasBefore: model.ReplicasBefore,
+			ReplicasAfter:  model.ReplicasAfter,
+			Persisted:      model.Persisted,
+			Status:         model.Status,
+			Message:        model.Message,
+			CreatedOn:      model.CreatedOn,
+			CreatedBy:      model.CreatedBy,
+		})
+	}
+	return timeline, nil
+}
+
+func (impl *WorkloadActionServiceImpl) getActionTarget(request *WorkloadActionRequest) (*workloadActionTarget, error) {
+	if len(request.HelmAppId) > 0 {
+		appIdentifier, err := impl.helmAppService.DecodeAppId(request.HelmAppId)
+		if err != nil {
+			return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: "400", UserMessage: err.Error(), InternalMessage: err.Error()}
+		}
+		return &workloadActionTarget{clusterId: appIdentifier.ClusterId, namespace: appIdentifier.Namespace, appIdentifier: appIdentifier}, nil
+	}
+	if request.AppId == 0 || request.EnvId == 0 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: "400", UserMessage: "either helmAppId or appId and envId are required"}
+	}
+	pipelines, err := impl.pipelineRepository.FindActiveByAppIdAndEnvironmentId(request.AppId, request.EnvId)
+	if err != nil && !util.IsErrNoRows(err) {
+		impl.logger.Errorw("error in getting pipeline", "err", err, "appId", request.AppId, "envId", request.EnvId)
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusNotFound, Code: "404", UserMessage: "app is not deployed on environment"}
+	}
+	env, err := impl.environmentRepository.FindById(request.EnvId)
+	if err != nil {
+		impl.logger.Errorw("error in getting environment", "err", err, "envId", request.EnvId)
+		return nil, err
+	}
+	return &workloadActionTarget{clusterId: env.ClusterId, namespace: env.Namespace, pipeline: pipelines[0]}, nil
+}
+
+// applyAction mutates live workload, audit and timeline entries are saved whether it succeeds or not
+func (impl *WorkloadActionServiceImpl) applyAction(ctx context.Context, request *WorkloadActionRequest, target *workloadActionTarget,
+	resource *WorkloadResource, action string, replicas *int64) *WorkloadActionResult {
+	result := &WorkloadActionResult{WorkloadResource: *resource, Status: WORKLOAD_ACTION_STATUS_SUCCEEDED}
+	if target.pipeline != nil || len(result.Namespace) == 0 {
+		result.Namespace = target.namespace
+	}
+	if action == WORKLOAD_ACTION_SCALE {
+		result.ReplicasAfter = replicas
+	}
+	before, after, err := impl.mutateWorkload(ctx, target, &result.WorkloadResource, action, replicas)
+	if err == nil && action == WORKLOAD_ACTION_SCALE {
+		result.ReplicasBefore, _ = getReplicas(before.Object)
+	}
+	auditAction := kubernetesResourceAuditLogs.AUDIT_ACTION_RESTART
+	if action == WORKLOAD_ACTION_SCALE {
+		auditAction = kubernetesResourceAuditLogs.AUDIT_ACTION_SCALE
+	}
+	impl.k8sResourceAuditLogService.SaveAuditLog(ctx, &kubernetesResourceAuditLogs.K8sResourceAuditLogRequest{
+		ClusterId:        target.clusterId,
+		GroupVersionKind: schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind},
+		Namespace:        result.Namespace,
+		Name:             resource.Name,
+		Action:           auditAction,
+		Before:           before,
+		After:            after,
+		Err:              err,
+	})
+	if err != nil {
+		result.Status = WORKLOAD_ACTION_STATUS_FAILED
+		result.Message = err.Error()
+	}
+	impl.saveTimeline(request, target, result, action, false)
+	return result
+}
+
+// mutateWorkload returns workload before and after the action, workload of a devtron app must carry app and env labels
+// on its pod template and workload of a helm app must be in resource tree of the release
+func (impl *WorkloadActionServiceImpl) mutateWorkload(ctx context.Context, target *workloadActionTarget, resource *WorkloadResource,
+	action string, replicas *int64) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	err := validateActionKind(action, resource.Kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	k8sRequest := &application.K8sRequestBean{
+		ResourceIdentifier: application.ResourceIdentifier{
+			Name:             resource.Name,
+			Namespace:        resource.Namespace,
+			GroupVersionKind: schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind},
+		},
+	}
+	if target.appIdentifier != nil {
+		valid, err := impl.k8sApplicationService.ValidateResourceRequest(ctx, target.appIdentifier, k8sRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !valid {
+			return nil, nil, fmt.Errorf("%s %s does not belong to the helm app", resource.Kind, resource.Name)
+		}
+	}
+	restConfig, err := impl.k8sApplicationService.GetRestConfigByClusterId(ctx, target.clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config of cluster", "err", err, "clusterId", target.clusterId)
+		return nil, nil, err
+	}
+	manifest, err := impl.k8sClientService.GetResource(ctx, restConfig, k8sRequest)
+	if err != nil {
+		impl.logger.Errorw("error in getting workload", "err", err, "workload", k8sRequest.ResourceIdentifier)
+		return nil, nil, err
+	}
+	before := manifest.Manifest.DeepCopy()
+	if target.pipeline != nil && !isWorkloadOfDevtronApp(before.Object, target.pipeline.AppId, target.pipeline.EnvironmentId) {
+		return nil, nil, fmt.Errorf("%s %s does not belong to the app", resource.Kind, resource.Name)
+	}
+	object := manifest.Manifest.Object
+	err = setWorkloadActionFields(object, action, replicas, time.Now())
+	if err != nil {
+		return before, nil, err
+	}
+	updatedManifest, err := json.Marshal(object)
+	if err != nil {
+		return before, nil, err
+	}
+	k8sRequest.Patch = string(updatedManifest)
+	updated, err := impl.k8sClientService.UpdateResource(ctx, restConfig, k8sRequest)
+	if err != nil {
+		impl.logger.Errorw("error in updating workload", "err", err, "workload", k8sRequest.ResourceIdentifier, "action", action)
+		return before, nil, err
+	}
+	return before, &updated.Manifest, nil
+}
+
+// persistScale saves replicaCount in env override and redeploys latest artifact, argo cd then applies the scale and
+// keeps it. Only the main workload of the chart is driven by replicaCount so exactly one workload is accepted.
+func (impl *WorkloadActionServiceImpl) persistScale(ctx context.Context, request *WorkloadScaleRequest, target *workloadActionTarget) (*WorkloadActionResponse, error) {
+	if target.pipeline == nil || !util.IsAcdApp(target.pipeline.DeploymentAppType) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: "400", UserMessage: "scale can only be persisted for devtron apps deployed through argo cd"}
+	}
+	if len(request.Resources) != 1 {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: "400", UserMessage: "exactly one workload is required to persist scale"}
+	}
+	resource := request.Resources[0]
+	err := validateActionKind(WORKLOAD_ACTION_SCALE, resource.Kind)
+	if err != nil {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: "400", UserMessage: err.Error()}
+	}
+	result := &WorkloadActionResult{WorkloadResource: *resource, Status: WORKLOAD_ACTION_STATUS_SUCCEEDED, ReplicasAfter: request.Replicas}
+	result.Namespace = target.namespace
+	result.ReplicasBefore = impl.getLiveReplicas(ctx, target, &result.WorkloadResource)
+	releaseId, err := impl.saveReplicaCountAndDeploy(ctx, target.pipeline, *request.Replicas, request.UserId)
+	message := fmt.Sprintf("replicaCount %d saved in deployment template, release: %d", *request.Replicas, releaseId)
+	if err != nil {
+		result.Status = WORKLOAD_ACTION_STATUS_FAILED
+		result.Message = err.Error()
+		message = "persisting replicaCount in deployment template"
+	}
+	impl.k8sResourceAuditLogService.SaveAuditLog(ctx, &kubernetesResourceAuditLogs.K8sResourceAuditLogRequest{
+		ClusterId:        target.clusterId,
+		GroupVersionKind: schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind},
+		Namespace:        result.Namespace,
+		Name:             resource.Name,
+		Action:           kubernetesResourceAuditLogs.AUDIT_ACTION_SCALE,
+		Message:          message,
+		Err:              err,
+	})
+	impl.saveTimeline(&request.WorkloadActionRequest, target, result, WORKLOAD_ACTION_SCALE, true)
+	if err != nil {
+		return nil, err
+	}
+	return &WorkloadActionResponse{Results: []*WorkloadActionResult{result}, Persisted: true, ReleaseId: releaseId}, nil
+}
+
+func (impl *WorkloadActionServiceImpl) saveReplicaCountAndDeploy(ctx context.Context, cdPipeline *pipelineConfig.Pipeline, replicas int64, userId int32) (int, error) {
+	appId, envId := cdPipeline.AppId, cdPipeline.EnvironmentId
+	chartRefRes, err := impl.chartService.ChartRefAutocompleteForAppOrEnv(appId, envId)
+	if err != nil {
+		impl.logger.Errorw("error in getting chart ref", "err", err, "appId", appId, "envId", envId)
+		return 0, err
+	}
+	envProperties, err := impl.propertiesConfigService.GetEnvironmentProperties(appId, envId, chartRefRes.LatestEnvChartRef)
+	if err != nil {
+		impl.logger.Errorw("error in getting env properties", "err", err, "appId", appId, "envId", envId)
+		return 0, err
+	}
+	merged, err := overrideValuesWithReplicaCount(envProperties, replicas)
+	if err != nil {
+		impl.logger.Errorw("error in merging replica count in env override", "err", err, "appId", appId, "envId", envId)
+		return 0, err
+	}
+	envPropertiesReq := &pipeline.EnvironmentProperties{
+		Id:                envProperties.EnvironmentConfig.Id,
+		EnvOverrideValues: merged,
+		Status:            envProperties.EnvironmentConfig.Status,
+		ManualReviewed:    true,
+		Active:            true,
+		Namespace:         envProperties.EnvironmentConfig.Namespace,
+		EnvironmentId:     envId,
+		EnvironmentName:   envProperties.EnvironmentConfig.EnvironmentName,
+		Latest:            true,
+		UserId:            userId,
+		AppMetrics:        envProperties.EnvironmentConfig.AppMetrics,
+		ChartRefId:        chartRefRes.LatestEnvChartRef,
+		IsOverride:        true,
+		IsBasicViewLocked: envProperties.EnvironmentConfig.IsBasicViewLocked,
+		CurrentViewEditor: envProperties.EnvironmentConfig.CurrentViewEditor,
+	}
+	_, err = impl.propertiesConfigService.UpdateEnvironmentProperties(appId, envPropertiesReq, userId)
+	if err != nil {
+		impl.logger.Errorw("error in saving replica count in env override", "err", err, "appId", appId, "envId", envId)
+		return 0, err
+	}
+	wf, err := impl.cdWorkflowRepository.FindLatestCdWorkflowByPipelineId([]int{cdPipeline.Id})
+	if err != nil {
+		impl.logger.Errorw("error in getting latest cd workflow", "err", err, "pipelineId", cdPipeline.Id)
+		return 0, err
+	}
+	acdToken, err := impl.argoUserService.GetLatestDevtronArgoCdUserToken()
+	if err != nil {
+		impl.logger.Errorw("error in getting acd token", "err", err)
+		return 0, err
+	}
+	overrideRequest := &bean.ValuesOverrideRequest{
+		PipelineId:     cdPipeline.Id,
+		AppId:          appId,
+		CiArtifactId:   wf.CiArtifactId,
+		UserId:         userId,
+		CdWorkflowType: bean.CD_WORKFLOW_TYPE_DEPLOY,
+		DeploymentType: models.DEPLOYMENTTYPE_DEPLOY,
+	}
+	releaseId, err := impl.workflowDagExecutor.ManualCdTrigger(overrideRequest, context.WithValue(ctx, "token", acdToken))
+	if err != nil {
+		impl.logger.Errorw("error in redeploying after persisting replica count", "err", err, "pipelineId", cdPipeline.Id)
+		return 0, err
+	}
+	return releaseId, nil
+}
+
+// overrideValuesWithReplicaCount sets replicaCount in env override, an override is not created implicitly as that
+// would stop the environment from inheriting later changes of base deployment template
+func overrideValuesWithReplicaCount(envProperties *pipeline.EnvironmentPropertiesResponse, replicas int64) (json.RawMessage, error) {
+	if !envProperties.IsOverride {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: "400", UserMessage: "deployment template is not overridden for environment, override it to persist scale"}
+	}
+	overrideValues := envProperties.EnvironmentConfig.EnvOverrideValues
+	if isAutoscalingEnabled(overrideValues) {
+		return nil, &util.ApiError{HttpStatusCode: http.StatusBadRequest, Code: "400", UserMessage: "replicas are managed by autoscaling in deployment template"}
+	}
+	return jsonpatch.MergePatch(overrideValues, []byte(fmt.Sprintf(`{"replicaCount":%d}`, replicas)))
+}
+
+// getLiveReplicas is best effort, it is only used for timeline
+func (impl *WorkloadActionServiceImpl) getLiveReplicas(ctx context.Context, target *workloadActionTarget, resource *WorkloadResource) *int64 {
+	restConfig, err := impl.k8sApplicationService.GetRestConfigByClusterId(ctx, target.clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config of cluster", "err", err, "clusterId", target.clusterId)
+		return nil
+	}
+	manifest, err := impl.k8sClientService.GetResource(ctx, restConfig, &application.K8sRequestBean{
+		ResourceIdentifier: application.ResourceIdentifier{
+			Name:             resource.Name,
+			Namespace:        resource.Namespace,
+			GroupVersionKind: schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind},
+		},
+	})
+	if err != nil {
+		return nil
+	}
+	replicas, _ := getReplicas(manifest.Manifest.Object)
+	return replicas
+}
+
+func (impl *WorkloadActionServiceImpl) saveTimeline(request *WorkloadActionRequest, target *workloadActionTarget, result *WorkloadActionResult, action string, persisted bool) {
+	model := &repository.WorkloadActionTimeline{
+		AppId:          request.AppId,
+		EnvId:          request.EnvId,
+		HelmAppId:      request.HelmAppId,
+		ClusterId:      target.clusterId,
+		Namespace:      result.Namespace,
+		Action:         action,
+		Group:          result.Group,
+		Version:        result.Version,
+		Kind:           result.Kind,
+		ResourceName:   result.Name,
+		ReplicasBefore: result.ReplicasBefore,
+		ReplicasAfter:  result.ReplicasAfter,
+		Persisted:      persisted,
+		Status:         result.Status,
+		Message:        result.Message,
+		CreatedOn:      time.Now(),
+		CreatedBy:      request.UserId,
+	}
+	err := impl.workloadActionRepository.Save(model)
+	if err != nil {
+		impl.logger.Errorw("error in saving workload action timeline", "err", err, "action", action, "kind", result.Kind, "name", result.Name)
+	}
+}
+
+var restartableKinds = map[string]bool{"Deployment": true, "StatefulSet": true, "DaemonSet": true, "Rollout": true}
+var scalableKinds = map[string]bool{"Deployment": true, "StatefulSet": true, "ReplicaSet": true, "Rollout": true}
+
+func validateActionKind(action string, kind string) error {
+	switch action {
+	case WORKLOAD_ACTION_RESTART:
+		if restartableKinds[kind] {
+			return nil
+		}
+	case WORKLOAD_ACTION_SCALE:
+		if scalableKinds[kind] {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported workload action %s", action)
+	}
+	return fmt.Errorf("%s is not supported for kind %s", action, kind)
+}
+
+// setWorkloadActionFields restart changes pod template so that pods are rolled out as per workload's update strategy
+func setWorkloadActionFields(object map[string]interface{}, action string, replicas *int64, now time.Time) error {
+	switch action {
+	case WORKLOAD_ACTION_RESTART:
+		return unstructured.SetNestedField(object, now.Format(time.RFC3339), "spec", "template", "metadata", "annotations", RESTARTED_AT_ANNOTATION)
+	case WORKLOAD_ACTION_SCALE:
+		if replicas == nil || *replicas < 0 {
+			return fmt.Errorf("invalid replicas")
+		}
+		return unstructured.SetNestedField(object, *replicas, "spec", "replicas")
+	}
+	return fmt.Errorf("unsupported workload action %s", action)
+}
+
+func getReplicas(object map[string]interface{}) (*int64, error) {
+	replicas, found, err := unstructured.NestedInt64(object, "spec", "replicas")
+	if err != nil || !found {
+		return nil, err
+	}
+	return &replicas, nil
+}
+
+// isWorkloadOfDevtronApp reference charts label pod template with app and env ids
+func isWorkloadOfDevtronApp(object map[string]interface{}, appId int, envId int) bool {
+	podLabels, _, _ := unstructured.NestedStringMap(object, "spec", "template", "metadata", "labels")
+	return podLabels[k8s.DEVTRON_APP_ID_LABEL] == strconv.Itoa(appId) && podLabels[k8s.DEVTRON_ENV_ID_LABEL] == strconv.Itoa(envId)
+}
+
+func isAutoscalingEnabled(values json.RawMessage) bool {
+	var parsed struct {
+		Autoscaling struct {
+			Enabled bool `json:"enabled"`
+		} `json:"autoscaling"`
+	}
+	_ = json.Unmarshal(values, &parsed)
+	return parsed.Autoscaling.Enabled
+}
@@ -0,0 +1,94 @@
+package workloadAction
+
+import (
+	"encoding/json"
+	"github.com/devtron-labs/devtron/pkg/pipeline"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+	"time"
+)
+
+func newTestWorkload(replicas int64, podLabels map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "api"},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{"metadata": map[string]interface{}{"labels": podLabels}},
+		},
+	}
+}
+
+func TestValidateActionKind(t *testing.T) {
+	if validateActionKind(WORKLOAD_ACTION_RESTART, "DaemonSet") != nil || validateActionKind(WORKLOAD_ACTION_SCALE, "Rollout") != nil {
+		t.Errorf("expected supported kinds to be accepted")
+	}
+	if validateActionKind(WORKLOAD_ACTION_SCALE, "DaemonSet") == nil || validateActionKind(WORKLOAD_ACTION_RESTART, "ReplicaSet") == nil {
+		t.Errorf("expected unsupported kinds to be rejected")
+	}
+	if validateActionKind("DELETE", "Deployment") == nil {
+		t.Errorf("expected unsupported action to be rejected")
+	}
+}
+
+func TestSetWorkloadActionFields(t *testing.T) {
+	object := newTestWorkload(2, nil)
+	now := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	if err := setWorkloadActionFields(object, WORKLOAD_ACTION_RESTART, nil, now); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	restartedAt, _, _ := unstructured.NestedString(object, "spec", "template", "metadata", "annotations", RESTARTED_AT_ANNOTATION)
+	if restartedAt != "2023-03-01T10:00:00Z" {
+		t.Errorf("expected restartedAt annotation on pod template, got %q", restartedAt)
+	}
+	replicas := int64(0)
+	if err := setWorkloadActionFields(object, WORKLOAD_ACTION_SCALE, &replicas, now); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if current, _ := getReplicas(object); current == nil || *current != 0 {
+		t.Errorf("expected workload to be scaled to zero, got %v", current)
+	}
+	if err := setWorkloadActionFields(object, WORKLOAD_ACTION_SCALE, nil, now); err == nil {
+		t.Errorf("expected scale without replicas to be rejected")
+	}
+}
+
+func TestIsWorkloadOfDevtronApp(t *testing.T) {
+	object := newTestWorkload(1, map[string]interface{}{"appId": "12", "envId": "3"})
+	if !isWorkloadOfDevtronApp(object, 12, 3) {
+		t.Errorf("expected workload labelled with app and env to belong to app")
+	}
+	if isWorkloadOfDevtronApp(object, 12, 4) || isWorkloadOfDevtronApp(newTestWorkload(1, nil), 12, 3) {
+		t.Errorf("expected workload of other env or without labels to be rejected")
+	}
+}
+
+func TestIsAutoscalingEnabled(t *testing.T) {
+	if !isAutoscalingEnabled(json.RawMessage(`{"replicaCount":1,"autoscaling":{"enabled":true,"MinReplicas":1}}`)) {
+		t.Errorf("expected autoscaling to be detected")
+	}
+	if isAutoscalingEnabled(json.RawMessage(`{"replicaCount":1}`)) || isAutoscalingEnabled(nil) {
+		t.Errorf("expected autoscaling to be disabled by default")
+	}
+}
+
+func TestOverrideValuesWithReplicaCount(t *testing.T) {
+	envProperties := &pipeline.EnvironmentPropertiesResponse{GlobalConfig: json.RawMessage(`{"replicaCount":1}`)}
+	if _, err := overrideValuesWithReplicaCount(envProperties, 3); err == nil {
+		t.Errorf("expected env override not to be created when environment inherits base template")
+	}
+	envProperties.IsOverride = true
+	envProperties.EnvironmentConfig.EnvOverrideValues = json.RawMessage(`{"replicaCount":1,"image":"api"}`)
+	values, err := overrideValuesWithReplicaCount(envProperties, 3)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	overrideValues := map[string]interface{}{}
+	if err = json.Unmarshal(values, &overrideValues); err != nil || overrideValues["replicaCount"] != float64(3) || overrideValues["image"] != "api" {
+		t.Errorf("expected replicaCount to be merged in env override, got %s", values)
+	}
+	envProperties.EnvironmentConfig.EnvOverrideValues = json.RawMessage(`{"autoscaling":{"enabled":true}}`)
+	if _, err = overrideValuesWithReplicaCount(envProperties, 3); err == nil {
+		t.Errorf("expected replicas managed by autoscaling to be rejected")
+	}
+}
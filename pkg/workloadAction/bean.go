@@ -0,0 +1,79 @@
+package workloadAction
+
+import (
+	"time"
+)
+
+const (
+	WORKLOAD_ACTION_RESTART = "RESTART"
+	WORKLOAD_ACTION_SCALE   = "SCALE"
+)
+
+const (
+	WORKLOAD_ACTION_STATUS_SUCCEEDED = "Succeeded"
+	WORKLOAD_ACTION_STATUS_FAILED    = "Failed"
+)
+
+// annotation set on pod template to trigger a rolling restart, same as kubectl rollout restart
+const RESTARTED_AT_ANNOTATION = "kubectl.kubernetes.io/restartedAt"
+
+// WorkloadResource is a workload of the app, Namespace is only used for helm apps and defaults to release namespace,
+// workloads of devtron apps are always looked up in namespace of the environment
+type WorkloadResource struct {
+	Group     string `json:"group"`
+	Version   string `json:"version" validate:"required"`
+	Kind      string `json:"kind" validate:"required"`
+	Name      string `json:"name" validate:"required"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// WorkloadActionRequest targets workloads of a devtron app on an environment (AppId and EnvId) or of a helm app
+// (HelmAppId as encoded by helm app service)
+type WorkloadActionRequest struct {
+	AppId     int                 `json:"appId"`
+	EnvId     int                 `json:"envId"`
+	HelmAppId string              `json:"helmAppId"`
+	Resources []*WorkloadResource `json:"resources" validate:"required,min=1,dive"`
+	UserId    int32               `json:"-"`
+}
+
+// WorkloadScaleRequest sets replicas of the workloads. When PersistInDeploymentTemplate is set for a devtron app
+// deployed through argo cd, replicaCount is saved in the existing env override and the latest artifact is redeployed
+// so that argo cd does not revert the scale.
+type WorkloadScaleRequest struct {
+	WorkloadActionRequest
+	Replicas                    *int64 `json:"replicas" validate:"required,min=0"`
+	PersistInDeploymentTemplate bool   `json:"persistInDeploymentTemplate"`
+}
+
+type WorkloadActionResult struct {
+	WorkloadResource
+	Status         string `json:"status"`
+	Message        string `json:"message,omitempty"`
+	ReplicasBefore *int64 `json:"replicasBefore,omitempty"`
+	ReplicasAfter  *int64 `json:"replicasAfter,omitempty"`
+}
+
+type WorkloadActionResponse struct {
+	Results   []*WorkloadActionResult `json:"results"`
+	Persisted bool                    `json:"persisted"`
+	// release of the redeploy triggered for persisted scale
+	ReleaseId int `json:"releaseId,omitempty"`
+}
+
+type WorkloadActionTimelineBean struct {
+	Id             int       `json:"id"`
+	Action         string    `json:"action"`
+	Group          string    `json:"group"`
+	Version        string    `json:"version"`
+	Kind           string    `json:"kind"`
+	Namespace      string    `json:"namespace"`
+	ResourceName   string    `json:"resourceName"`
+	ReplicasBefore *int64    `json:"replicasBefore,omitempty"`
+	ReplicasAfter  *int64    `json:"replicasAfter,omitempty"`
+	Persisted      bool      `json:"persisted"`
+	Status         string    `json:"status"`
+	Message        string    `json:"message,omitempty"`
+	CreatedOn      time.Time `json:"createdOn"`
+	CreatedBy      int32     `json:"createdBy"`
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"github.com/go-pg/pg"
+	"go.uber.org/zap"
+	"time"
+)
+
+type WorkloadActionTimeline struct {
+	tableName      struct{}  `sql:"workload_action_timeline" pg:",discard_unknown_columns"`
+	Id             int       `sql:"id,pk"`
+	AppId          int       `sql:"app_id"`
+	EnvId          int       `sql:"env_id"`
+	HelmAppId      string    `sql:"helm_app_id"`
+	ClusterId      int       `sql:"cluster_id,notnull"`
+	Namespace      string    `sql:"namespace"`
+	Action         string    `sql:"action,notnull"`
+	Group          string    `sql:"group"`
+	Version        string    `sql:"version"`
+	Kind           string    `sql:"kind,notnull"`
+	ResourceName   string    `sql:"resource_name,notnull"`
+	ReplicasBefore *int64    `sql:"replicas_before"`
+	ReplicasAfter  *int64    `sql:"replicas_after"`
+	Persisted      bool      `sql:"persisted,notnull"`
+	Status         string    `sql:"status,notnull"`
+	Message        string    `sql:"message"`
+	CreatedOn      time.Time `sql:"created_on,notnull"`
+	CreatedBy      int32     `sql:"created_by,notnull"`
+}
+
+type WorkloadActionRepository interface {
+	Save(model *WorkloadActionTimeline) error
+	FindByAppIdAndEnvId(appId int, envId int, offset int, size int) ([]*WorkloadActionTimeline, error)
+	FindByHelmAppId(helmAppId string, offset int, size int) ([]*WorkloadActionTimeline, error)
+}
+
+type WorkloadActionRepositoryImpl struct {
+	dbConnection *pg.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewWorkloadActionRepositoryImpl(dbConnection *pg.DB, logger *zap.SugaredLogger) *WorkloadActionRepositoryImpl {
+	return &WorkloadActionRepositoryImpl{
+		dbConnection: dbConnection,
+		logger:       logger,
+	}
+}
+
+func (repo WorkloadActionRepositoryImpl) Save(model *WorkloadActionTimeline) error {
+	return repo.dbConnection.Insert(model)
+}
+
+func (repo WorkloadActionRepositoryImpl) FindByAppIdAndEnvId(appId int, envId int, offset int, size int) ([]*WorkloadActionTimeline, error) {
+	var models []*WorkloadActionTimeline
+	err := repo.dbConnection.Model(&models).
+		Where("app_id = ?", appId).
+		Where("env_id = ?", envId).
+		Order("created_on DESC").
+		Offset(offset).
+		Limit(size).
+		Select()
+	return models, err
+}
+
+func (repo WorkloadActionRepositoryImpl) FindByHelmAppId(helmAppId string, offset int, size int) ([]*WorkloadActionTimeline, error) {
+	var models []*WorkloadActionTimeline
+	err := repo.dbConnection.Model(&models).
+		Where("helm_app_id = ?", helmAppId).
+		Order("created_on DESC").
+		Offset(offset).
+		Limit(size).
+		Select()
+	return models, err
+}
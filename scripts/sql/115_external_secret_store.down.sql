@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "public"."external_secret_store_env_type_unique";
+
+DROP TABLE IF EXISTS "public"."external_secret_store";
+
+---- DROP sequence
+DROP SEQUENCE IF EXISTS public.id_seq_external_secret_store;
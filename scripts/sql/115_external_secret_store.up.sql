@@ -0,0 +1,21 @@
+-- Sequence and defined type
+CREATE SEQUENCE IF NOT EXISTS id_seq_external_secret_store;
+
+-- Table Definition
+CREATE TABLE IF NOT EXISTS "public"."external_secret_store"
+(
+    "id"               int4         NOT NULL DEFAULT nextval('id_seq_external_secret_store'::regclass),
+    "environment_id"   int4         NOT NULL,
+    "external_type"    varchar(50)  NOT NULL,
+    "provider"         TEXT,
+    "secret_store_ref" TEXT,
+    "active"           bool         NOT NULL,
+    "created_on"       timestamptz  NOT NULL,
+    "created_by"       int4         NOT NULL,
+    "updated_on"       timestamptz,
+    "updated_by"       int4,
+    CONSTRAINT "external_secret_store_environment_id_fkey" FOREIGN KEY ("environment_id") REFERENCES "public"."environment" ("id"),
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "external_secret_store_env_type_unique" ON "public"."external_secret_store" ("environment_id", "external_type") WHERE "active" = true;
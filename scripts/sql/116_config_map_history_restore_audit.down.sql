@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "public"."config_map_history_restore_audit";
+
+---- DROP sequence
+DROP SEQUENCE IF EXISTS public.id_seq_config_map_history_restore_audit;
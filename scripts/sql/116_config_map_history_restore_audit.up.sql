@@ -0,0 +1,22 @@
+-- Sequence and defined type
+CREATE SEQUENCE IF NOT EXISTS id_seq_config_map_history_restore_audit;
+
+-- Table Definition
+CREATE TABLE IF NOT EXISTS "public"."config_map_history_restore_audit"
+(
+    "id"                    int4         NOT NULL DEFAULT nextval('id_seq_config_map_history_restore_audit'::regclass),
+    "app_id"                int4         NOT NULL,
+    "environment_id"        int4,
+    "data_type"             varchar(50)  NOT NULL,
+    "name"                  varchar(250) NOT NULL,
+    "history_id"            int4         NOT NULL,
+    "redeployed"            bool         NOT NULL DEFAULT false,
+    "release_id"            int4,
+    "created_on"            timestamptz  NOT NULL,
+    "created_by"            int4         NOT NULL,
+    "updated_on"            timestamptz,
+    "updated_by"            int4,
+    CONSTRAINT "config_map_history_restore_audit_app_id_fkey" FOREIGN KEY ("app_id") REFERENCES "public"."app" ("id"),
+    CONSTRAINT "config_map_history_restore_audit_history_id_fkey" FOREIGN KEY ("history_id") REFERENCES "public"."config_map_history" ("id"),
+    PRIMARY KEY ("id")
+);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS global_cm_cs_history;
+DROP SEQUENCE IF EXISTS id_seq_global_cm_cs_history;
+
+ALTER TABLE global_cm_cs DROP COLUMN IF EXISTS selectors;
+ALTER TABLE global_cm_cs DROP COLUMN IF EXISTS use_in_stages;
+ALTER TABLE global_cm_cs DROP COLUMN IF EXISTS version;
@@ -0,0 +1,22 @@
+ALTER TABLE global_cm_cs ADD COLUMN IF NOT EXISTS selectors text;
+ALTER TABLE global_cm_cs ADD COLUMN IF NOT EXISTS use_in_stages text[];
+ALTER TABLE global_cm_cs ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
+--until this release global cm/cs were used only in ci workflows
+UPDATE global_cm_cs SET use_in_stages = '{CI}';
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_global_cm_cs_history;
+
+CREATE TABLE IF NOT EXISTS public.global_cm_cs_history
+(
+    "id"              integer NOT NULL DEFAULT nextval('id_seq_global_cm_cs_history'::regclass),
+    "global_cm_cs_id" integer NOT NULL,
+    "version"         integer NOT NULL,
+    "action"          text    NOT NULL,
+    "config"          text,
+    "created_on"      timestamptz,
+    "created_by"      int4,
+    "updated_on"      timestamptz,
+    "updated_by"      int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT global_cm_cs_history_global_cm_cs_id_fkey FOREIGN KEY ("global_cm_cs_id") REFERENCES "public"."global_cm_cs" ("id")
+);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS scoped_variable_snapshot;
+DROP SEQUENCE IF EXISTS id_seq_scoped_variable_snapshot;
+DROP TABLE IF EXISTS scoped_variable_value;
+DROP SEQUENCE IF EXISTS id_seq_scoped_variable_value;
+DROP TABLE IF EXISTS scoped_variable;
+DROP SEQUENCE IF EXISTS id_seq_scoped_variable;
@@ -0,0 +1,54 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_scoped_variable;
+
+CREATE TABLE IF NOT EXISTS public.scoped_variable
+(
+    "id"          integer NOT NULL DEFAULT nextval('id_seq_scoped_variable'::regclass),
+    "name"        varchar(250) NOT NULL,
+    "description" text,
+    "active"      bool    NOT NULL DEFAULT TRUE,
+    "created_on"  timestamptz,
+    "created_by"  int4,
+    "updated_on"  timestamptz,
+    "updated_by"  int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS scoped_variable_active_name_idx ON scoped_variable (name) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_scoped_variable_value;
+
+CREATE TABLE IF NOT EXISTS public.scoped_variable_value
+(
+    "id"          integer NOT NULL DEFAULT nextval('id_seq_scoped_variable_value'::regclass),
+    "variable_id" integer NOT NULL,
+    "scope_type"  varchar(50) NOT NULL,
+    "scope_id"    integer NOT NULL DEFAULT 0,
+    "value"       text,
+    "active"      bool    NOT NULL DEFAULT TRUE,
+    "created_on"  timestamptz,
+    "created_by"  int4,
+    "updated_on"  timestamptz,
+    "updated_by"  int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT scoped_variable_value_variable_id_fkey FOREIGN KEY ("variable_id") REFERENCES "public"."scoped_variable" ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_scoped_variable_snapshot;
+
+CREATE TABLE IF NOT EXISTS public.scoped_variable_snapshot
+(
+    "id"                    integer NOT NULL DEFAULT nextval('id_seq_scoped_variable_snapshot'::regclass),
+    "app_id"                integer NOT NULL,
+    "environment_id"        integer,
+    "pipeline_id"           integer,
+    "cd_workflow_runner_id" integer,
+    "stage"                 varchar(50) NOT NULL,
+    "variables"             text,
+    "created_on"            timestamptz,
+    "created_by"            int4,
+    "updated_on"            timestamptz,
+    "updated_by"            int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS scoped_variable_snapshot_wfr_idx ON scoped_variable_snapshot (app_id, cd_workflow_runner_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS bulk_update_job_item;
+DROP SEQUENCE IF EXISTS id_seq_bulk_update_job_item;
+DROP TABLE IF EXISTS bulk_update_job;
+DROP SEQUENCE IF EXISTS id_seq_bulk_update_job;
@@ -0,0 +1,41 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_bulk_update_job;
+
+CREATE TABLE IF NOT EXISTS public.bulk_update_job
+(
+    "id"          integer NOT NULL DEFAULT nextval('id_seq_bulk_update_job'::regclass),
+    "status"      varchar(50) NOT NULL,
+    "payload"     text,
+    "response"    text,
+    "started_on"  timestamptz,
+    "finished_on" timestamptz,
+    "created_on"  timestamptz,
+    "created_by"  int4,
+    "updated_on"  timestamptz,
+    "updated_by"  int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_bulk_update_job_item;
+
+--before and after state of every object changed by a job, secret states are encrypted like secret_data
+CREATE TABLE IF NOT EXISTS public.bulk_update_job_item
+(
+    "id"           integer NOT NULL DEFAULT nextval('id_seq_bulk_update_job_item'::regclass),
+    "job_id"       integer NOT NULL,
+    "object_type"  varchar(50) NOT NULL,
+    "object_id"    integer NOT NULL,
+    "app_id"       integer NOT NULL,
+    "app_name"     varchar(250),
+    "env_id"       integer NOT NULL DEFAULT 0,
+    "before_state" text,
+    "after_state"  text,
+    "reverted"     bool    NOT NULL DEFAULT FALSE,
+    "created_on"   timestamptz,
+    "created_by"   int4,
+    "updated_on"   timestamptz,
+    "updated_by"   int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT bulk_update_job_item_job_id_fkey FOREIGN KEY ("job_id") REFERENCES "public"."bulk_update_job" ("id")
+);
+
+CREATE INDEX IF NOT EXISTS bulk_update_job_item_job_id_idx ON bulk_update_job_item (job_id);
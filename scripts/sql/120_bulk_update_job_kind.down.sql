@@ -0,0 +1 @@
+ALTER TABLE bulk_update_job DROP COLUMN IF EXISTS kind;
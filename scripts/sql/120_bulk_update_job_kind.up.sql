@@ -0,0 +1 @@
+ALTER TABLE bulk_update_job ADD COLUMN IF NOT EXISTS kind VARCHAR(50) NOT NULL DEFAULT 'application';
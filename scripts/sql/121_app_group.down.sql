@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS app_group_mapping;
+DROP SEQUENCE IF EXISTS id_seq_app_group_mapping;
+DROP TABLE IF EXISTS app_group;
+DROP SEQUENCE IF EXISTS id_seq_app_group;
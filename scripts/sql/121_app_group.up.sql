@@ -0,0 +1,37 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_app_group;
+
+--members of a group are the apps mapped to it and the apps matching its selector
+CREATE TABLE IF NOT EXISTS public.app_group
+(
+    "id"          integer NOT NULL DEFAULT nextval('id_seq_app_group'::regclass),
+    "name"        varchar(250) NOT NULL,
+    "description" text,
+    "selector"    text,
+    "active"      bool    NOT NULL DEFAULT TRUE,
+    "created_on"  timestamptz,
+    "created_by"  int4,
+    "updated_on"  timestamptz,
+    "updated_by"  int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS app_group_active_name_idx ON app_group (LOWER(name)) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_app_group_mapping;
+
+CREATE TABLE IF NOT EXISTS public.app_group_mapping
+(
+    "id"           integer NOT NULL DEFAULT nextval('id_seq_app_group_mapping'::regclass),
+    "app_group_id" integer NOT NULL,
+    "app_id"       integer NOT NULL,
+    "active"       bool    NOT NULL DEFAULT TRUE,
+    "created_on"   timestamptz,
+    "created_by"   int4,
+    "updated_on"   timestamptz,
+    "updated_by"   int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT app_group_mapping_app_group_id_fkey FOREIGN KEY ("app_group_id") REFERENCES "public"."app_group" ("id"),
+    CONSTRAINT app_group_mapping_app_id_fkey FOREIGN KEY ("app_id") REFERENCES "public"."app" ("id")
+);
+
+CREATE INDEX IF NOT EXISTS app_group_mapping_app_group_id_idx ON app_group_mapping (app_group_id) WHERE active = true;
@@ -0,0 +1,59 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_app_template;
+
+CREATE TABLE IF NOT EXISTS public.app_template
+(
+    "id"             integer NOT NULL DEFAULT nextval('id_seq_app_template'::regclass),
+    "name"           varchar(250) NOT NULL,
+    "description"    text,
+    "latest_version" integer NOT NULL,
+    "active"         bool    NOT NULL DEFAULT TRUE,
+    "created_on"     timestamptz,
+    "created_by"     int4,
+    "updated_on"     timestamptz,
+    "updated_by"     int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS app_template_active_name_idx ON app_template (name) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_app_template_version;
+
+--versions are immutable, every update of a template adds a new version
+CREATE TABLE IF NOT EXISTS public.app_template_version
+(
+    "id"              integer NOT NULL DEFAULT nextval('id_seq_app_template_version'::regclass),
+    "app_template_id" integer NOT NULL,
+    "version"         integer NOT NULL,
+    "parameters"      text,
+    "template_data"   text    NOT NULL,
+    "ref_app_id"      integer,
+    "created_on"      timestamptz,
+    "created_by"      int4,
+    "updated_on"      timestamptz,
+    "updated_by"      int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT app_template_version_app_template_id_fkey FOREIGN KEY ("app_template_id") REFERENCES "public"."app_template" ("id"),
+    UNIQUE ("app_template_id", "version")
+);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_app_template_app;
+
+--template version and parameter values with which an app was created
+CREATE TABLE IF NOT EXISTS public.app_template_app
+(
+    "id"               integer NOT NULL DEFAULT nextval('id_seq_app_template_app'::regclass),
+    "app_template_id"  integer NOT NULL,
+    "app_id"           integer NOT NULL,
+    "version"          integer NOT NULL,
+    "parameter_values" text,
+    "created_on"       timestamptz,
+    "created_by"       int4,
+    "updated_on"       timestamptz,
+    "updated_by"       int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT app_template_app_app_template_id_fkey FOREIGN KEY ("app_template_id") REFERENCES "public"."app_template" ("id"),
+    CONSTRAINT app_template_app_app_id_fkey FOREIGN KEY ("app_id") REFERENCES "public"."app" ("id"),
+    UNIQUE ("app_id")
+);
+
+CREATE INDEX IF NOT EXISTS app_template_app_app_template_id_idx ON app_template_app (app_template_id);
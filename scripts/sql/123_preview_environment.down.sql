@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS preview_environment;
+DROP SEQUENCE IF EXISTS id_seq_preview_environment;
+DROP TABLE IF EXISTS preview_env_config;
+DROP SEQUENCE IF EXISTS id_seq_preview_env_config;
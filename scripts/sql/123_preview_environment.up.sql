@@ -0,0 +1,60 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_preview_env_config;
+
+--one preview config per app, previews are created for pull requests built by ci_pipeline_id
+CREATE TABLE IF NOT EXISTS public.preview_env_config
+(
+    "id"              integer NOT NULL DEFAULT nextval('id_seq_preview_env_config'::regclass),
+    "app_id"          integer NOT NULL,
+    "ci_pipeline_id"  integer NOT NULL,
+    "source_env_id"   integer NOT NULL,
+    "cluster_id"      integer NOT NULL,
+    "env_name_prefix" varchar(50),
+    "url_template"    text,
+    "callback_url"    text,
+    "ttl_hours"       integer NOT NULL,
+    "override_values" text,
+    "rewrites"        text,
+    "active"          bool    NOT NULL DEFAULT TRUE,
+    "created_on"      timestamptz,
+    "created_by"      int4,
+    "updated_on"      timestamptz,
+    "updated_by"      int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT preview_env_config_app_id_fkey FOREIGN KEY ("app_id") REFERENCES "public"."app" ("id"),
+    CONSTRAINT preview_env_config_ci_pipeline_id_fkey FOREIGN KEY ("ci_pipeline_id") REFERENCES "public"."ci_pipeline" ("id"),
+    CONSTRAINT preview_env_config_source_env_id_fkey FOREIGN KEY ("source_env_id") REFERENCES "public"."environment" ("id"),
+    CONSTRAINT preview_env_config_cluster_id_fkey FOREIGN KEY ("cluster_id") REFERENCES "public"."cluster" ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS preview_env_config_active_app_id_idx ON preview_env_config (app_id) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_preview_environment;
+
+CREATE TABLE IF NOT EXISTS public.preview_environment
+(
+    "id"                    integer NOT NULL DEFAULT nextval('id_seq_preview_environment'::regclass),
+    "preview_env_config_id" integer NOT NULL,
+    "app_id"                integer NOT NULL,
+    "pr_unique_id"          varchar(250) NOT NULL,
+    "pr_title"              text,
+    "source_branch"         varchar(250),
+    "head_commit"           varchar(250),
+    "environment_id"        integer,
+    "pipeline_id"           integer,
+    "namespace"             varchar(250),
+    "url"                   text,
+    "status"                varchar(50) NOT NULL,
+    "message"               text,
+    "ci_artifact_id"        integer,
+    "expires_on"            timestamptz NOT NULL,
+    "active"                bool    NOT NULL DEFAULT TRUE,
+    "created_on"            timestamptz,
+    "created_by"            int4,
+    "updated_on"            timestamptz,
+    "updated_by"            int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT preview_environment_preview_env_config_id_fkey FOREIGN KEY ("preview_env_config_id") REFERENCES "public"."preview_env_config" ("id"),
+    CONSTRAINT preview_environment_app_id_fkey FOREIGN KEY ("app_id") REFERENCES "public"."app" ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS preview_environment_active_pr_idx ON preview_environment (preview_env_config_id, pr_unique_id) WHERE active = true;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS hibernation_schedule_history;
+DROP SEQUENCE IF EXISTS id_seq_hibernation_schedule_history;
+DROP TABLE IF EXISTS hibernation_schedule;
+DROP SEQUENCE IF EXISTS id_seq_hibernation_schedule;
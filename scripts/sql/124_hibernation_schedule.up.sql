@@ -0,0 +1,44 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_hibernation_schedule;
+
+--target is the json of scope specific targets, cron expressions are evaluated in timezone
+CREATE TABLE IF NOT EXISTS public.hibernation_schedule
+(
+    "id"                  integer      NOT NULL DEFAULT nextval('id_seq_hibernation_schedule'::regclass),
+    "name"                varchar(250) NOT NULL,
+    "scope"               varchar(50)  NOT NULL,
+    "target"              text         NOT NULL,
+    "hibernate_cron"      varchar(100) NOT NULL,
+    "wakeup_cron"         varchar(100) NOT NULL,
+    "timezone"            varchar(100) NOT NULL,
+    "state"               varchar(50),
+    "skip_next"           bool         NOT NULL DEFAULT FALSE,
+    "extended_until"      timestamptz,
+    "hibernation_pending" bool         NOT NULL DEFAULT FALSE,
+    "active"              bool         NOT NULL DEFAULT TRUE,
+    "created_on"          timestamptz,
+    "created_by"          int4,
+    "updated_on"          timestamptz,
+    "updated_by"          int4,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS hibernation_schedule_active_name_idx ON hibernation_schedule (name) WHERE active = true;
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_hibernation_schedule_history;
+
+CREATE TABLE IF NOT EXISTS public.hibernation_schedule_history
+(
+    "id"                      integer     NOT NULL DEFAULT nextval('id_seq_hibernation_schedule_history'::regclass),
+    "hibernation_schedule_id" integer     NOT NULL,
+    "action"                  varchar(50) NOT NULL,
+    "trigger"                 varchar(50) NOT NULL,
+    "status"                  varchar(50) NOT NULL,
+    "message"                 text,
+    "response"                text,
+    "executed_on"             timestamptz NOT NULL,
+    "executed_by"             int4,
+    PRIMARY KEY ("id"),
+    CONSTRAINT hibernation_schedule_history_schedule_id_fkey FOREIGN KEY ("hibernation_schedule_id") REFERENCES "public"."hibernation_schedule" ("id")
+);
+
+CREATE INDEX IF NOT EXISTS hibernation_schedule_history_schedule_id_idx ON hibernation_schedule_history (hibernation_schedule_id);
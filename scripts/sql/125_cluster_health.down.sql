@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS cluster_health_check;
+DROP SEQUENCE IF EXISTS id_seq_cluster_health_check;
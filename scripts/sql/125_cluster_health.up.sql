@@ -0,0 +1,26 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_cluster_health_check;
+
+--one row per periodic check of a cluster, latest row of a cluster is its current health
+CREATE TABLE IF NOT EXISTS public.cluster_health_check
+(
+    "id"                 integer     NOT NULL DEFAULT nextval('id_seq_cluster_health_check'::regclass),
+    "cluster_id"         integer     NOT NULL,
+    "status"             varchar(50) NOT NULL,
+    "reachable"          bool        NOT NULL DEFAULT FALSE,
+    "latency_ms"         integer,
+    "server_version"     varchar(100),
+    "cert_expiry"        timestamptz,
+    "token_expiry"       timestamptz,
+    "nodes_total"        integer,
+    "nodes_ready"        integer,
+    "argo_cd_reachable"  bool,
+    "argo_cd_skipped"    bool        NOT NULL DEFAULT FALSE,
+    "argo_cd_message"    text,
+    "message"            text,
+    "status_changed"     bool        NOT NULL DEFAULT FALSE,
+    "checked_on"         timestamptz NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT cluster_health_check_cluster_id_fkey FOREIGN KEY ("cluster_id") REFERENCES "public"."cluster" ("id")
+);
+
+CREATE INDEX IF NOT EXISTS cluster_health_check_cluster_id_checked_on_idx ON cluster_health_check (cluster_id, checked_on DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS kubernetes_resource_audit_log;
+DROP SEQUENCE IF EXISTS id_seq_kubernetes_resource_audit_log;
@@ -0,0 +1,25 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_kubernetes_resource_audit_log;
+
+--one row per mutation of a kubernetes resource done through devtron, diff is json merge patch of the change
+CREATE TABLE IF NOT EXISTS public.kubernetes_resource_audit_log
+(
+    "id"            integer      NOT NULL DEFAULT nextval('id_seq_kubernetes_resource_audit_log'::regclass),
+    "cluster_id"    integer      NOT NULL,
+    "group"         varchar(250),
+    "version"       varchar(100),
+    "kind"          varchar(250) NOT NULL,
+    "namespace"     varchar(250),
+    "resource_name" varchar(250) NOT NULL,
+    "action"        varchar(50)  NOT NULL,
+    "status"        varchar(50)  NOT NULL,
+    "message"       text,
+    "diff"          text,
+    "client_ip"     varchar(100),
+    "created_by"    integer,
+    "created_on"    timestamptz  NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS kubernetes_resource_audit_log_cluster_id_created_on_idx ON kubernetes_resource_audit_log (cluster_id, created_on DESC);
+CREATE INDEX IF NOT EXISTS kubernetes_resource_audit_log_created_by_idx ON kubernetes_resource_audit_log (created_by);
+CREATE INDEX IF NOT EXISTS kubernetes_resource_audit_log_created_on_idx ON kubernetes_resource_audit_log (created_on);
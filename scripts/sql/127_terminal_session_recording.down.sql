@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS terminal_session_recording_setting;
+DROP SEQUENCE IF EXISTS id_seq_terminal_session_recording_setting;
+DROP TABLE IF EXISTS terminal_session_recording;
+DROP SEQUENCE IF EXISTS id_seq_terminal_session_recording;
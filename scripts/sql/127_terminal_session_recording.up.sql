@@ -0,0 +1,43 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_terminal_session_recording;
+
+--one row per recorded pod exec or cluster terminal session, recording itself is an asciicast file on local disk or blob storage
+CREATE TABLE IF NOT EXISTS public.terminal_session_recording
+(
+    "id"             integer      NOT NULL DEFAULT nextval('id_seq_terminal_session_recording'::regclass),
+    "session_id"     varchar(100) NOT NULL,
+    "cluster_id"     integer,
+    "environment_id" integer,
+    "app_id"         integer,
+    "helm_app_id"    varchar(250),
+    "namespace"      varchar(250),
+    "pod_name"       varchar(250),
+    "container_name" varchar(250),
+    "user_id"        integer,
+    "storage_type"   varchar(50)  NOT NULL,
+    "file_path"      text         NOT NULL,
+    "status"         varchar(50)  NOT NULL,
+    "size"           bigint,
+    "started_on"     timestamptz  NOT NULL,
+    "ended_on"       timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS terminal_session_recording_cluster_id_started_on_idx ON terminal_session_recording (cluster_id, started_on DESC);
+CREATE INDEX IF NOT EXISTS terminal_session_recording_user_id_idx ON terminal_session_recording (user_id);
+CREATE INDEX IF NOT EXISTS terminal_session_recording_started_on_idx ON terminal_session_recording (started_on);
+
+CREATE SEQUENCE IF NOT EXISTS id_seq_terminal_session_recording_setting;
+
+--sessions of cluster (or only of environment when environment_id is set) are always recorded
+CREATE TABLE IF NOT EXISTS public.terminal_session_recording_setting
+(
+    "id"             integer     NOT NULL DEFAULT nextval('id_seq_terminal_session_recording_setting'::regclass),
+    "cluster_id"     integer     NOT NULL,
+    "environment_id" integer,
+    "active"         bool        NOT NULL,
+    "created_on"     timestamptz NOT NULL,
+    "created_by"     integer     NOT NULL,
+    "updated_on"     timestamptz NOT NULL,
+    "updated_by"     integer     NOT NULL,
+    PRIMARY KEY ("id")
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS workload_action_timeline;
+DROP SEQUENCE IF EXISTS id_seq_workload_action_timeline;
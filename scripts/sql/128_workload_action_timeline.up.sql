@@ -0,0 +1,28 @@
+CREATE SEQUENCE IF NOT EXISTS id_seq_workload_action_timeline;
+
+--one row per workload restarted or scaled from app details, devtron apps are identified by app_id and env_id, helm apps by helm_app_id
+CREATE TABLE IF NOT EXISTS public.workload_action_timeline
+(
+    "id"              integer      NOT NULL DEFAULT nextval('id_seq_workload_action_timeline'::regclass),
+    "app_id"          integer,
+    "env_id"          integer,
+    "helm_app_id"     varchar(500),
+    "cluster_id"      integer      NOT NULL,
+    "namespace"       varchar(250),
+    "action"          varchar(50)  NOT NULL,
+    "group"           varchar(250),
+    "version"         varchar(250),
+    "kind"            varchar(250) NOT NULL,
+    "resource_name"   varchar(250) NOT NULL,
+    "replicas_before" integer,
+    "replicas_after"  integer,
+    "persisted"       bool         NOT NULL DEFAULT false,
+    "status"          varchar(50)  NOT NULL,
+    "message"         text,
+    "created_on"      timestamptz  NOT NULL,
+    "created_by"      integer      NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS workload_action_timeline_app_id_env_id_idx ON workload_action_timeline (app_id, env_id);
+CREATE INDEX IF NOT EXISTS workload_action_timeline_helm_app_id_idx ON workload_action_timeline (helm_app_id);
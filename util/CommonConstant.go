@@ -17,6 +17,8 @@
 
 package util
 
+import "strings"
+
 const (
 	KubernetesSecret                    string = "KubernetesSecret"
 	AWSSecretsManager                   string = "AWSSecretsManager"
@@ -27,7 +29,21 @@ const (
 	ESOAzureSecretsManager              string = "ESO_AzureSecretsManager"
 	ESOHashiCorpVault                   string = "ESO_HashiCorpVault"
 	KubernetesExternalSecret            string = "KubernetesExternalSecret"
+	HashiCorpVaultKV2                   string = "HashiCorpVaultKV2"
+	GCPSecretManager                    string = "GCPSecretManager"
+	AzureKeyVault                       string = "AzureKeyVault"
 	ConfigMapSecretUsageTypeEnvironment string = "environment"
 	ConfigMapSecretUsageTypeVolume      string = "volume"
 	YamlSeparator                       string = "---\n"
 )
+
+// IsESOProviderType returns true for the first-class external secret providers whose secret store is
+// rendered by devtron from typed provider config (or the environment default) instead of raw ESO data
+func IsESOProviderType(externalType string) bool {
+	return externalType == HashiCorpVaultKV2 || externalType == GCPSecretManager || externalType == AzureKeyVault
+}
+
+// IsESOType returns true if the secret is synced through external secrets operator
+func IsESOType(externalType string) bool {
+	return strings.HasPrefix(externalType, "ESO") || IsESOProviderType(externalType)
+}
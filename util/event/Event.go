@@ -27,6 +27,7 @@ type PipelineType string
 
 const CI PipelineType = "CI"
 const CD PipelineType = "CD"
+const Cluster PipelineType = "CLUSTER"
 
 type Level string
 
@@ -2,8 +2,11 @@ package k8s
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	"time"
 )
 
 type ClusterCapacityDetail struct {
@@ -113,6 +116,195 @@ type NodeDrainHelper struct {
 	k8sClientSet    *kubernetes.Clientset
 }
 
+const (
+	NODE_DRAIN_PLAN_STATUS_READY   = "READY"
+	NODE_DRAIN_PLAN_STATUS_BLOCKED = "BLOCKED"
+
+	NODE_DRAIN_POD_ACTION_EVICT   = "EVICT"
+	NODE_DRAIN_POD_ACTION_SKIP    = "SKIP"
+	NODE_DRAIN_POD_ACTION_BLOCKED = "BLOCKED"
+)
+
+const (
+	NODE_MAINTENANCE_EVENT_PLAN          = "PLAN"
+	NODE_MAINTENANCE_EVENT_BATCH_STARTED = "BATCH_STARTED"
+	NODE_MAINTENANCE_EVENT_NODE_CORDONED = "NODE_CORDONED"
+	NODE_MAINTENANCE_EVENT_POD_EVICTED   = "POD_EVICTED"
+	NODE_MAINTENANCE_EVENT_NODE_DRAINED  = "NODE_DRAINED"
+	NODE_MAINTENANCE_EVENT_BLOCKED       = "BLOCKED"
+	NODE_MAINTENANCE_EVENT_FAILED        = "FAILED"
+	NODE_MAINTENANCE_EVENT_COMPLETED     = "COMPLETED"
+
+	NODE_MAINTENANCE_STATUS_COMPLETED = "COMPLETED"
+	NODE_MAINTENANCE_STATUS_BLOCKED   = "BLOCKED"
+	NODE_MAINTENANCE_STATUS_FAILED    = "FAILED"
+)
+
+// NodeMaintenanceRequest selects nodes either by name or by label selector (e.g. a nodepool label)
+type NodeMaintenanceRequest struct {
+	ClusterId    int      `json:"clusterId"`
+	NodeNames    []string `json:"nodeNames"`
+	NodeSelector string   `json:"nodeSelector"`
+	// Parallelism is the number of nodes drained together in one batch, defaults to 1
+	Parallelism int `json:"parallelism"`
+	// NodeTimeoutSeconds bounds eviction retries and pod termination wait per node, defaults to 600
+	NodeTimeoutSeconds int              `json:"nodeTimeoutSeconds"`
+	NodeDrainHelper    *NodeDrainHelper `json:"nodeDrainOptions"`
+}
+
+type NodeDrainPlan struct {
+	ClusterId int                  `json:"clusterId"`
+	Status    string               `json:"status"`
+	Nodes     []*NodeDrainPlanNode `json:"nodes"`
+	Batches   [][]string           `json:"batches"`
+	Blockers  []*NodeDrainBlocker  `json:"blockers"`
+}
+
+type NodeDrainPlanNode struct {
+	Name          string              `json:"name"`
+	Unschedulable bool                `json:"unschedulable"`
+	Batch         int                 `json:"batch"`
+	Status        string              `json:"status"`
+	Pods          []*NodeDrainPlanPod `json:"pods"`
+}
+
+type NodeDrainPlanPod struct {
+	Name                 string   `json:"name"`
+	Namespace            string   `json:"namespace"`
+	WorkloadKind         string   `json:"workloadKind"`
+	WorkloadName         string   `json:"workloadName"`
+	Replicas             *int32   `json:"replicas,omitempty"`
+	Action               string   `json:"action"`
+	Message              string   `json:"message,omitempty"`
+	PodDisruptionBudgets []string `json:"podDisruptionBudgets,omitempty"`
+	Warnings             []string `json:"warnings,omitempty"`
+	pod                  corev1.Pod
+}
+
+// NodeDrainBlocker is a workload which prevents draining of the listed nodes
+type NodeDrainBlocker struct {
+	NodeNames           []string `json:"nodeNames"`
+	Namespace           string   `json:"namespace"`
+	WorkloadKind        string   `json:"workloadKind"`
+	WorkloadName        string   `json:"workloadName"`
+	PodDisruptionBudget string   `json:"podDisruptionBudget,omitempty"`
+	DisruptionsAllowed  *int32   `json:"disruptionsAllowed,omitempty"`
+	Reason              string   `json:"reason"`
+}
+
+type NodeMaintenanceEvent struct {
+	Type      string                 `json:"type"`
+	Batch     int                    `json:"batch"`
+	NodeName  string                 `json:"nodeName,omitempty"`
+	Namespace string                 `json:"namespace,omitempty"`
+	PodName   string                 `json:"podName,omitempty"`
+	Message   string                 `json:"message,omitempty"`
+	Plan      *NodeDrainPlan         `json:"plan,omitempty"`
+	Result    *NodeMaintenanceResult `json:"result,omitempty"`
+	Time      time.Time              `json:"time"`
+}
+
+type NodeMaintenanceResult struct {
+	Status       string              `json:"status"`
+	DrainedNodes []string            `json:"drainedNodes"`
+	PendingNodes []string            `json:"pendingNodes"`
+	Blockers     []*NodeDrainBlocker `json:"blockers,omitempty"`
+	Message      string              `json:"message,omitempty"`
+}
+
+const (
+	RESOURCE_WATCH_EVENT_SNAPSHOT = "SNAPSHOT"
+	RESOURCE_WATCH_EVENT_ADDED    = "ADDED"
+	RESOURCE_WATCH_EVENT_MODIFIED = "MODIFIED"
+	RESOURCE_WATCH_EVENT_DELETED  = "DELETED"
+	// RESOURCE_WATCH_EVENT_RESYNC is sent when resume resource version is expired, a fresh snapshot follows it
+	RESOURCE_WATCH_EVENT_RESYNC = "RESYNC"
+)
+
+// ResourceListWatchEvent is a delta of resource browser list, rows are formatted same as ClusterResourceListMap
+type ResourceListWatchEvent struct {
+	Type            string                   `json:"type"`
+	ResourceVersion string                   `json:"resourceVersion"`
+	Name            string                   `json:"name,omitempty"`
+	Namespace       string                   `json:"namespace,omitempty"`
+	Headers         []string                 `json:"headers,omitempty"`
+	Data            []map[string]interface{} `json:"data,omitempty"`
+	Row             map[string]interface{}   `json:"row,omitempty"`
+}
+
+const (
+	EPHEMERAL_CONTAINER_NAME_PREFIX = "debugger"
+	// EPHEMERAL_CONTAINER_START_TIMEOUT is how long debug container is waited for to be running before attaching terminal
+	EPHEMERAL_CONTAINER_START_TIMEOUT = 2 * time.Minute
+)
+
+// EphemeralContainerRequest adds debug container to a running pod, AppId is set for helm release and ClusterId
+// for direct cluster access. TargetContainerName shares process namespace of that container when set.
+type EphemeralContainerRequest struct {
+	AppId               string   `json:"appId"`
+	ClusterId           int      `json:"clusterId"`
+	Namespace           string   `json:"namespace" validate:"required"`
+	PodName             string   `json:"podName" validate:"required"`
+	Image               string   `json:"image" validate:"required"`
+	TargetContainerName string   `json:"targetContainerName,omitempty"`
+	Command             []string `json:"command,omitempty"`
+}
+
+type EphemeralContainerResponse struct {
+	SessionID     string `json:"SessionID"`
+	ContainerName string `json:"containerName"`
+}
+
 const DEFAULT_NAMESPACE = "default"
 const EVENT_K8S_KIND = "Event"
 const LIST_VERB = "list"
+
+const (
+	// DEVTRON_APP_ID_LABEL and DEVTRON_ENV_ID_LABEL are set on pods by devtron reference charts
+	DEVTRON_APP_ID_LABEL = "appId"
+	DEVTRON_ENV_ID_LABEL = "envId"
+)
+
+// MultiPodLogsRequest selects pods either of a devtron app deployed in an environment (AppId and EnvId), of a workload
+// (WorkloadName and WorkloadGvk) or of a label selector. Pods matching later are picked up in follow mode.
+type MultiPodLogsRequest struct {
+	ClusterId     int
+	Namespace     string
+	AppId         int
+	EnvId         int
+	WorkloadName  string
+	WorkloadGvk   schema.GroupVersionKind
+	LabelSelector string
+	// ContainerName restricts logs to containers of this name, all containers are streamed when empty
+	ContainerName string
+	// Grep keeps lines containing it and Regex keeps lines matching it, both are applied when set
+	Grep         string
+	Regex        string
+	SinceSeconds int
+	SinceTime    *metav1.Time
+	TailLines    int
+	Follow       bool
+}
+
+type MultiPodLogLine struct {
+	PodName       string
+	ContainerName string
+	Time          time.Time
+	Log           string
+}
+
+// PortForwardRequest forwards Port of pod, for services port is service port and is forwarded to its target port on
+// a ready pod selected by the service
+type PortForwardRequest struct {
+	ClusterId int
+	Namespace string
+	Kind      string
+	Name      string
+	Port      int
+}
+
+// PortForwardTarget is the pod and container port resolved for PortForwardRequest
+type PortForwardTarget struct {
+	PodName string
+	Port    int
+}
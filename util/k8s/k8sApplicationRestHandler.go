@@ -11,6 +11,7 @@ import (
 	"github.com/devtron-labs/devtron/api/restHandler/common"
 	"github.com/devtron-labs/devtron/client/k8s/application"
 	util2 "github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
 	"github.com/devtron-labs/devtron/pkg/terminal"
 	"github.com/devtron-labs/devtron/pkg/user"
 	"github.com/devtron-labs/devtron/pkg/user/casbin"
@@ -18,14 +19,18 @@ import (
 	"github.com/devtron-labs/devtron/util/k8sObjectsUtil"
 	"github.com/devtron-labs/devtron/util/rbac"
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 	errors2 "github.com/juju/errors"
 	"go.uber.org/zap"
+	"io"
 	errors3 "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type K8sApplicationRestHandler interface {
@@ -41,6 +46,12 @@ type K8sApplicationRestHandler interface {
 	GetAllApiResources(w http.ResponseWriter, r *http.Request)
 	GetResourceList(w http.ResponseWriter, r *http.Request)
 	ApplyResources(w http.ResponseWriter, r *http.Request)
+	WatchResourceList(w http.ResponseWriter, r *http.Request)
+	DryRunApplyResources(w http.ResponseWriter, r *http.Request)
+	DryRunUpdateResource(w http.ResponseWriter, r *http.Request)
+	CreateEphemeralContainerSession(w http.ResponseWriter, r *http.Request)
+	GetMultiPodLogs(w http.ResponseWriter, r *http.Request)
+	PortForward(w http.ResponseWriter, r *http.Request)
 }
 
 type K8sApplicationRestHandlerImpl struct {
@@ -209,9 +220,14 @@ func (handler *K8sApplicationRestHandlerImpl) GetHostUrlsByBatch(w http.Response
 }
 
 func (handler *K8sApplicationRestHandlerImpl) CreateResource(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
 	decoder := json.NewDecoder(r.Body)
 	var request ResourceRequestBean
-	err := decoder.Decode(&request)
+	err = decoder.Decode(&request)
 	if err != nil {
 		handler.logger.Errorw("error in decoding request body", "err", err)
 		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
@@ -234,7 +250,7 @@ func (handler *K8sApplicationRestHandlerImpl) CreateResource(w http.ResponseWrit
 		return
 	}
 	//RBAC enforcer Ends
-	resource, err := handler.k8sApplicationService.CreateResource(r.Context(), &request)
+	resource, err := handler.k8sApplicationService.CreateResource(withAuditContext(r, userId), &request)
 	if err != nil {
 		handler.logger.Errorw("error in creating resource", "err", err)
 		common.WriteJsonResp(w, err, resource, http.StatusInternalServerError)
@@ -244,8 +260,34 @@ func (handler *K8sApplicationRestHandlerImpl) CreateResource(w http.ResponseWrit
 }
 
 func (handler *K8sApplicationRestHandlerImpl) UpdateResource(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request ResourceRequestBean
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("error in decoding request body", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if ok := handler.authorizeResourceUpdate(w, r, &request); !ok {
+		return
+	}
+
+	resource, err := handler.k8sApplicationService.UpdateResource(withAuditContext(r, userId), &request)
+	if err != nil {
+		handler.logger.Errorw("error in updating resource", "err", err)
+		common.WriteJsonResp(w, err, resource, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resource, http.StatusOK)
+}
+
+func (handler *K8sApplicationRestHandlerImpl) DryRunUpdateResource(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
-	token := r.Header.Get("token")
 	var request ResourceRequestBean
 	err := decoder.Decode(&request)
 	if err != nil {
@@ -253,14 +295,29 @@ func (handler *K8sApplicationRestHandlerImpl) UpdateResource(w http.ResponseWrit
 		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
 		return
 	}
+	if ok := handler.authorizeResourceUpdate(w, r, &request); !ok {
+		return
+	}
+
+	response, err := handler.k8sApplicationService.DryRunUpdateResource(r.Context(), &request)
+	if err != nil {
+		handler.logger.Errorw("error in dry run update of resource", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
 
+// authorizeResourceUpdate checks update access for helm release or direct cluster resource, error response is written when it returns false
+func (handler *K8sApplicationRestHandlerImpl) authorizeResourceUpdate(w http.ResponseWriter, r *http.Request, request *ResourceRequestBean) bool {
+	token := r.Header.Get("token")
 	if len(request.AppId) > 0 {
 		// assume it as helm release case in which appId is supplied
 		appIdentifier, err := handler.helmAppService.DecodeAppId(request.AppId)
 		if err != nil {
 			handler.logger.Errorw("error in decoding appId", "err", err, "appId", request.AppId)
 			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
-			return
+			return false
 		}
 		//setting appIdentifier value in request
 		request.AppIdentifier = appIdentifier
@@ -269,34 +326,26 @@ func (handler *K8sApplicationRestHandlerImpl) UpdateResource(w http.ResponseWrit
 		if err != nil || !valid {
 			handler.logger.Errorw("error in validating resource request", "err", err)
 			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
-			return
+			return false
 		}
 		// RBAC enforcer applying
 		rbacObject, rbacObject2 := handler.enforcerUtilHelm.GetHelmObjectByClusterIdNamespaceAndAppName(request.AppIdentifier.ClusterId, request.AppIdentifier.Namespace, request.AppIdentifier.ReleaseName)
-		token := r.Header.Get("token")
 		ok := handler.enforcer.Enforce(token, casbin.ResourceHelmApp, casbin.ActionUpdate, rbacObject) || handler.enforcer.Enforce(token, casbin.ResourceHelmApp, casbin.ActionUpdate, rbacObject2)
 		if !ok {
 			common.WriteJsonResp(w, errors2.New("unauthorized"), nil, http.StatusForbidden)
-			return
+			return false
 		}
 		//RBAC enforcer Ends
 	} else if request.ClusterId > 0 {
 		// assume direct update in cluster
-		if ok := handler.handleRbac(r, w, request, token, casbin.ActionUpdate); !ok {
-			return
+		if ok := handler.handleRbac(r, w, *request, token, casbin.ActionUpdate); !ok {
+			return false
 		}
 	} else {
 		common.WriteJsonResp(w, errors.New("can not update resource as target cluster is not provided"), nil, http.StatusBadRequest)
-		return
-	}
-
-	resource, err := handler.k8sApplicationService.UpdateResource(r.Context(), &request)
-	if err != nil {
-		handler.logger.Errorw("error in updating resource", "err", err)
-		common.WriteJsonResp(w, err, resource, http.StatusInternalServerError)
-		return
+		return false
 	}
-	common.WriteJsonResp(w, nil, resource, http.StatusOK)
+	return true
 }
 
 func (handler *K8sApplicationRestHandlerImpl) handleRbac(r *http.Request, w http.ResponseWriter, request ResourceRequestBean, token string, casbinAction string) bool {
@@ -367,7 +416,7 @@ func (handler *K8sApplicationRestHandlerImpl) DeleteResource(w http.ResponseWrit
 		return
 	}
 
-	resource, err := handler.k8sApplicationService.DeleteResource(r.Context(), &request, userId)
+	resource, err := handler.k8sApplicationService.DeleteResource(withAuditContext(r, userId), &request, userId)
 	if err != nil {
 		handler.logger.Errorw("error in deleting resource", "err", err)
 		common.WriteJsonResp(w, err, resource, http.StatusInternalServerError)
@@ -560,7 +609,12 @@ func (handler *K8sApplicationRestHandlerImpl) GetPodLogs(w http.ResponseWriter,
 }
 
 func (handler *K8sApplicationRestHandlerImpl) GetTerminalSession(w http.ResponseWriter, r *http.Request) {
-	request := &terminal.TerminalSessionRequest{}
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	request := &terminal.TerminalSessionRequest{UserId: userId}
 	vars := mux.Vars(r)
 	token := r.Header.Get("token")
 	request.ContainerName = vars["container"]
@@ -631,6 +685,85 @@ func (handler *K8sApplicationRestHandlerImpl) GetTerminalSession(w http.Response
 	common.WriteJsonResp(w, err, message, status)
 }
 
+// CreateEphemeralContainerSession injects debug container in pod and returns terminal session attached to it, used
+// for pods whose containers have no shell. Access is checked with debug action which is not granted along with exec.
+func (handler *K8sApplicationRestHandlerImpl) CreateEphemeralContainerSession(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	var request EphemeralContainerRequest
+	err = decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("error in decoding request body", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if len(request.Namespace) == 0 || len(request.PodName) == 0 || len(request.Image) == 0 {
+		common.WriteJsonResp(w, errors.New("namespace, podName and image are required"), nil, http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("token")
+	if len(request.AppId) > 0 {
+		app, err := handler.helmAppService.DecodeAppId(request.AppId)
+		if err != nil {
+			handler.logger.Errorw("invalid app id", "err", err, "appId", request.AppId)
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+		request.ClusterId = app.ClusterId
+		// RBAC enforcer applying
+		rbacObject, rbacObject2 := handler.enforcerUtilHelm.GetHelmObjectByClusterIdNamespaceAndAppName(app.ClusterId, app.Namespace, app.ReleaseName)
+		ok := handler.enforcer.Enforce(token, casbin.ResourceHelmApp, casbin.ActionDebug, rbacObject) || handler.enforcer.Enforce(token, casbin.ResourceHelmApp, casbin.ActionDebug, rbacObject2)
+		if !ok {
+			common.WriteJsonResp(w, errors2.New("unauthorized"), nil, http.StatusForbidden)
+			return
+		}
+		//RBAC enforcer Ends
+	} else if request.ClusterId > 0 {
+		resourceRequestBean := ResourceRequestBean{
+			ClusterId: request.ClusterId,
+			K8sRequest: &application.K8sRequestBean{
+				ResourceIdentifier: application.ResourceIdentifier{
+					Name:             request.PodName,
+					Namespace:        request.Namespace,
+					GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+				},
+			},
+		}
+		if ok := handler.handleRbac(r, w, resourceRequestBean, token, casbin.ActionDebug); !ok {
+			return
+		}
+	} else {
+		common.WriteJsonResp(w, errors.New("can not create debug container as target cluster is not provided"), nil, http.StatusBadRequest)
+		return
+	}
+
+	containerName, err := handler.k8sApplicationService.CreateEphemeralContainer(withAuditContext(r, userId), &request)
+	if err != nil {
+		handler.logger.Errorw("error in creating ephemeral container", "err", err, "podName", request.PodName, "namespace", request.Namespace)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	sessionRequest := &terminal.TerminalSessionRequest{
+		Namespace:     request.Namespace,
+		PodName:       request.PodName,
+		ContainerName: containerName,
+		ApplicationId: request.AppId,
+		ClusterId:     request.ClusterId,
+		UserId:        userId,
+		Attach:        true,
+	}
+	status, message, err := handler.terminalSessionHandler.GetTerminalSession(sessionRequest)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, status)
+		return
+	}
+	common.WriteJsonResp(w, nil, &EphemeralContainerResponse{SessionID: message.SessionID, ContainerName: containerName}, status)
+}
+
 func (handler *K8sApplicationRestHandlerImpl) GetResourceInfo(w http.ResponseWriter, r *http.Request) {
 	userId, err := handler.userService.GetLoggedInUser(r)
 	if userId == 0 || err != nil {
@@ -710,18 +843,400 @@ func (handler *K8sApplicationRestHandlerImpl) GetResourceList(w http.ResponseWri
 	common.WriteJsonResp(w, nil, response, http.StatusOK)
 }
 
+// WatchResourceList streams resource list deltas over sse, Last-Event-ID is the resource version to resume from.
+// Watch is stopped when client disconnects.
+func (handler *K8sApplicationRestHandlerImpl) WatchResourceList(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	token := r.Header.Get("token")
+	v := r.URL.Query()
+	clusterId, err := strconv.Atoi(v.Get("clusterId"))
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	if len(v.Get("version")) == 0 || len(v.Get("kind")) == 0 {
+		common.WriteJsonResp(w, errors.New("version and kind are required"), nil, http.StatusBadRequest)
+		return
+	}
+	request := &ResourceRequestBean{
+		ClusterId: clusterId,
+		K8sRequest: &application.K8sRequestBean{
+			ResourceIdentifier: application.ResourceIdentifier{
+				Namespace: v.Get("namespace"),
+				GroupVersionKind: schema.GroupVersionKind{
+					Group:   v.Get("group"),
+					Version: v.Get("version"),
+					Kind:    v.Get("kind"),
+				},
+			},
+		},
+	}
+	stream, ok := newSseStream(w)
+	if !ok {
+		common.WriteJsonResp(w, errors.New("unexpected server doesnt support streaming"), nil, http.StatusInternalServerError)
+		return
+	}
+	send := func(event *ResourceListWatchEvent) error {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return stream.writeEvent(event.ResourceVersion, event.Type, payload)
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	heartbeatDone := stream.startHeartbeat(ctx, cancel)
+	defer func() {
+		cancel()
+		<-heartbeatDone
+	}()
+	err = handler.k8sApplicationService.WatchResourceList(ctx, token, request, r.Header.Get("Last-Event-ID"), handler.verifyRbacForCluster, send)
+	if err != nil {
+		handler.logger.Errorw("error in watching resource list", "err", err, "clusterId", clusterId, "gvk", request.K8sRequest.ResourceIdentifier.GroupVersionKind)
+		if !stream.isStarted() {
+			if statusErr, ok := err.(*errors3.StatusError); ok && statusErr.Status().Code == 404 {
+				err = &util2.ApiError{Code: "404", HttpStatusCode: 404, UserMessage: "no resource found", InternalMessage: err.Error()}
+			}
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		_ = stream.writeEvent("", "UNEXPECTED_END_OF_STREAM", []byte(err.Error()))
+	}
+}
+
+// GetMultiPodLogs streams logs of all pods of a devtron app in an environment, of a workload or of a label selector.
+// Every line is sent as "[pod/container] log" with its timestamp as event id so that Last-Event-ID resumes stream.
+func (handler *K8sApplicationRestHandlerImpl) GetMultiPodLogs(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	token := r.Header.Get("token")
+	request, err := decodeMultiPodLogsRequest(r)
+	if err != nil {
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	var checkPodAccess func(clusterName string, resourceIdentifier application.ResourceIdentifier) bool
+	if request.AppId > 0 && request.EnvId > 0 {
+		// RBAC enforcer applying
+		appObject := handler.enforcerUtil.GetAppRBACNameByAppId(request.AppId)
+		envObject := handler.enforcerUtil.GetEnvRBACNameByAppId(request.AppId, request.EnvId)
+		if !handler.enforcer.Enforce(token, casbin.ResourceApplications, casbin.ActionGet, appObject) ||
+			!handler.enforcer.Enforce(token, casbin.ResourceEnvironment, casbin.ActionGet, envObject) {
+			common.WriteJsonResp(w, errors2.New("unauthorized"), nil, http.StatusForbidden)
+			return
+		}
+		//RBAC enforcer Ends
+	} else if request.ClusterId > 0 && len(request.Namespace) > 0 {
+		if len(request.WorkloadName) > 0 {
+			workloadRequest := ResourceRequestBean{
+				ClusterId: request.ClusterId,
+				K8sRequest: &application.K8sRequestBean{
+					ResourceIdentifier: application.ResourceIdentifier{
+						Name:             request.WorkloadName,
+						Namespace:        request.Namespace,
+						GroupVersionKind: request.WorkloadGvk,
+					},
+				},
+			}
+			if ok := handler.handleRbac(r, w, workloadRequest, token, casbin.ActionGet); !ok {
+				return
+			}
+		}
+		// pods are checked one by one as label selector may select pods which user can not access
+		checkPodAccess = handler.getRbacCallbackForResource(token, casbin.ActionGet)
+	} else {
+		common.WriteJsonResp(w, errors.New("can not get pod logs as app and environment or target cluster and namespace are not provided"), nil, http.StatusBadRequest)
+		return
+	}
+	if lastEventId := r.Header.Get("Last-Event-ID"); len(lastEventId) > 0 {
+		lastSeenMsgId, err := strconv.ParseInt(lastEventId, 10, 64)
+		if err != nil {
+			common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+			return
+		}
+		t := v1.Unix(0, lastSeenMsgId+1) //increased by one ns to avoid duplicate
+		request.SinceTime = &t
+	}
+
+	stream, ok := newSseStream(w)
+	if !ok {
+		common.WriteJsonResp(w, errors.New("unexpected server doesnt support streaming"), nil, http.StatusInternalServerError)
+		return
+	}
+	send := func(line *MultiPodLogLine) error {
+		eventId := ""
+		if !line.Time.IsZero() {
+			eventId = strconv.FormatInt(line.Time.UnixNano(), 10)
+		}
+		return stream.writeEvent(eventId, "", []byte(fmt.Sprintf("[%s/%s] %s", line.PodName, line.ContainerName, line.Log)))
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	heartbeatDone := stream.startHeartbeat(ctx, cancel)
+	defer func() {
+		cancel()
+		<-heartbeatDone
+	}()
+	err = handler.k8sApplicationService.StreamMultiPodLogs(ctx, request, checkPodAccess, send)
+	if err != nil {
+		handler.logger.Errorw("error in streaming multi pod logs", "err", err, "clusterId", request.ClusterId, "namespace", request.Namespace)
+		if !stream.isStarted() {
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		_ = stream.writeEvent("", "UNEXPECTED_END_OF_STREAM", []byte(err.Error()))
+	}
+}
+
+func decodeMultiPodLogsRequest(r *http.Request) (*MultiPodLogsRequest, error) {
+	v := r.URL.Query()
+	request := &MultiPodLogsRequest{
+		Namespace:    v.Get("namespace"),
+		WorkloadName: v.Get("name"),
+		WorkloadGvk: schema.GroupVersionKind{
+			Group:   v.Get("group"),
+			Version: v.Get("version"),
+			Kind:    v.Get("kind"),
+		},
+		LabelSelector: v.Get("labelSelector"),
+		ContainerName: v.Get("containerName"),
+		Grep:          v.Get("grep"),
+		Regex:         v.Get("regex"),
+		Follow:        v.Get("follow") == "true",
+	}
+	intParams := map[string]*int{"appId": &request.AppId, "envId": &request.EnvId, "clusterId": &request.ClusterId,
+		"sinceSeconds": &request.SinceSeconds, "tailLines": &request.TailLines}
+	for param, value := range intParams {
+		if len(v.Get(param)) == 0 {
+			continue
+		}
+		var err error
+		if *value, err = strconv.Atoi(v.Get(param)); err != nil || *value < 0 {
+			return nil, fmt.Errorf("invalid %s: %s", param, v.Get(param))
+		}
+	}
+	if len(request.WorkloadName) > 0 && (len(request.WorkloadGvk.Version) == 0 || len(request.WorkloadGvk.Kind) == 0) {
+		return nil, errors.New("version and kind are required for workload")
+	}
+	if _, err := newLogLineFilter(request.Grep, request.Regex); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// portForwardUpgrader keeps default origin check of websocket, token is also read from cookie so cross site pages
+// must not be able to open tunnels on behalf of logged in users
+var portForwardUpgrader = websocket.Upgrader{ReadBufferSize: 32 * 1024, WriteBufferSize: 32 * 1024}
+
+// PortForward tunnels one tcp connection to a port of pod or service over websocket, bytes are carried in binary
+// messages. Clients like local proxies open one websocket per accepted connection.
+func (handler *K8sApplicationRestHandlerImpl) PortForward(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
+	token := r.Header.Get("token")
+	v := r.URL.Query()
+	request := &PortForwardRequest{
+		Namespace: v.Get("namespace"),
+		Kind:      v.Get("kind"),
+		Name:      v.Get("name"),
+	}
+	if len(request.Kind) == 0 {
+		request.Kind = "Pod"
+	}
+	request.ClusterId, err = strconv.Atoi(v.Get("clusterId"))
+	if err != nil {
+		common.WriteJsonResp(w, fmt.Errorf("invalid clusterId: %s", v.Get("clusterId")), nil, http.StatusBadRequest)
+		return
+	}
+	request.Port, err = strconv.Atoi(v.Get("port"))
+	if err != nil {
+		common.WriteJsonResp(w, fmt.Errorf("invalid port: %s", v.Get("port")), nil, http.StatusBadRequest)
+		return
+	}
+	if len(request.Namespace) == 0 || len(request.Name) == 0 {
+		common.WriteJsonResp(w, errors.New("namespace and name are required"), nil, http.StatusBadRequest)
+		return
+	}
+	rbacRequest := ResourceRequestBean{
+		ClusterId: request.ClusterId,
+		K8sRequest: &application.K8sRequestBean{
+			ResourceIdentifier: application.ResourceIdentifier{
+				Name:             request.Name,
+				Namespace:        request.Namespace,
+				GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: request.Kind},
+			},
+		},
+	}
+	// same as terminal session, reaching into a pod needs update access on it
+	if ok := handler.handleRbac(r, w, rbacRequest, token, casbin.ActionUpdate); !ok {
+		return
+	}
+	target, err := handler.k8sApplicationService.ResolvePortForwardTarget(r.Context(), request)
+	if err != nil {
+		handler.logger.Errorw("error in resolving port forward target", "err", err, "request", request)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+	conn, err := portForwardUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// upgrader has already written error response
+		handler.logger.Errorw("error in upgrading port forward connection", "err", err)
+		return
+	}
+	defer conn.Close()
+	err = handler.k8sApplicationService.PortForward(withAuditContext(r, userId), request, target, &websocketStream{conn: conn})
+	closeCode, closeReason := websocket.CloseNormalClosure, ""
+	if err != nil {
+		handler.logger.Infow("port forward session ended", "err", err, "request", request, "podName", target.PodName)
+		closeCode, closeReason = websocket.CloseInternalServerErr, err.Error()
+		// control frame payload is limited to 125 bytes including close code
+		if len(closeReason) > 123 {
+			closeReason = closeReason[:123]
+		}
+	}
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, closeReason), time.Now().Add(time.Second))
+}
+
+// websocketStream adapts websocket to io.ReadWriter, each write is sent as one binary message
+type websocketStream struct {
+	conn   *websocket.Conn
+	reader io.Reader
+}
+
+func (s *websocketStream) Read(p []byte) (int, error) {
+	for {
+		if s.reader == nil {
+			_, reader, err := s.conn.NextReader()
+			if err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					return 0, io.EOF
+				}
+				return 0, err
+			}
+			s.reader = reader
+		}
+		n, err := s.reader.Read(p)
+		if err == io.EOF {
+			s.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (s *websocketStream) Write(p []byte) (int, error) {
+	err := s.conn.WriteMessage(websocket.BinaryMessage, p)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// sseStream writes server sent events, headers are set with first event so that errors before it are still written
+// as json response
+type sseStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	lock    sync.Mutex
+	started bool
+}
+
+func newSseStream(w http.ResponseWriter) (*sseStream, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+	return &sseStream{w: w, flusher: flusher}, true
+}
+
+func (s *sseStream) writeEvent(eventId string, eventName string, payload []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.started {
+		s.w.Header().Set("Content-Type", "text/event-stream")
+		s.w.Header().Set("X-Accel-Buffering", "no")
+		s.w.Header().Set("X-Content-Type-Options", "nosniff")
+		s.w.Header().Set("Cache-Control", "no-cache, no-transform")
+		s.started = true
+	}
+	var res []byte
+	if len(eventId) > 0 {
+		res = append(res, fmt.Sprintf("id: %s\n", eventId)...)
+	}
+	if len(eventName) > 0 {
+		res = append(res, fmt.Sprintf("event:%s\n", eventName)...)
+	}
+	res = append(res, "data:"...)
+	res = append(res, payload...)
+	res = append(res, '\n', '\n')
+	if _, err := s.w.Write(res); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+func (s *sseStream) isStarted() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.started
+}
+
+// startHeartbeat keeps idle streams alive behind proxies till ctx is done, failure to write means client is gone
+// and cancels ctx. Returned channel is closed when heartbeat stops.
+func (s *sseStream) startHeartbeat(ctx context.Context, cancel context.CancelFunc) <-chan struct{} {
+	heartbeatDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		defer close(heartbeatDone)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t := <-ticker.C:
+				if !s.isStarted() {
+					continue
+				}
+				if err := s.writeEvent("", "PING", []byte(t.String())); err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return heartbeatDone
+}
+
 func (handler *K8sApplicationRestHandlerImpl) ApplyResources(w http.ResponseWriter, r *http.Request) {
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return
+	}
 	decoder := json.NewDecoder(r.Body)
 	var request application.ApplyResourcesRequest
 	token := r.Header.Get("token")
-	err := decoder.Decode(&request)
+	err = decoder.Decode(&request)
 	if err != nil {
 		handler.logger.Errorw("error in decoding request body", "err", err)
 		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
 		return
 	}
 
-	response, err := handler.k8sApplicationService.ApplyResources(r.Context(), token, &request, handler.verifyRbacForCluster)
+	response, err := handler.k8sApplicationService.ApplyResources(withAuditContext(r, userId), token, &request, handler.verifyRbacForCluster)
 	if err != nil {
 		handler.logger.Errorw("error in applying resource", "err", err)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -730,6 +1245,31 @@ func (handler *K8sApplicationRestHandlerImpl) ApplyResources(w http.ResponseWrit
 	common.WriteJsonResp(w, nil, response, http.StatusOK)
 }
 
+func (handler *K8sApplicationRestHandlerImpl) DryRunApplyResources(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	var request application.ApplyResourcesRequest
+	token := r.Header.Get("token")
+	err := decoder.Decode(&request)
+	if err != nil {
+		handler.logger.Errorw("error in decoding request body", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	response, err := handler.k8sApplicationService.DryRunApplyResources(r.Context(), token, &request, handler.verifyRbacForCluster)
+	if err != nil {
+		handler.logger.Errorw("error in dry run apply of resources", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, response, http.StatusOK)
+}
+
+// withAuditContext returns request context carrying user and client ip, resource mutations done with it are audited
+func withAuditContext(r *http.Request, userId int32) context.Context {
+	return kubernetesResourceAuditLogs.WithAuditContext(r.Context(), userId, util.GetClientIP(r))
+}
+
 func (handler *K8sApplicationRestHandlerImpl) getRbacCallbackForResource(token string, casbinAction string) func(clusterName string, resourceIdentifier application.ResourceIdentifier) bool {
 	return func(clusterName string, resourceIdentifier application.ResourceIdentifier) bool {
 		return handler.verifyRbacForResource(token, clusterName, resourceIdentifier, casbinAction)
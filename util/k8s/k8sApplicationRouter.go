@@ -32,6 +32,9 @@ func (impl *K8sApplicationRouterImpl) InitK8sApplicationRouter(k8sAppRouter *mux
 	k8sAppRouter.Path("/resource").
 		HandlerFunc(impl.k8sApplicationRestHandler.UpdateResource).Methods("PUT")
 
+	k8sAppRouter.Path("/resource/dry-run").
+		HandlerFunc(impl.k8sApplicationRestHandler.DryRunUpdateResource).Methods("PUT")
+
 	k8sAppRouter.Path("/resource/delete").
 		HandlerFunc(impl.k8sApplicationRestHandler.DeleteResource).Methods("POST")
 
@@ -46,9 +49,15 @@ func (impl *K8sApplicationRouterImpl) InitK8sApplicationRouter(k8sAppRouter *mux
 		Queries("follow", "{follow}").
 		Queries("tailLines", "{tailLines}").
 		HandlerFunc(impl.k8sApplicationRestHandler.GetPodLogs).Methods("GET")
+	k8sAppRouter.Path("/pods/logs").
+		HandlerFunc(impl.k8sApplicationRestHandler.GetMultiPodLogs).Methods("GET")
 
 	k8sAppRouter.Path("/pod/exec/session/{identifier}/{namespace}/{pod}/{shell}/{container}").
 		HandlerFunc(impl.k8sApplicationRestHandler.GetTerminalSession).Methods("GET")
+	k8sAppRouter.Path("/pod/debug/session").
+		HandlerFunc(impl.k8sApplicationRestHandler.CreateEphemeralContainerSession).Methods("POST")
+	k8sAppRouter.Path("/pod/port-forward").
+		HandlerFunc(impl.k8sApplicationRestHandler.PortForward).Methods("GET")
 	k8sAppRouter.PathPrefix("/pod/exec/sockjs/ws").Handler(terminal.CreateAttachHandler("/pod/exec/sockjs/ws"))
 
 	/*k8sAppRouter.Path("/pod/exec/sockjs/ws/").
@@ -63,6 +72,13 @@ func (impl *K8sApplicationRouterImpl) InitK8sApplicationRouter(k8sAppRouter *mux
 	k8sAppRouter.Path("/resource/list").
 		HandlerFunc(impl.k8sApplicationRestHandler.GetResourceList).Methods("POST")
 
+	k8sAppRouter.Path("/resource/watch").
+		Queries("clusterId", "{clusterId}").
+		HandlerFunc(impl.k8sApplicationRestHandler.WatchResourceList).Methods("GET")
+
 	k8sAppRouter.Path("/resources/apply").
 		HandlerFunc(impl.k8sApplicationRestHandler.ApplyResources).Methods("POST")
+
+	k8sAppRouter.Path("/resources/apply/dry-run").
+		HandlerFunc(impl.k8sApplicationRestHandler.DryRunApplyResources).Methods("POST")
 }
@@ -54,6 +54,15 @@ type K8sApplicationService interface {
 	GetAllApiResources(ctx context.Context, clusterId int, isSuperAdmin bool, userId int32) (*application.GetAllApiResourcesResponse, error)
 	GetResourceList(ctx context.Context, token string, request *ResourceRequestBean, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) (*util.ClusterResourceListMap, error)
 	ApplyResources(ctx context.Context, token string, request *application.ApplyResourcesRequest, resourceRbacHandler func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) ([]*application.ApplyResourcesResponse, error)
+	WatchResourceList(ctx context.Context, token string, request *ResourceRequestBean, resourceVersion string,
+		validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool, send func(event *ResourceListWatchEvent) error) error
+	DryRunApplyResources(ctx context.Context, token string, request *application.ApplyResourcesRequest, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) ([]*application.DryRunResourceResponse, error)
+	DryRunUpdateResource(ctx context.Context, request *ResourceRequestBean) (*application.DryRunResourceResponse, error)
+	CreateEphemeralContainer(ctx context.Context, request *EphemeralContainerRequest) (string, error)
+	StreamMultiPodLogs(ctx context.Context, request *MultiPodLogsRequest,
+		checkPodAccess func(clusterName string, resourceIdentifier application.ResourceIdentifier) bool, send func(line *MultiPodLogLine) error) error
+	ResolvePortForwardTarget(ctx context.Context, request *PortForwardRequest) (*PortForwardTarget, error)
+	PortForward(ctx context.Context, request *PortForwardRequest, target *PortForwardTarget, conn io.ReadWriter) error
 }
 type K8sApplicationServiceImpl struct {
 	logger                      *zap.SugaredLogger
@@ -65,18 +74,27 @@ type K8sApplicationServiceImpl struct {
 	aCDAuthConfig               *util3.ACDAuthConfig
 	K8sApplicationServiceConfig *K8sApplicationServiceConfig
 	K8sResourceHistoryService   kubernetesResourceAuditLogs.K8sResourceHistoryService
+	k8sResourceAuditLogService  kubernetesResourceAuditLogs.K8sResourceAuditLogService
+	environmentService          cluster.EnvironmentService
 }
 
 type K8sApplicationServiceConfig struct {
 	BatchSize        int `env:"BATCH_SIZE" envDefault:"5"`
 	TimeOutInSeconds int `env:"TIMEOUT_IN_SECONDS" envDefault:"5"`
+	// port forward sessions are closed after max duration even when active, and after idle timeout without traffic
+	PortForwardMaxDurationMins int `env:"PORT_FORWARD_MAX_DURATION_MINS" envDefault:"60"`
+	PortForwardIdleTimeoutMins int `env:"PORT_FORWARD_IDLE_TIMEOUT_MINS" envDefault:"10"`
+	// multi pod log requests stream at most these many containers at once, others wait for a stream to end
+	MultiPodLogMaxStreams int `env:"MULTI_POD_LOG_MAX_STREAMS" envDefault:"50"`
 }
 
 func NewK8sApplicationServiceImpl(Logger *zap.SugaredLogger,
 	clusterService cluster.ClusterService,
 	pump connector.Pump, k8sClientService application.K8sClientService,
 	helmAppService client.HelmAppService, K8sUtil *util.K8sUtil, aCDAuthConfig *util3.ACDAuthConfig,
-	K8sResourceHistoryService kubernetesResourceAuditLogs.K8sResourceHistoryService) *K8sApplicationServiceImpl {
+	K8sResourceHistoryService kubernetesResourceAuditLogs.K8sResourceHistoryService,
+	k8sResourceAuditLogService kubernetesResourceAuditLogs.K8sResourceAuditLogService,
+	environmentService cluster.EnvironmentService) *K8sApplicationServiceImpl {
 	cfg := &K8sApplicationServiceConfig{}
 	err := env.Parse(cfg)
 	if err != nil {
@@ -92,6 +110,8 @@ func NewK8sApplicationServiceImpl(Logger *zap.SugaredLogger,
 		aCDAuthConfig:               aCDAuthConfig,
 		K8sApplicationServiceConfig: cfg,
 		K8sResourceHistoryService:   K8sResourceHistoryService,
+		k8sResourceAuditLogService:  k8sResourceAuditLogService,
+		environmentService:          environmentService,
 	}
 }
 
@@ -337,6 +357,7 @@ func (impl *K8sApplicationServiceImpl) CreateResource(ctx context.Context, reque
 		return nil, err
 	}
 	resp, err := impl.k8sClientService.CreateResource(ctx, restConfig, request.K8sRequest, *manifest)
+	impl.saveAuditLog(ctx, request.AppIdentifier.ClusterId, request.K8sRequest.ResourceIdentifier, kubernetesResourceAuditLogs.AUDIT_ACTION_CREATE, nil, resp, err)
 	if err != nil {
 		impl.logger.Errorw("error in creating resource", "err", err, "request", request)
 		return nil, err
@@ -352,7 +373,13 @@ func (impl *K8sApplicationServiceImpl) UpdateResource(ctx context.Context, reque
 		impl.logger.Errorw("error in getting rest config by cluster Id", "err", err, "clusterId", clusterId)
 		return nil, err
 	}
+	// live state is fetched only for audit diff, update goes ahead even when it can not be fetched
+	live, err := impl.k8sClientService.GetResource(ctx, restConfig, request.K8sRequest)
+	if err != nil {
+		impl.logger.Warnw("error in getting live resource for audit log", "err", err, "request", request)
+	}
 	resp, err := impl.k8sClientService.UpdateResource(ctx, restConfig, request.K8sRequest)
+	impl.saveAuditLog(ctx, clusterId, request.K8sRequest.ResourceIdentifier, kubernetesResourceAuditLogs.AUDIT_ACTION_UPDATE, live, resp, err)
 	if err != nil {
 		impl.logger.Errorw("error in updating resource", "err", err, "request", request)
 		return nil, err
@@ -369,6 +396,7 @@ func (impl *K8sApplicationServiceImpl) DeleteResource(ctx context.Context, reque
 		return nil, err
 	}
 	resp, err := impl.k8sClientService.DeleteResource(ctx, restConfig, request.K8sRequest)
+	impl.saveAuditLog(ctx, clusterId, request.K8sRequest.ResourceIdentifier, kubernetesResourceAuditLogs.AUDIT_ACTION_DELETE, resp, nil, err)
 	if err != nil {
 		impl.logger.Errorw("error in deleting resource", "err", err, "request", request)
 		return nil, err
@@ -738,7 +766,7 @@ func (impl *K8sApplicationServiceImpl) ApplyResources(ctx context.Context, token
 		}
 		actionAllowed := validateResourceAccess(token, clusterBean.ClusterName, resourceRequestBean, casbin.ActionUpdate)
 		if actionAllowed {
-			resourceExists, err := impl.applyResourceFromManifest(ctx, manifest, restConfig, namespace)
+			resourceExists, err := impl.applyResourceFromManifest(ctx, clusterId, manifest, restConfig, namespace)
 			manifestRes.IsUpdate = resourceExists
 			if err != nil {
 				manifestRes.Error = err.Error()
@@ -752,7 +780,7 @@ func (impl *K8sApplicationServiceImpl) ApplyResources(ctx context.Context, token
 	return response, nil
 }
 
-func (impl *K8sApplicationServiceImpl) applyResourceFromManifest(ctx context.Context, manifest unstructured.Unstructured, restConfig *rest.Config, namespace string) (bool, error) {
+func (impl *K8sApplicationServiceImpl) applyResourceFromManifest(ctx context.Context, clusterId int, manifest unstructured.Unstructured, restConfig *rest.Config, namespace string) (bool, error) {
 	var isUpdateResource bool
 	k8sRequestBean := &application.K8sRequestBean{
 		ResourceIdentifier: application.ResourceIdentifier{
@@ -767,7 +795,7 @@ func (impl *K8sApplicationServiceImpl) applyResourceFromManifest(ctx context.Con
 		return isUpdateResource, err
 	}
 	jsonStr := string(jsonStrByteErr)
-	_, err = impl.k8sClientService.GetResource(ctx, restConfig, k8sRequestBean)
+	live, err := impl.k8sClientService.GetResource(ctx, restConfig, k8sRequestBean)
 	if err != nil {
 		statusError, ok := err.(*errors2.StatusError)
 		if !ok || statusError == nil || statusError.ErrStatus.Reason != metav1.StatusReasonNotFound {
@@ -775,7 +803,8 @@ func (impl *K8sApplicationServiceImpl) applyResourceFromManifest(ctx context.Con
 			return isUpdateResource, err
 		}
 		// case of resource not found
-		_, err = impl.k8sClientService.CreateResource(ctx, restConfig, k8sRequestBean, jsonStr)
+		resp, err := impl.k8sClientService.CreateResource(ctx, restConfig, k8sRequestBean, jsonStr)
+		impl.saveAuditLog(ctx, clusterId, k8sRequestBean.ResourceIdentifier, kubernetesResourceAuditLogs.AUDIT_ACTION_CREATE, nil, resp, err)
 		if err != nil {
 			impl.logger.Errorw("error in creating resource", "err", err)
 			return isUpdateResource, err
@@ -783,7 +812,8 @@ func (impl *K8sApplicationServiceImpl) applyResourceFromManifest(ctx context.Con
 	} else {
 		// case of resource update
 		isUpdateResource = true
-		_, err = impl.k8sClientService.ApplyResource(ctx, restConfig, k8sRequestBean, jsonStr)
+		resp, err := impl.k8sClientService.ApplyResource(ctx, restConfig, k8sRequestBean, jsonStr)
+		impl.saveAuditLog(ctx, clusterId, k8sRequestBean.ResourceIdentifier, kubernetesResourceAuditLogs.AUDIT_ACTION_PATCH, live, resp, err)
 		if err != nil {
 			impl.logger.Errorw("error in updating resource", "err", err)
 			return isUpdateResource, err
@@ -792,3 +822,25 @@ func (impl *K8sApplicationServiceImpl) applyResourceFromManifest(ctx context.Con
 
 	return isUpdateResource, nil
 }
+
+// saveAuditLog records mutation of resource, before and after are nil when resource did not exist or was not returned
+func (impl *K8sApplicationServiceImpl) saveAuditLog(ctx context.Context, clusterId int, resourceIdentifier application.ResourceIdentifier, action string,
+	before *application.ManifestResponse, after *application.ManifestResponse, err error) {
+	auditLogRequest := &kubernetesResourceAuditLogs.K8sResourceAuditLogRequest{
+		ClusterId:        clusterId,
+		GroupVersionKind: resourceIdentifier.GroupVersionKind,
+		Namespace:        resourceIdentifier.Namespace,
+		Name:             resourceIdentifier.Name,
+		Action:           action,
+		Err:              err,
+	}
+	if before != nil {
+		auditLogRequest.Before = &before.Manifest
+	}
+	if after != nil {
+		auditLogRequest.After = &after.Manifest
+	}
+	//values of secrets are not to be kept in audit logs
+	auditLogRequest.Before, auditLogRequest.After = application.MaskSecretData(auditLogRequest.Before, auditLogRequest.After)
+	impl.k8sResourceAuditLogService.SaveAuditLog(ctx, auditLogRequest)
+}
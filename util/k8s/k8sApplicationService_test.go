@@ -10,7 +10,6 @@ import (
 	"github.com/devtron-labs/devtron/pkg/cluster"
 	"github.com/devtron-labs/devtron/pkg/cluster/repository"
 	"github.com/google/go-cmp/cmp"
-	"github.com/stretchr/testify/mock"
 	"io"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -80,11 +79,12 @@ var manifest = `{
 		}
 	  }`
 
+// mocks embed the interfaces so that methods not used by these tests need not be stubbed
 type NewK8sClientServiceImplMock struct {
-	mock.Mock
+	application.K8sClientService
 }
 type NewClusterServiceMock struct {
-	mock.Mock
+	cluster.ClusterService
 }
 
 func (n NewClusterServiceMock) Save(parent context.Context, bean *cluster.ClusterBean, userId int32) (*cluster.ClusterBean, error) {
@@ -157,33 +157,33 @@ func (n NewClusterServiceMock) GetK8sClient() (*v1.CoreV1Client, error) {
 	panic("implement me")
 }
 
-func (n NewK8sClientServiceImplMock) GetResource(restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
+func (n NewK8sClientServiceImplMock) GetResource(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
 	kind := request.ResourceIdentifier.GroupVersionKind.Kind
 	man := generateTestManifest(kind)
 	return &man, nil
 }
 
-func (n NewK8sClientServiceImplMock) CreateResource(restConfig *rest.Config, request *application.K8sRequestBean, manifest string) (resp *application.ManifestResponse, err error) {
+func (n NewK8sClientServiceImplMock) CreateResource(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean, manifest string) (resp *application.ManifestResponse, err error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewK8sClientServiceImplMock) UpdateResource(restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
+func (n NewK8sClientServiceImplMock) UpdateResource(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewK8sClientServiceImplMock) DeleteResource(restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
+func (n NewK8sClientServiceImplMock) DeleteResource(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean) (resp *application.ManifestResponse, err error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewK8sClientServiceImplMock) ListEvents(restConfig *rest.Config, request *application.K8sRequestBean) (*application.EventsResponse, error) {
+func (n NewK8sClientServiceImplMock) ListEvents(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean) (*application.EventsResponse, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (n NewK8sClientServiceImplMock) GetPodLogs(restConfig *rest.Config, request *application.K8sRequestBean) (io.ReadCloser, error) {
+func (n NewK8sClientServiceImplMock) GetPodLogs(ctx context.Context, restConfig *rest.Config, request *application.K8sRequestBean) (io.ReadCloser, error) {
 	//TODO implement me
 	panic("implement me")
 }
@@ -194,7 +194,7 @@ func Test_GetManifestsInBatch(t *testing.T) {
 		clusterService = NewClusterServiceMock{}
 		impl           = NewK8sApplicationServiceImpl(
 			nil, clusterService, nil, k8sCS, nil,
-			nil, nil)
+			nil, nil, nil, nil, nil)
 	)
 	n := 10
 	kinds := []string{"Service", "Ingress", "Random", "Invalid"}
@@ -215,7 +215,7 @@ func Test_GetManifestsInBatch(t *testing.T) {
 	}
 
 	t.Run(fmt.Sprint("test1"), func(t *testing.T) {
-		resultOutput := impl.GetHostUrlsByBatch(testInput)
+		resultOutput := impl.getManifestsByBatch(context.Background(), testInput)
 		//check if all the output manifests are expected
 		for j, _ := range resultOutput {
 			if !cmp.Equal(resultOutput[j], expectedTestOutputs[j]) {
@@ -248,7 +248,7 @@ type test struct {
 func Test_getUrls(t *testing.T) {
 	impl := NewK8sApplicationServiceImpl(
 		nil, nil, nil, nil, nil,
-		nil, nil)
+		nil, nil, nil, nil, nil)
 	tests := make([]test, 3)
 	tests[0] = test{
 		inp: generateTestManifest("Service"),
@@ -297,11 +297,12 @@ func generateTestManifest(kind string) application.ManifestResponse {
 
 func getObj(kind string) map[string]interface{} {
 	var obj map[string]interface{}
+	//not overwriting the shared manifest, later manifests of valid kinds are generated from it
+	objManifest := manifest
 	if (kind != "Service") && (kind != "Ingress") {
-		fmt.Println(kind)
-		manifest = `{"invalid":{}}`
+		objManifest = `{"invalid":{}}`
 	}
-	err := json.Unmarshal([]byte(manifest), &obj)
+	err := json.Unmarshal([]byte(objManifest), &obj)
 	if err != nil {
 		fmt.Print("error in marshaling : ", err)
 		return nil
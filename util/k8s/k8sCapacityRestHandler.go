@@ -3,6 +3,7 @@ package k8s
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/devtron-labs/devtron/api/restHandler/common"
 	"github.com/devtron-labs/devtron/pkg/cluster"
 	"github.com/devtron-labs/devtron/pkg/user"
@@ -24,6 +25,8 @@ type K8sCapacityRestHandler interface {
 	CordonOrUnCordonNode(w http.ResponseWriter, r *http.Request)
 	DrainNode(w http.ResponseWriter, r *http.Request)
 	EditNodeTaints(w http.ResponseWriter, r *http.Request)
+	GetNodeDrainPlan(w http.ResponseWriter, r *http.Request)
+	ExecuteNodeMaintenance(w http.ResponseWriter, r *http.Request)
 }
 type K8sCapacityRestHandlerImpl struct {
 	logger             *zap.SugaredLogger
@@ -234,7 +237,7 @@ func (handler *K8sCapacityRestHandlerImpl) UpdateNodeManifest(w http.ResponseWri
 		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
 		return
 	}
-	updatedManifest, err := handler.k8sCapacityService.UpdateNodeManifest(r.Context(), &manifestUpdateReq)
+	updatedManifest, err := handler.k8sCapacityService.UpdateNodeManifest(withAuditContext(r, userId), &manifestUpdateReq)
 	if err != nil {
 		handler.logger.Errorw("error in updating node manifest", "err", err, "updateRequest", manifestUpdateReq)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -263,7 +266,7 @@ func (handler *K8sCapacityRestHandlerImpl) DeleteNode(w http.ResponseWriter, r *
 		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
 		return
 	}
-	updatedManifest, err := handler.k8sCapacityService.DeleteNode(r.Context(), &nodeDelReq)
+	updatedManifest, err := handler.k8sCapacityService.DeleteNode(withAuditContext(r, userId), &nodeDelReq)
 	if err != nil {
 		handler.logger.Errorw("error in deleting node", "err", err, "deleteRequest", nodeDelReq)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -292,7 +295,7 @@ func (handler *K8sCapacityRestHandlerImpl) CordonOrUnCordonNode(w http.ResponseW
 		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
 		return
 	}
-	resp, err := handler.k8sCapacityService.CordonOrUnCordonNode(r.Context(), &nodeCordonReq)
+	resp, err := handler.k8sCapacityService.CordonOrUnCordonNode(withAuditContext(r, userId), &nodeCordonReq)
 	if err != nil {
 		handler.logger.Errorw("error in cordon/unCordon node", "err", err, "req", nodeCordonReq)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -321,7 +324,7 @@ func (handler *K8sCapacityRestHandlerImpl) DrainNode(w http.ResponseWriter, r *h
 		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
 		return
 	}
-	resp, err := handler.k8sCapacityService.DrainNode(r.Context(), &nodeDrainReq)
+	resp, err := handler.k8sCapacityService.DrainNode(withAuditContext(r, userId), &nodeDrainReq)
 	if err != nil {
 		handler.logger.Errorw("error in draining node", "err", err, "req", nodeDrainReq)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
@@ -330,6 +333,87 @@ func (handler *K8sCapacityRestHandlerImpl) DrainNode(w http.ResponseWriter, r *h
 	common.WriteJsonResp(w, nil, resp, http.StatusOK)
 }
 
+func (handler *K8sCapacityRestHandlerImpl) GetNodeDrainPlan(w http.ResponseWriter, r *http.Request) {
+	maintenanceReq, _, ok := handler.decodeAndAuthorizeNodeMaintenance(w, r)
+	if !ok {
+		return
+	}
+	resp, err := handler.k8sCapacityService.GetNodeDrainPlan(r.Context(), maintenanceReq)
+	if err != nil {
+		handler.logger.Errorw("error in getting node drain plan", "err", err, "req", maintenanceReq)
+		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	common.WriteJsonResp(w, nil, resp, http.StatusOK)
+}
+
+// ExecuteNodeMaintenance streams maintenance progress as server sent events, errors before the first event are
+// returned as regular json response
+func (handler *K8sCapacityRestHandlerImpl) ExecuteNodeMaintenance(w http.ResponseWriter, r *http.Request) {
+	maintenanceReq, userId, ok := handler.decodeAndAuthorizeNodeMaintenance(w, r)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		common.WriteJsonResp(w, errors.New("streaming not supported"), nil, http.StatusInternalServerError)
+		return
+	}
+	streamStarted := false
+	writeEvent := func(event *NodeMaintenanceEvent) {
+		if !streamStarted {
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("X-Accel-Buffering", "no")
+			streamStarted = true
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			handler.logger.Errorw("error in marshaling node maintenance event", "err", err)
+			return
+		}
+		if _, err = fmt.Fprintf(w, "event:%s\ndata:%s\n\n", event.Type, data); err != nil {
+			handler.logger.Errorw("error in writing node maintenance event", "err", err)
+			return
+		}
+		flusher.Flush()
+	}
+	_, err := handler.k8sCapacityService.ExecuteNodeMaintenance(withAuditContext(r, userId), maintenanceReq, writeEvent)
+	if err != nil {
+		handler.logger.Errorw("error in executing node maintenance", "err", err, "req", maintenanceReq)
+		if !streamStarted {
+			common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		writeEvent(&NodeMaintenanceEvent{Type: NODE_MAINTENANCE_EVENT_FAILED, Message: err.Error()})
+	}
+}
+
+func (handler *K8sCapacityRestHandlerImpl) decodeAndAuthorizeNodeMaintenance(w http.ResponseWriter, r *http.Request) (*NodeMaintenanceRequest, int32, bool) {
+	decoder := json.NewDecoder(r.Body)
+	var maintenanceReq NodeMaintenanceRequest
+	err := decoder.Decode(&maintenanceReq)
+	if err != nil {
+		handler.logger.Errorw("error in decoding request body", "err", err)
+		common.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return nil, 0, false
+	}
+	userId, err := handler.userService.GetLoggedInUser(r)
+	if userId == 0 || err != nil {
+		common.WriteJsonResp(w, err, "Unauthorized User", http.StatusUnauthorized)
+		return nil, 0, false
+	}
+	// RBAC enforcer applying
+	token := r.Header.Get("token")
+	if ok := handler.enforcer.Enforce(token, casbin.ResourceGlobal, casbin.ActionUpdate, "*"); !ok {
+		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
+		return nil, 0, false
+	}
+	//RBAC enforcer Ends
+	return &maintenanceReq, userId, true
+}
+
 func (handler *K8sCapacityRestHandlerImpl) EditNodeTaints(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
 	var nodeTaintReq NodeUpdateRequestDto
@@ -350,7 +434,7 @@ func (handler *K8sCapacityRestHandlerImpl) EditNodeTaints(w http.ResponseWriter,
 		common.WriteJsonResp(w, errors.New("unauthorized"), nil, http.StatusForbidden)
 		return
 	}
-	resp, err := handler.k8sCapacityService.EditNodeTaints(r.Context(), &nodeTaintReq)
+	resp, err := handler.k8sCapacityService.EditNodeTaints(withAuditContext(r, userId), &nodeTaintReq)
 	if err != nil {
 		handler.logger.Errorw("error in editing node taints", "err", err, "req", nodeTaintReq)
 		common.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
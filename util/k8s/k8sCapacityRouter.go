@@ -43,6 +43,12 @@ func (impl *K8sCapacityRouterImpl) InitK8sCapacityRouter(k8sCapacityRouter *mux.
 	k8sCapacityRouter.Path("/node/drain").
 		HandlerFunc(impl.k8sCapacityRestHandler.DrainNode).Methods("PUT")
 
+	k8sCapacityRouter.Path("/node/drain/plan").
+		HandlerFunc(impl.k8sCapacityRestHandler.GetNodeDrainPlan).Methods("POST")
+
+	k8sCapacityRouter.Path("/node/maintenance").
+		HandlerFunc(impl.k8sCapacityRestHandler.ExecuteNodeMaintenance).Methods("POST")
+
 	k8sCapacityRouter.Path("/node/taints/edit").
 		HandlerFunc(impl.k8sCapacityRestHandler.EditNodeTaints).Methods("PUT")
 }
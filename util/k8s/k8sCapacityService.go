@@ -6,6 +6,7 @@ import (
 	"github.com/devtron-labs/devtron/client/k8s/application"
 	"github.com/devtron-labs/devtron/internal/util"
 	"github.com/devtron-labs/devtron/pkg/cluster"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
@@ -13,7 +14,9 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/duration"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -77,6 +80,8 @@ type K8sCapacityService interface {
 	CordonOrUnCordonNode(ctx context.Context, request *NodeUpdateRequestDto) (string, error)
 	DrainNode(ctx context.Context, request *NodeUpdateRequestDto) (string, error)
 	EditNodeTaints(ctx context.Context, request *NodeUpdateRequestDto) (string, error)
+	GetNodeDrainPlan(ctx context.Context, request *NodeMaintenanceRequest) (*NodeDrainPlan, error)
+	ExecuteNodeMaintenance(ctx context.Context, request *NodeMaintenanceRequest, progress func(event *NodeMaintenanceEvent)) (*NodeMaintenanceResult, error)
 }
 type K8sCapacityServiceImpl struct {
 	logger                *zap.SugaredLogger
@@ -84,19 +89,22 @@ type K8sCapacityServiceImpl struct {
 	k8sApplicationService K8sApplicationService
 	k8sClientService      application.K8sClientService
 	clusterCronService    ClusterCronService
+	auditLogService       kubernetesResourceAuditLogs.K8sResourceAuditLogService
 }
 
 func NewK8sCapacityServiceImpl(Logger *zap.SugaredLogger,
 	clusterService cluster.ClusterService,
 	k8sApplicationService K8sApplicationService,
 	k8sClientService application.K8sClientService,
-	clusterCronService ClusterCronService) *K8sCapacityServiceImpl {
+	clusterCronService ClusterCronService,
+	auditLogService kubernetesResourceAuditLogs.K8sResourceAuditLogService) *K8sCapacityServiceImpl {
 	return &K8sCapacityServiceImpl{
 		logger:                Logger,
 		clusterService:        clusterService,
 		k8sApplicationService: k8sApplicationService,
 		k8sClientService:      k8sClientService,
 		clusterCronService:    clusterCronService,
+		auditLogService:       auditLogService,
 	}
 }
 
@@ -568,7 +576,20 @@ func (impl *K8sCapacityServiceImpl) UpdateNodeManifest(ctx context.Context, requ
 		},
 		Patch: request.ManifestPatch,
 	}
+	liveManifest, err := impl.k8sClientService.GetResource(ctx, restConfig, manifestUpdateReq)
+	if err != nil {
+		impl.logger.Warnw("error in getting node manifest for audit log", "err", err, "nodeName", request.Name)
+	}
 	manifestResponse, err := impl.k8sClientService.UpdateResource(ctx, restConfig, manifestUpdateReq)
+	auditLogRequest := &kubernetesResourceAuditLogs.K8sResourceAuditLogRequest{ClusterId: request.ClusterId, GroupVersionKind: manifestUpdateReq.ResourceIdentifier.GroupVersionKind,
+		Name: request.Name, Action: kubernetesResourceAuditLogs.AUDIT_ACTION_UPDATE, Err: err}
+	if liveManifest != nil {
+		auditLogRequest.Before = &liveManifest.Manifest
+	}
+	if manifestResponse != nil {
+		auditLogRequest.After = &manifestResponse.Manifest
+	}
+	impl.auditLogService.SaveAuditLog(ctx, auditLogRequest)
 	if err != nil {
 		impl.logger.Errorw("error in updating node manifest", "err", err)
 		return nil, err
@@ -594,6 +615,12 @@ func (impl *K8sCapacityServiceImpl) DeleteNode(ctx context.Context, request *Nod
 		},
 	}
 	manifestResponse, err := impl.k8sClientService.DeleteResource(ctx, restConfig, deleteReq)
+	auditLogRequest := &kubernetesResourceAuditLogs.K8sResourceAuditLogRequest{ClusterId: request.ClusterId, GroupVersionKind: deleteReq.ResourceIdentifier.GroupVersionKind,
+		Name: request.Name, Action: kubernetesResourceAuditLogs.AUDIT_ACTION_DELETE, Err: err}
+	if manifestResponse != nil {
+		auditLogRequest.Before = &manifestResponse.Manifest
+	}
+	impl.auditLogService.SaveAuditLog(ctx, auditLogRequest)
 	if err != nil {
 		impl.logger.Errorw("error in deleting node", "err", err)
 		return nil, err
@@ -622,7 +649,13 @@ func (impl *K8sCapacityServiceImpl) CordonOrUnCordonNode(ctx context.Context, re
 		return respMessage, getErrorForCordonUpdateReq(request.NodeCordonHelper.UnschedulableDesired)
 	}
 	//updating node with desired cordon value
+	liveNode := node.DeepCopy()
 	node, err = updateNodeUnschedulableProperty(request.NodeCordonHelper.UnschedulableDesired, node, k8sClientSet)
+	auditAction := kubernetesResourceAuditLogs.AUDIT_ACTION_UNCORDON
+	if request.NodeCordonHelper.UnschedulableDesired {
+		auditAction = kubernetesResourceAuditLogs.AUDIT_ACTION_CORDON
+	}
+	impl.saveNodeAuditLog(ctx, request.ClusterId, request.Name, auditAction, liveNode, node, "", err)
 	if err != nil {
 		impl.logger.Errorw("error in updating node", "err", err)
 		return respMessage, err
@@ -656,7 +689,9 @@ func (impl *K8sCapacityServiceImpl) DrainNode(ctx context.Context, request *Node
 	}
 	//checking if node is unschedulable or not, if not then need to unschedule before draining
 	if !node.Spec.Unschedulable {
+		liveNode := node.DeepCopy()
 		node, err = updateNodeUnschedulableProperty(true, node, k8sClientSet)
+		impl.saveNodeAuditLog(ctx, request.ClusterId, request.Name, kubernetesResourceAuditLogs.AUDIT_ACTION_CORDON, liveNode, node, "cordoned for drain", err)
 		if err != nil {
 			impl.logger.Errorw("error in making node unschedulable", "err", err)
 			return respMessage, err
@@ -664,6 +699,7 @@ func (impl *K8sCapacityServiceImpl) DrainNode(ctx context.Context, request *Node
 	}
 	request.NodeDrainHelper.k8sClientSet = k8sClientSet
 	err = impl.deleteOrEvictPods(request.Name, request.NodeDrainHelper)
+	impl.saveNodeAuditLog(ctx, request.ClusterId, request.Name, kubernetesResourceAuditLogs.AUDIT_ACTION_DRAIN, nil, nil, "", err)
 	if err != nil {
 		impl.logger.Errorw("error in deleting/evicting pods", "err", err, "nodeName", request.Name)
 		return respMessage, err
@@ -703,8 +739,10 @@ func (impl *K8sCapacityServiceImpl) EditNodeTaints(ctx context.Context, request
 		impl.logger.Errorw("error in getting node", "err", err)
 		return respMessage, err
 	}
+	liveNode := node.DeepCopy()
 	node.Spec.Taints = request.Taints
 	node, err = k8sClientSet.CoreV1().Nodes().Update(context.Background(), node, v1.UpdateOptions{})
+	impl.saveNodeAuditLog(ctx, request.ClusterId, request.Name, kubernetesResourceAuditLogs.AUDIT_ACTION_EDIT_TAINTS, liveNode, node, "", err)
 	if err != nil {
 		impl.logger.Errorw("error in updating taints in node", "err", err)
 		return respMessage, err
@@ -916,6 +954,35 @@ func updateNodeUnschedulableProperty(desiredUnschedulable bool, node *corev1.Nod
 	return node, err
 }
 
+// saveNodeAuditLog records mutation of node, before and after are nil when node spec was not changed directly
+func (impl *K8sCapacityServiceImpl) saveNodeAuditLog(ctx context.Context, clusterId int, nodeName string, action string, before *corev1.Node, after *corev1.Node, message string, err error) {
+	auditLogRequest := &kubernetesResourceAuditLogs.K8sResourceAuditLogRequest{
+		ClusterId:        clusterId,
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Node"},
+		Name:             nodeName,
+		Action:           action,
+		Message:          message,
+		Err:              err,
+	}
+	if err == nil {
+		auditLogRequest.Before = impl.nodeToUnstructured(before)
+		auditLogRequest.After = impl.nodeToUnstructured(after)
+	}
+	impl.auditLogService.SaveAuditLog(ctx, auditLogRequest)
+}
+
+func (impl *K8sCapacityServiceImpl) nodeToUnstructured(node *corev1.Node) *unstructured.Unstructured {
+	if node == nil {
+		return nil
+	}
+	nodeObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(node)
+	if err != nil {
+		impl.logger.Errorw("error in converting node for audit log", "err", err, "nodeName", node.Name)
+		return nil
+	}
+	return &unstructured.Unstructured{Object: nodeObj}
+}
+
 func getErrorForCordonUpdateReq(desired bool) error {
 	if desired {
 		return fmt.Errorf("node already cordoned")
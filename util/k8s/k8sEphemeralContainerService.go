@@ -0,0 +1,165 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"time"
+)
+
+// CreateEphemeralContainer injects debug container in pod and waits for it to be running, returns name of the
+// created container. Every attempt is recorded in audit log against the pod.
+func (impl *K8sApplicationServiceImpl) CreateEphemeralContainer(ctx context.Context, request *EphemeralContainerRequest) (string, error) {
+	restConfig, err := impl.GetRestConfigByClusterId(ctx, request.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config by cluster Id", "err", err, "clusterId", request.ClusterId)
+		return "", err
+	}
+	k8sHttpClient, err := util.OverrideK8sHttpClientWithTracer(restConfig)
+	if err != nil {
+		return "", err
+	}
+	k8sClientSet, err := kubernetes.NewForConfigAndClient(restConfig, k8sHttpClient)
+	if err != nil {
+		impl.logger.Errorw("error in getting client set by rest config", "err", err, "clusterId", request.ClusterId)
+		return "", err
+	}
+	pods := k8sClientSet.CoreV1().Pods(request.Namespace)
+	pod, err := pods.Get(ctx, request.PodName, metav1.GetOptions{})
+	if err != nil {
+		impl.logger.Errorw("error in getting pod", "err", err, "namespace", request.Namespace, "podName", request.PodName)
+		return "", err
+	}
+	ephemeralContainer, err := buildEphemeralContainer(pod, request)
+	if err != nil {
+		return "", err
+	}
+	podWithDebugContainer := pod.DeepCopy()
+	podWithDebugContainer.Spec.EphemeralContainers = append(podWithDebugContainer.Spec.EphemeralContainers, *ephemeralContainer)
+	updatedPod, err := pods.UpdateEphemeralContainers(ctx, request.PodName, podWithDebugContainer, metav1.UpdateOptions{})
+	impl.saveEphemeralContainerAuditLog(ctx, request, ephemeralContainer.Name, pod, updatedPod, err)
+	if err != nil {
+		impl.logger.Errorw("error in adding ephemeral container", "err", err, "namespace", request.Namespace, "podName", request.PodName)
+		return "", err
+	}
+	err = wait.PollImmediate(2*time.Second, EPHEMERAL_CONTAINER_START_TIMEOUT, func() (bool, error) {
+		pod, err := pods.Get(ctx, request.PodName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return isEphemeralContainerRunning(pod, ephemeralContainer.Name)
+	})
+	if err != nil {
+		impl.logger.Errorw("error in waiting for ephemeral container to start", "err", err, "podName", request.PodName, "containerName", ephemeralContainer.Name)
+		return "", err
+	}
+	return ephemeralContainer.Name, nil
+}
+
+// buildEphemeralContainer validates request against pod and returns container with stdin and tty enabled so that
+// terminal can be attached to it, command is left empty to run entrypoint of image
+func buildEphemeralContainer(pod *corev1.Pod, request *EphemeralContainerRequest) (*corev1.EphemeralContainer, error) {
+	if len(request.Image) == 0 {
+		return nil, fmt.Errorf("image is required for debug container")
+	}
+	existingNames := make(map[string]bool)
+	for _, container := range pod.Spec.Containers {
+		existingNames[container.Name] = true
+	}
+	for _, container := range pod.Spec.InitContainers {
+		existingNames[container.Name] = true
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		existingNames[container.Name] = true
+	}
+	if len(request.TargetContainerName) > 0 {
+		found := false
+		for _, container := range pod.Spec.Containers {
+			if container.Name == request.TargetContainerName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("target container %s not found in pod %s", request.TargetContainerName, pod.Name)
+		}
+	}
+	name := fmt.Sprintf("%s-%s", EPHEMERAL_CONTAINER_NAME_PREFIX, utilrand.String(5))
+	for existingNames[name] {
+		name = fmt.Sprintf("%s-%s", EPHEMERAL_CONTAINER_NAME_PREFIX, utilrand.String(5))
+	}
+	return &corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     name,
+			Image:                    request.Image,
+			Command:                  request.Command,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			Stdin:                    true,
+			TTY:                      true,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		},
+		TargetContainerName: request.TargetContainerName,
+	}, nil
+}
+
+// isEphemeralContainerRunning returns error when container has terminated or its image can not be pulled, waiting
+// on these would only run into timeout
+func isEphemeralContainerRunning(pod *corev1.Pod, containerName string) (bool, error) {
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		if status.Name != containerName {
+			continue
+		}
+		if status.State.Running != nil {
+			return true, nil
+		}
+		if status.State.Terminated != nil {
+			return false, fmt.Errorf("debug container %s terminated, reason: %s", containerName, status.State.Terminated.Reason)
+		}
+		if waiting := status.State.Waiting; waiting != nil {
+			switch waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerError":
+				return false, fmt.Errorf("debug container %s can not be started, reason: %s, %s", containerName, waiting.Reason, waiting.Message)
+			}
+		}
+	}
+	return false, nil
+}
+
+func (impl *K8sApplicationServiceImpl) saveEphemeralContainerAuditLog(ctx context.Context, request *EphemeralContainerRequest, containerName string,
+	before *corev1.Pod, after *corev1.Pod, err error) {
+	auditLogRequest := &kubernetesResourceAuditLogs.K8sResourceAuditLogRequest{
+		ClusterId:        request.ClusterId,
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Namespace:        request.Namespace,
+		Name:             request.PodName,
+		Action:           kubernetesResourceAuditLogs.AUDIT_ACTION_DEBUG_CONTAINER,
+		Message:          fmt.Sprintf("container: %s, image: %s, target: %s", containerName, request.Image, request.TargetContainerName),
+		Err:              err,
+	}
+	if err == nil {
+		auditLogRequest.Before = impl.podToUnstructured(before)
+		auditLogRequest.After = impl.podToUnstructured(after)
+	}
+	impl.k8sResourceAuditLogService.SaveAuditLog(ctx, auditLogRequest)
+}
+
+func (impl *K8sApplicationServiceImpl) podToUnstructured(pod *corev1.Pod) *unstructured.Unstructured {
+	if pod == nil {
+		return nil
+	}
+	podObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		impl.logger.Errorw("error in converting pod to unstructured", "err", err, "podName", pod.Name)
+		return nil
+	}
+	return &unstructured.Unstructured{Object: podObj}
+}
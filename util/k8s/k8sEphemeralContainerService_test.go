@@ -0,0 +1,45 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"strings"
+	"testing"
+)
+
+func TestBuildEphemeralContainer(t *testing.T) {
+	pod := newTestPod("api-0", corev1.Container{Name: "api"}, corev1.Container{Name: "sidecar"})
+	container, err := buildEphemeralContainer(pod, &EphemeralContainerRequest{Image: "busybox", TargetContainerName: "api"})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if !strings.HasPrefix(container.Name, EPHEMERAL_CONTAINER_NAME_PREFIX+"-") || container.TargetContainerName != "api" || !container.Stdin || !container.TTY {
+		t.Errorf("unexpected container %+v", container)
+	}
+	if _, err = buildEphemeralContainer(pod, &EphemeralContainerRequest{Image: "busybox", TargetContainerName: "db"}); err == nil {
+		t.Errorf("expected err for unknown target container")
+	}
+	if _, err = buildEphemeralContainer(pod, &EphemeralContainerRequest{}); err == nil {
+		t.Errorf("expected err for missing image")
+	}
+}
+
+func TestIsEphemeralContainerRunning(t *testing.T) {
+	pod := newTestPod("api-0", corev1.Container{Name: "api"})
+	pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{
+		{Name: "debugger-a", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+		{Name: "debugger-b", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		{Name: "debugger-c", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+	}
+	if running, err := isEphemeralContainerRunning(pod, "debugger-a"); running || err != nil {
+		t.Errorf("expected creating container to be waited for, got %v %v", running, err)
+	}
+	if running, err := isEphemeralContainerRunning(pod, "debugger-b"); !running || err != nil {
+		t.Errorf("expected running container, got %v %v", running, err)
+	}
+	if _, err := isEphemeralContainerRunning(pod, "debugger-c"); err == nil {
+		t.Errorf("expected err for image pull failure")
+	}
+	if running, err := isEphemeralContainerRunning(pod, "debugger-d"); running || err != nil {
+		t.Errorf("expected container without status to be waited for, got %v %v", running, err)
+	}
+}
@@ -0,0 +1,482 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/devtron/client/k8s/application"
+	"github.com/devtron-labs/devtron/internal/util"
+	"go.uber.org/zap"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamMultiPodLogs streams logs of all containers of pods selected by request to send, lines of different containers
+// are sent in the order they are read. In follow mode pods are watched so that pods created later, e.g. during a
+// rollout, and restarted containers are streamed too, it returns when ctx is done or send fails. checkPodAccess is
+// called for every pod before streaming it, nil allows all pods.
+func (impl *K8sApplicationServiceImpl) StreamMultiPodLogs(ctx context.Context, request *MultiPodLogsRequest,
+	checkPodAccess func(clusterName string, resourceIdentifier application.ResourceIdentifier) bool, send func(line *MultiPodLogLine) error) error {
+	filter, err := newLogLineFilter(request.Grep, request.Regex)
+	if err != nil {
+		return err
+	}
+	if request.AppId > 0 && request.EnvId > 0 {
+		env, err := impl.environmentService.FindById(request.EnvId)
+		if err != nil {
+			impl.logger.Errorw("error in getting environment by id", "err", err, "envId", request.EnvId)
+			return err
+		}
+		request.ClusterId = env.ClusterId
+		request.Namespace = env.Namespace
+	}
+	clusterBean, err := impl.clusterService.FindById(request.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster by cluster Id", "err", err, "clusterId", request.ClusterId)
+		return err
+	}
+	restConfig, err := impl.GetRestConfigByCluster(ctx, clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config by cluster Id", "err", err, "clusterId", request.ClusterId)
+		return err
+	}
+	listOptions, err := impl.getMultiPodLogListOptions(ctx, restConfig, request)
+	if err != nil {
+		impl.logger.Errorw("error in resolving pods for logs", "err", err, "request", request)
+		return err
+	}
+	k8sHttpClient, err := util.OverrideK8sHttpClientWithTracer(restConfig)
+	if err != nil {
+		return err
+	}
+	k8sClientSet, err := kubernetes.NewForConfigAndClient(restConfig, k8sHttpClient)
+	if err != nil {
+		impl.logger.Errorw("error in getting client set by rest config", "err", err, "clusterId", request.ClusterId)
+		return err
+	}
+	maxStreams := impl.K8sApplicationServiceConfig.MultiPodLogMaxStreams
+	if maxStreams <= 0 {
+		maxStreams = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	streamer := &multiPodLogStreamer{
+		ctx:       ctx,
+		cancel:    cancel,
+		logger:    impl.logger,
+		pods:      k8sClientSet.CoreV1().Pods(request.Namespace),
+		request:   request,
+		filter:    filter,
+		send:      send,
+		streaming: make(map[string]bool),
+		restarts:  make(map[string]int32),
+		lastSeen:  make(map[string]time.Time),
+		slots:     make(chan struct{}, maxStreams),
+		podAllowed: func(pod *corev1.Pod) bool {
+			return checkPodAccess == nil || checkPodAccess(clusterBean.ClusterName, application.ResourceIdentifier{
+				Name:             pod.Name,
+				Namespace:        pod.Namespace,
+				GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			})
+		},
+	}
+	err = streamer.run(listOptions)
+	if err != nil {
+		cancel()
+	}
+	streamer.wg.Wait()
+	if err != nil {
+		impl.logger.Errorw("error in watching pods for logs", "err", err, "clusterId", request.ClusterId, "namespace", request.Namespace)
+		return err
+	}
+	return streamer.sendErr
+}
+
+// getMultiPodLogListOptions resolves pod selector of request, workloads are looked up for their spec.selector
+func (impl *K8sApplicationServiceImpl) getMultiPodLogListOptions(ctx context.Context, restConfig *rest.Config, request *MultiPodLogsRequest) (metav1.ListOptions, error) {
+	switch {
+	case request.AppId > 0 && request.EnvId > 0:
+		selector := labels.SelectorFromSet(labels.Set{
+			DEVTRON_APP_ID_LABEL: strconv.Itoa(request.AppId),
+			DEVTRON_ENV_ID_LABEL: strconv.Itoa(request.EnvId),
+		})
+		return metav1.ListOptions{LabelSelector: selector.String()}, nil
+	case len(request.WorkloadName) > 0:
+		if request.WorkloadGvk.Group == "" && request.WorkloadGvk.Kind == "Pod" {
+			return metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", request.WorkloadName).String()}, nil
+		}
+		resp, err := impl.k8sClientService.GetResource(ctx, restConfig, &application.K8sRequestBean{
+			ResourceIdentifier: application.ResourceIdentifier{
+				Name:             request.WorkloadName,
+				Namespace:        request.Namespace,
+				GroupVersionKind: request.WorkloadGvk,
+			},
+		})
+		if err != nil {
+			return metav1.ListOptions{}, err
+		}
+		selector, err := workloadPodSelector(&resp.Manifest)
+		if err != nil {
+			return metav1.ListOptions{}, err
+		}
+		return metav1.ListOptions{LabelSelector: selector.String()}, nil
+	case len(request.LabelSelector) > 0:
+		selector, err := labels.Parse(request.LabelSelector)
+		if err != nil {
+			return metav1.ListOptions{}, err
+		}
+		return metav1.ListOptions{LabelSelector: selector.String()}, nil
+	}
+	return metav1.ListOptions{}, errors.New("app and environment, workload or label selector is required")
+}
+
+// workloadPodSelector returns selector of pods managed by workload, an empty selector is rejected as it would
+// select every pod of the namespace
+func workloadPodSelector(manifest *unstructured.Unstructured) (labels.Selector, error) {
+	selectorMap, found, err := unstructured.NestedMap(manifest.Object, "spec", "selector")
+	if err != nil || !found {
+		return nil, fmt.Errorf("%s %s does not have pod selector", manifest.GetKind(), manifest.GetName())
+	}
+	labelSelector := &metav1.LabelSelector{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("%s %s has empty pod selector", manifest.GetKind(), manifest.GetName())
+	}
+	return selector, nil
+}
+
+// newLogLineFilter returns func keeping lines which contain grep and match regex, empty values are not applied
+func newLogLineFilter(grep string, regex string) (func(log string) bool, error) {
+	var pattern *regexp.Regexp
+	if len(regex) > 0 {
+		var err error
+		pattern, err = regexp.Compile(regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %v", err)
+		}
+	}
+	return func(log string) bool {
+		if len(grep) > 0 && !strings.Contains(log, grep) {
+			return false
+		}
+		return pattern == nil || pattern.MatchString(log)
+	}, nil
+}
+
+// parseTimestampedLogLine splits line read with timestamps enabled, line is returned as is when it has no timestamp
+func parseTimestampedLogLine(line string) (time.Time, string) {
+	line = strings.TrimRight(line, "\r\n")
+	splitLine := strings.SplitN(line, " ", 2)
+	logTime, err := time.Parse(time.RFC3339Nano, splitLine[0])
+	if err != nil {
+		return time.Time{}, line
+	}
+	if len(splitLine) == 1 {
+		return logTime, ""
+	}
+	return logTime, splitLine[1]
+}
+
+// streamableContainers returns names and restart counts of containers which have started, logs of waiting
+// containers can not be read yet
+func streamableContainers(pod *corev1.Pod, containerName string) map[string]int32 {
+	containers := make(map[string]int32)
+	for _, status := range pod.Status.ContainerStatuses {
+		if len(containerName) > 0 && status.Name != containerName {
+			continue
+		}
+		if status.State.Running != nil || status.State.Terminated != nil {
+			containers[status.Name] = status.RestartCount
+		}
+	}
+	return containers
+}
+
+// buildMultiPodLogOptions resumes just after last seen line of container, otherwise since and tail of request are
+// applied to pods present at start only, pods created afterwards are streamed from their first line
+func buildMultiPodLogOptions(request *MultiPodLogsRequest, containerName string, initialPod bool, lastSeen time.Time) *corev1.PodLogOptions {
+	podLogOptions := &corev1.PodLogOptions{
+		Container:  containerName,
+		Follow:     request.Follow,
+		Timestamps: true,
+	}
+	if !lastSeen.IsZero() {
+		sinceTime := metav1.NewTime(lastSeen.Add(time.Nanosecond))
+		podLogOptions.SinceTime = &sinceTime
+		return podLogOptions
+	}
+	if !initialPod {
+		return podLogOptions
+	}
+	if request.TailLines > 0 {
+		tailLines := int64(request.TailLines)
+		podLogOptions.TailLines = &tailLines
+	}
+	if request.SinceTime != nil {
+		podLogOptions.SinceTime = request.SinceTime
+	} else if request.SinceSeconds > 0 {
+		sinceSeconds := int64(request.SinceSeconds)
+		podLogOptions.SinceSeconds = &sinceSeconds
+	}
+	return podLogOptions
+}
+
+// runningContainerRestarts returns restart count of container if it is running, follow stream of a running
+// container is re-opened when it ends, e.g. on api server or kubelet timeout
+func runningContainerRestarts(pod *corev1.Pod, containerName string) (int32, bool) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName && status.State.Running != nil {
+			return status.RestartCount, true
+		}
+	}
+	return 0, false
+}
+
+// multiPodLogStreamer streams every matching container in its own goroutine, lock guards container state and send
+type multiPodLogStreamer struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	logger     *zap.SugaredLogger
+	pods       v1.PodInterface
+	request    *MultiPodLogsRequest
+	filter     func(log string) bool
+	send       func(line *MultiPodLogLine) error
+	podAllowed func(pod *corev1.Pod) bool
+	wg         sync.WaitGroup
+	lock       sync.Mutex
+	streaming  map[string]bool
+	restarts   map[string]int32
+	lastSeen   map[string]time.Time
+	// slots caps concurrent container streams of request
+	slots   chan struct{}
+	sendErr error
+}
+
+// run lists pods and, in follow mode, watches them till ctx is done. Pods are listed again when watch resource
+// version expires, containers already streamed resume from their last seen line.
+func (s *multiPodLogStreamer) run(listOptions metav1.ListOptions) error {
+	resourceVersion := ""
+	initialList := true
+	for s.ctx.Err() == nil {
+		if len(resourceVersion) == 0 {
+			podList, err := s.pods.List(s.ctx, listOptions)
+			if err != nil {
+				if s.ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			for i := range podList.Items {
+				s.streamPod(&podList.Items[i], initialList)
+			}
+			initialList = false
+			resourceVersion = podList.ResourceVersion
+			if !s.request.Follow {
+				return nil
+			}
+		}
+		watchStartedOn := time.Now()
+		watchOptions := listOptions
+		watchOptions.ResourceVersion = resourceVersion
+		watchIf, err := s.pods.Watch(s.ctx, watchOptions)
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return nil
+			}
+			if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+				resourceVersion = ""
+				continue
+			}
+			return err
+		}
+		resourceVersion, err = s.consumePodWatch(watchIf, resourceVersion)
+		watchIf.Stop()
+		if err == errResourceVersionExpired {
+			resourceVersion = ""
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if elapsed := time.Since(watchStartedOn); elapsed < rewatchInterval {
+			time.Sleep(rewatchInterval - elapsed)
+		}
+	}
+	return nil
+}
+
+func (s *multiPodLogStreamer) consumePodWatch(watchIf watch.Interface, resourceVersion string) (string, error) {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return resourceVersion, nil
+		case event, ok := <-watchIf.ResultChan():
+			if !ok {
+				return resourceVersion, nil
+			}
+			switch event.Type {
+			case watch.Error:
+				err := apierrors.FromObject(event.Object)
+				if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+					return resourceVersion, errResourceVersionExpired
+				}
+				return resourceVersion, err
+			case watch.Added, watch.Modified:
+				pod, ok := event.Object.(*corev1.Pod)
+				if !ok {
+					continue
+				}
+				resourceVersion = pod.ResourceVersion
+				s.streamPod(pod, false)
+			case watch.Deleted:
+				// streams of deleted pod end on their own
+				if pod, ok := event.Object.(*corev1.Pod); ok {
+					resourceVersion = pod.ResourceVersion
+				}
+			}
+		}
+	}
+}
+
+// streamPod starts streams of started containers which are not being streamed yet, a container is streamed again
+// only after it restarts so that finished containers are not read repeatedly on every pod update. Streams beyond
+// the slots of request wait for a slot before opening.
+func (s *multiPodLogStreamer) streamPod(pod *corev1.Pod, initialPod bool) {
+	if !s.podAllowed(pod) {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for containerName, restartCount := range streamableContainers(pod, s.request.ContainerName) {
+		key := pod.Name + "/" + containerName
+		if s.streaming[key] {
+			continue
+		}
+		if streamedRestarts, ok := s.restarts[key]; ok && streamedRestarts == restartCount {
+			continue
+		}
+		s.streaming[key] = true
+		s.restarts[key] = restartCount
+		podLogOptions := buildMultiPodLogOptions(s.request, containerName, initialPod, s.lastSeen[key])
+		s.wg.Add(1)
+		go s.streamContainer(pod.Name, containerName, key, podLogOptions)
+	}
+}
+
+// streamContainer streams container once a slot is free, in follow mode stream is re-opened from last seen line
+// till container stops running
+func (s *multiPodLogStreamer) streamContainer(podName string, containerName string, key string, podLogOptions *corev1.PodLogOptions) {
+	defer s.wg.Done()
+	defer func() {
+		s.lock.Lock()
+		s.streaming[key] = false
+		s.lock.Unlock()
+	}()
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-s.ctx.Done():
+		return
+	}
+	for {
+		if err := s.readContainerLogs(podName, containerName, key, podLogOptions); err != nil || !s.request.Follow {
+			return
+		}
+		// wait before re-opening so that a failing stream is not retried in a tight loop
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(rewatchInterval):
+		}
+		pod, err := s.pods.Get(s.ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			// pod is gone, its containers are not streamed anymore
+			return
+		}
+		restartCount, running := runningContainerRestarts(pod, containerName)
+		if !running {
+			// restarted container is streamed again by streamPod once it is running
+			return
+		}
+		s.lock.Lock()
+		s.restarts[key] = restartCount
+		if lastSeen := s.lastSeen[key]; !lastSeen.IsZero() {
+			podLogOptions = buildMultiPodLogOptions(s.request, containerName, false, lastSeen)
+		}
+		s.lock.Unlock()
+	}
+}
+
+// readContainerLogs sends lines of one log stream till it ends, error is returned only when send fails
+func (s *multiPodLogStreamer) readContainerLogs(podName string, containerName string, key string, podLogOptions *corev1.PodLogOptions) error {
+	stream, err := s.pods.GetLogs(podName, podLogOptions).Stream(s.ctx)
+	if err != nil {
+		// pod may be gone by now, other containers keep streaming
+		if s.ctx.Err() == nil {
+			s.logger.Errorw("error in streaming pod logs", "err", err, "podName", podName, "containerName", containerName)
+		}
+		return nil
+	}
+	defer stream.Close()
+	bufReader := bufio.NewReader(stream)
+	for {
+		line, err := bufReader.ReadString('\n')
+		if len(line) > 0 {
+			if sendErr := s.sendLine(podName, containerName, key, line); sendErr != nil {
+				return sendErr
+			}
+		}
+		if err != nil {
+			if err != io.EOF && s.ctx.Err() == nil {
+				s.logger.Errorw("error in reading pod logs", "err", err, "podName", podName, "containerName", containerName)
+			}
+			return nil
+		}
+	}
+}
+
+// sendLine sends filtered line, failure in sending means client is gone and stops every stream
+func (s *multiPodLogStreamer) sendLine(podName string, containerName string, key string, line string) error {
+	logTime, log := parseTimestampedLogLine(line)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.sendErr != nil {
+		return s.sendErr
+	}
+	if !logTime.IsZero() {
+		s.lastSeen[key] = logTime
+	}
+	if !s.filter(log) {
+		return nil
+	}
+	err := s.send(&MultiPodLogLine{PodName: podName, ContainerName: containerName, Time: logTime, Log: log})
+	if err != nil {
+		s.sendErr = err
+		s.cancel()
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+	"time"
+)
+
+func TestNewLogLineFilter(t *testing.T) {
+	filter, err := newLogLineFilter("GET", `status=5\d\d`)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if !filter("GET /api status=503") || filter("GET /api status=200") || filter("POST /api status=500") {
+		t.Errorf("expected grep and regex to be applied together")
+	}
+	filter, _ = newLogLineFilter("", "")
+	if !filter("anything") {
+		t.Errorf("expected empty filter to keep every line")
+	}
+	if _, err = newLogLineFilter("", "("); err == nil {
+		t.Errorf("expected invalid regex to be rejected")
+	}
+}
+
+func TestParseTimestampedLogLine(t *testing.T) {
+	logTime, log := parseTimestampedLogLine("2023-03-01T10:00:00.123456789Z server started on :8080\n")
+	if log != "server started on :8080" || logTime.UnixNano() != time.Date(2023, 3, 1, 10, 0, 0, 123456789, time.UTC).UnixNano() {
+		t.Errorf("unexpected parsed line %v %q", logTime, log)
+	}
+	logTime, log = parseTimestampedLogLine("no timestamp here\r\n")
+	if !logTime.IsZero() || log != "no timestamp here" {
+		t.Errorf("expected line without timestamp to be kept as is, got %v %q", logTime, log)
+	}
+}
+
+func TestStreamableContainers(t *testing.T) {
+	pod := &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+		{Name: "app", RestartCount: 2, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		{Name: "sidecar", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}},
+		{Name: "migrate", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}},
+	}}}
+	containers := streamableContainers(pod, "")
+	if len(containers) != 2 || containers["app"] != 2 {
+		t.Errorf("expected started containers only, got %v", containers)
+	}
+	if containers = streamableContainers(pod, "migrate"); len(containers) != 1 {
+		t.Errorf("expected container name to filter containers, got %v", containers)
+	}
+}
+
+func TestRunningContainerRestarts(t *testing.T) {
+	pod := &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+		{Name: "app", RestartCount: 3, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		{Name: "migrate", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}},
+		{Name: "sidecar", RestartCount: 1, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}},
+	}}}
+	if restartCount, running := runningContainerRestarts(pod, "app"); !running || restartCount != 3 {
+		t.Errorf("expected stream of running container to be re-opened, got %d %v", restartCount, running)
+	}
+	for _, containerName := range []string{"migrate", "sidecar", "missing"} {
+		if _, running := runningContainerRestarts(pod, containerName); running {
+			t.Errorf("expected stream of %s not to be re-opened", containerName)
+		}
+	}
+}
+
+func TestBuildMultiPodLogOptions(t *testing.T) {
+	request := &MultiPodLogsRequest{TailLines: 100, SinceSeconds: 60, Follow: true}
+	options := buildMultiPodLogOptions(request, "app", true, time.Time{})
+	if *options.TailLines != 100 || *options.SinceSeconds != 60 || !options.Follow || !options.Timestamps || options.Container != "app" {
+		t.Errorf("expected tail and since for initial pods, got %+v", options)
+	}
+	options = buildMultiPodLogOptions(request, "app", false, time.Time{})
+	if options.TailLines != nil || options.SinceSeconds != nil || options.SinceTime != nil {
+		t.Errorf("expected new pods to be streamed from first line, got %+v", options)
+	}
+	lastSeen := time.Unix(100, 0)
+	options = buildMultiPodLogOptions(request, "app", true, lastSeen)
+	if options.TailLines != nil || options.SinceTime == nil || !options.SinceTime.Time.Equal(lastSeen.Add(time.Nanosecond)) {
+		t.Errorf("expected restarted stream to resume after last seen line, got %+v", options)
+	}
+}
+
+func TestWorkloadPodSelector(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "api"},
+		"spec": map[string]interface{}{"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"app": "api"},
+			"matchExpressions": []interface{}{
+				map[string]interface{}{"key": "tier", "operator": "In", "values": []interface{}{"web"}},
+			},
+		}},
+	}}
+	selector, err := workloadPodSelector(deployment)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if selector.String() != "app=api,tier in (web)" {
+		t.Errorf("unexpected selector %s", selector.String())
+	}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "ConfigMap", "metadata": map[string]interface{}{"name": "api"}}}
+	if _, err = workloadPodSelector(configMap); err == nil {
+		t.Errorf("expected error for resource without selector")
+	}
+	deployment.Object["spec"] = map[string]interface{}{"selector": map[string]interface{}{}}
+	if _, err = workloadPodSelector(deployment); err == nil {
+		t.Errorf("expected error for empty selector")
+	}
+}
@@ -0,0 +1,562 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultNodeMaintenanceTimeoutSecs = 600
+	evictionRetryInterval             = 5 * time.Second
+	podDeletionPollInterval           = 2 * time.Second
+)
+
+// podDisruptionBudgetState is the version independent view of a policy/v1 or policy/v1beta1 PodDisruptionBudget
+type podDisruptionBudgetState struct {
+	Namespace          string
+	Name               string
+	selector           labels.Selector
+	DisruptionsAllowed int32
+	CurrentHealthy     int32
+	DesiredHealthy     int32
+	ExpectedPods       int32
+}
+
+// neverAllowsEviction is true when budget can not allow an eviction even after all pods are healthy,
+// e.g. maxUnavailable 0 or minAvailable equal to replicas
+func (pdb *podDisruptionBudgetState) neverAllowsEviction() bool {
+	return pdb.ExpectedPods > 0 && pdb.DesiredHealthy >= pdb.ExpectedPods
+}
+
+type workloadRef struct {
+	kind     string
+	name     string
+	replicas *int32
+}
+
+func (impl *K8sCapacityServiceImpl) GetNodeDrainPlan(ctx context.Context, request *NodeMaintenanceRequest) (*NodeDrainPlan, error) {
+	k8sClientSet, nodes, err := impl.getMaintenanceNodes(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return impl.buildNodeDrainPlan(ctx, k8sClientSet, nodes, request)
+}
+
+// ExecuteNodeMaintenance cordons and drains planned nodes batch by batch. Before every batch the plan is
+// recomputed against live PodDisruptionBudgets, maintenance stops without touching further nodes as soon as
+// a workload blocks the drain. Already processed nodes are left cordoned.
+func (impl *K8sCapacityServiceImpl) ExecuteNodeMaintenance(ctx context.Context, request *NodeMaintenanceRequest, progress func(event *NodeMaintenanceEvent)) (*NodeMaintenanceResult, error) {
+	k8sClientSet, nodes, err := impl.getMaintenanceNodes(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	evictionGroupVersion, err := CheckEvictionSupport(k8sClientSet)
+	if err != nil {
+		impl.logger.Errorw("error in checking eviction support", "err", err, "clusterId", request.ClusterId)
+		return nil, err
+	}
+	if evictionGroupVersion.Empty() {
+		return nil, fmt.Errorf("cluster does not support pod eviction, pod disruption budgets can not be respected")
+	}
+	plan, err := impl.buildNodeDrainPlan(ctx, k8sClientSet, nodes, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var publishLock sync.Mutex
+	publish := func(event *NodeMaintenanceEvent) {
+		publishLock.Lock()
+		defer publishLock.Unlock()
+		event.Time = time.Now()
+		progress(event)
+	}
+	publish(&NodeMaintenanceEvent{Type: NODE_MAINTENANCE_EVENT_PLAN, Plan: plan})
+
+	result := &NodeMaintenanceResult{Status: NODE_MAINTENANCE_STATUS_COMPLETED, DrainedNodes: []string{}, PendingNodes: []string{}}
+	for batchIndex, batch := range plan.Batches {
+		batchNodes, err := getNodesByName(ctx, k8sClientSet, batch)
+		if err != nil {
+			impl.logger.Errorw("error in getting nodes for maintenance batch", "err", err, "nodes", batch)
+			return impl.stopNodeMaintenance(result, plan, batchIndex, NODE_MAINTENANCE_STATUS_FAILED, err.Error(), nil, publish), nil
+		}
+		// all nodes of batch are drained together, so live plan is computed as a single batch
+		batchRequest := *request
+		batchRequest.Parallelism = len(batch)
+		livePlan, err := impl.buildNodeDrainPlan(ctx, k8sClientSet, batchNodes, &batchRequest)
+		if err != nil {
+			return impl.stopNodeMaintenance(result, plan, batchIndex, NODE_MAINTENANCE_STATUS_FAILED, err.Error(), nil, publish), nil
+		}
+		if len(livePlan.Blockers) > 0 {
+			return impl.stopNodeMaintenance(result, plan, batchIndex, NODE_MAINTENANCE_STATUS_BLOCKED, "drain blocked before starting batch", livePlan.Blockers, publish), nil
+		}
+		publish(&NodeMaintenanceEvent{Type: NODE_MAINTENANCE_EVENT_BATCH_STARTED, Batch: batchIndex, Message: strings.Join(batch, ", ")})
+
+		var wg sync.WaitGroup
+		var resultLock sync.Mutex
+		var blockers []*NodeDrainBlocker
+		var errs []string
+		for _, planNode := range livePlan.Nodes {
+			wg.Add(1)
+			go func(planNode *NodeDrainPlanNode) {
+				defer wg.Done()
+				nodeBlockers, err := impl.drainPlannedNode(ctx, k8sClientSet, evictionGroupVersion, planNode, batchIndex, request, publish)
+				impl.saveNodeAuditLog(ctx, request.ClusterId, planNode.Name, kubernetesResourceAuditLogs.AUDIT_ACTION_DRAIN, nil, nil,
+					fmt.Sprintf("node maintenance batch %d", batchIndex+1), nodeDrainAuditError(nodeBlockers, err))
+				resultLock.Lock()
+				defer resultLock.Unlock()
+				if err != nil {
+					impl.logger.Errorw("error in draining node", "err", err, "nodeName", planNode.Name)
+					errs = append(errs, fmt.Sprintf("%s: %s", planNode.Name, err.Error()))
+					return
+				}
+				blockers = append(blockers, nodeBlockers...)
+			}(planNode)
+		}
+		wg.Wait()
+		if len(blockers) > 0 {
+			return impl.stopNodeMaintenance(result, plan, batchIndex, NODE_MAINTENANCE_STATUS_BLOCKED, "eviction would violate pod disruption budget", blockers, publish), nil
+		}
+		if len(errs) > 0 {
+			return impl.stopNodeMaintenance(result, plan, batchIndex, NODE_MAINTENANCE_STATUS_FAILED, strings.Join(errs, "; "), nil, publish), nil
+		}
+		result.DrainedNodes = append(result.DrainedNodes, batch...)
+	}
+	result.Message = fmt.Sprintf("%d nodes drained", len(result.DrainedNodes))
+	publish(&NodeMaintenanceEvent{Type: NODE_MAINTENANCE_EVENT_COMPLETED, Result: result})
+	return result, nil
+}
+
+func (impl *K8sCapacityServiceImpl) stopNodeMaintenance(result *NodeMaintenanceResult, plan *NodeDrainPlan, batchIndex int, status string, message string,
+	blockers []*NodeDrainBlocker, publish func(event *NodeMaintenanceEvent)) *NodeMaintenanceResult {
+	result.Status = status
+	result.Message = message
+	result.Blockers = blockers
+	for _, batch := range plan.Batches[batchIndex:] {
+		result.PendingNodes = append(result.PendingNodes, batch...)
+	}
+	eventType := NODE_MAINTENANCE_EVENT_FAILED
+	if status == NODE_MAINTENANCE_STATUS_BLOCKED {
+		eventType = NODE_MAINTENANCE_EVENT_BLOCKED
+	}
+	impl.logger.Infow("node maintenance stopped", "status", status, "message", message, "pendingNodes", result.PendingNodes)
+	publish(&NodeMaintenanceEvent{Type: eventType, Batch: batchIndex, Message: message, Result: result})
+	return result
+}
+
+// drainPlannedNode cordons the node and evicts planned pods one by one, eviction rejected by a pod disruption budget
+// is retried until node timeout after which the pod workload is returned as blocker
+func (impl *K8sCapacityServiceImpl) drainPlannedNode(ctx context.Context, k8sClientSet *kubernetes.Clientset, evictionGroupVersion schema.GroupVersion,
+	planNode *NodeDrainPlanNode, batchIndex int, request *NodeMaintenanceRequest, publish func(event *NodeMaintenanceEvent)) ([]*NodeDrainBlocker, error) {
+	if !planNode.Unschedulable {
+		node, err := k8sClientSet.CoreV1().Nodes().Get(ctx, planNode.Name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		liveNode := node.DeepCopy()
+		node, err = updateNodeUnschedulableProperty(true, node, k8sClientSet)
+		impl.saveNodeAuditLog(ctx, request.ClusterId, planNode.Name, kubernetesResourceAuditLogs.AUDIT_ACTION_CORDON, liveNode, node, "cordoned for node maintenance", err)
+		if err != nil {
+			return nil, err
+		}
+		publish(&NodeMaintenanceEvent{Type: NODE_MAINTENANCE_EVENT_NODE_CORDONED, Batch: batchIndex, NodeName: planNode.Name})
+	}
+	deadline := time.Now().Add(time.Duration(request.NodeTimeoutSeconds) * time.Second)
+	deleteOptions := v1.DeleteOptions{}
+	if request.NodeDrainHelper.GracePeriodSeconds >= 0 {
+		gracePeriodSecConverted := int64(request.NodeDrainHelper.GracePeriodSeconds)
+		deleteOptions.GracePeriodSeconds = &gracePeriodSecConverted
+	}
+	var evictedPods []corev1.Pod
+	for _, planPod := range planNode.Pods {
+		if planPod.Action != NODE_DRAIN_POD_ACTION_EVICT {
+			continue
+		}
+		for {
+			err := EvictPod(planPod.pod, k8sClientSet, evictionGroupVersion, deleteOptions)
+			if err == nil || apierrors.IsNotFound(err) {
+				break
+			}
+			if !apierrors.IsTooManyRequests(err) {
+				return nil, fmt.Errorf("error when evicting pods/%q -n %q: %v", planPod.Name, planPod.Namespace, err)
+			}
+			if time.Now().After(deadline) {
+				return []*NodeDrainBlocker{newPodDisruptionBlocker(planNode.Name, planPod, err.Error())}, nil
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(evictionRetryInterval):
+			}
+		}
+		evictedPods = append(evictedPods, planPod.pod)
+		publish(&NodeMaintenanceEvent{Type: NODE_MAINTENANCE_EVENT_POD_EVICTED, Batch: batchIndex, NodeName: planNode.Name, Namespace: planPod.Namespace, PodName: planPod.Name})
+	}
+	if err := waitForPodsDeletion(ctx, k8sClientSet, evictedPods, deadline); err != nil {
+		return nil, err
+	}
+	publish(&NodeMaintenanceEvent{Type: NODE_MAINTENANCE_EVENT_NODE_DRAINED, Batch: batchIndex, NodeName: planNode.Name})
+	return nil, nil
+}
+
+func waitForPodsDeletion(ctx context.Context, k8sClientSet *kubernetes.Clientset, pods []corev1.Pod, deadline time.Time) error {
+	pending := pods
+	for len(pending) > 0 {
+		var stillPending []corev1.Pod
+		for _, pod := range pending {
+			currentPod, err := k8sClientSet.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, v1.GetOptions{})
+			if apierrors.IsNotFound(err) || (err == nil && currentPod.UID != pod.UID) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			stillPending = append(stillPending, pod)
+		}
+		pending = stillPending
+		if len(pending) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for %d pods to terminate", len(pending))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(podDeletionPollInterval):
+		}
+	}
+	return nil
+}
+
+// getMaintenanceNodes validates request, applies defaults and returns client set with selected nodes
+func (impl *K8sCapacityServiceImpl) getMaintenanceNodes(ctx context.Context, request *NodeMaintenanceRequest) (*kubernetes.Clientset, []corev1.Node, error) {
+	if (len(request.NodeNames) == 0) == (len(request.NodeSelector) == 0) {
+		return nil, nil, fmt.Errorf("either node names or node selector is required")
+	}
+	if request.NodeDrainHelper == nil {
+		request.NodeDrainHelper = &NodeDrainHelper{GracePeriodSeconds: -1}
+	}
+	if request.NodeDrainHelper.DisableEviction {
+		return nil, nil, fmt.Errorf("pod disruption budget aware drain requires eviction, disableEviction is not supported")
+	}
+	if request.Parallelism <= 0 {
+		request.Parallelism = 1
+	}
+	if request.NodeTimeoutSeconds <= 0 {
+		request.NodeTimeoutSeconds = defaultNodeMaintenanceTimeoutSecs
+	}
+	cluster, err := impl.getClusterBean(request.ClusterId)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, _, k8sClientSet, err := impl.getK8sConfigAndClients(ctx, cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	request.NodeDrainHelper.k8sClientSet = k8sClientSet
+	var nodes []corev1.Node
+	if len(request.NodeSelector) > 0 {
+		selector, err := labels.Parse(request.NodeSelector)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid node selector %q: %v", request.NodeSelector, err)
+		}
+		nodeList, err := k8sClientSet.CoreV1().Nodes().List(ctx, v1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			impl.logger.Errorw("error in listing nodes by selector", "err", err, "selector", request.NodeSelector)
+			return nil, nil, err
+		}
+		nodes = nodeList.Items
+		if len(nodes) == 0 {
+			return nil, nil, fmt.Errorf("no node matches selector %q", request.NodeSelector)
+		}
+	} else {
+		nodes, err = getNodesByName(ctx, k8sClientSet, request.NodeNames)
+		if err != nil {
+			impl.logger.Errorw("error in getting nodes", "err", err, "nodeNames", request.NodeNames)
+			return nil, nil, err
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	return k8sClientSet, nodes, nil
+}
+
+func getNodesByName(ctx context.Context, k8sClientSet *kubernetes.Clientset, nodeNames []string) ([]corev1.Node, error) {
+	var nodes []corev1.Node
+	for _, nodeName := range nodeNames {
+		node, err := k8sClientSet.CoreV1().Nodes().Get(ctx, nodeName, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, *node)
+	}
+	return nodes, nil
+}
+
+func (impl *K8sCapacityServiceImpl) buildNodeDrainPlan(ctx context.Context, k8sClientSet *kubernetes.Clientset, nodes []corev1.Node, request *NodeMaintenanceRequest) (*NodeDrainPlan, error) {
+	pdbs, err := impl.listPodDisruptionBudgets(ctx, k8sClientSet)
+	if err != nil {
+		return nil, err
+	}
+	workloads := make(map[string]*workloadRef)
+	plan := &NodeDrainPlan{ClusterId: request.ClusterId}
+	filters := request.NodeDrainHelper.makeFilters()
+	for _, node := range nodes {
+		podList, err := k8sClientSet.CoreV1().Pods(corev1.NamespaceAll).List(ctx, v1.ListOptions{
+			FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": node.Name}).String(),
+		})
+		if err != nil {
+			impl.logger.Errorw("error in listing pods of node", "err", err, "nodeName", node.Name)
+			return nil, err
+		}
+		planNode := &NodeDrainPlanNode{Name: node.Name, Unschedulable: node.Spec.Unschedulable}
+		for _, podDelete := range filterPods(podList, filters).items {
+			planPod := newNodeDrainPlanPod(podDelete)
+			workload := impl.resolveWorkload(ctx, k8sClientSet, podDelete.Pod, workloads)
+			planPod.WorkloadKind, planPod.WorkloadName, planPod.Replicas = workload.kind, workload.name, workload.replicas
+			planNode.Pods = append(planNode.Pods, planPod)
+		}
+		plan.Nodes = append(plan.Nodes, planNode)
+	}
+	assignDrainBatches(plan, request.Parallelism)
+	applyPodDisruptionBudgets(plan, pdbs)
+	return plan, nil
+}
+
+func newNodeDrainPlanPod(podDelete PodDelete) *NodeDrainPlanPod {
+	planPod := &NodeDrainPlanPod{
+		Name:      podDelete.Pod.Name,
+		Namespace: podDelete.Pod.Namespace,
+		Message:   podDelete.Status.Message,
+		pod:       podDelete.Pod,
+	}
+	switch {
+	case podDelete.Status.Delete:
+		planPod.Action = NODE_DRAIN_POD_ACTION_EVICT
+	case podDelete.Status.Reason == PodDeleteStatusTypeError:
+		planPod.Action = NODE_DRAIN_POD_ACTION_BLOCKED
+	default:
+		planPod.Action = NODE_DRAIN_POD_ACTION_SKIP
+	}
+	return planPod
+}
+
+// resolveWorkload finds top level controller of pod with its desired replicas, results are cached per controller
+func (impl *K8sCapacityServiceImpl) resolveWorkload(ctx context.Context, k8sClientSet *kubernetes.Clientset, pod corev1.Pod, workloads map[string]*workloadRef) *workloadRef {
+	controllerRef := v1.GetControllerOf(&pod)
+	if controllerRef == nil {
+		return &workloadRef{kind: "Pod", name: pod.Name}
+	}
+	key := fmt.Sprintf("%s/%s/%s", pod.Namespace, controllerRef.Kind, controllerRef.Name)
+	if workload, ok := workloads[key]; ok {
+		return workload
+	}
+	workload := &workloadRef{kind: controllerRef.Kind, name: controllerRef.Name}
+	switch controllerRef.Kind {
+	case "ReplicaSet":
+		replicaSet, err := k8sClientSet.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, controllerRef.Name, v1.GetOptions{})
+		if err != nil {
+			impl.logger.Warnw("error in getting replica set of pod", "err", err, "namespace", pod.Namespace, "name", controllerRef.Name)
+			break
+		}
+		workload.replicas = replicaSet.Spec.Replicas
+		if deploymentRef := v1.GetControllerOf(replicaSet); deploymentRef != nil && deploymentRef.Kind == "Deployment" {
+			workload.kind, workload.name = deploymentRef.Kind, deploymentRef.Name
+			if deployment, err := k8sClientSet.AppsV1().Deployments(pod.Namespace).Get(ctx, deploymentRef.Name, v1.GetOptions{}); err == nil {
+				workload.replicas = deployment.Spec.Replicas
+			}
+		}
+	case "StatefulSet":
+		statefulSet, err := k8sClientSet.AppsV1().StatefulSets(pod.Namespace).Get(ctx, controllerRef.Name, v1.GetOptions{})
+		if err != nil {
+			impl.logger.Warnw("error in getting stateful set of pod", "err", err, "namespace", pod.Namespace, "name", controllerRef.Name)
+			break
+		}
+		workload.replicas = statefulSet.Spec.Replicas
+	}
+	workloads[key] = workload
+	return workload
+}
+
+// listPodDisruptionBudgets lists budgets of all namespaces, falling back to policy/v1beta1 for clusters older than 1.21
+func (impl *K8sCapacityServiceImpl) listPodDisruptionBudgets(ctx context.Context, k8sClientSet *kubernetes.Clientset) ([]*podDisruptionBudgetState, error) {
+	var pdbs []*podDisruptionBudgetState
+	pdbList, err := k8sClientSet.PolicyV1().PodDisruptionBudgets(corev1.NamespaceAll).List(ctx, v1.ListOptions{})
+	if err == nil {
+		for _, pdb := range pdbList.Items {
+			selector, err := v1.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if err != nil {
+				impl.logger.Warnw("invalid pod disruption budget selector", "err", err, "namespace", pdb.Namespace, "name", pdb.Name)
+				continue
+			}
+			pdbs = append(pdbs, &podDisruptionBudgetState{Namespace: pdb.Namespace, Name: pdb.Name, selector: selector, DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+				CurrentHealthy: pdb.Status.CurrentHealthy, DesiredHealthy: pdb.Status.DesiredHealthy, ExpectedPods: pdb.Status.ExpectedPods})
+		}
+		return pdbs, nil
+	} else if !apierrors.IsNotFound(err) {
+		impl.logger.Errorw("error in listing pod disruption budgets", "err", err)
+		return nil, err
+	}
+	pdbBetaList, err := k8sClientSet.PolicyV1beta1().PodDisruptionBudgets(corev1.NamespaceAll).List(ctx, v1.ListOptions{})
+	if err != nil {
+		impl.logger.Errorw("error in listing policy/v1beta1 pod disruption budgets", "err", err)
+		return nil, err
+	}
+	for _, pdb := range pdbBetaList.Items {
+		// empty selector matches no pod in policy/v1beta1
+		selector := labels.Nothing()
+		if pdb.Spec.Selector != nil && (len(pdb.Spec.Selector.MatchLabels) > 0 || len(pdb.Spec.Selector.MatchExpressions) > 0) {
+			selector, err = v1.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if err != nil {
+				impl.logger.Warnw("invalid pod disruption budget selector", "err", err, "namespace", pdb.Namespace, "name", pdb.Name)
+				continue
+			}
+		}
+		pdbs = append(pdbs, &podDisruptionBudgetState{Namespace: pdb.Namespace, Name: pdb.Name, selector: selector, DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+			CurrentHealthy: pdb.Status.CurrentHealthy, DesiredHealthy: pdb.Status.DesiredHealthy, ExpectedPods: pdb.Status.ExpectedPods})
+	}
+	return pdbs, nil
+}
+
+func assignDrainBatches(plan *NodeDrainPlan, parallelism int) {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	plan.Batches = [][]string{}
+	for i, planNode := range plan.Nodes {
+		planNode.Batch = i / parallelism
+		if planNode.Batch == len(plan.Batches) {
+			plan.Batches = append(plan.Batches, []string{})
+		}
+		plan.Batches[planNode.Batch] = append(plan.Batches[planNode.Batch], planNode.Name)
+	}
+}
+
+// applyPodDisruptionBudgets marks pods which can never be evicted as blocked and collects blockers per workload.
+// Budgets which allow fewer disruptions than pods evicted in a batch only produce warnings, such evictions
+// are retried until replacement pods become healthy.
+func applyPodDisruptionBudgets(plan *NodeDrainPlan, pdbs []*podDisruptionBudgetState) {
+	blockers := make(map[string]*NodeDrainBlocker)
+	var blockerKeys []string
+	addBlocker := func(planNode *NodeDrainPlanNode, blocker *NodeDrainBlocker) {
+		key := fmt.Sprintf("%s/%s/%s/%s/%s", blocker.Namespace, blocker.WorkloadKind, blocker.WorkloadName, blocker.PodDisruptionBudget, blocker.Reason)
+		if existing, ok := blockers[key]; ok {
+			if existing.NodeNames[len(existing.NodeNames)-1] != planNode.Name {
+				existing.NodeNames = append(existing.NodeNames, planNode.Name)
+			}
+			return
+		}
+		blocker.NodeNames = []string{planNode.Name}
+		blockers[key] = blocker
+		blockerKeys = append(blockerKeys, key)
+	}
+
+	for batchIndex := range plan.Batches {
+		evictionsByPdb := make(map[*podDisruptionBudgetState]int)
+		evictionsByWorkload := make(map[string]int)
+		var batchPods []*NodeDrainPlanPod
+		for _, planNode := range plan.Nodes {
+			if planNode.Batch != batchIndex {
+				continue
+			}
+			for _, planPod := range planNode.Pods {
+				if planPod.Action == NODE_DRAIN_POD_ACTION_BLOCKED {
+					addBlocker(planNode, &NodeDrainBlocker{Namespace: planPod.Namespace, WorkloadKind: planPod.WorkloadKind, WorkloadName: planPod.WorkloadName, Reason: planPod.Message})
+					continue
+				}
+				if planPod.Action != NODE_DRAIN_POD_ACTION_EVICT {
+					continue
+				}
+				batchPods = append(batchPods, planPod)
+				evictionsByWorkload[planPod.Namespace+"/"+planPod.WorkloadKind+"/"+planPod.WorkloadName]++
+				for _, pdb := range pdbs {
+					if pdb.Namespace != planPod.Namespace || !pdb.selector.Matches(labels.Set(planPod.pod.Labels)) {
+						continue
+					}
+					planPod.PodDisruptionBudgets = append(planPod.PodDisruptionBudgets, pdb.Name)
+					evictionsByPdb[pdb]++
+					if pdb.neverAllowsEviction() {
+						planPod.Action = NODE_DRAIN_POD_ACTION_BLOCKED
+						planPod.Message = fmt.Sprintf("pod disruption budget %s requires %d of %d pods to be healthy", pdb.Name, pdb.DesiredHealthy, pdb.ExpectedPods)
+						disruptionsAllowed := pdb.DisruptionsAllowed
+						blocker := newPodDisruptionBlocker(planNode.Name, planPod, planPod.Message)
+						blocker.PodDisruptionBudget, blocker.DisruptionsAllowed = pdb.Name, &disruptionsAllowed
+						addBlocker(planNode, blocker)
+					}
+				}
+			}
+		}
+		for _, planPod := range batchPods {
+			if planPod.Action != NODE_DRAIN_POD_ACTION_EVICT {
+				continue
+			}
+			if planPod.Replicas != nil && *planPod.Replicas <= 1 && len(planPod.PodDisruptionBudgets) == 0 {
+				planPod.Warnings = append(planPod.Warnings, fmt.Sprintf("%s %s has a single replica, eviction causes downtime", planPod.WorkloadKind, planPod.WorkloadName))
+			} else if planPod.Replicas != nil && *planPod.Replicas > 1 && int32(evictionsByWorkload[planPod.Namespace+"/"+planPod.WorkloadKind+"/"+planPod.WorkloadName]) >= *planPod.Replicas {
+				planPod.Warnings = append(planPod.Warnings, fmt.Sprintf("all %d replicas of %s %s are drained in batch %d", *planPod.Replicas, planPod.WorkloadKind, planPod.WorkloadName, batchIndex))
+			}
+			for _, pdb := range pdbs {
+				if count, ok := evictionsByPdb[pdb]; ok && int32(count) > pdb.DisruptionsAllowed && containsString(planPod.PodDisruptionBudgets, pdb.Name) {
+					planPod.Warnings = append(planPod.Warnings, fmt.Sprintf("pod disruption budget %s allows %d disruptions for %d evictions in batch %d, evictions wait for replacement pods", pdb.Name, pdb.DisruptionsAllowed, count, batchIndex))
+				}
+			}
+		}
+	}
+
+	plan.Blockers = []*NodeDrainBlocker{}
+	for _, key := range blockerKeys {
+		plan.Blockers = append(plan.Blockers, blockers[key])
+	}
+	plan.Status = NODE_DRAIN_PLAN_STATUS_READY
+	for _, planNode := range plan.Nodes {
+		planNode.Status = NODE_DRAIN_PLAN_STATUS_READY
+		for _, planPod := range planNode.Pods {
+			if planPod.Action == NODE_DRAIN_POD_ACTION_BLOCKED {
+				planNode.Status = NODE_DRAIN_PLAN_STATUS_BLOCKED
+				plan.Status = NODE_DRAIN_PLAN_STATUS_BLOCKED
+				break
+			}
+		}
+	}
+}
+
+func newPodDisruptionBlocker(nodeName string, planPod *NodeDrainPlanPod, reason string) *NodeDrainBlocker {
+	return &NodeDrainBlocker{
+		NodeNames:           []string{nodeName},
+		Namespace:           planPod.Namespace,
+		WorkloadKind:        planPod.WorkloadKind,
+		WorkloadName:        planPod.WorkloadName,
+		PodDisruptionBudget: strings.Join(planPod.PodDisruptionBudgets, ","),
+		Reason:              reason,
+	}
+}
+
+// nodeDrainAuditError is the outcome of a node drain as recorded in audit log, a blocked drain is recorded as failed
+func nodeDrainAuditError(blockers []*NodeDrainBlocker, err error) error {
+	if err != nil || len(blockers) == 0 {
+		return err
+	}
+	blocker := blockers[0]
+	return fmt.Errorf("drain blocked by %s %s/%s: %s", blocker.WorkloadKind, blocker.Namespace, blocker.WorkloadName, blocker.Reason)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
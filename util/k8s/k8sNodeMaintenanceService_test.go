@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"testing"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func newTestPlanPod(name string, workload string, replicas int32, podLabels map[string]string) *NodeDrainPlanPod {
+	pod := newTestPod(name)
+	pod.Labels = podLabels
+	return &NodeDrainPlanPod{Name: name, Namespace: testNamespace, WorkloadKind: "Deployment", WorkloadName: workload, Replicas: int32Ptr(replicas), Action: NODE_DRAIN_POD_ACTION_EVICT, pod: *pod}
+}
+
+func TestAssignDrainBatches(t *testing.T) {
+	plan := &NodeDrainPlan{Nodes: []*NodeDrainPlanNode{{Name: "a"}, {Name: "b"}, {Name: "c"}}}
+	assignDrainBatches(plan, 2)
+	if len(plan.Batches) != 2 || len(plan.Batches[0]) != 2 || plan.Batches[1][0] != "c" || plan.Nodes[2].Batch != 1 {
+		t.Errorf("unexpected batches %v", plan.Batches)
+	}
+	assignDrainBatches(plan, 0)
+	if len(plan.Batches) != 3 {
+		t.Errorf("expected a batch per node, got %v", plan.Batches)
+	}
+}
+
+func TestApplyPodDisruptionBudgets(t *testing.T) {
+	pdbs := []*podDisruptionBudgetState{
+		{Namespace: "prod", Name: "api-pdb", selector: labels.SelectorFromSet(labels.Set{"app": "api"}), DisruptionsAllowed: 1, CurrentHealthy: 3, DesiredHealthy: 2, ExpectedPods: 3},
+		{Namespace: "prod", Name: "db-pdb", selector: labels.SelectorFromSet(labels.Set{"app": "db"}), DisruptionsAllowed: 0, CurrentHealthy: 2, DesiredHealthy: 2, ExpectedPods: 2},
+	}
+	plan := &NodeDrainPlan{Nodes: []*NodeDrainPlanNode{
+		{Name: "node-1", Pods: []*NodeDrainPlanPod{
+			newTestPlanPod("api-1", "api", 3, map[string]string{"app": "api"}),
+			newTestPlanPod("api-2", "api", 3, map[string]string{"app": "api"}),
+			newTestPlanPod("worker-1", "worker", 1, map[string]string{"app": "worker"}),
+		}},
+		{Name: "node-2", Pods: []*NodeDrainPlanPod{
+			newTestPlanPod("db-1", "db", 2, map[string]string{"app": "db"}),
+			{Name: "bare", Namespace: "prod", WorkloadKind: "Pod", WorkloadName: "bare", Action: NODE_DRAIN_POD_ACTION_BLOCKED, Message: unmanagedFatal},
+		}},
+	}}
+	assignDrainBatches(plan, 1)
+	applyPodDisruptionBudgets(plan, pdbs)
+
+	if plan.Status != NODE_DRAIN_PLAN_STATUS_BLOCKED || plan.Nodes[0].Status != NODE_DRAIN_PLAN_STATUS_READY || plan.Nodes[1].Status != NODE_DRAIN_PLAN_STATUS_BLOCKED {
+		t.Errorf("unexpected plan status %s, node statuses %s %s", plan.Status, plan.Nodes[0].Status, plan.Nodes[1].Status)
+	}
+	apiPod := plan.Nodes[0].Pods[0]
+	if apiPod.Action != NODE_DRAIN_POD_ACTION_EVICT || len(apiPod.PodDisruptionBudgets) != 1 || len(apiPod.Warnings) != 1 {
+		t.Errorf("expected api pod to be evicted with budget warning, got %s %v %v", apiPod.Action, apiPod.PodDisruptionBudgets, apiPod.Warnings)
+	}
+	if workerPod := plan.Nodes[0].Pods[2]; len(workerPod.Warnings) != 1 {
+		t.Errorf("expected single replica warning, got %v", workerPod.Warnings)
+	}
+	if dbPod := plan.Nodes[1].Pods[0]; dbPod.Action != NODE_DRAIN_POD_ACTION_BLOCKED {
+		t.Errorf("expected db pod to be blocked, got %s", dbPod.Action)
+	}
+	if len(plan.Blockers) != 2 {
+		t.Fatalf("expected 2 blockers, got %d", len(plan.Blockers))
+	}
+	dbBlocker := plan.Blockers[0]
+	if dbBlocker.WorkloadName != "db" || dbBlocker.PodDisruptionBudget != "db-pdb" || *dbBlocker.DisruptionsAllowed != 0 || dbBlocker.NodeNames[0] != "node-2" {
+		t.Errorf("unexpected db blocker %+v", dbBlocker)
+	}
+	if bareBlocker := plan.Blockers[1]; bareBlocker.WorkloadKind != "Pod" || bareBlocker.Reason != unmanagedFatal {
+		t.Errorf("unexpected unmanaged pod blocker %+v", bareBlocker)
+	}
+}
+
+func TestApplyPodDisruptionBudgetsAllReplicasInBatch(t *testing.T) {
+	plan := &NodeDrainPlan{Nodes: []*NodeDrainPlanNode{
+		{Name: "node-1", Pods: []*NodeDrainPlanPod{newTestPlanPod("web-1", "web", 2, nil)}},
+		{Name: "node-2", Pods: []*NodeDrainPlanPod{newTestPlanPod("web-2", "web", 2, nil)}},
+	}}
+	assignDrainBatches(plan, 2)
+	applyPodDisruptionBudgets(plan, nil)
+	if plan.Status != NODE_DRAIN_PLAN_STATUS_READY || len(plan.Blockers) != 0 {
+		t.Errorf("unexpected plan status %s, blockers %v", plan.Status, plan.Blockers)
+	}
+	if warnings := plan.Nodes[1].Pods[0].Warnings; len(warnings) != 1 {
+		t.Errorf("expected all replicas warning, got %v", warnings)
+	}
+}
@@ -0,0 +1,252 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/kubernetesResourceAuditLogs"
+	"io"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+var errPortForwardIdleTimeout = errors.New("port forward session closed as it was idle")
+
+// ResolvePortForwardTarget finds pod and container port to forward, services are resolved to a ready pod selected
+// by them and to target port of requested service port
+func (impl *K8sApplicationServiceImpl) ResolvePortForwardTarget(ctx context.Context, request *PortForwardRequest) (*PortForwardTarget, error) {
+	if request.Port <= 0 || request.Port > 65535 {
+		return nil, fmt.Errorf("invalid port: %d", request.Port)
+	}
+	k8sClientSet, _, err := impl.getPortForwardClientSet(ctx, request.ClusterId)
+	if err != nil {
+		return nil, err
+	}
+	pods := k8sClientSet.CoreV1().Pods(request.Namespace)
+	switch request.Kind {
+	case "Pod":
+		pod, err := pods.Get(ctx, request.Name, metav1.GetOptions{})
+		if err != nil {
+			impl.logger.Errorw("error in getting pod", "err", err, "namespace", request.Namespace, "podName", request.Name)
+			return nil, err
+		}
+		if pod.Status.Phase != corev1.PodRunning {
+			return nil, fmt.Errorf("pod %s is not running, current phase: %s", pod.Name, pod.Status.Phase)
+		}
+		return &PortForwardTarget{PodName: pod.Name, Port: request.Port}, nil
+	case "Service":
+		service, err := k8sClientSet.CoreV1().Services(request.Namespace).Get(ctx, request.Name, metav1.GetOptions{})
+		if err != nil {
+			impl.logger.Errorw("error in getting service", "err", err, "namespace", request.Namespace, "serviceName", request.Name)
+			return nil, err
+		}
+		if len(service.Spec.Selector) == 0 {
+			return nil, fmt.Errorf("service %s does not select any pod", service.Name)
+		}
+		podList, err := pods.List(ctx, metav1.ListOptions{LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String()})
+		if err != nil {
+			impl.logger.Errorw("error in listing pods of service", "err", err, "namespace", request.Namespace, "serviceName", request.Name)
+			return nil, err
+		}
+		pod, err := selectPortForwardPod(podList.Items)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %v", service.Name, err)
+		}
+		port, err := resolveServiceTargetPort(service, request.Port, pod)
+		if err != nil {
+			return nil, err
+		}
+		return &PortForwardTarget{PodName: pod.Name, Port: port}, nil
+	}
+	return nil, fmt.Errorf("port forward is not supported for kind %s", request.Kind)
+}
+
+// PortForward relays bytes between conn and target port till either side closes, session exceeds configured max
+// duration or stays idle for idle timeout. Session start is recorded in audit log against requested resource.
+// conn is not closed here, caller closes it once PortForward returns.
+func (impl *K8sApplicationServiceImpl) PortForward(ctx context.Context, request *PortForwardRequest, target *PortForwardTarget, conn io.ReadWriter) error {
+	maxDuration := time.Duration(impl.K8sApplicationServiceConfig.PortForwardMaxDurationMins) * time.Minute
+	idleTimeout := time.Duration(impl.K8sApplicationServiceConfig.PortForwardIdleTimeoutMins) * time.Minute
+	k8sClientSet, restConfig, err := impl.getPortForwardClientSet(ctx, request.ClusterId)
+	if err != nil {
+		return err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+	if err != nil {
+		impl.logger.Errorw("error in creating spdy round tripper", "err", err, "clusterId", request.ClusterId)
+		return err
+	}
+	portForwardUrl := k8sClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(request.Namespace).Name(target.PodName).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, portForwardUrl)
+	streamConn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	impl.k8sResourceAuditLogService.SaveAuditLog(ctx, &kubernetesResourceAuditLogs.K8sResourceAuditLogRequest{
+		ClusterId:        request.ClusterId,
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: request.Kind},
+		Namespace:        request.Namespace,
+		Name:             request.Name,
+		Action:           kubernetesResourceAuditLogs.AUDIT_ACTION_PORT_FORWARD,
+		Message:          fmt.Sprintf("pod: %s, port: %d, max duration: %s", target.PodName, target.Port, maxDuration),
+		Err:              err,
+	})
+	if err != nil {
+		impl.logger.Errorw("error in dialing port forward", "err", err, "namespace", request.Namespace, "podName", target.PodName)
+		return err
+	}
+	defer streamConn.Close()
+
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(target.Port))
+	headers.Set(corev1.PortForwardRequestIDHeader, "0")
+	errorStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		impl.logger.Errorw("error in creating port forward error stream", "err", err, "podName", target.PodName, "port", target.Port)
+		return err
+	}
+	// nothing is written on error stream
+	errorStream.Close()
+	remoteErr := make(chan error, 1)
+	go func() {
+		message, err := ioutil.ReadAll(errorStream)
+		if err != nil {
+			remoteErr <- err
+		} else if len(message) > 0 {
+			remoteErr <- fmt.Errorf("error in forwarding port %d: %s", target.Port, string(message))
+		}
+	}()
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		impl.logger.Errorw("error in creating port forward data stream", "err", err, "podName", target.PodName, "port", target.Port)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, maxDuration)
+	defer cancel()
+	return relayPortForward(ctx, conn, dataStream, remoteErr, idleTimeout)
+}
+
+func (impl *K8sApplicationServiceImpl) getPortForwardClientSet(ctx context.Context, clusterId int) (*kubernetes.Clientset, *rest.Config, error) {
+	restConfig, err := impl.GetRestConfigByClusterId(ctx, clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config by cluster Id", "err", err, "clusterId", clusterId)
+		return nil, nil, err
+	}
+	k8sHttpClient, err := util.OverrideK8sHttpClientWithTracer(restConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	k8sClientSet, err := kubernetes.NewForConfigAndClient(restConfig, k8sHttpClient)
+	if err != nil {
+		impl.logger.Errorw("error in getting client set by rest config", "err", err, "clusterId", clusterId)
+		return nil, nil, err
+	}
+	return k8sClientSet, restConfig, nil
+}
+
+// selectPortForwardPod returns first running pod which is ready, same as kubectl port-forward does for services
+func selectPortForwardPod(pods []corev1.Pod) (*corev1.Pod, error) {
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				return pod, nil
+			}
+		}
+	}
+	return nil, errors.New("no ready pod found")
+}
+
+// resolveServiceTargetPort maps service port to container port, named target ports are looked up in pod containers
+func resolveServiceTargetPort(service *corev1.Service, servicePort int, pod *corev1.Pod) (int, error) {
+	for _, port := range service.Spec.Ports {
+		if int(port.Port) != servicePort {
+			continue
+		}
+		switch {
+		case port.TargetPort.Type == intstr.Int && port.TargetPort.IntVal > 0:
+			return int(port.TargetPort.IntVal), nil
+		case port.TargetPort.Type == intstr.String && len(port.TargetPort.StrVal) > 0:
+			for _, container := range pod.Spec.Containers {
+				for _, containerPort := range container.Ports {
+					if containerPort.Name == port.TargetPort.StrVal && containerPort.Protocol == port.Protocol {
+						return int(containerPort.ContainerPort), nil
+					}
+				}
+			}
+			return 0, fmt.Errorf("pod %s does not have port named %s", pod.Name, port.TargetPort.StrVal)
+		}
+		// target port defaults to service port
+		return servicePort, nil
+	}
+	return 0, fmt.Errorf("service %s does not have port %d", service.Name, servicePort)
+}
+
+// activityReader records time of last read so that idle sessions can be closed
+type activityReader struct {
+	reader       io.Reader
+	lastActivity *int64
+}
+
+func (r *activityReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		atomic.StoreInt64(r.lastActivity, time.Now().UnixNano())
+	}
+	return n, err
+}
+
+// relayPortForward copies bytes in both directions till either side ends, ctx is done, remote reports an error or
+// no bytes flow for idleTimeout. Returned error is nil when either side closed normally.
+func relayPortForward(ctx context.Context, conn io.ReadWriter, dataStream io.ReadWriteCloser, remoteErr <-chan error, idleTimeout time.Duration) error {
+	lastActivity := time.Now().UnixNano()
+	copyDone := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(conn, &activityReader{reader: dataStream, lastActivity: &lastActivity})
+		copyDone <- err
+	}()
+	go func() {
+		_, err := io.Copy(dataStream, &activityReader{reader: conn, lastActivity: &lastActivity})
+		copyDone <- err
+	}()
+	defer dataStream.Close()
+	idleCheckInterval := idleTimeout / 10
+	if idleCheckInterval <= 0 || idleCheckInterval > time.Minute {
+		idleCheckInterval = time.Minute
+	}
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return errors.New("port forward session closed as it reached max duration")
+			}
+			return nil
+		case err := <-remoteErr:
+			return err
+		case err := <-copyDone:
+			return err
+		case <-ticker.C:
+			if idleTimeout > 0 && time.Since(time.Unix(0, atomic.LoadInt64(&lastActivity))) > idleTimeout {
+				return errPortForwardIdleTimeout
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net"
+	"testing"
+	"time"
+)
+
+var testHttpContainer = corev1.Container{
+	Name:  "app",
+	Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: corev1.ProtocolTCP}},
+}
+
+func TestSelectPortForwardPod(t *testing.T) {
+	pods := []corev1.Pod{
+		*setTestPodStatus(newTestPod("pending", testHttpContainer), corev1.PodPending, false),
+		*setTestPodStatus(newTestPod("not-ready", testHttpContainer), corev1.PodRunning, false),
+		*setTestPodStatus(newTestPod("ready", testHttpContainer), corev1.PodRunning, true),
+	}
+	pod, err := selectPortForwardPod(pods)
+	if err != nil || pod.Name != "ready" {
+		t.Errorf("expected ready pod to be selected, got %v %v", pod, err)
+	}
+	if _, err = selectPortForwardPod(pods[:2]); err == nil {
+		t.Errorf("expected error when no pod is ready")
+	}
+}
+
+func TestResolveServiceTargetPort(t *testing.T) {
+	pod := newTestPod("api", testHttpContainer)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "api"},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+			{Port: 80, TargetPort: intstr.FromString("http"), Protocol: corev1.ProtocolTCP},
+			{Port: 9090, TargetPort: intstr.FromInt(9091), Protocol: corev1.ProtocolTCP},
+			{Port: 5000, Protocol: corev1.ProtocolTCP},
+			{Port: 81, TargetPort: intstr.FromString("admin"), Protocol: corev1.ProtocolTCP},
+		}},
+	}
+	expectedPorts := map[int]int{80: 8080, 9090: 9091, 5000: 5000}
+	for servicePort, expectedPort := range expectedPorts {
+		port, err := resolveServiceTargetPort(service, servicePort, pod)
+		if err != nil || port != expectedPort {
+			t.Errorf("expected service port %d to resolve to %d, got %d %v", servicePort, expectedPort, port, err)
+		}
+	}
+	if _, err := resolveServiceTargetPort(service, 81, pod); err == nil {
+		t.Errorf("expected error for named port missing in pod")
+	}
+	if _, err := resolveServiceTargetPort(service, 443, pod); err == nil {
+		t.Errorf("expected error for port missing in service")
+	}
+}
+
+func TestRelayPortForward(t *testing.T) {
+	client, clientSide := net.Pipe()
+	remote, remoteSide := net.Pipe()
+	relayDone := make(chan error, 1)
+	go func() {
+		relayDone <- relayPortForward(context.Background(), clientSide, remoteSide, nil, time.Minute)
+	}()
+	go func() {
+		buf := make([]byte, 4)
+		_, _ = io.ReadFull(remote, buf)
+		_, _ = remote.Write([]byte("pong"))
+	}()
+	_, _ = client.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(client, buf); err != nil || string(buf) != "pong" {
+		t.Fatalf("expected bytes to be relayed, got %q %v", buf, err)
+	}
+	remote.Close()
+	if err := <-relayDone; err != nil {
+		t.Errorf("expected remote close to end relay without error, got %v", err)
+	}
+
+	_, clientSide = net.Pipe()
+	_, remoteSide = net.Pipe()
+	remoteErr := make(chan error, 1)
+	remoteErr <- errors.New("connection refused")
+	if err := relayPortForward(context.Background(), clientSide, remoteSide, remoteErr, time.Minute); err == nil {
+		t.Errorf("expected remote error to end relay")
+	}
+
+	_, clientSide = net.Pipe()
+	_, remoteSide = net.Pipe()
+	if err := relayPortForward(context.Background(), clientSide, remoteSide, nil, 10*time.Millisecond); err != errPortForwardIdleTimeout {
+		t.Errorf("expected idle session to be closed, got %v", err)
+	}
+}
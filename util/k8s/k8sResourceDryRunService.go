@@ -0,0 +1,148 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/devtron-labs/devtron/client/k8s/application"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	yamlUtil "github.com/devtron-labs/devtron/util/yaml"
+	errors2 "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+)
+
+// DryRunApplyResources runs apply of every document of manifest with dryRun=All and reports per object
+// change against live state, nothing is persisted. RBAC is checked same as ApplyResources.
+func (impl *K8sApplicationServiceImpl) DryRunApplyResources(ctx context.Context, token string, request *application.ApplyResourcesRequest, validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool) ([]*application.DryRunResourceResponse, error) {
+	manifests, err := yamlUtil.SplitYAMLs([]byte(request.Manifest))
+	if err != nil {
+		impl.logger.Errorw("error in splitting yaml in manifest", "err", err)
+		return nil, err
+	}
+	clusterId := request.ClusterId
+	clusterBean, err := impl.clusterService.FindById(clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting clusterBean by cluster Id", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+	restConfig, err := impl.GetRestConfigByCluster(ctx, clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config by cluster", "clusterId", clusterId, "err", err)
+		return nil, err
+	}
+
+	var response []*application.DryRunResourceResponse
+	for _, manifest := range manifests {
+		namespace := manifest.GetNamespace()
+		if len(namespace) == 0 {
+			namespace = DEFAULT_NAMESPACE
+		}
+		resourceRequestBean := ResourceRequestBean{
+			ClusterId: clusterId,
+			K8sRequest: &application.K8sRequestBean{
+				ResourceIdentifier: application.ResourceIdentifier{
+					Name:             manifest.GetName(),
+					Namespace:        namespace,
+					GroupVersionKind: manifest.GroupVersionKind(),
+				},
+			},
+		}
+		if !validateResourceAccess(token, clusterBean.ClusterName, resourceRequestBean, casbin.ActionUpdate) {
+			response = append(response, &application.DryRunResourceResponse{Kind: manifest.GetKind(), Name: manifest.GetName(), Namespace: namespace,
+				PermissionDenied: true, Error: "permission-denied"})
+			continue
+		}
+		manifestJson, err := json.Marshal(manifest.UnstructuredContent())
+		if err != nil {
+			impl.logger.Errorw("error in marshalling json", "err", err)
+			return nil, err
+		}
+		dryRunResp := impl.dryRunResource(ctx, restConfig, resourceRequestBean.K8sRequest, manifest.GetKind(), func() (*application.ManifestResponse, error) {
+			return impl.k8sClientService.DryRunApplyResource(ctx, restConfig, resourceRequestBean.K8sRequest, string(manifestJson))
+		})
+		response = append(response, dryRunResp)
+	}
+	return response, nil
+}
+
+// DryRunUpdateResource runs UpdateResource with dryRun=All so that resource editor can show what will change
+func (impl *K8sApplicationServiceImpl) DryRunUpdateResource(ctx context.Context, request *ResourceRequestBean) (*application.DryRunResourceResponse, error) {
+	clusterId := request.ClusterId
+	restConfig, err := impl.GetRestConfigByClusterId(ctx, clusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config by cluster Id", "err", err, "clusterId", clusterId)
+		return nil, err
+	}
+	return impl.dryRunResource(ctx, restConfig, request.K8sRequest, request.K8sRequest.ResourceIdentifier.GroupVersionKind.Kind, func() (*application.ManifestResponse, error) {
+		return impl.k8sClientService.DryRunUpdateResource(ctx, restConfig, request.K8sRequest)
+	}), nil
+}
+
+func (impl *K8sApplicationServiceImpl) dryRunResource(ctx context.Context, restConfig *rest.Config, k8sRequest *application.K8sRequestBean, kind string,
+	dryRun func() (*application.ManifestResponse, error)) *application.DryRunResourceResponse {
+	resourceIdentifier := k8sRequest.ResourceIdentifier
+	response := &application.DryRunResourceResponse{Kind: kind, Name: resourceIdentifier.Name, Namespace: resourceIdentifier.Namespace}
+	var liveManifest *unstructured.Unstructured
+	live, err := impl.k8sClientService.GetResource(ctx, restConfig, k8sRequest)
+	if err == nil {
+		liveManifest = &live.Manifest
+	} else if !errors2.IsNotFound(err) {
+		impl.logger.Errorw("error in getting live resource for dry run", "err", err, "resource", resourceIdentifier)
+		setDryRunError(response, err)
+		return response
+	}
+	dryRunResp, err := dryRun()
+	if err != nil {
+		setDryRunError(response, err)
+		return response
+	}
+	if err = buildDryRunDiff(response, liveManifest, &dryRunResp.Manifest); err != nil {
+		impl.logger.Errorw("error in building dry run diff", "err", err, "resource", resourceIdentifier)
+		response.Error = err.Error()
+	}
+	return response
+}
+
+// setDryRunError separates field validation errors and rbac denials of target cluster from other errors
+func setDryRunError(response *application.DryRunResourceResponse, err error) {
+	response.Error = err.Error()
+	statusErr, ok := err.(*errors2.StatusError)
+	if !ok {
+		return
+	}
+	if errors2.IsForbidden(statusErr) {
+		response.PermissionDenied = true
+	}
+	if errors2.IsInvalid(statusErr) && statusErr.ErrStatus.Details != nil {
+		for _, cause := range statusErr.ErrStatus.Details.Causes {
+			if len(cause.Field) > 0 {
+				response.ValidationErrors = append(response.ValidationErrors, fmt.Sprintf("%s: %s", cause.Field, cause.Message))
+			} else {
+				response.ValidationErrors = append(response.ValidationErrors, cause.Message)
+			}
+		}
+	}
+}
+
+// buildDryRunDiff sets action and diff of dry run object against live object, live is nil for new objects
+func buildDryRunDiff(response *application.DryRunResourceResponse, live *unstructured.Unstructured, dryRun *unstructured.Unstructured) error {
+	response.DryRunManifest = application.NormalizeManifestForDiff(dryRun)
+	if live != nil {
+		response.LiveManifest = application.NormalizeManifestForDiff(live)
+	}
+	diff, err := application.CreateManifestDiff(live, dryRun)
+	if err != nil {
+		return err
+	}
+	switch {
+	case live == nil:
+		response.Action = application.DRY_RUN_ACTION_CREATE
+	case len(diff) == 0:
+		response.Action = application.DRY_RUN_ACTION_UNCHANGED
+	default:
+		response.Action = application.DRY_RUN_ACTION_CHANGE
+	}
+	response.Diff = diff
+	return nil
+}
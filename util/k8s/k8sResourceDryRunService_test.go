@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"github.com/devtron-labs/devtron/client/k8s/application"
+	errors2 "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"strings"
+	"testing"
+)
+
+// newTestConfigMap config map with the server managed fields which are to be left out of dry run diff
+func newTestConfigMap(resourceVersion string, data map[string]interface{}) *unstructured.Unstructured {
+	return newTestUnstructured("v1", "ConfigMap", "app-config", map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": resourceVersion,
+			"uid":             "5f1c",
+			"managedFields":   []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			"annotations":     map[string]interface{}{application.LastAppliedConfigAnnotation: "{}"},
+		},
+		"data": data,
+	})
+}
+
+func TestBuildDryRunDiff(t *testing.T) {
+	live := newTestConfigMap("10", map[string]interface{}{"LOG_LEVEL": "info", "PORT": "8080"})
+
+	response := &application.DryRunResourceResponse{}
+	if err := buildDryRunDiff(response, live, newTestConfigMap("11", map[string]interface{}{"LOG_LEVEL": "info", "PORT": "8080"})); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if response.Action != application.DRY_RUN_ACTION_UNCHANGED || len(response.Diff) != 0 {
+		t.Errorf("expected unchanged without diff, got %s %s", response.Action, response.Diff)
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(response.LiveManifest.Object, "metadata", "managedFields"); found {
+		t.Errorf("expected managed fields to be removed from live manifest")
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(live.Object, "metadata", "managedFields"); !found {
+		t.Errorf("expected live object to be left untouched")
+	}
+
+	response = &application.DryRunResourceResponse{}
+	if err := buildDryRunDiff(response, live, newTestConfigMap("11", map[string]interface{}{"LOG_LEVEL": "debug"})); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if response.Action != application.DRY_RUN_ACTION_CHANGE || string(response.Diff) != `{"data":{"LOG_LEVEL":"debug","PORT":null}}` {
+		t.Errorf("unexpected change diff %s %s", response.Action, response.Diff)
+	}
+
+	response = &application.DryRunResourceResponse{}
+	if err := buildDryRunDiff(response, nil, newTestConfigMap("1", map[string]interface{}{"PORT": "8080"})); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if response.Action != application.DRY_RUN_ACTION_CREATE || response.LiveManifest != nil || strings.Contains(string(response.Diff), "resourceVersion") {
+		t.Errorf("unexpected create diff %s %s", response.Action, response.Diff)
+	}
+}
+
+func TestSetDryRunError(t *testing.T) {
+	gk := schema.GroupKind{Kind: "Deployment", Group: "apps"}
+	invalidErr := errors2.NewInvalid(gk, "api", field.ErrorList{field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0")})
+	response := &application.DryRunResourceResponse{}
+	setDryRunError(response, invalidErr)
+	if len(response.ValidationErrors) != 1 || !strings.HasPrefix(response.ValidationErrors[0], "spec.replicas: ") || response.PermissionDenied {
+		t.Errorf("unexpected validation errors %v", response.ValidationErrors)
+	}
+
+	response = &application.DryRunResourceResponse{}
+	setDryRunError(response, errors2.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "api", nil))
+	if !response.PermissionDenied || len(response.Error) == 0 {
+		t.Errorf("expected permission denied, got %+v", response)
+	}
+}
@@ -0,0 +1,190 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"github.com/devtron-labs/devtron/internal/util"
+	"github.com/devtron-labs/devtron/pkg/user/casbin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	"time"
+)
+
+var errResourceVersionExpired = errors.New("resource version expired")
+
+// minimum gap between two watch requests, protects api server when watch is closed right after opening
+const rewatchInterval = time.Second
+
+// WatchResourceList streams resource list deltas to send until ctx is done or send fails. Without resourceVersion a
+// snapshot of the list is sent first, otherwise watch resumes after given version. Expired versions result in a
+// RESYNC event followed by a fresh snapshot. Rows are filtered with the same RBAC callback as GetResourceList.
+func (impl *K8sApplicationServiceImpl) WatchResourceList(ctx context.Context, token string, request *ResourceRequestBean, resourceVersion string,
+	validateResourceAccess func(token string, clusterName string, request ResourceRequestBean, casbinAction string) bool, send func(event *ResourceListWatchEvent) error) error {
+	clusterBean, err := impl.clusterService.FindById(request.ClusterId)
+	if err != nil {
+		impl.logger.Errorw("error in getting cluster by cluster Id", "err", err, "clusterId", request.ClusterId)
+		return err
+	}
+	restConfig, err := impl.GetRestConfigByCluster(ctx, clusterBean)
+	if err != nil {
+		impl.logger.Errorw("error in getting rest config by cluster Id", "err", err, "clusterId", request.ClusterId)
+		return err
+	}
+	checkForResourceCallback := func(namespace, group, kind, resourceName string) bool {
+		// rbac is checked on a copy so that watched resource identifier is not overwritten
+		k8sRequest := *request.K8sRequest
+		k8sRequest.ResourceIdentifier.Name = resourceName
+		k8sRequest.ResourceIdentifier.Namespace = namespace
+		if group != "" && kind != "" {
+			k8sRequest.ResourceIdentifier.GroupVersionKind = schema.GroupVersionKind{Group: group, Kind: kind}
+		}
+		rbacRequest := *request
+		rbacRequest.K8sRequest = &k8sRequest
+		return validateResourceAccess(token, clusterBean.ClusterName, rbacRequest, casbin.ActionGet)
+	}
+	for ctx.Err() == nil {
+		// list is needed for column definitions even when resuming, watch events do not carry them reliably
+		resp, namespaced, err := impl.k8sClientService.GetResourceList(ctx, restConfig, request.K8sRequest)
+		if err != nil {
+			impl.logger.Errorw("error in getting resource list", "err", err, "request", request)
+			return err
+		}
+		columnDefinitions := resp.Resources.Object[util.K8sClusterResourceColumnDefinitionKey]
+		if len(resourceVersion) == 0 {
+			resourceList, err := impl.K8sUtil.BuildK8sObjectListTableData(&resp.Resources, namespaced, request.K8sRequest.ResourceIdentifier.GroupVersionKind, checkForResourceCallback)
+			if err != nil {
+				impl.logger.Errorw("error on parsing for k8s resource", "err", err)
+				return err
+			}
+			resourceVersion = resp.Resources.GetResourceVersion()
+			err = send(&ResourceListWatchEvent{Type: RESOURCE_WATCH_EVENT_SNAPSHOT, ResourceVersion: resourceVersion, Headers: resourceList.Headers, Data: resourceList.Data})
+			if err != nil {
+				return err
+			}
+		}
+		resourceVersion, err = impl.watchResourceListFrom(ctx, restConfig, request, resourceVersion, namespaced, columnDefinitions, checkForResourceCallback, send)
+		if err == errResourceVersionExpired {
+			impl.logger.Infow("resource version expired, resyncing resource watch", "clusterId", request.ClusterId, "resourceVersion", resourceVersion)
+			if err = send(&ResourceListWatchEvent{Type: RESOURCE_WATCH_EVENT_RESYNC, ResourceVersion: resourceVersion}); err != nil {
+				return err
+			}
+			resourceVersion = ""
+			continue
+		}
+		return err
+	}
+	return nil
+}
+
+// watchResourceListFrom keeps watching from last seen resource version, reopening watch when server closes it.
+// It returns nil when ctx is done, i.e. client has disconnected.
+func (impl *K8sApplicationServiceImpl) watchResourceListFrom(ctx context.Context, restConfig *rest.Config, request *ResourceRequestBean, resourceVersion string, namespaced bool,
+	columnDefinitions interface{}, checkForResourceCallback func(namespace, group, kind, resourceName string) bool, send func(event *ResourceListWatchEvent) error) (string, error) {
+	gvk := request.K8sRequest.ResourceIdentifier.GroupVersionKind
+	for {
+		watchStartedOn := time.Now()
+		watchIf, _, err := impl.k8sClientService.WatchResourceList(ctx, restConfig, request.K8sRequest, resourceVersion)
+		if err != nil {
+			if ctx.Err() != nil {
+				return resourceVersion, nil
+			}
+			if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+				return resourceVersion, errResourceVersionExpired
+			}
+			return resourceVersion, err
+		}
+		resourceVersion, err = impl.consumeResourceWatch(ctx, watchIf, resourceVersion, namespaced, gvk, columnDefinitions, checkForResourceCallback, send)
+		watchIf.Stop()
+		if err != nil || ctx.Err() != nil {
+			return resourceVersion, err
+		}
+		if elapsed := time.Since(watchStartedOn); elapsed < rewatchInterval {
+			time.Sleep(rewatchInterval - elapsed)
+		}
+	}
+}
+
+func (impl *K8sApplicationServiceImpl) consumeResourceWatch(ctx context.Context, watchIf watch.Interface, resourceVersion string, namespaced bool, gvk schema.GroupVersionKind,
+	columnDefinitions interface{}, checkForResourceCallback func(namespace, group, kind, resourceName string) bool, send func(event *ResourceListWatchEvent) error) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion, nil
+		case event, ok := <-watchIf.ResultChan():
+			if !ok {
+				return resourceVersion, nil
+			}
+			switch event.Type {
+			case watch.Error:
+				err := apierrors.FromObject(event.Object)
+				if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+					return resourceVersion, errResourceVersionExpired
+				}
+				impl.logger.Errorw("error event in resource watch", "err", err, "resourceVersion", resourceVersion)
+				return resourceVersion, err
+			case watch.Added, watch.Modified, watch.Deleted:
+				table, ok := event.Object.(*unstructured.Unstructured)
+				if !ok {
+					continue
+				}
+				watchEvents, eventResourceVersion, err := impl.buildResourceWatchEvents(table, string(event.Type), namespaced, gvk, columnDefinitions, checkForResourceCallback)
+				if err != nil {
+					impl.logger.Errorw("error on parsing k8s resource watch event", "err", err)
+					return resourceVersion, err
+				}
+				if len(eventResourceVersion) > 0 {
+					resourceVersion = eventResourceVersion
+				}
+				for _, watchEvent := range watchEvents {
+					if err = send(watchEvent); err != nil {
+						return resourceVersion, err
+					}
+				}
+			}
+		}
+	}
+}
+
+// buildResourceWatchEvents formats rows of a table watch event with column definitions of initial list, rows not
+// allowed by rbac are dropped but their resource version is still returned so that resume skips them
+func (impl *K8sApplicationServiceImpl) buildResourceWatchEvents(table *unstructured.Unstructured, eventType string, namespaced bool, gvk schema.GroupVersionKind,
+	columnDefinitions interface{}, checkForResourceCallback func(namespace, group, kind, resourceName string) bool) ([]*ResourceListWatchEvent, string, error) {
+	resourceVersion := table.GetResourceVersion()
+	rows, _ := table.Object[util.K8sClusterResourceRowsKey].([]interface{})
+	var watchEvents []*ResourceListWatchEvent
+	for _, row := range rows {
+		name, namespace, rowResourceVersion := tableRowMetadata(row)
+		if len(resourceVersion) == 0 {
+			resourceVersion = rowResourceVersion
+		}
+		rowList := &unstructured.UnstructuredList{Object: map[string]interface{}{
+			util.K8sClusterResourceColumnDefinitionKey: columnDefinitions,
+			util.K8sClusterResourceRowsKey:             []interface{}{row},
+		}}
+		resourceList, err := impl.K8sUtil.BuildK8sObjectListTableData(rowList, namespaced, gvk, checkForResourceCallback)
+		if err != nil {
+			return nil, resourceVersion, err
+		}
+		if len(resourceList.Data) == 0 {
+			continue
+		}
+		watchEvents = append(watchEvents, &ResourceListWatchEvent{Type: eventType, Name: name, Namespace: namespace, Row: resourceList.Data[0]})
+	}
+	for _, watchEvent := range watchEvents {
+		watchEvent.ResourceVersion = resourceVersion
+	}
+	return watchEvents, resourceVersion, nil
+}
+
+func tableRowMetadata(row interface{}) (name string, namespace string, resourceVersion string) {
+	rowMap, _ := row.(map[string]interface{})
+	object, _ := rowMap[util.K8sClusterResourceObjectKey].(map[string]interface{})
+	metadata, _ := object[util.K8sClusterResourceMetadataKey].(map[string]interface{})
+	name, _ = metadata[util.K8sClusterResourceMetadataNameKey].(string)
+	namespace, _ = metadata[util.K8sClusterResourceNamespaceKey].(string)
+	resourceVersion, _ = metadata["resourceVersion"].(string)
+	return name, namespace, resourceVersion
+}
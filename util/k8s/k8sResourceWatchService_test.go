@@ -0,0 +1,68 @@
+package k8s
+
+import (
+	"github.com/devtron-labs/authenticator/client"
+	"github.com/devtron-labs/devtron/internal/util"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+)
+
+func newTestTableRow(name string, resourceVersion string, phase string) interface{} {
+	object := newTestUnstructured("v1", "Pod", name, nil)
+	object.SetResourceVersion(resourceVersion)
+	return map[string]interface{}{"cells": []interface{}{name, phase}, "object": object.Object}
+}
+
+func TestBuildResourceWatchEvents(t *testing.T) {
+	impl := &K8sApplicationServiceImpl{K8sUtil: util.NewK8sUtil(zap.NewNop().Sugar(), &client.RuntimeConfig{})}
+	columnDefinitions := []interface{}{
+		map[string]interface{}{"name": "Name", "priority": int64(0)},
+		map[string]interface{}{"name": "Status", "priority": int64(0)},
+	}
+	table := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Table",
+		"rows": []interface{}{
+			newTestTableRow("api-1", "101", "Running"),
+			newTestTableRow("db-1", "101", "Pending"),
+		},
+	}}
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	allowOnlyApi := func(namespace, group, kind, resourceName string) bool {
+		return resourceName == "api-1"
+	}
+	events, resourceVersion, err := impl.buildResourceWatchEvents(table, RESOURCE_WATCH_EVENT_MODIFIED, true, gvk, columnDefinitions, allowOnlyApi)
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if resourceVersion != "101" {
+		t.Errorf("expected resource version from row metadata, got %s", resourceVersion)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected rbac to filter one row, got %d events", len(events))
+	}
+	event := events[0]
+	if event.Type != RESOURCE_WATCH_EVENT_MODIFIED || event.Name != "api-1" || event.Namespace != "prod" || event.ResourceVersion != "101" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	if event.Row["name"] != "api-1" || event.Row["status"] != "Running" || event.Row["namespace"] != "prod" {
+		t.Errorf("unexpected row %v", event.Row)
+	}
+
+	table.SetResourceVersion("102")
+	events, resourceVersion, err = impl.buildResourceWatchEvents(table, RESOURCE_WATCH_EVENT_DELETED, true, gvk, columnDefinitions, func(namespace, group, kind, resourceName string) bool { return false })
+	if err != nil || len(events) != 0 || resourceVersion != "102" {
+		t.Errorf("expected no events with table resource version, got %d %s %v", len(events), resourceVersion, err)
+	}
+}
+
+func TestTableRowMetadata(t *testing.T) {
+	name, namespace, resourceVersion := tableRowMetadata(newTestTableRow("api-1", "7", "Running"))
+	if name != "api-1" || namespace != "prod" || resourceVersion != "7" {
+		t.Errorf("unexpected metadata %s %s %s", name, namespace, resourceVersion)
+	}
+	if name, _, _ = tableRowMetadata(map[string]interface{}{}); name != "" {
+		t.Errorf("expected empty name for row without object")
+	}
+}
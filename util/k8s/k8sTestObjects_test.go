@@ -0,0 +1,40 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// builders of objects shared by tests of this package, objects are created in testNamespace
+
+const testNamespace = "prod"
+
+// newTestUnstructured builds an object of kind with content set on it, metadata of content is kept along with name and namespace
+func newTestUnstructured(apiVersion string, kind string, name string, content map[string]interface{}) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	for key, value := range content {
+		object.Object[key] = value
+	}
+	object.SetAPIVersion(apiVersion)
+	object.SetKind(kind)
+	object.SetName(name)
+	object.SetNamespace(testNamespace)
+	return object
+}
+
+func newTestPod(name string, containers ...corev1.Container) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec:       corev1.PodSpec{Containers: containers},
+	}
+}
+
+func setTestPodStatus(pod *corev1.Pod, phase corev1.PodPhase, ready bool) *corev1.Pod {
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	pod.Status = corev1.PodStatus{Phase: phase, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}}}
+	return pod
+}
@@ -4,6 +4,7 @@ import (
 	application2 "github.com/devtron-labs/devtron/client/k8s/application"
 	"github.com/devtron-labs/devtron/client/k8s/informer"
 	"github.com/devtron-labs/devtron/pkg/terminal"
+	"github.com/devtron-labs/devtron/pkg/terminal/repository"
 	"github.com/google/wire"
 )
 
@@ -18,6 +19,10 @@ var K8sApplicationWireSet = wire.NewSet(
 	wire.Bind(new(application2.K8sClientService), new(*application2.K8sClientServiceImpl)),
 	terminal.NewTerminalSessionHandlerImpl,
 	wire.Bind(new(terminal.TerminalSessionHandler), new(*terminal.TerminalSessionHandlerImpl)),
+	terminal.NewTerminalSessionRecordingServiceImpl,
+	wire.Bind(new(terminal.TerminalSessionRecordingService), new(*terminal.TerminalSessionRecordingServiceImpl)),
+	repository.NewTerminalSessionRecordingRepositoryImpl,
+	wire.Bind(new(repository.TerminalSessionRecordingRepository), new(*repository.TerminalSessionRecordingRepositoryImpl)),
 	NewK8sCapacityRouterImpl,
 	wire.Bind(new(K8sCapacityRouter), new(*K8sCapacityRouterImpl)),
 	NewK8sCapacityRestHandlerImpl,